@@ -3,184 +3,204 @@ package v1
 import (
 	"os"
 	"reflect"
+	"sort"
 	"strings"
 	"testing"
 
-	"gopkg.in/yaml.v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
 )
 
-// CRDSchema represents the structure we need from the CRD YAML
-type CRDSchema struct {
-	Spec struct {
-		Versions []struct {
-			Schema struct {
-				OpenAPIV3Schema struct {
-					Properties map[string]SchemaProperty `yaml:"properties"`
-				} `yaml:"openAPIV3Schema"`
-			} `yaml:"schema"`
-		} `yaml:"versions"`
-	} `yaml:"spec"`
+// crdCopies are the test and chart copies of each CRD, which are maintained by hand and
+// must both match the Go types
+var crdCopies = []struct {
+	name   string
+	goType reflect.Type
+	paths  []string
+}{
+	{
+		name:   "VpaManager",
+		goType: reflect.TypeOf(VpaManager{}),
+		paths: []string{
+			"../../test/crds/vpamanager-crd.yaml",
+			"../../charts/vpa-operator/templates/crds/vpamanager-crd.yaml",
+		},
+	},
+	{
+		name:   "VpaOverride",
+		goType: reflect.TypeOf(VpaOverride{}),
+		paths: []string{
+			"../../test/crds/vpaoverride-crd.yaml",
+			"../../charts/vpa-operator/templates/crds/vpaoverride-crd.yaml",
+		},
+	},
 }
 
-type SchemaProperty struct {
-	Properties map[string]SchemaProperty `yaml:"properties,omitempty"`
-	Items      *SchemaProperty           `yaml:"items,omitempty"`
+// schemaTypes maps the types of other packages to their schema type; their fields are
+// not compared
+var schemaTypes = map[reflect.Type]string{
+	reflect.TypeOf(metav1.Time{}):          "string",
+	reflect.TypeOf(metav1.Duration{}):      "string",
+	reflect.TypeOf(metav1.LabelSelector{}): "object",
+	reflect.TypeOf(metav1.Condition{}):     "object",
 }
 
-// getJSONFieldNames extracts JSON field names from a struct type using reflection
-func getJSONFieldNames(t reflect.Type) map[string]bool {
-	fields := make(map[string]bool)
-	for i := 0; i < t.NumField(); i++ {
-		field := t.Field(i)
-		jsonTag := field.Tag.Get("json")
-		if jsonTag == "" || jsonTag == "-" {
-			continue
-		}
-		// Extract field name from json tag (before comma for omitempty etc)
-		jsonName := strings.Split(jsonTag, ",")[0]
-		if jsonName != "" && jsonName != "inline" {
-			fields[jsonName] = true
+// loadCRD parses a CRD, stripping the Helm template lines of the chart copy
+func loadCRD(t *testing.T, path string) *apiextensionsv1.CustomResourceDefinition {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	var lines []string
+	for _, line := range strings.Split(string(data), "\n") {
+		if !strings.Contains(line, "{{") {
+			lines = append(lines, line)
 		}
 	}
-	return fields
+	crd := &apiextensionsv1.CustomResourceDefinition{}
+	require.NoError(t, yaml.Unmarshal([]byte(strings.Join(lines, "\n")), crd))
+	require.NotEmpty(t, crd.Spec.Versions, "no versions in %s", path)
+	require.NotNil(t, crd.Spec.Versions[0].Schema, "no schema in %s", path)
+	return crd
 }
 
-// TestCRDSchemaMatchesGoTypes verifies that all Go struct fields are defined in the CRD schema
+// TestCRDSchemaMatchesGoTypes walks the Go types and both copies of each CRD schema side
+// by side, so a field added to, renamed in or removed from either is reported with its path
 func TestCRDSchemaMatchesGoTypes(t *testing.T) {
-	// Try multiple possible CRD locations
-	crdPaths := []string{
-		"../../test/crds/vpamanager-crd.yaml",
-		"../../charts/vpa-operator/templates/crds/vpamanager-crd.yaml",
-	}
-
-	var crdData []byte
-	var err error
-	var usedPath string
-
-	for _, path := range crdPaths {
-		crdData, err = os.ReadFile(path)
-		if err == nil {
-			usedPath = path
-			break
+	for _, tt := range crdCopies {
+		for _, path := range tt.paths {
+			t.Run(path, func(t *testing.T) {
+				schema := loadCRD(t, path).Spec.Versions[0].Schema.OpenAPIV3Schema
+				for _, name := range []string{"spec", "status"} {
+					field, ok := tt.goType.FieldByName(strings.ToUpper(name[:1]) + name[1:])
+					if !ok {
+						continue
+					}
+					prop, ok := schema.Properties[name]
+					if !assert.True(t, ok, "CRD schema has no %s", name) {
+						continue
+					}
+					compareSchema(t, name, field.Type, prop)
+				}
+			})
 		}
 	}
+}
 
-	if crdData == nil {
-		t.Fatalf("Could not read CRD from any known location: %v", err)
-	}
-
-	t.Logf("Using CRD from: %s", usedPath)
-
-	// Handle Helm template conditionals by stripping them
-	crdContent := string(crdData)
-	crdContent = strings.ReplaceAll(crdContent, "{{- if .Values.crds.install -}}", "")
-	crdContent = strings.ReplaceAll(crdContent, "{{- end }}", "")
-	// Remove any remaining Helm template expressions for labels
-	lines := strings.Split(crdContent, "\n")
-	var cleanLines []string
-	for _, line := range lines {
-		if !strings.Contains(line, "{{") {
-			cleanLines = append(cleanLines, line)
-		}
+// TestChartCRDsMatchTestCRDs checks that the chart copy of each CRD has the same schema as
+// the test copy
+func TestChartCRDsMatchTestCRDs(t *testing.T) {
+	for _, tt := range crdCopies {
+		t.Run(tt.name, func(t *testing.T) {
+			testCRD, chartCRD := loadCRD(t, tt.paths[0]), loadCRD(t, tt.paths[1])
+			assert.Equal(t, testCRD.Spec.Versions, chartCRD.Spec.Versions)
+		})
 	}
-	crdContent = strings.Join(cleanLines, "\n")
+}
 
-	var crd CRDSchema
-	if err := yaml.Unmarshal([]byte(crdContent), &crd); err != nil {
-		t.Fatalf("Failed to parse CRD YAML: %v", err)
+// compareSchema reports where the schema of path differs from the Go type typ
+func compareSchema(t *testing.T, path string, typ reflect.Type, schema apiextensionsv1.JSONSchemaProps) {
+	t.Helper()
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
 	}
-
-	if len(crd.Spec.Versions) == 0 {
-		t.Fatal("No versions found in CRD")
+	if want, ok := schemaTypes[typ]; ok {
+		checkType(t, path, want, schema.Type)
+		return
 	}
 
-	schema := crd.Spec.Versions[0].Schema.OpenAPIV3Schema.Properties
-
-	// Test spec fields
-	t.Run("VpaManagerSpec fields match CRD", func(t *testing.T) {
-		goSpecFields := getJSONFieldNames(reflect.TypeOf(VpaManagerSpec{}))
-		crdSpecFields := getSchemaFieldNames(schema["spec"])
-
-		for field := range goSpecFields {
-			if !crdSpecFields[field] {
-				t.Errorf("Go VpaManagerSpec has field %q but CRD spec schema does not", field)
+	switch typ.Kind() {
+	case reflect.Struct:
+		checkType(t, path, "object", schema.Type)
+		fields := jsonFields(typ)
+		for _, name := range sortedNames(fields) {
+			prop, ok := schema.Properties[name]
+			if !ok {
+				t.Errorf("%s: Go type %s has field %q but the CRD schema does not", path, typ.Name(), name)
+				continue
 			}
+			compareSchema(t, path+"."+name, fields[name], prop)
 		}
-
-		for field := range crdSpecFields {
-			if !goSpecFields[field] {
-				t.Errorf("CRD spec schema has field %q but Go VpaManagerSpec does not", field)
+		for name := range schema.Properties {
+			if _, ok := fields[name]; !ok {
+				t.Errorf("%s: CRD schema has field %q but Go type %s does not", path, name, typ.Name())
 			}
 		}
-	})
-
-	// Test status fields
-	t.Run("VpaManagerStatus fields match CRD", func(t *testing.T) {
-		goStatusFields := getJSONFieldNames(reflect.TypeOf(VpaManagerStatus{}))
-		crdStatusFields := getSchemaFieldNames(schema["status"])
-
-		for field := range goStatusFields {
-			if !crdStatusFields[field] {
-				t.Errorf("Go VpaManagerStatus has field %q but CRD status schema does not", field)
-			}
+	case reflect.Slice:
+		checkType(t, path, "array", schema.Type)
+		if schema.Items == nil || schema.Items.Schema == nil {
+			t.Errorf("%s: CRD schema has no items", path)
+			return
 		}
-
-		for field := range crdStatusFields {
-			if !goStatusFields[field] {
-				t.Errorf("CRD status schema has field %q but Go VpaManagerStatus does not", field)
-			}
+		compareSchema(t, path+"[]", typ.Elem(), *schema.Items.Schema)
+	case reflect.Map:
+		checkType(t, path, "object", schema.Type)
+		if schema.AdditionalProperties == nil || schema.AdditionalProperties.Schema == nil {
+			t.Errorf("%s: CRD schema has no additionalProperties", path)
+			return
 		}
-	})
-
-	// Test WorkloadReference fields (used in status arrays)
-	t.Run("WorkloadReference fields match CRD managedDeployments items", func(t *testing.T) {
-		goWorkloadFields := getJSONFieldNames(reflect.TypeOf(WorkloadReference{}))
-
-		statusProps := schema["status"]
-		if managedDeps, ok := statusProps.Properties["managedDeployments"]; ok {
-			if managedDeps.Items != nil {
-				crdWorkloadFields := getSchemaFieldNames(*managedDeps.Items)
-
-				for field := range goWorkloadFields {
-					if !crdWorkloadFields[field] {
-						t.Errorf("Go WorkloadReference has field %q but CRD managedDeployments items do not", field)
-					}
-				}
+		// ResourceBounds decodes unquoted quantities too, so its values are int-or-string
+		if typ == reflect.TypeOf(ResourceBounds{}) {
+			if !schema.AdditionalProperties.Schema.XIntOrString {
+				t.Errorf("%s{}: CRD schema must be x-kubernetes-int-or-string", path)
 			}
+			return
 		}
-	})
-
-	// Test ContainerResourcePolicy fields
-	t.Run("ContainerResourcePolicy fields match CRD", func(t *testing.T) {
-		goContainerFields := getJSONFieldNames(reflect.TypeOf(ContainerResourcePolicy{}))
-
-		specProps := schema["spec"]
-		if resourcePolicy, ok := specProps.Properties["resourcePolicy"]; ok {
-			if containerPolicies, ok := resourcePolicy.Properties["containerPolicies"]; ok {
-				if containerPolicies.Items != nil {
-					crdContainerFields := getSchemaFieldNames(*containerPolicies.Items)
+		compareSchema(t, path+"{}", typ.Elem(), *schema.AdditionalProperties.Schema)
+	case reflect.String:
+		checkType(t, path, "string", schema.Type)
+	case reflect.Bool:
+		checkType(t, path, "boolean", schema.Type)
+	case reflect.Int, reflect.Int32, reflect.Int64:
+		checkType(t, path, "integer", schema.Type)
+	case reflect.Float32, reflect.Float64:
+		checkType(t, path, "number", schema.Type)
+	default:
+		t.Errorf("%s: unsupported Go kind %s", path, typ.Kind())
+	}
+}
 
-					for field := range goContainerFields {
-						if !crdContainerFields[field] {
-							t.Errorf("Go ContainerResourcePolicy has field %q but CRD containerPolicies items do not", field)
-						}
-					}
+// checkType reports a schema type other than want
+func checkType(t *testing.T, path, want, got string) {
+	t.Helper()
+	if got != want {
+		t.Errorf("%s: CRD schema type is %q, the Go type needs %q", path, got, want)
+	}
+}
 
-					for field := range crdContainerFields {
-						if !goContainerFields[field] {
-							t.Errorf("CRD containerPolicies has field %q but Go ContainerResourcePolicy does not", field)
-						}
-					}
-				}
+// jsonFields returns the JSON field names of a struct and their types, following inlined
+// embedded structs
+func jsonFields(typ reflect.Type) map[string]reflect.Type {
+	fields := map[string]reflect.Type{}
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		name, opts, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" || !field.IsExported() {
+			continue
+		}
+		if name == "" && (field.Anonymous || strings.Contains(opts, "inline")) {
+			for inlined, inlinedType := range jsonFields(field.Type) {
+				fields[inlined] = inlinedType
 			}
+			continue
+		}
+		if name == "" {
+			name = field.Name
 		}
-	})
+		fields[name] = field.Type
+	}
+	return fields
 }
 
-func getSchemaFieldNames(prop SchemaProperty) map[string]bool {
-	fields := make(map[string]bool)
-	for name := range prop.Properties {
-		fields[name] = true
+// sortedNames returns the names of fields in order, so failures are reported in order
+func sortedNames(fields map[string]reflect.Type) []string {
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
 	}
-	return fields
+	sort.Strings(names)
+	return names
 }
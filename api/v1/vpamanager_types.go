@@ -1,6 +1,10 @@
 package v1
 
 import (
+	"encoding/json"
+	"slices"
+	"sort"
+
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -10,48 +14,410 @@ type VpaManagerSpec struct {
 	// +kubebuilder:default=true
 	Enabled bool `json:"enabled"`
 
+	// Priority decides which VpaManager manages a workload that several enabled VpaManagers
+	// select: the highest priority wins, and the name sorting first breaks ties. It is not
+	// inherited through inheritFrom.
+	// +optional
+	Priority int32 `json:"priority,omitempty"`
+
 	// UpdateMode defines the VPA update mode (Off, Initial, Auto)
 	// +kubebuilder:validation:Enum=Off;Initial;Auto
 	// +kubebuilder:default="Off"
 	UpdateMode string `json:"updateMode"`
 
+	// UpdateModes sets the update mode per workload kind, e.g. Initial for StatefulSets,
+	// which are riskier to evict. Kinds it omits use UpdateMode.
+	// +optional
+	UpdateModes *UpdateModesByKind `json:"updateModes,omitempty"`
+
+	// UpdatePolicy holds updatePolicy settings of generated VPAs besides the update mode
+	// +optional
+	UpdatePolicy *UpdatePolicy `json:"updatePolicy,omitempty"`
+
+	// MatchAllNamespaces selects every namespace and is mutually exclusive with
+	// NamespaceSelector. An omitted namespaceSelector matching every namespace is
+	// deprecated and will match nothing in a future release.
+	// +optional
+	MatchAllNamespaces bool `json:"matchAllNamespaces,omitempty"`
+
+	// MatchAllWorkloads selects every workload of the kinds that have no selector.
+	// Without it, kinds whose selector is omitted are not managed.
+	// +optional
+	MatchAllWorkloads bool `json:"matchAllWorkloads,omitempty"`
+
 	// NamespaceSelector selects the namespaces to manage VPAs for
 	// +optional
 	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
 
+	// NamespaceSelectors selects further namespaces: a namespace matching namespaceSelector
+	// or any of these is selected. Use it for alternatives across label keys, which a single
+	// selector's matchExpressions cannot express.
+	// +optional
+	NamespaceSelectors []metav1.LabelSelector `json:"namespaceSelectors,omitempty"`
+
+	// Namespaces lists namespaces to manage VPAs for by name, in addition to those
+	// matched by NamespaceSelector. Without a NamespaceSelector only the listed
+	// namespaces are selected. Mutually exclusive with MatchAllNamespaces.
+	// +optional
+	Namespaces []string `json:"namespaces,omitempty"`
+
+	// ExcludeNamespaces lists namespaces never to manage VPAs in, even when they are
+	// listed in Namespaces or matched by NamespaceSelector or MatchAllNamespaces
+	// +optional
+	ExcludeNamespaces []string `json:"excludeNamespaces,omitempty"`
+
+	// NamespaceExcludeSelector excludes the namespaces it matches, even when they are
+	// listed in Namespaces or matched by NamespaceSelector or MatchAllNamespaces
+	// +optional
+	NamespaceExcludeSelector *metav1.LabelSelector `json:"namespaceExcludeSelector,omitempty"`
+
 	// DeploymentSelector selects the deployments to manage VPAs for
 	// +optional
 	DeploymentSelector *metav1.LabelSelector `json:"deploymentSelector,omitempty"`
 
+	// DeploymentSelectors selects further deployments, matching deploymentSelector or any of these
+	// +optional
+	DeploymentSelectors []metav1.LabelSelector `json:"deploymentSelectors,omitempty"`
+
 	// StatefulSetSelector selects the statefulsets to manage VPAs for
 	// +optional
 	StatefulSetSelector *metav1.LabelSelector `json:"statefulSetSelector,omitempty"`
 
+	// StatefulSetSelectors selects further statefulsets, matching statefulSetSelector or any of these
+	// +optional
+	StatefulSetSelectors []metav1.LabelSelector `json:"statefulSetSelectors,omitempty"`
+
 	// DaemonSetSelector selects the daemonsets to manage VPAs for
 	// +optional
 	DaemonSetSelector *metav1.LabelSelector `json:"daemonSetSelector,omitempty"`
 
+	// DaemonSetSelectors selects further daemonsets, matching daemonSetSelector or any of these
+	// +optional
+	DaemonSetSelectors []metav1.LabelSelector `json:"daemonSetSelectors,omitempty"`
+
+	// WorkloadNamePatterns narrows the workloads of every kind by name with regular
+	// expressions, in addition to the label selectors. Combine it with MatchAllWorkloads
+	// to select workloads without consistent labels by name alone.
+	// +optional
+	WorkloadNamePatterns *NamePatterns `json:"workloadNamePatterns,omitempty"`
+
 	// ResourcePolicy defines the resource policy for the VPA
 	// +optional
 	ResourcePolicy *ResourcePolicy `json:"resourcePolicy,omitempty"`
+
+	// DefaultControlledResources lists the resources the generated VPAs set requests for
+	// in containers whose policy lists no controlledResources of its own, e.g. ["cpu"] to
+	// roll out CPU autoscaling before the riskier memory resizing. Defaults to all resources.
+	// +optional
+	// +kubebuilder:validation:items:Enum=cpu;memory
+	DefaultControlledResources []string `json:"defaultControlledResources,omitempty"`
+
+	// ExcludeWellKnownSidecars turns VPA scaling Off for service mesh and secret injection
+	// sidecars (istio-proxy, linkerd-proxy, envoy, vault-agent) found in the pod template,
+	// which are sized by their injector. Containers with a policy of their own are kept.
+	// +optional
+	ExcludeWellKnownSidecars bool `json:"excludeWellKnownSidecars,omitempty"`
+
+	// InheritFrom names a parent VpaManager whose spec this one extends. Fields omitted
+	// here are taken from the parent, container policies are merged by container name
+	// and propagated annotations are combined. Enabled is never inherited, so a disabled
+	// parent can serve as a shared base policy.
+	// +optional
+	InheritFrom string `json:"inheritFrom,omitempty"`
+
+	// ProfileRef points at a profile ConfigMap maintained by the platform team. Its
+	// profile.yaml key holds a partial spec that fills the fields omitted here, so
+	// cluster-wide tuning is changed in one place.
+	// +optional
+	ProfileRef *ProfileReference `json:"profileRef,omitempty"`
+
+	// VPANameTemplate is a Go template naming the VPA of each workload, evaluated against
+	// the workload's .Kind, .Name and .Namespace with the lower and upper functions, e.g.
+	// "{{ .Kind | lower }}-{{ .Name }}-vpa". Defaults to "<name>-vpa".
+	// +optional
+	VPANameTemplate string `json:"vpaNameTemplate,omitempty"`
+
+	// PropagateAnnotations is an allow-list of workload annotation keys (e.g. team,
+	// service-tier, change-ticket IDs) copied onto the generated VPA. The source
+	// workload UID is always recorded in the vpa-operator.io/source-uid annotation.
+	// +optional
+	PropagateAnnotations []string `json:"propagateAnnotations,omitempty"`
+
+	// VPALabels are set on every generated VPA, e.g. for chargeback tooling. They never
+	// override the labels marking the operator's VPAs.
+	// +optional
+	VPALabels map[string]string `json:"vpaLabels,omitempty"`
+
+	// VPAAnnotations are set on every generated VPA, e.g. team or cost-center annotations.
+	// Keys under vpa-operator.io/ are reserved for the operator.
+	// +optional
+	VPAAnnotations map[string]string `json:"vpaAnnotations,omitempty"`
+
+	// Recommenders routes generated VPAs to specific VPA recommenders, e.g. a
+	// GPU-aware one. The default recommender is used when empty.
+	// +optional
+	Recommenders []RecommenderSelector `json:"recommenders,omitempty"`
+
+	// RecommendationTuning passes tuning settings to the recommender as
+	// recommender.vpa-operator.io/ annotations on generated VPAs. The default VPA
+	// recommender ignores them; use it with recommenders that read them.
+	// +optional
+	RecommendationTuning *RecommendationTuning `json:"recommendationTuning,omitempty"`
+
+	// ContainerPolicyMerge decides what happens to hand-tuned container policies when an
+	// existing VPA, e.g. an adopted one, is updated. OperatorWins (the default) replaces
+	// them, ExistingWins keeps them instead of the generated ones, and MergeByContainerName
+	// keeps them and adds generated policies for the other containers.
+	// +kubebuilder:validation:Enum=OperatorWins;ExistingWins;MergeByContainerName
+	// +optional
+	ContainerPolicyMerge string `json:"containerPolicyMerge,omitempty"`
+
+	// Dormancy switches the VPAs of workloads scaled to zero replicas to updateMode Off
+	// once they stayed idle long enough, so idle workloads do not act on meaningless
+	// recommendations. The updateMode is restored when the workload scales up.
+	// Disabled when unset.
+	// +optional
+	Dormancy *DormancyPolicy `json:"dormancy,omitempty"`
+
+	// MaxAllowedFromQuota caps maxAllowed of generated VPAs at a share of the hard
+	// limits of the namespace's ResourceQuotas, so no single workload is recommended
+	// past a fraction of its tenant's quota. Tighter maxAllowed bounds are kept.
+	// +optional
+	MaxAllowedFromQuota *QuotaFraction `json:"maxAllowedFromQuota,omitempty"`
+}
+
+// QuotaFraction is the share of a namespace's ResourceQuota one container may be
+// recommended. Values are decimal strings greater than 0 and at most 1.
+type QuotaFraction struct {
+	// CPUFraction is the share of the namespace CPU quota, e.g. "0.25"
+	// +kubebuilder:validation:Pattern=`^(0(\.[0-9]+)?|1(\.0+)?)$`
+	// +optional
+	CPUFraction string `json:"cpuFraction,omitempty"`
+
+	// MemoryFraction is the share of the namespace memory quota, e.g. "0.25"
+	// +kubebuilder:validation:Pattern=`^(0(\.[0-9]+)?|1(\.0+)?)$`
+	// +optional
+	MemoryFraction string `json:"memoryFraction,omitempty"`
+}
+
+// ProfileReference identifies a profile ConfigMap
+type ProfileReference struct {
+	// Namespace is the namespace of the ConfigMap
+	// +kubebuilder:validation:MinLength=1
+	Namespace string `json:"namespace"`
+
+	// Name is the name of the ConfigMap
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+}
+
+// UpdatePolicy holds settings rendered into the updatePolicy of generated VPAs
+type UpdatePolicy struct {
+	// MinReplicas is the minimum number of live replicas the VPA updater requires before
+	// it evicts a pod of the target. 2 makes sure pods of single-replica workloads are
+	// never evicted. The updater's --min-replicas flag applies when unset.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MinReplicas *int32 `json:"minReplicas,omitempty"`
+}
+
+// UpdateModesByKind holds an update mode per workload kind
+type UpdateModesByKind struct {
+	// Deployment is the update mode of the VPAs of Deployments
+	// +kubebuilder:validation:Enum=Off;Initial;Auto
+	// +optional
+	Deployment string `json:"deployment,omitempty"`
+
+	// StatefulSet is the update mode of the VPAs of StatefulSets
+	// +kubebuilder:validation:Enum=Off;Initial;Auto
+	// +optional
+	StatefulSet string `json:"statefulset,omitempty"`
+
+	// DaemonSet is the update mode of the VPAs of DaemonSets
+	// +kubebuilder:validation:Enum=Off;Initial;Auto
+	// +optional
+	DaemonSet string `json:"daemonset,omitempty"`
+}
+
+// ForKind returns the update mode set for workloads of kind, empty when there is none
+func (m *UpdateModesByKind) ForKind(kind string) string {
+	if m == nil {
+		return ""
+	}
+	switch kind {
+	case "Deployment":
+		return m.Deployment
+	case "StatefulSet":
+		return m.StatefulSet
+	case "DaemonSet":
+		return m.DaemonSet
+	default:
+		return ""
+	}
+}
+
+// DormancyPolicy configures how VPAs of workloads scaled to zero replicas are handled
+type DormancyPolicy struct {
+	// After is how long a workload must stay at zero replicas before its VPA is
+	// switched to updateMode Off, e.g. "6h"
+	After metav1.Duration `json:"after"`
+}
+
+// Strategies of VpaManagerSpec.ContainerPolicyMerge
+const (
+	ContainerPolicyMergeOperatorWins    = "OperatorWins"
+	ContainerPolicyMergeExistingWins    = "ExistingWins"
+	ContainerPolicyMergeByContainerName = "MergeByContainerName"
+)
+
+// RecommendationTuning holds per-VPA settings for recommender variants. Values are
+// decimal strings, since the VPA API has no per-object fields for them.
+type RecommendationTuning struct {
+	// TargetCPUPercentile is the usage percentile the CPU target is based on, e.g. "0.9"
+	// +kubebuilder:validation:Pattern=`^(0(\.[0-9]+)?|1(\.0+)?)$`
+	// +optional
+	TargetCPUPercentile string `json:"targetCPUPercentile,omitempty"`
+
+	// TargetMemoryPercentile is the usage percentile the memory target is based on, e.g. "0.95"
+	// +kubebuilder:validation:Pattern=`^(0(\.[0-9]+)?|1(\.0+)?)$`
+	// +optional
+	TargetMemoryPercentile string `json:"targetMemoryPercentile,omitempty"`
+
+	// SafetyMarginFraction is the fraction added on top of the recommendation, e.g. "0.15"
+	// +kubebuilder:validation:Pattern=`^[0-9]+(\.[0-9]+)?$`
+	// +optional
+	SafetyMarginFraction string `json:"safetyMarginFraction,omitempty"`
+
+	// Parameters are further recommender-specific settings, each set as the
+	// recommender.vpa-operator.io/<name> annotation
+	// +optional
+	Parameters map[string]string `json:"parameters,omitempty"`
+}
+
+// NamePatterns selects names with regular expressions, which must match the whole name
+type NamePatterns struct {
+	// Include selects names matching any of these; empty selects every name
+	// +optional
+	Include []string `json:"include,omitempty"`
+
+	// Exclude drops names matching any of these, even when they are included
+	// +optional
+	Exclude []string `json:"exclude,omitempty"`
+}
+
+// RecommenderSelector names a VPA recommender that should handle a generated VPA
+type RecommenderSelector struct {
+	// Name is the name of the recommender, as passed to its --recommender-name flag
+	Name string `json:"name"`
 }
 
 // ResourcePolicy defines the resource policy for VPAs
 type ResourcePolicy struct {
-	// ContainerPolicies is a list of resource policies for containers
+	// ContainerPolicies is a list of resource policies for containers. Init containers
+	// are matched by name and by glob pattern like other containers, but not by "*".
 	ContainerPolicies []ContainerResourcePolicy `json:"containerPolicies,omitempty"`
+
+	// IgnoreInitContainers turns VPA scaling Off for every init container of the pod
+	// template that has no policy of its own
+	// +optional
+	IgnoreInitContainers bool `json:"ignoreInitContainers,omitempty"`
 }
 
 // ContainerResourcePolicy defines the resource policy for a container
 type ContainerResourcePolicy struct {
-	// ContainerName is the name of the container
+	// ContainerName is the name of the container, "*" for every container without a
+	// policy of its own, or a glob pattern such as "*-sidecar" that is expanded into one
+	// policy per matching container of the workload
 	ContainerName string `json:"containerName,omitempty"`
 
-	// MinAllowed is the minimum amount of resources allowed
-	MinAllowed map[string]string `json:"minAllowed,omitempty"`
+	// MinAllowed is the minimum amount of resources allowed. Values may be templates
+	// evaluated per workload, e.g. "{{ .Requests.memory | divide 2 }}".
+	MinAllowed ResourceBounds `json:"minAllowed,omitempty"`
+
+	// MaxAllowed is the maximum amount of resources allowed. Values may be templates
+	// evaluated per workload, e.g. "{{ .Requests.memory | multiply 2 }}".
+	MaxAllowed ResourceBounds `json:"maxAllowed,omitempty"`
+
+	// Limits bounds the resource limits the VPA sets. The VPA API only bounds requests
+	// and scales limits in proportion to them, so these bounds are converted into request
+	// bounds through each container's limit to request ratio. They do not apply to
+	// resources a container has no limit for. The tighter of a converted bound and
+	// MinAllowed or MaxAllowed wins.
+	// +optional
+	Limits *LimitBounds `json:"limits,omitempty"`
+
+	// ControlledResources lists the resources the VPA sets requests for, e.g. ["memory"]
+	// to leave CPU to a HorizontalPodAutoscaler. Defaults to all resources.
+	// +optional
+	// +kubebuilder:validation:items:Enum=cpu;memory
+	ControlledResources []string `json:"controlledResources,omitempty"`
+
+	// ControlledValues selects whether the VPA scales limits along with requests
+	// (RequestsAndLimits, the VPA default) or leaves limits unchanged (RequestsOnly)
+	// +optional
+	// +kubebuilder:validation:Enum=RequestsAndLimits;RequestsOnly
+	ControlledValues string `json:"controlledValues,omitempty"`
+
+	// Mode selects whether the VPA scales the container (Auto, the VPA default) or leaves
+	// it alone (Off)
+	// +optional
+	// +kubebuilder:validation:Enum=Auto;Off
+	Mode string `json:"mode,omitempty"`
+}
+
+// Values of ContainerResourcePolicy.ControlledValues
+const (
+	ControlledValuesRequestsAndLimits = "RequestsAndLimits"
+	ControlledValuesRequestsOnly      = "RequestsOnly"
+)
+
+// Values of ContainerResourcePolicy.Mode
+const (
+	ContainerScalingModeAuto = "Auto"
+	ContainerScalingModeOff  = "Off"
+)
+
+// LimitBounds bounds the resource limits of a container. Values are literal quantities.
+type LimitBounds struct {
+	// MinAllowed is the minimum limit allowed per resource
+	// +optional
+	MinAllowed ResourceBounds `json:"minAllowed,omitempty"`
+
+	// MaxAllowed is the maximum limit allowed per resource
+	// +optional
+	MaxAllowed ResourceBounds `json:"maxAllowed,omitempty"`
+}
+
+// ResourceBounds maps resource names to quantities. Besides cpu and memory it may hold
+// hugepages-<size> and extended resources such as nvidia.com/gpu, which are passed to
+// the VPA unchanged for custom recommenders to act on.
+type ResourceBounds map[string]string
 
-	// MaxAllowed is the maximum amount of resources allowed
-	MaxAllowed map[string]string `json:"maxAllowed,omitempty"`
+// UnmarshalJSON accepts integer quantities as well as strings, since the schema allows
+// both and extended resources are commonly written unquoted (nvidia.com/gpu: 1)
+func (b *ResourceBounds) UnmarshalJSON(data []byte) error {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	if raw == nil {
+		*b = nil
+		return nil
+	}
+	out := make(ResourceBounds, len(raw))
+	for name, value := range raw {
+		var quantity string
+		if err := json.Unmarshal(value, &quantity); err != nil {
+			var number json.Number
+			if err := json.Unmarshal(value, &number); err != nil {
+				return err
+			}
+			quantity = number.String()
+		}
+		out[name] = quantity
+	}
+	*b = out
+	return nil
 }
 
 // WorkloadReference contains information about a workload (Deployment, StatefulSet, or DaemonSet) with a VPA
@@ -76,32 +442,466 @@ type WorkloadReference struct {
 // Deprecated: Use WorkloadReference instead
 type DeploymentReference = WorkloadReference
 
+// VPARejection records a VPA write that was rejected by an admission webhook
+type VPARejection struct {
+	// Kind is the type of workload (Deployment, StatefulSet, DaemonSet)
+	Kind string `json:"kind"`
+
+	// Name is the name of the workload
+	Name string `json:"name"`
+
+	// Namespace is the namespace of the workload
+	Namespace string `json:"namespace"`
+
+	// Message is the rejection message returned by the API server, truncated
+	Message string `json:"message"`
+
+	// Time is when the rejection was observed
+	Time metav1.Time `json:"time"`
+}
+
+// SkippedWorkload records a selected workload the operator deliberately did not manage a
+// VPA for, and why
+type SkippedWorkload struct {
+	// Kind is the type of workload (Deployment, StatefulSet, DaemonSet)
+	Kind string `json:"kind"`
+
+	// Name is the name of the workload
+	Name string `json:"name"`
+
+	// Namespace is the namespace of the workload
+	Namespace string `json:"namespace"`
+
+	// Reason is why the workload was skipped, e.g. ExcludedByAnnotation
+	Reason string `json:"reason"`
+}
+
+// MaxForbiddenNamespaces bounds the number of entries kept in VpaManagerStatus.ForbiddenNamespaces
+const MaxForbiddenNamespaces = 50
+
+// MaxPausedNamespaces bounds the number of entries kept in VpaManagerStatus.PausedNamespaces
+const MaxPausedNamespaces = 50
+
+// MaxWorkloadReferences bounds the number of entries kept in each managed workload list of VpaManagerStatus
+const MaxWorkloadReferences = 1000
+
+// MaxRejectedVPAs bounds the number of entries kept in VpaManagerStatus.RejectedVPAs
+const MaxRejectedVPAs = 10
+
+// MaxSkippedWorkloads bounds the number of entries kept in VpaManagerStatus.SkippedWorkloads
+const MaxSkippedWorkloads = 20
+
+// MaxSpecHistory bounds the number of entries kept in VpaManagerStatus.SpecHistory
+const MaxSpecHistory = 5
+
+// SpecRevision is a spec a VpaManager was reconciled with, and its effect
+type SpecRevision struct {
+	// Generation is the metadata.generation of the spec
+	Generation int64 `json:"generation"`
+
+	// Hash identifies the spec. A re-applied spec has the hash of its earlier revision.
+	Hash string `json:"hash"`
+
+	// Spec is the VpaManager spec as JSON, as written and before inheritance
+	Spec string `json:"spec"`
+
+	// FirstReconcileTime is when the spec was first reconciled
+	FirstReconcileTime metav1.Time `json:"firstReconcileTime"`
+
+	// ManagedVPAs is the number of VPAs managed under this spec at its last reconciliation
+	ManagedVPAs int `json:"managedVPAs"`
+
+	// LastError is the error of the last reconciliation under this spec, empty when it succeeded
+	// +optional
+	LastError string `json:"lastError,omitempty"`
+}
+
+// Condition types of VpaManagerStatus.Conditions
+const (
+	// ConditionReady is True when the last reconciliation brought the selected workloads' VPAs
+	// in line with the spec. It is shown in the Ready column of kubectl get vpamanagers.
+	ConditionReady = "Ready"
+
+	// ConditionHealthy is True when the last reconciliation succeeded without conflicts.
+	// It is shown in the Healthy column of kubectl get vpamanagers.
+	ConditionHealthy = "Healthy"
+
+	// ConditionForeignInstanceConflict is True when another operator instance generated the
+	// VPA of workloads this VpaManager selects. Those VPAs are neither updated nor deleted.
+	ConditionForeignInstanceConflict = "ForeignInstanceConflict"
+
+	// ConditionClusterCapacityReached is True when selected workloads got no VPA because the
+	// operator already manages its cluster-wide maximum of VPAs
+	ConditionClusterCapacityReached = "ClusterCapacityReached"
+
+	// ConditionDegraded is True when the operator keeps working but needs attention, e.g.
+	// the webhook serving certificate is about to expire without having been renewed
+	ConditionDegraded = "Degraded"
+
+	// ConditionConflicting is True when other enabled VpaManagers select workloads this
+	// VpaManager selects. The VpaManager with the highest priority manages each of them.
+	ConditionConflicting = "Conflicting"
+
+	// ConditionVPACRDMissing is True while the API server does not serve VerticalPodAutoscalers,
+	// so no VPA can be created until the VPA CRD is installed
+	ConditionVPACRDMissing = "VPACRDMissing"
+)
+
 // VpaManagerStatus defines the observed state of VpaManager
 type VpaManagerStatus struct {
 	// ManagedVPAs is the total number of VPAs managed by this operator
 	ManagedVPAs int `json:"managedVPAs"`
 
-	// ManagedDeployments is a list of deployments that have VPAs
-	// Deprecated: Use ManagedWorkloads instead. Will be removed in v1.
+	// ManagedDeployments lists the deployments that have VPAs, and only deployments.
+	// Populated only when the operator runs with --record-workload-lists, capped at
+	// MaxWorkloadReferences entries. Use AllManagedWorkloads to read every kind.
+	// Deprecated: Use the count fields instead. Will be removed in v1.
 	// +optional
 	ManagedDeployments []WorkloadReference `json:"managedDeployments,omitempty"`
 
-	// ManagedWorkloads is a list of all workloads that have VPAs
+	// ManagedStatefulSets lists the statefulsets that have VPAs. Populated only when
+	// the operator runs with --record-workload-lists, capped at MaxWorkloadReferences entries.
+	// +optional
+	ManagedStatefulSets []WorkloadReference `json:"managedStatefulSets,omitempty"`
+
+	// ManagedDaemonSets lists the daemonsets that have VPAs. Populated only when
+	// the operator runs with --record-workload-lists, capped at MaxWorkloadReferences entries.
+	// +optional
+	ManagedDaemonSets []WorkloadReference `json:"managedDaemonSets,omitempty"`
+
+	// ManagedWorkloads is a list of all workloads that have VPAs. Populated only when
+	// the operator runs with --record-workload-lists, capped at MaxWorkloadReferences entries.
 	// Deprecated: This field is expensive at scale. Use count fields instead.
 	// +optional
 	ManagedWorkloads []WorkloadReference `json:"managedWorkloads,omitempty"`
 
-	// DeploymentCount is the number of deployments with managed VPAs
-	DeploymentCount int `json:"deploymentCount,omitempty"`
+	// DeploymentCount is the number of deployments with managed VPAs. Set on every
+	// reconciliation, including zero, so the printer column is never empty.
+	DeploymentCount int `json:"deploymentCount"`
 
 	// StatefulSetCount is the number of statefulsets with managed VPAs
-	StatefulSetCount int `json:"statefulSetCount,omitempty"`
+	StatefulSetCount int `json:"statefulSetCount"`
 
 	// DaemonSetCount is the number of daemonsets with managed VPAs
-	DaemonSetCount int `json:"daemonSetCount,omitempty"`
+	DaemonSetCount int `json:"daemonSetCount"`
+
+	// DriftedVPAs is the number of GitOps-managed VPAs (Argo CD, Flux) whose spec
+	// differs from what the operator would generate. These VPAs are never overwritten.
+	// +optional
+	DriftedVPAs int `json:"driftedVPAs,omitempty"`
+
+	// DormantVPAs is the number of managed VPAs switched to updateMode Off because their
+	// workload stayed scaled to zero replicas, see spec.dormancy
+	// +optional
+	DormantVPAs int `json:"dormantVPAs,omitempty"`
+
+	// ForeignVPAs is the number of selected workloads whose VPA was generated by another
+	// operator instance, identified by its ownership label or instance ID. These VPAs are
+	// left untouched; see the ForeignInstanceConflict condition.
+	// +optional
+	ForeignVPAs int `json:"foreignVPAs,omitempty"`
+
+	// ConflictingWorkloads is the number of selected workloads that other enabled
+	// VpaManagers select too, whichever of them manages the workload; see the Conflicting
+	// condition
+	// +optional
+	ConflictingWorkloads int `json:"conflictingWorkloads,omitempty"`
+
+	// RightsizingScore is a 0-100 score of how closely container requests match VPA
+	// target recommendations across managed workloads, weighted by request size.
+	// Unset until at least one managed VPA has a recommendation.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=100
+	// +optional
+	RightsizingScore *int `json:"rightsizingScore,omitempty"`
+
+	// RejectedVPAs lists workloads whose VPA was rejected by an admission webhook
+	// during the last reconciliation, capped at MaxRejectedVPAs entries.
+	// +kubebuilder:validation:MaxItems=10
+	// +optional
+	RejectedVPAs []VPARejection `json:"rejectedVPAs,omitempty"`
+
+	// SkippedWorkloads samples the selected workloads the last reconciliation deliberately
+	// did not manage a VPA for, with the reason, capped at MaxSkippedWorkloads entries.
+	// vpa_operator_workloads_skipped_total counts all of them by reason.
+	// +kubebuilder:validation:MaxItems=20
+	// +optional
+	SkippedWorkloads []SkippedWorkload `json:"skippedWorkloads,omitempty"`
+
+	// ForbiddenNamespaces lists matching namespaces, sorted, where the operator was
+	// denied permission to list workloads during the last reconciliation, capped at
+	// MaxForbiddenNamespaces entries. VPAs in these namespaces are left untouched.
+	// +kubebuilder:validation:MaxItems=50
+	// +optional
+	ForbiddenNamespaces []string `json:"forbiddenNamespaces,omitempty"`
+
+	// PausedNamespaces lists matching namespaces, sorted, that carry the
+	// vpa-operator.io/paused: "true" annotation, capped at MaxPausedNamespaces
+	// entries. VPAs in these namespaces are kept but neither created, updated nor deleted.
+	// +kubebuilder:validation:MaxItems=50
+	// +optional
+	PausedNamespaces []string `json:"pausedNamespaces,omitempty"`
+
+	// OperatorWorkloadExcluded names the operator's own workload (Kind namespace/name)
+	// when it matched this VpaManager's selectors during the last reconciliation. The
+	// operator never manages itself, since Auto-mode evictions would interrupt reconciling.
+	// +optional
+	OperatorWorkloadExcluded string `json:"operatorWorkloadExcluded,omitempty"`
+
+	// PendingOrphanDeletions is the number of orphaned VPAs held back because
+	// deleting them at once would exceed the operator's burst limit
+	// +optional
+	PendingOrphanDeletions int `json:"pendingOrphanDeletions,omitempty"`
+
+	// OrphanDeletionsBlockedSince is when the pending orphan deletions were first
+	// held back. They proceed once the grace period has elapsed or the deletion is
+	// confirmed with the vpa-operator.io/confirm-orphan-deletion annotation.
+	// +optional
+	OrphanDeletionsBlockedSince *metav1.Time `json:"orphanDeletionsBlockedSince,omitempty"`
+
+	// Progress tracks a pass over the matching namespaces that was split into chunks
+	// because it exceeded the operator's reconcile budget. The other status fields
+	// describe the last completed pass until this one completes. Unset between passes.
+	// +optional
+	Progress *ReconcileProgress `json:"progress,omitempty"`
+
+	// SpecHistory lists the specs this VpaManager was reconciled with, oldest first,
+	// capped at MaxSpecHistory entries. kubectl vpamgr rollback-manager re-applies one.
+	// +kubebuilder:validation:MaxItems=5
+	// +optional
+	SpecHistory []SpecRevision `json:"specHistory,omitempty"`
+
+	// LastError summarizes the most recent reconcile failure as "<error_type>: <message>",
+	// truncated. It is kept after later successful reconciles; compare LastErrorTime
+	// with LastReconcileTime to tell whether it is still current.
+	// +optional
+	LastError string `json:"lastError,omitempty"`
+
+	// LastErrorTime is when LastError was observed
+	// +optional
+	LastErrorTime *metav1.Time `json:"lastErrorTime,omitempty"`
 
 	// LastReconcileTime is the last time the operator reconciled
 	LastReconcileTime *metav1.Time `json:"lastReconcileTime,omitempty"`
+
+	// ObservedGeneration is the generation of the spec the last successful reconciliation
+	// completed a pass for. The Ready condition is only True while it matches
+	// metadata.generation.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Conditions describe the observed state of the VpaManager
+	// +listType=map
+	// +listMapKey=type
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// ReconcileProgress is the state of a pass over the matching namespaces that continues
+// across requeues. The tallies cover the chunks processed so far.
+type ReconcileProgress struct {
+	// Continue is the position of the next namespace to process, "<priority>/<name>"
+	Continue string `json:"continue"`
+
+	// ObservedGeneration is the VpaManager generation the pass started at. A spec change
+	// restarts the pass.
+	ObservedGeneration int64 `json:"observedGeneration"`
+
+	// StartTime is when the pass started
+	StartTime metav1.Time `json:"startTime"`
+
+	// Chunks is the number of chunks processed so far
+	Chunks int `json:"chunks"`
+
+	// ManagedVPAs is the number of VPAs managed so far
+	ManagedVPAs int `json:"managedVPAs"`
+
+	// DeploymentCount is the number of deployments with managed VPAs so far
+	DeploymentCount int `json:"deploymentCount"`
+
+	// StatefulSetCount is the number of statefulsets with managed VPAs so far
+	StatefulSetCount int `json:"statefulSetCount"`
+
+	// DaemonSetCount is the number of daemonsets with managed VPAs so far
+	DaemonSetCount int `json:"daemonSetCount"`
+
+	// WatchedWorkloads is the number of selected workloads so far
+	WatchedWorkloads int `json:"watchedWorkloads"`
+
+	// DriftedVPAs is the number of drifted GitOps-managed VPAs so far
+	// +optional
+	DriftedVPAs int `json:"driftedVPAs,omitempty"`
+
+	// DormantVPAs is the number of dormant VPAs so far
+	// +optional
+	DormantVPAs int `json:"dormantVPAs,omitempty"`
+
+	// ForeignVPAs is the number of VPAs of other operator instances so far
+	// +optional
+	ForeignVPAs int `json:"foreignVPAs,omitempty"`
+
+	// ConflictingWorkloads is the number of workloads other VpaManagers select too so far
+	// +optional
+	ConflictingWorkloads int `json:"conflictingWorkloads,omitempty"`
+
+	// OrphanedVPAs is the number of orphaned VPAs found so far. The orphan burst limit
+	// applies to the whole pass, not to each chunk.
+	// +optional
+	OrphanedVPAs int `json:"orphanedVPAs,omitempty"`
+
+	// PendingOrphanDeletions is the number of orphaned VPAs held back so far
+	// +optional
+	PendingOrphanDeletions int `json:"pendingOrphanDeletions,omitempty"`
+
+	// ForbiddenNamespaces lists the namespaces where listing workloads was forbidden so far
+	// +kubebuilder:validation:MaxItems=50
+	// +optional
+	ForbiddenNamespaces []string `json:"forbiddenNamespaces,omitempty"`
+
+	// PausedNamespaces lists the paused namespaces skipped so far
+	// +kubebuilder:validation:MaxItems=50
+	// +optional
+	PausedNamespaces []string `json:"pausedNamespaces,omitempty"`
+}
+
+// Outranks reports whether m takes precedence over other for a workload both select: the
+// higher Priority wins, and the name sorting first breaks ties
+func (m *VpaManager) Outranks(other *VpaManager) bool {
+	if m.Spec.Priority != other.Spec.Priority {
+		return m.Spec.Priority > other.Spec.Priority
+	}
+	return m.Name < other.Name
+}
+
+// SortByPriority sorts vpaManagers so that each one outranks those after it
+func SortByPriority(vpaManagers []VpaManager) {
+	sort.Slice(vpaManagers, func(i, j int) bool {
+		return vpaManagers[i].Outranks(&vpaManagers[j])
+	})
+}
+
+// ListsNamespace reports whether Namespaces lists the namespace name
+func (s *VpaManagerSpec) ListsNamespace(name string) bool {
+	return slices.Contains(s.Namespaces, name)
+}
+
+// ExcludesNamespace reports whether ExcludeNamespaces lists the namespace name
+func (s *VpaManagerSpec) ExcludesNamespace(name string) bool {
+	return slices.Contains(s.ExcludeNamespaces, name)
+}
+
+// NamespaceSelectorTerms returns NamespaceSelector followed by NamespaceSelectors. A namespace
+// is selected by labels when it matches any of them; the result is empty when none is set.
+func (s *VpaManagerSpec) NamespaceSelectorTerms() []*metav1.LabelSelector {
+	return selectorTerms(s.NamespaceSelector, s.NamespaceSelectors)
+}
+
+// WorkloadSelectorTerms returns the selector and the further selectors of a workload kind.
+// A workload is selected when it matches any of them; the result is empty when none is set.
+func (s *VpaManagerSpec) WorkloadSelectorTerms(kind string) []*metav1.LabelSelector {
+	switch kind {
+	case "Deployment":
+		return selectorTerms(s.DeploymentSelector, s.DeploymentSelectors)
+	case "StatefulSet":
+		return selectorTerms(s.StatefulSetSelector, s.StatefulSetSelectors)
+	case "DaemonSet":
+		return selectorTerms(s.DaemonSetSelector, s.DaemonSetSelectors)
+	}
+	return nil
+}
+
+// selectorTerms lists single, when set, and every entry of list
+func selectorTerms(single *metav1.LabelSelector, list []metav1.LabelSelector) []*metav1.LabelSelector {
+	if single == nil && len(list) == 0 {
+		return nil
+	}
+	terms := make([]*metav1.LabelSelector, 0, len(list)+1)
+	if single != nil {
+		terms = append(terms, single)
+	}
+	for i := range list {
+		terms = append(terms, &list[i])
+	}
+	return terms
+}
+
+// SetManagedWorkloads records refs in ManagedWorkloads and in the per-kind lists, each
+// sorted by namespace and name and capped at MaxWorkloadReferences entries. A nil refs
+// clears all lists.
+func (s *VpaManagerStatus) SetManagedWorkloads(refs []WorkloadReference) {
+	s.ManagedDeployments, s.ManagedStatefulSets, s.ManagedDaemonSets, s.ManagedWorkloads = nil, nil, nil, nil
+	if refs == nil {
+		return
+	}
+
+	sorted := append([]WorkloadReference(nil), refs...)
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Namespace != sorted[j].Namespace {
+			return sorted[i].Namespace < sorted[j].Namespace
+		}
+		if sorted[i].Name != sorted[j].Name {
+			return sorted[i].Name < sorted[j].Name
+		}
+		return sorted[i].Kind < sorted[j].Kind
+	})
+
+	for _, ref := range sorted {
+		appendCapped(&s.ManagedWorkloads, ref)
+		switch referenceKind(ref) {
+		case "Deployment":
+			appendCapped(&s.ManagedDeployments, ref)
+		case "StatefulSet":
+			appendCapped(&s.ManagedStatefulSets, ref)
+		case "DaemonSet":
+			appendCapped(&s.ManagedDaemonSets, ref)
+		}
+	}
+}
+
+// DeprecatedFieldsInUse returns the JSON paths of deprecated status fields that are populated
+func (s *VpaManagerStatus) DeprecatedFieldsInUse() []string {
+	var fields []string
+	if len(s.ManagedDeployments) > 0 {
+		fields = append(fields, "status.managedDeployments")
+	}
+	if len(s.ManagedWorkloads) > 0 {
+		fields = append(fields, "status.managedWorkloads")
+	}
+	return fields
+}
+
+// AllManagedWorkloads returns every workload reference recorded in status, without
+// duplicates. It also reads statuses written by older operator versions, whose
+// ManagedDeployments listed workloads of every kind, so existing consumers keep working.
+func (s *VpaManagerStatus) AllManagedWorkloads() []WorkloadReference {
+	var out []WorkloadReference
+	seen := map[WorkloadReference]bool{}
+	for _, list := range [][]WorkloadReference{s.ManagedWorkloads, s.ManagedDeployments, s.ManagedStatefulSets, s.ManagedDaemonSets} {
+		for _, ref := range list {
+			ref.Kind = referenceKind(ref)
+			if seen[ref] {
+				continue
+			}
+			seen[ref] = true
+			out = append(out, ref)
+		}
+	}
+	return out
+}
+
+// referenceKind returns the kind of ref; references written before Kind was recorded are Deployments
+func referenceKind(ref WorkloadReference) string {
+	if ref.Kind == "" {
+		return "Deployment"
+	}
+	return ref.Kind
+}
+
+// appendCapped appends ref unless list already holds MaxWorkloadReferences entries
+func appendCapped(list *[]WorkloadReference, ref WorkloadReference) {
+	if len(*list) < MaxWorkloadReferences {
+		*list = append(*list, ref)
+	}
 }
 
 // +kubebuilder:object:root=true
@@ -109,7 +909,13 @@ type VpaManagerStatus struct {
 // +kubebuilder:resource:scope=Cluster,shortName=vpa
 // +kubebuilder:printcolumn:name="Enabled",type="boolean",JSONPath=".spec.enabled"
 // +kubebuilder:printcolumn:name="UpdateMode",type="string",JSONPath=".spec.updateMode"
+// +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status"
+// +kubebuilder:printcolumn:name="Healthy",type="string",JSONPath=".status.conditions[?(@.type==\"Healthy\")].status"
 // +kubebuilder:printcolumn:name="ManagedVPAs",type="integer",JSONPath=".status.managedVPAs"
+// +kubebuilder:printcolumn:name="Deployments",type="integer",JSONPath=".status.deploymentCount"
+// +kubebuilder:printcolumn:name="StatefulSets",type="integer",JSONPath=".status.statefulSetCount"
+// +kubebuilder:printcolumn:name="DaemonSets",type="integer",JSONPath=".status.daemonSetCount"
+// +kubebuilder:printcolumn:name="Score",type="integer",JSONPath=".status.rightsizingScore"
 // +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 
 // VpaManager is the Schema for the vpamanagers API
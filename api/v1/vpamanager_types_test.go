@@ -0,0 +1,220 @@
+package v1
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestResourceBounds_UnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected ResourceBounds
+		wantErr  bool
+	}{
+		{name: "strings", input: `{"cpu":"100m","memory":"1Gi"}`, expected: ResourceBounds{"cpu": "100m", "memory": "1Gi"}},
+		{name: "integers", input: `{"nvidia.com/gpu":1,"cpu":2}`, expected: ResourceBounds{"nvidia.com/gpu": "1", "cpu": "2"}},
+		{name: "null", input: `null`},
+		{name: "invalid value", input: `{"cpu":true}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got ResourceBounds
+			err := json.Unmarshal([]byte(tt.input), &got)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got %v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(got) != len(tt.expected) {
+				t.Fatalf("got %v, want %v", got, tt.expected)
+			}
+			for name, value := range tt.expected {
+				if got[name] != value {
+					t.Errorf("%s = %q, want %q", name, got[name], value)
+				}
+			}
+		})
+	}
+}
+
+func TestContainerResourcePolicy_DecodesUnquotedExtendedResources(t *testing.T) {
+	var policy ContainerResourcePolicy
+	input := `{"containerName":"trainer","minAllowed":{"nvidia.com/gpu":1},"maxAllowed":{"nvidia.com/gpu":"4"}}`
+	if err := json.Unmarshal([]byte(input), &policy); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if policy.MinAllowed["nvidia.com/gpu"] != "1" || policy.MaxAllowed["nvidia.com/gpu"] != "4" {
+		t.Errorf("extended resources were not decoded: %+v", policy)
+	}
+}
+
+func TestContainerResourcePolicy_LimitsRoundTrip(t *testing.T) {
+	input := `{"containerName":"app","limits":{"minAllowed":{"cpu":"100m"},"maxAllowed":{"memory":"2Gi"}}}`
+	var policy ContainerResourcePolicy
+	if err := json.Unmarshal([]byte(input), &policy); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	copied := policy.DeepCopy()
+	policy.Limits.MaxAllowed["memory"] = "4Gi"
+	if copied.Limits.MaxAllowed["memory"] != "2Gi" {
+		t.Errorf("deep copy shares limit bounds: %+v", copied.Limits)
+	}
+	out, err := json.Marshal(copied)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(out) != input {
+		t.Errorf("round trip = %s, want %s", out, input)
+	}
+}
+
+func TestVpaManagerStatus_SetManagedWorkloads(t *testing.T) {
+	dep := WorkloadReference{Kind: "Deployment", Name: "web", Namespace: "b"}
+	sts := WorkloadReference{Kind: "StatefulSet", Name: "db", Namespace: "a"}
+	ds := WorkloadReference{Kind: "DaemonSet", Name: "agent", Namespace: "a"}
+	many := make([]WorkloadReference, MaxWorkloadReferences+1)
+	for i := range many {
+		many[i] = WorkloadReference{Kind: "Deployment", Name: fmt.Sprintf("dep-%04d", i), Namespace: "a"}
+	}
+
+	tests := []struct {
+		name string
+		refs []WorkloadReference
+		want VpaManagerStatus
+	}{
+		{name: "nil clears every list"},
+		{
+			name: "split by kind and sorted",
+			refs: []WorkloadReference{dep, sts, ds},
+			want: VpaManagerStatus{
+				ManagedWorkloads:    []WorkloadReference{ds, sts, dep},
+				ManagedDeployments:  []WorkloadReference{dep},
+				ManagedStatefulSets: []WorkloadReference{sts},
+				ManagedDaemonSets:   []WorkloadReference{ds},
+			},
+		},
+		{
+			name: "capped",
+			refs: many,
+			want: VpaManagerStatus{
+				ManagedWorkloads:   many[:MaxWorkloadReferences],
+				ManagedDeployments: many[:MaxWorkloadReferences],
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status := VpaManagerStatus{
+				ManagedDeployments: []WorkloadReference{{Name: "stale", Namespace: "old"}},
+				ManagedWorkloads:   []WorkloadReference{{Name: "stale", Namespace: "old"}},
+			}
+			status.SetManagedWorkloads(tt.refs)
+			if !reflect.DeepEqual(status, tt.want) {
+				t.Errorf("status = %+v, want %+v", status, tt.want)
+			}
+		})
+	}
+}
+
+func TestVpaManagerStatus_AllManagedWorkloads(t *testing.T) {
+	dep := WorkloadReference{Kind: "Deployment", Name: "web", Namespace: "a"}
+	sts := WorkloadReference{Kind: "StatefulSet", Name: "db", Namespace: "a"}
+
+	tests := []struct {
+		name   string
+		status VpaManagerStatus
+		want   []WorkloadReference
+	}{
+		{name: "empty"},
+		{
+			name:   "legacy status with every kind in managedDeployments",
+			status: VpaManagerStatus{ManagedDeployments: []WorkloadReference{dep, sts}},
+			want:   []WorkloadReference{dep, sts},
+		},
+		{
+			name:   "legacy reference without kind is a Deployment",
+			status: VpaManagerStatus{ManagedDeployments: []WorkloadReference{{Name: "web", Namespace: "a"}}},
+			want:   []WorkloadReference{dep},
+		},
+		{
+			name: "split lists are deduplicated",
+			status: VpaManagerStatus{
+				ManagedWorkloads:    []WorkloadReference{dep, sts},
+				ManagedDeployments:  []WorkloadReference{dep},
+				ManagedStatefulSets: []WorkloadReference{sts},
+			},
+			want: []WorkloadReference{dep, sts},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.status.AllManagedWorkloads(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("AllManagedWorkloads() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVpaManagerStatus_DeprecatedFieldsInUse(t *testing.T) {
+	dep := WorkloadReference{Kind: "Deployment", Name: "web", Namespace: "a"}
+	sts := WorkloadReference{Kind: "StatefulSet", Name: "db", Namespace: "a"}
+
+	tests := []struct {
+		name   string
+		status VpaManagerStatus
+		want   []string
+	}{
+		{name: "counts only", status: VpaManagerStatus{ManagedVPAs: 2, DeploymentCount: 2}},
+		{
+			name:   "per-kind lists are not deprecated",
+			status: VpaManagerStatus{ManagedStatefulSets: []WorkloadReference{sts}},
+		},
+		{
+			name: "recorded workload lists",
+			status: VpaManagerStatus{
+				ManagedWorkloads:   []WorkloadReference{dep},
+				ManagedDeployments: []WorkloadReference{dep},
+			},
+			want: []string{"status.managedDeployments", "status.managedWorkloads"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.status.DeprecatedFieldsInUse(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DeprecatedFieldsInUse() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSortByPriority(t *testing.T) {
+	manager := func(name string, priority int32) VpaManager {
+		vm := VpaManager{Spec: VpaManagerSpec{Priority: priority}}
+		vm.Name = name
+		return vm
+	}
+	managers := []VpaManager{manager("b", 0), manager("c", 5), manager("a", 0), manager("d", -1), manager("b2", 5)}
+
+	SortByPriority(managers)
+	var got []string
+	for _, vm := range managers {
+		got = append(got, vm.Name)
+	}
+	if want := []string{"b2", "c", "a", "b", "d"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("SortByPriority() = %v, want %v", got, want)
+	}
+	if managers[0].Outranks(&managers[0]) {
+		t.Error("a VpaManager must not outrank itself")
+	}
+}
@@ -0,0 +1,61 @@
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// VpaOverrideSpec defines the settings that replace those of the VpaManager for one workload
+type VpaOverrideSpec struct {
+	// TargetRef is the workload, in the namespace of the VpaOverride, whose VPA is overridden
+	TargetRef OverrideTargetReference `json:"targetRef"`
+
+	// UpdateMode replaces the update mode of the VpaManager for the workload
+	// +kubebuilder:validation:Enum=Off;Initial;Auto
+	// +optional
+	UpdateMode string `json:"updateMode,omitempty"`
+
+	// ResourcePolicy replaces the resource policy of the VpaManager for the workload.
+	// maxAllowedFromQuota is not applied on top of it.
+	// +optional
+	ResourcePolicy *ResourcePolicy `json:"resourcePolicy,omitempty"`
+}
+
+// OverrideTargetReference identifies a workload by kind and name
+type OverrideTargetReference struct {
+	// Kind is the kind of the workload
+	// +kubebuilder:validation:Enum=Deployment;StatefulSet;DaemonSet
+	Kind string `json:"kind"`
+
+	// Name is the name of the workload
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Namespaced,shortName=vpao
+// +kubebuilder:printcolumn:name="Kind",type="string",JSONPath=".spec.targetRef.kind"
+// +kubebuilder:printcolumn:name="Target",type="string",JSONPath=".spec.targetRef.name"
+// +kubebuilder:printcolumn:name="UpdateMode",type="string",JSONPath=".spec.updateMode"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// VpaOverride is the Schema for the vpaoverrides API. It overrides the update mode
+// and resource policy of the VPA of exactly one workload.
+type VpaOverride struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec VpaOverrideSpec `json:"spec,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// VpaOverrideList contains a list of VpaOverride
+type VpaOverrideList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []VpaOverride `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&VpaOverride{}, &VpaOverrideList{})
+}
@@ -12,18 +12,28 @@ func (in *ContainerResourcePolicy) DeepCopyInto(out *ContainerResourcePolicy) {
 	*out = *in
 	if in.MinAllowed != nil {
 		in, out := &in.MinAllowed, &out.MinAllowed
-		*out = make(map[string]string, len(*in))
+		*out = make(ResourceBounds, len(*in))
 		for key, val := range *in {
 			(*out)[key] = val
 		}
 	}
 	if in.MaxAllowed != nil {
 		in, out := &in.MaxAllowed, &out.MaxAllowed
-		*out = make(map[string]string, len(*in))
+		*out = make(ResourceBounds, len(*in))
 		for key, val := range *in {
 			(*out)[key] = val
 		}
 	}
+	if in.Limits != nil {
+		in, out := &in.Limits, &out.Limits
+		*out = new(LimitBounds)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ControlledResources != nil {
+		in, out := &in.ControlledResources, &out.ControlledResources
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ContainerResourcePolicy.
@@ -36,6 +46,35 @@ func (in *ContainerResourcePolicy) DeepCopy() *ContainerResourcePolicy {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LimitBounds) DeepCopyInto(out *LimitBounds) {
+	*out = *in
+	if in.MinAllowed != nil {
+		in, out := &in.MinAllowed, &out.MinAllowed
+		*out = make(ResourceBounds, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.MaxAllowed != nil {
+		in, out := &in.MaxAllowed, &out.MaxAllowed
+		*out = make(ResourceBounds, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LimitBounds.
+func (in *LimitBounds) DeepCopy() *LimitBounds {
+	if in == nil {
+		return nil
+	}
+	out := new(LimitBounds)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *WorkloadReference) DeepCopyInto(out *WorkloadReference) {
 	*out = *in
@@ -51,6 +90,176 @@ func (in *WorkloadReference) DeepCopy() *WorkloadReference {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DormancyPolicy) DeepCopyInto(out *DormancyPolicy) {
+	*out = *in
+	out.After = in.After
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DormancyPolicy.
+func (in *DormancyPolicy) DeepCopy() *DormancyPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(DormancyPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OverrideTargetReference) DeepCopyInto(out *OverrideTargetReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OverrideTargetReference.
+func (in *OverrideTargetReference) DeepCopy() *OverrideTargetReference {
+	if in == nil {
+		return nil
+	}
+	out := new(OverrideTargetReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProfileReference) DeepCopyInto(out *ProfileReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProfileReference.
+func (in *ProfileReference) DeepCopy() *ProfileReference {
+	if in == nil {
+		return nil
+	}
+	out := new(ProfileReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *QuotaFraction) DeepCopyInto(out *QuotaFraction) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuotaFraction.
+func (in *QuotaFraction) DeepCopy() *QuotaFraction {
+	if in == nil {
+		return nil
+	}
+	out := new(QuotaFraction)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReconcileProgress) DeepCopyInto(out *ReconcileProgress) {
+	*out = *in
+	in.StartTime.DeepCopyInto(&out.StartTime)
+	if in.ForbiddenNamespaces != nil {
+		in, out := &in.ForbiddenNamespaces, &out.ForbiddenNamespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.PausedNamespaces != nil {
+		in, out := &in.PausedNamespaces, &out.PausedNamespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReconcileProgress.
+func (in *ReconcileProgress) DeepCopy() *ReconcileProgress {
+	if in == nil {
+		return nil
+	}
+	out := new(ReconcileProgress)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RecommendationTuning) DeepCopyInto(out *RecommendationTuning) {
+	*out = *in
+	if in.Parameters != nil {
+		in, out := &in.Parameters, &out.Parameters
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RecommendationTuning.
+func (in *RecommendationTuning) DeepCopy() *RecommendationTuning {
+	if in == nil {
+		return nil
+	}
+	out := new(RecommendationTuning)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NamePatterns) DeepCopyInto(out *NamePatterns) {
+	*out = *in
+	if in.Include != nil {
+		in, out := &in.Include, &out.Include
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Exclude != nil {
+		in, out := &in.Exclude, &out.Exclude
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NamePatterns.
+func (in *NamePatterns) DeepCopy() *NamePatterns {
+	if in == nil {
+		return nil
+	}
+	out := new(NamePatterns)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RecommenderSelector) DeepCopyInto(out *RecommenderSelector) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RecommenderSelector.
+func (in *RecommenderSelector) DeepCopy() *RecommenderSelector {
+	if in == nil {
+		return nil
+	}
+	out := new(RecommenderSelector)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in ResourceBounds) DeepCopyInto(out *ResourceBounds) {
+	{
+		in := &in
+		*out = make(ResourceBounds, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceBounds.
+func (in ResourceBounds) DeepCopy() ResourceBounds {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceBounds)
+	in.DeepCopyInto(out)
+	return *out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ResourcePolicy) DeepCopyInto(out *ResourcePolicy) {
 	*out = *in
@@ -73,6 +282,72 @@ func (in *ResourcePolicy) DeepCopy() *ResourcePolicy {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SkippedWorkload) DeepCopyInto(out *SkippedWorkload) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SkippedWorkload.
+func (in *SkippedWorkload) DeepCopy() *SkippedWorkload {
+	if in == nil {
+		return nil
+	}
+	out := new(SkippedWorkload)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SpecRevision) DeepCopyInto(out *SpecRevision) {
+	*out = *in
+	in.FirstReconcileTime.DeepCopyInto(&out.FirstReconcileTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SpecRevision.
+func (in *SpecRevision) DeepCopy() *SpecRevision {
+	if in == nil {
+		return nil
+	}
+	out := new(SpecRevision)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UpdatePolicy) DeepCopyInto(out *UpdatePolicy) {
+	*out = *in
+	if in.MinReplicas != nil {
+		in, out := &in.MinReplicas, &out.MinReplicas
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UpdatePolicy.
+func (in *UpdatePolicy) DeepCopy() *UpdatePolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(UpdatePolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UpdateModesByKind) DeepCopyInto(out *UpdateModesByKind) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UpdateModesByKind.
+func (in *UpdateModesByKind) DeepCopy() *UpdateModesByKind {
+	if in == nil {
+		return nil
+	}
+	out := new(UpdateModesByKind)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VpaManager) DeepCopyInto(out *VpaManager) {
 	*out = *in
@@ -135,26 +410,138 @@ func (in *VpaManagerList) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VpaManagerSpec) DeepCopyInto(out *VpaManagerSpec) {
 	*out = *in
+	if in.UpdateModes != nil {
+		in, out := &in.UpdateModes, &out.UpdateModes
+		*out = new(UpdateModesByKind)
+		**out = **in
+	}
+	if in.UpdatePolicy != nil {
+		in, out := &in.UpdatePolicy, &out.UpdatePolicy
+		*out = new(UpdatePolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ProfileRef != nil {
+		in, out := &in.ProfileRef, &out.ProfileRef
+		*out = new(ProfileReference)
+		**out = **in
+	}
+	if in.PropagateAnnotations != nil {
+		in, out := &in.PropagateAnnotations, &out.PropagateAnnotations
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Recommenders != nil {
+		in, out := &in.Recommenders, &out.Recommenders
+		*out = make([]RecommenderSelector, len(*in))
+		copy(*out, *in)
+	}
+	if in.RecommendationTuning != nil {
+		in, out := &in.RecommendationTuning, &out.RecommendationTuning
+		*out = new(RecommendationTuning)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Dormancy != nil {
+		in, out := &in.Dormancy, &out.Dormancy
+		*out = new(DormancyPolicy)
+		**out = **in
+	}
+	if in.MaxAllowedFromQuota != nil {
+		in, out := &in.MaxAllowedFromQuota, &out.MaxAllowedFromQuota
+		*out = new(QuotaFraction)
+		**out = **in
+	}
 	if in.NamespaceSelector != nil {
 		in, out := &in.NamespaceSelector, &out.NamespaceSelector
 		*out = new(metav1.LabelSelector)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.NamespaceSelectors != nil {
+		in, out := &in.NamespaceSelectors, &out.NamespaceSelectors
+		*out = make([]metav1.LabelSelector, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Namespaces != nil {
+		in, out := &in.Namespaces, &out.Namespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ExcludeNamespaces != nil {
+		in, out := &in.ExcludeNamespaces, &out.ExcludeNamespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.NamespaceExcludeSelector != nil {
+		in, out := &in.NamespaceExcludeSelector, &out.NamespaceExcludeSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.DeploymentSelector != nil {
 		in, out := &in.DeploymentSelector, &out.DeploymentSelector
 		*out = new(metav1.LabelSelector)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.DeploymentSelectors != nil {
+		in, out := &in.DeploymentSelectors, &out.DeploymentSelectors
+		*out = make([]metav1.LabelSelector, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.StatefulSetSelector != nil {
 		in, out := &in.StatefulSetSelector, &out.StatefulSetSelector
 		*out = new(metav1.LabelSelector)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.StatefulSetSelectors != nil {
+		in, out := &in.StatefulSetSelectors, &out.StatefulSetSelectors
+		*out = make([]metav1.LabelSelector, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.DaemonSetSelector != nil {
+		in, out := &in.DaemonSetSelector, &out.DaemonSetSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DaemonSetSelectors != nil {
+		in, out := &in.DaemonSetSelectors, &out.DaemonSetSelectors
+		*out = make([]metav1.LabelSelector, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.WorkloadNamePatterns != nil {
+		in, out := &in.WorkloadNamePatterns, &out.WorkloadNamePatterns
+		*out = new(NamePatterns)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.ResourcePolicy != nil {
 		in, out := &in.ResourcePolicy, &out.ResourcePolicy
 		*out = new(ResourcePolicy)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.DefaultControlledResources != nil {
+		in, out := &in.DefaultControlledResources, &out.DefaultControlledResources
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.VPALabels != nil {
+		in, out := &in.VPALabels, &out.VPALabels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.VPAAnnotations != nil {
+		in, out := &in.VPAAnnotations, &out.VPAAnnotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VpaManagerSpec.
@@ -175,15 +562,79 @@ func (in *VpaManagerStatus) DeepCopyInto(out *VpaManagerStatus) {
 		*out = make([]WorkloadReference, len(*in))
 		copy(*out, *in)
 	}
+	if in.ManagedStatefulSets != nil {
+		in, out := &in.ManagedStatefulSets, &out.ManagedStatefulSets
+		*out = make([]WorkloadReference, len(*in))
+		copy(*out, *in)
+	}
+	if in.ManagedDaemonSets != nil {
+		in, out := &in.ManagedDaemonSets, &out.ManagedDaemonSets
+		*out = make([]WorkloadReference, len(*in))
+		copy(*out, *in)
+	}
 	if in.ManagedWorkloads != nil {
 		in, out := &in.ManagedWorkloads, &out.ManagedWorkloads
 		*out = make([]WorkloadReference, len(*in))
 		copy(*out, *in)
 	}
+	if in.RightsizingScore != nil {
+		in, out := &in.RightsizingScore, &out.RightsizingScore
+		*out = new(int)
+		**out = **in
+	}
+	if in.ForbiddenNamespaces != nil {
+		in, out := &in.ForbiddenNamespaces, &out.ForbiddenNamespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.PausedNamespaces != nil {
+		in, out := &in.PausedNamespaces, &out.PausedNamespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.RejectedVPAs != nil {
+		in, out := &in.RejectedVPAs, &out.RejectedVPAs
+		*out = make([]VPARejection, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.SkippedWorkloads != nil {
+		in, out := &in.SkippedWorkloads, &out.SkippedWorkloads
+		*out = make([]SkippedWorkload, len(*in))
+		copy(*out, *in)
+	}
+	if in.OrphanDeletionsBlockedSince != nil {
+		in, out := &in.OrphanDeletionsBlockedSince, &out.OrphanDeletionsBlockedSince
+		*out = (*in).DeepCopy()
+	}
+	if in.Progress != nil {
+		in, out := &in.Progress, &out.Progress
+		*out = new(ReconcileProgress)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SpecHistory != nil {
+		in, out := &in.SpecHistory, &out.SpecHistory
+		*out = make([]SpecRevision, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.LastErrorTime != nil {
+		in, out := &in.LastErrorTime, &out.LastErrorTime
+		*out = (*in).DeepCopy()
+	}
 	if in.LastReconcileTime != nil {
 		in, out := &in.LastReconcileTime, &out.LastReconcileTime
 		*out = (*in).DeepCopy()
 	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VpaManagerStatus.
@@ -195,3 +646,98 @@ func (in *VpaManagerStatus) DeepCopy() *VpaManagerStatus {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VpaOverride) DeepCopyInto(out *VpaOverride) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VpaOverride.
+func (in *VpaOverride) DeepCopy() *VpaOverride {
+	if in == nil {
+		return nil
+	}
+	out := new(VpaOverride)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *VpaOverride) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VpaOverrideList) DeepCopyInto(out *VpaOverrideList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]VpaOverride, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VpaOverrideList.
+func (in *VpaOverrideList) DeepCopy() *VpaOverrideList {
+	if in == nil {
+		return nil
+	}
+	out := new(VpaOverrideList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *VpaOverrideList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VpaOverrideSpec) DeepCopyInto(out *VpaOverrideSpec) {
+	*out = *in
+	out.TargetRef = in.TargetRef
+	if in.ResourcePolicy != nil {
+		in, out := &in.ResourcePolicy, &out.ResourcePolicy
+		*out = new(ResourcePolicy)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VpaOverrideSpec.
+func (in *VpaOverrideSpec) DeepCopy() *VpaOverrideSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(VpaOverrideSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VPARejection) DeepCopyInto(out *VPARejection) {
+	*out = *in
+	in.Time.DeepCopyInto(&out.Time)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VPARejection.
+func (in *VPARejection) DeepCopy() *VPARejection {
+	if in == nil {
+		return nil
+	}
+	out := new(VPARejection)
+	in.DeepCopyInto(out)
+	return out
+}
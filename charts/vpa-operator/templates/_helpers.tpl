@@ -70,3 +70,17 @@ Create the name of the service account to use
 {{- default "default" .Values.serviceAccount.name }}
 {{- end }}
 {{- end }}
+
+{{/*
+Whether the webhooks are served; never in reconcile-only mode
+*/}}
+{{- define "vpa-operator.webhookEnabled" -}}
+{{- if and .Values.webhook.enabled (ne .Values.mode "reconcile-only") }}true{{- end }}
+{{- end }}
+
+{{/*
+Environment variable holding the value of a Secret-backed export header
+*/}}
+{{- define "vpa-operator.exportHeaderEnv" -}}
+{{- printf "EXPORT_HEADER_%s" (. | upper | replace "-" "_") }}
+{{- end }}
@@ -0,0 +1,313 @@
+// Command kubectl-vpamgr is a kubectl plugin that shows what the VPA operator would do to a
+// cluster. Install it on the PATH and run it as kubectl vpamgr <command>.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/clientcmd"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/config"
+
+	autoscalingv1 "github.com/joaomo/k8s_op_vpa/api/v1"
+	"github.com/joaomo/k8s_op_vpa/internal/controller"
+	"github.com/joaomo/k8s_op_vpa/internal/policy"
+	"github.com/joaomo/k8s_op_vpa/internal/validation"
+	"github.com/joaomo/k8s_op_vpa/internal/vpa"
+	"github.com/joaomo/k8s_op_vpa/internal/vpamgr"
+)
+
+// Exit codes follow kubectl diff: 1 when there are differences, for simulate when the pod
+// would no longer fit any node, or for lint when a manifest has errors, above 1 on errors
+const (
+	exitDiffers = 1
+	exitError   = 2
+)
+
+var scheme = runtime.NewScheme()
+
+func init() {
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(autoscalingv1.AddToScheme(scheme))
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage(os.Stderr)
+		os.Exit(exitError)
+	}
+	switch os.Args[1] {
+	case "diff":
+		os.Exit(diff(os.Args[2:]))
+	case "simulate":
+		os.Exit(simulate(os.Args[2:]))
+	case "rollback-manager":
+		os.Exit(rollbackManager(os.Args[2:]))
+	case "lint":
+		os.Exit(lint(os.Args[2:]))
+	case "help", "-h", "--help":
+		usage(os.Stdout)
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n", os.Args[1])
+		usage(os.Stderr)
+		os.Exit(exitError)
+	}
+}
+
+func usage(w io.Writer) {
+	fmt.Fprint(w, `Usage: kubectl vpamgr <command> [flags]
+
+Commands:
+  diff                Show how the operator would change the VPAs of the cluster
+  simulate            Show what applying a workload's VPA recommendation would change
+  rollback-manager    Re-apply a previous spec of a VpaManager from its status history
+  lint                Check VpaManager and VpaOverride manifests without a cluster
+
+Run kubectl vpamgr <command> -h for the flags of a command.
+`)
+}
+
+// diff renders the VPAs every enabled VpaManager would generate and diffs them against the
+// cluster, returning the exit code
+func diff(args []string) int {
+	fs := flag.NewFlagSet("diff", flag.ExitOnError)
+	config.RegisterFlags(fs)
+	kubeContext := fs.String("context", "", "The kubeconfig context to use.")
+	vpaManager := fs.String("vpamanager", "", "Only diff the VPAs of this VpaManager.")
+	strictSelectors := fs.Bool("strict-selectors", false, "Diff as an operator started with --strict-selectors.")
+	enableDefaultSelectors := fs.Bool("enable-default-selectors", false, "Diff as an operator started with --enable-default-selectors.")
+	defaultNamespaceSelector := fs.String("default-namespace-selector", policy.DefaultSelectorLabel,
+		"The operator's --default-namespace-selector. Requires --enable-default-selectors.")
+	defaultDeploymentSelector := fs.String("default-deployment-selector", policy.DefaultSelectorLabel,
+		"The operator's --default-deployment-selector. Requires --enable-default-selectors.")
+	ownershipLabelKey := fs.String("ownership-label-key", vpa.ManagedByLabel, "The operator's --ownership-label-key.")
+	ownershipLabelValue := fs.String("ownership-label-value", vpa.DefaultManagedByValue, "The operator's --ownership-label-value.")
+	instanceID := fs.String("instance-id", "", "The operator's --instance-id.")
+	_ = fs.Parse(args)
+
+	ownership, err := vpa.NewOwnership(*ownershipLabelKey, *ownershipLabelValue, *instanceID)
+	if err != nil {
+		return fail(fmt.Errorf("invalid ownership label: %w", err))
+	}
+	var selectorDefaults *policy.SelectorDefaults
+	if *enableDefaultSelectors {
+		selectorDefaults, err = policy.NewSelectorDefaults(*defaultNamespaceSelector, *defaultDeploymentSelector)
+		if err != nil {
+			return fail(fmt.Errorf("invalid default selector: %w", err))
+		}
+	}
+
+	cfg, err := config.GetConfigWithContext(*kubeContext)
+	if err != nil {
+		return fail(err)
+	}
+	c, err := client.New(cfg, client.Options{Scheme: scheme})
+	if err != nil {
+		return fail(err)
+	}
+
+	reconciler := &controller.VpaManagerReconciler{
+		Client:           c,
+		Scheme:           scheme,
+		WorkloadConfigs:  controller.DefaultWorkloadConfigs(),
+		SelectorDefaults: selectorDefaults,
+		StrictSelectors:  *strictSelectors,
+		Ownership:        ownership,
+	}
+	changes, err := reconciler.Plan(context.Background(), *vpaManager)
+	if err != nil {
+		return fail(err)
+	}
+	differs, err := vpamgr.WriteDiff(os.Stdout, changes)
+	if err != nil {
+		return fail(err)
+	}
+	if differs {
+		return exitDiffers
+	}
+	return 0
+}
+
+// simulate shows what applying the recommendation of a workload's VPA would change and
+// whether the pod still fits the cluster's nodes, returning the exit code
+func simulate(args []string) int {
+	fs := flag.NewFlagSet("simulate", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: kubectl vpamgr simulate [-n <namespace>] <kind>/<name>")
+		fs.PrintDefaults()
+	}
+	config.RegisterFlags(fs)
+	kubeContext := fs.String("context", "", "The kubeconfig context to use.")
+	var namespace string
+	fs.StringVar(&namespace, "namespace", "", "The namespace of the workload, the context's namespace when empty.")
+	fs.StringVar(&namespace, "n", "", "Shorthand for --namespace.")
+	_ = fs.Parse(args)
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return exitError
+	}
+	ref := fs.Arg(0)
+	// Flags may follow the workload, as with kubectl
+	_ = fs.Parse(fs.Args()[1:])
+	if fs.NArg() > 0 {
+		return fail(fmt.Errorf("unexpected arguments %v", fs.Args()))
+	}
+
+	provider, name, err := vpamgr.ParseWorkloadRef(ref)
+	if err != nil {
+		return fail(err)
+	}
+	if namespace == "" {
+		loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+		loadingRules.ExplicitPath = fs.Lookup("kubeconfig").Value.String()
+		overrides := &clientcmd.ConfigOverrides{CurrentContext: *kubeContext}
+		namespace, _, err = clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, overrides).Namespace()
+		if err != nil {
+			return fail(err)
+		}
+	}
+
+	cfg, err := config.GetConfigWithContext(*kubeContext)
+	if err != nil {
+		return fail(err)
+	}
+	c, err := client.New(cfg, client.Options{Scheme: scheme})
+	if err != nil {
+		return fail(err)
+	}
+
+	sim, err := vpamgr.Simulate(context.Background(), c, provider, namespace, name)
+	if err != nil {
+		return fail(err)
+	}
+	if err := vpamgr.WriteSimulation(os.Stdout, sim); err != nil {
+		return fail(err)
+	}
+	if !sim.FitsAnyNode() {
+		return exitDiffers
+	}
+	return 0
+}
+
+// rollbackManager re-applies a previous spec of a VpaManager from its status.specHistory, or
+// lists the history, returning the exit code
+func rollbackManager(args []string) int {
+	fs := flag.NewFlagSet("rollback-manager", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: kubectl vpamgr rollback-manager [--generation <n>] [--list] [--dry-run] <vpamanager>")
+		fs.PrintDefaults()
+	}
+	config.RegisterFlags(fs)
+	kubeContext := fs.String("context", "", "The kubeconfig context to use.")
+	generation := fs.Int64("generation", 0, "The generation to roll back to, the newest one with a different spec when 0.")
+	list := fs.Bool("list", false, "List the spec history instead of rolling back.")
+	dryRun := fs.Bool("dry-run", false, "Validate the rollback on the API server without applying it.")
+	_ = fs.Parse(args)
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return exitError
+	}
+	name := fs.Arg(0)
+	// Flags may follow the VpaManager, as with kubectl
+	_ = fs.Parse(fs.Args()[1:])
+	if fs.NArg() > 0 {
+		return fail(fmt.Errorf("unexpected arguments %v", fs.Args()))
+	}
+
+	cfg, err := config.GetConfigWithContext(*kubeContext)
+	if err != nil {
+		return fail(err)
+	}
+	c, err := client.New(cfg, client.Options{Scheme: scheme})
+	if err != nil {
+		return fail(err)
+	}
+
+	ctx := context.Background()
+	if *list {
+		vm := &autoscalingv1.VpaManager{}
+		if err := c.Get(ctx, client.ObjectKey{Name: name}, vm); err != nil {
+			return fail(err)
+		}
+		if err := vpamgr.WriteSpecHistory(os.Stdout, vm); err != nil {
+			return fail(err)
+		}
+		return 0
+	}
+
+	revision, err := vpamgr.Rollback(ctx, c, name, *generation, *dryRun)
+	if err != nil {
+		return fail(err)
+	}
+	suffix := ""
+	if *dryRun {
+		suffix = " (dry run)"
+	}
+	fmt.Printf("vpamanager/%s rolled back to the spec of generation %d (%s)%s\n", name, revision.Generation, revision.Hash, suffix)
+	return 0
+}
+
+// lint checks VpaManager and VpaOverride manifests offline, returning exitDiffers when any
+// has an error, or a warning with --fail-on-warnings
+func lint(args []string) int {
+	fs := flag.NewFlagSet("lint", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: kubectl vpamgr lint [--auto-safeguards <mode>] [--fail-on-warnings] <file>...")
+		fs.PrintDefaults()
+	}
+	autoSafeguards := fs.String("auto-safeguards", string(validation.AutoSafeguardsWarn),
+		"The operator's --auto-safeguards mode: off, warn or enforce.")
+	failOnWarnings := fs.Bool("fail-on-warnings", false, "Also fail when there are only warnings.")
+	_ = fs.Parse(args)
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return exitError
+	}
+	mode, err := validation.ParseAutoSafeguardMode(*autoSafeguards)
+	if err != nil {
+		return fail(err)
+	}
+
+	failed := false
+	for _, path := range fs.Args() {
+		findings, err := lintFile(path, vpamgr.LintOptions{AutoSafeguards: mode})
+		if err != nil {
+			return fail(fmt.Errorf("%s: %w", path, err))
+		}
+		hasErrors, err := vpamgr.WriteLint(os.Stdout, path, findings)
+		if err != nil {
+			return fail(err)
+		}
+		failed = failed || hasErrors || (*failOnWarnings && len(findings) > 0)
+	}
+	if failed {
+		return exitDiffers
+	}
+	return 0
+}
+
+// lintFile lints the manifest at path, or standard input when path is -
+func lintFile(path string, opts vpamgr.LintOptions) ([]vpamgr.Finding, error) {
+	if path == "-" {
+		return vpamgr.Lint(os.Stdin, opts)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return vpamgr.Lint(f, opts)
+}
+
+// fail prints err and returns the error exit code
+func fail(err error) int {
+	fmt.Fprintf(os.Stderr, "error: %v\n", err)
+	return exitError
+}
@@ -0,0 +1,184 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/joaomo/k8s_op_vpa/internal/metrics"
+	"github.com/joaomo/k8s_op_vpa/internal/workload"
+)
+
+// BootstrapVPALabel marks the VPAs BootstrapVPAs maintains. They carry no ownership label,
+// so neither reconciles nor the orphan sweeper update or delete them.
+const BootstrapVPALabel = "vpa-operator.io/bootstrap"
+
+// BootstrapVPAs maintains an Off-mode VPA for each Deployment of the VPA installation and,
+// optionally, for the operator's own workload, so the autoscaling stack gets recommendations
+// to rightsize it by hand. VpaManagers never manage these workloads, and a bootstrap VPA
+// switched to another update mode is set back to Off.
+type BootstrapVPAs struct {
+	Client          client.Client
+	Metrics         *metrics.Metrics
+	WorkloadConfigs []WorkloadConfig
+
+	// Components are the VPA component Deployments; those that do not exist are skipped
+	Components *workload.Bootstrapped
+
+	// Self is the operator's own workload; nil leaves the operator without a VPA
+	Self *workload.Self
+
+	// Interval is how often the VPAs are ensured again, e.g. after the VPA CRD was installed
+	Interval time.Duration
+
+	Log logr.Logger
+}
+
+// Start ensures the VPAs right away and then every Interval until the context is cancelled
+func (b *BootstrapVPAs) Start(ctx context.Context) error {
+	if b.Log.GetSink() == nil {
+		b.Log = ctrl.Log.WithName("bootstrap-vpas")
+	}
+
+	ticker := time.NewTicker(b.Interval)
+	defer ticker.Stop()
+
+	for {
+		count, err := b.Ensure(ctx)
+		switch {
+		case meta.IsNoMatchError(err):
+			b.Log.Info("VPA CRD not installed, retrying bootstrap VPAs later", "interval", b.Interval)
+		case err != nil:
+			b.Log.Error(err, "failed to ensure bootstrap VPAs")
+		}
+		b.Metrics.SetBootstrapVPAs(count)
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// NeedLeaderElection ensures only the leader writes the VPAs
+func (b *BootstrapVPAs) NeedLeaderElection() bool {
+	return true
+}
+
+// Ensure creates or resets the Off-mode VPA of every existing target and returns how many
+// are in place. It stops at the first failure other than a missing target.
+func (b *BootstrapVPAs) Ensure(ctx context.Context) (int, error) {
+	count := 0
+	for _, target := range b.targets() {
+		wc, ok := workloadConfigIn(b.WorkloadConfigs, target.Kind)
+		if !ok {
+			b.Log.V(1).Info("workload kind is not managed, skipping", "kind", target.Kind, "name", target.Name, "namespace", target.Namespace)
+			continue
+		}
+		wl, err := wc.Provider.Get(ctx, b.Client, target.Namespace, target.Name)
+		if err != nil {
+			if errors.IsNotFound(err) {
+				b.Log.V(1).Info("bootstrap target not found, skipping", "kind", target.Kind, "name", target.Name, "namespace", target.Namespace)
+				continue
+			}
+			return count, err
+		}
+		ok, err = b.ensureVPA(ctx, wl)
+		if err != nil {
+			return count, fmt.Errorf("%s %s/%s: %w", target.Kind, target.Namespace, target.Name, err)
+		}
+		if ok {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// bootstrapTarget is a workload that gets a bootstrap VPA
+type bootstrapTarget struct {
+	Kind      string
+	Namespace string
+	Name      string
+}
+
+// targets returns the VPA components, then the operator when Self names a workload
+func (b *BootstrapVPAs) targets() []bootstrapTarget {
+	var targets []bootstrapTarget
+	if b.Components != nil {
+		for _, name := range b.Components.Names {
+			targets = append(targets, bootstrapTarget{Kind: "Deployment", Namespace: b.Components.Namespace, Name: name})
+		}
+	}
+	if b.Self != nil && b.Self.Name != "" {
+		targets = append(targets, bootstrapTarget{Kind: b.Self.Kind, Namespace: b.Self.Namespace, Name: b.Self.Name})
+	}
+	return targets
+}
+
+// ensureVPA creates the bootstrap VPA of wl, or resets its spec when it was changed, and
+// reports whether it is in place. A VPA of the same name without BootstrapVPALabel belongs
+// to someone else and is left alone.
+func (b *BootstrapVPAs) ensureVPA(ctx context.Context, wl workload.Workload) (bool, error) {
+	desired := bootstrapVPA(wl)
+	existing := &unstructured.Unstructured{}
+	existing.SetGroupVersionKind(vpaGVK)
+	err := b.Client.Get(ctx, client.ObjectKeyFromObject(desired), existing)
+	switch {
+	case errors.IsNotFound(err):
+		if err := b.Client.Create(ctx, desired); err != nil {
+			return false, err
+		}
+		b.Log.Info("created bootstrap VPA", "vpa", desired.GetName(), "namespace", desired.GetNamespace(), "kind", wl.GetKind())
+		return true, nil
+	case err != nil:
+		return false, err
+	case existing.GetLabels()[BootstrapVPALabel] != "true":
+		b.Log.V(1).Info("VPA of the same name is not a bootstrap VPA, leaving it alone", "vpa", existing.GetName(), "namespace", existing.GetNamespace())
+		return false, nil
+	case equality.Semantic.DeepEqual(existing.Object["spec"], desired.Object["spec"]):
+		return true, nil
+	}
+	existing.Object["spec"] = desired.Object["spec"]
+	if err := b.Client.Update(ctx, existing); err != nil {
+		return false, err
+	}
+	b.Log.Info("reset changed bootstrap VPA", "vpa", existing.GetName(), "namespace", existing.GetNamespace())
+	return true, nil
+}
+
+// bootstrapVPA builds the Off-mode VPA of wl. The workload owns it, so it is garbage
+// collected with the workload.
+func bootstrapVPA(wl workload.Workload) *unstructured.Unstructured {
+	v := &unstructured.Unstructured{}
+	v.SetGroupVersionKind(vpaGVK)
+	v.SetName(wl.GetName() + "-vpa")
+	v.SetNamespace(wl.GetNamespace())
+	v.SetLabels(map[string]string{BootstrapVPALabel: "true"})
+	v.SetOwnerReferences([]metav1.OwnerReference{{
+		APIVersion: wl.GetAPIVersion(),
+		Kind:       wl.GetKind(),
+		Name:       wl.GetName(),
+		UID:        wl.GetUID(),
+	}})
+	v.Object["spec"] = map[string]interface{}{
+		"targetRef": map[string]interface{}{
+			"apiVersion": wl.GetAPIVersion(),
+			"kind":       wl.GetKind(),
+			"name":       wl.GetName(),
+		},
+		"updatePolicy": map[string]interface{}{
+			"updateMode": "Off",
+		},
+	}
+	return v
+}
@@ -0,0 +1,137 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	autoscalingv1 "github.com/joaomo/k8s_op_vpa/api/v1"
+	"github.com/joaomo/k8s_op_vpa/internal/workload"
+)
+
+// Test: the VPA components and the operator get an Off-mode VPA, which is set back to Off when changed
+func TestBootstrapVPAs_Ensure(t *testing.T) {
+	scheme := setupScheme(t)
+	ctx := context.Background()
+
+	deployment := func(name, namespace string) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, UID: types.UID(name + "-uid")},
+			Spec:       createDeploymentSpec(),
+		}
+	}
+	// The updater's VPA was switched to Auto by hand
+	changed := bootstrapVPA(&workload.DeploymentWorkload{Deployment: deployment("vpa-updater", "kube-system")})
+	require.NoError(t, unstructured.SetNestedField(changed.Object, "Auto", "spec", "updatePolicy", "updateMode"))
+	// The admission controller already has a VPA of someone else under the same name
+	foreign := createUnstructuredVPA("vpa-admission-controller-vpa", "kube-system", "vpa-admission-controller")
+	foreign.SetLabels(nil)
+
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(
+			deployment("vpa-recommender", "kube-system"),
+			deployment("vpa-updater", "kube-system"),
+			deployment("vpa-admission-controller", "kube-system"),
+			deployment("vpa-operator", "vpa-system"),
+			changed,
+			foreign,
+		).
+		Build()
+	bootstrap := &BootstrapVPAs{
+		Client:  fakeClient,
+		Metrics: createTestMetrics(),
+		Components: &workload.Bootstrapped{
+			Namespace: "kube-system",
+			// vpa-checkpointer does not exist and is skipped
+			Names: append([]string{"vpa-checkpointer"}, workload.DefaultVPAComponents...),
+		},
+		Self: &workload.Self{Namespace: "vpa-system", Kind: "Deployment", Name: "vpa-operator"},
+	}
+
+	count, err := bootstrap.Ensure(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 3, count, "recommender, updater and operator")
+
+	for _, key := range []types.NamespacedName{
+		{Namespace: "kube-system", Name: "vpa-recommender-vpa"},
+		{Namespace: "kube-system", Name: "vpa-updater-vpa"},
+		{Namespace: "vpa-system", Name: "vpa-operator-vpa"},
+	} {
+		v := &unstructured.Unstructured{}
+		v.SetGroupVersionKind(vpaGVK)
+		require.NoError(t, fakeClient.Get(ctx, key, v), key.String())
+		mode, _, _ := unstructured.NestedString(v.Object, "spec", "updatePolicy", "updateMode")
+		assert.Equal(t, "Off", mode, key.String())
+		assert.Equal(t, "true", v.GetLabels()[BootstrapVPALabel], key.String())
+		assert.Empty(t, v.GetLabels()["app.kubernetes.io/managed-by"], "bootstrap VPAs are not owned by a VpaManager")
+	}
+
+	v := &unstructured.Unstructured{}
+	v.SetGroupVersionKind(vpaGVK)
+	require.NoError(t, fakeClient.Get(ctx, client.ObjectKeyFromObject(foreign), v))
+	assert.Empty(t, v.GetLabels()[BootstrapVPALabel], "a VPA of someone else is left alone")
+}
+
+// Test: VpaManagers never manage the VPA components that have a bootstrap VPA
+func TestReconcile_SkipsBootstrappedComponents(t *testing.T) {
+	scheme := setupScheme(t)
+	ctx := context.Background()
+
+	vpaManager := &autoscalingv1.VpaManager{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-vpamanager"},
+		Spec: autoscalingv1.VpaManagerSpec{
+			Enabled:            true,
+			UpdateMode:         "Auto",
+			MatchAllNamespaces: true,
+			MatchAllWorkloads:  true,
+		},
+	}
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(
+			vpaManager,
+			&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "kube-system"}},
+			&appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Name: "vpa-recommender", Namespace: "kube-system", UID: "uid-1"},
+				Spec:       createDeploymentSpec(),
+			},
+			&appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Name: "coredns", Namespace: "kube-system", UID: "uid-2"},
+				Spec:       createDeploymentSpec(),
+			},
+		).
+		WithStatusSubresource(vpaManager).
+		Build()
+	reconciler := &VpaManagerReconciler{
+		Client:          fakeClient,
+		Scheme:          scheme,
+		Metrics:         createTestMetrics(),
+		WorkloadConfigs: DefaultWorkloadConfigs(),
+		Bootstrapped:    &workload.Bootstrapped{Namespace: "kube-system", Names: workload.DefaultVPAComponents},
+	}
+
+	_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: "test-vpamanager"}})
+	require.NoError(t, err)
+
+	vpaList := newVPAList()
+	require.NoError(t, fakeClient.List(ctx, vpaList))
+	require.Len(t, vpaList.Items, 1)
+	assert.Equal(t, "coredns-vpa", vpaList.Items[0].GetName())
+
+	updated := &autoscalingv1.VpaManager{}
+	require.NoError(t, fakeClient.Get(ctx, types.NamespacedName{Name: "test-vpamanager"}, updated))
+	assert.Equal(t, []autoscalingv1.SkippedWorkload{
+		{Kind: "Deployment", Name: "vpa-recommender", Namespace: "kube-system", Reason: "BootstrapVPA"},
+	}, updated.Status.SkippedWorkloads)
+}
@@ -0,0 +1,62 @@
+package controller
+
+import (
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	autoscalingv1 "github.com/joaomo/k8s_op_vpa/api/v1"
+)
+
+// ConfirmOrphanDeletionAnnotation on a VpaManager lets a held-back burst of orphan
+// deletions proceed immediately. The operator removes it once the deletions ran.
+const ConfirmOrphanDeletionAnnotation = "vpa-operator.io/confirm-orphan-deletion"
+
+// OrphanBurstGuard holds back orphan VPA deletions when a single reconcile would
+// delete more VPAs than expected, e.g. after a chart dropped a selector label
+type OrphanBurstGuard struct {
+	// MaxDeletions is the number of orphans deleted without confirmation, zero disables the guard
+	MaxDeletions int
+
+	// GracePeriod is how long a burst is held back before it proceeds unconfirmed,
+	// zero holds it until confirmed
+	GracePeriod time.Duration
+}
+
+// burstDecision is the outcome of checking pending orphan deletions against the guard
+type burstDecision struct {
+	// allowed reports whether the deletions may proceed
+	allowed bool
+
+	// confirmed reports whether they proceed because of the confirmation annotation
+	confirmed bool
+
+	// blockedSince is when the burst was first held back, nil when allowed
+	blockedSince *metav1.Time
+}
+
+// check decides whether pending orphan deletions may proceed. A split pass passes the
+// orphans of all its chunks so far; since it cannot tell how many its later chunks hold, a
+// burst held back before stays held back until confirmed, its grace period elapsed, or a
+// pass found no orphans. The guard is nil-safe.
+func (g *OrphanBurstGuard) check(vm *autoscalingv1.VpaManager, pending int, split bool, now metav1.Time) burstDecision {
+	if g == nil || g.MaxDeletions <= 0 || pending == 0 {
+		return burstDecision{allowed: true}
+	}
+	held := split && vm.Status.OrphanDeletionsBlockedSince != nil
+	if pending <= g.MaxDeletions && !held {
+		return burstDecision{allowed: true}
+	}
+	if vm.Annotations[ConfirmOrphanDeletionAnnotation] == "true" {
+		return burstDecision{allowed: true, confirmed: true}
+	}
+
+	since := vm.Status.OrphanDeletionsBlockedSince
+	if since == nil {
+		since = &now
+	}
+	if g.GracePeriod > 0 && now.Sub(since.Time) >= g.GracePeriod {
+		return burstDecision{allowed: true}
+	}
+	return burstDecision{blockedSince: since}
+}
@@ -0,0 +1,68 @@
+package controller
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	autoscalingv1 "github.com/joaomo/k8s_op_vpa/api/v1"
+)
+
+func TestOrphanBurstGuard_Check(t *testing.T) {
+	now := metav1.NewTime(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+	earlier := metav1.NewTime(now.Add(-30 * time.Minute))
+	longAgo := metav1.NewTime(now.Add(-2 * time.Hour))
+	guard := &OrphanBurstGuard{MaxDeletions: 5, GracePeriod: time.Hour}
+
+	tests := []struct {
+		name             string
+		guard            *OrphanBurstGuard
+		annotations      map[string]string
+		blockedSince     *metav1.Time
+		pending          int
+		split            bool
+		expectedAllowed  bool
+		expectedConfirm  bool
+		expectedBlockSet *metav1.Time
+	}{
+		{name: "nil guard", guard: nil, pending: 100, expectedAllowed: true},
+		{name: "disabled guard", guard: &OrphanBurstGuard{}, pending: 100, expectedAllowed: true},
+		{name: "within limit", guard: guard, pending: 5, expectedAllowed: true},
+		{name: "first burst is held back", guard: guard, pending: 6, expectedBlockSet: &now},
+		{name: "burst keeps its start time", guard: guard, pending: 6, blockedSince: &earlier, expectedBlockSet: &earlier},
+		{name: "grace period elapsed", guard: guard, pending: 6, blockedSince: &longAgo, expectedAllowed: true},
+		{name: "within limit after a held back burst", guard: guard, pending: 3, blockedSince: &earlier, expectedAllowed: true},
+		{name: "split pass keeps a held back burst", guard: guard, pending: 3, split: true, blockedSince: &earlier, expectedBlockSet: &earlier},
+		{name: "split pass without orphans", guard: guard, pending: 0, split: true, blockedSince: &earlier, expectedAllowed: true},
+		{
+			name:            "confirmed",
+			guard:           guard,
+			pending:         6,
+			annotations:     map[string]string{ConfirmOrphanDeletionAnnotation: "true"},
+			expectedAllowed: true,
+			expectedConfirm: true,
+		},
+		{
+			name:             "no grace period waits for confirmation",
+			guard:            &OrphanBurstGuard{MaxDeletions: 5},
+			pending:          6,
+			blockedSince:     &longAgo,
+			expectedBlockSet: &longAgo,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vm := &autoscalingv1.VpaManager{
+				ObjectMeta: metav1.ObjectMeta{Name: "test", Annotations: tt.annotations},
+				Status:     autoscalingv1.VpaManagerStatus{OrphanDeletionsBlockedSince: tt.blockedSince},
+			}
+			decision := tt.guard.check(vm, tt.pending, tt.split, now)
+			assert.Equal(t, tt.expectedAllowed, decision.allowed)
+			assert.Equal(t, tt.expectedConfirm, decision.confirmed)
+			assert.Equal(t, tt.expectedBlockSet, decision.blockedSince)
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package controller
+
+import (
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	autoscalingv1 "github.com/joaomo/k8s_op_vpa/api/v1"
+)
+
+// CertificateExpiry reports the expiry of the webhook serving certificate, implemented by
+// the webhook package's CertExpiryMonitor
+type CertificateExpiry interface {
+	// Expiring returns the expiry of the certificate and whether it is within the renewal
+	// window at now
+	Expiring(now time.Time) (time.Time, bool)
+}
+
+// setDegradedCondition reports whether the webhook serving certificate is about to expire;
+// the condition is removed when this instance serves no webhooks
+func (r *VpaManagerReconciler) setDegradedCondition(status *autoscalingv1.VpaManagerStatus, generation int64, now time.Time) {
+	if r.WebhookCertificate == nil {
+		meta.RemoveStatusCondition(&status.Conditions, autoscalingv1.ConditionDegraded)
+		return
+	}
+	condition := metav1.Condition{
+		Type:               autoscalingv1.ConditionDegraded,
+		Status:             metav1.ConditionFalse,
+		ObservedGeneration: generation,
+		Reason:             "AsExpected",
+		Message:            "The webhook serving certificate is outside its renewal window",
+	}
+	if notAfter, expiring := r.WebhookCertificate.Expiring(now); expiring {
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = "WebhookCertificateExpiring"
+		condition.Message = fmt.Sprintf("The webhook serving certificate expires at %s and has not been renewed. "+
+			"Check the certificate issuer, e.g. the cert-manager Certificate.", notAfter.UTC().Format(time.RFC3339))
+	}
+	meta.SetStatusCondition(&status.Conditions, condition)
+}
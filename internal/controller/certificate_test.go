@@ -0,0 +1,75 @@
+package controller
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	autoscalingv1 "github.com/joaomo/k8s_op_vpa/api/v1"
+)
+
+// fakeCertificate expires at notAfter and is expiring when expiring is set
+type fakeCertificate struct {
+	notAfter time.Time
+	expiring bool
+}
+
+func (f fakeCertificate) Expiring(time.Time) (time.Time, bool) {
+	return f.notAfter, f.expiring
+}
+
+func TestSetDegradedCondition(t *testing.T) {
+	notAfter := time.Date(2026, 11, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name            string
+		certificate     CertificateExpiry
+		expectedStatus  metav1.ConditionStatus
+		expectedReason  string
+		expectedMessage string
+	}{
+		{name: "no webhooks"},
+		{
+			name:           "certificate valid",
+			certificate:    fakeCertificate{notAfter: notAfter},
+			expectedStatus: metav1.ConditionFalse,
+			expectedReason: "AsExpected",
+		},
+		{
+			name:            "certificate expiring",
+			certificate:     fakeCertificate{notAfter: notAfter, expiring: true},
+			expectedStatus:  metav1.ConditionTrue,
+			expectedReason:  "WebhookCertificateExpiring",
+			expectedMessage: "expires at 2026-11-01T12:00:00Z",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &VpaManagerReconciler{WebhookCertificate: tt.certificate}
+			// A stale condition is replaced or removed
+			status := &autoscalingv1.VpaManagerStatus{Conditions: []metav1.Condition{{
+				Type:   autoscalingv1.ConditionDegraded,
+				Status: metav1.ConditionTrue,
+				Reason: "WebhookCertificateExpiring",
+			}}}
+
+			r.setDegradedCondition(status, 3, time.Now())
+
+			condition := meta.FindStatusCondition(status.Conditions, autoscalingv1.ConditionDegraded)
+			if tt.certificate == nil {
+				assert.Nil(t, condition)
+				return
+			}
+			require.NotNil(t, condition)
+			assert.Equal(t, tt.expectedStatus, condition.Status)
+			assert.Equal(t, tt.expectedReason, condition.Reason)
+			assert.Contains(t, condition.Message, tt.expectedMessage)
+			assert.Equal(t, int64(3), condition.ObservedGeneration)
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	autoscalingv1 "github.com/joaomo/k8s_op_vpa/api/v1"
+	"github.com/joaomo/k8s_op_vpa/internal/metrics"
+)
+
+// conflictBackoff paces retries of writes that lost a race with the webhooks or another
+// reconcile. The jitter spreads writers that conflicted at the same time.
+var conflictBackoff = wait.Backoff{
+	Steps:    5,
+	Duration: 10 * time.Millisecond,
+	Factor:   2,
+	Jitter:   0.5,
+}
+
+// isWriteConflict reports whether a write lost a race: an update of an outdated object, or
+// a create of an object written concurrently
+func isWriteConflict(err error) bool {
+	return errors.IsConflict(err) || errors.IsAlreadyExists(err)
+}
+
+// retryOnConflict runs write, which must read what it writes, again on conflicts with
+// jittered backoff and counts every conflict for object
+func (r *VpaManagerReconciler) retryOnConflict(vpaManagerName, object string, write func() error) error {
+	return retry.OnError(conflictBackoff, isWriteConflict, func() error {
+		err := write()
+		if isWriteConflict(err) {
+			r.Metrics.RecordWriteConflict(vpaManagerName, object)
+		}
+		return err
+	})
+}
+
+// patchStatus patches the status of vpaManager to status. On a conflict the patch is
+// computed again against the latest VpaManager.
+func (r *VpaManagerReconciler) patchStatus(ctx context.Context, vpaManager *autoscalingv1.VpaManager, status *autoscalingv1.VpaManagerStatus) error {
+	base := vpaManager
+	return r.retryOnConflict(vpaManager.Name, metrics.ConflictObjectStatus, func() error {
+		patched := base.DeepCopy()
+		patched.Status = *status
+		err := r.Status().Patch(ctx, patched, client.MergeFrom(base))
+		if errors.IsConflict(err) {
+			latest := &autoscalingv1.VpaManager{}
+			if getErr := r.Get(ctx, types.NamespacedName{Name: vpaManager.Name}, latest); getErr != nil {
+				return getErr
+			}
+			base = latest
+		}
+		return err
+	})
+}
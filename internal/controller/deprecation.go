@@ -0,0 +1,33 @@
+package controller
+
+import (
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+
+	autoscalingv1 "github.com/joaomo/k8s_op_vpa/api/v1"
+)
+
+// DeprecatedStatusFieldsReason is the reason of the Warning event emitted on a VpaManager
+// whose status populates deprecated fields
+const DeprecatedStatusFieldsReason = "DeprecatedStatusFields"
+
+// warnDeprecatedStatusFields tells the readers of a VpaManager status that it populates
+// deprecated fields. Status readers cannot be sent admission warnings, so the warning is a
+// log line and an event on the VpaManager, emitted once per VpaManager and operator process.
+func (r *VpaManagerReconciler) warnDeprecatedStatusFields(vpaManager *autoscalingv1.VpaManager, status *autoscalingv1.VpaManagerStatus) {
+	fields := status.DeprecatedFieldsInUse()
+	if len(fields) == 0 {
+		return
+	}
+	if _, warned := r.deprecationWarned.LoadOrStore(vpaManager.UID, true); warned {
+		return
+	}
+
+	message := strings.Join(fields, " and ") + " are deprecated and will be removed in a future API version; " +
+		"read status.deploymentCount, status.statefulSetCount and status.daemonSetCount, or the per-kind lists, instead"
+	r.Log.Info("VpaManager status populates deprecated fields", "vpaManager", vpaManager.Name, "fields", fields)
+	if r.Recorder != nil {
+		r.Recorder.Event(vpaManager, corev1.EventTypeWarning, DeprecatedStatusFieldsReason, message)
+	}
+}
@@ -0,0 +1,147 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	autoscalingv1 "github.com/joaomo/k8s_op_vpa/api/v1"
+	"github.com/joaomo/k8s_op_vpa/internal/decisions"
+	"github.com/joaomo/k8s_op_vpa/internal/vpa"
+	"github.com/joaomo/k8s_op_vpa/internal/workload"
+)
+
+// handoverRequeueDelay is how soon a VpaManager that deferred handovers reconciles again
+const handoverRequeueDelay = 10 * time.Second
+
+// handoverKey identifies the VPAs of one namespace moving from one VpaManager to another
+type handoverKey struct {
+	namespace string
+	from      string
+	to        string
+}
+
+// handovers counts the VPAs a reconcile took over from other VpaManagers, and those it
+// deferred to stay within MaxHandovers, per namespace and previous VpaManager
+type handovers struct {
+	// limit bounds the VPAs taken over per reconcile, zero is unbounded
+	limit    int
+	taken    int
+	done     map[handoverKey]int
+	deferred map[handoverKey]int
+}
+
+func newHandovers(limit int) *handovers {
+	return &handovers{limit: limit, done: map[handoverKey]int{}, deferred: map[handoverKey]int{}}
+}
+
+// full reports whether this reconcile may take over no more VPAs
+func (h *handovers) full() bool {
+	return h.limit > 0 && h.taken >= h.limit
+}
+
+// add counts a VPA taken over
+func (h *handovers) add(key handoverKey) {
+	h.taken++
+	h.done[key]++
+}
+
+// deferTo counts a VPA left to a later reconcile
+func (h *handovers) deferTo(key handoverKey) {
+	h.deferred[key]++
+}
+
+// pending reports whether VPAs were deferred
+func (h *handovers) pending() bool {
+	return len(h.deferred) > 0
+}
+
+// recordHandovers emits one Normal event per namespace and previous VpaManager summarizing
+// the VPAs this reconcile took over
+func (r *VpaManagerReconciler) recordHandovers(h *handovers) {
+	if r.Recorder == nil {
+		return
+	}
+	keys := map[handoverKey]bool{}
+	for key := range h.done {
+		keys[key] = true
+	}
+	for key := range h.deferred {
+		keys[key] = true
+	}
+	for key := range keys {
+		ns := &corev1.Namespace{}
+		ns.Name = key.namespace
+		message := fmt.Sprintf("%d VPAs moved from VpaManager %s to %s", h.done[key], key.from, key.to)
+		if deferred := h.deferred[key]; deferred > 0 {
+			message += fmt.Sprintf(", %d more follow shortly", deferred)
+		}
+		r.Recorder.Event(ns, corev1.EventTypeNormal, "VPAsHandedOver", message)
+	}
+}
+
+// handoverTarget returns the enabled VpaManager of the highest priority other than the VPA's
+// creator that now manages the workload of an orphaned VPA, e.g. after its namespace was relabeled into
+// another tier. That VpaManager takes the VPA over, so it must not be deleted meanwhile.
+// It returns empty when no other VpaManager manages the workload.
+func (r *VpaManagerReconciler) handoverTarget(ctx context.Context, vpaObj *unstructured.Unstructured) (string, error) {
+	kind, _, _ := unstructured.NestedString(vpaObj.Object, "spec", "targetRef", "kind")
+	name, _, _ := unstructured.NestedString(vpaObj.Object, "spec", "targetRef", "name")
+	wc, ok := r.workloadConfigFor(kind)
+	if !ok || name == "" {
+		return "", nil
+	}
+	wl, err := wc.Provider.Get(ctx, r.Client, vpaObj.GetNamespace(), name)
+	if err != nil {
+		return "", client.IgnoreNotFound(err)
+	}
+	if workload.Excluded(wl.GetObject()) || r.Self.Matches(wl) {
+		return "", nil
+	}
+	ns := &corev1.Namespace{}
+	if err := r.Get(ctx, types.NamespacedName{Name: vpaObj.GetNamespace()}, ns); err != nil {
+		return "", client.IgnoreNotFound(err)
+	}
+
+	vpaManagerList := &autoscalingv1.VpaManagerList{}
+	if err := r.List(ctx, vpaManagerList); err != nil {
+		return "", err
+	}
+	// The VpaManager of the highest priority wins the workload
+	autoscalingv1.SortByPriority(vpaManagerList.Items)
+	creator := vpaObj.GetLabels()[vpa.CreatedByLabel]
+	for i := range vpaManagerList.Items {
+		vm := &vpaManagerList.Items[i]
+		if vm.Name == creator {
+			continue
+		}
+		if _, reason := r.matchWorkload(ctx, vm, wc, ns, wl.GetObject()); reason == "" {
+			return vm.Name, nil
+		}
+	}
+	return "", nil
+}
+
+// withoutHandovers drops the orphaned VPAs another VpaManager now manages, which it takes
+// over instead of having them deleted and recreated. On error the remaining VPAs are kept
+// back too, so a VPA is never deleted just because its new VpaManager could not be found.
+func (r *VpaManagerReconciler) withoutHandovers(ctx context.Context, orphans []unstructured.Unstructured) ([]unstructured.Unstructured, error) {
+	var kept []unstructured.Unstructured
+	for i := range orphans {
+		target, err := r.handoverTarget(ctx, &orphans[i])
+		if err != nil {
+			return kept, err
+		}
+		if target != "" {
+			r.recordOrphanDecision(&orphans[i], decisions.ActionSkipped, "HandoverPending")
+			continue
+		}
+		kept = append(kept, orphans[i])
+	}
+	return kept, nil
+}
@@ -0,0 +1,139 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	autoscalingv1 "github.com/joaomo/k8s_op_vpa/api/v1"
+	"github.com/joaomo/k8s_op_vpa/internal/vpa"
+)
+
+func TestHandovers(t *testing.T) {
+	key := handoverKey{namespace: "shop", from: "staging", to: "production"}
+
+	tests := []struct {
+		name            string
+		limit           int
+		vpas            int
+		expectedTaken   int
+		expectedPending bool
+	}{
+		{name: "unbounded", vpas: 5, expectedTaken: 5},
+		{name: "within the limit", limit: 5, vpas: 5, expectedTaken: 5},
+		{name: "over the limit", limit: 2, vpas: 5, expectedTaken: 2, expectedPending: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newHandovers(tt.limit)
+			for i := 0; i < tt.vpas; i++ {
+				if h.full() {
+					h.deferTo(key)
+					continue
+				}
+				h.add(key)
+			}
+			assert.Equal(t, tt.expectedTaken, h.done[key])
+			assert.Equal(t, tt.vpas-tt.expectedTaken, h.deferred[key])
+			assert.Equal(t, tt.expectedPending, h.pending())
+		})
+	}
+}
+
+func TestReconcile_NamespaceTierHandover(t *testing.T) {
+	scheme := setupScheme(t)
+	ctx := context.Background()
+
+	tierManager := func(name, mode string) *autoscalingv1.VpaManager {
+		return &autoscalingv1.VpaManager{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec: autoscalingv1.VpaManagerSpec{
+				Enabled:            true,
+				UpdateMode:         mode,
+				NamespaceSelector:  &metav1.LabelSelector{MatchLabels: map[string]string{"env": name}},
+				DeploymentSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"vpa-enabled": "true"}},
+			},
+		}
+	}
+	staging := tierManager("staging", "Off")
+	production := tierManager("production", "Initial")
+	ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "shop", Labels: map[string]string{"env": "staging"}}}
+	objs := []client.Object{staging, production, ns}
+	for i := 0; i < 3; i++ {
+		objs = append(objs, &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("web-%d", i), Namespace: "shop", Labels: map[string]string{"vpa-enabled": "true"}},
+			Spec:       createDeploymentSpec(),
+		})
+	}
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(objs...).
+		WithStatusSubresource(staging, production).
+		Build()
+	recorder := record.NewFakeRecorder(10)
+	reconciler := &VpaManagerReconciler{
+		Client:          fakeClient,
+		Scheme:          scheme,
+		Metrics:         createTestMetrics(),
+		Recorder:        recorder,
+		WorkloadConfigs: DefaultWorkloadConfigs(),
+		MaxHandovers:    2,
+	}
+	reconcileManager := func(name string) reconcile.Result {
+		result, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: name}})
+		require.NoError(t, err)
+		return result
+	}
+	// creators returns the VpaManager and update mode of each VPA in the namespace
+	creators := func() map[string]string {
+		vpaList := newVPAList()
+		require.NoError(t, fakeClient.List(ctx, vpaList, client.InNamespace("shop")))
+		got := map[string]string{}
+		for _, item := range vpaList.Items {
+			mode, _, _ := unstructured.NestedString(item.Object, "spec", "updatePolicy", "updateMode")
+			got[item.GetName()] = item.GetLabels()[vpa.CreatedByLabel] + "/" + mode
+		}
+		return got
+	}
+
+	reconcileManager("staging")
+	assert.Equal(t, map[string]string{"web-0-vpa": "staging/Off", "web-1-vpa": "staging/Off", "web-2-vpa": "staging/Off"}, creators())
+
+	// Promote the namespace to production
+	ns.Labels = map[string]string{"env": "production"}
+	require.NoError(t, fakeClient.Update(ctx, ns))
+
+	// The previous VpaManager leaves the VPAs to the new one
+	reconcileManager("staging")
+	assert.Len(t, creators(), 3)
+
+	// The new VpaManager takes over two VPAs and requeues soon for the third
+	result := reconcileManager("production")
+	assert.Equal(t, handoverRequeueDelay, result.RequeueAfter)
+	assert.Equal(t, map[string]string{"web-0-vpa": "production/Initial", "web-1-vpa": "production/Initial", "web-2-vpa": "staging/Off"}, creators())
+	assert.Equal(t, "Normal VPAsHandedOver 2 VPAs moved from VpaManager staging to production, 1 more follow shortly", <-recorder.Events)
+
+	result = reconcileManager("production")
+	assert.Equal(t, DefaultResyncPeriod, result.RequeueAfter)
+	assert.Equal(t, "Normal VPAsHandedOver 1 VPAs moved from VpaManager staging to production", <-recorder.Events)
+
+	reconcileManager("staging")
+	assert.Equal(t, map[string]string{"web-0-vpa": "production/Initial", "web-1-vpa": "production/Initial", "web-2-vpa": "production/Initial"}, creators())
+
+	updated := &autoscalingv1.VpaManager{}
+	require.NoError(t, fakeClient.Get(ctx, types.NamespacedName{Name: "production"}, updated))
+	assert.Equal(t, 3, updated.Status.ManagedVPAs)
+}
@@ -0,0 +1,47 @@
+package controller
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	autoscalingv1 "github.com/joaomo/k8s_op_vpa/api/v1"
+)
+
+// vpaManagerSpecHash identifies a VpaManager spec in status.specHistory
+func vpaManagerSpecHash(data []byte) string {
+	hash := sha256.Sum256(data)
+	return fmt.Sprintf("%x", hash[:8])
+}
+
+// recordSpecHistory records the spec vpaManager was reconciled with in status.specHistory.
+// The first reconcile of a generation appends a revision, dropping the oldest beyond
+// MaxSpecHistory; later reconciles of that generation update its effect.
+func recordSpecHistory(status *autoscalingv1.VpaManagerStatus, vpaManager *autoscalingv1.VpaManager, now metav1.Time, managedVPAs int, lastErr error) {
+	history := status.SpecHistory
+	if n := len(history); n == 0 || history[n-1].Generation != vpaManager.Generation {
+		data, err := json.Marshal(vpaManager.Spec)
+		if err != nil {
+			return
+		}
+		history = append(history, autoscalingv1.SpecRevision{
+			Generation:         vpaManager.Generation,
+			Hash:               vpaManagerSpecHash(data),
+			Spec:               string(data),
+			FirstReconcileTime: now,
+		})
+		if len(history) > autoscalingv1.MaxSpecHistory {
+			history = history[len(history)-autoscalingv1.MaxSpecHistory:]
+		}
+	}
+
+	latest := &history[len(history)-1]
+	latest.ManagedVPAs = managedVPAs
+	latest.LastError = ""
+	if lastErr != nil {
+		latest.LastError = truncate(lastErr.Error(), maxRejectionMessageLength)
+	}
+	status.SpecHistory = history
+}
@@ -0,0 +1,83 @@
+package controller
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	autoscalingv1 "github.com/joaomo/k8s_op_vpa/api/v1"
+)
+
+func TestRecordSpecHistory(t *testing.T) {
+	revisions := func(generations ...int64) []autoscalingv1.SpecRevision {
+		var history []autoscalingv1.SpecRevision
+		for _, g := range generations {
+			history = append(history, autoscalingv1.SpecRevision{Generation: g, Hash: "old", ManagedVPAs: 1, LastError: "old error"})
+		}
+		return history
+	}
+
+	tests := []struct {
+		name                string
+		history             []autoscalingv1.SpecRevision
+		generation          int64
+		lastErr             error
+		expectedGenerations []int64
+		expectedLastError   string
+	}{
+		{
+			name:                "first reconcile",
+			generation:          1,
+			expectedGenerations: []int64{1},
+		},
+		{
+			name:                "new generation is appended",
+			history:             revisions(1, 2),
+			generation:          3,
+			lastErr:             errors.New("namespaces forbidden"),
+			expectedGenerations: []int64{1, 2, 3},
+			expectedLastError:   "namespaces forbidden",
+		},
+		{
+			name:                "same generation updates the effect",
+			history:             revisions(1, 2),
+			generation:          2,
+			expectedGenerations: []int64{1, 2},
+		},
+		{
+			name:                "oldest revision is dropped",
+			history:             revisions(1, 2, 3, 4, 5),
+			generation:          6,
+			expectedGenerations: []int64{2, 3, 4, 5, 6},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vm := &autoscalingv1.VpaManager{
+				ObjectMeta: metav1.ObjectMeta{Name: "prod", Generation: tt.generation},
+				Spec:       autoscalingv1.VpaManagerSpec{UpdateMode: "Auto"},
+			}
+			status := &autoscalingv1.VpaManagerStatus{SpecHistory: tt.history}
+			now := metav1.Now()
+
+			recordSpecHistory(status, vm, now, 4, tt.lastErr)
+
+			var generations []int64
+			for _, revision := range status.SpecHistory {
+				generations = append(generations, revision.Generation)
+			}
+			assert.Equal(t, tt.expectedGenerations, generations)
+			latest := status.SpecHistory[len(status.SpecHistory)-1]
+			assert.Equal(t, 4, latest.ManagedVPAs)
+			assert.Equal(t, tt.expectedLastError, latest.LastError)
+			if len(tt.history) == 0 || tt.history[len(tt.history)-1].Generation != tt.generation {
+				assert.Equal(t, `{"enabled":false,"updateMode":"Auto"}`, latest.Spec)
+				assert.Len(t, latest.Hash, 16)
+				assert.Equal(t, now, latest.FirstReconcileTime)
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package controller
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	autoscalingv1 "github.com/joaomo/k8s_op_vpa/api/v1"
+	"github.com/joaomo/k8s_op_vpa/internal/inheritance"
+)
+
+// findInheritingVpaManagers returns reconcile requests for every VpaManager that
+// inherits, directly or transitively, from the changed one
+func (r *VpaManagerReconciler) findInheritingVpaManagers(ctx context.Context, obj client.Object) []reconcile.Request {
+	vpaManagerList := &autoscalingv1.VpaManagerList{}
+	if err := r.List(ctx, vpaManagerList); err != nil {
+		return nil
+	}
+
+	children := map[string][]string{}
+	for _, vm := range vpaManagerList.Items {
+		if vm.Spec.InheritFrom != "" {
+			children[vm.Spec.InheritFrom] = append(children[vm.Spec.InheritFrom], vm.Name)
+		}
+	}
+
+	requests := []reconcile.Request{}
+	visited := map[string]bool{obj.GetName(): true}
+	queue := []string{obj.GetName()}
+	for len(queue) > 0 {
+		name := queue[0]
+		queue = queue[1:]
+		for _, child := range children[name] {
+			if visited[child] {
+				continue
+			}
+			visited[child] = true
+			queue = append(queue, child)
+			requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Name: child}})
+		}
+	}
+	return requests
+}
+
+// findVpaManagersForProfile returns reconcile requests for every VpaManager that references
+// the changed profile ConfigMap, and for the VpaManagers inheriting from them
+func (r *VpaManagerReconciler) findVpaManagersForProfile(ctx context.Context, obj client.Object) []reconcile.Request {
+	vpaManagerList := &autoscalingv1.VpaManagerList{}
+	if err := r.List(ctx, vpaManagerList); err != nil {
+		return nil
+	}
+
+	requests := []reconcile.Request{}
+	seen := map[string]bool{}
+	add := func(name string) {
+		if !seen[name] {
+			seen[name] = true
+			requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Name: name}})
+		}
+	}
+	for i := range vpaManagerList.Items {
+		vm := &vpaManagerList.Items[i]
+		ref := vm.Spec.ProfileRef
+		if ref == nil || ref.Namespace != obj.GetNamespace() || ref.Name != obj.GetName() {
+			continue
+		}
+		add(vm.Name)
+		for _, req := range r.findInheritingVpaManagers(ctx, vm) {
+			add(req.Name)
+		}
+	}
+	return requests
+}
+
+// profilePredicate passes events of ConfigMaps labeled as profiles only
+func profilePredicate() predicate.Predicate {
+	return predicate.NewPredicateFuncs(func(obj client.Object) bool {
+		return obj.GetLabels()[inheritance.ProfileLabel] == "true"
+	})
+}
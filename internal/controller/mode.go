@@ -0,0 +1,74 @@
+package controller
+
+import (
+	"fmt"
+	"time"
+)
+
+// Mode selects which parts of the operator run in a process
+type Mode string
+
+const (
+	// ModeCombined runs the reconciler and, when enabled, the webhooks
+	ModeCombined Mode = "combined"
+
+	// ModeReconcileOnly never registers webhooks; the reconciler alone manages VPAs,
+	// with a shorter resync to make up for the missing admission-time updates
+	ModeReconcileOnly Mode = "reconcile-only"
+
+	// ModeWebhookOnly serves the webhooks without the reconciler, next to another
+	// instance that reconciles. It uses its own leader election lease, so it never
+	// competes with that instance.
+	ModeWebhookOnly Mode = "webhook-only"
+)
+
+// LeaderElectionID is the lease held by the instance that reconciles
+const LeaderElectionID = "vpa-operator.operators.joaomo.io"
+
+// WebhookLeaderElectionID is the lease held among webhook-only instances for
+// leader-only work such as webhook registration
+const WebhookLeaderElectionID = "vpa-operator-webhook.operators.joaomo.io"
+
+const (
+	// DefaultResyncPeriod is how often a VpaManager is reconciled without any change
+	DefaultResyncPeriod = 5 * time.Minute
+
+	// ReconcileOnlyResyncPeriod is the default resync period in ModeReconcileOnly
+	ReconcileOnlyResyncPeriod = time.Minute
+)
+
+// ParseMode parses the --mode flag
+func ParseMode(value string) (Mode, error) {
+	switch mode := Mode(value); mode {
+	case ModeCombined, ModeReconcileOnly, ModeWebhookOnly:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("unknown mode %q, must be one of %s, %s, %s", value, ModeCombined, ModeReconcileOnly, ModeWebhookOnly)
+	}
+}
+
+// RunsReconciler reports whether the VpaManager reconciler runs in this mode
+func (m Mode) RunsReconciler() bool {
+	return m != ModeWebhookOnly
+}
+
+// RunsWebhooks reports whether webhook handlers may be registered in this mode
+func (m Mode) RunsWebhooks() bool {
+	return m != ModeReconcileOnly
+}
+
+// LeaderElectionID returns the leader election lease of the mode's role
+func (m Mode) LeaderElectionID() string {
+	if m == ModeWebhookOnly {
+		return WebhookLeaderElectionID
+	}
+	return LeaderElectionID
+}
+
+// ResyncPeriod returns the default resync period of the mode
+func (m Mode) ResyncPeriod() time.Duration {
+	if m == ModeReconcileOnly {
+		return ReconcileOnlyResyncPeriod
+	}
+	return DefaultResyncPeriod
+}
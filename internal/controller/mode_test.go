@@ -0,0 +1,41 @@
+package controller
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMode(t *testing.T) {
+	tests := []struct {
+		value          string
+		wantErr        bool
+		runsWebhooks   bool
+		runsReconciler bool
+		leaseID        string
+		resync         time.Duration
+	}{
+		{value: "combined", runsWebhooks: true, runsReconciler: true, leaseID: LeaderElectionID, resync: DefaultResyncPeriod},
+		{value: "reconcile-only", runsReconciler: true, leaseID: LeaderElectionID, resync: ReconcileOnlyResyncPeriod},
+		{value: "webhook-only", runsWebhooks: true, leaseID: WebhookLeaderElectionID, resync: DefaultResyncPeriod},
+		{value: "", wantErr: true},
+		{value: "webhooks", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			mode, err := ParseMode(tt.value)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.runsWebhooks, mode.RunsWebhooks())
+			assert.Equal(t, tt.runsReconciler, mode.RunsReconciler())
+			assert.Equal(t, tt.leaseID, mode.LeaderElectionID())
+			assert.Equal(t, tt.resync, mode.ResyncPeriod())
+		})
+	}
+}
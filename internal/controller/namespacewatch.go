@@ -0,0 +1,46 @@
+package controller
+
+import (
+	"context"
+	"maps"
+
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+
+	"github.com/joaomo/k8s_op_vpa/internal/workload"
+)
+
+// namespaceLabelHandler enqueues the VpaManagers selecting a namespace. On updates the
+// VpaManagers selecting the old labels are enqueued too, so a namespace that loses the
+// enabling labels has its VPAs cleaned up right away instead of at the next resync. Updates
+// that leave the labels and the paused annotation alone cannot change selection and are dropped.
+func (r *VpaManagerReconciler) namespaceLabelHandler() handler.EventHandler {
+	enqueue := func(ctx context.Context, q workqueue.RateLimitingInterface, objs ...client.Object) {
+		for _, obj := range objs {
+			for _, req := range r.findVpaManagersForNamespace(ctx, obj) {
+				// The queue deduplicates requests selected by both label sets
+				q.Add(req)
+			}
+		}
+	}
+	return handler.Funcs{
+		CreateFunc: func(ctx context.Context, e event.CreateEvent, q workqueue.RateLimitingInterface) {
+			enqueue(ctx, q, e.Object)
+		},
+		UpdateFunc: func(ctx context.Context, e event.UpdateEvent, q workqueue.RateLimitingInterface) {
+			if maps.Equal(e.ObjectOld.GetLabels(), e.ObjectNew.GetLabels()) &&
+				workload.NamespacePaused(e.ObjectOld) == workload.NamespacePaused(e.ObjectNew) {
+				return
+			}
+			enqueue(ctx, q, e.ObjectOld, e.ObjectNew)
+		},
+		DeleteFunc: func(ctx context.Context, e event.DeleteEvent, q workqueue.RateLimitingInterface) {
+			enqueue(ctx, q, e.Object)
+		},
+		GenericFunc: func(ctx context.Context, e event.GenericEvent, q workqueue.RateLimitingInterface) {
+			enqueue(ctx, q, e.Object)
+		},
+	}
+}
@@ -0,0 +1,71 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+
+	autoscalingv1 "github.com/joaomo/k8s_op_vpa/api/v1"
+	"github.com/joaomo/k8s_op_vpa/internal/workload"
+)
+
+func TestNamespaceLabelHandler_Update(t *testing.T) {
+	scheme := setupScheme(t)
+	ctx := context.Background()
+
+	optIn := map[string]string{"vpa-enabled": "true"}
+	vpaManager := &autoscalingv1.VpaManager{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-vpamanager"},
+		Spec: autoscalingv1.VpaManagerSpec{
+			Enabled:           true,
+			MatchAllWorkloads: true,
+			NamespaceSelector: &metav1.LabelSelector{MatchLabels: optIn},
+		},
+	}
+	reconciler := &VpaManagerReconciler{
+		Client:          fake.NewClientBuilder().WithScheme(scheme).WithObjects(vpaManager).Build(),
+		Scheme:          scheme,
+		Metrics:         createTestMetrics(),
+		WorkloadConfigs: DefaultWorkloadConfigs(),
+	}
+
+	paused := map[string]string{workload.PausedAnnotation: "true"}
+
+	tests := []struct {
+		name           string
+		oldLabels      map[string]string
+		newLabels      map[string]string
+		oldAnnotations map[string]string
+		newAnnotations map[string]string
+		want           int
+	}{
+		{name: "gains the enabling labels", newLabels: optIn, want: 1},
+		{name: "loses the enabling labels", oldLabels: optIn, want: 1},
+		{name: "other label change while selected", oldLabels: optIn, newLabels: map[string]string{"vpa-enabled": "true", "team": "payments"}, want: 1},
+		{name: "other label change while not selected", newLabels: map[string]string{"team": "payments"}},
+		{name: "labels unchanged", oldLabels: optIn, newLabels: optIn},
+		{name: "paused", oldLabels: optIn, newLabels: optIn, newAnnotations: paused, want: 1},
+		{name: "resumed", oldLabels: optIn, newLabels: optIn, oldAnnotations: paused, want: 1},
+		{name: "other annotation change", oldLabels: optIn, newLabels: optIn, newAnnotations: map[string]string{"team": "payments"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())
+			defer q.ShutDown()
+
+			reconciler.namespaceLabelHandler().Update(ctx, event.UpdateEvent{
+				ObjectOld: &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "prod", Labels: tt.oldLabels, Annotations: tt.oldAnnotations}},
+				ObjectNew: &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "prod", Labels: tt.newLabels, Annotations: tt.newAnnotations}},
+			}, q)
+
+			assert.Equal(t, tt.want, q.Len())
+		})
+	}
+}
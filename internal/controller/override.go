@@ -0,0 +1,31 @@
+package controller
+
+import (
+	"context"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	autoscalingv1 "github.com/joaomo/k8s_op_vpa/api/v1"
+)
+
+// findVpaManagersForOverride returns reconcile requests for the VpaManagers affected by the
+// workload a VpaOverride targets, so creating, changing or deleting an override takes effect
+// without waiting for the resync
+func (r *VpaManagerReconciler) findVpaManagersForOverride(ctx context.Context, obj client.Object) []reconcile.Request {
+	override, ok := obj.(*autoscalingv1.VpaOverride)
+	if !ok {
+		return nil
+	}
+	for _, wc := range r.WorkloadConfigs {
+		if wc.Provider.Kind() != override.Spec.TargetRef.Kind {
+			continue
+		}
+		wl, err := wc.Provider.Get(ctx, r.Client, override.Namespace, override.Spec.TargetRef.Name)
+		if err != nil {
+			return nil
+		}
+		return r.findVpaManagersForWorkload(ctx, wl.GetObject())
+	}
+	return nil
+}
@@ -0,0 +1,225 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	autoscalingv1 "github.com/joaomo/k8s_op_vpa/api/v1"
+	"github.com/joaomo/k8s_op_vpa/internal/metrics"
+)
+
+// DefaultPermissionRecheckInterval is how often missing permissions are checked again
+const DefaultPermissionRecheckInterval = time.Minute
+
+// Permission is an API access the operator needs at cluster scope
+type Permission struct {
+	Group    string
+	Resource string
+	Verb     string
+
+	// Feature is what fails without the permission
+	Feature string
+}
+
+// GroupResource returns the resource in kubectl notation, e.g. deployments.apps or
+// vpamanagers.operators.joaomo.io/status
+func (p Permission) GroupResource() string {
+	resource, subresource, ok := strings.Cut(p.Resource, "/")
+	if p.Group != "" {
+		resource += "." + p.Group
+	}
+	if ok {
+		resource += "/" + subresource
+	}
+	return resource
+}
+
+// String describes the permission for the startup summary
+func (p Permission) String() string {
+	return fmt.Sprintf("%s %s (%s)", p.Verb, p.GroupResource(), p.Feature)
+}
+
+// PermissionCheck reviews the operator's own access with SelfSubjectAccessReviews when the
+// manager starts, independently of how the ClusterRole was installed. Missing permissions
+// are logged once with the rules to add, exported as vpa_operator_missing_rbac and keep the
+// operator unready; they are checked again until all are granted.
+type PermissionCheck struct {
+	Client          client.Client
+	Metrics         *metrics.Metrics
+	WorkloadConfigs []WorkloadConfig
+
+	// AnnotateWorkloads requires patch access to workloads, see VpaManagerReconciler
+	AnnotateWorkloads bool
+
+	// CheckpointWarmStart requires access to VPA checkpoints, see VpaManagerReconciler
+	CheckpointWarmStart bool
+
+	// TopologyGuard requires read access to nodes, see VpaManagerReconciler
+	TopologyGuard bool
+
+	// RecheckInterval is how often missing permissions are checked again; zero uses
+	// DefaultPermissionRecheckInterval
+	RecheckInterval time.Duration
+
+	Log logr.Logger
+
+	mu      sync.Mutex
+	checked bool
+	missing []Permission
+}
+
+// Start checks the operator's permissions and rechecks them until none is missing
+func (c *PermissionCheck) Start(ctx context.Context) error {
+	if c.Log.GetSink() == nil {
+		c.Log = ctrl.Log.WithName("permission-check")
+	}
+	interval := c.RecheckInterval
+	if interval <= 0 {
+		interval = DefaultPermissionRecheckInterval
+	}
+
+	reported := false
+	for {
+		missing, err := c.Check(ctx)
+		switch {
+		case err != nil:
+			c.Log.Error(err, "unable to check RBAC permissions")
+		case len(missing) == 0:
+			if reported {
+				c.Log.Info("all required RBAC permissions are granted")
+			}
+			return nil
+		case !reported:
+			c.Log.Error(nil, "the operator is missing RBAC permissions and stays unready until they are granted; "+
+				"add them to its ClusterRole and verify with kubectl auth can-i <verb> <resource> --as=system:serviceaccount:<namespace>:<name>",
+				"missing", permissionStrings(missing))
+			reported = true
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(interval):
+		}
+	}
+}
+
+// NeedLeaderElection makes every replica check its own permissions, since all of them report readiness
+func (c *PermissionCheck) NeedLeaderElection() bool {
+	return false
+}
+
+// Check reviews every required permission, records the result and returns the missing ones
+func (c *PermissionCheck) Check(ctx context.Context) ([]Permission, error) {
+	required, err := c.Required()
+	if err != nil {
+		return nil, err
+	}
+	var missing []Permission
+	for _, p := range required {
+		resource, subresource, _ := strings.Cut(p.Resource, "/")
+		review := &authorizationv1.SelfSubjectAccessReview{
+			Spec: authorizationv1.SelfSubjectAccessReviewSpec{
+				ResourceAttributes: &authorizationv1.ResourceAttributes{
+					Group:       p.Group,
+					Resource:    resource,
+					Subresource: subresource,
+					Verb:        p.Verb,
+				},
+			},
+		}
+		if err := c.Client.Create(ctx, review); err != nil {
+			return nil, fmt.Errorf("checking access to %s %s: %w", p.Verb, p.GroupResource(), err)
+		}
+		if !review.Status.Allowed {
+			missing = append(missing, p)
+		}
+		c.Metrics.SetMissingRBAC(p.GroupResource(), p.Verb, !review.Status.Allowed)
+	}
+
+	c.mu.Lock()
+	c.checked = true
+	c.missing = missing
+	c.mu.Unlock()
+	return missing, nil
+}
+
+// Checker is a readiness check that fails until the check has run and found nothing missing
+func (c *PermissionCheck) Checker(_ *http.Request) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.checked {
+		return fmt.Errorf("RBAC permissions have not been checked yet")
+	}
+	if len(c.missing) > 0 {
+		return fmt.Errorf("missing RBAC permissions: %s", strings.Join(permissionStrings(c.missing), ", "))
+	}
+	return nil
+}
+
+// Required returns the permissions the configured workload kinds and features need
+func (c *PermissionCheck) Required() ([]Permission, error) {
+	var required []Permission
+	add := func(group, resource, feature string, verbs ...string) {
+		for _, verb := range verbs {
+			required = append(required, Permission{Group: group, Resource: resource, Verb: verb, Feature: feature})
+		}
+	}
+
+	configs := c.WorkloadConfigs
+	if len(configs) == 0 {
+		configs = DefaultWorkloadConfigs()
+	}
+	for _, wc := range configs {
+		gvk, err := c.Client.GroupVersionKindFor(wc.Provider.NewObject())
+		if err != nil {
+			return nil, err
+		}
+		mapping, err := c.Client.RESTMapper().RESTMapping(gvk.GroupKind(), gvk.Version)
+		if meta.IsNoMatchError(err) {
+			// Not served, so not managed until it is
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("resolving the resource of %s: %w", wc.Provider.Kind(), err)
+		}
+		add(gvk.Group, mapping.Resource.Resource, "discovering "+wc.Provider.Kind()+"s", "get", "list", "watch")
+		if c.AnnotateWorkloads {
+			add(gvk.Group, mapping.Resource.Resource, "--annotate-workloads", "patch")
+		}
+	}
+
+	add(vpaGVK.Group, "verticalpodautoscalers", "managing VPAs", "get", "list", "watch", "create", "update", "patch", "delete")
+	if c.CheckpointWarmStart {
+		add(vpaGVK.Group, "verticalpodautoscalercheckpoints", "--checkpoint-warm-start", "list", "create")
+	}
+	add(autoscalingv1.GroupVersion.Group, "vpamanagers", "reading VpaManagers", "get", "list", "watch")
+	add(autoscalingv1.GroupVersion.Group, "vpamanagers/status", "reporting VpaManager status", "patch")
+	add(autoscalingv1.GroupVersion.Group, "vpaoverrides", "applying VpaOverrides", "list", "watch")
+	add("", "namespaces", "selecting namespaces", "get", "list", "watch")
+	add("", "configmaps", "reading profiles", "list", "watch")
+	if c.TopologyGuard {
+		add("", "nodes", "--topology-guard", "list", "watch")
+	}
+	add("", "events", "recording events", "create")
+	return required, nil
+}
+
+// permissionStrings describes permissions for logs and readiness errors
+func permissionStrings(permissions []Permission) []string {
+	out := make([]string, 0, len(permissions))
+	for _, p := range permissions {
+		out = append(out, p.String())
+	}
+	return out
+}
@@ -0,0 +1,120 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/api/meta/testrestmapper"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+func TestPermissionCheck_Check(t *testing.T) {
+	tests := []struct {
+		name              string
+		annotateWorkloads bool
+		topologyGuard     bool
+		denied            map[string]bool // "<verb> <group resource>"
+		reviewErr         error
+		expectedMissing   []string
+		expectedReady     bool
+		expectedErr       bool
+	}{
+		{
+			name:          "everything granted",
+			expectedReady: true,
+		},
+		{
+			name:            "missing workload and status access",
+			denied:          map[string]bool{"list statefulsets.apps": true, "patch vpamanagers.operators.joaomo.io/status": true},
+			expectedMissing: []string{"list statefulsets.apps", "patch vpamanagers.operators.joaomo.io/status"},
+		},
+		{
+			name:              "patch is only required when annotating workloads",
+			denied:            map[string]bool{"patch deployments.apps": true},
+			annotateWorkloads: false,
+			expectedReady:     true,
+		},
+		{
+			name:              "missing patch while annotating workloads",
+			denied:            map[string]bool{"patch deployments.apps": true},
+			annotateWorkloads: true,
+			expectedMissing:   []string{"patch deployments.apps"},
+		},
+		{
+			name:          "nodes are only required with the topology guard",
+			denied:        map[string]bool{"list nodes": true},
+			expectedReady: true,
+		},
+		{
+			name:            "missing nodes with the topology guard",
+			denied:          map[string]bool{"list nodes": true},
+			topologyGuard:   true,
+			expectedMissing: []string{"list nodes"},
+		},
+		{
+			name:        "review fails",
+			reviewErr:   errors.New("connection refused"),
+			expectedErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheme := setupScheme(t)
+			require.NoError(t, authorizationv1.AddToScheme(scheme))
+
+			fakeClient := fake.NewClientBuilder().
+				WithScheme(scheme).
+				WithRESTMapper(testrestmapper.TestOnlyStaticRESTMapper(scheme)).
+				WithInterceptorFuncs(interceptor.Funcs{
+					Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+						if tt.reviewErr != nil {
+							return tt.reviewErr
+						}
+						review := obj.(*authorizationv1.SelfSubjectAccessReview)
+						attrs := review.Spec.ResourceAttributes
+						resource := attrs.Resource
+						if attrs.Subresource != "" {
+							resource += "/" + attrs.Subresource
+						}
+						p := Permission{Group: attrs.Group, Resource: resource, Verb: attrs.Verb}
+						review.Status.Allowed = !tt.denied[p.Verb+" "+p.GroupResource()]
+						return nil
+					},
+				}).
+				Build()
+
+			m := createTestMetrics()
+			check := &PermissionCheck{Client: fakeClient, Metrics: m, AnnotateWorkloads: tt.annotateWorkloads, TopologyGuard: tt.topologyGuard}
+			assert.Error(t, check.Checker(nil), "not ready before the first check")
+
+			missing, err := check.Check(context.Background())
+			if tt.expectedErr {
+				assert.Error(t, err)
+				assert.Error(t, check.Checker(nil))
+				return
+			}
+			require.NoError(t, err)
+
+			got := make([]string, 0, len(missing))
+			for _, p := range missing {
+				got = append(got, p.Verb+" "+p.GroupResource())
+				assert.Equal(t, float64(1), testutil.ToFloat64(m.MissingRBAC.WithLabelValues(p.GroupResource(), p.Verb)))
+			}
+			assert.ElementsMatch(t, tt.expectedMissing, got)
+			assert.Equal(t, float64(0), testutil.ToFloat64(m.MissingRBAC.WithLabelValues("verticalpodautoscalers.autoscaling.k8s.io", "create")))
+			if tt.expectedReady {
+				assert.NoError(t, check.Checker(nil))
+			} else {
+				assert.ErrorContains(t, check.Checker(nil), "missing RBAC permissions")
+			}
+		})
+	}
+}
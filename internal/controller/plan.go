@@ -0,0 +1,211 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	autoscalingv1 "github.com/joaomo/k8s_op_vpa/api/v1"
+	"github.com/joaomo/k8s_op_vpa/internal/metrics"
+)
+
+// Operations of a PlannedChange
+const (
+	PlanCreate = "create"
+	PlanUpdate = "update"
+	PlanDelete = "delete"
+)
+
+// PlannedChange is a VPA write a reconcile would make
+type PlannedChange struct {
+	VpaManager string
+	Operation  string
+
+	// Live is the VPA in the cluster, nil for a create
+	Live *unstructured.Unstructured
+
+	// Desired is the VPA as the reconcile would write it, nil for a delete
+	Desired *unstructured.Unstructured
+}
+
+// Plan reconciles every enabled VpaManager, or only the one named, without writing anything
+// and returns the VPA writes the reconciles would make, ordered by VpaManager, namespace and
+// name. It runs Reconcile itself, so every rule of the operator applies, but the reconciles
+// leave no events, status, metrics or summary behind.
+func (r *VpaManagerReconciler) Plan(ctx context.Context, name string) ([]PlannedChange, error) {
+	vpaManagerList := &autoscalingv1.VpaManagerList{}
+	if err := r.List(ctx, vpaManagerList); err != nil {
+		return nil, err
+	}
+
+	planner := &planClient{Client: r.Client}
+	dryRun := &VpaManagerReconciler{
+		Client:              planner,
+		Scheme:              r.Scheme,
+		Metrics:             metrics.NewMetrics(prometheus.NewRegistry()),
+		WorkloadConfigs:     r.WorkloadConfigs,
+		SelectorDefaults:    r.SelectorDefaults,
+		StrictSelectors:     r.StrictSelectors,
+		Selectors:           r.Selectors,
+		OrphanBurstGuard:    r.OrphanBurstGuard,
+		MaxHandovers:        r.MaxHandovers,
+		Self:                r.Self,
+		Bootstrapped:        r.Bootstrapped,
+		RecordWorkloadLists: r.RecordWorkloadLists,
+		Ownership:           r.Ownership,
+		kinds:               r.kinds,
+	}
+	for i := range vpaManagerList.Items {
+		vm := &vpaManagerList.Items[i]
+		if !vm.Spec.Enabled || (name != "" && vm.Name != name) {
+			continue
+		}
+		planner.vpaManager = vm.Name
+		if _, err := dryRun.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: vm.Name}}); err != nil {
+			return nil, fmt.Errorf("planning VpaManager %s: %w", vm.Name, err)
+		}
+	}
+
+	sort.SliceStable(planner.changes, func(i, j int) bool {
+		a, b := planner.changes[i], planner.changes[j]
+		if a.VpaManager != b.VpaManager {
+			return a.VpaManager < b.VpaManager
+		}
+		if a.namespace() != b.namespace() {
+			return a.namespace() < b.namespace()
+		}
+		return a.name() < b.name()
+	})
+	return planner.changes, nil
+}
+
+// namespace returns the namespace of the VPA a change writes
+func (c PlannedChange) namespace() string {
+	if c.Desired != nil {
+		return c.Desired.GetNamespace()
+	}
+	return c.Live.GetNamespace()
+}
+
+// name returns the name of the VPA a change writes
+func (c PlannedChange) name() string {
+	if c.Desired != nil {
+		return c.Desired.GetName()
+	}
+	return c.Live.GetName()
+}
+
+// planClient reads through the wrapped client and records VPA writes instead of making them.
+// Writes of other objects, such as VpaManager status, are dropped.
+type planClient struct {
+	client.Client
+
+	// vpaManager is the VpaManager being reconciled
+	vpaManager string
+
+	changes []PlannedChange
+}
+
+// Create implements client.Writer
+func (c *planClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	if desired, ok := plannedVPA(obj); ok {
+		c.changes = append(c.changes, PlannedChange{VpaManager: c.vpaManager, Operation: PlanCreate, Desired: desired})
+	}
+	return nil
+}
+
+// Update implements client.Writer
+func (c *planClient) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	desired, ok := plannedVPA(obj)
+	if !ok {
+		return nil
+	}
+	live := &unstructured.Unstructured{}
+	live.SetGroupVersionKind(vpaGVK)
+	if err := c.Client.Get(ctx, client.ObjectKeyFromObject(obj), live); err != nil {
+		return err
+	}
+	c.changes = append(c.changes, PlannedChange{VpaManager: c.vpaManager, Operation: PlanUpdate, Live: live, Desired: desired})
+	return nil
+}
+
+// Delete implements client.Writer
+func (c *planClient) Delete(ctx context.Context, obj client.Object, opts ...client.DeleteOption) error {
+	if live, ok := plannedVPA(obj); ok {
+		c.changes = append(c.changes, PlannedChange{VpaManager: c.vpaManager, Operation: PlanDelete, Live: live})
+	}
+	return nil
+}
+
+// Patch implements client.Writer
+func (c *planClient) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	return nil
+}
+
+// DeleteAllOf implements client.Writer
+func (c *planClient) DeleteAllOf(ctx context.Context, obj client.Object, opts ...client.DeleteAllOfOption) error {
+	return nil
+}
+
+// Status implements client.StatusClient
+func (c *planClient) Status() client.SubResourceWriter {
+	return discardSubResourceWriter{}
+}
+
+// SubResource implements client.SubResourceClientConstructor
+func (c *planClient) SubResource(subResource string) client.SubResourceClient {
+	return discardSubResourceClient{SubResourceClient: c.Client.SubResource(subResource)}
+}
+
+// plannedVPA returns a copy of obj when it is a VPA
+func plannedVPA(obj client.Object) (*unstructured.Unstructured, bool) {
+	u, ok := obj.(*unstructured.Unstructured)
+	if !ok || u.GroupVersionKind() != vpaGVK {
+		return nil, false
+	}
+	return u.DeepCopy(), true
+}
+
+// discardSubResourceWriter drops every subresource write
+type discardSubResourceWriter struct{}
+
+// Create implements client.SubResourceWriter
+func (discardSubResourceWriter) Create(ctx context.Context, obj client.Object, subResource client.Object, opts ...client.SubResourceCreateOption) error {
+	return nil
+}
+
+// Update implements client.SubResourceWriter
+func (discardSubResourceWriter) Update(ctx context.Context, obj client.Object, opts ...client.SubResourceUpdateOption) error {
+	return nil
+}
+
+// Patch implements client.SubResourceWriter
+func (discardSubResourceWriter) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.SubResourcePatchOption) error {
+	return nil
+}
+
+// discardSubResourceClient reads subresources and drops writes to them
+type discardSubResourceClient struct {
+	client.SubResourceClient
+}
+
+// Create implements client.SubResourceWriter
+func (c discardSubResourceClient) Create(ctx context.Context, obj client.Object, subResource client.Object, opts ...client.SubResourceCreateOption) error {
+	return nil
+}
+
+// Update implements client.SubResourceWriter
+func (c discardSubResourceClient) Update(ctx context.Context, obj client.Object, opts ...client.SubResourceUpdateOption) error {
+	return nil
+}
+
+// Patch implements client.SubResourceWriter
+func (c discardSubResourceClient) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.SubResourcePatchOption) error {
+	return nil
+}
@@ -0,0 +1,101 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	autoscalingv1 "github.com/joaomo/k8s_op_vpa/api/v1"
+)
+
+// Test: Plan reports the creates, updates and deletes of every enabled VpaManager without writing
+func TestPlan_ReportsVPAWritesWithoutWriting(t *testing.T) {
+	scheme := setupScheme(t)
+	ctx := context.Background()
+
+	selected := map[string]string{"vpa-enabled": "true"}
+	namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "test-ns", Labels: selected}}
+	deployment := func(name string) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "test-ns", Labels: selected, UID: types.UID(name + "-uid")},
+			Spec:       createDeploymentSpec(),
+		}
+	}
+	vpaManager := func(name string, enabled bool) *autoscalingv1.VpaManager {
+		return &autoscalingv1.VpaManager{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec: autoscalingv1.VpaManagerSpec{
+				Enabled:            enabled,
+				UpdateMode:         "Auto",
+				NamespaceSelector:  &metav1.LabelSelector{MatchLabels: selected},
+				DeploymentSelector: &metav1.LabelSelector{MatchLabels: selected},
+			},
+		}
+	}
+	managedVPA := func(name, mode string) *unstructured.Unstructured {
+		obj := &unstructured.Unstructured{}
+		obj.SetGroupVersionKind(vpaGVK)
+		obj.SetName(name)
+		obj.SetNamespace("test-ns")
+		obj.SetLabels(map[string]string{
+			"app.kubernetes.io/managed-by": "vpa-operator",
+			"app.kubernetes.io/created-by": "test-vpamanager",
+		})
+		obj.Object["spec"] = map[string]interface{}{"updatePolicy": map[string]interface{}{"updateMode": mode}}
+		return obj
+	}
+
+	enabled := vpaManager("test-vpamanager", true)
+	disabled := vpaManager("disabled-vpamanager", false)
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(namespace, deployment("new"), deployment("stale"), enabled, disabled,
+			managedVPA("stale-vpa", "Off"), managedVPA("gone-vpa", "Auto")).
+		WithStatusSubresource(enabled, disabled).
+		Build()
+	reconciler := &VpaManagerReconciler{Client: fakeClient, Scheme: scheme, WorkloadConfigs: DefaultWorkloadConfigs()}
+
+	changes, err := reconciler.Plan(ctx, "")
+	require.NoError(t, err)
+
+	type planned struct{ vpaManager, operation, name string }
+	var got []planned
+	for _, change := range changes {
+		got = append(got, planned{change.VpaManager, change.Operation, change.name()})
+	}
+	assert.Equal(t, []planned{
+		{"test-vpamanager", PlanDelete, "gone-vpa"},
+		{"test-vpamanager", PlanCreate, "new-vpa"},
+		{"test-vpamanager", PlanUpdate, "stale-vpa"},
+	}, got)
+	update := changes[2]
+	liveMode, _, _ := unstructured.NestedString(update.Live.Object, "spec", "updatePolicy", "updateMode")
+	desiredMode, _, _ := unstructured.NestedString(update.Desired.Object, "spec", "updatePolicy", "updateMode")
+	assert.Equal(t, "Off", liveMode)
+	assert.Equal(t, "Auto", desiredMode)
+
+	// Nothing was written
+	vpaList := &unstructured.UnstructuredList{}
+	vpaList.SetGroupVersionKind(vpaGVK.GroupVersion().WithKind("VerticalPodAutoscalerList"))
+	require.NoError(t, fakeClient.List(ctx, vpaList))
+	require.Len(t, vpaList.Items, 2)
+	for _, item := range vpaList.Items {
+		assert.Empty(t, item.GetAnnotations(), "VPA %s should be untouched", item.GetName())
+	}
+	stored := &autoscalingv1.VpaManager{}
+	require.NoError(t, fakeClient.Get(ctx, types.NamespacedName{Name: "test-vpamanager"}, stored))
+	assert.Nil(t, stored.Status.LastReconcileTime)
+
+	// A named VpaManager limits the plan
+	changes, err = reconciler.Plan(ctx, "disabled-vpamanager")
+	require.NoError(t, err)
+	assert.Empty(t, changes)
+}
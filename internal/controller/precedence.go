@@ -0,0 +1,67 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	autoscalingv1 "github.com/joaomo/k8s_op_vpa/api/v1"
+)
+
+// rivals returns the enabled VpaManagers other than vpaManager, highest priority first.
+// Those that outrank vpaManager take the workloads they select too.
+func (r *VpaManagerReconciler) rivals(ctx context.Context, vpaManager *autoscalingv1.VpaManager) ([]autoscalingv1.VpaManager, error) {
+	vpaManagerList := &autoscalingv1.VpaManagerList{}
+	if err := r.List(ctx, vpaManagerList); err != nil {
+		return nil, err
+	}
+	var rivals []autoscalingv1.VpaManager
+	for _, vm := range vpaManagerList.Items {
+		if vm.Spec.Enabled && vm.Name != vpaManager.Name {
+			rivals = append(rivals, vm)
+		}
+	}
+	autoscalingv1.SortByPriority(rivals)
+	return rivals, nil
+}
+
+// overlap returns the first of rivals that also manages obj, a workload of kind in ns, nil
+// when none does. It manages the workload instead when it outranks the VpaManager reconciled.
+func (r *VpaManagerReconciler) overlap(ctx context.Context, rivals []autoscalingv1.VpaManager, kind string, ns *corev1.Namespace, obj client.Object) *autoscalingv1.VpaManager {
+	if len(rivals) == 0 {
+		return nil
+	}
+	wc, ok := r.workloadConfigFor(kind)
+	if !ok {
+		return nil
+	}
+	for i := range rivals {
+		if _, reason := r.matchWorkload(ctx, &rivals[i], wc, ns, obj); reason == "" {
+			return &rivals[i]
+		}
+	}
+	return nil
+}
+
+// setConflictingCondition reports whether other enabled VpaManagers select workloads this
+// one selects, naming one of them as an example
+func setConflictingCondition(status *autoscalingv1.VpaManagerStatus, generation int64, conflicting int, example string) {
+	condition := metav1.Condition{
+		Type:               autoscalingv1.ConditionConflicting,
+		Status:             metav1.ConditionFalse,
+		ObservedGeneration: generation,
+		Reason:             "NoOverlap",
+		Message:            "No other VpaManager selects the selected workloads",
+	}
+	if conflicting > 0 {
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = "OverlappingVpaManagers"
+		condition.Message = fmt.Sprintf("%d selected workloads are selected by other VpaManagers too (e.g. %s). Each is managed "+
+			"by the VpaManager with the highest spec.priority, of equal priorities by the name sorting first.", conflicting, example)
+	}
+	meta.SetStatusCondition(&status.Conditions, condition)
+}
@@ -0,0 +1,114 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	autoscalingv1 "github.com/joaomo/k8s_op_vpa/api/v1"
+	"github.com/joaomo/k8s_op_vpa/internal/vpa"
+)
+
+func TestReconcile_HighestPriorityVpaManagerWins(t *testing.T) {
+	scheme := setupScheme(t)
+	ctx := context.Background()
+
+	manager := func(name, mode string, priority int32) *autoscalingv1.VpaManager {
+		return &autoscalingv1.VpaManager{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec: autoscalingv1.VpaManagerSpec{
+				Enabled:            true,
+				Priority:           priority,
+				UpdateMode:         mode,
+				NamespaceSelector:  &metav1.LabelSelector{MatchLabels: map[string]string{"vpa-enabled": "true"}},
+				DeploymentSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"vpa-enabled": "true"}},
+			},
+		}
+	}
+	// The default name sorts first, so only the priority makes the other one win
+	fallback := manager("default", "Off", 0)
+	critical := manager("critical", "Initial", 10)
+	// Disabled VpaManagers never win, whatever their priority
+	disabled := manager("archived", "Auto", 100)
+	disabled.Spec.Enabled = false
+
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(fallback, critical, disabled,
+			&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "shop", Labels: map[string]string{"vpa-enabled": "true"}}},
+			&appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "shop", Labels: map[string]string{"vpa-enabled": "true"}},
+				Spec:       createDeploymentSpec(),
+			}).
+		WithStatusSubresource(fallback, critical, disabled).
+		Build()
+	reconciler := &VpaManagerReconciler{Client: fakeClient, Scheme: scheme, Metrics: createTestMetrics(), WorkloadConfigs: DefaultWorkloadConfigs()}
+	reconcileManager := func(name string) {
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: name}})
+		require.NoError(t, err)
+	}
+	listVPAs := func() []unstructured.Unstructured {
+		vpaList := newVPAList()
+		require.NoError(t, fakeClient.List(ctx, vpaList, client.InNamespace("shop")))
+		return vpaList.Items
+	}
+
+	// The outranked VpaManager leaves the workload alone, even when it reconciles first
+	reconcileManager("default")
+	assert.Empty(t, listVPAs())
+	status := &autoscalingv1.VpaManager{}
+	require.NoError(t, fakeClient.Get(ctx, types.NamespacedName{Name: "default"}, status))
+	assert.Zero(t, status.Status.ManagedVPAs)
+
+	reconcileManager("critical")
+	reconcileManager("default")
+	vpas := listVPAs()
+	require.Len(t, vpas, 1)
+	assert.Equal(t, "critical", vpas[0].GetLabels()[vpa.CreatedByLabel])
+	mode, _, _ := unstructured.NestedString(vpas[0].Object, "spec", "updatePolicy", "updateMode")
+	assert.Equal(t, "Initial", mode)
+
+	// Both sides of the overlap report it
+	for _, name := range []string{"critical", "default"} {
+		require.NoError(t, fakeClient.Get(ctx, types.NamespacedName{Name: name}, status))
+		assert.Equal(t, 1, status.Status.ConflictingWorkloads, name)
+		condition := meta.FindStatusCondition(status.Status.Conditions, autoscalingv1.ConditionConflicting)
+		require.NotNil(t, condition, name)
+		assert.Equal(t, metav1.ConditionTrue, condition.Status, name)
+		assert.Contains(t, condition.Message, "deployment shop/web", name)
+		assert.Equal(t, float64(1), testutil.ToFloat64(reconciler.Metrics.ConflictingWorkloads.WithLabelValues(name)), name)
+	}
+
+	// Raising the priority of the other VpaManager hands the VPA over
+	require.NoError(t, fakeClient.Get(ctx, types.NamespacedName{Name: "default"}, fallback))
+	fallback.Spec.Priority = 20
+	require.NoError(t, fakeClient.Update(ctx, fallback))
+	reconcileManager("critical")
+	reconcileManager("default")
+	vpas = listVPAs()
+	require.Len(t, vpas, 1)
+	assert.Equal(t, "default", vpas[0].GetLabels()[vpa.CreatedByLabel])
+	mode, _, _ = unstructured.NestedString(vpas[0].Object, "spec", "updatePolicy", "updateMode")
+	assert.Equal(t, "Off", mode)
+
+	// The conflict clears once the VpaManagers no longer overlap
+	require.NoError(t, fakeClient.Get(ctx, types.NamespacedName{Name: "critical"}, critical))
+	critical.Spec.Enabled = false
+	require.NoError(t, fakeClient.Update(ctx, critical))
+	reconcileManager("default")
+	require.NoError(t, fakeClient.Get(ctx, types.NamespacedName{Name: "default"}, status))
+	assert.Zero(t, status.Status.ConflictingWorkloads)
+	assert.True(t, meta.IsStatusConditionFalse(status.Status.Conditions, autoscalingv1.ConditionConflicting))
+}
@@ -0,0 +1,148 @@
+package controller
+
+import (
+	"maps"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	"github.com/joaomo/k8s_op_vpa/internal/vpa"
+	"github.com/joaomo/k8s_op_vpa/internal/workload"
+)
+
+// vpaCRDPredicate passes events of the VerticalPodAutoscaler CRD only
+func vpaCRDPredicate() predicate.Predicate {
+	return predicate.NewPredicateFuncs(func(obj client.Object) bool {
+		return obj.GetName() == vpa.CRDName
+	})
+}
+
+// workloadChangePredicate drops workload updates that cannot change the generated VPAs.
+// Status-only updates, which Deployments receive constantly during rollouts and scaling,
+// would otherwise trigger full VpaManager reconciles. Creates and deletes always pass.
+// topologyGuard also passes the updates the topology guard depends on.
+func workloadChangePredicate(topologyGuard bool) predicate.Predicate {
+	return predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			return workloadChanged(e.ObjectOld, e.ObjectNew, topologyGuard)
+		},
+	}
+}
+
+// workloadChanged reports whether an update touched anything VPA generation depends on:
+// labels for selector matching, annotations for propagateAnnotations, scaling to or from
+// zero replicas for dormancy, and the pod template containers and their resources for
+// templated bounds and the rightsizing score. With topologyGuard, the replica count and the
+// pod template labels, node selector and affinity count too for workloads with required pod
+// anti-affinity on their own pods before or after the update, as they decide whether
+// eviction is blocked.
+func workloadChanged(oldObj, newObj client.Object, topologyGuard bool) bool {
+	if oldObj == nil || newObj == nil {
+		return true
+	}
+	if !maps.Equal(oldObj.GetLabels(), newObj.GetLabels()) {
+		return true
+	}
+	if !vpa.WorkloadAnnotationsEqual(oldObj.GetAnnotations(), newObj.GetAnnotations()) {
+		return true
+	}
+	if scaledToZero(oldObj) != scaledToZero(newObj) {
+		return true
+	}
+	oldTemplate, newTemplate := podTemplateOf(oldObj), podTemplateOf(newObj)
+	if oldTemplate == nil || newTemplate == nil {
+		// Unknown workload type, do not risk missing a change
+		return true
+	}
+	if topologyGuard && (selfAntiAffine(oldObj, oldTemplate) || selfAntiAffine(newObj, newTemplate)) &&
+		topologyChanged(oldObj, newObj, oldTemplate, newTemplate) {
+		return true
+	}
+	return !equality.Semantic.DeepEqual(containerResources(oldTemplate), containerResources(newTemplate))
+}
+
+// podTemplateOf returns the pod template of a supported workload, nil otherwise
+func podTemplateOf(obj client.Object) *corev1.PodTemplateSpec {
+	switch o := obj.(type) {
+	case *appsv1.Deployment:
+		return &o.Spec.Template
+	case *appsv1.StatefulSet:
+		return &o.Spec.Template
+	case *appsv1.DaemonSet:
+		return &o.Spec.Template
+	default:
+		return nil
+	}
+}
+
+// selfAntiAffine reports whether a workload's pod template has required pod anti-affinity on
+// its own pods, without which the topology guard never blocks eviction
+func selfAntiAffine(obj client.Object, template *corev1.PodTemplateSpec) bool {
+	return len(workload.SelfAntiAffinityTopologyKeys(obj.GetNamespace(), template)) > 0
+}
+
+// topologyChanged reports whether an update touched what EvictionBlocked depends on
+func topologyChanged(oldObj, newObj client.Object, oldTemplate, newTemplate *corev1.PodTemplateSpec) bool {
+	if replicasOf(oldObj) != replicasOf(newObj) {
+		return true
+	}
+	if !maps.Equal(oldTemplate.Labels, newTemplate.Labels) || !maps.Equal(oldTemplate.Spec.NodeSelector, newTemplate.Spec.NodeSelector) {
+		return true
+	}
+	return !equality.Semantic.DeepEqual(oldTemplate.Spec.Affinity, newTemplate.Spec.Affinity)
+}
+
+// replicasOf returns the replica count of a Deployment or StatefulSet, 0 otherwise
+func replicasOf(obj client.Object) int32 {
+	switch o := obj.(type) {
+	case *appsv1.Deployment:
+		return (&workload.DeploymentWorkload{Deployment: o}).GetReplicas()
+	case *appsv1.StatefulSet:
+		return (&workload.StatefulSetWorkload{StatefulSet: o}).GetReplicas()
+	default:
+		return 0
+	}
+}
+
+// nodeTopologyPredicate passes the node events that can change the topology domains
+// counted by the topology guard: nodes added or removed, relabeled, or cordoned
+func nodeTopologyPredicate() predicate.Predicate {
+	return predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			oldNode, ok := e.ObjectOld.(*corev1.Node)
+			if !ok {
+				return true
+			}
+			newNode, ok := e.ObjectNew.(*corev1.Node)
+			if !ok {
+				return true
+			}
+			return !maps.Equal(oldNode.Labels, newNode.Labels) || oldNode.Spec.Unschedulable != newNode.Spec.Unschedulable
+		},
+	}
+}
+
+// scaledToZero reports whether a Deployment or StatefulSet is scaled to zero replicas
+func scaledToZero(obj client.Object) bool {
+	switch o := obj.(type) {
+	case *appsv1.Deployment:
+		return workload.ScaledToZero(&workload.DeploymentWorkload{Deployment: o})
+	case *appsv1.StatefulSet:
+		return workload.ScaledToZero(&workload.StatefulSetWorkload{StatefulSet: o})
+	default:
+		return false
+	}
+}
+
+// containerResources maps container names to their resource requirements
+func containerResources(template *corev1.PodTemplateSpec) map[string]corev1.ResourceRequirements {
+	out := make(map[string]corev1.ResourceRequirements, len(template.Spec.Containers))
+	for _, c := range template.Spec.Containers {
+		out[c.Name] = c.Resources
+	}
+	return out
+}
@@ -0,0 +1,206 @@
+package controller
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+
+	"github.com/joaomo/k8s_op_vpa/internal/vpa"
+)
+
+func TestWorkloadChangePredicate(t *testing.T) {
+	base := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "web",
+			Namespace:   "default",
+			Labels:      map[string]string{"vpa-enabled": "true"},
+			Annotations: map[string]string{"team": "payments"},
+			Generation:  1,
+		},
+		Spec: createDeploymentSpec(),
+	}
+
+	tests := []struct {
+		name   string
+		mutate func(d *appsv1.Deployment)
+		want   bool
+	}{
+		{
+			name: "status only",
+			mutate: func(d *appsv1.Deployment) {
+				d.Status.ReadyReplicas = 3
+				d.ResourceVersion = "2"
+			},
+		},
+		{
+			name: "replica count",
+			mutate: func(d *appsv1.Deployment) {
+				replicas := int32(3)
+				d.Spec.Replicas = &replicas
+			},
+		},
+		{
+			name:   "scaled to zero",
+			mutate: func(d *appsv1.Deployment) { d.Spec.Replicas = new(int32) },
+			want:   true,
+		},
+		{
+			name:   "image change",
+			mutate: func(d *appsv1.Deployment) { d.Spec.Template.Spec.Containers[0].Image = "nginx:1.27" },
+		},
+		{
+			name:   "label change",
+			mutate: func(d *appsv1.Deployment) { d.Labels = map[string]string{"vpa-enabled": "false"} },
+			want:   true,
+		},
+		{
+			name:   "annotation change",
+			mutate: func(d *appsv1.Deployment) { d.Annotations["team"] = "search" },
+			want:   true,
+		},
+		{
+			name:   "operator annotation",
+			mutate: func(d *appsv1.Deployment) { d.Annotations[vpa.WorkloadVPAAnnotation] = "web-vpa,mode=Auto" },
+		},
+		{
+			name: "container resources",
+			mutate: func(d *appsv1.Deployment) {
+				d.Spec.Template.Spec.Containers[0].Resources.Requests = corev1.ResourceList{
+					corev1.ResourceMemory: resource.MustParse("256Mi"),
+				}
+			},
+			want: true,
+		},
+		{
+			name: "container added",
+			mutate: func(d *appsv1.Deployment) {
+				d.Spec.Template.Spec.Containers = append(d.Spec.Template.Spec.Containers, corev1.Container{Name: "sidecar"})
+			},
+			want: true,
+		},
+	}
+
+	p := workloadChangePredicate(false)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			updated := base.DeepCopy()
+			tt.mutate(updated)
+			assert.Equal(t, tt.want, p.Update(event.UpdateEvent{ObjectOld: base, ObjectNew: updated}))
+		})
+	}
+
+	assert.True(t, p.Create(event.CreateEvent{Object: base}))
+	assert.True(t, p.Delete(event.DeleteEvent{Object: base}))
+}
+
+func TestWorkloadChanged_UnknownType(t *testing.T) {
+	var oldObj, newObj client.Object = &corev1.Pod{}, &corev1.Pod{}
+	assert.True(t, workloadChanged(oldObj, newObj, false))
+}
+
+// Test: with the topology guard, updates that can block or unblock eviction pass for
+// workloads with required pod anti-affinity on their own pods, and only for them
+func TestWorkloadChangePredicate_TopologyGuard(t *testing.T) {
+	antiAffinity := func(d *appsv1.Deployment) {
+		d.Spec.Template.Spec.Affinity = &corev1.Affinity{PodAntiAffinity: &corev1.PodAntiAffinity{
+			RequiredDuringSchedulingIgnoredDuringExecution: []corev1.PodAffinityTerm{{
+				LabelSelector: &metav1.LabelSelector{MatchLabels: d.Spec.Template.Labels},
+				TopologyKey:   "kubernetes.io/hostname",
+			}},
+		}}
+	}
+	fewerReplicas := func(d *appsv1.Deployment) {
+		fewer := int32(2)
+		d.Spec.Replicas = &fewer
+	}
+	replicas := int32(3)
+	plain := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+		Spec:       createDeploymentSpec(),
+	}
+	plain.Spec.Replicas = &replicas
+	guarded := plain.DeepCopy()
+	antiAffinity(guarded)
+
+	tests := []struct {
+		name   string
+		base   *appsv1.Deployment
+		mutate func(d *appsv1.Deployment)
+		want   bool
+	}{
+		{
+			name:   "status only",
+			base:   guarded,
+			mutate: func(d *appsv1.Deployment) { d.Status.ReadyReplicas = 3 },
+		},
+		{
+			name:   "replica count without scaling to zero",
+			base:   guarded,
+			mutate: fewerReplicas,
+			want:   true,
+		},
+		{
+			name:   "node selector",
+			base:   guarded,
+			mutate: func(d *appsv1.Deployment) { d.Spec.Template.Spec.NodeSelector = map[string]string{"pool": "batch"} },
+			want:   true,
+		},
+		{
+			name:   "anti-affinity removed",
+			base:   guarded,
+			mutate: func(d *appsv1.Deployment) { d.Spec.Template.Spec.Affinity = nil },
+			want:   true,
+		},
+		{
+			name:   "anti-affinity added",
+			base:   plain,
+			mutate: antiAffinity,
+			want:   true,
+		},
+		{
+			name:   "replica count without anti-affinity",
+			base:   plain,
+			mutate: fewerReplicas,
+		},
+		{
+			name:   "node selector without anti-affinity",
+			base:   plain,
+			mutate: func(d *appsv1.Deployment) { d.Spec.Template.Spec.NodeSelector = map[string]string{"pool": "batch"} },
+		},
+	}
+
+	p := workloadChangePredicate(true)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			updated := tt.base.DeepCopy()
+			tt.mutate(updated)
+			assert.Equal(t, tt.want, p.Update(event.UpdateEvent{ObjectOld: tt.base, ObjectNew: updated}))
+		})
+	}
+}
+
+func TestNodeTopologyPredicate(t *testing.T) {
+	base := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1", Labels: map[string]string{"topology.kubernetes.io/zone": "a"}}}
+
+	p := nodeTopologyPredicate()
+	assert.True(t, p.Create(event.CreateEvent{Object: base}))
+	assert.True(t, p.Delete(event.DeleteEvent{Object: base}))
+
+	heartbeat := base.DeepCopy()
+	heartbeat.Status.Conditions = []corev1.NodeCondition{{Type: corev1.NodeReady, Status: corev1.ConditionTrue}}
+	assert.False(t, p.Update(event.UpdateEvent{ObjectOld: base, ObjectNew: heartbeat}), "status only")
+
+	relabeled := base.DeepCopy()
+	relabeled.Labels["topology.kubernetes.io/zone"] = "b"
+	assert.True(t, p.Update(event.UpdateEvent{ObjectOld: base, ObjectNew: relabeled}), "label change")
+
+	cordoned := base.DeepCopy()
+	cordoned.Spec.Unschedulable = true
+	assert.True(t, p.Update(event.UpdateEvent{ObjectOld: base, ObjectNew: cordoned}), "cordoned")
+}
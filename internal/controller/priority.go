@@ -0,0 +1,65 @@
+package controller
+
+import (
+	"context"
+	"sort"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/joaomo/k8s_op_vpa/internal/labelselector"
+	"github.com/joaomo/k8s_op_vpa/internal/workload"
+)
+
+// RolloutPriorityAnnotation orders VPA generation when a VpaManager matches many workloads.
+// Namespaces with a higher priority are processed first, and within a namespace, workloads
+// with a priority are processed before the others, highest first. The value is a
+// non-negative integer; anything else counts as no priority.
+const RolloutPriorityAnnotation = "vpa-operator.io/rollout-priority"
+
+// workloadPriority returns the rollout priority of a namespace or workload, 0 when unset or invalid
+func workloadPriority(obj metav1.Object) int {
+	value, ok := obj.GetAnnotations()[RolloutPriorityAnnotation]
+	if !ok {
+		return 0
+	}
+	priority, err := strconv.Atoi(value)
+	if err != nil || priority < 0 {
+		return 0
+	}
+	return priority
+}
+
+// orderByPriority returns the namespaces sorted by descending rollout priority, keeping
+// the list order among namespaces of the same priority
+func orderByPriority(namespaces []corev1.Namespace) []corev1.Namespace {
+	ordered := append([]corev1.Namespace(nil), namespaces...)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return workloadPriority(&ordered[i]) > workloadPriority(&ordered[j])
+	})
+	return ordered
+}
+
+// prioritizedWorkloads returns the selected workloads of a namespace that have a rollout
+// priority, highest first. Only these are held in memory; the rest of the namespace is
+// streamed afterwards. Listing errors are left to that second pass to report.
+func (r *VpaManagerReconciler) prioritizedWorkloads(ctx context.Context, selectors map[string]labelselector.Any, namespace string) []workload.Workload {
+	var prioritized []workload.Workload
+	for _, wc := range r.WorkloadConfigs {
+		selector, ok := selectors[wc.Provider.Kind()]
+		if !ok {
+			continue
+		}
+		_ = workload.ForEachMatchingAny(ctx, r.Client, wc.Provider, namespace, selector, func(wl workload.Workload) (bool, error) {
+			if workloadPriority(wl.GetObject()) > 0 {
+				prioritized = append(prioritized, wl)
+			}
+			return true, nil
+		})
+	}
+	sort.SliceStable(prioritized, func(i, j int) bool {
+		return workloadPriority(prioritized[i].GetObject()) > workloadPriority(prioritized[j].GetObject())
+	})
+	return prioritized
+}
@@ -0,0 +1,135 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	autoscalingv1 "github.com/joaomo/k8s_op_vpa/api/v1"
+)
+
+func TestWorkloadPriority(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		expected    int
+	}{
+		{name: "no annotation", expected: 0},
+		{name: "priority", annotations: map[string]string{RolloutPriorityAnnotation: "100"}, expected: 100},
+		{name: "negative", annotations: map[string]string{RolloutPriorityAnnotation: "-1"}, expected: 0},
+		{name: "not a number", annotations: map[string]string{RolloutPriorityAnnotation: "high"}, expected: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, workloadPriority(&metav1.ObjectMeta{Annotations: tt.annotations}))
+		})
+	}
+}
+
+func TestOrderByPriority(t *testing.T) {
+	namespace := func(name, priority string) corev1.Namespace {
+		ns := corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name}}
+		if priority != "" {
+			ns.Annotations = map[string]string{RolloutPriorityAnnotation: priority}
+		}
+		return ns
+	}
+	namespaces := []corev1.Namespace{
+		namespace("a", ""),
+		namespace("b", "10"),
+		namespace("c", ""),
+		namespace("d", "20"),
+		namespace("e", "10"),
+	}
+
+	var names []string
+	for _, ns := range orderByPriority(namespaces) {
+		names = append(names, ns.Name)
+	}
+	assert.Equal(t, []string{"d", "b", "e", "a", "c"}, names)
+	assert.Equal(t, "a", namespaces[0].Name, "input should not be reordered")
+}
+
+// Test: VPAs are created in rollout priority order, namespaces first
+func TestReconcile_CreatesVPAsInPriorityOrder(t *testing.T) {
+	scheme := setupScheme(t)
+	ctx := context.Background()
+
+	selected := map[string]string{"vpa-enabled": "true"}
+	namespace := func(name, priority string) *corev1.Namespace {
+		ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: selected}}
+		if priority != "" {
+			ns.Annotations = map[string]string{RolloutPriorityAnnotation: priority}
+		}
+		return ns
+	}
+	deployment := func(namespace, name, priority string) *appsv1.Deployment {
+		d := &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, Labels: selected},
+			Spec:       createDeploymentSpec(),
+		}
+		if priority != "" {
+			d.Annotations = map[string]string{RolloutPriorityAnnotation: priority}
+		}
+		return d
+	}
+	vpaManager := &autoscalingv1.VpaManager{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-vpamanager"},
+		Spec: autoscalingv1.VpaManagerSpec{
+			Enabled:            true,
+			UpdateMode:         "Off",
+			NamespaceSelector:  &metav1.LabelSelector{MatchLabels: selected},
+			DeploymentSelector: &metav1.LabelSelector{MatchLabels: selected},
+		},
+	}
+
+	var created []string
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(
+			vpaManager,
+			namespace("batch", ""),
+			namespace("payments", "100"),
+			deployment("batch", "report", ""),
+			deployment("payments", "api", ""),
+			deployment("payments", "checkout", "10"),
+			deployment("payments", "ledger", "50"),
+		).
+		WithStatusSubresource(vpaManager).
+		WithInterceptorFuncs(interceptor.Funcs{
+			Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+				if _, ok := obj.(*unstructured.Unstructured); ok {
+					created = append(created, obj.GetNamespace()+"/"+obj.GetName())
+				}
+				return c.Create(ctx, obj, opts...)
+			},
+		}).
+		Build()
+
+	reconciler := &VpaManagerReconciler{Client: fakeClient, Scheme: scheme, Metrics: createTestMetrics(), WorkloadConfigs: DefaultWorkloadConfigs()}
+	_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: "test-vpamanager"}})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		"payments/ledger-vpa",
+		"payments/checkout-vpa",
+		"payments/api-vpa",
+		"batch/report-vpa",
+	}, created)
+
+	updated := &autoscalingv1.VpaManager{}
+	require.NoError(t, fakeClient.Get(ctx, types.NamespacedName{Name: "test-vpamanager"}, updated))
+	assert.Equal(t, 4, updated.Status.ManagedVPAs, "prioritized workloads should be counted once")
+}
@@ -0,0 +1,76 @@
+package controller
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/joaomo/k8s_op_vpa/internal/vpa"
+)
+
+// vpaRepairWindow is how long after its deletion a recreated VPA still counts as repaired.
+// Deletions the operator made itself, e.g. of orphans, are never recreated and expire.
+const vpaRepairWindow = 10 * time.Minute
+
+// deletedVPAs remembers when managed VPAs were deleted, so the reconcile recreating one can
+// tell a repair from a first creation. The zero value is ready to use.
+type deletedVPAs struct {
+	mu   sync.Mutex
+	seen map[types.NamespacedName]time.Time
+}
+
+// record remembers the deletion of the VPA key at now, dropping deletions past the repair window
+func (d *deletedVPAs) record(key types.NamespacedName, now time.Time) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.seen == nil {
+		d.seen = map[types.NamespacedName]time.Time{}
+	}
+	for k, deleted := range d.seen {
+		if now.Sub(deleted) > vpaRepairWindow {
+			delete(d.seen, k)
+		}
+	}
+	d.seen[key] = now
+}
+
+// take reports whether the VPA key was deleted within the repair window and forgets it
+func (d *deletedVPAs) take(key types.NamespacedName, now time.Time) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	deleted, ok := d.seen[key]
+	delete(d.seen, key)
+	return ok && now.Sub(deleted) <= vpaRepairWindow
+}
+
+// managedVPADeletionPredicate passes deletions of VPAs this operator instance generated
+// only. The VpaManager that created a VPA is reconciled when it is deleted, so a VPA deleted
+// by hand or by another tool is recreated right away rather than at the next resync.
+func managedVPADeletionPredicate(ownership vpa.Ownership) predicate.Predicate {
+	return predicate.Funcs{
+		CreateFunc:  func(event.CreateEvent) bool { return false },
+		UpdateFunc:  func(event.UpdateEvent) bool { return false },
+		GenericFunc: func(event.GenericEvent) bool { return false },
+		DeleteFunc: func(e event.DeleteEvent) bool {
+			_, generated := e.Object.GetLabels()[vpa.CreatedByLabel]
+			return generated && ownership.Owns(e.Object)
+		},
+	}
+}
+
+// findVpaManagerForDeletedVPA returns a reconcile request for the VpaManager that created a
+// deleted VPA, and remembers the deletion so recreating the VPA counts as a repair
+func (r *VpaManagerReconciler) findVpaManagerForDeletedVPA(_ context.Context, obj client.Object) []reconcile.Request {
+	creator := obj.GetLabels()[vpa.CreatedByLabel]
+	if creator == "" {
+		return nil
+	}
+	r.deletedVPAs.record(client.ObjectKeyFromObject(obj), time.Now())
+	return []reconcile.Request{{NamespacedName: types.NamespacedName{Name: creator}}}
+}
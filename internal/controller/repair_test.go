@@ -0,0 +1,133 @@
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	autoscalingv1 "github.com/joaomo/k8s_op_vpa/api/v1"
+	"github.com/joaomo/k8s_op_vpa/internal/vpa"
+)
+
+func TestDeletedVPAs(t *testing.T) {
+	key := types.NamespacedName{Namespace: "shop", Name: "web-vpa"}
+	deleted := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		record   bool
+		after    time.Duration
+		expected bool
+	}{
+		{name: "never deleted"},
+		{name: "deleted just now", record: true, after: time.Second, expected: true},
+		{name: "deleted at the end of the window", record: true, after: vpaRepairWindow, expected: true},
+		{name: "deleted before the window", record: true, after: vpaRepairWindow + time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var d deletedVPAs
+			if tt.record {
+				d.record(key, deleted)
+			}
+			assert.Equal(t, tt.expected, d.take(key, deleted.Add(tt.after)))
+			assert.False(t, d.take(key, deleted.Add(tt.after)), "a deletion is only taken once")
+		})
+	}
+}
+
+func TestManagedVPADeletionPredicate(t *testing.T) {
+	ownership := vpa.Ownership{}
+
+	tests := []struct {
+		name     string
+		labels   map[string]string
+		expected bool
+	}{
+		{name: "generated VPA", labels: ownership.Labels("production"), expected: true},
+		{name: "hand-made VPA"},
+		{name: "managed VPA without a creator", labels: ownership.Selector()},
+		{name: "VPA of another instance", labels: vpa.Ownership{Instance: "blue"}.Labels("production")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			obj := &metav1.PartialObjectMetadata{ObjectMeta: metav1.ObjectMeta{Name: "web-vpa", Namespace: "shop", Labels: tt.labels}}
+			p := managedVPADeletionPredicate(ownership)
+
+			assert.Equal(t, tt.expected, p.Delete(event.DeleteEvent{Object: obj}))
+			assert.False(t, p.Create(event.CreateEvent{Object: obj}))
+			assert.False(t, p.Update(event.UpdateEvent{ObjectOld: obj, ObjectNew: obj}))
+		})
+	}
+}
+
+// Test: a VPA deleted behind the operator's back is recreated by the reconcile its deletion
+// triggers, counted as a repair rather than a creation
+func TestReconcile_RepairsDeletedVPA(t *testing.T) {
+	scheme := setupScheme(t)
+	ctx := context.Background()
+
+	vpaManager := &autoscalingv1.VpaManager{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-vpamanager"},
+		Spec: autoscalingv1.VpaManagerSpec{
+			Enabled:            true,
+			UpdateMode:         "Off",
+			MatchAllNamespaces: true,
+			MatchAllWorkloads:  true,
+		},
+	}
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(
+			vpaManager,
+			&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "shop"}},
+			&appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "shop", UID: "web-uid"},
+				Spec:       createDeploymentSpec(),
+			},
+		).
+		WithStatusSubresource(vpaManager).
+		Build()
+	m := createTestMetrics()
+	reconciler := &VpaManagerReconciler{
+		Client:          fakeClient,
+		Scheme:          scheme,
+		Metrics:         m,
+		WorkloadConfigs: DefaultWorkloadConfigs(),
+	}
+	req := reconcile.Request{NamespacedName: types.NamespacedName{Name: "test-vpamanager"}}
+
+	_, err := reconciler.Reconcile(ctx, req)
+	require.NoError(t, err)
+	vpaList := newVPAList()
+	require.NoError(t, fakeClient.List(ctx, vpaList))
+	require.Len(t, vpaList.Items, 1)
+
+	deleted := vpaList.Items[0].DeepCopy()
+	require.NoError(t, fakeClient.Delete(ctx, deleted))
+	assert.Equal(t, []reconcile.Request{req}, reconciler.findVpaManagerForDeletedVPA(ctx, deleted))
+
+	_, err = reconciler.Reconcile(ctx, req)
+	require.NoError(t, err)
+	vpaList = newVPAList()
+	require.NoError(t, fakeClient.List(ctx, vpaList, client.InNamespace("shop")))
+	require.Len(t, vpaList.Items, 1)
+	assert.Equal(t, "web-vpa", vpaList.Items[0].GetName())
+
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.VPAOperationsTotal.WithLabelValues("create", "test-vpamanager")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.VPAOperationsTotal.WithLabelValues("repair", "test-vpamanager")))
+}
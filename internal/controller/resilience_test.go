@@ -0,0 +1,255 @@
+package controller
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	clienttesting "k8s.io/client-go/testing"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	autoscalingv1 "github.com/joaomo/k8s_op_vpa/api/v1"
+	"github.com/joaomo/k8s_op_vpa/internal/faultinject"
+	"github.com/joaomo/k8s_op_vpa/internal/metrics"
+	"github.com/joaomo/k8s_op_vpa/internal/vpa"
+)
+
+// Test: API failures degrade a reconcile instead of aborting it, and are reported and retried
+func TestReconcile_Resilience(t *testing.T) {
+	tests := []struct {
+		name string
+		// rules are injected into every reconcile
+		rules      []faultinject.Rule
+		reconciles int
+		// expectedErr is checked against the error of the last reconcile; nil expects none
+		expectedErr func(error) bool
+		// expectedVPAs are the namespaces holding a VPA afterwards
+		expectedVPAs []string
+		check        func(t *testing.T, status autoscalingv1.VpaManagerStatus, m *metrics.Metrics)
+	}{
+		{
+			name:         "conflicting VPA write is reported and other namespaces converge",
+			rules:        []faultinject.Rule{{Verb: "create", Kind: "VerticalPodAutoscaler", Namespace: "team-a", Fault: faultinject.FaultConflict}},
+			reconciles:   1,
+			expectedVPAs: []string{"team-b"},
+			check: func(t *testing.T, status autoscalingv1.VpaManagerStatus, m *metrics.Metrics) {
+				assert.True(t, strings.HasPrefix(status.LastError, "conflict: deployment team-a/api: "), "lastError = %q", status.LastError)
+				assert.False(t, meta.IsStatusConditionTrue(status.Conditions, autoscalingv1.ConditionHealthy))
+				assert.False(t, meta.IsStatusConditionTrue(status.Conditions, autoscalingv1.ConditionReady))
+				assert.Equal(t, float64(conflictBackoff.Steps), testutil.ToFloat64(m.WriteConflictsTotal.WithLabelValues("test-vpamanager", metrics.ConflictObjectVPA)))
+			},
+		},
+		{
+			name:         "VPA write losing a race is retried within the reconcile",
+			rules:        []faultinject.Rule{{Verb: "create", Kind: "VerticalPodAutoscaler", Namespace: "team-a", Fault: faultinject.FaultConflict, Times: 1}},
+			reconciles:   1,
+			expectedVPAs: []string{"team-a", "team-b"},
+			check: func(t *testing.T, status autoscalingv1.VpaManagerStatus, m *metrics.Metrics) {
+				assert.Empty(t, status.LastError)
+				assert.Equal(t, float64(1), testutil.ToFloat64(m.WriteConflictsTotal.WithLabelValues("test-vpamanager", metrics.ConflictObjectVPA)))
+			},
+		},
+		{
+			name:         "status patch conflict is retried",
+			rules:        []faultinject.Rule{{Verb: "patch-status", Kind: "VpaManager", Fault: faultinject.FaultConflict, Times: 1}},
+			reconciles:   1,
+			expectedVPAs: []string{"team-a", "team-b"},
+			check: func(t *testing.T, status autoscalingv1.VpaManagerStatus, m *metrics.Metrics) {
+				assert.NotNil(t, status.LastReconcileTime)
+				assert.Equal(t, 2, status.ManagedVPAs)
+				assert.Equal(t, float64(1), testutil.ToFloat64(m.WriteConflictsTotal.WithLabelValues("test-vpamanager", metrics.ConflictObjectStatus)))
+			},
+		},
+		{
+			name:         "forbidden namespace is skipped",
+			rules:        []faultinject.Rule{{Verb: "list", Kind: "Deployment", Namespace: "team-a", Fault: faultinject.FaultForbidden}},
+			reconciles:   1,
+			expectedVPAs: []string{"team-b"},
+			check: func(t *testing.T, status autoscalingv1.VpaManagerStatus, m *metrics.Metrics) {
+				assert.Equal(t, []string{"team-a"}, status.ForbiddenNamespaces)
+				assert.Empty(t, status.LastError)
+				assert.True(t, meta.IsStatusConditionTrue(status.Conditions, autoscalingv1.ConditionHealthy))
+			},
+		},
+		{
+			name:         "unavailable API server fails the status write for a retry",
+			rules:        []faultinject.Rule{{Verb: "patch-status", Kind: "VpaManager", Fault: faultinject.FaultUnavailable}},
+			reconciles:   1,
+			expectedErr:  apierrors.IsServiceUnavailable,
+			expectedVPAs: []string{"team-a", "team-b"},
+			check: func(t *testing.T, status autoscalingv1.VpaManagerStatus, m *metrics.Metrics) {
+				assert.Nil(t, status.LastReconcileTime, "status should not be written")
+				assert.Equal(t, float64(1), testutil.ToFloat64(m.ReconcileTotal.WithLabelValues("test-vpamanager", metrics.ResultError, metrics.ErrorTypeAPIServer)))
+			},
+		},
+		{
+			name:         "transient failure converges on the next reconcile",
+			rules:        []faultinject.Rule{{Verb: "create", Kind: "VerticalPodAutoscaler", Fault: faultinject.FaultTimeout, Times: 1}},
+			reconciles:   2,
+			expectedVPAs: []string{"team-a", "team-b"},
+			check: func(t *testing.T, status autoscalingv1.VpaManagerStatus, m *metrics.Metrics) {
+				assert.Equal(t, 2, status.ManagedVPAs)
+				assert.True(t, meta.IsStatusConditionTrue(status.Conditions, autoscalingv1.ConditionHealthy))
+			},
+		},
+		{
+			name:         "slow API server still converges",
+			rules:        []faultinject.Rule{{Verb: faultinject.Wildcard, Kind: "Deployment", Delay: 10 * time.Millisecond}},
+			reconciles:   1,
+			expectedVPAs: []string{"team-a", "team-b"},
+			check: func(t *testing.T, status autoscalingv1.VpaManagerStatus, m *metrics.Metrics) {
+				assert.Equal(t, 2, status.ManagedVPAs)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheme := setupScheme(t)
+			ctx := context.Background()
+
+			selected := map[string]string{"vpa-enabled": "true"}
+			vpaManager := &autoscalingv1.VpaManager{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-vpamanager"},
+				Spec: autoscalingv1.VpaManagerSpec{
+					Enabled:            true,
+					UpdateMode:         "Off",
+					NamespaceSelector:  &metav1.LabelSelector{MatchLabels: selected},
+					DeploymentSelector: &metav1.LabelSelector{MatchLabels: selected},
+				},
+			}
+			objects := []client.Object{vpaManager}
+			for _, ns := range []string{"team-a", "team-b"} {
+				objects = append(objects,
+					&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: ns, Labels: selected}},
+					&appsv1.Deployment{
+						ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: ns, Labels: selected, UID: types.UID(ns + "-api")},
+						Spec:       createDeploymentSpec(),
+					},
+				)
+			}
+			fakeClient := fake.NewClientBuilder().
+				WithScheme(scheme).
+				WithObjects(objects...).
+				WithStatusSubresource(vpaManager).
+				Build()
+
+			m := createTestMetrics()
+			reconciler := &VpaManagerReconciler{
+				Client:          faultinject.New(tt.rules...).Wrap(fakeClient),
+				Scheme:          scheme,
+				Metrics:         m,
+				WorkloadConfigs: DefaultWorkloadConfigs(),
+			}
+
+			var err error
+			for i := 0; i < tt.reconciles; i++ {
+				_, err = reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: "test-vpamanager"}})
+			}
+			if tt.expectedErr != nil {
+				assert.True(t, tt.expectedErr(err), "unexpected error: %v", err)
+			} else {
+				require.NoError(t, err)
+			}
+
+			vpaList := newVPAList()
+			require.NoError(t, fakeClient.List(ctx, vpaList))
+			var namespaces []string
+			for _, item := range vpaList.Items {
+				namespaces = append(namespaces, item.GetNamespace())
+			}
+			assert.ElementsMatch(t, tt.expectedVPAs, namespaces)
+
+			updated := &autoscalingv1.VpaManager{}
+			require.NoError(t, fakeClient.Get(ctx, types.NamespacedName{Name: "test-vpamanager"}, updated))
+			tt.check(t, updated.Status, m)
+		})
+	}
+}
+
+// Test: without the VPA CRD a reconcile only reports it, and the CRD watch resumes reconciles once it is installed
+func TestReconcile_WaitsForVPACRD(t *testing.T) {
+	scheme := setupScheme(t)
+	ctx := context.Background()
+
+	selected := map[string]string{"vpa-enabled": "true"}
+	vpaManager := &autoscalingv1.VpaManager{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-vpamanager"},
+		Spec: autoscalingv1.VpaManagerSpec{
+			Enabled:            true,
+			UpdateMode:         "Off",
+			NamespaceSelector:  &metav1.LabelSelector{MatchLabels: selected},
+			DeploymentSelector: &metav1.LabelSelector{MatchLabels: selected},
+		},
+	}
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(
+			vpaManager,
+			&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "test-ns", Labels: selected}},
+			&appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "test-ns", Labels: selected, UID: "api-uid"},
+				Spec:       createDeploymentSpec(),
+			},
+		).
+		WithStatusSubresource(vpaManager).
+		Build()
+
+	discovery := &fakediscovery.FakeDiscovery{Fake: &clienttesting.Fake{}}
+	m := createTestMetrics()
+	reconciler := &VpaManagerReconciler{
+		Client:          fakeClient,
+		Scheme:          scheme,
+		Metrics:         m,
+		WorkloadConfigs: DefaultWorkloadConfigs(),
+		VPAAvailability: vpa.NewAvailability(discovery, time.Hour),
+	}
+	request := reconcile.Request{NamespacedName: types.NamespacedName{Name: "test-vpamanager"}}
+
+	result, err := reconciler.Reconcile(ctx, request)
+	require.NoError(t, err)
+	assert.Equal(t, time.Hour, result.RequeueAfter)
+	assert.Equal(t, float64(0), testutil.ToFloat64(m.VPAServed))
+
+	vpaList := newVPAList()
+	require.NoError(t, fakeClient.List(ctx, vpaList))
+	assert.Empty(t, vpaList.Items)
+	updated := &autoscalingv1.VpaManager{}
+	require.NoError(t, fakeClient.Get(ctx, request.NamespacedName, updated))
+	assert.Equal(t, metrics.ErrorTypeUnknown+": "+vpa.ErrNotServed.Error(), updated.Status.LastError)
+	assert.True(t, meta.IsStatusConditionTrue(updated.Status.Conditions, autoscalingv1.ConditionVPACRDMissing))
+	ready := meta.FindStatusCondition(updated.Status.Conditions, autoscalingv1.ConditionReady)
+	require.NotNil(t, ready)
+	assert.Equal(t, metav1.ConditionFalse, ready.Status)
+	assert.Equal(t, autoscalingv1.ConditionVPACRDMissing, ready.Reason)
+
+	// Installing the CRD invalidates the cached answer and re-enqueues the VpaManager
+	discovery.Resources = []*metav1.APIResourceList{{
+		GroupVersion: "autoscaling.k8s.io/v1",
+		APIResources: []metav1.APIResource{{Name: "verticalpodautoscalers"}},
+	}}
+	crd := &metav1.PartialObjectMetadata{ObjectMeta: metav1.ObjectMeta{Name: vpa.CRDName}}
+	assert.Equal(t, []reconcile.Request{request}, reconciler.findVpaManagersForVPACRD(ctx, crd))
+
+	_, err = reconciler.Reconcile(ctx, request)
+	require.NoError(t, err)
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.VPAServed))
+	require.NoError(t, fakeClient.List(ctx, vpaList))
+	assert.Len(t, vpaList.Items, 1)
+	require.NoError(t, fakeClient.Get(ctx, request.NamespacedName, updated))
+	assert.True(t, meta.IsStatusConditionFalse(updated.Status.Conditions, autoscalingv1.ConditionVPACRDMissing))
+	assert.True(t, meta.IsStatusConditionTrue(updated.Status.Conditions, autoscalingv1.ConditionReady))
+}
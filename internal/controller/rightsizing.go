@@ -0,0 +1,77 @@
+package controller
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/joaomo/k8s_op_vpa/internal/vpa"
+)
+
+// rightsizingResources are the resources that contribute to the rightsizing score
+var rightsizingResources = []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory}
+
+// rightsizingScore accumulates how closely container requests match VPA targets
+// across all workloads of a VpaManager. For each resource the score is
+// sum(min(request, target)) / sum(max(request, target)), so large containers weigh
+// more than small ones, and the final score is the mean across resources.
+type rightsizingScore struct {
+	lower map[corev1.ResourceName]float64
+	upper map[corev1.ResourceName]float64
+}
+
+func newRightsizingScore() *rightsizingScore {
+	return &rightsizingScore{
+		lower: map[corev1.ResourceName]float64{},
+		upper: map[corev1.ResourceName]float64{},
+	}
+}
+
+// add folds the requests of a pod template and the target recommendation of its VPA into the score
+func (s *rightsizingScore) add(template *corev1.PodTemplateSpec, vpaObj *unstructured.Unstructured) {
+	if template == nil || vpaObj == nil {
+		return
+	}
+
+	targets := vpa.Recommendations(vpaObj, vpa.RecommendationTarget)
+	if len(targets) == 0 {
+		return
+	}
+
+	for _, c := range template.Spec.Containers {
+		target, ok := targets[c.Name]
+		if !ok {
+			continue
+		}
+		for _, res := range rightsizingResources {
+			request, hasRequest := c.Resources.Requests[res]
+			recommended, hasTarget := target[res]
+			if !hasRequest || !hasTarget {
+				continue
+			}
+			req := request.AsApproximateFloat64()
+			rec := recommended.AsApproximateFloat64()
+			if req <= 0 && rec <= 0 {
+				continue
+			}
+			s.lower[res] += min(req, rec)
+			s.upper[res] += max(req, rec)
+		}
+	}
+}
+
+// value returns the score as a ratio between 0 and 1, and false if no data was collected
+func (s *rightsizingScore) value() (float64, bool) {
+	total := 0.0
+	resources := 0
+	for _, res := range rightsizingResources {
+		if s.upper[res] <= 0 {
+			continue
+		}
+		total += s.lower[res] / s.upper[res]
+		resources++
+	}
+	if resources == 0 {
+		return 0, false
+	}
+	return total / float64(resources), true
+}
@@ -0,0 +1,115 @@
+package controller
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestRightsizingScore(t *testing.T) {
+	tests := []struct {
+		name      string
+		requests  map[string]corev1.ResourceList
+		targets   map[string]map[string]interface{}
+		expected  float64
+		expectSet bool
+	}{
+		{
+			name:      "no recommendation yet",
+			requests:  map[string]corev1.ResourceList{"main": resources("500m", "256Mi")},
+			expectSet: false,
+		},
+		{
+			name:      "requests match targets exactly",
+			requests:  map[string]corev1.ResourceList{"main": resources("500m", "256Mi")},
+			targets:   map[string]map[string]interface{}{"main": {"cpu": "500m", "memory": "256Mi"}},
+			expected:  1.0,
+			expectSet: true,
+		},
+		{
+			name:      "over-provisioned by 2x",
+			requests:  map[string]corev1.ResourceList{"main": resources("1", "512Mi")},
+			targets:   map[string]map[string]interface{}{"main": {"cpu": "500m", "memory": "256Mi"}},
+			expected:  0.5,
+			expectSet: true,
+		},
+		{
+			name:      "under-provisioned by 4x",
+			requests:  map[string]corev1.ResourceList{"main": resources("250m", "128Mi")},
+			targets:   map[string]map[string]interface{}{"main": {"cpu": "1", "memory": "512Mi"}},
+			expected:  0.25,
+			expectSet: true,
+		},
+		{
+			name: "weighted by request size",
+			requests: map[string]corev1.ResourceList{
+				"big":   {corev1.ResourceCPU: resource.MustParse("3")},
+				"small": {corev1.ResourceCPU: resource.MustParse("100m")},
+			},
+			targets: map[string]map[string]interface{}{
+				"big":   {"cpu": "3"},
+				"small": {"cpu": "1100m"},
+			},
+			// (3 + 0.1) / (3 + 1.1)
+			expected:  3.1 / 4.1,
+			expectSet: true,
+		},
+		{
+			name:      "containers without requests are ignored",
+			requests:  map[string]corev1.ResourceList{"main": {}},
+			targets:   map[string]map[string]interface{}{"main": {"cpu": "500m"}},
+			expectSet: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			template := &corev1.PodTemplateSpec{}
+			for name, req := range tt.requests {
+				template.Spec.Containers = append(template.Spec.Containers, corev1.Container{
+					Name:      name,
+					Resources: corev1.ResourceRequirements{Requests: req},
+				})
+			}
+
+			score := newRightsizingScore()
+			score.add(template, vpaWithTargets(tt.targets))
+
+			value, ok := score.value()
+			assert.Equal(t, tt.expectSet, ok)
+			if tt.expectSet {
+				assert.InDelta(t, tt.expected, value, 0.001)
+			}
+		})
+	}
+}
+
+func resources(cpu, memory string) corev1.ResourceList {
+	return corev1.ResourceList{
+		corev1.ResourceCPU:    resource.MustParse(cpu),
+		corev1.ResourceMemory: resource.MustParse(memory),
+	}
+}
+
+func vpaWithTargets(targets map[string]map[string]interface{}) *unstructured.Unstructured {
+	vpa := &unstructured.Unstructured{Object: map[string]interface{}{}}
+	if targets == nil {
+		return vpa
+	}
+	recommendations := make([]interface{}, 0, len(targets))
+	for name, target := range targets {
+		recommendations = append(recommendations, map[string]interface{}{
+			"containerName": name,
+			"target":        target,
+		})
+	}
+	vpa.Object["status"] = map[string]interface{}{
+		"recommendation": map[string]interface{}{
+			"containerRecommendations": recommendations,
+		},
+	}
+	return vpa
+}
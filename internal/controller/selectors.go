@@ -0,0 +1,76 @@
+package controller
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	autoscalingv1 "github.com/joaomo/k8s_op_vpa/api/v1"
+	"github.com/joaomo/k8s_op_vpa/internal/workload"
+)
+
+// namespaceSelectors returns the namespace selectors for a spec, of which a namespace must
+// match any and where none select every namespace, and false when the spec selects no
+// namespaces by labels. A spec listing namespaces without a selector selects only those.
+func (r *VpaManagerReconciler) namespaceSelectors(spec *autoscalingv1.VpaManagerSpec) ([]*metav1.LabelSelector, bool) {
+	if spec.MatchAllNamespaces {
+		return nil, true
+	}
+	selectors := spec.NamespaceSelectorTerms()
+	if len(selectors) == 0 && (r.StrictSelectors || len(spec.Namespaces) > 0) {
+		return nil, false
+	}
+	return selectors, true
+}
+
+// selectsNamespaces reports whether a spec selects any namespace at all
+func (r *VpaManagerReconciler) selectsNamespaces(spec *autoscalingv1.VpaManagerSpec) bool {
+	_, ok := r.namespaceSelectors(spec)
+	return ok || len(spec.Namespaces) > 0
+}
+
+// selectsNamespace reports whether spec selects ns: excluded namespaces never are, listed
+// ones always are, and the rest must match the namespace selector
+func (r *VpaManagerReconciler) selectsNamespace(vm *autoscalingv1.VpaManager, spec *autoscalingv1.VpaManagerSpec, ns *corev1.Namespace) bool {
+	if spec.ExcludesNamespace(ns.Name) || r.excludedByLabels(vm, spec, ns) {
+		return false
+	}
+	if spec.ListsNamespace(ns.Name) {
+		return true
+	}
+	selectors, ok := r.namespaceSelectors(spec)
+	return ok && r.namespaceMatchesSelector(vm, ns, selectors)
+}
+
+// excludedByLabels reports whether the namespaceExcludeSelector of spec matches ns. A
+// selector that cannot be compiled excludes every namespace rather than none.
+func (r *VpaManagerReconciler) excludedByLabels(vm *autoscalingv1.VpaManager, spec *autoscalingv1.VpaManagerSpec, ns *corev1.Namespace) bool {
+	if spec.NamespaceExcludeSelector == nil {
+		return false
+	}
+	selector, err := r.Selectors.Compile(vm.Name, vm.Generation, namespaceExcludeSelectorField, spec.NamespaceExcludeSelector)
+	if err != nil {
+		return true
+	}
+	return selector.Matches(labels.Set(ns.Labels))
+}
+
+// workloadNames compiles the workloadNamePatterns of spec, nil when it has none
+func workloadNames(spec *autoscalingv1.VpaManagerSpec) (*workload.NameMatcher, error) {
+	if spec.WorkloadNamePatterns == nil {
+		return nil, nil
+	}
+	return workload.CompileNamePatterns(spec.WorkloadNamePatterns.Include, spec.WorkloadNamePatterns.Exclude)
+}
+
+// workloadSelectorTerms returns the selectors for one workload kind, of which a workload must
+// match any, and false when the kind is not managed
+func workloadSelectorTerms(spec *autoscalingv1.VpaManagerSpec, selectors []*metav1.LabelSelector) ([]*metav1.LabelSelector, bool) {
+	if len(selectors) > 0 {
+		return selectors, true
+	}
+	if spec.MatchAllWorkloads {
+		return []*metav1.LabelSelector{{}}, true
+	}
+	return nil, false
+}
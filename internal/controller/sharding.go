@@ -0,0 +1,149 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	autoscalingv1 "github.com/joaomo/k8s_op_vpa/api/v1"
+)
+
+// shardRequeueDelay is how long a split pass waits before its next chunk. The request goes
+// to the back of the workqueue, so other VpaManagers are reconciled in between.
+const shardRequeueDelay = 100 * time.Millisecond
+
+// shardToken is the position of a namespace in the processing order, "<priority>/<name>"
+func shardToken(ns *corev1.Namespace) string {
+	return fmt.Sprintf("%d/%s", workloadPriority(ns), ns.Name)
+}
+
+// shardOrder returns the namespaces in processing order: descending rollout priority, then
+// name. The order does not depend on list order, so a split pass can resume by position.
+func shardOrder(namespaces []corev1.Namespace) []corev1.Namespace {
+	sorted := append([]corev1.Namespace(nil), namespaces...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Name < sorted[j].Name })
+	return orderByPriority(sorted)
+}
+
+// resumeIndex returns the index of the first namespace in ordered at or after token, so a
+// pass continues correctly when the namespace it stopped at was deleted meanwhile
+func resumeIndex(ordered []corev1.Namespace, token string) int {
+	value, name, _ := strings.Cut(token, "/")
+	priority, err := strconv.Atoi(value)
+	if err != nil {
+		return 0
+	}
+	for i := range ordered {
+		p := workloadPriority(&ordered[i])
+		if p < priority || (p == priority && ordered[i].Name >= name) {
+			return i
+		}
+	}
+	return len(ordered)
+}
+
+// chunkFull reports whether a reconcile that started at start and processed the given
+// number of namespaces must leave the rest of its pass to the next chunk. A chunk always
+// processes at least one namespace.
+func (r *VpaManagerReconciler) chunkFull(processed int, start time.Time) bool {
+	if processed == 0 {
+		return false
+	}
+	return (r.ReconcileBudget > 0 && time.Since(start) >= r.ReconcileBudget) ||
+		(r.MaxNamespacesPerCycle > 0 && processed >= r.MaxNamespacesPerCycle)
+}
+
+// resumableProgress returns the progress of a split pass the next chunk continues, nil
+// when a new pass starts because there is none or the spec changed since it started
+func resumableProgress(vpaManager *autoscalingv1.VpaManager) *autoscalingv1.ReconcileProgress {
+	progress := vpaManager.Status.Progress
+	if progress == nil || progress.ObservedGeneration != vpaManager.Generation {
+		return nil
+	}
+	return progress.DeepCopy()
+}
+
+// addProgress adds the tallies of done, the chunks processed before, to chunk
+func addProgress(chunk, done *autoscalingv1.ReconcileProgress) {
+	if done == nil {
+		return
+	}
+	chunk.StartTime = done.StartTime
+	chunk.Chunks += done.Chunks
+	chunk.ManagedVPAs += done.ManagedVPAs
+	chunk.DeploymentCount += done.DeploymentCount
+	chunk.StatefulSetCount += done.StatefulSetCount
+	chunk.DaemonSetCount += done.DaemonSetCount
+	chunk.WatchedWorkloads += done.WatchedWorkloads
+	chunk.DriftedVPAs += done.DriftedVPAs
+	chunk.DormantVPAs += done.DormantVPAs
+	chunk.ForeignVPAs += done.ForeignVPAs
+	chunk.ConflictingWorkloads += done.ConflictingWorkloads
+	chunk.OrphanedVPAs += done.OrphanedVPAs
+	chunk.PendingOrphanDeletions += done.PendingOrphanDeletions
+	forbidden := map[string]bool{}
+	for _, ns := range append(chunk.ForbiddenNamespaces, done.ForbiddenNamespaces...) {
+		forbidden[ns] = true
+	}
+	chunk.ForbiddenNamespaces = namespaceList(forbidden, autoscalingv1.MaxForbiddenNamespaces)
+	paused := map[string]bool{}
+	for _, ns := range append(chunk.PausedNamespaces, done.PausedNamespaces...) {
+		paused[ns] = true
+	}
+	chunk.PausedNamespaces = namespaceList(paused, autoscalingv1.MaxPausedNamespaces)
+}
+
+// continuePass records the progress of an unfinished pass, with the orphan deletions held
+// back and the last error so far, and requeues the request for the next chunk. A burst is
+// only resolved once the pass completes, so later chunks are judged against the same one.
+func (r *VpaManagerReconciler) continuePass(ctx context.Context, log logr.Logger, vpaManager *autoscalingv1.VpaManager, progress *autoscalingv1.ReconcileProgress,
+	next *corev1.Namespace, burst burstDecision, lastErr error, start time.Time) (reconcile.Result, error) {
+	progress.Continue = shardToken(next)
+	progress.ObservedGeneration = vpaManager.Generation
+
+	statusUpdate := vpaManager.DeepCopy()
+	statusUpdate.Status.Progress = progress
+	if burst.blockedSince != nil {
+		statusUpdate.Status.OrphanDeletionsBlockedSince = burst.blockedSince
+		statusUpdate.Status.PendingOrphanDeletions = progress.PendingOrphanDeletions
+	}
+	if lastErr != nil {
+		setLastError(&statusUpdate.Status, lastErr, metav1.Now())
+	}
+	setProgressingCondition(&statusUpdate.Status, vpaManager.Generation, progress)
+	if err := r.patchStatus(ctx, vpaManager, &statusUpdate.Status); err != nil {
+		log.Error(err, "failed to record reconcile progress")
+		r.Metrics.RecordReconcile(vpaManager.Name, start, err)
+		return reconcile.Result{}, err
+	}
+
+	log.Info("reconcile budget exceeded, continuing with the next chunk", "chunk", progress.Chunks,
+		"continue", progress.Continue, "budget", r.ReconcileBudget, "maxNamespaces", r.MaxNamespacesPerCycle)
+	r.Metrics.RecordReconcileChunk(vpaManager.Name)
+	r.Metrics.RecordReconcile(vpaManager.Name, start, nil)
+	return reconcile.Result{RequeueAfter: shardRequeueDelay}, nil
+}
+
+// setProgressingCondition turns Ready False while the pass for a spec change is unfinished; a
+// pass over an observed generation keeps the Ready condition of the last one
+func setProgressingCondition(status *autoscalingv1.VpaManagerStatus, generation int64, progress *autoscalingv1.ReconcileProgress) {
+	if status.ObservedGeneration == generation {
+		return
+	}
+	meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+		Type:               autoscalingv1.ConditionReady,
+		Status:             metav1.ConditionFalse,
+		ObservedGeneration: generation,
+		Reason:             "Progressing",
+		Message:            fmt.Sprintf("A reconcile pass over the spec of generation %d is in progress, %d chunks are done", generation, progress.Chunks),
+	})
+}
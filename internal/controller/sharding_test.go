@@ -0,0 +1,280 @@
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	autoscalingv1 "github.com/joaomo/k8s_op_vpa/api/v1"
+)
+
+func TestResumeIndex(t *testing.T) {
+	namespace := func(name, priority string) corev1.Namespace {
+		ns := corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name}}
+		if priority != "" {
+			ns.Annotations = map[string]string{RolloutPriorityAnnotation: priority}
+		}
+		return ns
+	}
+	ordered := shardOrder([]corev1.Namespace{
+		namespace("web", ""),
+		namespace("api", ""),
+		namespace("payments", "10"),
+	})
+
+	tests := []struct {
+		name     string
+		token    string
+		expected int
+	}{
+		{name: "prioritized namespace", token: "10/payments", expected: 0},
+		{name: "exact match", token: "0/web", expected: 2},
+		{name: "deleted namespace resumes at the next one", token: "0/billing", expected: 2},
+		{name: "deleted prioritized namespace", token: "5/cache", expected: 1},
+		{name: "past the end", token: "0/zeta", expected: 3},
+		{name: "malformed token restarts", token: "web", expected: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, resumeIndex(ordered, tt.token))
+		})
+	}
+}
+
+// Test: with a reconcile budget, a pass continues across requeues from status.progress,
+// leaves VPAs of later chunks alone and reports the totals once complete
+func TestReconcile_SplitsPassAcrossRequeues(t *testing.T) {
+	scheme := setupScheme(t)
+	ctx := context.Background()
+
+	selected := map[string]string{"vpa-enabled": "true"}
+	vpaManager := &autoscalingv1.VpaManager{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-vpamanager"},
+		Spec: autoscalingv1.VpaManagerSpec{
+			Enabled:            true,
+			UpdateMode:         "Off",
+			NamespaceSelector:  &metav1.LabelSelector{MatchLabels: selected},
+			DeploymentSelector: &metav1.LabelSelector{MatchLabels: selected},
+		},
+	}
+	objects := []client.Object{vpaManager, createUnstructuredVPA("api-vpa", "ns-c", "api")}
+	for _, ns := range []string{"ns-a", "ns-b", "ns-c"} {
+		objects = append(objects,
+			&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: ns, Labels: selected}},
+			&appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: ns, Labels: selected},
+				Spec:       createDeploymentSpec(),
+			},
+		)
+	}
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(objects...).
+		WithStatusSubresource(vpaManager).
+		Build()
+	m := createTestMetrics()
+	reconciler := &VpaManagerReconciler{
+		Client:          fakeClient,
+		Scheme:          scheme,
+		Metrics:         m,
+		WorkloadConfigs: DefaultWorkloadConfigs(),
+		ReconcileBudget: time.Nanosecond,
+	}
+	request := reconcile.Request{NamespacedName: types.NamespacedName{Name: "test-vpamanager"}}
+
+	for chunk, expectedContinue := range []string{"0/ns-b", "0/ns-c"} {
+		result, err := reconciler.Reconcile(ctx, request)
+		require.NoError(t, err)
+		assert.Equal(t, shardRequeueDelay, result.RequeueAfter)
+
+		updated := &autoscalingv1.VpaManager{}
+		require.NoError(t, fakeClient.Get(ctx, request.NamespacedName, updated))
+		require.NotNil(t, updated.Status.Progress)
+		assert.Equal(t, expectedContinue, updated.Status.Progress.Continue)
+		assert.Equal(t, chunk+1, updated.Status.Progress.Chunks)
+		assert.Equal(t, chunk+1, updated.Status.Progress.DeploymentCount)
+		assert.Zero(t, updated.Status.ManagedVPAs, "totals are only reported for a complete pass")
+
+		vpaList := newVPAList()
+		require.NoError(t, fakeClient.List(ctx, vpaList, client.InNamespace("ns-c")))
+		assert.Len(t, vpaList.Items, 1, "VPAs of later chunks are not orphans")
+	}
+
+	result, err := reconciler.Reconcile(ctx, request)
+	require.NoError(t, err)
+	assert.Equal(t, reconciler.resyncPeriod(), result.RequeueAfter)
+
+	updated := &autoscalingv1.VpaManager{}
+	require.NoError(t, fakeClient.Get(ctx, request.NamespacedName, updated))
+	assert.Nil(t, updated.Status.Progress)
+	assert.Equal(t, 3, updated.Status.ManagedVPAs)
+	assert.Equal(t, 3, updated.Status.DeploymentCount)
+	assert.Equal(t, float64(2), testutil.ToFloat64(m.ReconcileChunksTotal.WithLabelValues("test-vpamanager")))
+
+	vpaList := newVPAList()
+	require.NoError(t, fakeClient.List(ctx, vpaList))
+	assert.Len(t, vpaList.Items, 3)
+}
+
+func TestReconcile_LimitsNamespacesPerCycle(t *testing.T) {
+	scheme := setupScheme(t)
+	ctx := context.Background()
+
+	selected := map[string]string{"vpa-enabled": "true"}
+	vpaManager := &autoscalingv1.VpaManager{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-vpamanager", Generation: 2},
+		Spec: autoscalingv1.VpaManagerSpec{
+			Enabled:            true,
+			UpdateMode:         "Off",
+			NamespaceSelector:  &metav1.LabelSelector{MatchLabels: selected},
+			DeploymentSelector: &metav1.LabelSelector{MatchLabels: selected},
+		},
+	}
+	objects := []client.Object{vpaManager}
+	for _, ns := range []string{"ns-a", "ns-b", "ns-c", "ns-d", "ns-e"} {
+		objects = append(objects,
+			&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: ns, Labels: selected}},
+			&appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: ns, Labels: selected},
+				Spec:       createDeploymentSpec(),
+			},
+		)
+	}
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(objects...).
+		WithStatusSubresource(vpaManager).
+		Build()
+	reconciler := &VpaManagerReconciler{
+		Client:                fakeClient,
+		Scheme:                scheme,
+		Metrics:               createTestMetrics(),
+		WorkloadConfigs:       DefaultWorkloadConfigs(),
+		MaxNamespacesPerCycle: 2,
+	}
+	request := reconcile.Request{NamespacedName: types.NamespacedName{Name: "test-vpamanager"}}
+
+	for chunk, expectedContinue := range []string{"0/ns-c", "0/ns-e"} {
+		result, err := reconciler.Reconcile(ctx, request)
+		require.NoError(t, err)
+		assert.Equal(t, shardRequeueDelay, result.RequeueAfter)
+
+		updated := &autoscalingv1.VpaManager{}
+		require.NoError(t, fakeClient.Get(ctx, request.NamespacedName, updated))
+		require.NotNil(t, updated.Status.Progress)
+		assert.Equal(t, expectedContinue, updated.Status.Progress.Continue)
+		assert.Equal(t, 2*(chunk+1), updated.Status.Progress.DeploymentCount)
+		// The spec is not observed until the pass completes
+		assert.Zero(t, updated.Status.ObservedGeneration)
+		ready := meta.FindStatusCondition(updated.Status.Conditions, autoscalingv1.ConditionReady)
+		require.NotNil(t, ready)
+		assert.Equal(t, metav1.ConditionFalse, ready.Status)
+		assert.Equal(t, "Progressing", ready.Reason)
+
+		vpaList := newVPAList()
+		require.NoError(t, fakeClient.List(ctx, vpaList))
+		assert.Len(t, vpaList.Items, 2*(chunk+1))
+	}
+
+	result, err := reconciler.Reconcile(ctx, request)
+	require.NoError(t, err)
+	assert.Equal(t, reconciler.resyncPeriod(), result.RequeueAfter)
+
+	updated := &autoscalingv1.VpaManager{}
+	require.NoError(t, fakeClient.Get(ctx, request.NamespacedName, updated))
+	assert.Nil(t, updated.Status.Progress)
+	assert.Equal(t, 5, updated.Status.ManagedVPAs)
+	assert.Equal(t, int64(2), updated.Status.ObservedGeneration)
+	assert.True(t, meta.IsStatusConditionTrue(updated.Status.Conditions, autoscalingv1.ConditionReady))
+}
+
+// Test: the orphan burst limit applies to a whole split pass, and a burst held back stays
+// held back in the next pass until it is confirmed
+func TestReconcile_SplitPassHoldsBackOrphanBurst(t *testing.T) {
+	scheme := setupScheme(t)
+	ctx := context.Background()
+
+	selected := map[string]string{"vpa-enabled": "true"}
+	vpaManager := &autoscalingv1.VpaManager{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-vpamanager"},
+		Spec: autoscalingv1.VpaManagerSpec{
+			Enabled:            true,
+			UpdateMode:         "Off",
+			NamespaceSelector:  &metav1.LabelSelector{MatchLabels: selected},
+			DeploymentSelector: &metav1.LabelSelector{MatchLabels: selected},
+		},
+	}
+	namespaces := []string{"ns-a", "ns-b", "ns-c", "ns-d"}
+	objects := []client.Object{vpaManager}
+	for _, ns := range namespaces {
+		// The workloads lost their label, leaving one orphaned VPA per namespace
+		objects = append(objects,
+			&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: ns, Labels: selected}},
+			createUnstructuredVPA("api-vpa", ns, "api"),
+		)
+	}
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(objects...).
+		WithStatusSubresource(vpaManager).
+		Build()
+	reconciler := &VpaManagerReconciler{
+		Client:                fakeClient,
+		Scheme:                scheme,
+		Metrics:               createTestMetrics(),
+		WorkloadConfigs:       DefaultWorkloadConfigs(),
+		MaxNamespacesPerCycle: 1,
+		OrphanBurstGuard:      &OrphanBurstGuard{MaxDeletions: 2},
+	}
+	request := reconcile.Request{NamespacedName: types.NamespacedName{Name: "test-vpamanager"}}
+	runPass := func() {
+		for range namespaces {
+			_, err := reconciler.Reconcile(ctx, request)
+			require.NoError(t, err)
+		}
+		updated := &autoscalingv1.VpaManager{}
+		require.NoError(t, fakeClient.Get(ctx, request.NamespacedName, updated))
+		require.Nil(t, updated.Status.Progress, "the pass is complete")
+	}
+	remaining := func() int {
+		vpaList := newVPAList()
+		require.NoError(t, fakeClient.List(ctx, vpaList))
+		return len(vpaList.Items)
+	}
+
+	// The first chunks stay within the limit, the rest of the pass exceeds it
+	runPass()
+	assert.Equal(t, 2, remaining())
+	updated := &autoscalingv1.VpaManager{}
+	require.NoError(t, fakeClient.Get(ctx, request.NamespacedName, updated))
+	assert.Equal(t, 2, updated.Status.PendingOrphanDeletions)
+	require.NotNil(t, updated.Status.OrphanDeletionsBlockedSince)
+
+	// The held back orphans are within the limit on their own, but still wait for confirmation
+	runPass()
+	assert.Equal(t, 2, remaining())
+	require.NoError(t, fakeClient.Get(ctx, request.NamespacedName, updated))
+	assert.Equal(t, 2, updated.Status.PendingOrphanDeletions)
+
+	updated.Annotations = map[string]string{ConfirmOrphanDeletionAnnotation: "true"}
+	require.NoError(t, fakeClient.Update(ctx, updated))
+	runPass()
+	assert.Zero(t, remaining())
+	require.NoError(t, fakeClient.Get(ctx, request.NamespacedName, updated))
+	assert.Zero(t, updated.Status.PendingOrphanDeletions)
+	assert.Nil(t, updated.Status.OrphanDeletionsBlockedSince)
+	assert.NotContains(t, updated.Annotations, ConfirmOrphanDeletionAnnotation)
+}
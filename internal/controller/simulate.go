@@ -0,0 +1,218 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"net/http"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	autoscalingv1 "github.com/joaomo/k8s_op_vpa/api/v1"
+	"github.com/joaomo/k8s_op_vpa/internal/policy"
+	"github.com/joaomo/k8s_op_vpa/internal/vpa"
+	"github.com/joaomo/k8s_op_vpa/internal/workload"
+)
+
+// SimulationPath is where the simulation endpoint is served on the metrics server
+const SimulationPath = "/simulate"
+
+// maxSimulationBodySize bounds the workload manifest accepted by the simulation endpoint
+const maxSimulationBodySize = 1 << 20
+
+// errInvalidManifest marks simulation requests the caller has to fix
+var errInvalidManifest = stderrors.New("invalid workload manifest")
+
+// SimulationResult describes the VPAs the operator would generate for a workload
+type SimulationResult struct {
+	// Matched is true when at least one VpaManager would manage the workload
+	Matched bool `json:"matched"`
+
+	// VPAs lists the VPA the matching VpaManager of the highest priority would generate
+	VPAs []SimulatedVPA `json:"vpas,omitempty"`
+
+	// Skipped explains why the remaining VpaManagers would not manage the workload
+	Skipped []SimulationSkip `json:"skipped,omitempty"`
+}
+
+// SimulatedVPA is a VPA a VpaManager would generate
+type SimulatedVPA struct {
+	VpaManager string                 `json:"vpaManager"`
+	VPA        map[string]interface{} `json:"vpa"`
+
+	// Trace records which policy layer set each setting of the VPA
+	Trace policy.Trace `json:"trace,omitempty"`
+}
+
+// SimulationSkip records why a VpaManager would not manage a workload
+type SimulationSkip struct {
+	VpaManager string `json:"vpaManager"`
+	Reason     string `json:"reason"`
+}
+
+// Simulate evaluates every VpaManager against a workload manifest without writing anything.
+// It applies the same inheritance, validation, defaulting, tenant and selector rules as
+// Reconcile. Namespaces that do not exist yet are treated as having no labels.
+func (r *VpaManagerReconciler) Simulate(ctx context.Context, obj *unstructured.Unstructured) (*SimulationResult, error) {
+	wc, ok := r.workloadConfigFor(obj.GetKind())
+	if !ok {
+		return nil, fmt.Errorf("%w: unsupported workload kind %q", errInvalidManifest, obj.GetKind())
+	}
+	if obj.GetName() == "" {
+		return nil, fmt.Errorf("%w: metadata.name is required", errInvalidManifest)
+	}
+	if obj.GetNamespace() == "" {
+		obj.SetNamespace(corev1.NamespaceDefault)
+	}
+
+	ns := &corev1.Namespace{}
+	if err := r.Get(ctx, types.NamespacedName{Name: obj.GetNamespace()}, ns); err != nil {
+		if !errors.IsNotFound(err) {
+			return nil, err
+		}
+		ns = &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: obj.GetNamespace()}}
+	}
+
+	vpaManagerList := &autoscalingv1.VpaManagerList{}
+	if err := r.List(ctx, vpaManagerList); err != nil {
+		return nil, err
+	}
+	autoscalingv1.SortByPriority(vpaManagerList.Items)
+
+	result := &SimulationResult{}
+	for i := range vpaManagerList.Items {
+		vm := &vpaManagerList.Items[i]
+		vpaObj, trace, reason := r.simulateVpaManager(ctx, vm, wc, ns, obj)
+		if reason == "" && len(result.VPAs) > 0 {
+			reason = fmt.Sprintf("VpaManager %s manages the workload, it has a higher priority", result.VPAs[0].VpaManager)
+		}
+		if reason != "" {
+			result.Skipped = append(result.Skipped, SimulationSkip{VpaManager: vm.Name, Reason: reason})
+			continue
+		}
+		result.VPAs = append(result.VPAs, SimulatedVPA{VpaManager: vm.Name, VPA: vpaObj.Object, Trace: trace})
+	}
+	result.Matched = len(result.VPAs) > 0
+	return result, nil
+}
+
+// simulateVpaManager returns the VPA vm would generate for obj and where its settings came
+// from, or why it would not generate one
+func (r *VpaManagerReconciler) simulateVpaManager(ctx context.Context, vm *autoscalingv1.VpaManager, wc WorkloadConfig, ns *corev1.Namespace, obj *unstructured.Unstructured) (*unstructured.Unstructured, policy.Trace, string) {
+	spec, reason := r.matchWorkload(ctx, vm, wc, ns, obj)
+	if reason != "" {
+		return nil, nil, reason
+	}
+	if r.Self.MatchesObject(obj.GetKind(), obj.GetNamespace(), obj.GetName()) {
+		return nil, nil, "the operator never manages its own workload"
+	}
+	if r.Bootstrapped.MatchesObject(obj.GetKind(), obj.GetNamespace(), obj.GetName()) {
+		return nil, nil, "the VPA component has an Off-mode bootstrap VPA"
+	}
+	if workload.Excluded(obj) {
+		return nil, nil, fmt.Sprintf("the workload opts out with the %s=true annotation", workload.ExcludeAnnotation)
+	}
+	if workload.NamespacePaused(ns) {
+		return nil, nil, fmt.Sprintf("namespace %s is paused with the %s=true annotation", ns.Name, workload.PausedAnnotation)
+	}
+
+	effective := vm.DeepCopy()
+	effective.Spec = *spec
+	podTemplate, err := simulatedPodTemplate(obj)
+	if err != nil {
+		return nil, nil, fmt.Sprintf("invalid pod template: %v", err)
+	}
+	resolved, err := policy.Resolve(ctx, r.Client, effective, obj.GetKind(), obj, podTemplate)
+	if err != nil {
+		return nil, nil, fmt.Sprintf("policy cannot be resolved: %v", err)
+	}
+	vpaName, err := vpa.RenderName(spec.VPANameTemplate, vpa.NameTemplateData{Kind: obj.GetKind(), Name: obj.GetName(), Namespace: obj.GetNamespace()})
+	if err != nil {
+		return nil, nil, fmt.Sprintf("the VPA cannot be named: %v", err)
+	}
+	vpaObj := r.buildVPAForWorkload(resolved.VpaManager, obj.GetKind(), obj.GetName(), obj.GetNamespace(), obj.GetUID(), vpaName)
+	vpa.ApplyTraceAnnotations(vpaObj, vpa.TraceAnnotations(obj, spec.PropagateAnnotations))
+	vpa.ApplyTuningAnnotations(vpaObj, spec.RecommendationTuning)
+	vpa.ApplyCustomMetadata(vpaObj, spec.VPALabels, spec.VPAAnnotations, r.Ownership)
+	return vpaObj, resolved.Trace, ""
+}
+
+// simulatedPodTemplate returns the pod template of a workload manifest, nil when it has none
+func simulatedPodTemplate(obj *unstructured.Unstructured) (*corev1.PodTemplateSpec, error) {
+	raw, found, err := unstructured.NestedMap(obj.Object, "spec", "template")
+	if err != nil || !found {
+		return nil, err
+	}
+	podTemplate := &corev1.PodTemplateSpec{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(raw, podTemplate); err != nil {
+		return nil, err
+	}
+	return podTemplate, nil
+}
+
+// workloadConfigFor returns the configuration of a managed workload kind
+func (r *VpaManagerReconciler) workloadConfigFor(kind string) (WorkloadConfig, bool) {
+	return workloadConfigIn(r.WorkloadConfigs, kind)
+}
+
+// workloadConfigIn returns the configuration of a workload kind, looking it up in the
+// default configurations when configs is empty
+func workloadConfigIn(configs []WorkloadConfig, kind string) (WorkloadConfig, bool) {
+	if len(configs) == 0 {
+		configs = DefaultWorkloadConfigs()
+	}
+	for _, wc := range configs {
+		if wc.Provider.Kind() == kind {
+			return wc, true
+		}
+	}
+	return WorkloadConfig{}, false
+}
+
+// SimulationHandler serves Simulate over HTTP. CI pipelines POST a workload manifest as
+// YAML or JSON and get back a SimulationResult, so label and policy mistakes are caught
+// before deploying.
+type SimulationHandler struct {
+	Reconciler *VpaManagerReconciler
+}
+
+// ServeHTTP implements http.Handler
+func (h *SimulationHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "only POST is supported", http.StatusMethodNotAllowed)
+		return
+	}
+
+	obj := &unstructured.Unstructured{}
+	decoder := utilyaml.NewYAMLOrJSONDecoder(http.MaxBytesReader(w, req.Body, maxSimulationBodySize), 4096)
+	if err := decoder.Decode(&obj.Object); err != nil {
+		h.Reconciler.Metrics.RecordSimulation(false, err)
+		http.Error(w, fmt.Sprintf("%v: %v", errInvalidManifest, err), http.StatusBadRequest)
+		return
+	}
+
+	result, err := h.Reconciler.Simulate(req.Context(), obj)
+	h.Reconciler.Metrics.RecordSimulation(result != nil && result.Matched, err)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if stderrors.Is(err, errInvalidManifest) {
+			status = http.StatusBadRequest
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(result); err != nil {
+		ctrl.LoggerFrom(req.Context()).Error(err, "failed to write simulation result")
+	}
+}
@@ -0,0 +1,237 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	autoscalingv1 "github.com/joaomo/k8s_op_vpa/api/v1"
+	"github.com/joaomo/k8s_op_vpa/internal/policy"
+	"github.com/joaomo/k8s_op_vpa/internal/workload"
+)
+
+func newSimulationReconciler(t *testing.T) *VpaManagerReconciler {
+	scheme := setupScheme(t)
+	objects := []runtime.Object{
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-a", Labels: map[string]string{"vpa-enabled": "true"}}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-b"}},
+		&autoscalingv1.VpaManager{
+			ObjectMeta: metav1.ObjectMeta{Name: "apps"},
+			Spec: autoscalingv1.VpaManagerSpec{
+				Enabled:            true,
+				UpdateMode:         "Auto",
+				NamespaceSelector:  &metav1.LabelSelector{MatchLabels: map[string]string{"vpa-enabled": "true"}},
+				DeploymentSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"vpa": "on"}},
+				Recommenders:       []autoscalingv1.RecommenderSelector{{Name: "fast"}},
+			},
+		},
+		&autoscalingv1.VpaManager{
+			ObjectMeta: metav1.ObjectMeta{Name: "disabled"},
+			Spec:       autoscalingv1.VpaManagerSpec{Enabled: false, MatchAllNamespaces: true, MatchAllWorkloads: true},
+		},
+	}
+	return &VpaManagerReconciler{
+		Client:          fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(objects...).Build(),
+		Scheme:          scheme,
+		Metrics:         createTestMetrics(),
+		WorkloadConfigs: DefaultWorkloadConfigs(),
+		Self:            &workload.Self{Namespace: "team-a", Kind: "Deployment", Name: "vpa-operator"},
+	}
+}
+
+func newSimulatedWorkload(kind, namespace, name string, labels map[string]string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion("apps/v1")
+	obj.SetKind(kind)
+	obj.SetNamespace(namespace)
+	obj.SetName(name)
+	obj.SetLabels(labels)
+	return obj
+}
+
+func TestSimulate(t *testing.T) {
+	tests := []struct {
+		name        string
+		workload    *unstructured.Unstructured
+		wantMatched bool
+		wantReason  string
+		wantErr     bool
+	}{
+		{
+			name:        "matching deployment",
+			workload:    newSimulatedWorkload("Deployment", "team-a", "web", map[string]string{"vpa": "on"}),
+			wantMatched: true,
+		},
+		{
+			name:       "deployment labels do not match",
+			workload:   newSimulatedWorkload("Deployment", "team-a", "web", map[string]string{"vpa": "off"}),
+			wantReason: "workload labels do not match the Deployment selector",
+		},
+		{
+			name:       "namespace not selected",
+			workload:   newSimulatedWorkload("Deployment", "team-b", "web", map[string]string{"vpa": "on"}),
+			wantReason: "namespace team-b is not selected",
+		},
+		{
+			name:       "namespace that does not exist yet",
+			workload:   newSimulatedWorkload("Deployment", "team-c", "web", map[string]string{"vpa": "on"}),
+			wantReason: "namespace team-c is not selected",
+		},
+		{
+			name:       "kind not selected",
+			workload:   newSimulatedWorkload("StatefulSet", "team-a", "db", map[string]string{"vpa": "on"}),
+			wantReason: "StatefulSets are not selected",
+		},
+		{
+			name:       "operator workload",
+			workload:   newSimulatedWorkload("Deployment", "team-a", "vpa-operator", map[string]string{"vpa": "on"}),
+			wantReason: "the operator never manages its own workload",
+		},
+		{
+			name:     "unsupported kind",
+			workload: newSimulatedWorkload("CronJob", "team-a", "nightly", nil),
+			wantErr:  true,
+		},
+		{
+			name:     "missing name",
+			workload: newSimulatedWorkload("Deployment", "team-a", "", nil),
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newSimulationReconciler(t)
+			result, err := r.Simulate(context.Background(), tt.workload)
+			if tt.wantErr {
+				require.ErrorIs(t, err, errInvalidManifest)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantMatched, result.Matched)
+
+			skipped := map[string]string{}
+			for _, skip := range result.Skipped {
+				skipped[skip.VpaManager] = skip.Reason
+			}
+			assert.Equal(t, "VpaManager is disabled", skipped["disabled"])
+			if tt.wantMatched {
+				require.Len(t, result.VPAs, 1)
+				assert.Equal(t, "apps", result.VPAs[0].VpaManager)
+			} else {
+				assert.Empty(t, result.VPAs)
+				assert.Equal(t, tt.wantReason, skipped["apps"])
+			}
+		})
+	}
+}
+
+func TestSimulate_GeneratesSameVPAAsReconcile(t *testing.T) {
+	r := newSimulationReconciler(t)
+	wl := newSimulatedWorkload("Deployment", "team-a", "web", map[string]string{"vpa": "on"})
+
+	result, err := r.Simulate(context.Background(), wl)
+	require.NoError(t, err)
+	require.Len(t, result.VPAs, 1)
+
+	vpaObj := &unstructured.Unstructured{Object: result.VPAs[0].VPA}
+	assert.Equal(t, "web-vpa", vpaObj.GetName())
+	assert.Equal(t, "team-a", vpaObj.GetNamespace())
+	assert.Equal(t, "apps", vpaObj.GetLabels()["app.kubernetes.io/created-by"])
+	mode, _, _ := unstructured.NestedString(vpaObj.Object, "spec", "updatePolicy", "updateMode")
+	assert.Equal(t, "Auto", mode)
+	recommenders, _, _ := unstructured.NestedSlice(vpaObj.Object, "spec", "recommenders")
+	assert.Equal(t, []interface{}{map[string]interface{}{"name": "fast"}}, recommenders)
+}
+
+func TestSimulate_ExplainsPolicyLayers(t *testing.T) {
+	r := newSimulationReconciler(t)
+	wl := newSimulatedWorkload("Deployment", "team-a", "web", map[string]string{"vpa": "on"})
+	wl.SetAnnotations(map[string]string{policy.UpdateModeAnnotation: "Initial"})
+
+	result, err := r.Simulate(context.Background(), wl)
+	require.NoError(t, err)
+	require.Len(t, result.VPAs, 1)
+
+	vpaObj := &unstructured.Unstructured{Object: result.VPAs[0].VPA}
+	mode, _, _ := unstructured.NestedString(vpaObj.Object, "spec", "updatePolicy", "updateMode")
+	assert.Equal(t, "Initial", mode)
+	winner := result.VPAs[0].Trace.Winner(policy.SettingUpdateMode)
+	require.NotNil(t, winner)
+	assert.Equal(t, policy.LayerWorkload, winner.Layer)
+}
+
+func TestSimulationHandler(t *testing.T) {
+	tests := []struct {
+		name        string
+		method      string
+		body        string
+		wantStatus  int
+		wantMatched bool
+	}{
+		{
+			name:   "yaml manifest",
+			method: http.MethodPost,
+			body: `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: team-a
+  labels:
+    vpa: "on"
+`,
+			wantStatus:  http.StatusOK,
+			wantMatched: true,
+		},
+		{
+			name:       "json manifest without match",
+			method:     http.MethodPost,
+			body:       `{"apiVersion":"apps/v1","kind":"Deployment","metadata":{"name":"web","namespace":"team-b"}}`,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "unsupported kind",
+			method:     http.MethodPost,
+			body:       `{"apiVersion":"v1","kind":"Pod","metadata":{"name":"web"}}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "malformed body",
+			method:     http.MethodPost,
+			body:       `{"kind":`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "GET is rejected",
+			method:     http.MethodGet,
+			wantStatus: http.StatusMethodNotAllowed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := &SimulationHandler{Reconciler: newSimulationReconciler(t)}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(tt.method, SimulationPath, strings.NewReader(tt.body)))
+
+			require.Equal(t, tt.wantStatus, rec.Code, rec.Body.String())
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			result := &SimulationResult{}
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), result))
+			assert.Equal(t, tt.wantMatched, result.Matched)
+		})
+	}
+}
@@ -0,0 +1,408 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	autoscalingv1 "github.com/joaomo/k8s_op_vpa/api/v1"
+	"github.com/joaomo/k8s_op_vpa/internal/vpa"
+	"github.com/joaomo/k8s_op_vpa/internal/workload"
+)
+
+// DailySummaryReason is the reason of the Normal event that summarizes a VpaManager
+const DailySummaryReason = "DailySummary"
+
+// summaryTopDeviations bounds the deviations reported per summary
+const summaryTopDeviations = 5
+
+// maxSummaryMessageLength keeps summary events below the 1024 byte event message limit
+const maxSummaryMessageLength = 1024
+
+// maxSummaryLoggedVPAs bounds the added and removed VPAs named in the summary log line
+const maxSummaryLoggedVPAs = 20
+
+// Deviation is a container whose request differs from its VPA target
+type Deviation struct {
+	Namespace string
+	Workload  string
+	Container string
+	Resource  corev1.ResourceName
+	Request   resource.Quantity
+	Target    resource.Quantity
+	// Ratio is the larger of request and target divided by the smaller, at least 1
+	Ratio float64
+}
+
+// String describes the deviation, e.g. "shop/web[app] cpu request 1, target 100m (10.0x)"
+func (d Deviation) String() string {
+	return fmt.Sprintf("%s/%s[%s] %s request %s, target %s (%.1fx)",
+		d.Namespace, d.Workload, d.Container, d.Resource, d.Request.String(), d.Target.String(), d.Ratio)
+}
+
+// deviationTracker keeps the largest deviations between requests and VPA targets seen in a reconcile
+type deviationTracker struct {
+	limit int
+	top   []Deviation
+}
+
+func newDeviationTracker(limit int) *deviationTracker {
+	return &deviationTracker{limit: limit}
+}
+
+// add compares the requests of a workload with the target recommendation of its VPA.
+// A nil tracker ignores the workload.
+func (t *deviationTracker) add(wl workload.Workload, vpaObj *unstructured.Unstructured) {
+	if t == nil || vpaObj == nil {
+		return
+	}
+	template := wl.GetPodTemplateSpec()
+	if template == nil {
+		return
+	}
+	targets := vpa.Recommendations(vpaObj, vpa.RecommendationTarget)
+	for _, c := range template.Spec.Containers {
+		target, ok := targets[c.Name]
+		if !ok {
+			continue
+		}
+		for _, res := range rightsizingResources {
+			request, hasRequest := c.Resources.Requests[res]
+			recommended, hasTarget := target[res]
+			if !hasRequest || !hasTarget {
+				continue
+			}
+			req := request.AsApproximateFloat64()
+			rec := recommended.AsApproximateFloat64()
+			if req <= 0 || rec <= 0 {
+				continue
+			}
+			t.insert(Deviation{
+				Namespace: wl.GetNamespace(),
+				Workload:  wl.GetName(),
+				Container: c.Name,
+				Resource:  res,
+				Request:   request,
+				Target:    recommended,
+				Ratio:     max(req, rec) / min(req, rec),
+			})
+		}
+	}
+}
+
+// insert adds d when it is among the largest deviations
+func (t *deviationTracker) insert(d Deviation) {
+	if d.Ratio <= 1 {
+		return
+	}
+	i := sort.Search(len(t.top), func(i int) bool { return t.top[i].Ratio < d.Ratio })
+	if i >= t.limit {
+		return
+	}
+	t.top = append(t.top, Deviation{})
+	copy(t.top[i+1:], t.top[i:])
+	t.top[i] = d
+	if len(t.top) > t.limit {
+		t.top = t.top[:t.limit]
+	}
+}
+
+// list returns the tracked deviations, largest first
+func (t *deviationTracker) list() []Deviation {
+	if t == nil {
+		return nil
+	}
+	return t.top
+}
+
+// Summary is what happened to the VPAs of one VpaManager during a summary period
+type Summary struct {
+	VpaManager string
+	// Since is when the period started
+	Since time.Time
+	// Added and Removed are the "namespace/name" keys of VPAs that started or stopped being managed
+	Added   []string
+	Removed []string
+	// SelectedWorkloads and ManagedVPAs are taken from the last reconcile of the period
+	SelectedWorkloads int
+	ManagedVPAs       int
+	// Deviations are the largest deviations found by the last reconcile, largest first
+	Deviations []Deviation
+	// Errors is the number of reconciles of the period that ended with an error
+	Errors    int
+	LastError string
+}
+
+// Coverage returns the percentage of selected workloads that have a managed VPA, and false
+// when no workload is selected
+func (s Summary) Coverage() (int, bool) {
+	if s.SelectedWorkloads == 0 {
+		return 0, false
+	}
+	return int(float64(s.ManagedVPAs)*100/float64(s.SelectedWorkloads) + 0.5), true
+}
+
+// Message renders the summary as a single line, as emitted in the summary event
+func (s Summary) Message() string {
+	parts := []string{fmt.Sprintf("%d VPAs added, %d removed", len(s.Added), len(s.Removed))}
+	if coverage, ok := s.Coverage(); ok {
+		parts = append(parts, fmt.Sprintf("coverage %d%% (%d/%d workloads)", coverage, s.ManagedVPAs, s.SelectedWorkloads))
+	} else {
+		parts = append(parts, "no workloads selected")
+	}
+	if s.Errors > 0 {
+		parts = append(parts, fmt.Sprintf("%d reconcile errors, last: %s", s.Errors, s.LastError))
+	} else {
+		parts = append(parts, "no errors")
+	}
+	if len(s.Deviations) > 0 {
+		deviations := make([]string, 0, len(s.Deviations))
+		for _, d := range s.Deviations {
+			deviations = append(deviations, d.String())
+		}
+		parts = append(parts, "top deviations: "+strings.Join(deviations, "; "))
+	}
+	return fmt.Sprintf("Since %s: %s", s.Since.UTC().Format(time.RFC3339), strings.Join(parts, "; "))
+}
+
+// SummaryLedger collects, per VpaManager, what the next summary reports. Reconciles feed
+// it and DailySummary drains it. A nil ledger ignores everything.
+type SummaryLedger struct {
+	mu      sync.Mutex
+	periods map[string]*summaryPeriod
+	now     func() time.Time
+}
+
+// summaryPeriod is the state of one VpaManager since its last summary
+type summaryPeriod struct {
+	since time.Time
+	// baseline holds the VPAs managed when the period started, nil until the first reconcile
+	baseline   map[string]bool
+	current    map[string]bool
+	selected   int
+	deviations []Deviation
+	errors     int
+	lastError  string
+}
+
+// NewSummaryLedger returns an empty ledger
+func NewSummaryLedger() *SummaryLedger {
+	return &SummaryLedger{periods: map[string]*summaryPeriod{}, now: time.Now}
+}
+
+// period returns the period of a VpaManager, starting one if needed. Callers hold the lock.
+func (l *SummaryLedger) period(name string) *summaryPeriod {
+	p, ok := l.periods[name]
+	if !ok {
+		p = &summaryPeriod{since: l.now()}
+		l.periods[name] = p
+	}
+	return p
+}
+
+// observe records the outcome of a completed reconcile. The VPAs managed at the first
+// reconcile seen by this process are the baseline, so a restart reports nothing as added.
+func (l *SummaryLedger) observe(name string, managed map[string]bool, selected int, deviations []Deviation, err error) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	p := l.period(name)
+	current := make(map[string]bool, len(managed))
+	for key := range managed {
+		current[key] = true
+	}
+	if p.baseline == nil {
+		p.baseline = current
+	}
+	p.current = current
+	p.selected = selected
+	p.deviations = append([]Deviation(nil), deviations...)
+	if err != nil {
+		p.errors++
+		p.lastError = err.Error()
+	}
+}
+
+// recordError counts a reconcile that stopped before it could observe any VPA
+func (l *SummaryLedger) recordError(name string, err error) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	p := l.period(name)
+	p.errors++
+	p.lastError = err.Error()
+}
+
+// Summarize returns the summary of a VpaManager and starts its next period. It returns
+// false when nothing was recorded for the VpaManager since its last summary.
+func (l *SummaryLedger) Summarize(name string) (Summary, bool) {
+	if l == nil {
+		return Summary{}, false
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	p, ok := l.periods[name]
+	if !ok || (p.current == nil && p.errors == 0) {
+		return Summary{}, false
+	}
+	summary := Summary{
+		VpaManager:        name,
+		Since:             p.since,
+		Added:             keysMissingFrom(p.current, p.baseline),
+		Removed:           keysMissingFrom(p.baseline, p.current),
+		SelectedWorkloads: p.selected,
+		ManagedVPAs:       len(p.current),
+		Deviations:        p.deviations,
+		Errors:            p.errors,
+		LastError:         p.lastError,
+	}
+
+	// The next period starts from the VPAs managed now; a VpaManager that never
+	// completed a reconcile keeps waiting for its baseline
+	if p.current == nil {
+		delete(l.periods, name)
+	} else {
+		l.periods[name] = &summaryPeriod{since: l.now(), baseline: p.current, current: p.current, selected: p.selected}
+	}
+	return summary, true
+}
+
+// retain forgets VpaManagers that no longer exist
+func (l *SummaryLedger) retain(names map[string]bool) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for name := range l.periods {
+		if !names[name] {
+			delete(l.periods, name)
+		}
+	}
+}
+
+// keysMissingFrom returns the sorted keys of a that are not in b
+func keysMissingFrom(a, b map[string]bool) []string {
+	var keys []string
+	for key := range a {
+		if !b[key] {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// DailySummary periodically logs and emits an event per VpaManager summarizing the VPAs
+// added and removed, the coverage of selected workloads, reconcile errors and the largest
+// deviations between requests and recommendations, for teams that review logs rather
+// than dashboards. The ledger is fed by the reconciler, which also only runs on the leader.
+type DailySummary struct {
+	Client   client.Client
+	Ledger   *SummaryLedger
+	Recorder record.EventRecorder
+	Interval time.Duration
+
+	Log logr.Logger
+}
+
+// Start runs the summary loop until the context is cancelled
+func (d *DailySummary) Start(ctx context.Context) error {
+	if d.Log.GetSink() == nil {
+		d.Log = ctrl.Log.WithName("daily-summary")
+	}
+
+	ticker := time.NewTicker(d.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			d.runOnce(ctx)
+		}
+	}
+}
+
+// NeedLeaderElection ensures only the leader, whose reconciles fill the ledger, reports
+func (d *DailySummary) NeedLeaderElection() bool {
+	return true
+}
+
+// runOnce reports every VpaManager with data in the ledger
+func (d *DailySummary) runOnce(ctx context.Context) {
+	vpaManagers := &autoscalingv1.VpaManagerList{}
+	if err := d.Client.List(ctx, vpaManagers); err != nil {
+		d.Log.Error(err, "failed to list VpaManagers for the daily summary")
+		return
+	}
+
+	names := make(map[string]bool, len(vpaManagers.Items))
+	for i := range vpaManagers.Items {
+		vpaManager := &vpaManagers.Items[i]
+		names[vpaManager.Name] = true
+		summary, ok := d.Ledger.Summarize(vpaManager.Name)
+		if !ok {
+			continue
+		}
+
+		keysAndValues := []interface{}{
+			"vpaManager", vpaManager.Name,
+			"since", summary.Since,
+			"added", len(summary.Added),
+			"removed", len(summary.Removed),
+			"addedVPAs", firstN(summary.Added, maxSummaryLoggedVPAs),
+			"removedVPAs", firstN(summary.Removed, maxSummaryLoggedVPAs),
+			"selectedWorkloads", summary.SelectedWorkloads,
+			"managedVPAs", summary.ManagedVPAs,
+			"errors", summary.Errors,
+		}
+		if coverage, ok := summary.Coverage(); ok {
+			keysAndValues = append(keysAndValues, "coveragePercent", coverage)
+		}
+		if summary.LastError != "" {
+			keysAndValues = append(keysAndValues, "lastError", summary.LastError)
+		}
+		if len(summary.Deviations) > 0 {
+			deviations := make([]string, 0, len(summary.Deviations))
+			for _, dev := range summary.Deviations {
+				deviations = append(deviations, dev.String())
+			}
+			keysAndValues = append(keysAndValues, "topDeviations", deviations)
+		}
+		d.Log.Info("daily summary", keysAndValues...)
+
+		if d.Recorder != nil {
+			d.Recorder.Event(vpaManager, corev1.EventTypeNormal, DailySummaryReason, truncate(summary.Message(), maxSummaryMessageLength))
+		}
+	}
+	d.Ledger.retain(names)
+}
+
+// firstN returns at most n leading elements of list
+func firstN(list []string, n int) []string {
+	if len(list) <= n {
+		return list
+	}
+	return list[:n]
+}
@@ -0,0 +1,278 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	autoscalingv1 "github.com/joaomo/k8s_op_vpa/api/v1"
+)
+
+func TestSummaryLedger_Summarize(t *testing.T) {
+	keys := func(names ...string) map[string]bool {
+		set := map[string]bool{}
+		for _, name := range names {
+			set[name] = true
+		}
+		return set
+	}
+	type observation struct {
+		managed  map[string]bool
+		selected int
+		err      error
+		// early records a reconcile that stopped before observing any VPA
+		early bool
+	}
+
+	tests := []struct {
+		name         string
+		before       []observation
+		observations []observation
+		expectedOK   bool
+		expected     Summary
+	}{
+		{
+			name: "nothing recorded",
+		},
+		{
+			name:         "first reconcile is the baseline",
+			observations: []observation{{managed: keys("ns/a-vpa", "ns/b-vpa"), selected: 2}},
+			expectedOK:   true,
+			expected:     Summary{SelectedWorkloads: 2, ManagedVPAs: 2},
+		},
+		{
+			name:   "added and removed since the last summary",
+			before: []observation{{managed: keys("ns/a-vpa", "ns/b-vpa"), selected: 2}},
+			observations: []observation{
+				{managed: keys("ns/a-vpa", "ns/c-vpa"), selected: 3},
+				{managed: keys("ns/a-vpa", "ns/c-vpa", "ns/d-vpa"), selected: 4},
+			},
+			expectedOK: true,
+			expected: Summary{
+				Added:             []string{"ns/c-vpa", "ns/d-vpa"},
+				Removed:           []string{"ns/b-vpa"},
+				SelectedWorkloads: 4,
+				ManagedVPAs:       3,
+			},
+		},
+		{
+			name:   "VPA added and removed within the period",
+			before: []observation{{managed: keys("ns/a-vpa"), selected: 1}},
+			observations: []observation{
+				{managed: keys("ns/a-vpa", "ns/b-vpa"), selected: 2},
+				{managed: keys("ns/a-vpa"), selected: 1},
+			},
+			expectedOK: true,
+			expected:   Summary{SelectedWorkloads: 1, ManagedVPAs: 1},
+		},
+		{
+			name:   "counts errors",
+			before: []observation{{managed: keys("ns/a-vpa"), selected: 1, err: errors.New("stale")}},
+			observations: []observation{
+				{managed: keys("ns/a-vpa"), selected: 2, err: errors.New("deployment ns/b: denied")},
+				{early: true, err: errors.New("listing namespaces: timeout")},
+			},
+			expectedOK: true,
+			expected: Summary{
+				SelectedWorkloads: 2,
+				ManagedVPAs:       1,
+				Errors:            2,
+				LastError:         "listing namespaces: timeout",
+			},
+		},
+		{
+			name:         "errors before the first completed reconcile",
+			observations: []observation{{early: true, err: errors.New("listing namespaces: timeout")}},
+			expectedOK:   true,
+			expected:     Summary{Errors: 1, LastError: "listing namespaces: timeout"},
+		},
+		{
+			name:       "nothing recorded since the last summary",
+			before:     []observation{{early: true, err: errors.New("listing namespaces: timeout")}},
+			expectedOK: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+			ledger := NewSummaryLedger()
+			ledger.now = func() time.Time { return now }
+			record := func(o observation) {
+				if o.early {
+					ledger.recordError("test-vpamanager", o.err)
+				} else {
+					ledger.observe("test-vpamanager", o.managed, o.selected, nil, o.err)
+				}
+			}
+
+			for _, o := range tt.before {
+				record(o)
+			}
+			if len(tt.before) > 0 {
+				_, ok := ledger.Summarize("test-vpamanager")
+				require.True(t, ok)
+			}
+			now = now.Add(24 * time.Hour)
+			for _, o := range tt.observations {
+				record(o)
+			}
+
+			summary, ok := ledger.Summarize("test-vpamanager")
+			assert.Equal(t, tt.expectedOK, ok)
+			if !tt.expectedOK {
+				return
+			}
+			tt.expected.VpaManager = "test-vpamanager"
+			tt.expected.Since = summary.Since
+			assert.Equal(t, tt.expected, summary)
+		})
+	}
+}
+
+func TestDeviationTracker(t *testing.T) {
+	tracker := newDeviationTracker(2)
+	for _, ratio := range []float64{1, 3, 1.5, 10, 2} {
+		tracker.insert(Deviation{Workload: "app", Ratio: ratio})
+	}
+
+	var ratios []float64
+	for _, d := range tracker.list() {
+		ratios = append(ratios, d.Ratio)
+	}
+	assert.Equal(t, []float64{10, 3}, ratios)
+	assert.Nil(t, (*deviationTracker)(nil).list())
+}
+
+func TestSummary_Message(t *testing.T) {
+	since := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	deviation := Deviation{
+		Namespace: "shop",
+		Workload:  "web",
+		Container: "app",
+		Resource:  corev1.ResourceCPU,
+		Request:   resource.MustParse("1"),
+		Target:    resource.MustParse("100m"),
+		Ratio:     10,
+	}
+
+	tests := []struct {
+		name     string
+		summary  Summary
+		expected string
+	}{
+		{
+			name:     "no workloads",
+			summary:  Summary{Since: since},
+			expected: "Since 2026-03-01T12:00:00Z: 0 VPAs added, 0 removed; no workloads selected; no errors",
+		},
+		{
+			name: "everything",
+			summary: Summary{
+				Since:             since,
+				Added:             []string{"shop/web-vpa", "shop/api-vpa"},
+				Removed:           []string{"shop/old-vpa"},
+				SelectedWorkloads: 3,
+				ManagedVPAs:       2,
+				Deviations:        []Deviation{deviation},
+				Errors:            1,
+				LastError:         "deployment shop/db: denied",
+			},
+			expected: "Since 2026-03-01T12:00:00Z: 2 VPAs added, 1 removed; coverage 67% (2/3 workloads); " +
+				"1 reconcile errors, last: deployment shop/db: denied; " +
+				"top deviations: shop/web[app] cpu request 1, target 100m (10.0x)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.summary.Message())
+		})
+	}
+}
+
+// Test: Reconciles feed the ledger and the summary is emitted as an event on the VpaManager
+func TestDailySummary_ReportsReconciledVpaManager(t *testing.T) {
+	scheme := setupScheme(t)
+	ctx := context.Background()
+
+	namespace := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-ns", Labels: map[string]string{"vpa-enabled": "true"}},
+	}
+	spec := createDeploymentSpec()
+	spec.Template.Spec.Containers[0].Resources.Requests = resources("1", "512Mi")
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-deployment",
+			Namespace: "test-ns",
+			Labels:    map[string]string{"vpa-enabled": "true"},
+			UID:       "uid-1",
+		},
+		Spec: spec,
+	}
+	vpaManager := &autoscalingv1.VpaManager{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-vpamanager"},
+		Spec: autoscalingv1.VpaManagerSpec{
+			Enabled:            true,
+			UpdateMode:         "Off",
+			NamespaceSelector:  &metav1.LabelSelector{MatchLabels: map[string]string{"vpa-enabled": "true"}},
+			DeploymentSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"vpa-enabled": "true"}},
+		},
+	}
+	existingVPA := vpaWithTargets(map[string]map[string]interface{}{
+		"main": {"cpu": "250m", "memory": "256Mi"},
+	})
+	existingVPA.SetGroupVersionKind(vpaGVK)
+	existingVPA.SetName("test-deployment-vpa")
+	existingVPA.SetNamespace("test-ns")
+	existingVPA.SetLabels(map[string]string{
+		"app.kubernetes.io/managed-by": "vpa-operator",
+		"app.kubernetes.io/created-by": "test-vpamanager",
+	})
+
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(namespace, deployment, vpaManager, existingVPA).
+		WithStatusSubresource(vpaManager).
+		Build()
+
+	ledger := NewSummaryLedger()
+	ledger.observe("deleted-vpamanager", nil, 0, nil, nil)
+	reconciler := &VpaManagerReconciler{
+		Client:          fakeClient,
+		Scheme:          scheme,
+		Metrics:         createTestMetrics(),
+		WorkloadConfigs: DefaultWorkloadConfigs(),
+		Summary:         ledger,
+	}
+	_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: "test-vpamanager"}})
+	require.NoError(t, err)
+
+	recorder := record.NewFakeRecorder(10)
+	(&DailySummary{Client: fakeClient, Ledger: ledger, Recorder: recorder}).runOnce(ctx)
+
+	require.Len(t, recorder.Events, 1)
+	event := <-recorder.Events
+	assert.Contains(t, event, "Normal "+DailySummaryReason)
+	assert.Contains(t, event, "coverage 100% (1/1 workloads)")
+	assert.Contains(t, event, "top deviations: test-ns/test-deployment[main] cpu request 1, target 250m (4.0x); "+
+		"test-ns/test-deployment[main] memory request 512Mi, target 256Mi (2.0x)")
+
+	// The period was drained, and the deleted VpaManager forgotten
+	_, ok := ledger.Summarize("test-vpamanager")
+	assert.True(t, ok, "the next period keeps the last reconcile as its baseline")
+	_, ok = ledger.Summarize("deleted-vpamanager")
+	assert.False(t, ok)
+}
@@ -0,0 +1,433 @@
+package controller
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/go-logr/logr"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	autoscalingv1 "github.com/joaomo/k8s_op_vpa/api/v1"
+	"github.com/joaomo/k8s_op_vpa/internal/decisions"
+	"github.com/joaomo/k8s_op_vpa/internal/metrics"
+	"github.com/joaomo/k8s_op_vpa/internal/vpa"
+	"github.com/joaomo/k8s_op_vpa/internal/workload"
+)
+
+// Reasons the orphan sweeper deletes a VPA
+const (
+	SweepReasonVpaManagerMissing = "vpamanager_missing"
+	SweepReasonTargetMissing     = "target_missing"
+)
+
+// SweepResultForbidden is the sweep result when the operator lacks cluster-wide access to VPAs
+const SweepResultForbidden = "forbidden"
+
+// orphanSweepMinAge protects VPAs the webhook created at admission, before their workload
+// was persisted or reached the cache
+const orphanSweepMinAge = 10 * time.Minute
+
+// errSweepForbidden means the operator may not list or delete VPAs at cluster scope
+var errSweepForbidden = stderrors.New("missing cluster-wide access to VPAs")
+
+// OrphanSweeper periodically deletes managed VPAs whose VpaManager or target workload
+// no longer exists. Reconciles only find orphans of existing VpaManagers and skip
+// namespaces where workloads cannot be listed; the sweeper covers the rest at cluster scope.
+// VPAs owned by a GitOps controller and VPAs whose target cannot be read are never deleted.
+//
+// A VPA is only deleted once two consecutive sweeps found it orphaned, so a VpaManager or
+// workload that is deleted and recreated in between, e.g. by a GitOps prune and sync, keeps
+// its VPAs and their recommendation history.
+type OrphanSweeper struct {
+	Client          client.Client
+	Metrics         *metrics.Metrics
+	WorkloadConfigs []WorkloadConfig
+	Interval        time.Duration
+
+	// MaxDeletions bounds the deletions per sweep, zero is unlimited. Remaining
+	// orphans are deleted by the next sweep.
+	MaxDeletions int
+
+	// BurstGuard holds back the orphans of a VpaManager when there are more than its
+	// MaxDeletions, like reconciles do. The confirmation annotation of an existing VpaManager
+	// releases them; those of a deleted VpaManager wait for the grace period. nil deletes
+	// them unconditionally.
+	BurstGuard *OrphanBurstGuard
+
+	// Recorder emits an event on VpaManagers whose orphans are held back; nil emits none
+	Recorder record.EventRecorder
+
+	// Ownership is the label that marks this instance's VPAs; VPAs of other instances are never examined
+	Ownership vpa.Ownership
+
+	// Decisions keeps the recent per-workload decisions for debugging; nil records nothing
+	Decisions *decisions.Log
+
+	Log logr.Logger
+
+	// suspects are the UIDs of the VPAs the last sweep found orphaned
+	suspects map[types.UID]bool
+
+	// blockedSince is when the orphans of each VpaManager were first held back
+	blockedSince map[string]metav1.Time
+}
+
+// sweepOrphan is a VPA a sweep found orphaned
+type sweepOrphan struct {
+	vpa    *unstructured.Unstructured
+	reason string
+}
+
+// SweepResult summarizes one orphan sweep
+type SweepResult struct {
+	// Scanned is the number of managed VPAs examined
+	Scanned int
+
+	// Deleted counts deleted VPAs by reason
+	Deleted map[string]int
+
+	// Unverifiable is the number of VPAs whose target could not be read
+	Unverifiable int
+
+	// Suspected is the number of orphans found for the first time, deleted by the next sweep
+	Suspected int
+
+	// HeldBack is the number of orphans held back by the burst guard
+	HeldBack int
+}
+
+// Start runs the sweep loop until the context is cancelled
+func (s *OrphanSweeper) Start(ctx context.Context) error {
+	if s.Log.GetSink() == nil {
+		s.Log = ctrl.Log.WithName("orphan-sweeper")
+	}
+
+	ticker := time.NewTicker(s.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			s.runOnce(ctx)
+		}
+	}
+}
+
+// NeedLeaderElection ensures only the leader sweeps
+func (s *OrphanSweeper) NeedLeaderElection() bool {
+	return true
+}
+
+// runOnce runs a single sweep and records its outcome
+func (s *OrphanSweeper) runOnce(ctx context.Context) {
+	result, err := s.Sweep(ctx)
+	switch {
+	case stderrors.Is(err, errSweepForbidden):
+		s.Log.Info("skipping orphan sweep", "reason", err.Error())
+		s.Metrics.RecordOrphanSweep(SweepResultForbidden, result.Unverifiable)
+	case err != nil:
+		s.Log.Error(err, "orphan sweep failed")
+		s.Metrics.RecordOrphanSweep(metrics.ResultError, result.Unverifiable)
+	default:
+		s.Log.V(1).Info("orphan sweep complete", "scanned", result.Scanned, "deleted", result.Deleted,
+			"suspected", result.Suspected, "heldBack", result.HeldBack, "unverifiable", result.Unverifiable)
+		s.Metrics.RecordOrphanSweep(metrics.ResultSuccess, result.Unverifiable)
+	}
+}
+
+// Sweep deletes orphaned VPAs across the cluster
+func (s *OrphanSweeper) Sweep(ctx context.Context) (SweepResult, error) {
+	result := SweepResult{Deleted: map[string]int{}}
+	if err := s.checkAccess(ctx); err != nil {
+		return result, err
+	}
+
+	orphans, err := s.findOrphans(ctx, &result)
+	if err != nil {
+		return result, err
+	}
+
+	// Only orphans the last sweep found too are deleted
+	previous := s.suspects
+	s.suspects = make(map[types.UID]bool, len(orphans))
+	byManager := map[string][]sweepOrphan{}
+	var managerNames []string
+	for _, orphan := range orphans {
+		s.suspects[orphan.vpa.GetUID()] = true
+		if !previous[orphan.vpa.GetUID()] {
+			result.Suspected++
+			continue
+		}
+		name := orphan.vpa.GetLabels()[vpa.CreatedByLabel]
+		if _, ok := byManager[name]; !ok {
+			managerNames = append(managerNames, name)
+		}
+		byManager[name] = append(byManager[name], orphan)
+	}
+
+	now := metav1.Now()
+	blockedSince := map[string]metav1.Time{}
+	deleted := 0
+	sort.Strings(managerNames)
+	for _, name := range managerNames {
+		pending := byManager[name]
+		vm, err := s.burstSubject(ctx, name, pending[0].reason)
+		if err != nil {
+			return result, err
+		}
+		burst := s.BurstGuard.check(vm, len(pending), false, now)
+		if !burst.allowed {
+			blockedSince[name] = *burst.blockedSince
+			result.HeldBack += len(pending)
+			s.recordHeldBack(vm, pending)
+			continue
+		}
+
+		complete := true
+		for _, orphan := range pending {
+			if s.MaxDeletions > 0 && deleted >= s.MaxDeletions {
+				complete = false
+				break
+			}
+			if err := s.delete(ctx, orphan); err != nil {
+				return result, err
+			}
+			deleted++
+			result.Deleted[orphan.reason]++
+		}
+		if burst.confirmed && complete {
+			if err := s.clearDeletionConfirmation(ctx, vm); err != nil {
+				s.Log.Error(err, "failed to remove orphan deletion confirmation", "vpaManager", name)
+			}
+		}
+	}
+	s.blockedSince = blockedSince
+	return result, nil
+}
+
+// findOrphans lists the managed VPAs and returns those whose VpaManager or target is gone
+func (s *OrphanSweeper) findOrphans(ctx context.Context, result *SweepResult) ([]sweepOrphan, error) {
+	var orphans []sweepOrphan
+	managers := map[string]bool{}
+	pausedNamespaces := map[string]bool{}
+	vpaList := &unstructured.UnstructuredList{}
+	vpaList.SetGroupVersionKind(schema.GroupVersionKind{
+		Group:   vpaGVK.Group,
+		Version: vpaGVK.Version,
+		Kind:    "VerticalPodAutoscalerList",
+	})
+	listOpts := []client.ListOption{
+		client.MatchingLabels(s.Ownership.Selector()),
+		client.Limit(500),
+	}
+
+	var continueToken string
+	for {
+		opts := listOpts
+		if continueToken != "" {
+			opts = append(opts, client.Continue(continueToken))
+		}
+		if err := s.Client.List(ctx, vpaList, opts...); err != nil {
+			return nil, err
+		}
+
+		for i := range vpaList.Items {
+			vpaObj := &vpaList.Items[i]
+			if !s.Ownership.Owns(vpaObj) {
+				continue
+			}
+			result.Scanned++
+			if vpa.GitOpsOwner(vpaObj) != "" || time.Since(vpaObj.GetCreationTimestamp().Time) < orphanSweepMinAge {
+				continue
+			}
+			paused, err := s.namespacePaused(ctx, vpaObj.GetNamespace(), pausedNamespaces)
+			if err != nil {
+				return nil, err
+			}
+			if paused {
+				continue
+			}
+
+			reason, verified, err := s.orphanReason(ctx, vpaObj, managers)
+			if err != nil {
+				return nil, err
+			}
+			if !verified {
+				result.Unverifiable++
+				continue
+			}
+			if reason != "" {
+				orphans = append(orphans, sweepOrphan{vpa: vpaObj.DeepCopy(), reason: reason})
+			}
+		}
+
+		continueToken = vpaList.GetContinue()
+		if continueToken == "" {
+			return orphans, nil
+		}
+	}
+}
+
+// burstSubject returns the VpaManager the burst guard checks the orphans of name against:
+// the existing one with the time its orphans were first held back by the sweeper, or a
+// stand-in without a confirmation annotation when it was deleted
+func (s *OrphanSweeper) burstSubject(ctx context.Context, name, reason string) (*autoscalingv1.VpaManager, error) {
+	vm := &autoscalingv1.VpaManager{}
+	if reason != SweepReasonVpaManagerMissing {
+		err := s.Client.Get(ctx, types.NamespacedName{Name: name}, vm)
+		if err == nil {
+			vm = vm.DeepCopy()
+		} else if !errors.IsNotFound(err) {
+			return nil, err
+		}
+	}
+	if vm.UID == "" {
+		vm = &autoscalingv1.VpaManager{ObjectMeta: metav1.ObjectMeta{Name: name}}
+	}
+	vm.Status.OrphanDeletionsBlockedSince = nil
+	if since, ok := s.blockedSince[name]; ok {
+		vm.Status.OrphanDeletionsBlockedSince = &since
+	}
+	return vm, nil
+}
+
+// recordHeldBack logs, records and reports the orphans of vm the burst guard held back
+func (s *OrphanSweeper) recordHeldBack(vm *autoscalingv1.VpaManager, pending []sweepOrphan) {
+	s.Log.Info("holding back orphan VPA deletions", "vpaManager", vm.Name, "pending", len(pending),
+		"limit", s.BurstGuard.MaxDeletions, "blockedSince", vm.Status.OrphanDeletionsBlockedSince)
+	for _, orphan := range pending {
+		s.Decisions.Record(decisions.Decision{
+			Source:     decisions.SourceSweep,
+			VpaManager: vm.Name,
+			Namespace:  orphan.vpa.GetNamespace(),
+			VPA:        orphan.vpa.GetName(),
+			Action:     decisions.ActionHeldBack,
+			Reason:     "OrphanBurstProtection",
+		})
+	}
+	// Deleted VpaManagers cannot carry events
+	if s.Recorder == nil || vm.UID == "" {
+		return
+	}
+	s.Recorder.Eventf(vm, corev1.EventTypeWarning, "OrphanDeletionBlocked",
+		"The orphan sweep found %d orphaned VPAs, exceeding the deletion limit of %d; annotate with %s=true to delete them now",
+		len(pending), s.BurstGuard.MaxDeletions, ConfirmOrphanDeletionAnnotation)
+}
+
+// clearDeletionConfirmation removes the confirmation annotation once the confirmed
+// deletions ran
+func (s *OrphanSweeper) clearDeletionConfirmation(ctx context.Context, vm *autoscalingv1.VpaManager) error {
+	patched := vm.DeepCopy()
+	delete(patched.Annotations, ConfirmOrphanDeletionAnnotation)
+	return s.Client.Patch(ctx, patched, client.MergeFrom(vm))
+}
+
+// delete deletes an orphaned VPA and records it
+func (s *OrphanSweeper) delete(ctx context.Context, orphan sweepOrphan) error {
+	vpaObj := orphan.vpa
+	writeStart := time.Now()
+	err := s.Client.Delete(ctx, vpaObj)
+	s.Metrics.ObserveVPAWrite("delete", vpaObj.GetLabels()[vpa.CreatedByLabel], writeStart)
+	if err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	s.Metrics.RecordOrphanSweepDeletion(orphan.reason)
+	s.Log.Info("deleted orphaned VPA", "namespace", vpaObj.GetNamespace(), "name", vpaObj.GetName(), "reason", orphan.reason)
+	s.Decisions.Record(decisions.Decision{
+		Source:     decisions.SourceSweep,
+		VpaManager: vpaObj.GetLabels()[vpa.CreatedByLabel],
+		Namespace:  vpaObj.GetNamespace(),
+		VPA:        vpaObj.GetName(),
+		Action:     decisions.ActionDeleted,
+		Reason:     orphan.reason,
+	})
+	return nil
+}
+
+// namespacePaused reports whether a namespace is paused, caching the answer in pausedNamespaces
+func (s *OrphanSweeper) namespacePaused(ctx context.Context, name string, pausedNamespaces map[string]bool) (bool, error) {
+	if paused, cached := pausedNamespaces[name]; cached {
+		return paused, nil
+	}
+	ns := &corev1.Namespace{}
+	if err := s.Client.Get(ctx, types.NamespacedName{Name: name}, ns); err != nil && !errors.IsNotFound(err) {
+		return false, err
+	}
+	pausedNamespaces[name] = workload.NamespacePaused(ns)
+	return pausedNamespaces[name], nil
+}
+
+// orphanReason returns why a VPA is orphaned, empty when it is not. verified is false when
+// its target workload could not be read, so the VPA must be left alone.
+func (s *OrphanSweeper) orphanReason(ctx context.Context, vpaObj *unstructured.Unstructured, managers map[string]bool) (reason string, verified bool, err error) {
+	managerName := vpaObj.GetLabels()[vpa.CreatedByLabel]
+	if managerName == "" {
+		return "", true, nil
+	}
+	exists, cached := managers[managerName]
+	if !cached {
+		err := s.Client.Get(ctx, types.NamespacedName{Name: managerName}, &autoscalingv1.VpaManager{})
+		if err != nil && !errors.IsNotFound(err) {
+			return "", false, err
+		}
+		exists = err == nil
+		managers[managerName] = exists
+	}
+	if !exists {
+		return SweepReasonVpaManagerMissing, true, nil
+	}
+
+	kind, _, _ := unstructured.NestedString(vpaObj.Object, "spec", "targetRef", "kind")
+	name, _, _ := unstructured.NestedString(vpaObj.Object, "spec", "targetRef", "name")
+	wc, ok := workloadConfigIn(s.WorkloadConfigs, kind)
+	if !ok || name == "" {
+		return "", true, nil
+	}
+	_, err = wc.Provider.Get(ctx, s.Client, vpaObj.GetNamespace(), name)
+	switch {
+	case err == nil:
+		return "", true, nil
+	case errors.IsNotFound(err):
+		return SweepReasonTargetMissing, true, nil
+	case errors.IsForbidden(err):
+		return "", false, nil
+	default:
+		return "", false, err
+	}
+}
+
+// checkAccess verifies the operator may list and delete VPAs in every namespace
+func (s *OrphanSweeper) checkAccess(ctx context.Context) error {
+	for _, verb := range []string{"list", "delete"} {
+		review := &authorizationv1.SelfSubjectAccessReview{
+			Spec: authorizationv1.SelfSubjectAccessReviewSpec{
+				ResourceAttributes: &authorizationv1.ResourceAttributes{
+					Group:    vpaGVK.Group,
+					Resource: "verticalpodautoscalers",
+					Verb:     verb,
+				},
+			},
+		}
+		if err := s.Client.Create(ctx, review); err != nil {
+			return fmt.Errorf("checking access to %s VPAs: %w", verb, err)
+		}
+		if !review.Status.Allowed {
+			return fmt.Errorf("%w: may not %s verticalpodautoscalers at cluster scope", errSweepForbidden, verb)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,340 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	autoscalingv1 "github.com/joaomo/k8s_op_vpa/api/v1"
+	"github.com/joaomo/k8s_op_vpa/internal/metrics"
+	"github.com/joaomo/k8s_op_vpa/internal/vpa"
+	"github.com/joaomo/k8s_op_vpa/internal/workload"
+)
+
+func TestOrphanSweeper_Sweep(t *testing.T) {
+	old := metav1.NewTime(time.Now().Add(-time.Hour))
+
+	sweptVPA := func(name, namespace, manager, target string, mutate ...func(*unstructured.Unstructured)) *unstructured.Unstructured {
+		vpaObj := createUnstructuredVPA(name, namespace, target)
+		labels := vpaObj.GetLabels()
+		labels["app.kubernetes.io/created-by"] = manager
+		vpaObj.SetLabels(labels)
+		vpaObj.SetCreationTimestamp(old)
+		for _, fn := range mutate {
+			fn(vpaObj)
+		}
+		return vpaObj
+	}
+	manager := &autoscalingv1.VpaManager{ObjectMeta: metav1.ObjectMeta{Name: "test-vpamanager"}}
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "unselected"},
+		Spec:       createDeploymentSpec(),
+	}
+
+	tests := []struct {
+		name                 string
+		objects              []client.Object
+		denied               bool
+		forbiddenTarget      bool
+		maxDeletions         int
+		expectedErr          error
+		expectedRemaining    []string
+		expectedDeleted      map[string]int
+		expectedUnverifiable int
+	}{
+		{
+			name: "deletes VPA of a deleted VpaManager",
+			objects: []client.Object{
+				deployment,
+				sweptVPA("app-vpa", "unselected", "deleted-vpamanager", "app"),
+			},
+			expectedDeleted: map[string]int{SweepReasonVpaManagerMissing: 1},
+		},
+		{
+			name: "deletes VPA of a deleted target in any namespace",
+			objects: []client.Object{
+				manager,
+				deployment,
+				sweptVPA("app-vpa", "unselected", "test-vpamanager", "app"),
+				sweptVPA("gone-vpa", "unselected", "test-vpamanager", "gone"),
+			},
+			expectedRemaining: []string{"app-vpa"},
+			expectedDeleted:   map[string]int{SweepReasonTargetMissing: 1},
+		},
+		{
+			name: "keeps GitOps-owned and recently created VPAs",
+			objects: []client.Object{
+				sweptVPA("argocd-vpa", "unselected", "deleted-vpamanager", "gone", func(u *unstructured.Unstructured) {
+					u.SetAnnotations(map[string]string{"argocd.argoproj.io/tracking-id": "app:autoscaling.k8s.io/VerticalPodAutoscaler:unselected/argocd-vpa"})
+				}),
+				sweptVPA("new-vpa", "unselected", "deleted-vpamanager", "gone", func(u *unstructured.Unstructured) {
+					u.SetCreationTimestamp(metav1.Now())
+				}),
+			},
+			expectedRemaining: []string{"argocd-vpa", "new-vpa"},
+			expectedDeleted:   map[string]int{},
+		},
+		{
+			name: "ignores VPAs of other operator instances",
+			objects: []client.Object{
+				sweptVPA("staging-vpa", "unselected", "deleted-vpamanager", "gone", func(u *unstructured.Unstructured) {
+					u.SetLabels(vpa.Ownership{Instance: "staging"}.Labels("deleted-vpamanager"))
+				}),
+			},
+			expectedRemaining: []string{"staging-vpa"},
+			expectedDeleted:   map[string]int{},
+		},
+		{
+			name: "keeps VPAs whose target cannot be read",
+			objects: []client.Object{
+				manager,
+				sweptVPA("gone-vpa", "unselected", "test-vpamanager", "gone"),
+			},
+			forbiddenTarget:      true,
+			expectedRemaining:    []string{"gone-vpa"},
+			expectedDeleted:      map[string]int{},
+			expectedUnverifiable: 1,
+		},
+		{
+			name: "keeps VPAs in paused namespaces",
+			objects: []client.Object{
+				&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "frozen", Annotations: map[string]string{workload.PausedAnnotation: "true"}}},
+				sweptVPA("frozen-vpa", "frozen", "deleted-vpamanager", "gone"),
+				sweptVPA("app-vpa", "unselected", "deleted-vpamanager", "app"),
+			},
+			expectedRemaining: []string{"frozen-vpa"},
+			expectedDeleted:   map[string]int{SweepReasonVpaManagerMissing: 1},
+		},
+		{
+			name: "bounds deletions per sweep",
+			objects: []client.Object{
+				sweptVPA("a-vpa", "unselected", "deleted-vpamanager", "a"),
+				sweptVPA("b-vpa", "unselected", "deleted-vpamanager", "b"),
+				sweptVPA("c-vpa", "unselected", "deleted-vpamanager", "c"),
+			},
+			maxDeletions:      2,
+			expectedRemaining: []string{"c-vpa"},
+			expectedDeleted:   map[string]int{SweepReasonVpaManagerMissing: 2},
+		},
+		{
+			name: "skips the sweep without cluster-wide access",
+			objects: []client.Object{
+				sweptVPA("app-vpa", "unselected", "deleted-vpamanager", "app"),
+			},
+			denied:            true,
+			expectedErr:       errSweepForbidden,
+			expectedRemaining: []string{"app-vpa"},
+			expectedDeleted:   map[string]int{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheme := setupScheme(t)
+			require.NoError(t, authorizationv1.AddToScheme(scheme))
+			ctx := context.Background()
+
+			fakeClient := fake.NewClientBuilder().
+				WithScheme(scheme).
+				WithObjects(tt.objects...).
+				WithInterceptorFuncs(interceptor.Funcs{
+					Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+						if review, ok := obj.(*authorizationv1.SelfSubjectAccessReview); ok {
+							review.Status.Allowed = !tt.denied
+							return nil
+						}
+						return c.Create(ctx, obj, opts...)
+					},
+					Get: func(ctx context.Context, c client.WithWatch, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+						if _, ok := obj.(*appsv1.Deployment); ok && tt.forbiddenTarget {
+							return apierrors.NewForbidden(schema.GroupResource{Group: "apps", Resource: "deployments"}, key.Name, fmt.Errorf("RBAC: access denied"))
+						}
+						return c.Get(ctx, key, obj, opts...)
+					},
+				}).
+				Build()
+
+			m := createTestMetrics()
+			sweeper := &OrphanSweeper{Client: fakeClient, Metrics: m, MaxDeletions: tt.maxDeletions}
+
+			// Orphans are only deleted once a second sweep finds them again
+			result, err := sweeper.Sweep(ctx)
+			if tt.expectedErr == nil {
+				require.NoError(t, err)
+				assert.Empty(t, result.Deleted)
+			}
+			result, err = sweeper.Sweep(ctx)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Equal(t, tt.expectedDeleted, result.Deleted)
+			assert.Equal(t, tt.expectedUnverifiable, result.Unverifiable)
+			for reason, count := range tt.expectedDeleted {
+				assert.Equal(t, float64(count), testutil.ToFloat64(m.OrphanSweepDeletionsTotal.WithLabelValues(reason)))
+			}
+
+			vpaList := newVPAList()
+			require.NoError(t, fakeClient.List(ctx, vpaList))
+			remaining := []string{}
+			for _, item := range vpaList.Items {
+				remaining = append(remaining, item.GetName())
+			}
+			assert.ElementsMatch(t, tt.expectedRemaining, remaining)
+		})
+	}
+}
+
+// Test: a VpaManager or target recreated between sweeps keeps its VPAs
+func TestOrphanSweeper_KeepsRecreatedOwners(t *testing.T) {
+	scheme := setupScheme(t)
+	require.NoError(t, authorizationv1.AddToScheme(scheme))
+	ctx := context.Background()
+
+	vpaObj := createUnstructuredVPA("app-vpa", "default", "app")
+	vpaObj.SetCreationTimestamp(metav1.NewTime(time.Now().Add(-time.Hour)))
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(vpaObj, &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "default"},
+			Spec:       createDeploymentSpec(),
+		}).
+		WithInterceptorFuncs(allowSweepAccess()).
+		Build()
+	sweeper := &OrphanSweeper{Client: fakeClient, Metrics: createTestMetrics()}
+
+	// The VpaManager is pruned
+	result, err := sweeper.Sweep(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Suspected)
+
+	// and synced again before the next sweep
+	require.NoError(t, fakeClient.Create(ctx, &autoscalingv1.VpaManager{ObjectMeta: metav1.ObjectMeta{Name: "test-vpamanager"}}))
+	result, err = sweeper.Sweep(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, result.Deleted)
+	assert.Zero(t, result.Suspected)
+
+	// A later prune starts over
+	require.NoError(t, fakeClient.Delete(ctx, &autoscalingv1.VpaManager{ObjectMeta: metav1.ObjectMeta{Name: "test-vpamanager"}}))
+	result, err = sweeper.Sweep(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, result.Deleted)
+	assert.Equal(t, 1, result.Suspected)
+
+	vpaList := newVPAList()
+	require.NoError(t, fakeClient.List(ctx, vpaList))
+	assert.Len(t, vpaList.Items, 1)
+}
+
+// Test: the orphans of a VpaManager over the burst limit are held back until confirmed,
+// those of a deleted VpaManager until the grace period elapsed
+func TestOrphanSweeper_HoldsBackBursts(t *testing.T) {
+	scheme := setupScheme(t)
+	require.NoError(t, authorizationv1.AddToScheme(scheme))
+	ctx := context.Background()
+
+	old := metav1.NewTime(time.Now().Add(-time.Hour))
+	objects := []client.Object{
+		&autoscalingv1.VpaManager{ObjectMeta: metav1.ObjectMeta{Name: "test-vpamanager", UID: "manager-uid"}},
+	}
+	for _, name := range []string{"a", "b", "c"} {
+		// Targets of an existing VpaManager that are gone
+		gone := createUnstructuredVPA(name+"-vpa", "default", name)
+		gone.SetCreationTimestamp(old)
+		// VPAs of a deleted VpaManager
+		deleted := createUnstructuredVPA(name+"-vpa", "other", name)
+		deleted.SetLabels(vpa.Ownership{}.Labels("deleted-vpamanager"))
+		deleted.SetCreationTimestamp(old)
+		objects = append(objects, gone, deleted)
+	}
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(objects...).
+		WithInterceptorFuncs(allowSweepAccess()).
+		Build()
+	guard := &OrphanBurstGuard{MaxDeletions: 2, GracePeriod: time.Hour}
+	sweeper := &OrphanSweeper{Client: fakeClient, Metrics: createTestMetrics(), BurstGuard: guard}
+
+	for i := 0; i < 2; i++ {
+		result, err := sweeper.Sweep(ctx)
+		require.NoError(t, err)
+		assert.Empty(t, result.Deleted)
+	}
+	result, err := sweeper.Sweep(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 6, result.HeldBack)
+
+	// Confirming releases the orphans of the existing VpaManager and is consumed
+	manager := &autoscalingv1.VpaManager{}
+	require.NoError(t, fakeClient.Get(ctx, client.ObjectKey{Name: "test-vpamanager"}, manager))
+	manager.Annotations = map[string]string{ConfirmOrphanDeletionAnnotation: "true"}
+	require.NoError(t, fakeClient.Update(ctx, manager))
+	result, err = sweeper.Sweep(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{SweepReasonTargetMissing: 3}, result.Deleted)
+	assert.Equal(t, 3, result.HeldBack)
+	require.NoError(t, fakeClient.Get(ctx, client.ObjectKey{Name: "test-vpamanager"}, manager))
+	assert.NotContains(t, manager.Annotations, ConfirmOrphanDeletionAnnotation)
+
+	// Once the grace period elapsed, the orphans of the deleted VpaManager follow
+	sweeper.blockedSince["deleted-vpamanager"] = metav1.NewTime(time.Now().Add(-2 * time.Hour))
+	result, err = sweeper.Sweep(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{SweepReasonVpaManagerMissing: 3}, result.Deleted)
+
+	vpaList := newVPAList()
+	require.NoError(t, fakeClient.List(ctx, vpaList))
+	assert.Empty(t, vpaList.Items)
+}
+
+// allowSweepAccess grants the sweeper cluster-wide access to VPAs
+func allowSweepAccess() interceptor.Funcs {
+	return interceptor.Funcs{
+		Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+			if review, ok := obj.(*authorizationv1.SelfSubjectAccessReview); ok {
+				review.Status.Allowed = true
+				return nil
+			}
+			return c.Create(ctx, obj, opts...)
+		},
+	}
+}
+
+func TestOrphanSweeper_RunOnceRecordsResult(t *testing.T) {
+	scheme := setupScheme(t)
+	require.NoError(t, authorizationv1.AddToScheme(scheme))
+
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithInterceptorFuncs(interceptor.Funcs{
+			Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+				obj.(*authorizationv1.SelfSubjectAccessReview).Status.Allowed = false
+				return nil
+			},
+		}).
+		Build()
+
+	m := createTestMetrics()
+	sweeper := &OrphanSweeper{Client: fakeClient, Metrics: m}
+	sweeper.runOnce(context.Background())
+
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.OrphanSweepsTotal.WithLabelValues(SweepResultForbidden)))
+	assert.Equal(t, float64(0), testutil.ToFloat64(m.OrphanSweepsTotal.WithLabelValues(metrics.ResultSuccess)))
+}
@@ -0,0 +1,48 @@
+package controller
+
+import (
+	"context"
+	"sync"
+
+	"github.com/joaomo/k8s_op_vpa/internal/workload"
+)
+
+// evictionBlocked reports, when TopologyGuard is set, whether pods of wl evicted by the VPA
+// updater could not be rescheduled because of required pod anti-affinity, and on which
+// topology key
+func (r *VpaManagerReconciler) evictionBlocked(ctx context.Context, wl workload.Workload) (string, bool, error) {
+	if !r.TopologyGuard {
+		return "", false, nil
+	}
+	return workload.EvictionBlocked(ctx, r.Client, wl)
+}
+
+// topologyGuardable reports whether the topology guard can block eviction for wl: it has a
+// replica count and required pod anti-affinity on its own pods
+func topologyGuardable(wl workload.Workload) bool {
+	if _, ok := wl.(workload.Scalable); !ok {
+		return false
+	}
+	return len(workload.SelfAntiAffinityTopologyKeys(wl.GetNamespace(), wl.GetPodTemplateSpec())) > 0
+}
+
+// guardedManagers remembers the VpaManagers that manage a workload the topology guard can
+// block, the only ones node changes affect. A split pass only ever adds to it.
+type guardedManagers struct {
+	names sync.Map
+}
+
+// set records whether the VpaManager name manages a guardable workload
+func (g *guardedManagers) set(name string, guarded bool) {
+	if guarded {
+		g.names.Store(name, true)
+	} else {
+		g.names.Delete(name)
+	}
+}
+
+// has reports whether the VpaManager name manages a guardable workload
+func (g *guardedManagers) has(name string) bool {
+	_, ok := g.names.Load(name)
+	return ok
+}
@@ -0,0 +1,164 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	autoscalingv1 "github.com/joaomo/k8s_op_vpa/api/v1"
+	"github.com/joaomo/k8s_op_vpa/internal/vpa"
+)
+
+// Test: a statefulset with one replica per node through required pod anti-affinity gets an
+// Off VPA while the topology guard is enabled
+func TestReconcile_TopologyGuard(t *testing.T) {
+	tests := []struct {
+		name               string
+		topologyGuard      bool
+		expectedMode       string
+		expectedAnnotation string
+	}{
+		{name: "guard enabled", topologyGuard: true, expectedMode: "Off", expectedAnnotation: "kubernetes.io/hostname"},
+		{name: "guard disabled", expectedMode: "Auto"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheme := setupScheme(t)
+			ctx := context.Background()
+
+			selected := map[string]string{"vpa-enabled": "true"}
+			vpaManager := &autoscalingv1.VpaManager{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-vpamanager"},
+				Spec: autoscalingv1.VpaManagerSpec{
+					Enabled:             true,
+					UpdateMode:          "Auto",
+					NamespaceSelector:   &metav1.LabelSelector{MatchLabels: selected},
+					StatefulSetSelector: &metav1.LabelSelector{MatchLabels: selected},
+				},
+			}
+			replicas := int32(2)
+			stsSpec := createStatefulSetSpec()
+			stsSpec.Replicas = &replicas
+			stsSpec.Template.Spec.Affinity = &corev1.Affinity{PodAntiAffinity: &corev1.PodAntiAffinity{
+				RequiredDuringSchedulingIgnoredDuringExecution: []corev1.PodAffinityTerm{{
+					LabelSelector: &metav1.LabelSelector{MatchLabels: stsSpec.Template.Labels},
+					TopologyKey:   "kubernetes.io/hostname",
+				}},
+			}}
+			fakeClient := fake.NewClientBuilder().
+				WithScheme(scheme).
+				WithObjects(
+					vpaManager,
+					&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "test-ns", Labels: selected}},
+					&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-a", Labels: map[string]string{"kubernetes.io/hostname": "node-a"}}},
+					&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-b", Labels: map[string]string{"kubernetes.io/hostname": "node-b"}}},
+					&appsv1.StatefulSet{
+						ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "test-ns", Labels: selected},
+						Spec:       stsSpec,
+					},
+				).
+				WithStatusSubresource(vpaManager).
+				Build()
+			reconciler := &VpaManagerReconciler{
+				Client:          fakeClient,
+				Scheme:          scheme,
+				Metrics:         createTestMetrics(),
+				WorkloadConfigs: DefaultWorkloadConfigs(),
+				TopologyGuard:   tt.topologyGuard,
+			}
+
+			_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: "test-vpamanager"}})
+			require.NoError(t, err)
+
+			vpaList := newVPAList()
+			require.NoError(t, fakeClient.List(ctx, vpaList))
+			require.Len(t, vpaList.Items, 1)
+			vpaObj := vpaList.Items[0]
+			spec, _ := vpaObj.Object["spec"].(map[string]interface{})
+			assert.Equal(t, tt.expectedMode, vpa.UpdateMode(spec))
+			assert.Equal(t, tt.expectedAnnotation, vpaObj.GetAnnotations()[vpa.EvictionBlockedAnnotation])
+		})
+	}
+}
+
+// Test: node changes enqueue only the VpaManagers that manage a workload with required pod
+// anti-affinity on its own pods
+func TestFindVpaManagersForNode_OnlyGuardedManagers(t *testing.T) {
+	scheme := setupScheme(t)
+	ctx := context.Background()
+
+	newManager := func(name, team string) *autoscalingv1.VpaManager {
+		return &autoscalingv1.VpaManager{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec: autoscalingv1.VpaManagerSpec{
+				Enabled:             true,
+				UpdateMode:          "Auto",
+				StatefulSetSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"team": team}},
+			},
+		}
+	}
+	guardedManager := newManager("guarded", "db")
+	plainManager := newManager("plain", "cache")
+
+	replicas := int32(2)
+	affineSpec := createStatefulSetSpec()
+	affineSpec.Replicas = &replicas
+	affineSpec.Template.Spec.Affinity = &corev1.Affinity{PodAntiAffinity: &corev1.PodAntiAffinity{
+		RequiredDuringSchedulingIgnoredDuringExecution: []corev1.PodAffinityTerm{{
+			LabelSelector: &metav1.LabelSelector{MatchLabels: affineSpec.Template.Labels},
+			TopologyKey:   "kubernetes.io/hostname",
+		}},
+	}}
+	plainSpec := createStatefulSetSpec()
+	plainSpec.Replicas = &replicas
+
+	node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-a", Labels: map[string]string{"kubernetes.io/hostname": "node-a"}}}
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(
+			guardedManager,
+			plainManager,
+			&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "test-ns"}},
+			node,
+			&appsv1.StatefulSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "test-ns", Labels: map[string]string{"team": "db"}},
+				Spec:       affineSpec,
+			},
+			&appsv1.StatefulSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "cache", Namespace: "test-ns", Labels: map[string]string{"team": "cache"}},
+				Spec:       plainSpec,
+			},
+		).
+		WithStatusSubresource(guardedManager, plainManager).
+		Build()
+	reconciler := &VpaManagerReconciler{
+		Client:          fakeClient,
+		Scheme:          scheme,
+		Metrics:         createTestMetrics(),
+		WorkloadConfigs: DefaultWorkloadConfigs(),
+		TopologyGuard:   true,
+	}
+
+	assert.Empty(t, reconciler.findVpaManagersForNode(ctx, node), "nothing is guarded before a reconcile")
+
+	for _, name := range []string{"guarded", "plain"} {
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: name}})
+		require.NoError(t, err)
+	}
+	assert.Equal(t, []reconcile.Request{{NamespacedName: types.NamespacedName{Name: "guarded"}}},
+		reconciler.findVpaManagersForNode(ctx, node))
+
+	require.NoError(t, fakeClient.Delete(ctx, guardedManager))
+	_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: "guarded"}})
+	require.NoError(t, err)
+	assert.Empty(t, reconciler.findVpaManagersForNode(ctx, node))
+}
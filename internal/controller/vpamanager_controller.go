@@ -4,40 +4,109 @@ import (
 	"context"
 	"crypto/sha256"
 	"encoding/json"
+	stderrors "errors"
 	"fmt"
+	"maps"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/go-logr/logr"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/uuid"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
+	ctrlbuilder "sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	autoscalingv1 "github.com/joaomo/k8s_op_vpa/api/v1"
+	"github.com/joaomo/k8s_op_vpa/internal/decisions"
+	"github.com/joaomo/k8s_op_vpa/internal/inheritance"
+	"github.com/joaomo/k8s_op_vpa/internal/labelselector"
 	"github.com/joaomo/k8s_op_vpa/internal/metrics"
+	"github.com/joaomo/k8s_op_vpa/internal/policy"
+	"github.com/joaomo/k8s_op_vpa/internal/validation"
+	"github.com/joaomo/k8s_op_vpa/internal/vpa"
 	"github.com/joaomo/k8s_op_vpa/internal/workload"
 )
 
+// namespaceSelectorField names the namespace selector in the selector cache; workload
+// selectors are named by their kind
+const namespaceSelectorField = "namespaceSelector"
+
+// namespaceExcludeSelectorField names the namespace exclude selector in the selector cache
+const namespaceExcludeSelectorField = "namespaceExcludeSelector"
+
 var (
 	vpaGVK = schema.GroupVersionKind{
 		Group:   "autoscaling.k8s.io",
 		Version: "v1",
 		Kind:    "VerticalPodAutoscaler",
 	}
+	crdGVK = schema.GroupVersionKind{
+		Group:   "apiextensions.k8s.io",
+		Version: "v1",
+		Kind:    "CustomResourceDefinition",
+	}
 )
 
-// WorkloadConfig maps a workload kind to its selector in VpaManagerSpec
+// vpaAction describes the outcome of ensuring a VPA for a workload
+type vpaAction int
+
+const (
+	vpaUnchanged vpaAction = iota
+	vpaCreated
+	vpaUpdated
+	// vpaDrifted means the VPA is owned by a GitOps controller and differs from the desired spec
+	vpaDrifted
+	// vpaForeign means the VPA was generated by another operator instance and is left alone
+	vpaForeign
+	// vpaReset means the VPA belonged to a deleted workload of the same name and was regenerated
+	vpaReset
+	// vpaOverCapacity means the VPA was not created because the cluster-wide cap is reached
+	vpaOverCapacity
+	// vpaHandedOver means the VPA of another VpaManager was taken over and updated
+	vpaHandedOver
+	// vpaHandoverDeferred means the VPA of another VpaManager is left to a later reconcile
+	// because this one took over MaxHandovers VPAs already
+	vpaHandoverDeferred
+	// vpaTerminating means the VPA is being deleted and is left alone until it is gone
+	vpaTerminating
+)
+
+// terminatingVPARequeueDelay is how soon a VpaManager whose VPAs are being deleted
+// reconciles again to recreate them
+const terminatingVPARequeueDelay = 5 * time.Second
+
+// operation returns the metric label for the VPA write an action represents
+func (a vpaAction) operation() string {
+	switch a {
+	case vpaCreated:
+		return "create"
+	case vpaUpdated, vpaReset, vpaHandedOver:
+		return "update"
+	default:
+		return "get"
+	}
+}
+
+// WorkloadConfig maps a workload kind to its selectors in VpaManagerSpec, of which a
+// workload must match any
 type WorkloadConfig struct {
-	Provider workload.Provider
-	Selector func(*autoscalingv1.VpaManagerSpec) *metav1.LabelSelector
+	Provider  workload.Provider
+	Selectors func(*autoscalingv1.VpaManagerSpec) []*metav1.LabelSelector
 }
 
 // VpaManagerReconciler reconciles a VpaManager object
@@ -46,144 +115,864 @@ type VpaManagerReconciler struct {
 	Scheme          *runtime.Scheme
 	Metrics         *metrics.Metrics
 	Log             logr.Logger
+	Recorder        record.EventRecorder
 	WorkloadConfigs []WorkloadConfig
+
+	// SelectorDefaults fills in omitted selectors; nil keeps the legacy behavior
+	SelectorDefaults *policy.SelectorDefaults
+
+	// StrictSelectors makes an omitted namespaceSelector match no namespaces unless
+	// matchAllNamespaces is set. When false, the deprecated match-all behavior is kept.
+	StrictSelectors bool
+
+	// ClusterCapacity caps the VPAs managed across the cluster; nil creates VPAs without a cap
+	ClusterCapacity *vpa.ClusterCapacity
+
+	// Selectors caches compiled label selectors per VpaManager generation; nil compiles them on every use
+	Selectors *labelselector.Cache
+
+	// VPAAvailability tells whether the API server serves VPAs. While it does not, reconciles
+	// only report the missing CRD. nil assumes VPAs are always served.
+	VPAAvailability *vpa.Availability
+
+	// Summary collects what DailySummary reports; nil disables collection
+	Summary *SummaryLedger
+
+	// OrphanBurstGuard holds back mass orphan deletions; nil deletes orphans unconditionally
+	OrphanBurstGuard *OrphanBurstGuard
+
+	// MaxHandovers bounds the VPAs one reconcile takes over from other VpaManagers, e.g. when
+	// a relabeled namespace moves to another tier. The rest follow in a reconcile shortly
+	// after. Zero takes over every VPA at once.
+	MaxHandovers int
+
+	// Self is the operator's own workload, which is never managed; nil disables the check
+	Self *workload.Self
+
+	// Bootstrapped are the VPA components with a bootstrap VPA, which are never managed;
+	// nil disables the check
+	Bootstrapped *workload.Bootstrapped
+
+	// RecordWorkloadLists keeps the per-workload status lists (managedDeployments,
+	// managedStatefulSets, managedDaemonSets, managedWorkloads). They are expensive at
+	// scale, so only the count fields are kept by default.
+	RecordWorkloadLists bool
+
+	// AnnotateWorkloads sets the vpa-operator.io/vpa annotation of every managed workload to
+	// its VPA and effective update mode, and removes it when the VPA is deleted as an orphan
+	AnnotateWorkloads bool
+
+	// CheckpointWarmStart copies the recommender checkpoints of another VPA targeting a
+	// workload to the VPA created for it, so migrating from hand-made VPAs keeps the
+	// recommendation history
+	CheckpointWarmStart bool
+
+	// TopologyGuard turns the VPA of a workload Off while evicted pods could not be
+	// rescheduled: required pod anti-affinity allows one pod per topology domain and the
+	// workload has as many replicas as there are domains
+	TopologyGuard bool
+
+	// WebhookCertificate reports the expiry of the webhook serving certificate for the
+	// Degraded condition; nil when this instance serves no webhooks
+	WebhookCertificate CertificateExpiry
+
+	// ResyncPeriod is how often a VpaManager is reconciled without any change,
+	// DefaultResyncPeriod when zero
+	ResyncPeriod time.Duration
+
+	// Ownership is the label that marks this instance's VPAs, app.kubernetes.io/managed-by=vpa-operator
+	// when zero. Orphan cleanup only ever deletes VPAs carrying it.
+	Ownership vpa.Ownership
+
+	// ReconcileBudget bounds how long one reconcile processes namespaces. A pass over more
+	// namespaces continues in further reconciles, recorded in status.progress, so one large
+	// VpaManager does not hold a worker. Zero processes every namespace in one reconcile.
+	ReconcileBudget time.Duration
+
+	// MaxNamespacesPerCycle bounds how many namespaces one reconcile processes, keeping its
+	// API requests within priority and fairness limits. Like ReconcileBudget, a longer pass
+	// continues in further reconciles. Zero does not limit the count.
+	MaxNamespacesPerCycle int
+
+	// KindRecheckInterval is how often workload kinds whose API was missing at startup are
+	// checked again, e.g. Argo Rollouts before its CRD is installed. Zero disables rechecks.
+	KindRecheckInterval time.Duration
+
+	// Decisions keeps the recent per-workload decisions for debugging; nil records nothing
+	Decisions *decisions.Log
+
+	// kinds holds the workload kinds the API server serves; nil until SetupWithManager, when
+	// every kind is managed
+	kinds *workloadKinds
+
+	// deletedVPAs remembers recently deleted VPAs, so recreating one counts as a repair
+	deletedVPAs deletedVPAs
+
+	// deprecationWarned holds the UIDs of VpaManagers already warned about deprecated status fields
+	deprecationWarned sync.Map
+
+	// guarded holds the VpaManagers whose workloads node changes affect with TopologyGuard
+	guarded guardedManagers
 }
 
+// maxRejectionMessageLength bounds rejection messages copied into status
+const maxRejectionMessageLength = 256
+
+// maxLastErrorLength bounds status.lastError
+const maxLastErrorLength = 512
+
 // +kubebuilder:rbac:groups=operators.joaomo.io,resources=vpamanagers,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=operators.joaomo.io,resources=vpamanagers/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=operators.joaomo.io,resources=vpamanagers/finalizers,verbs=update
-// +kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch
-// +kubebuilder:rbac:groups=apps,resources=statefulsets,verbs=get;list;watch
-// +kubebuilder:rbac:groups=apps,resources=daemonsets,verbs=get;list;watch
+// +kubebuilder:rbac:groups=operators.joaomo.io,resources=vpaoverrides,verbs=get;list;watch
+// +kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch;patch
+// +kubebuilder:rbac:groups=apps,resources=statefulsets,verbs=get;list;watch;patch
+// +kubebuilder:rbac:groups=apps,resources=daemonsets,verbs=get;list;watch;patch
 // +kubebuilder:rbac:groups=autoscaling.k8s.io,resources=verticalpodautoscalers,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=autoscaling.k8s.io,resources=verticalpodautoscalercheckpoints,verbs=list;create
 // +kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+// +kubebuilder:rbac:groups="",resources=pods,verbs=get
+// +kubebuilder:rbac:groups="",resources=resourcequotas,verbs=list;watch
+// +kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=nodes,verbs=list;watch
+// +kubebuilder:rbac:groups=apps,resources=replicasets,verbs=get
+// +kubebuilder:rbac:groups=authorization.k8s.io,resources=selfsubjectaccessreviews,verbs=create
+// +kubebuilder:rbac:groups=apiextensions.k8s.io,resources=customresourcedefinitions,verbs=get;list;watch
+// +kubebuilder:rbac:groups=admissionregistration.k8s.io,resources=mutatingwebhookconfigurations;validatingwebhookconfigurations,verbs=get;list;watch;create;update;delete
 
 // Reconcile implements the reconciliation loop for VpaManager
 func (r *VpaManagerReconciler) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
 	start := time.Now()
-	log := ctrl.LoggerFrom(ctx).WithValues("vpamanager", req.Name)
+	reconcileID := string(uuid.NewUUID())
+	ctx = vpa.WithReconcileID(ctx, reconcileID)
+	log := ctrl.LoggerFrom(ctx).WithValues("vpamanager", req.Name, "vpaReconcileID", reconcileID)
 
 	// Fetch VpaManager instance
 	vpaManager := &autoscalingv1.VpaManager{}
 	if err := r.Get(ctx, req.NamespacedName, vpaManager); err != nil {
 		if errors.IsNotFound(err) {
 			log.Info("VpaManager not found, likely deleted")
+			r.Selectors.Forget(req.Name)
+			r.guarded.set(req.Name, false)
+			r.Metrics.ClearRightsizingScore(req.Name)
 			return reconcile.Result{}, nil
 		}
 		r.Metrics.RecordReconcile(req.Name, start, err)
 		return reconcile.Result{}, err
 	}
 
-	// If disabled, clean up managed VPAs and return
+	// A disabled VpaManager only reports that it is disabled; its VPAs are left as they are
 	if !vpaManager.Spec.Enabled {
 		log.Info("VpaManager is disabled, skipping reconciliation")
+		statusUpdate := vpaManager.DeepCopy()
+		setDisabledCondition(&statusUpdate.Status, vpaManager.Generation)
+		if err := r.patchStatus(ctx, vpaManager, &statusUpdate.Status); err != nil {
+			log.Error(err, "failed to patch VpaManager status")
+			r.Metrics.RecordReconcile(vpaManager.Name, start, err)
+			return reconcile.Result{}, err
+		}
 		r.Metrics.RecordReconcile(vpaManager.Name, start, nil)
 		return reconcile.Result{}, nil
 	}
 
-	// Get matching namespaces
-	matchingNamespaces, err := r.getMatchingNamespaces(ctx, vpaManager.Spec.NamespaceSelector)
+	// Without the VPA CRD there is nothing to manage; the CRD watch re-enqueues once it is installed
+	served, err := r.VPAAvailability.Served()
+	if err != nil {
+		log.Error(err, "failed to discover VerticalPodAutoscalers")
+		r.Metrics.RecordReconcile(vpaManager.Name, start, err)
+		return reconcile.Result{}, err
+	}
+	r.Metrics.SetVPAServed(served)
+	if !served {
+		log.Info("VerticalPodAutoscalers are not served, skipping reconciliation")
+		r.Metrics.RecordReconcile(vpaManager.Name, start, vpa.ErrNotServed)
+		r.recordLastError(ctx, vpaManager, vpa.ErrNotServed)
+		return reconcile.Result{RequeueAfter: r.VPAAvailability.TTL()}, nil
+	}
+
+	// Resolve the inheritFrom chain; a missing parent or a cycle needs a spec change to fix,
+	// and parent changes re-enqueue their children
+	spec, err := inheritance.ResolveSpec(ctx, r.Client, vpaManager)
 	if err != nil {
-		log.Error(err, "failed to get matching namespaces")
+		log.Error(err, "failed to resolve inheritFrom chain or profile, skipping reconciliation")
 		r.Metrics.RecordReconcile(vpaManager.Name, start, err)
+		r.recordLastError(ctx, vpaManager, err)
+		if errors.IsNotFound(err) || stderrors.Is(err, inheritance.ErrCycle) || stderrors.Is(err, inheritance.ErrInvalidProfile) {
+			return reconcile.Result{}, nil
+		}
 		return reconcile.Result{}, err
 	}
 
+	// Refuse to act on specs the VPA admission controller would reject, and re-check
+	// tenant scope in case the validating webhook was bypassed
+	errs := validation.ValidateVpaManagerSpec(spec)
+	errs = append(errs, validation.ValidateTenantScope(vpaManager)...)
+	if len(errs) > 0 {
+		err := errs.ToAggregate()
+		log.Error(err, "invalid VpaManager spec, skipping reconciliation")
+		r.Metrics.RecordReconcile(vpaManager.Name, start, err)
+		r.recordLastError(ctx, vpaManager, err)
+		return reconcile.Result{}, nil
+	}
+
+	spec = r.SelectorDefaults.Apply(spec)
+	// effective carries the resolved spec into VPA generation
+	effective := vpaManager.DeepCopy()
+	effective.Spec = *spec
+
+	// Workloads that other VpaManagers select too are counted as conflicts, and left to
+	// them when they have a higher priority
+	rivals, err := r.rivals(ctx, vpaManager)
+	if err != nil {
+		log.Error(err, "failed to list VpaManagers")
+		r.Metrics.RecordReconcile(vpaManager.Name, start, err)
+		r.recordLastError(ctx, vpaManager, err)
+		return reconcile.Result{}, err
+	}
+
+	// Get matching namespaces
+	var matchingNamespaces []corev1.Namespace
+	phaseStart := time.Now()
+	if r.selectsNamespaces(spec) {
+		if matchingNamespaces, err = r.getMatchingNamespaces(ctx, vpaManager, spec); err != nil {
+			log.Error(err, "failed to get matching namespaces")
+			r.Metrics.RecordReconcile(vpaManager.Name, start, err)
+			r.recordLastError(ctx, vpaManager, err)
+			return reconcile.Result{}, err
+		}
+	} else {
+		log.Info("no namespaceSelector or namespaces and matchAllNamespaces is not set, no namespaces selected")
+	}
+	r.Metrics.ObserveReconcilePhase(vpaManager.Name, metrics.PhaseListNamespaces, time.Since(phaseStart))
+
 	// Track counts by workload type (memory-efficient)
 	counts := map[string]int{}
 	totalManaged := 0
 	watchedWorkloadsCount := 0
+	driftedVPAs := 0
+	dormantVPAs := 0
+	foreignVPAs := 0
+	var foreignExample string
+	conflictingWorkloads := 0
+	var conflictExample string
+	overCapacity := 0
+	terminatingVPAs := 0
+	var rejections []autoscalingv1.VPARejection
+	var skipped []autoscalingv1.SkippedWorkload
+	forbidden := map[string]bool{}
+	// terminating namespaces are left to the namespace controller, VPAs included
+	terminating := map[string]bool{}
+	// paused namespaces keep their VPAs as they are
+	paused := map[string]bool{}
+	score := newRightsizingScore()
+	var deviations *deviationTracker
+	if r.Summary != nil {
+		deviations = newDeviationTracker(summaryTopDeviations)
+	}
+	// lastErr is the most recent failure that did not stop the cycle
+	var lastErr error
+	selfExcluded := false
+	handedOver := newHandovers(r.MaxHandovers)
+	// ensureTime is the part of the namespace loop spent ensuring VPAs rather than listing workloads
+	var ensureTime time.Duration
 
 	// Track VPA names for orphan cleanup
 	managedVPAKeys := make(map[string]bool)
+	// workloadRefs backs the status lists when RecordWorkloadLists is set
+	var workloadRefs []autoscalingv1.WorkloadReference
+
+	// Workload selectors are compiled once for all namespaces; kinds without one are not managed
+	workloadSelectors := map[string]labelselector.Any{}
+	for _, wc := range r.WorkloadConfigs {
+		selectors, ok := workloadSelectorTerms(spec, wc.Selectors(spec))
+		if !ok || !r.kinds.Enabled(wc.Provider.Kind()) {
+			continue
+		}
+		compiled, err := r.Selectors.CompileAny(vpaManager.Name, vpaManager.Generation, wc.Provider.Kind(), selectors)
+		if err != nil {
+			lastErr = fmt.Errorf("invalid %s selector: %w", strings.ToLower(wc.Provider.Kind()), err)
+			continue
+		}
+		workloadSelectors[wc.Provider.Kind()] = compiled
+	}
+	names, err := workloadNames(spec)
+	if err != nil {
+		lastErr = fmt.Errorf("invalid workloadNamePatterns: %w", err)
+		workloadSelectors = map[string]labelselector.Any{}
+	}
+
+	// guarded reports whether a selected workload can be blocked by the topology guard
+	guarded := false
+	// ensureWorkload ensures the VPA of one selected workload
+	ensureWorkload := func(ns *corev1.Namespace, wl workload.Workload) {
+		defer func(start time.Time) { ensureTime += time.Since(start) }(time.Now())
+		if r.Self.Matches(wl) {
+			log.V(1).Info("skipping the operator's own workload", "kind", wl.GetKind(), "name", wl.GetName(), "namespace", wl.GetNamespace())
+			r.recordSkip(vpaManager, &skipped, wl, "", "OperatorWorkload")
+			selfExcluded = true
+			return
+		}
+		if r.Bootstrapped.Matches(wl) {
+			log.V(1).Info("skipping VPA component with a bootstrap VPA", "kind", wl.GetKind(), "name", wl.GetName(), "namespace", wl.GetNamespace())
+			r.recordSkip(vpaManager, &skipped, wl, "", "BootstrapVPA")
+			return
+		}
+		if workload.Excluded(wl.GetObject()) {
+			log.V(1).Info("skipping workload excluded by annotation", "kind", wl.GetKind(), "name", wl.GetName(), "namespace", wl.GetNamespace())
+			r.recordSkip(vpaManager, &skipped, wl, "", "ExcludedByAnnotation")
+			return
+		}
+		if !names.Matches(wl.GetName()) {
+			log.V(1).Info("skipping workload not matching workloadNamePatterns", "kind", wl.GetKind(), "name", wl.GetName(), "namespace", wl.GetNamespace())
+			return
+		}
+		watchedWorkloadsCount++
+		if r.TopologyGuard && topologyGuardable(wl) {
+			guarded = true
+		}
+		vpaName, err := vpa.RenderName(spec.VPANameTemplate, vpa.NameTemplateData{Kind: wl.GetKind(), Name: wl.GetName(), Namespace: wl.GetNamespace()})
+		if err != nil {
+			log.Error(err, "failed to name VPA", "kind", wl.GetKind(), "name", wl.GetName(), "namespace", wl.GetNamespace())
+			lastErr = fmt.Errorf("%s %s/%s: vpaNameTemplate: %w", strings.ToLower(wl.GetKind()), wl.GetNamespace(), wl.GetName(), err)
+			r.recordDecision(vpaManager, wl, "", decisions.ActionFailed, truncate(err.Error(), maxRejectionMessageLength))
+			return
+		}
+		if rival := r.overlap(ctx, rivals, wl.GetKind(), ns, wl.GetObject()); rival != nil {
+			if conflictingWorkloads == 0 {
+				conflictExample = fmt.Sprintf("%s %s/%s with VpaManager %s", strings.ToLower(wl.GetKind()), wl.GetNamespace(), wl.GetName(), rival.Name)
+			}
+			conflictingWorkloads++
+			if rival.Outranks(vpaManager) {
+				log.V(1).Info("workload is managed by a VpaManager with a higher priority, skipping", "kind", wl.GetKind(), "name", wl.GetName(),
+					"namespace", wl.GetNamespace(), "winner", rival.Name)
+				// A VPA this VpaManager generated before is an orphan the winner takes over
+				r.recordSkip(vpaManager, &skipped, wl, vpaName, "OutrankedByVpaManager")
+				return
+			}
+		}
+		// Webhooks write the same VPAs, so a write that lost the race is retried from a fresh read
+		var vpaObj *unstructured.Unstructured
+		var action vpaAction
+		err = r.retryOnConflict(vpaManager.Name, metrics.ConflictObjectVPA, func() error {
+			var err error
+			vpaObj, action, err = r.ensureVPAForWorkload(ctx, effective, wl, vpaName, handedOver)
+			return err
+		})
+		if err != nil {
+			log.Error(err, "failed to ensure VPA", "kind", wl.GetKind(), "name", wl.GetName(), "namespace", wl.GetNamespace())
+			lastErr = fmt.Errorf("%s %s/%s: %w", strings.ToLower(wl.GetKind()), wl.GetNamespace(), wl.GetName(), err)
+			r.recordDecision(vpaManager, wl, vpaName, decisions.ActionFailed, truncate(err.Error(), maxRejectionMessageLength))
+			if vpa.IsAdmissionRejection(err) {
+				r.Metrics.RecordVPAOperationError(action.operation(), vpaManager.Name, err)
+				r.recordRejection(wl, vpaName, err)
+				if len(rejections) < autoscalingv1.MaxRejectedVPAs {
+					rejections = append(rejections, autoscalingv1.VPARejection{
+						Kind:      wl.GetKind(),
+						Name:      wl.GetName(),
+						Namespace: wl.GetNamespace(),
+						Message:   truncate(err.Error(), maxRejectionMessageLength),
+						Time:      metav1.Now(),
+					})
+				}
+			}
+			return // continue despite error
+		}
+		scope := r.Metrics.For(vpaManager.Name).WithKind(wl.GetKind()).WithNamespace(wl.GetNamespace())
+		switch action {
+		case vpaCreated:
+			if r.deletedVPAs.take(types.NamespacedName{Namespace: wl.GetNamespace(), Name: vpaName}, time.Now()) {
+				log.Info("recreated deleted VPA", "vpa", vpaName, "namespace", wl.GetNamespace())
+				scope.RecordVPAOperation("repair")
+				r.recordDecision(vpaManager, wl, vpaName, decisions.ActionCreated, "Repaired")
+				break
+			}
+			scope.RecordVPAOperation("create")
+			r.recordDecision(vpaManager, wl, vpaName, decisions.ActionCreated, "Selected")
+		case vpaUpdated:
+			r.recordDecision(vpaManager, wl, vpaName, decisions.ActionUpdated, "SpecChanged")
+		case vpaHandedOver:
+			scope.RecordVPAOperation("handover")
+			r.recordDecision(vpaManager, wl, vpaName, decisions.ActionUpdated, "HandedOver")
+		case vpaHandoverDeferred:
+			log.V(1).Info("handover limit reached, leaving VPA to its previous VpaManager for now", "vpa", vpaName, "namespace", wl.GetNamespace())
+			r.recordDecision(vpaManager, wl, vpaName, decisions.ActionHeldBack, "HandoverLimitReached")
+			return
+		case vpaReset:
+			log.Info("workload was recreated under the same name, reset its VPA", "vpa", vpaName, "namespace", wl.GetNamespace(), "uid", wl.GetUID())
+			scope.RecordVPAOperation("reset")
+			r.recordDecision(vpaManager, wl, vpaName, decisions.ActionReset, "WorkloadRecreated")
+		case vpaDrifted:
+			log.Info("VPA is managed by GitOps and has drifted, skipping update", "vpa", vpaName, "namespace", wl.GetNamespace())
+			r.recordDecision(vpaManager, wl, vpaName, decisions.ActionSkipped, "GitOpsDrift")
+			driftedVPAs++
+		case vpaForeign:
+			r.recordSkip(vpaManager, &skipped, wl, vpaName, "ForeignInstance")
+			log.Info("VPA was generated by another operator instance, skipping", "vpa", vpaName, "namespace", wl.GetNamespace(),
+				"instance", vpaObj.GetLabels()[vpa.InstanceLabel])
+			if foreignVPAs == 0 {
+				foreignExample = fmt.Sprintf("%s/%s", wl.GetNamespace(), vpaName)
+			}
+			foreignVPAs++
+			return
+		case vpaOverCapacity:
+			log.V(1).Info("cluster VPA capacity reached, not creating VPA", "vpa", vpaName, "namespace", wl.GetNamespace())
+			r.recordSkip(vpaManager, &skipped, wl, vpaName, "ClusterCapacityReached")
+			overCapacity++
+			return
+		case vpaTerminating:
+			log.V(1).Info("VPA is being deleted, recreating it once it is gone", "vpa", vpaName, "namespace", wl.GetNamespace())
+			r.recordDecision(vpaManager, wl, vpaName, decisions.ActionHeldBack, "VPATerminating")
+			// Not an orphan, it is on its way out already
+			managedVPAKeys[fmt.Sprintf("%s/%s", wl.GetNamespace(), vpaName)] = true
+			terminatingVPAs++
+			return
+		}
+		if r.AnnotateWorkloads {
+			vpaSpec, _ := vpaObj.Object["spec"].(map[string]interface{})
+			if err := r.linkWorkload(ctx, wl.GetObject(), vpa.WorkloadVPAValue(vpaName, vpa.UpdateMode(vpaSpec))); err != nil {
+				log.Error(err, "failed to annotate workload with its VPA", "kind", wl.GetKind(), "name", wl.GetName(), "namespace", wl.GetNamespace())
+			}
+		}
+		if _, dormant := vpaObj.GetAnnotations()[vpa.DormantAnnotation]; dormant {
+			dormantVPAs++
+		}
+		score.add(wl.GetPodTemplateSpec(), vpaObj)
+		deviations.add(wl, vpaObj)
+		counts[wl.GetKind()]++
+		totalManaged++
+		managedVPAKeys[fmt.Sprintf("%s/%s", wl.GetNamespace(), vpaName)] = true
+		if r.RecordWorkloadLists {
+			workloadRefs = append(workloadRefs, autoscalingv1.WorkloadReference{
+				Kind:      wl.GetKind(),
+				Name:      wl.GetName(),
+				Namespace: wl.GetNamespace(),
+				UID:       string(wl.GetUID()),
+				VpaName:   vpaName,
+			})
+		}
+	}
+
+	// A pass over the namespaces stops once it exceeds ReconcileBudget or has processed
+	// MaxNamespacesPerCycle namespaces; the next chunk continues from status.progress after
+	// a requeue
+	ordered := shardOrder(matchingNamespaces)
+	done := resumableProgress(vpaManager)
+	first := 0
+	if done != nil {
+		first = resumeIndex(ordered, done.Continue)
+	}
+	var next *corev1.Namespace
+	processed := map[string]bool{}
+
+	// For each matching namespace, highest priority first, process all workload types with streaming
+	phaseStart = time.Now()
+	for i := first; i < len(ordered); i++ {
+		ns := ordered[i]
+		if r.chunkFull(i-first, start) {
+			next = &ordered[i]
+			break
+		}
+		processed[ns.Name] = true
+		if !validation.NamespaceInTenant(vpaManager, &ns) {
+			log.Info("namespace is outside the VpaManager tenant, skipping", "namespace", ns.Name)
+			continue
+		}
+		if ns.DeletionTimestamp != nil || ns.Status.Phase == corev1.NamespaceTerminating {
+			log.V(1).Info("namespace is being deleted, skipping", "namespace", ns.Name)
+			terminating[ns.Name] = true
+			continue
+		}
+		if workload.NamespacePaused(&ns) {
+			log.V(1).Info("namespace is paused, leaving its VPAs untouched", "namespace", ns.Name)
+			paused[ns.Name] = true
+			continue
+		}
+
+		// Workloads with a priority annotation get their VPAs before the rest of the namespace
+		for _, wl := range r.prioritizedWorkloads(ctx, workloadSelectors, ns.Name) {
+			ensureWorkload(&ns, wl)
+		}
 
-	// For each matching namespace, process all workload types with streaming
-	for _, ns := range matchingNamespaces {
 		for _, wc := range r.WorkloadConfigs {
-			selector := wc.Selector(&vpaManager.Spec)
-			if selector == nil {
+			selector, ok := workloadSelectors[wc.Provider.Kind()]
+			if !ok {
 				continue
 			}
 
-			err := wc.Provider.ForEach(ctx, r.Client, ns.Name, selector, func(wl workload.Workload) (bool, error) {
-				watchedWorkloadsCount++
-				vpaName := fmt.Sprintf("%s-vpa", wl.GetName())
-				created, err := r.ensureVPAForWorkload(ctx, vpaManager, wl.GetKind(), wl.GetName(), wl.GetNamespace(), wl.GetUID(), vpaName)
-				if err != nil {
-					log.Error(err, "failed to ensure VPA", "kind", wl.GetKind(), "name", wl.GetName(), "namespace", wl.GetNamespace())
-					return true, nil // continue despite error
-				}
-				if created {
-					r.Metrics.RecordVPAOperation("create", vpaManager.Name)
+			err := workload.ForEachMatchingAny(ctx, r.Client, wc.Provider, ns.Name, selector, func(wl workload.Workload) (bool, error) {
+				if workloadPriority(wl.GetObject()) == 0 {
+					ensureWorkload(&ns, wl)
 				}
-				counts[wl.GetKind()]++
-				totalManaged++
-				managedVPAKeys[fmt.Sprintf("%s/%s", wl.GetNamespace(), vpaName)] = true
 				return true, nil
 			})
-			if err != nil {
+			if errors.IsForbidden(err) {
+				// Partial RBAC is expected in multi-tenant clusters, keep going with other namespaces
+				log.Info("forbidden to list workloads, skipping namespace", "kind", wc.Provider.Kind(), "namespace", ns.Name)
+				forbidden[ns.Name] = true
+			} else if err != nil {
 				log.Error(err, "failed to iterate workloads", "kind", wc.Provider.Kind(), "namespace", ns.Name)
+				lastErr = fmt.Errorf("listing %ss in namespace %s: %w", strings.ToLower(wc.Provider.Kind()), ns.Name, err)
 			}
 		}
 	}
 
-	// Clean up orphaned VPAs
-	orphansDeleted, err := r.cleanupOrphanedVPAsWithKeys(ctx, vpaManager, managedVPAKeys)
+	r.Metrics.ObserveReconcilePhase(vpaManager.Name, metrics.PhaseListWorkloads, time.Since(phaseStart)-ensureTime)
+	r.Metrics.ObserveReconcilePhase(vpaManager.Name, metrics.PhaseEnsureVPAs, ensureTime)
+
+	// Clean up orphaned VPAs, holding back bursts caused by mass label changes
+	now := metav1.Now()
+	var burst burstDecision
+	var passOrphans, heldBack int
+	scanStart := time.Now()
+	skipOrphans := maps.Clone(forbidden)
+	maps.Copy(skipOrphans, terminating)
+	maps.Copy(skipOrphans, paused)
+	// The VPAs of namespaces in other chunks are cleaned up with their chunk
+	if first > 0 || next != nil {
+		for i := range ordered {
+			if !processed[ordered[i].Name] {
+				skipOrphans[ordered[i].Name] = true
+			}
+		}
+	}
+	orphans, err := r.findOrphanedVPAs(ctx, vpaManager, managedVPAKeys, skipOrphans)
+	r.Metrics.ObserveOrphanScan(vpaManager.Name, scanStart)
 	if err != nil {
-		log.Error(err, "failed to cleanup orphaned VPAs")
+		log.Error(err, "failed to list orphaned VPAs")
+		lastErr = fmt.Errorf("listing orphaned VPAs: %w", err)
+	} else {
+		// VPAs another VpaManager now manages are taken over by it rather than deleted
+		if orphans, err = r.withoutHandovers(ctx, orphans); err != nil {
+			log.Error(err, "failed to check orphaned VPAs for a handover")
+			lastErr = fmt.Errorf("checking orphaned VPAs for a handover: %w", err)
+		}
+		// A split pass checks the orphans of all its chunks so far, so a mass label change
+		// spread over many chunks is held back like one in a single reconcile
+		passOrphans = len(orphans)
+		if done != nil {
+			passOrphans += done.OrphanedVPAs
+		}
+		burst = r.OrphanBurstGuard.check(vpaManager, passOrphans, done != nil || next != nil, now)
+		if burst.allowed {
+			orphansDeleted, err := r.deleteVPAs(ctx, orphans)
+			if err != nil {
+				log.Error(err, "failed to cleanup orphaned VPAs")
+				lastErr = fmt.Errorf("deleting orphaned VPAs: %w", err)
+			}
+			for i := 0; i < orphansDeleted; i++ {
+				r.Metrics.RecordVPAOperation("delete", vpaManager.Name)
+			}
+			r.Metrics.RecordOrphanVPAsDeleted(vpaManager.Name, orphansDeleted)
+			// The confirmation stays until a pass completes without holding back deletions
+			pendingBefore := 0
+			if done != nil {
+				pendingBefore = done.PendingOrphanDeletions
+			}
+			if burst.confirmed && err == nil && next == nil && pendingBefore == 0 {
+				if err := r.clearDeletionConfirmation(ctx, vpaManager); err != nil {
+					log.Error(err, "failed to remove orphan deletion confirmation")
+				}
+			}
+		} else {
+			log.Info("holding back orphan VPA deletions", "pending", len(orphans), "passOrphans", passOrphans,
+				"limit", r.OrphanBurstGuard.MaxDeletions, "blockedSince", burst.blockedSince.Time)
+			heldBack = len(orphans)
+			r.recordBlockedDeletions(vpaManager, passOrphans)
+			for i := range orphans {
+				r.recordOrphanDecision(&orphans[i], decisions.ActionHeldBack, "OrphanBurstProtection")
+			}
+		}
+	}
+
+	r.Metrics.ObserveReconcilePhase(vpaManager.Name, metrics.PhaseCleanup, time.Since(scanStart))
+
+	// Fold the chunks processed before into this one; an unfinished pass only records its progress
+	chunk := &autoscalingv1.ReconcileProgress{
+		StartTime:              metav1.NewTime(start),
+		Chunks:                 1,
+		ManagedVPAs:            totalManaged,
+		DeploymentCount:        counts["Deployment"],
+		StatefulSetCount:       counts["StatefulSet"],
+		DaemonSetCount:         counts["DaemonSet"],
+		WatchedWorkloads:       watchedWorkloadsCount,
+		DriftedVPAs:            driftedVPAs,
+		DormantVPAs:            dormantVPAs,
+		ForeignVPAs:            foreignVPAs,
+		ConflictingWorkloads:   conflictingWorkloads,
+		OrphanedVPAs:           len(orphans),
+		PendingOrphanDeletions: heldBack,
+		ForbiddenNamespaces:    namespaceList(forbidden, autoscalingv1.MaxForbiddenNamespaces),
+		PausedNamespaces:       namespaceList(paused, autoscalingv1.MaxPausedNamespaces),
+	}
+	addProgress(chunk, done)
+	r.recordHandovers(handedOver)
+	if guarded || (done == nil && next == nil) {
+		r.guarded.set(vpaManager.Name, guarded)
+	}
+	if next != nil {
+		return r.continuePass(ctx, log, vpaManager, chunk, next, burst, lastErr, start)
 	}
-	for i := 0; i < orphansDeleted; i++ {
-		r.Metrics.RecordVPAOperation("delete", vpaManager.Name)
+	if done != nil {
+		log.Info("split reconciliation complete", "chunks", chunk.Chunks, "duration", time.Since(chunk.StartTime.Time))
+		totalManaged = chunk.ManagedVPAs
+		counts["Deployment"] = chunk.DeploymentCount
+		counts["StatefulSet"] = chunk.StatefulSetCount
+		counts["DaemonSet"] = chunk.DaemonSetCount
+		watchedWorkloadsCount = chunk.WatchedWorkloads
+		driftedVPAs = chunk.DriftedVPAs
+		dormantVPAs = chunk.DormantVPAs
+		foreignVPAs = chunk.ForeignVPAs
+		conflictingWorkloads = chunk.ConflictingWorkloads
+		for _, ns := range chunk.ForbiddenNamespaces {
+			forbidden[ns] = true
+		}
+		for _, ns := range chunk.PausedNamespaces {
+			paused[ns] = true
+		}
 	}
 
 	// Update status using Patch to avoid conflicts with stale resourceVersion
-	now := metav1.Now()
 	statusUpdate := vpaManager.DeepCopy()
+	statusUpdate.Status.Progress = nil
 	statusUpdate.Status.ManagedVPAs = totalManaged
 	statusUpdate.Status.DeploymentCount = counts["Deployment"]
 	statusUpdate.Status.StatefulSetCount = counts["StatefulSet"]
 	statusUpdate.Status.DaemonSetCount = counts["DaemonSet"]
-	// Clear deprecated fields to reduce status size
-	statusUpdate.Status.ManagedDeployments = nil
-	statusUpdate.Status.ManagedWorkloads = nil
+	statusUpdate.Status.DriftedVPAs = driftedVPAs
+	statusUpdate.Status.DormantVPAs = dormantVPAs
+	statusUpdate.Status.ForeignVPAs = foreignVPAs
+	setForeignInstanceCondition(&statusUpdate.Status, vpaManager.Generation, foreignVPAs, foreignExample)
+	statusUpdate.Status.ConflictingWorkloads = conflictingWorkloads
+	setConflictingCondition(&statusUpdate.Status, vpaManager.Generation, conflictingWorkloads, conflictExample)
+	r.setClusterCapacityCondition(&statusUpdate.Status, vpaManager.Generation, overCapacity)
+	r.setDegradedCondition(&statusUpdate.Status, vpaManager.Generation, now.Time)
+	statusUpdate.Status.RejectedVPAs = rejections
+	statusUpdate.Status.SkippedWorkloads = skipped
+	statusUpdate.Status.ForbiddenNamespaces = namespaceList(forbidden, autoscalingv1.MaxForbiddenNamespaces)
+	statusUpdate.Status.PausedNamespaces = namespaceList(paused, autoscalingv1.MaxPausedNamespaces)
+	statusUpdate.Status.OperatorWorkloadExcluded = ""
+	if selfExcluded {
+		statusUpdate.Status.OperatorWorkloadExcluded = r.Self.String()
+	}
+	statusUpdate.Status.PendingOrphanDeletions = 0
+	statusUpdate.Status.OrphanDeletionsBlockedSince = burst.blockedSince
+	if burst.blockedSince != nil {
+		statusUpdate.Status.PendingOrphanDeletions = chunk.PendingOrphanDeletions
+	}
+	statusUpdate.Status.RightsizingScore = nil
+	if value, ok := score.value(); ok {
+		percent := int(value*100 + 0.5)
+		statusUpdate.Status.RightsizingScore = &percent
+		r.Metrics.SetRightsizingScore(vpaManager.Name, value)
+	} else {
+		r.Metrics.ClearRightsizingScore(vpaManager.Name)
+	}
+	// Per-workload lists are only kept on request, otherwise they are cleared to reduce status size
+	statusUpdate.Status.SetManagedWorkloads(workloadRefs)
+	r.warnDeprecatedStatusFields(vpaManager, &statusUpdate.Status)
 	statusUpdate.Status.LastReconcileTime = &now
+	if lastErr != nil {
+		setLastError(&statusUpdate.Status, lastErr, now)
+	}
+	setReadyCondition(&statusUpdate.Status, vpaManager.Generation, lastErr)
+	setHealthyCondition(&statusUpdate.Status, vpaManager.Generation, lastErr)
+	recordSpecHistory(&statusUpdate.Status, vpaManager, now, totalManaged, lastErr)
+	// The summary diffs VPA sets between passes, which a split pass only has for its last chunk
+	if done == nil {
+		r.Summary.observe(vpaManager.Name, managedVPAKeys, watchedWorkloadsCount, deviations.list(), lastErr)
+	}
 
-	if err := r.Status().Patch(ctx, statusUpdate, client.MergeFrom(vpaManager)); err != nil {
+	phaseStart = time.Now()
+	if err := r.patchStatus(ctx, vpaManager, &statusUpdate.Status); err != nil {
 		log.Error(err, "failed to patch VpaManager status")
 		r.Metrics.RecordReconcile(vpaManager.Name, start, err)
 		return reconcile.Result{}, err
 	}
+	r.Metrics.ObserveReconcilePhase(vpaManager.Name, metrics.PhaseStatus, time.Since(phaseStart))
 
 	// Update metrics
 	r.Metrics.UpdateManagedResources(vpaManager.Name, totalManaged, watchedWorkloadsCount)
+	r.Metrics.SetForbiddenNamespaces(vpaManager.Name, len(forbidden))
+	r.Metrics.SetPausedNamespaces(vpaManager.Name, len(paused))
+	r.Metrics.SetForeignVPAs(vpaManager.Name, foreignVPAs)
+	r.Metrics.SetConflictingWorkloads(vpaManager.Name, conflictingWorkloads)
+	r.Metrics.SetPendingOrphanDeletions(vpaManager.Name, statusUpdate.Status.PendingOrphanDeletions)
+	r.Metrics.SetClusterCapacity(r.ClusterCapacity.Usage())
 	r.Metrics.RecordReconcile(vpaManager.Name, start, nil)
 
+	if overCapacity > 0 {
+		_, limit := r.ClusterCapacity.Usage()
+		log.Info("cluster VPA capacity reached, workloads left without a VPA", "workloads", overCapacity, "limit", limit)
+	}
 	log.Info("reconciliation complete", "managedVPAs", totalManaged, "watchedWorkloads", watchedWorkloadsCount)
-	return reconcile.Result{RequeueAfter: 5 * time.Minute}, nil
+	if terminatingVPAs > 0 {
+		return reconcile.Result{RequeueAfter: terminatingVPARequeueDelay}, nil
+	}
+	if handedOver.pending() {
+		return reconcile.Result{RequeueAfter: handoverRequeueDelay}, nil
+	}
+	return reconcile.Result{RequeueAfter: r.resyncPeriod()}, nil
 }
 
-// getMatchingNamespaces returns namespaces that match the selector
-func (r *VpaManagerReconciler) getMatchingNamespaces(ctx context.Context, selector *metav1.LabelSelector) ([]corev1.Namespace, error) {
+// setForeignInstanceCondition reports whether VPAs of another operator instance target
+// selected workloads, naming one of them as an example
+func setForeignInstanceCondition(status *autoscalingv1.VpaManagerStatus, generation int64, foreign int, example string) {
+	condition := metav1.Condition{
+		Type:               autoscalingv1.ConditionForeignInstanceConflict,
+		Status:             metav1.ConditionFalse,
+		ObservedGeneration: generation,
+		Reason:             "NoForeignVPAs",
+		Message:            "No selected workload has a VPA of another operator instance",
+	}
+	if foreign > 0 {
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = "ForeignVPAsFound"
+		condition.Message = fmt.Sprintf("%d selected workloads have a VPA generated by another operator instance, "+
+			"which is left untouched (e.g. %s). Check for overlapping VpaManagers across operator instances.", foreign, example)
+	}
+	meta.SetStatusCondition(&status.Conditions, condition)
+}
+
+// setClusterCapacityCondition reports whether selected workloads were left without a VPA by the
+// cluster-wide cap; the condition is removed when there is no cap
+func (r *VpaManagerReconciler) setClusterCapacityCondition(status *autoscalingv1.VpaManagerStatus, generation int64, overCapacity int) {
+	if r.ClusterCapacity == nil {
+		meta.RemoveStatusCondition(&status.Conditions, autoscalingv1.ConditionClusterCapacityReached)
+		return
+	}
+	_, limit := r.ClusterCapacity.Usage()
+	condition := metav1.Condition{
+		Type:               autoscalingv1.ConditionClusterCapacityReached,
+		Status:             metav1.ConditionFalse,
+		ObservedGeneration: generation,
+		Reason:             "WithinCapacity",
+		Message:            fmt.Sprintf("The operator manages fewer than %d VPAs", limit),
+	}
+	if overCapacity > 0 {
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = autoscalingv1.ConditionClusterCapacityReached
+		condition.Message = fmt.Sprintf("%d selected workloads have no VPA because the operator already manages its maximum of %d VPAs. "+
+			"Narrow over-broad selectors or raise --max-managed-vpas.", overCapacity, limit)
+	}
+	meta.SetStatusCondition(&status.Conditions, condition)
+}
+
+// setReadyCondition reports whether the last reconciliation brought the VPAs in line with the
+// spec, along with whether the VPA CRD was missing; conflicts and the cluster-wide VPA cap are
+// left to Healthy. A successful one observes generation.
+func setReadyCondition(status *autoscalingv1.VpaManagerStatus, generation int64, err error) {
+	missing := metav1.Condition{
+		Type:               autoscalingv1.ConditionVPACRDMissing,
+		Status:             metav1.ConditionFalse,
+		ObservedGeneration: generation,
+		Reason:             "VPAServed",
+		Message:            "VerticalPodAutoscalers are served",
+	}
+	condition := metav1.Condition{
+		Type:               autoscalingv1.ConditionReady,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: generation,
+		Reason:             "Reconciled",
+		Message:            "The selected workloads' VPAs match the spec",
+	}
+	switch {
+	case stderrors.Is(err, vpa.ErrNotServed):
+		missing.Status = metav1.ConditionTrue
+		missing.Reason = "VPANotServed"
+		missing.Message = err.Error()
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = autoscalingv1.ConditionVPACRDMissing
+		condition.Message = err.Error()
+	case err != nil:
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "ReconcileFailed"
+		condition.Message = status.LastError
+	default:
+		status.ObservedGeneration = generation
+	}
+	meta.SetStatusCondition(&status.Conditions, missing)
+	meta.SetStatusCondition(&status.Conditions, condition)
+}
+
+// setDisabledCondition marks a disabled VpaManager as neither Ready nor Healthy and its
+// generation as observed, since there is nothing to reconcile for it
+func setDisabledCondition(status *autoscalingv1.VpaManagerStatus, generation int64) {
+	status.ObservedGeneration = generation
+	for _, conditionType := range []string{autoscalingv1.ConditionReady, autoscalingv1.ConditionHealthy} {
+		meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+			Type:               conditionType,
+			Status:             metav1.ConditionFalse,
+			ObservedGeneration: generation,
+			Reason:             "Disabled",
+			Message:            "The VpaManager is disabled",
+		})
+	}
+}
+
+// setHealthyCondition summarizes the last reconciliation: unhealthy when it failed, even in
+// part, when another operator instance's VPAs are in the way, or when the cluster-wide VPA
+// cap left workloads without a VPA
+func setHealthyCondition(status *autoscalingv1.VpaManagerStatus, generation int64, err error) {
+	condition := metav1.Condition{
+		Type:               autoscalingv1.ConditionHealthy,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: generation,
+		Reason:             "Reconciled",
+		Message:            "The last reconciliation succeeded",
+	}
+	switch {
+	case err != nil:
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "ReconcileFailed"
+		condition.Message = status.LastError
+	case meta.IsStatusConditionTrue(status.Conditions, autoscalingv1.ConditionForeignInstanceConflict):
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = autoscalingv1.ConditionForeignInstanceConflict
+		condition.Message = meta.FindStatusCondition(status.Conditions, autoscalingv1.ConditionForeignInstanceConflict).Message
+	case meta.IsStatusConditionTrue(status.Conditions, autoscalingv1.ConditionClusterCapacityReached):
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = autoscalingv1.ConditionClusterCapacityReached
+		condition.Message = meta.FindStatusCondition(status.Conditions, autoscalingv1.ConditionClusterCapacityReached).Message
+	}
+	meta.SetStatusCondition(&status.Conditions, condition)
+}
+
+// resyncPeriod returns ResyncPeriod, or DefaultResyncPeriod when it is not set
+func (r *VpaManagerReconciler) resyncPeriod() time.Duration {
+	if r.ResyncPeriod > 0 {
+		return r.ResyncPeriod
+	}
+	return DefaultResyncPeriod
+}
+
+// getMatchingNamespaces returns the namespaces spec selects by name or namespace selector,
+// without the excluded ones
+func (r *VpaManagerReconciler) getMatchingNamespaces(ctx context.Context, vpaManager *autoscalingv1.VpaManager, spec *autoscalingv1.VpaManagerSpec) ([]corev1.Namespace, error) {
 	namespaceList := &corev1.NamespaceList{}
+	var opts []client.ListOption
 
-	if selector == nil {
-		// No selector means all namespaces
-		if err := r.List(ctx, namespaceList); err != nil {
+	// Only a single selector alone can narrow the list; listed namespaces and alternative
+	// selectors need every namespace
+	if selectors, ok := r.namespaceSelectors(spec); ok && len(selectors) == 1 && len(spec.Namespaces) == 0 {
+		labelSelector, err := r.Selectors.Compile(vpaManager.Name, vpaManager.Generation, namespaceSelectorField, selectors[0])
+		if err != nil {
 			return nil, err
 		}
-		return namespaceList.Items, nil
+		opts = append(opts, client.MatchingLabelsSelector{Selector: labelSelector})
 	}
 
-	labelSelector, err := metav1.LabelSelectorAsSelector(selector)
-	if err != nil {
+	if err := r.List(ctx, namespaceList, opts...); err != nil {
 		return nil, err
 	}
 
-	if err := r.List(ctx, namespaceList, client.MatchingLabelsSelector{Selector: labelSelector}); err != nil {
-		return nil, err
+	selected := namespaceList.Items[:0]
+	for i := range namespaceList.Items {
+		if r.selectsNamespace(vpaManager, spec, &namespaceList.Items[i]) {
+			selected = append(selected, namespaceList.Items[i])
+		}
 	}
-
-	return namespaceList.Items, nil
+	return selected, nil
 }
 
 // specHash computes a hash of the VPA spec for change detection
@@ -194,35 +983,136 @@ func specHash(spec map[string]interface{}) string {
 }
 
 // ensureVPAForWorkload creates or updates a VPA for a workload (Deployment or StatefulSet)
-func (r *VpaManagerReconciler) ensureVPAForWorkload(ctx context.Context, vpaManager *autoscalingv1.VpaManager, kind, name, namespace string, uid types.UID, vpaName string) (bool, error) {
-	vpa := r.buildVPAForWorkload(vpaManager, kind, name, namespace, uid, vpaName)
-	desiredSpec := vpa.Object["spec"].(map[string]interface{})
+// VPAs owned by a GitOps controller are never overwritten; drift is reported instead
+// It returns the VPA as last read from or written to the API server; on error the
+// returned action is the write that was attempted
+func (r *VpaManagerReconciler) ensureVPAForWorkload(ctx context.Context, vpaManager *autoscalingv1.VpaManager, wl workload.Workload, vpaName string, handedOver *handovers) (*unstructured.Unstructured, vpaAction, error) {
+	scope := r.Metrics.For(vpaManager.Name).WithKind(wl.GetKind()).WithNamespace(wl.GetNamespace())
+	namespace := wl.GetNamespace()
+	resolved, err := policy.Resolve(ctx, r.Client, vpaManager, wl.GetKind(), wl.GetObject(), wl.GetPodTemplateSpec())
+	if err != nil {
+		return nil, vpaUnchanged, err
+	}
+	vpaManager = resolved.VpaManager
+	vpaObj := r.buildVPAForWorkload(vpaManager, wl.GetKind(), wl.GetName(), namespace, wl.GetUID(), vpaName)
+	desiredSpec := vpaObj.Object["spec"].(map[string]interface{})
 	desiredHash := specHash(desiredSpec)
+	trace := vpa.TraceAnnotations(wl.GetObject(), vpaManager.Spec.PropagateAnnotations)
 
 	// Check if VPA already exists
 	existing := &unstructured.Unstructured{}
 	existing.SetGroupVersionKind(vpaGVK)
-	err := r.Get(ctx, types.NamespacedName{Name: vpaName, Namespace: namespace}, existing)
+	err = r.Get(ctx, types.NamespacedName{Name: vpaName, Namespace: namespace}, existing)
 
+	scaledToZero := workload.ScaledToZero(wl)
+	topologyKey, blocked, blockedErr := r.evictionBlocked(ctx, wl)
+	if blockedErr != nil {
+		return nil, vpaUnchanged, blockedErr
+	}
 	if err != nil {
 		if errors.IsNotFound(err) {
+			vpa.ApplyDormancy(vpaObj, desiredSpec, scaledToZero, vpaManager.Spec.Dormancy, time.Now())
+			vpa.ApplyEvictionGuard(vpaObj, desiredSpec, topologyKey, blocked)
+			desiredHash = specHash(desiredSpec)
+			layer, source := resolved.UpdateModeSource(vpaObj)
+			vpa.RecordUpdateModeChange(vpaObj, "", vpa.UpdateMode(desiredSpec), string(layer), source, time.Now())
+
 			// Add spec hash annotation for future change detection
-			annotations := vpa.GetAnnotations()
+			annotations := vpaObj.GetAnnotations()
 			if annotations == nil {
 				annotations = make(map[string]string)
 			}
 			annotations["vpa-operator.io/spec-hash"] = desiredHash
-			vpa.SetAnnotations(annotations)
+			vpaObj.SetAnnotations(annotations)
+			vpa.SetManagedContainerPolicies(vpaObj, vpa.ContainerPolicyNames(desiredSpec))
+			vpa.ApplyTraceAnnotations(vpaObj, trace)
+			vpa.ApplyTuningAnnotations(vpaObj, vpaManager.Spec.RecommendationTuning)
+			vpa.ApplyCustomMetadata(vpaObj, vpaManager.Spec.VPALabels, vpaManager.Spec.VPAAnnotations, r.Ownership)
+			previous := r.previousVPA(ctx, wl, vpaName)
+			if previous != "" {
+				annotations := vpaObj.GetAnnotations()
+				annotations[vpa.WarmStartedFromAnnotation] = previous
+				vpaObj.SetAnnotations(annotations)
+			}
 
-			// Create VPA
-			if err := r.Create(ctx, vpa); err != nil {
-				return false, err
+			// Create VPA within the cluster-wide cap
+			allowed, err := r.ClusterCapacity.Reserve(ctx, r.Client)
+			if err != nil {
+				return nil, vpaUnchanged, fmt.Errorf("counting managed VPAs: %w", err)
+			}
+			if !allowed {
+				return nil, vpaOverCapacity, nil
+			}
+			writeStart := time.Now()
+			vpa.StampReconcile(ctx, vpaObj, writeStart)
+			err = r.Create(ctx, vpaObj)
+			scope.ObserveVPAWrite("create", writeStart)
+			if err != nil {
+				r.ClusterCapacity.Release()
+				return nil, vpaCreated, err
+			}
+			if previous != "" {
+				r.warmStart(ctx, vpaManager, wl, previous, vpaName)
 			}
-			return true, nil
+			return vpaObj, vpaCreated, nil
 		}
-		return false, err
+		return nil, vpaUnchanged, err
 	}
 
+	// A VPA being deleted, e.g. by namespace cleanup or a foreground cascading deletion,
+	// cannot be updated; it is recreated by the reconcile after it is gone
+	if existing.GetDeletionTimestamp() != nil {
+		return existing, vpaTerminating, nil
+	}
+
+	// Never take over the VPA of another operator instance
+	if r.Ownership.Foreign(existing) {
+		return existing, vpaForeign, nil
+	}
+
+	// Never fight a GitOps controller over the spec, only report drift
+	if vpa.GitOpsOwner(existing) != "" {
+		existingSpec, _ := existing.Object["spec"].(map[string]interface{})
+		if specHash(existingSpec) != desiredHash {
+			return existing, vpaDrifted, nil
+		}
+		return existing, vpaUnchanged, nil
+	}
+
+	// A VPA another VpaManager generated is taken over when the workload changed hands, e.g.
+	// its namespace was relabeled into another tier. Updating it in place keeps its
+	// recommendation history.
+	handover := handoverKey{namespace: namespace, from: existing.GetLabels()[vpa.CreatedByLabel], to: vpaManager.Name}
+	handingOver := r.Ownership.Owns(existing) && handover.from != "" && handover.from != vpaManager.Name
+	if handingOver && handedOver.full() {
+		handedOver.deferTo(handover)
+		return existing, vpaHandoverDeferred, nil
+	}
+
+	// A VPA left behind by a deleted workload of the same name is reset: its owner reference
+	// would let the garbage collector delete it, and hand-tuned policies were for the old object
+	reset := vpa.StaleTarget(existing, wl.GetKind(), wl.GetName(), wl.GetUID())
+	strategy := vpaManager.Spec.ContainerPolicyMerge
+	if reset {
+		strategy = autoscalingv1.ContainerPolicyMergeOperatorWins
+	}
+
+	// Keep hand-tuned container policies of the existing VPA as the VpaManager asks
+	existingSpec, _ := existing.Object["spec"].(map[string]interface{})
+	previousMode := vpa.UpdateMode(existingSpec)
+	managed := vpa.ManagedContainerPolicies(existing, existingSpec)
+	desiredSpec, written := vpa.MergeContainerPolicies(strategy, desiredSpec, existingSpec, managed)
+	// Turn the VPA Off while its workload stays scaled to zero, and back once it scales up
+	dormancyChanged := vpa.ApplyDormancy(existing, desiredSpec, scaledToZero, vpaManager.Spec.Dormancy, time.Now())
+	// Turn the VPA Off while pods it evicts could not be rescheduled
+	guardChanged := vpa.ApplyEvictionGuard(existing, desiredSpec, topologyKey, blocked)
+	desiredHash = specHash(desiredSpec)
+
+	// Record which policy moved the update mode, so a flapping mode can be traced
+	desiredMode := vpa.UpdateMode(desiredSpec)
+	modeLayer, modeSource := resolved.UpdateModeSource(existing)
+	modeChanged := vpa.RecordUpdateModeChange(existing, previousMode, desiredMode, string(modeLayer), modeSource, time.Now())
+
 	// Check if update is needed using hash comparison
 	existingAnnotations := existing.GetAnnotations()
 	existingHash := ""
@@ -230,44 +1120,121 @@ func (r *VpaManagerReconciler) ensureVPAForWorkload(ctx context.Context, vpaMana
 		existingHash = existingAnnotations["vpa-operator.io/spec-hash"]
 	}
 
-	// Skip update if spec hasn't changed
-	if existingHash == desiredHash {
-		return false, nil
+	// Skip update if neither the spec nor the traceability, tuning or custom metadata changed
+	traceChanged := vpa.ApplyTraceAnnotations(existing, trace)
+	tuningChanged := vpa.ApplyTuningAnnotations(existing, vpaManager.Spec.RecommendationTuning)
+	metadataChanged := vpa.ApplyCustomMetadata(existing, vpaManager.Spec.VPALabels, vpaManager.Spec.VPAAnnotations, r.Ownership)
+	if existingHash == desiredHash && !traceChanged && !tuningChanged && !metadataChanged && !dormancyChanged && !guardChanged && !modeChanged && !reset && !handingOver {
+		return existing, vpaUnchanged, nil
 	}
 
 	// Update existing VPA
 	existing.Object["spec"] = desiredSpec
+	if reset {
+		vpa.ReplaceOwnerReferences(existing, vpaObj.GetOwnerReferences())
+	}
 	annotations := existing.GetAnnotations()
 	if annotations == nil {
 		annotations = make(map[string]string)
 	}
 	annotations["vpa-operator.io/spec-hash"] = desiredHash
 	existing.SetAnnotations(annotations)
+	vpa.SetManagedContainerPolicies(existing, written)
+	if handingOver {
+		labels := existing.GetLabels()
+		maps.Copy(labels, r.Ownership.Labels(vpaManager.Name))
+		existing.SetLabels(labels)
+	}
 
-	if err := r.Update(ctx, existing); err != nil {
-		return false, err
+	writeStart := time.Now()
+	vpa.StampReconcile(ctx, existing, writeStart)
+	err = r.Update(ctx, existing)
+	scope.ObserveVPAWrite("update", writeStart)
+	action := vpaUpdated
+	if handingOver {
+		action = vpaHandedOver
 	}
+	if reset {
+		action = vpaReset
+	}
+	if err != nil {
+		return nil, action, err
+	}
+	if handingOver {
+		handedOver.add(handover)
+	}
+	if modeChanged {
+		scope.RecordUpdateModeTransition(previousMode, desiredMode, string(modeLayer))
+	}
+
+	return existing, action, nil
+}
+
+// namespaceList returns a set of namespaces sorted and capped at limit entries for status
+func namespaceList(namespaces map[string]bool, limit int) []string {
+	if len(namespaces) == 0 {
+		return nil
+	}
+	names := make([]string, 0, len(namespaces))
+	for ns := range namespaces {
+		names = append(names, ns)
+	}
+	sort.Strings(names)
+	if len(names) > limit {
+		names = names[:limit]
+	}
+	return names
+}
+
+// recordRejection emits a Warning event on the workload whose VPA was rejected
+func (r *VpaManagerReconciler) recordRejection(wl workload.Workload, vpaName string, err error) {
+	if r.Recorder == nil {
+		return
+	}
+	r.Recorder.Eventf(wl.GetObject(), corev1.EventTypeWarning, "VPARejected",
+		"VPA %s was rejected by admission: %s", vpaName, truncate(err.Error(), maxRejectionMessageLength))
+}
+
+// recordLastError patches status.lastError for a reconcile that stopped early
+func (r *VpaManagerReconciler) recordLastError(ctx context.Context, vpaManager *autoscalingv1.VpaManager, err error) {
+	r.Summary.recordError(vpaManager.Name, err)
+	statusUpdate := vpaManager.DeepCopy()
+	setLastError(&statusUpdate.Status, err, metav1.Now())
+	setReadyCondition(&statusUpdate.Status, vpaManager.Generation, err)
+	setHealthyCondition(&statusUpdate.Status, vpaManager.Generation, err)
+	if patchErr := r.patchStatus(ctx, vpaManager, &statusUpdate.Status); patchErr != nil {
+		ctrl.LoggerFrom(ctx).Error(patchErr, "failed to record last error in VpaManager status")
+	}
+}
+
+// setLastError stores a classified, truncated summary of err in status
+func setLastError(status *autoscalingv1.VpaManagerStatus, err error, now metav1.Time) {
+	status.LastError = truncate(fmt.Sprintf("%s: %s", metrics.ClassifyError(err), err.Error()), maxLastErrorLength)
+	status.LastErrorTime = &now
+}
 
-	return false, nil
+// truncate shortens s to at most n bytes, marking the cut with an ellipsis
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n-3] + "..."
 }
 
 // buildVPAForWorkload creates a VPA unstructured object for any workload type
 func (r *VpaManagerReconciler) buildVPAForWorkload(vpaManager *autoscalingv1.VpaManager, kind, name, namespace string, uid types.UID, vpaName string) *unstructured.Unstructured {
-	vpa := &unstructured.Unstructured{}
-	vpa.SetGroupVersionKind(vpaGVK)
-	vpa.SetName(vpaName)
-	vpa.SetNamespace(namespace)
+	vpaObj := &unstructured.Unstructured{}
+	vpaObj.SetGroupVersionKind(vpaGVK)
+	vpaObj.SetName(vpaName)
+	vpaObj.SetNamespace(namespace)
 
 	// Set labels
-	vpa.SetLabels(map[string]string{
-		"app.kubernetes.io/managed-by": "vpa-operator",
-		"app.kubernetes.io/created-by": vpaManager.Name,
-	})
+	vpaObj.SetLabels(r.Ownership.Labels(vpaManager.Name))
 
 	// Set owner reference to workload for garbage collection
 	controller := true
 	blockOwnerDeletion := true
-	vpa.SetOwnerReferences([]metav1.OwnerReference{
+	vpaObj.SetOwnerReferences([]metav1.OwnerReference{
 		{
 			APIVersion:         "apps/v1",
 			Kind:               kind,
@@ -278,52 +1245,13 @@ func (r *VpaManagerReconciler) buildVPAForWorkload(vpaManager *autoscalingv1.Vpa
 		},
 	})
 
-	// Build spec
-	spec := map[string]interface{}{
-		"targetRef": map[string]interface{}{
-			"apiVersion": "apps/v1",
-			"kind":       kind,
-			"name":       name,
-		},
-		"updatePolicy": map[string]interface{}{
-			"updateMode": vpaManager.Spec.UpdateMode,
-		},
-	}
-
-	// Add resource policy if specified
-	if vpaManager.Spec.ResourcePolicy != nil && len(vpaManager.Spec.ResourcePolicy.ContainerPolicies) > 0 {
-		containerPolicies := make([]interface{}, 0, len(vpaManager.Spec.ResourcePolicy.ContainerPolicies))
-		for _, cp := range vpaManager.Spec.ResourcePolicy.ContainerPolicies {
-			policy := map[string]interface{}{
-				"containerName": cp.ContainerName,
-			}
-			if cp.MinAllowed != nil {
-				minAllowed := make(map[string]interface{})
-				for k, v := range cp.MinAllowed {
-					minAllowed[k] = v
-				}
-				policy["minAllowed"] = minAllowed
-			}
-			if cp.MaxAllowed != nil {
-				maxAllowed := make(map[string]interface{})
-				for k, v := range cp.MaxAllowed {
-					maxAllowed[k] = v
-				}
-				policy["maxAllowed"] = maxAllowed
-			}
-			containerPolicies = append(containerPolicies, policy)
-		}
-		spec["resourcePolicy"] = map[string]interface{}{
-			"containerPolicies": containerPolicies,
-		}
-	}
-
-	vpa.Object["spec"] = spec
-	return vpa
+	vpaObj.Object["spec"] = vpa.BuildSpec(vpaManager, kind, name)
+	return vpaObj
 }
 
-// cleanupOrphanedVPAsWithKeys removes VPAs for workloads that no longer match (memory-efficient version)
-func (r *VpaManagerReconciler) cleanupOrphanedVPAsWithKeys(ctx context.Context, vpaManager *autoscalingv1.VpaManager, currentVPAKeys map[string]bool) (int, error) {
+// findOrphanedVPAs lists VPAs created by this VpaManager that are not in currentVPAKeys,
+// ignoring VPAs in skipNamespaces
+func (r *VpaManagerReconciler) findOrphanedVPAs(ctx context.Context, vpaManager *autoscalingv1.VpaManager, currentVPAKeys map[string]bool, skipNamespaces map[string]bool) ([]unstructured.Unstructured, error) {
 	// List all VPAs managed by this operator with pagination
 	vpaList := &unstructured.UnstructuredList{}
 	vpaList.SetGroupVersionKind(schema.GroupVersionKind{
@@ -333,14 +1261,11 @@ func (r *VpaManagerReconciler) cleanupOrphanedVPAsWithKeys(ctx context.Context,
 	})
 
 	listOpts := []client.ListOption{
-		client.MatchingLabels{
-			"app.kubernetes.io/managed-by": "vpa-operator",
-			"app.kubernetes.io/created-by": vpaManager.Name,
-		},
+		client.MatchingLabels(r.Ownership.Labels(vpaManager.Name)),
 		client.Limit(500),
 	}
 
-	deleted := 0
+	var orphans []unstructured.Unstructured
 	var continueToken string
 
 	for {
@@ -350,16 +1275,16 @@ func (r *VpaManagerReconciler) cleanupOrphanedVPAsWithKeys(ctx context.Context,
 		}
 
 		if err := r.List(ctx, vpaList, opts...); err != nil {
-			return deleted, err
+			return nil, err
 		}
 
 		for _, vpa := range vpaList.Items {
+			if skipNamespaces[vpa.GetNamespace()] || !r.Ownership.Owns(&vpa) {
+				continue
+			}
 			key := fmt.Sprintf("%s/%s", vpa.GetNamespace(), vpa.GetName())
 			if !currentVPAKeys[key] {
-				if err := r.Delete(ctx, &vpa); err != nil && !errors.IsNotFound(err) {
-					return deleted, err
-				}
-				deleted++
+				orphans = append(orphans, vpa)
 			}
 		}
 
@@ -369,9 +1294,94 @@ func (r *VpaManagerReconciler) cleanupOrphanedVPAsWithKeys(ctx context.Context,
 		}
 	}
 
+	return orphans, nil
+}
+
+// deleteVPAs deletes the given VPAs and returns how many were deleted
+func (r *VpaManagerReconciler) deleteVPAs(ctx context.Context, vpas []unstructured.Unstructured) (int, error) {
+	deleted := 0
+	for i := range vpas {
+		writeStart := time.Now()
+		err := r.Delete(ctx, &vpas[i])
+		r.Metrics.ObserveVPAWrite("delete", vpas[i].GetLabels()[vpa.CreatedByLabel], writeStart)
+		if err != nil && !errors.IsNotFound(err) {
+			return deleted, err
+		}
+		deleted++
+		r.recordOrphanDecision(&vpas[i], decisions.ActionDeleted, "NoLongerSelected")
+		if r.AnnotateWorkloads {
+			if err := r.unlinkWorkload(ctx, &vpas[i]); err != nil {
+				ctrl.LoggerFrom(ctx).Error(err, "failed to remove the VPA annotation of a workload",
+					"vpa", vpas[i].GetName(), "namespace", vpas[i].GetNamespace())
+			}
+		}
+	}
 	return deleted, nil
 }
 
+// recordDecision records the decision made about the VPA of a selected workload
+func (r *VpaManagerReconciler) recordDecision(vpaManager *autoscalingv1.VpaManager, wl workload.Workload, vpaName, action, reason string) {
+	r.Decisions.Record(decisions.Decision{
+		Source:     decisions.SourceReconciler,
+		VpaManager: vpaManager.Name,
+		Kind:       wl.GetKind(),
+		Namespace:  wl.GetNamespace(),
+		Name:       wl.GetName(),
+		VPA:        vpaName,
+		Action:     action,
+		Reason:     reason,
+	})
+}
+
+// recordSkip records a selected workload left without a VPA of vpaManager on purpose: as a
+// decision, in the skip counter and, up to MaxSkippedWorkloads, in skipped for the status
+func (r *VpaManagerReconciler) recordSkip(vpaManager *autoscalingv1.VpaManager, skipped *[]autoscalingv1.SkippedWorkload, wl workload.Workload, vpaName, reason string) {
+	r.recordDecision(vpaManager, wl, vpaName, decisions.ActionSkipped, reason)
+	r.Metrics.RecordWorkloadSkipped(vpaManager.Name, reason)
+	if len(*skipped) < autoscalingv1.MaxSkippedWorkloads {
+		*skipped = append(*skipped, autoscalingv1.SkippedWorkload{
+			Kind:      wl.GetKind(),
+			Name:      wl.GetName(),
+			Namespace: wl.GetNamespace(),
+			Reason:    reason,
+		})
+	}
+}
+
+// recordOrphanDecision records the decision made about an orphaned VPA, whose workload is
+// only known by the VPA's target
+func (r *VpaManagerReconciler) recordOrphanDecision(vpaObj *unstructured.Unstructured, action, reason string) {
+	kind, _, _ := unstructured.NestedString(vpaObj.Object, "spec", "targetRef", "kind")
+	name, _, _ := unstructured.NestedString(vpaObj.Object, "spec", "targetRef", "name")
+	r.Decisions.Record(decisions.Decision{
+		Source:     decisions.SourceReconciler,
+		VpaManager: vpaObj.GetLabels()[vpa.CreatedByLabel],
+		Kind:       kind,
+		Namespace:  vpaObj.GetNamespace(),
+		Name:       name,
+		VPA:        vpaObj.GetName(),
+		Action:     action,
+		Reason:     reason,
+	})
+}
+
+// clearDeletionConfirmation removes the one-shot orphan deletion confirmation
+func (r *VpaManagerReconciler) clearDeletionConfirmation(ctx context.Context, vpaManager *autoscalingv1.VpaManager) error {
+	patched := vpaManager.DeepCopy()
+	delete(patched.Annotations, ConfirmOrphanDeletionAnnotation)
+	return r.Patch(ctx, patched, client.MergeFrom(vpaManager))
+}
+
+// recordBlockedDeletions emits a Warning event on a VpaManager whose orphan deletions are held back
+func (r *VpaManagerReconciler) recordBlockedDeletions(vpaManager *autoscalingv1.VpaManager, pending int) {
+	if r.Recorder == nil {
+		return
+	}
+	r.Recorder.Eventf(vpaManager, corev1.EventTypeWarning, "OrphanDeletionBlocked",
+		"%d orphaned VPAs exceed the deletion limit of %d; annotate with %s=true to delete them now",
+		pending, r.OrphanBurstGuard.MaxDeletions, ConfirmOrphanDeletionAnnotation)
+}
+
 // SetupWithManager sets up the controller with the Manager
 func (r *VpaManagerReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	r.Log = ctrl.Log.WithName("controllers").WithName("VpaManager")
@@ -383,20 +1393,87 @@ func (r *VpaManagerReconciler) SetupWithManager(mgr ctrl.Manager) error {
 
 	builder := ctrl.NewControllerManagedBy(mgr).
 		For(&autoscalingv1.VpaManager{}).
+		Watches(
+			&autoscalingv1.VpaManager{},
+			handler.EnqueueRequestsFromMapFunc(r.findInheritingVpaManagers),
+		).
 		Watches(
 			&corev1.Namespace{},
-			handler.EnqueueRequestsFromMapFunc(r.findVpaManagersForNamespace),
+			r.namespaceLabelHandler(),
+		).
+		Watches(
+			&corev1.ConfigMap{},
+			handler.EnqueueRequestsFromMapFunc(r.findVpaManagersForProfile),
+			ctrlbuilder.WithPredicates(profilePredicate()),
 		)
 
-	// Add watches for all workload types
-	for _, wc := range r.WorkloadConfigs {
+	// Overrides are optional: without the VpaOverride CRD they are simply never found
+	overrideKind := autoscalingv1.GroupVersion.WithKind("VpaOverride")
+	if _, err := mgr.GetRESTMapper().RESTMapping(overrideKind.GroupKind(), overrideKind.Version); err == nil {
 		builder = builder.Watches(
-			wc.Provider.NewObject(),
-			handler.EnqueueRequestsFromMapFunc(r.findVpaManagersForWorkload),
+			&autoscalingv1.VpaOverride{},
+			handler.EnqueueRequestsFromMapFunc(r.findVpaManagersForOverride),
 		)
+	} else {
+		r.Log.Info("VpaOverride CRD not installed, overrides are not watched", "reason", err.Error())
 	}
 
-	return builder.Complete(r)
+	// Recover as soon as the VPA CRD is installed instead of waiting for the availability TTL
+	if r.VPAAvailability != nil {
+		crd := &metav1.PartialObjectMetadata{}
+		crd.SetGroupVersionKind(crdGVK)
+		builder = builder.WatchesMetadata(
+			crd,
+			handler.EnqueueRequestsFromMapFunc(r.findVpaManagersForVPACRD),
+			ctrlbuilder.WithPredicates(vpaCRDPredicate()),
+		)
+	}
+
+	// Recreate managed VPAs as soon as they are deleted instead of at the next resync
+	if _, err := mgr.GetRESTMapper().RESTMapping(vpaGVK.GroupKind(), vpaGVK.Version); err == nil {
+		vpaMetadata := &metav1.PartialObjectMetadata{}
+		vpaMetadata.SetGroupVersionKind(vpaGVK)
+		builder = builder.WatchesMetadata(
+			vpaMetadata,
+			handler.EnqueueRequestsFromMapFunc(r.findVpaManagerForDeletedVPA),
+			ctrlbuilder.WithPredicates(managedVPADeletionPredicate(r.Ownership)),
+		)
+	} else {
+		r.Log.Info("VPA CRD not installed, deleted VPAs are recreated at the next resync", "reason", err.Error())
+	}
+
+	// Lift or impose the topology guard as soon as nodes change the topology domains,
+	// instead of at the next resync
+	if r.TopologyGuard {
+		builder = builder.Watches(
+			&corev1.Node{},
+			handler.EnqueueRequestsFromMapFunc(r.findVpaManagersForNode),
+			ctrlbuilder.WithPredicates(nodeTopologyPredicate()),
+		)
+	}
+
+	// Watch the workload kinds the API server serves; the others are picked up once served
+	builder, missing := r.setupWorkloadWatches(mgr, builder)
+	c, err := builder.Build(r)
+	if err != nil {
+		return err
+	}
+	if len(missing) == 0 || r.KindRecheckInterval <= 0 {
+		return nil
+	}
+	return mgr.Add(&kindWatcher{
+		controller:    c,
+		cache:         mgr.GetCache(),
+		mapper:        mgr.GetRESTMapper(),
+		scheme:        mgr.GetScheme(),
+		kinds:         r.kinds,
+		metrics:       r.Metrics,
+		topologyGuard: r.TopologyGuard,
+		handler:       handler.EnqueueRequestsFromMapFunc(r.findVpaManagersForWorkload),
+		interval:      r.KindRecheckInterval,
+		missing:       missing,
+		log:           r.Log.WithName("kinds"),
+	})
 }
 
 // DefaultWorkloadConfigs returns the default workload configurations
@@ -404,35 +1481,57 @@ func DefaultWorkloadConfigs() []WorkloadConfig {
 	return []WorkloadConfig{
 		{
 			Provider: &workload.DeploymentProvider{},
-			Selector: func(spec *autoscalingv1.VpaManagerSpec) *metav1.LabelSelector {
-				return spec.DeploymentSelector
+			Selectors: func(spec *autoscalingv1.VpaManagerSpec) []*metav1.LabelSelector {
+				return spec.WorkloadSelectorTerms("Deployment")
 			},
 		},
 		{
 			Provider: &workload.StatefulSetProvider{},
-			Selector: func(spec *autoscalingv1.VpaManagerSpec) *metav1.LabelSelector {
-				return spec.StatefulSetSelector
+			Selectors: func(spec *autoscalingv1.VpaManagerSpec) []*metav1.LabelSelector {
+				return spec.WorkloadSelectorTerms("StatefulSet")
 			},
 		},
 		{
 			Provider: &workload.DaemonSetProvider{},
-			Selector: func(spec *autoscalingv1.VpaManagerSpec) *metav1.LabelSelector {
-				return spec.DaemonSetSelector
+			Selectors: func(spec *autoscalingv1.VpaManagerSpec) []*metav1.LabelSelector {
+				return spec.WorkloadSelectorTerms("DaemonSet")
 			},
 		},
 	}
 }
 
-// findVpaManagersForWorkload returns reconcile requests for VpaManagers that might manage this workload
+// findVpaManagersForWorkload returns reconcile requests for the VpaManagers a workload event
+// affects: those whose selectors match the workload, and the one that created its current
+// VPA, so a workload that stopped matching has its VPA cleaned up promptly
 func (r *VpaManagerReconciler) findVpaManagersForWorkload(ctx context.Context, obj client.Object) []reconcile.Request {
 	vpaManagerList := &autoscalingv1.VpaManagerList{}
 	if err := r.List(ctx, vpaManagerList); err != nil {
 		return nil
 	}
 
+	wc, known := r.workloadConfigForObject(obj)
+	ns := &corev1.Namespace{}
+	if err := r.Get(ctx, types.NamespacedName{Name: obj.GetNamespace()}, ns); err != nil {
+		// Without the namespace labels the selectors cannot be evaluated
+		known = false
+	}
+	creator := ""
+	if known {
+		creator = r.vpaCreator(ctx, obj, wc.Provider.Kind(), vpaManagerList.Items)
+	}
+
 	requests := []reconcile.Request{}
-	for _, vm := range vpaManagerList.Items {
-		if vm.Spec.Enabled {
+	for i := range vpaManagerList.Items {
+		vm := &vpaManagerList.Items[i]
+		if !vm.Spec.Enabled {
+			continue
+		}
+		affected := !known || vm.Name == creator
+		if !affected {
+			_, reason := r.matchWorkload(ctx, vm, wc, ns, obj)
+			affected = reason == ""
+		}
+		if affected {
 			requests = append(requests, reconcile.Request{
 				NamespacedName: types.NamespacedName{Name: vm.Name},
 			})
@@ -441,6 +1540,99 @@ func (r *VpaManagerReconciler) findVpaManagersForWorkload(ctx context.Context, o
 	return requests
 }
 
+// matchWorkload resolves the effective spec of vm and checks it against a workload of
+// wc's kind, obj, in ns, applying the same rules as Reconcile. The reason
+// is empty when vm manages the workload and explains the mismatch otherwise.
+func (r *VpaManagerReconciler) matchWorkload(ctx context.Context, vm *autoscalingv1.VpaManager, wc WorkloadConfig, ns *corev1.Namespace, obj client.Object) (*autoscalingv1.VpaManagerSpec, string) {
+	if !vm.Spec.Enabled {
+		return nil, "VpaManager is disabled"
+	}
+	spec, err := inheritance.ResolveSpec(ctx, r.Client, vm)
+	if err != nil {
+		return nil, fmt.Sprintf("inheritFrom chain or profile cannot be resolved: %v", err)
+	}
+	errs := validation.ValidateVpaManagerSpec(spec)
+	errs = append(errs, validation.ValidateTenantScope(vm)...)
+	if len(errs) > 0 {
+		return nil, fmt.Sprintf("invalid spec: %v", errs.ToAggregate())
+	}
+	spec = r.SelectorDefaults.Apply(spec)
+
+	if !r.selectsNamespaces(spec) {
+		return nil, "no namespaceSelector or namespaces and matchAllNamespaces is not set"
+	}
+	if spec.ExcludesNamespace(ns.Name) {
+		return nil, fmt.Sprintf("namespace %s is excluded", ns.Name)
+	}
+	if r.excludedByLabels(vm, spec, ns) {
+		return nil, fmt.Sprintf("namespace %s is excluded by the namespaceExcludeSelector", ns.Name)
+	}
+	if !r.selectsNamespace(vm, spec, ns) {
+		return nil, fmt.Sprintf("namespace %s is not selected", ns.Name)
+	}
+	if !validation.NamespaceInTenant(vm, ns) {
+		return nil, fmt.Sprintf("namespace %s is outside the VpaManager tenant", ns.Name)
+	}
+
+	selectors, ok := workloadSelectorTerms(spec, wc.Selectors(spec))
+	if !ok {
+		return nil, fmt.Sprintf("%ss are not selected", wc.Provider.Kind())
+	}
+	labelSelector, err := r.Selectors.CompileAny(vm.Name, vm.Generation, wc.Provider.Kind(), selectors)
+	if err != nil {
+		return nil, fmt.Sprintf("invalid %s selector: %v", wc.Provider.Kind(), err)
+	}
+	if !labelSelector.Matches(labels.Set(obj.GetLabels())) {
+		return nil, fmt.Sprintf("workload labels do not match the %s selector", wc.Provider.Kind())
+	}
+	names, err := workloadNames(spec)
+	if err != nil {
+		return nil, fmt.Sprintf("invalid workloadNamePatterns: %v", err)
+	}
+	if !names.Matches(obj.GetName()) {
+		return nil, "workload name does not match workloadNamePatterns"
+	}
+	return spec, ""
+}
+
+// workloadConfigForObject returns the configuration whose provider watches obj's type
+func (r *VpaManagerReconciler) workloadConfigForObject(obj client.Object) (WorkloadConfig, bool) {
+	for _, wc := range r.WorkloadConfigs {
+		if reflect.TypeOf(wc.Provider.NewObject()) == reflect.TypeOf(obj) {
+			return wc, true
+		}
+	}
+	return WorkloadConfig{}, false
+}
+
+// vpaCreator returns the VpaManager that created the VPA of a workload of kind, empty when
+// the workload has no operator-managed VPA. The VPA is looked up under the default name and
+// the names the vpaNameTemplates of vpaManagers give it.
+func (r *VpaManagerReconciler) vpaCreator(ctx context.Context, obj client.Object, kind string, vpaManagers []autoscalingv1.VpaManager) string {
+	data := vpa.NameTemplateData{Kind: kind, Name: obj.GetName(), Namespace: obj.GetNamespace()}
+	templates := map[string]bool{"": true}
+	for i := range vpaManagers {
+		templates[vpaManagers[i].Spec.VPANameTemplate] = true
+	}
+	seen := map[string]bool{}
+	for nameTemplate := range templates {
+		name, err := vpa.RenderName(nameTemplate, data)
+		if err != nil || seen[name] {
+			continue
+		}
+		seen[name] = true
+		existing := &unstructured.Unstructured{}
+		existing.SetGroupVersionKind(vpaGVK)
+		if err := r.Get(ctx, types.NamespacedName{Name: name, Namespace: obj.GetNamespace()}, existing); err != nil {
+			continue
+		}
+		if r.Ownership.Owns(existing) {
+			return existing.GetLabels()[vpa.CreatedByLabel]
+		}
+	}
+	return ""
+}
+
 // findVpaManagersForNamespace returns reconcile requests for VpaManagers when namespace changes
 func (r *VpaManagerReconciler) findVpaManagersForNamespace(ctx context.Context, obj client.Object) []reconcile.Request {
 	vpaManagerList := &autoscalingv1.VpaManagerList{}
@@ -452,7 +1644,14 @@ func (r *VpaManagerReconciler) findVpaManagersForNamespace(ctx context.Context,
 	requests := []reconcile.Request{}
 
 	for _, vm := range vpaManagerList.Items {
-		if vm.Spec.Enabled && r.namespaceMatchesSelector(ns, vm.Spec.NamespaceSelector) {
+		if !vm.Spec.Enabled {
+			continue
+		}
+		spec, err := inheritance.ResolveSpec(ctx, r.Client, &vm)
+		if err != nil {
+			continue
+		}
+		if r.selectsNamespace(&vm, r.SelectorDefaults.Apply(spec), ns) {
 			requests = append(requests, reconcile.Request{
 				NamespacedName: types.NamespacedName{Name: vm.Name},
 			})
@@ -461,13 +1660,49 @@ func (r *VpaManagerReconciler) findVpaManagersForNamespace(ctx context.Context,
 	return requests
 }
 
-// namespaceMatchesSelector checks if a namespace matches a label selector
-func (r *VpaManagerReconciler) namespaceMatchesSelector(ns *corev1.Namespace, selector *metav1.LabelSelector) bool {
-	if selector == nil {
+// findVpaManagersForVPACRD drops the cached VPA availability when the VPA CRD changes and
+// re-enqueues every enabled VpaManager
+func (r *VpaManagerReconciler) findVpaManagersForVPACRD(ctx context.Context, obj client.Object) []reconcile.Request {
+	r.VPAAvailability.Invalidate()
+	return r.enabledVpaManagerRequests(ctx)
+}
+
+// findVpaManagersForNode enqueues the enabled VpaManagers with workloads the topology guard
+// can block when a node changes the topology domains
+func (r *VpaManagerReconciler) findVpaManagersForNode(ctx context.Context, obj client.Object) []reconcile.Request {
+	requests := []reconcile.Request{}
+	for _, request := range r.enabledVpaManagerRequests(ctx) {
+		if r.guarded.has(request.Name) {
+			requests = append(requests, request)
+		}
+	}
+	return requests
+}
+
+// enabledVpaManagerRequests returns a request for every enabled VpaManager
+func (r *VpaManagerReconciler) enabledVpaManagerRequests(ctx context.Context) []reconcile.Request {
+	vpaManagerList := &autoscalingv1.VpaManagerList{}
+	if err := r.List(ctx, vpaManagerList); err != nil {
+		return nil
+	}
+
+	requests := []reconcile.Request{}
+	for _, vm := range vpaManagerList.Items {
+		if vm.Spec.Enabled {
+			requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Name: vm.Name}})
+		}
+	}
+	return requests
+}
+
+// namespaceMatchesSelector checks if a namespace matches any of the namespace selectors of
+// vm; no selectors match every namespace
+func (r *VpaManagerReconciler) namespaceMatchesSelector(vm *autoscalingv1.VpaManager, ns *corev1.Namespace, selectors []*metav1.LabelSelector) bool {
+	if len(selectors) == 0 {
 		return true
 	}
 
-	labelSelector, err := metav1.LabelSelectorAsSelector(selector)
+	labelSelector, err := r.Selectors.CompileAny(vm.Name, vm.Generation, namespaceSelectorField, selectors)
 	if err != nil {
 		return false
 	}
@@ -2,23 +2,39 @@ package controller
 
 import (
 	"context"
+	"fmt"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	autoscalingv1 "github.com/joaomo/k8s_op_vpa/api/v1"
+	"github.com/joaomo/k8s_op_vpa/internal/decisions"
+	"github.com/joaomo/k8s_op_vpa/internal/labelselector"
 	"github.com/joaomo/k8s_op_vpa/internal/metrics"
+	"github.com/joaomo/k8s_op_vpa/internal/policy"
+	"github.com/joaomo/k8s_op_vpa/internal/validation"
+	"github.com/joaomo/k8s_op_vpa/internal/vpa"
+	"github.com/joaomo/k8s_op_vpa/internal/workload"
 )
 
 // Test: Automatically create VPA resources for deployments
@@ -384,6 +400,8 @@ func TestReconcile_SetsResourcePoliciesForContainers(t *testing.T) {
 							"cpu":    "1",
 							"memory": "1Gi",
 						},
+						ControlledResources: []string{"memory"},
+						ControlledValues:    autoscalingv1.ControlledValuesRequestsOnly,
 					},
 				},
 			},
@@ -417,6 +435,9 @@ func TestReconcile_SetsResourcePoliciesForContainers(t *testing.T) {
 	policy := containerPolicies[0].(map[string]interface{})
 	assert.Equal(t, "*", policy["containerName"])
 
+	assert.Equal(t, []interface{}{"memory"}, policy["controlledResources"])
+	assert.Equal(t, "RequestsOnly", policy["controlledValues"])
+
 	minAllowed := policy["minAllowed"].(map[string]interface{})
 	assert.Equal(t, "100m", minAllowed["cpu"])
 	assert.Equal(t, "100Mi", minAllowed["memory"])
@@ -426,6 +447,159 @@ func TestReconcile_SetsResourcePoliciesForContainers(t *testing.T) {
 	assert.Equal(t, "1Gi", maxAllowed["memory"])
 }
 
+// Test: Well-known sidecars get a mode Off container policy
+func TestReconcile_ExcludesWellKnownSidecars(t *testing.T) {
+	scheme := setupScheme(t)
+	ctx := context.Background()
+
+	namespace := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "test-ns",
+			Labels: map[string]string{"vpa-enabled": "true"},
+		},
+	}
+
+	spec := createDeploymentSpec()
+	spec.Template.Spec.Containers = append(spec.Template.Spec.Containers, corev1.Container{Name: "istio-proxy", Image: "istio/proxyv2"})
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-deployment",
+			Namespace: "test-ns",
+			Labels:    map[string]string{"vpa-enabled": "true"},
+			UID:       "uid-1",
+		},
+		Spec: spec,
+	}
+
+	vpaManager := &autoscalingv1.VpaManager{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-vpamanager"},
+		Spec: autoscalingv1.VpaManagerSpec{
+			Enabled:    true,
+			UpdateMode: "Auto",
+			NamespaceSelector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{"vpa-enabled": "true"},
+			},
+			DeploymentSelector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{"vpa-enabled": "true"},
+			},
+			ExcludeWellKnownSidecars: true,
+		},
+	}
+
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(namespace, deployment, vpaManager).
+		WithStatusSubresource(vpaManager).
+		Build()
+
+	reconciler := &VpaManagerReconciler{Client: fakeClient, Scheme: scheme, Metrics: createTestMetrics(), WorkloadConfigs: DefaultWorkloadConfigs()}
+
+	_, err := reconciler.Reconcile(ctx, reconcile.Request{
+		NamespacedName: types.NamespacedName{Name: "test-vpamanager"},
+	})
+	require.NoError(t, err)
+
+	vpaList := newVPAList()
+	err = fakeClient.List(ctx, vpaList, client.InNamespace("test-ns"))
+	require.NoError(t, err)
+	require.Len(t, vpaList.Items, 1)
+
+	containerPolicies, _, _ := unstructured.NestedSlice(vpaList.Items[0].Object, "spec", "resourcePolicy", "containerPolicies")
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"containerName": "istio-proxy", "mode": "Off"},
+	}, containerPolicies)
+}
+
+// Test: A VPA being deleted is left alone and recreated once it is gone
+func TestReconcile_WaitsForTerminatingVPA(t *testing.T) {
+	scheme := setupScheme(t)
+	ctx := context.Background()
+
+	namespace := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "test-ns",
+			Labels: map[string]string{"vpa-enabled": "true"},
+		},
+	}
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-deployment",
+			Namespace: "test-ns",
+			Labels:    map[string]string{"vpa-enabled": "true"},
+			UID:       "uid-1",
+		},
+		Spec: createDeploymentSpec(),
+	}
+	vpaManager := &autoscalingv1.VpaManager{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-vpamanager"},
+		Spec: autoscalingv1.VpaManagerSpec{
+			Enabled:    true,
+			UpdateMode: "Off",
+			NamespaceSelector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{"vpa-enabled": "true"},
+			},
+			DeploymentSelector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{"vpa-enabled": "true"},
+			},
+		},
+	}
+
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(namespace, deployment, vpaManager).
+		WithStatusSubresource(vpaManager).
+		Build()
+
+	reconciler := &VpaManagerReconciler{Client: fakeClient, Scheme: scheme, Metrics: createTestMetrics(), WorkloadConfigs: DefaultWorkloadConfigs()}
+	reconcileManager := func() reconcile.Result {
+		result, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: "test-vpamanager"}})
+		require.NoError(t, err)
+		return result
+	}
+	vpaKey := types.NamespacedName{Name: "test-deployment-vpa", Namespace: "test-ns"}
+	getVPA := func() (*unstructured.Unstructured, error) {
+		obj := &unstructured.Unstructured{}
+		obj.SetGroupVersionKind(vpaGVK)
+		return obj, fakeClient.Get(ctx, vpaKey, obj)
+	}
+	updateMode := func() string {
+		obj, err := getVPA()
+		require.NoError(t, err)
+		mode, _, _ := unstructured.NestedString(obj.Object, "spec", "updatePolicy", "updateMode")
+		return mode
+	}
+
+	reconcileManager()
+	assert.Equal(t, "Off", updateMode())
+
+	// A finalizer holds the VPA while a cascading deletion removes it
+	existing, err := getVPA()
+	require.NoError(t, err)
+	existing.SetFinalizers([]string{"example.com/cleanup"})
+	require.NoError(t, fakeClient.Update(ctx, existing))
+	require.NoError(t, fakeClient.Delete(ctx, existing))
+
+	require.NoError(t, fakeClient.Get(ctx, types.NamespacedName{Name: "test-vpamanager"}, vpaManager))
+	vpaManager.Spec.UpdateMode = "Auto"
+	require.NoError(t, fakeClient.Update(ctx, vpaManager))
+
+	result := reconcileManager()
+	assert.Equal(t, terminatingVPARequeueDelay, result.RequeueAfter)
+	assert.Equal(t, "Off", updateMode(), "a VPA being deleted is not updated")
+
+	// Once the finalizer is removed the VPA is gone and is recreated
+	existing, err = getVPA()
+	require.NoError(t, err)
+	existing.SetFinalizers(nil)
+	require.NoError(t, fakeClient.Update(ctx, existing))
+	_, err = getVPA()
+	require.True(t, apierrors.IsNotFound(err))
+
+	result = reconcileManager()
+	assert.Equal(t, DefaultResyncPeriod, result.RequeueAfter)
+	assert.Equal(t, "Auto", updateMode())
+}
+
 // Test: Disabled VpaManager should not create VPAs
 func TestReconcile_DisabledManagerDoesNotCreateVPAs(t *testing.T) {
 	scheme := setupScheme(t)
@@ -482,6 +656,58 @@ func TestReconcile_DisabledManagerDoesNotCreateVPAs(t *testing.T) {
 	assert.Len(t, vpaList.Items, 0, "should not create VPA when manager is disabled")
 }
 
+// Test: disabling a VpaManager replaces the Ready and Healthy conditions of its last
+// reconciliation and observes the new generation
+func TestReconcile_DisabledManagerReportsNotReady(t *testing.T) {
+	scheme := setupScheme(t)
+	ctx := context.Background()
+
+	vpaManager := &autoscalingv1.VpaManager{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-vpamanager", Generation: 2},
+		Spec:       autoscalingv1.VpaManagerSpec{Enabled: false, UpdateMode: "Auto", MatchAllNamespaces: true},
+		Status: autoscalingv1.VpaManagerStatus{
+			ObservedGeneration: 1,
+			Conditions: []metav1.Condition{{
+				Type:               autoscalingv1.ConditionReady,
+				Status:             metav1.ConditionTrue,
+				ObservedGeneration: 1,
+				Reason:             "Reconciled",
+				LastTransitionTime: metav1.Now(),
+			}, {
+				Type:               autoscalingv1.ConditionHealthy,
+				Status:             metav1.ConditionTrue,
+				ObservedGeneration: 1,
+				Reason:             "Reconciled",
+				LastTransitionTime: metav1.Now(),
+			}},
+		},
+	}
+
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(vpaManager).
+		WithStatusSubresource(vpaManager).
+		Build()
+
+	reconciler := &VpaManagerReconciler{Client: fakeClient, Scheme: scheme, Metrics: createTestMetrics(), WorkloadConfigs: DefaultWorkloadConfigs()}
+
+	_, err := reconciler.Reconcile(ctx, reconcile.Request{
+		NamespacedName: types.NamespacedName{Name: "test-vpamanager"},
+	})
+	require.NoError(t, err)
+
+	updated := &autoscalingv1.VpaManager{}
+	require.NoError(t, fakeClient.Get(ctx, types.NamespacedName{Name: "test-vpamanager"}, updated))
+	assert.Equal(t, int64(2), updated.Status.ObservedGeneration)
+	for _, conditionType := range []string{autoscalingv1.ConditionReady, autoscalingv1.ConditionHealthy} {
+		condition := meta.FindStatusCondition(updated.Status.Conditions, conditionType)
+		require.NotNil(t, condition, conditionType)
+		assert.Equal(t, metav1.ConditionFalse, condition.Status, conditionType)
+		assert.Equal(t, "Disabled", condition.Reason, conditionType)
+		assert.Equal(t, int64(2), condition.ObservedGeneration, conditionType)
+	}
+}
+
 // Test: VpaManager not found should not error
 func TestReconcile_VpaManagerNotFound(t *testing.T) {
 	scheme := setupScheme(t)
@@ -639,76 +865,66 @@ func TestReconcile_RemovesVPAWhenDeploymentDeleted(t *testing.T) {
 	assert.Len(t, updatedManager.Status.ManagedDeployments, 0)
 }
 
-// Test: No namespace selector means all namespaces
-func TestReconcile_NoNamespaceSelectorMatchesAllNamespaces(t *testing.T) {
+// Test: Orphan cleanup never deletes VPAs of an operator instance with another ownership label
+func TestReconcile_KeepsOrphansOfOtherInstances(t *testing.T) {
 	scheme := setupScheme(t)
 	ctx := context.Background()
 
-	ns1 := &corev1.Namespace{
-		ObjectMeta: metav1.ObjectMeta{Name: "ns1"},
-	}
-	ns2 := &corev1.Namespace{
-		ObjectMeta: metav1.ObjectMeta{Name: "ns2"},
-	}
-
-	deployment1 := &appsv1.Deployment{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      "dep1",
-			Namespace: "ns1",
-			Labels:    map[string]string{"vpa-enabled": "true"},
-			UID:       "uid-1",
-		},
-		Spec: createDeploymentSpec(),
-	}
-
-	deployment2 := &appsv1.Deployment{
+	namespace := &corev1.Namespace{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      "dep2",
-			Namespace: "ns2",
-			Labels:    map[string]string{"vpa-enabled": "true"},
-			UID:       "uid-2",
+			Name:   "test-ns",
+			Labels: map[string]string{"vpa-enabled": "true"},
 		},
-		Spec: createDeploymentSpec(),
 	}
-
 	vpaManager := &autoscalingv1.VpaManager{
 		ObjectMeta: metav1.ObjectMeta{Name: "test-vpamanager"},
 		Spec: autoscalingv1.VpaManagerSpec{
-			Enabled:           true,
-			UpdateMode:        "Auto",
-			NamespaceSelector: nil, // No selector = all namespaces
+			Enabled:    true,
+			UpdateMode: "Auto",
+			NamespaceSelector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{"vpa-enabled": "true"},
+			},
 			DeploymentSelector: &metav1.LabelSelector{
 				MatchLabels: map[string]string{"vpa-enabled": "true"},
 			},
 		},
 	}
 
+	// Both VPAs lost their deployment; only the staging instance's VPA is ours
+	prodVPA := createUnstructuredVPA("prod-vpa", "test-ns", "prod")
+	stagingVPA := createUnstructuredVPA("staging-vpa", "test-ns", "staging")
+	stagingVPA.SetLabels(map[string]string{
+		"app.kubernetes.io/managed-by": "vpa-operator-staging",
+		"app.kubernetes.io/created-by": "test-vpamanager",
+	})
+
 	fakeClient := fake.NewClientBuilder().
 		WithScheme(scheme).
-		WithObjects(ns1, ns2, deployment1, deployment2, vpaManager).
+		WithObjects(namespace, vpaManager, prodVPA, stagingVPA).
 		WithStatusSubresource(vpaManager).
 		Build()
 
-	reconciler := &VpaManagerReconciler{Client: fakeClient, Scheme: scheme, Metrics: createTestMetrics(), WorkloadConfigs: DefaultWorkloadConfigs()}
+	reconciler := &VpaManagerReconciler{
+		Client:          fakeClient,
+		Scheme:          scheme,
+		Metrics:         createTestMetrics(),
+		WorkloadConfigs: DefaultWorkloadConfigs(),
+		Ownership:       vpa.Ownership{Key: "app.kubernetes.io/managed-by", Value: "vpa-operator-staging"},
+	}
 
 	_, err := reconciler.Reconcile(ctx, reconcile.Request{
 		NamespacedName: types.NamespacedName{Name: "test-vpamanager"},
 	})
 	require.NoError(t, err)
 
-	// VPAs should be created in both namespaces
-	var totalVPAs int
-	for _, nsName := range []string{"ns1", "ns2"} {
-		vpaList := newVPAList()
-		err = fakeClient.List(ctx, vpaList, client.InNamespace(nsName))
-		require.NoError(t, err)
-		totalVPAs += len(vpaList.Items)
-	}
-	assert.Equal(t, 2, totalVPAs, "should create VPAs in all namespaces")
+	vpaList := newVPAList()
+	require.NoError(t, fakeClient.List(ctx, vpaList, client.InNamespace("test-ns")))
+	require.Len(t, vpaList.Items, 1)
+	assert.Equal(t, "prod-vpa", vpaList.Items[0].GetName())
 }
 
-// Test: No deployment selector means all deployments
-func TestReconcile_NoDeploymentSelectorMatchesAllDeployments(t *testing.T) {
+// Test: VPAs of another operator instance are left alone and reported with a condition
+func TestReconcile_ReportsForeignInstanceVPAs(t *testing.T) {
 	scheme := setupScheme(t)
 	ctx := context.Background()
 
@@ -718,76 +934,317 @@ func TestReconcile_NoDeploymentSelectorMatchesAllDeployments(t *testing.T) {
 			Labels: map[string]string{"vpa-enabled": "true"},
 		},
 	}
-
-	deployment1 := &appsv1.Deployment{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      "dep1",
-			Namespace: "test-ns",
-			Labels:    map[string]string{"app": "frontend"},
-			UID:       "uid-1",
-		},
-		Spec: createDeploymentSpec(),
-	}
-
-	deployment2 := &appsv1.Deployment{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      "dep2",
-			Namespace: "test-ns",
-			Labels:    map[string]string{"app": "backend"},
-			UID:       "uid-2",
-		},
-		Spec: createDeploymentSpec(),
-	}
-
 	vpaManager := &autoscalingv1.VpaManager{
-		ObjectMeta: metav1.ObjectMeta{Name: "test-vpamanager"},
+		ObjectMeta: metav1.ObjectMeta{Name: "test-vpamanager", Generation: 2},
 		Spec: autoscalingv1.VpaManagerSpec{
 			Enabled:    true,
 			UpdateMode: "Auto",
 			NamespaceSelector: &metav1.LabelSelector{
 				MatchLabels: map[string]string{"vpa-enabled": "true"},
 			},
-			DeploymentSelector: &metav1.LabelSelector{}, // Empty selector = all deployments
+			DeploymentSelector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{"vpa-enabled": "true"},
+			},
 		},
 	}
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "web",
+			Namespace: "test-ns",
+			Labels:    map[string]string{"vpa-enabled": "true"},
+		},
+		Spec: createDeploymentSpec(),
+	}
+
+	staging := vpa.Ownership{Instance: "staging"}
+	// The staging instance generated the deployment's VPA and an orphan of its own
+	stagingVPA := createUnstructuredVPA("web-vpa", "test-ns", "web")
+	stagingVPA.SetLabels(staging.Labels("test-vpamanager"))
+	stagingOrphan := createUnstructuredVPA("gone-vpa", "test-ns", "gone")
+	stagingOrphan.SetLabels(staging.Labels("test-vpamanager"))
 
 	fakeClient := fake.NewClientBuilder().
 		WithScheme(scheme).
-		WithObjects(namespace, deployment1, deployment2, vpaManager).
+		WithObjects(namespace, vpaManager, deployment, stagingVPA, stagingOrphan).
 		WithStatusSubresource(vpaManager).
 		Build()
 
-	reconciler := &VpaManagerReconciler{Client: fakeClient, Scheme: scheme, Metrics: createTestMetrics(), WorkloadConfigs: DefaultWorkloadConfigs()}
+	m := createTestMetrics()
+	reconciler := &VpaManagerReconciler{
+		Client:          fakeClient,
+		Scheme:          scheme,
+		Metrics:         m,
+		WorkloadConfigs: DefaultWorkloadConfigs(),
+		Ownership:       vpa.Ownership{Instance: "prod"},
+	}
 
 	_, err := reconciler.Reconcile(ctx, reconcile.Request{
 		NamespacedName: types.NamespacedName{Name: "test-vpamanager"},
 	})
 	require.NoError(t, err)
 
+	// Neither VPA was touched
 	vpaList := newVPAList()
-	err = fakeClient.List(ctx, vpaList, client.InNamespace("test-ns"))
+	require.NoError(t, fakeClient.List(ctx, vpaList, client.InNamespace("test-ns")))
+	require.Len(t, vpaList.Items, 2)
+	for _, item := range vpaList.Items {
+		assert.Equal(t, "staging", item.GetLabels()[vpa.InstanceLabel])
+		assert.Empty(t, item.GetAnnotations()["vpa-operator.io/spec-hash"], "foreign VPA should not be updated")
+	}
+
+	updatedManager := &autoscalingv1.VpaManager{}
+	require.NoError(t, fakeClient.Get(ctx, types.NamespacedName{Name: "test-vpamanager"}, updatedManager))
+	assert.Equal(t, 0, updatedManager.Status.ManagedVPAs)
+	assert.Equal(t, 1, updatedManager.Status.ForeignVPAs)
+	condition := meta.FindStatusCondition(updatedManager.Status.Conditions, autoscalingv1.ConditionForeignInstanceConflict)
+	require.NotNil(t, condition)
+	assert.Equal(t, metav1.ConditionTrue, condition.Status)
+	assert.Equal(t, int64(2), condition.ObservedGeneration)
+	assert.Contains(t, condition.Message, "test-ns/web-vpa")
+	healthy := meta.FindStatusCondition(updatedManager.Status.Conditions, autoscalingv1.ConditionHealthy)
+	require.NotNil(t, healthy)
+	assert.Equal(t, metav1.ConditionFalse, healthy.Status)
+	assert.Equal(t, autoscalingv1.ConditionForeignInstanceConflict, healthy.Reason)
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.ForeignVPAs.WithLabelValues("test-vpamanager")))
+
+	// Once the staging instance is gone and its VPA removed, the conflict clears
+	require.NoError(t, fakeClient.Delete(ctx, stagingVPA))
+	_, err = reconciler.Reconcile(ctx, reconcile.Request{
+		NamespacedName: types.NamespacedName{Name: "test-vpamanager"},
+	})
 	require.NoError(t, err)
-	assert.Len(t, vpaList.Items, 2, "should create VPAs for all deployments when using empty selector")
+	require.NoError(t, fakeClient.Get(ctx, types.NamespacedName{Name: "test-vpamanager"}, updatedManager))
+	assert.Equal(t, 0, updatedManager.Status.ForeignVPAs)
+	assert.True(t, meta.IsStatusConditionFalse(updatedManager.Status.Conditions, autoscalingv1.ConditionForeignInstanceConflict))
+	assert.True(t, meta.IsStatusConditionTrue(updatedManager.Status.Conditions, autoscalingv1.ConditionHealthy))
 }
 
-// Test: Automatically create VPA resources for StatefulSets
-func TestReconcile_CreatesVPAForMatchingStatefulSet(t *testing.T) {
+// Test: No VPA is created over the cluster-wide cap, and the VpaManager reports it
+func TestReconcile_StopsCreatingVPAsAtClusterCapacity(t *testing.T) {
 	scheme := setupScheme(t)
 	ctx := context.Background()
 
-	namespace := &corev1.Namespace{
-		ObjectMeta: metav1.ObjectMeta{
-			Name: "test-ns",
-			Labels: map[string]string{
-				"vpa-enabled": "true",
-			},
+	selected := map[string]string{"vpa-enabled": "true"}
+	objects := []client.Object{
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "test-ns", Labels: selected}},
+	}
+	vpaManager := &autoscalingv1.VpaManager{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-vpamanager", Generation: 1},
+		Spec: autoscalingv1.VpaManagerSpec{
+			Enabled:            true,
+			UpdateMode:         "Off",
+			NamespaceSelector:  &metav1.LabelSelector{MatchLabels: selected},
+			DeploymentSelector: &metav1.LabelSelector{MatchLabels: selected},
 		},
 	}
+	objects = append(objects, vpaManager)
+	for _, name := range []string{"a", "b", "c"} {
+		objects = append(objects, &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "test-ns", Labels: selected},
+			Spec:       createDeploymentSpec(),
+		})
+	}
 
-	statefulset := &appsv1.StatefulSet{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      "test-statefulset",
-			Namespace: "test-ns",
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(objects...).
+		WithStatusSubresource(vpaManager).
+		Build()
+
+	m := createTestMetrics()
+	reconciler := &VpaManagerReconciler{
+		Client:          fakeClient,
+		Scheme:          scheme,
+		Metrics:         m,
+		WorkloadConfigs: DefaultWorkloadConfigs(),
+		ClusterCapacity: vpa.NewClusterCapacity(2, vpa.Ownership{}),
+	}
+
+	_, err := reconciler.Reconcile(ctx, reconcile.Request{
+		NamespacedName: types.NamespacedName{Name: "test-vpamanager"},
+	})
+	require.NoError(t, err)
+
+	vpaList := newVPAList()
+	require.NoError(t, fakeClient.List(ctx, vpaList, client.InNamespace("test-ns")))
+	assert.Len(t, vpaList.Items, 2)
+
+	updatedManager := &autoscalingv1.VpaManager{}
+	require.NoError(t, fakeClient.Get(ctx, types.NamespacedName{Name: "test-vpamanager"}, updatedManager))
+	assert.Equal(t, 2, updatedManager.Status.ManagedVPAs)
+	condition := meta.FindStatusCondition(updatedManager.Status.Conditions, autoscalingv1.ConditionClusterCapacityReached)
+	require.NotNil(t, condition)
+	assert.Equal(t, metav1.ConditionTrue, condition.Status)
+	assert.Contains(t, condition.Message, "1 selected workloads have no VPA")
+	healthy := meta.FindStatusCondition(updatedManager.Status.Conditions, autoscalingv1.ConditionHealthy)
+	require.NotNil(t, healthy)
+	assert.Equal(t, metav1.ConditionFalse, healthy.Status)
+	assert.Equal(t, autoscalingv1.ConditionClusterCapacityReached, healthy.Reason)
+	assert.Equal(t, float64(2), testutil.ToFloat64(m.ClusterManagedVPAs))
+	assert.Equal(t, float64(2), testutil.ToFloat64(m.ClusterVPALimit))
+
+	// Without a cap the condition is dropped and the last VPA created
+	reconciler.ClusterCapacity = nil
+	_, err = reconciler.Reconcile(ctx, reconcile.Request{
+		NamespacedName: types.NamespacedName{Name: "test-vpamanager"},
+	})
+	require.NoError(t, err)
+	require.NoError(t, fakeClient.List(ctx, vpaList, client.InNamespace("test-ns")))
+	assert.Len(t, vpaList.Items, 3)
+	require.NoError(t, fakeClient.Get(ctx, types.NamespacedName{Name: "test-vpamanager"}, updatedManager))
+	assert.Nil(t, meta.FindStatusCondition(updatedManager.Status.Conditions, autoscalingv1.ConditionClusterCapacityReached))
+	assert.True(t, meta.IsStatusConditionTrue(updatedManager.Status.Conditions, autoscalingv1.ConditionHealthy))
+}
+
+// Test: No namespace selector means all namespaces
+func TestReconcile_NoNamespaceSelectorMatchesAllNamespaces(t *testing.T) {
+	scheme := setupScheme(t)
+	ctx := context.Background()
+
+	ns1 := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{Name: "ns1"},
+	}
+	ns2 := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{Name: "ns2"},
+	}
+
+	deployment1 := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "dep1",
+			Namespace: "ns1",
+			Labels:    map[string]string{"vpa-enabled": "true"},
+			UID:       "uid-1",
+		},
+		Spec: createDeploymentSpec(),
+	}
+
+	deployment2 := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "dep2",
+			Namespace: "ns2",
+			Labels:    map[string]string{"vpa-enabled": "true"},
+			UID:       "uid-2",
+		},
+		Spec: createDeploymentSpec(),
+	}
+
+	vpaManager := &autoscalingv1.VpaManager{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-vpamanager"},
+		Spec: autoscalingv1.VpaManagerSpec{
+			Enabled:           true,
+			UpdateMode:        "Auto",
+			NamespaceSelector: nil, // No selector = all namespaces
+			DeploymentSelector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{"vpa-enabled": "true"},
+			},
+		},
+	}
+
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(ns1, ns2, deployment1, deployment2, vpaManager).
+		WithStatusSubresource(vpaManager).
+		Build()
+
+	reconciler := &VpaManagerReconciler{Client: fakeClient, Scheme: scheme, Metrics: createTestMetrics(), WorkloadConfigs: DefaultWorkloadConfigs()}
+
+	_, err := reconciler.Reconcile(ctx, reconcile.Request{
+		NamespacedName: types.NamespacedName{Name: "test-vpamanager"},
+	})
+	require.NoError(t, err)
+
+	// VPAs should be created in both namespaces
+	var totalVPAs int
+	for _, nsName := range []string{"ns1", "ns2"} {
+		vpaList := newVPAList()
+		err = fakeClient.List(ctx, vpaList, client.InNamespace(nsName))
+		require.NoError(t, err)
+		totalVPAs += len(vpaList.Items)
+	}
+	assert.Equal(t, 2, totalVPAs, "should create VPAs in all namespaces")
+}
+
+// Test: No deployment selector means all deployments
+func TestReconcile_NoDeploymentSelectorMatchesAllDeployments(t *testing.T) {
+	scheme := setupScheme(t)
+	ctx := context.Background()
+
+	namespace := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "test-ns",
+			Labels: map[string]string{"vpa-enabled": "true"},
+		},
+	}
+
+	deployment1 := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "dep1",
+			Namespace: "test-ns",
+			Labels:    map[string]string{"app": "frontend"},
+			UID:       "uid-1",
+		},
+		Spec: createDeploymentSpec(),
+	}
+
+	deployment2 := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "dep2",
+			Namespace: "test-ns",
+			Labels:    map[string]string{"app": "backend"},
+			UID:       "uid-2",
+		},
+		Spec: createDeploymentSpec(),
+	}
+
+	vpaManager := &autoscalingv1.VpaManager{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-vpamanager"},
+		Spec: autoscalingv1.VpaManagerSpec{
+			Enabled:    true,
+			UpdateMode: "Auto",
+			NamespaceSelector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{"vpa-enabled": "true"},
+			},
+			DeploymentSelector: &metav1.LabelSelector{}, // Empty selector = all deployments
+		},
+	}
+
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(namespace, deployment1, deployment2, vpaManager).
+		WithStatusSubresource(vpaManager).
+		Build()
+
+	reconciler := &VpaManagerReconciler{Client: fakeClient, Scheme: scheme, Metrics: createTestMetrics(), WorkloadConfigs: DefaultWorkloadConfigs()}
+
+	_, err := reconciler.Reconcile(ctx, reconcile.Request{
+		NamespacedName: types.NamespacedName{Name: "test-vpamanager"},
+	})
+	require.NoError(t, err)
+
+	vpaList := newVPAList()
+	err = fakeClient.List(ctx, vpaList, client.InNamespace("test-ns"))
+	require.NoError(t, err)
+	assert.Len(t, vpaList.Items, 2, "should create VPAs for all deployments when using empty selector")
+}
+
+// Test: Automatically create VPA resources for StatefulSets
+func TestReconcile_CreatesVPAForMatchingStatefulSet(t *testing.T) {
+	scheme := setupScheme(t)
+	ctx := context.Background()
+
+	namespace := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "test-ns",
+			Labels: map[string]string{
+				"vpa-enabled": "true",
+			},
+		},
+	}
+
+	statefulset := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-statefulset",
+			Namespace: "test-ns",
 			Labels: map[string]string{
 				"vpa-enabled": "true",
 			},
@@ -1372,6 +1829,201 @@ func TestReconcile_ProcessesAllWorkloadTypes(t *testing.T) {
 	assert.Equal(t, 1, updatedManager.Status.DaemonSetCount)
 }
 
+// Test: Selectors are compiled once per VpaManager and dropped once it is deleted
+func TestReconcile_CachesCompiledSelectors(t *testing.T) {
+	scheme := setupScheme(t)
+	ctx := context.Background()
+
+	selected := map[string]string{"vpa-enabled": "true"}
+	vpaManager := &autoscalingv1.VpaManager{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-vpamanager", Generation: 1},
+		Spec: autoscalingv1.VpaManagerSpec{
+			Enabled:             true,
+			UpdateMode:          "Off",
+			NamespaceSelector:   &metav1.LabelSelector{MatchLabels: selected},
+			DeploymentSelector:  &metav1.LabelSelector{MatchLabels: selected},
+			StatefulSetSelector: &metav1.LabelSelector{MatchLabels: selected},
+		},
+	}
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(
+			vpaManager,
+			&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "a", Labels: selected}},
+			&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "b", Labels: selected}},
+			&appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "b", Labels: selected},
+				Spec:       createDeploymentSpec(),
+			},
+		).
+		WithStatusSubresource(vpaManager).
+		Build()
+
+	selectors := labelselector.NewCache()
+	reconciler := &VpaManagerReconciler{
+		Client:          fakeClient,
+		Scheme:          scheme,
+		Metrics:         createTestMetrics(),
+		WorkloadConfigs: DefaultWorkloadConfigs(),
+		Selectors:       selectors,
+	}
+	request := reconcile.Request{NamespacedName: types.NamespacedName{Name: "test-vpamanager"}}
+
+	for i := 0; i < 2; i++ {
+		_, err := reconciler.Reconcile(ctx, request)
+		require.NoError(t, err)
+	}
+	vpaList := newVPAList()
+	require.NoError(t, fakeClient.List(ctx, vpaList, client.InNamespace("b")))
+	assert.Len(t, vpaList.Items, 1)
+	// The namespace, Deployment and StatefulSet selectors
+	assert.Equal(t, 3, selectors.Len())
+
+	require.NoError(t, fakeClient.Delete(ctx, vpaManager))
+	_, err := reconciler.Reconcile(ctx, request)
+	require.NoError(t, err)
+	assert.Equal(t, 0, selectors.Len())
+}
+
+// Test: A successful reconcile is requeued after the configured resync period
+func TestReconcile_RequeuesAfterResyncPeriod(t *testing.T) {
+	tests := []struct {
+		name         string
+		resyncPeriod time.Duration
+		expected     time.Duration
+	}{
+		{name: "default", expected: DefaultResyncPeriod},
+		{name: "reconcile-only mode", resyncPeriod: ModeReconcileOnly.ResyncPeriod(), expected: time.Minute},
+		{name: "custom", resyncPeriod: 30 * time.Second, expected: 30 * time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheme := setupScheme(t)
+			vpaManager := &autoscalingv1.VpaManager{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-vpamanager"},
+				Spec:       autoscalingv1.VpaManagerSpec{Enabled: true, UpdateMode: "Off", MatchAllNamespaces: true},
+			}
+			fakeClient := fake.NewClientBuilder().
+				WithScheme(scheme).
+				WithObjects(vpaManager).
+				WithStatusSubresource(vpaManager).
+				Build()
+
+			reconciler := &VpaManagerReconciler{
+				Client:          fakeClient,
+				Scheme:          scheme,
+				Metrics:         createTestMetrics(),
+				WorkloadConfigs: DefaultWorkloadConfigs(),
+				ResyncPeriod:    tt.resyncPeriod,
+			}
+
+			result, err := reconciler.Reconcile(context.Background(), reconcile.Request{
+				NamespacedName: types.NamespacedName{Name: "test-vpamanager"},
+			})
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result.RequeueAfter)
+		})
+	}
+}
+
+// Test: Status lists are split by workload kind when RecordWorkloadLists is set
+func TestReconcile_RecordsWorkloadListsByKind(t *testing.T) {
+	tests := []struct {
+		name        string
+		recordLists bool
+	}{
+		{name: "lists disabled", recordLists: false},
+		{name: "lists enabled", recordLists: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheme := setupScheme(t)
+			ctx := context.Background()
+
+			namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "test-ns", Labels: map[string]string{"vpa-enabled": "true"}}}
+			deployment := &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-deployment", Namespace: "test-ns", UID: "dep-uid", Labels: map[string]string{"vpa-enabled": "true"}},
+				Spec:       createDeploymentSpec(),
+			}
+			statefulset := &appsv1.StatefulSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-statefulset", Namespace: "test-ns", UID: "sts-uid", Labels: map[string]string{"vpa-enabled": "true"}},
+				Spec:       createStatefulSetSpec(),
+			}
+			daemonset := &appsv1.DaemonSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-daemonset", Namespace: "test-ns", UID: "ds-uid", Labels: map[string]string{"vpa-enabled": "true"}},
+				Spec:       createDaemonSetSpec(),
+			}
+			vpaManager := &autoscalingv1.VpaManager{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-vpamanager"},
+				Spec: autoscalingv1.VpaManagerSpec{
+					Enabled:             true,
+					UpdateMode:          "Off",
+					NamespaceSelector:   &metav1.LabelSelector{MatchLabels: map[string]string{"vpa-enabled": "true"}},
+					DeploymentSelector:  &metav1.LabelSelector{MatchLabels: map[string]string{"vpa-enabled": "true"}},
+					StatefulSetSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"vpa-enabled": "true"}},
+					DaemonSetSelector:   &metav1.LabelSelector{MatchLabels: map[string]string{"vpa-enabled": "true"}},
+				},
+			}
+
+			fakeClient := fake.NewClientBuilder().
+				WithScheme(scheme).
+				WithObjects(namespace, deployment, statefulset, daemonset, vpaManager).
+				WithStatusSubresource(vpaManager).
+				Build()
+
+			recorder := record.NewFakeRecorder(10)
+			reconciler := &VpaManagerReconciler{
+				Client:              fakeClient,
+				Scheme:              scheme,
+				Metrics:             createTestMetrics(),
+				Recorder:            recorder,
+				WorkloadConfigs:     DefaultWorkloadConfigs(),
+				RecordWorkloadLists: tt.recordLists,
+			}
+
+			for i := 0; i < 2; i++ {
+				_, err := reconciler.Reconcile(ctx, reconcile.Request{
+					NamespacedName: types.NamespacedName{Name: "test-vpamanager"},
+				})
+				require.NoError(t, err)
+			}
+
+			updatedManager := &autoscalingv1.VpaManager{}
+			err := fakeClient.Get(ctx, types.NamespacedName{Name: "test-vpamanager"}, updatedManager)
+			require.NoError(t, err)
+			status := updatedManager.Status
+			assert.Equal(t, 3, status.ManagedVPAs)
+
+			if !tt.recordLists {
+				assert.Empty(t, recorder.Events)
+				assert.Empty(t, status.ManagedWorkloads)
+				assert.Empty(t, status.ManagedDeployments)
+				assert.Empty(t, status.ManagedStatefulSets)
+				assert.Empty(t, status.ManagedDaemonSets)
+				return
+			}
+
+			require.Len(t, status.ManagedDeployments, 1)
+			assert.Equal(t, "Deployment", status.ManagedDeployments[0].Kind)
+			assert.Equal(t, "test-deployment-vpa", status.ManagedDeployments[0].VpaName)
+			require.Len(t, status.ManagedStatefulSets, 1)
+			assert.Equal(t, "sts-uid", status.ManagedStatefulSets[0].UID)
+			require.Len(t, status.ManagedDaemonSets, 1)
+			assert.Equal(t, "test-daemonset", status.ManagedDaemonSets[0].Name)
+			assert.Len(t, status.ManagedWorkloads, 3)
+			assert.Len(t, status.AllManagedWorkloads(), 3)
+
+			// The deprecated lists are announced once, not on every reconcile
+			require.Len(t, recorder.Events, 1)
+			event := <-recorder.Events
+			assert.Contains(t, event, DeprecatedStatusFieldsReason)
+			assert.Contains(t, event, "status.managedDeployments and status.managedWorkloads are deprecated")
+		})
+	}
+}
+
 // Test: VPA is owned by VpaManager for garbage collection
 func TestReconcile_VPAHasOwnerReference(t *testing.T) {
 	scheme := setupScheme(t)
@@ -1436,8 +2088,2065 @@ func TestReconcile_VPAHasOwnerReference(t *testing.T) {
 	assert.Equal(t, "test-deployment", ownerRefs[0].Name)
 }
 
-// Helper functions
-
+// Test: GitOps-managed VPAs are not overwritten and drift is reported in status
+func TestReconcile_SkipsGitOpsManagedVPAAndReportsDrift(t *testing.T) {
+	scheme := setupScheme(t)
+	ctx := context.Background()
+
+	namespace := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "test-ns",
+			Labels: map[string]string{"vpa-enabled": "true"},
+		},
+	}
+
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-deployment",
+			Namespace: "test-ns",
+			Labels:    map[string]string{"vpa-enabled": "true"},
+			UID:       "uid-1",
+		},
+		Spec: createDeploymentSpec(),
+	}
+
+	vpaManager := &autoscalingv1.VpaManager{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-vpamanager"},
+		Spec: autoscalingv1.VpaManagerSpec{
+			Enabled:    true,
+			UpdateMode: "Auto",
+			NamespaceSelector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{"vpa-enabled": "true"},
+			},
+			DeploymentSelector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{"vpa-enabled": "true"},
+			},
+		},
+	}
+
+	// Pre-existing VPA committed to Git and synced by Argo CD with a different update mode
+	gitOpsVPA := createUnstructuredVPA("test-deployment-vpa", "test-ns", "test-deployment")
+	gitOpsVPA.SetLabels(nil)
+	gitOpsVPA.SetAnnotations(map[string]string{
+		"argocd.argoproj.io/tracking-id": "apps:autoscaling.k8s.io/VerticalPodAutoscaler:test-ns/test-deployment-vpa",
+	})
+	gitOpsVPA.Object["spec"].(map[string]interface{})["updatePolicy"] = map[string]interface{}{
+		"updateMode": "Off",
+	}
+
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(namespace, deployment, vpaManager, gitOpsVPA).
+		WithStatusSubresource(vpaManager).
+		Build()
+
+	reconciler := &VpaManagerReconciler{Client: fakeClient, Scheme: scheme, Metrics: createTestMetrics(), WorkloadConfigs: DefaultWorkloadConfigs()}
+
+	_, err := reconciler.Reconcile(ctx, reconcile.Request{
+		NamespacedName: types.NamespacedName{Name: "test-vpamanager"},
+	})
+	require.NoError(t, err)
+
+	// Verify the GitOps-managed spec was left untouched
+	vpa := &unstructured.Unstructured{}
+	vpa.SetGroupVersionKind(vpaGVK)
+	err = fakeClient.Get(ctx, types.NamespacedName{Name: "test-deployment-vpa", Namespace: "test-ns"}, vpa)
+	require.NoError(t, err)
+	updatePolicy := vpa.Object["spec"].(map[string]interface{})["updatePolicy"].(map[string]interface{})
+	assert.Equal(t, "Off", updatePolicy["updateMode"], "GitOps-managed VPA spec should not be overwritten")
+	assert.NotContains(t, vpa.GetAnnotations(), "vpa-operator.io/spec-hash")
+
+	// Verify drift is reported in status
+	updatedManager := &autoscalingv1.VpaManager{}
+	err = fakeClient.Get(ctx, types.NamespacedName{Name: "test-vpamanager"}, updatedManager)
+	require.NoError(t, err)
+	assert.Equal(t, 1, updatedManager.Status.DriftedVPAs)
+}
+
+// Test: VPA writes are stamped with the reconcile that made them, and unchanged VPAs are not rewritten
+func TestReconcile_StampsVPAWithReconcile(t *testing.T) {
+	scheme := setupScheme(t)
+	ctx := context.Background()
+
+	namespace := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "test-ns",
+			Labels: map[string]string{"vpa-enabled": "true"},
+		},
+	}
+
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-deployment",
+			Namespace: "test-ns",
+			Labels:    map[string]string{"vpa-enabled": "true"},
+		},
+		Spec: createDeploymentSpec(),
+	}
+
+	vpaManager := &autoscalingv1.VpaManager{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-vpamanager"},
+		Spec: autoscalingv1.VpaManagerSpec{
+			Enabled:    true,
+			UpdateMode: "Off",
+			NamespaceSelector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{"vpa-enabled": "true"},
+			},
+			DeploymentSelector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{"vpa-enabled": "true"},
+			},
+		},
+	}
+
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(namespace, deployment, vpaManager).
+		WithStatusSubresource(vpaManager).
+		Build()
+
+	reconciler := &VpaManagerReconciler{Client: fakeClient, Scheme: scheme, Metrics: createTestMetrics(), WorkloadConfigs: DefaultWorkloadConfigs()}
+
+	getVPA := func() *unstructured.Unstructured {
+		vpaObj := &unstructured.Unstructured{}
+		vpaObj.SetGroupVersionKind(vpaGVK)
+		require.NoError(t, fakeClient.Get(ctx, types.NamespacedName{Name: "test-deployment-vpa", Namespace: "test-ns"}, vpaObj))
+		return vpaObj
+	}
+
+	_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: "test-vpamanager"}})
+	require.NoError(t, err)
+	created := getVPA()
+	annotations := created.GetAnnotations()
+	assert.NotEmpty(t, annotations[vpa.ReconcileIDAnnotation])
+	_, err = time.Parse(time.RFC3339, annotations[vpa.LastReconciledAtAnnotation])
+	assert.NoError(t, err)
+
+	_, err = reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: "test-vpamanager"}})
+	require.NoError(t, err)
+	unchanged := getVPA()
+	assert.Equal(t, created.GetResourceVersion(), unchanged.GetResourceVersion(), "an unchanged VPA should not be rewritten to refresh the stamp")
+	assert.Equal(t, annotations[vpa.ReconcileIDAnnotation], unchanged.GetAnnotations()[vpa.ReconcileIDAnnotation])
+}
+
+// Test: The VPA of a deleted workload is reset when a new workload reuses the name
+func TestReconcile_ResetsVPAOfRecreatedWorkload(t *testing.T) {
+	scheme := setupScheme(t)
+	ctx := context.Background()
+
+	namespace := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "test-ns",
+			Labels: map[string]string{"vpa-enabled": "true"},
+		},
+	}
+
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-deployment",
+			Namespace: "test-ns",
+			Labels:    map[string]string{"vpa-enabled": "true"},
+			UID:       "new-uid",
+		},
+		Spec: createDeploymentSpec(),
+	}
+
+	vpaManager := &autoscalingv1.VpaManager{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-vpamanager"},
+		Spec: autoscalingv1.VpaManagerSpec{
+			Enabled:    true,
+			UpdateMode: "Off",
+			NamespaceSelector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{"vpa-enabled": "true"},
+			},
+			DeploymentSelector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{"vpa-enabled": "true"},
+			},
+			ContainerPolicyMerge: autoscalingv1.ContainerPolicyMergeByContainerName,
+		},
+	}
+
+	// VPA generated for the previous deployment of the same name, with a hand-tuned policy
+	controller := true
+	staleVPA := createUnstructuredVPA("test-deployment-vpa", "test-ns", "test-deployment")
+	staleVPA.SetAnnotations(map[string]string{
+		vpa.SourceUIDAnnotation:                "old-uid",
+		vpa.ManagedContainerPoliciesAnnotation: "",
+	})
+	staleVPA.SetOwnerReferences([]metav1.OwnerReference{
+		{APIVersion: "apps/v1", Kind: "Deployment", Name: "test-deployment", UID: "old-uid", Controller: &controller},
+	})
+	staleVPA.Object["spec"].(map[string]interface{})["resourcePolicy"] = map[string]interface{}{
+		"containerPolicies": []interface{}{
+			map[string]interface{}{"containerName": "app", "mode": "Off"},
+		},
+	}
+
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(namespace, deployment, vpaManager, staleVPA).
+		WithStatusSubresource(vpaManager).
+		Build()
+
+	m := createTestMetrics()
+	reconciler := &VpaManagerReconciler{Client: fakeClient, Scheme: scheme, Metrics: m, WorkloadConfigs: DefaultWorkloadConfigs()}
+
+	_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: "test-vpamanager"}})
+	require.NoError(t, err)
+
+	vpaObj := &unstructured.Unstructured{}
+	vpaObj.SetGroupVersionKind(vpaGVK)
+	require.NoError(t, fakeClient.Get(ctx, types.NamespacedName{Name: "test-deployment-vpa", Namespace: "test-ns"}, vpaObj))
+
+	owners := vpaObj.GetOwnerReferences()
+	require.Len(t, owners, 1)
+	assert.Equal(t, types.UID("new-uid"), owners[0].UID, "owner reference should point at the new deployment")
+	assert.Equal(t, "new-uid", vpaObj.GetAnnotations()[vpa.SourceUIDAnnotation])
+	_, found, err := unstructured.NestedSlice(vpaObj.Object, "spec", "resourcePolicy", "containerPolicies")
+	require.NoError(t, err)
+	assert.False(t, found, "policies tuned for the deleted deployment should be dropped")
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.VPAOperationsTotal.WithLabelValues("reset", "test-vpamanager")))
+}
+
+// Test: VPAs of workloads idle at zero replicas turn Off and get their mode back on scale-up
+func TestReconcile_DormantVPAOfWorkloadScaledToZero(t *testing.T) {
+	scheme := setupScheme(t)
+	ctx := context.Background()
+
+	selected := map[string]string{"vpa-enabled": "true"}
+	namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "test-ns", Labels: selected}}
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-deployment", Namespace: "test-ns", Labels: selected, UID: "uid-1"},
+		Spec:       createDeploymentSpec(),
+	}
+	deployment.Spec.Replicas = new(int32)
+	vpaManager := &autoscalingv1.VpaManager{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-vpamanager"},
+		Spec: autoscalingv1.VpaManagerSpec{
+			Enabled:            true,
+			UpdateMode:         "Auto",
+			NamespaceSelector:  &metav1.LabelSelector{MatchLabels: selected},
+			DeploymentSelector: &metav1.LabelSelector{MatchLabels: selected},
+			Dormancy:           &autoscalingv1.DormancyPolicy{After: metav1.Duration{Duration: time.Hour}},
+		},
+	}
+
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(namespace, deployment, vpaManager).
+		WithStatusSubresource(vpaManager).
+		Build()
+	reconciler := &VpaManagerReconciler{Client: fakeClient, Scheme: scheme, Metrics: createTestMetrics(), WorkloadConfigs: DefaultWorkloadConfigs()}
+	req := reconcile.Request{NamespacedName: types.NamespacedName{Name: "test-vpamanager"}}
+	vpaKey := types.NamespacedName{Name: "test-deployment-vpa", Namespace: "test-ns"}
+	getVPA := func() *unstructured.Unstructured {
+		vpaObj := &unstructured.Unstructured{}
+		vpaObj.SetGroupVersionKind(vpaGVK)
+		require.NoError(t, fakeClient.Get(ctx, vpaKey, vpaObj))
+		return vpaObj
+	}
+	updateMode := func(vpaObj *unstructured.Unstructured) string {
+		mode, _, _ := unstructured.NestedString(vpaObj.Object, "spec", "updatePolicy", "updateMode")
+		return mode
+	}
+
+	// A workload created at zero replicas starts the clock, but keeps its mode for now
+	_, err := reconciler.Reconcile(ctx, req)
+	require.NoError(t, err)
+	vpaObj := getVPA()
+	assert.Equal(t, "Auto", updateMode(vpaObj))
+	require.Contains(t, vpaObj.GetAnnotations(), vpa.ScaledToZeroSinceAnnotation)
+
+	// Past the threshold the VPA turns Off
+	annotations := vpaObj.GetAnnotations()
+	annotations[vpa.ScaledToZeroSinceAnnotation] = time.Now().Add(-2 * time.Hour).UTC().Format(time.RFC3339)
+	vpaObj.SetAnnotations(annotations)
+	require.NoError(t, fakeClient.Update(ctx, vpaObj))
+
+	_, err = reconciler.Reconcile(ctx, req)
+	require.NoError(t, err)
+	vpaObj = getVPA()
+	assert.Equal(t, "Off", updateMode(vpaObj))
+	assert.Equal(t, "Auto", vpaObj.GetAnnotations()[vpa.DormantAnnotation])
+	assert.Equal(t, "dormancy: dormancy policy of VpaManager test-vpamanager", vpaObj.GetAnnotations()[vpa.UpdateModeSourceAnnotation])
+
+	updated := &autoscalingv1.VpaManager{}
+	require.NoError(t, fakeClient.Get(ctx, req.NamespacedName, updated))
+	assert.Equal(t, 1, updated.Status.DormantVPAs)
+
+	// Scaling up restores the mode
+	require.NoError(t, fakeClient.Get(ctx, types.NamespacedName{Name: "test-deployment", Namespace: "test-ns"}, deployment))
+	replicas := int32(2)
+	deployment.Spec.Replicas = &replicas
+	require.NoError(t, fakeClient.Update(ctx, deployment))
+
+	_, err = reconciler.Reconcile(ctx, req)
+	require.NoError(t, err)
+	vpaObj = getVPA()
+	assert.Equal(t, "Auto", updateMode(vpaObj))
+	assert.NotContains(t, vpaObj.GetAnnotations(), vpa.DormantAnnotation)
+	assert.Equal(t, "vpaManager: VpaManager test-vpamanager", vpaObj.GetAnnotations()[vpa.UpdateModeSourceAnnotation])
+	assert.NotContains(t, vpaObj.GetAnnotations(), vpa.ScaledToZeroSinceAnnotation)
+
+	require.NoError(t, fakeClient.Get(ctx, req.NamespacedName, updated))
+	assert.Equal(t, 0, updated.Status.DormantVPAs)
+}
+
+// Test: Update mode changes record their source on the VPA and count as transitions
+func TestReconcile_RecordsUpdateModeTransitions(t *testing.T) {
+	scheme := setupScheme(t)
+	ctx := context.Background()
+
+	selected := map[string]string{"vpa-enabled": "true"}
+	namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "test-ns", Labels: selected}}
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-deployment", Namespace: "test-ns", Labels: selected, UID: "uid-1"},
+		Spec:       createDeploymentSpec(),
+	}
+	vpaManager := &autoscalingv1.VpaManager{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-vpamanager"},
+		Spec: autoscalingv1.VpaManagerSpec{
+			Enabled:            true,
+			UpdateMode:         "Auto",
+			NamespaceSelector:  &metav1.LabelSelector{MatchLabels: selected},
+			DeploymentSelector: &metav1.LabelSelector{MatchLabels: selected},
+		},
+	}
+
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(namespace, deployment, vpaManager).
+		WithStatusSubresource(vpaManager).
+		Build()
+	m := createTestMetrics()
+	reconciler := &VpaManagerReconciler{Client: fakeClient, Scheme: scheme, Metrics: m, WorkloadConfigs: DefaultWorkloadConfigs()}
+	req := reconcile.Request{NamespacedName: types.NamespacedName{Name: "test-vpamanager"}}
+	getVPA := func() *unstructured.Unstructured {
+		vpaObj := &unstructured.Unstructured{}
+		vpaObj.SetGroupVersionKind(vpaGVK)
+		require.NoError(t, fakeClient.Get(ctx, types.NamespacedName{Name: "test-deployment-vpa", Namespace: "test-ns"}, vpaObj))
+		return vpaObj
+	}
+	transitions := func(from, to, layer string) float64 {
+		return testutil.ToFloat64(m.UpdateModeTransitionsTotal.WithLabelValues("test-vpamanager", from, to, layer))
+	}
+
+	// A new VPA records the layer that chose its mode, without counting a transition
+	_, err := reconciler.Reconcile(ctx, req)
+	require.NoError(t, err)
+	annotations := getVPA().GetAnnotations()
+	assert.Equal(t, "vpaManager: VpaManager test-vpamanager", annotations[vpa.UpdateModeSourceAnnotation])
+	require.Contains(t, annotations, vpa.UpdateModeChangedAtAnnotation)
+
+	// An annotation on the workload moves the mode
+	require.NoError(t, fakeClient.Get(ctx, types.NamespacedName{Name: "test-deployment", Namespace: "test-ns"}, deployment))
+	deployment.SetAnnotations(map[string]string{policy.UpdateModeAnnotation: "Initial"})
+	require.NoError(t, fakeClient.Update(ctx, deployment))
+
+	_, err = reconciler.Reconcile(ctx, req)
+	require.NoError(t, err)
+	annotations = getVPA().GetAnnotations()
+	assert.Equal(t, "workload: annotation "+policy.UpdateModeAnnotation, annotations[vpa.UpdateModeSourceAnnotation])
+	assert.Equal(t, float64(1), transitions("Auto", "Initial", "workload"))
+	changedAt := annotations[vpa.UpdateModeChangedAtAnnotation]
+
+	// Reconciling an unchanged mode records nothing
+	_, err = reconciler.Reconcile(ctx, req)
+	require.NoError(t, err)
+	assert.Equal(t, changedAt, getVPA().GetAnnotations()[vpa.UpdateModeChangedAtAnnotation])
+	assert.Equal(t, float64(1), transitions("Auto", "Initial", "workload"))
+}
+
+// Test: Terminating namespaces get no new VPAs and their VPAs are not deleted as orphans
+func TestReconcile_SkipsTerminatingNamespaces(t *testing.T) {
+	scheme := setupScheme(t)
+	ctx := context.Background()
+
+	selected := map[string]string{"vpa-enabled": "true"}
+	namespace := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-ns", Labels: selected},
+		Status:     corev1.NamespaceStatus{Phase: corev1.NamespaceTerminating},
+	}
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "new-deployment", Namespace: "test-ns", Labels: selected},
+		Spec:       createDeploymentSpec(),
+	}
+	vpaManager := &autoscalingv1.VpaManager{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-vpamanager"},
+		Spec: autoscalingv1.VpaManagerSpec{
+			Enabled:            true,
+			UpdateMode:         "Off",
+			NamespaceSelector:  &metav1.LabelSelector{MatchLabels: selected},
+			DeploymentSelector: &metav1.LabelSelector{MatchLabels: selected},
+		},
+	}
+	// The deployment of this VPA was already removed by the namespace deletion
+	leftover := createUnstructuredVPA("deleted-deployment-vpa", "test-ns", "deleted-deployment")
+
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(namespace, deployment, vpaManager, leftover).
+		WithStatusSubresource(vpaManager).
+		Build()
+	reconciler := &VpaManagerReconciler{Client: fakeClient, Scheme: scheme, Metrics: createTestMetrics(), WorkloadConfigs: DefaultWorkloadConfigs()}
+
+	_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: "test-vpamanager"}})
+	require.NoError(t, err)
+
+	vpaList := newVPAList()
+	require.NoError(t, fakeClient.List(ctx, vpaList, client.InNamespace("test-ns")))
+	require.Len(t, vpaList.Items, 1)
+	assert.Equal(t, "deleted-deployment-vpa", vpaList.Items[0].GetName(), "no VPA should be created or deleted")
+
+	updated := &autoscalingv1.VpaManager{}
+	require.NoError(t, fakeClient.Get(ctx, types.NamespacedName{Name: "test-vpamanager"}, updated))
+	assert.Empty(t, updated.Status.LastError)
+	assert.Equal(t, 0, updated.Status.ManagedVPAs)
+}
+
+// Test: Paused namespaces keep their VPAs untouched and are listed in status until resumed
+func TestReconcile_SkipsPausedNamespaces(t *testing.T) {
+	scheme := setupScheme(t)
+	ctx := context.Background()
+
+	selected := map[string]string{"vpa-enabled": "true"}
+	namespace := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "test-ns",
+			Labels:      selected,
+			Annotations: map[string]string{workload.PausedAnnotation: "true"},
+		},
+	}
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "new-deployment", Namespace: "test-ns", Labels: selected},
+		Spec:       createDeploymentSpec(),
+	}
+	vpaManager := &autoscalingv1.VpaManager{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-vpamanager"},
+		Spec: autoscalingv1.VpaManagerSpec{
+			Enabled:            true,
+			UpdateMode:         "Off",
+			NamespaceSelector:  &metav1.LabelSelector{MatchLabels: selected},
+			DeploymentSelector: &metav1.LabelSelector{MatchLabels: selected},
+		},
+	}
+	leftover := createUnstructuredVPA("deleted-deployment-vpa", "test-ns", "deleted-deployment")
+
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(namespace, deployment, vpaManager, leftover).
+		WithStatusSubresource(vpaManager).
+		Build()
+	m := createTestMetrics()
+	reconciler := &VpaManagerReconciler{Client: fakeClient, Scheme: scheme, Metrics: m, WorkloadConfigs: DefaultWorkloadConfigs()}
+	req := reconcile.Request{NamespacedName: types.NamespacedName{Name: "test-vpamanager"}}
+	vpaNames := func() []string {
+		vpaList := newVPAList()
+		require.NoError(t, fakeClient.List(ctx, vpaList, client.InNamespace("test-ns")))
+		var names []string
+		for _, item := range vpaList.Items {
+			names = append(names, item.GetName())
+		}
+		return names
+	}
+
+	_, err := reconciler.Reconcile(ctx, req)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"deleted-deployment-vpa"}, vpaNames(), "no VPA should be created or deleted")
+	updated := &autoscalingv1.VpaManager{}
+	require.NoError(t, fakeClient.Get(ctx, req.NamespacedName, updated))
+	assert.Equal(t, []string{"test-ns"}, updated.Status.PausedNamespaces)
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.PausedNamespaces.WithLabelValues("test-vpamanager")))
+
+	// Resuming the namespace catches up on the changes held back
+	require.NoError(t, fakeClient.Get(ctx, types.NamespacedName{Name: "test-ns"}, namespace))
+	namespace.Annotations = nil
+	require.NoError(t, fakeClient.Update(ctx, namespace))
+
+	_, err = reconciler.Reconcile(ctx, req)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"new-deployment-vpa"}, vpaNames())
+	require.NoError(t, fakeClient.Get(ctx, req.NamespacedName, updated))
+	assert.Empty(t, updated.Status.PausedNamespaces)
+}
+
+// Test: Hand-tuned container policies of an adopted VPA survive a MergeByContainerName update
+func TestReconcile_MergesHandTunedContainerPolicies(t *testing.T) {
+	scheme := setupScheme(t)
+	ctx := context.Background()
+
+	namespace := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "test-ns",
+			Labels: map[string]string{"vpa-enabled": "true"},
+		},
+	}
+
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-deployment",
+			Namespace: "test-ns",
+			Labels:    map[string]string{"vpa-enabled": "true"},
+		},
+		Spec: createDeploymentSpec(),
+	}
+
+	vpaManager := &autoscalingv1.VpaManager{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-vpamanager"},
+		Spec: autoscalingv1.VpaManagerSpec{
+			Enabled:    true,
+			UpdateMode: "Off",
+			NamespaceSelector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{"vpa-enabled": "true"},
+			},
+			DeploymentSelector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{"vpa-enabled": "true"},
+			},
+			ResourcePolicy: &autoscalingv1.ResourcePolicy{
+				ContainerPolicies: []autoscalingv1.ContainerResourcePolicy{
+					{ContainerName: "*", MaxAllowed: map[string]string{"memory": "4Gi"}},
+				},
+			},
+			ContainerPolicyMerge: autoscalingv1.ContainerPolicyMergeByContainerName,
+		},
+	}
+
+	// Pre-existing VPA created by hand with a tuned policy for the app container
+	handMadeVPA := createUnstructuredVPA("test-deployment-vpa", "test-ns", "test-deployment")
+	handMadeVPA.SetLabels(nil)
+	handMadeVPA.Object["spec"].(map[string]interface{})["resourcePolicy"] = map[string]interface{}{
+		"containerPolicies": []interface{}{
+			map[string]interface{}{"containerName": "app", "maxAllowed": map[string]interface{}{"memory": "1Gi"}},
+		},
+	}
+
+	updates := 0
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(namespace, deployment, vpaManager, handMadeVPA).
+		WithStatusSubresource(vpaManager).
+		WithInterceptorFuncs(interceptor.Funcs{
+			Update: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.UpdateOption) error {
+				if _, ok := obj.(*unstructured.Unstructured); ok {
+					updates++
+				}
+				return c.Update(ctx, obj, opts...)
+			},
+		}).
+		Build()
+
+	reconciler := &VpaManagerReconciler{Client: fakeClient, Scheme: scheme, Metrics: createTestMetrics(), WorkloadConfigs: DefaultWorkloadConfigs()}
+
+	for i := 0; i < 2; i++ {
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{
+			NamespacedName: types.NamespacedName{Name: "test-vpamanager"},
+		})
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 1, updates, "an unchanged merge should not be written again")
+
+	vpaObj := &unstructured.Unstructured{}
+	vpaObj.SetGroupVersionKind(vpaGVK)
+	require.NoError(t, fakeClient.Get(ctx, types.NamespacedName{Name: "test-deployment-vpa", Namespace: "test-ns"}, vpaObj))
+	policies, _, err := unstructured.NestedSlice(vpaObj.Object, "spec", "resourcePolicy", "containerPolicies")
+	require.NoError(t, err)
+	require.Len(t, policies, 2)
+	assert.Equal(t, "*", policies[0].(map[string]interface{})["containerName"])
+	assert.Equal(t, map[string]interface{}{"containerName": "app", "maxAllowed": map[string]interface{}{"memory": "1Gi"}}, policies[1])
+	assert.Equal(t, "*", vpaObj.GetAnnotations()[vpa.ManagedContainerPoliciesAnnotation])
+}
+
+// Test: Status reports the rightsizing score from VPA recommendations
+func TestReconcile_ReportsRightsizingScore(t *testing.T) {
+	scheme := setupScheme(t)
+	ctx := context.Background()
+
+	namespace := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "test-ns",
+			Labels: map[string]string{"vpa-enabled": "true"},
+		},
+	}
+
+	spec := createDeploymentSpec()
+	spec.Template.Spec.Containers[0].Resources.Requests = resources("1", "512Mi")
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-deployment",
+			Namespace: "test-ns",
+			Labels:    map[string]string{"vpa-enabled": "true"},
+			UID:       "uid-1",
+		},
+		Spec: spec,
+	}
+
+	vpaManager := &autoscalingv1.VpaManager{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-vpamanager"},
+		Spec: autoscalingv1.VpaManagerSpec{
+			Enabled:    true,
+			UpdateMode: "Off",
+			NamespaceSelector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{"vpa-enabled": "true"},
+			},
+			DeploymentSelector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{"vpa-enabled": "true"},
+			},
+		},
+	}
+
+	// Existing VPA whose recommender suggests half of the current requests
+	existingVPA := vpaWithTargets(map[string]map[string]interface{}{
+		"main": {"cpu": "500m", "memory": "256Mi"},
+	})
+	existingVPA.SetGroupVersionKind(vpaGVK)
+	existingVPA.SetName("test-deployment-vpa")
+	existingVPA.SetNamespace("test-ns")
+	existingVPA.SetLabels(map[string]string{
+		"app.kubernetes.io/managed-by": "vpa-operator",
+		"app.kubernetes.io/created-by": "test-vpamanager",
+	})
+
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(namespace, deployment, vpaManager, existingVPA).
+		WithStatusSubresource(vpaManager).
+		Build()
+
+	m := createTestMetrics()
+	reconciler := &VpaManagerReconciler{Client: fakeClient, Scheme: scheme, Metrics: m, WorkloadConfigs: DefaultWorkloadConfigs()}
+
+	_, err := reconciler.Reconcile(ctx, reconcile.Request{
+		NamespacedName: types.NamespacedName{Name: "test-vpamanager"},
+	})
+	require.NoError(t, err)
+
+	updatedManager := &autoscalingv1.VpaManager{}
+	err = fakeClient.Get(ctx, types.NamespacedName{Name: "test-vpamanager"}, updatedManager)
+	require.NoError(t, err)
+	require.NotNil(t, updatedManager.Status.RightsizingScore)
+	assert.Equal(t, 50, *updatedManager.Status.RightsizingScore)
+	assert.Equal(t, 0.5, testutil.ToFloat64(m.RightsizingScore.WithLabelValues("test-vpamanager")))
+
+	// Without recommendations there is no score, and the series is removed
+	require.NoError(t, fakeClient.Get(ctx, types.NamespacedName{Namespace: "test-ns", Name: "test-deployment-vpa"}, existingVPA))
+	unstructured.RemoveNestedField(existingVPA.Object, "status")
+	require.NoError(t, fakeClient.Update(ctx, existingVPA))
+	_, err = reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: "test-vpamanager"}})
+	require.NoError(t, err)
+	require.NoError(t, fakeClient.Get(ctx, types.NamespacedName{Name: "test-vpamanager"}, updatedManager))
+	assert.Nil(t, updatedManager.Status.RightsizingScore)
+	assert.Equal(t, 0, testutil.CollectAndCount(m.RightsizingScore))
+
+	// A deleted VpaManager leaves no score behind
+	m.SetRightsizingScore("test-vpamanager", 0.5)
+	require.NoError(t, fakeClient.Delete(ctx, updatedManager))
+	_, err = reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: "test-vpamanager"}})
+	require.NoError(t, err)
+	assert.Equal(t, 0, testutil.CollectAndCount(m.RightsizingScore))
+}
+
+// Test: Invalid resource policies are not turned into VPAs
+func TestReconcile_SkipsInvalidSpec(t *testing.T) {
+	scheme := setupScheme(t)
+	ctx := context.Background()
+
+	namespace := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "test-ns",
+			Labels: map[string]string{"vpa-enabled": "true"},
+		},
+	}
+
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-deployment",
+			Namespace: "test-ns",
+			Labels:    map[string]string{"vpa-enabled": "true"},
+			UID:       "uid-1",
+		},
+		Spec: createDeploymentSpec(),
+	}
+
+	vpaManager := &autoscalingv1.VpaManager{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-vpamanager"},
+		Spec: autoscalingv1.VpaManagerSpec{
+			Enabled:    true,
+			UpdateMode: "Auto",
+			DeploymentSelector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{"vpa-enabled": "true"},
+			},
+			ResourcePolicy: &autoscalingv1.ResourcePolicy{
+				ContainerPolicies: []autoscalingv1.ContainerResourcePolicy{{
+					ContainerName: "*",
+					MinAllowed:    map[string]string{"cpu": "2"},
+					MaxAllowed:    map[string]string{"cpu": "1"},
+				}},
+			},
+		},
+	}
+
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(namespace, deployment, vpaManager).
+		WithStatusSubresource(vpaManager).
+		Build()
+
+	m := createTestMetrics()
+	reconciler := &VpaManagerReconciler{Client: fakeClient, Scheme: scheme, Metrics: m, WorkloadConfigs: DefaultWorkloadConfigs()}
+
+	_, err := reconciler.Reconcile(ctx, reconcile.Request{
+		NamespacedName: types.NamespacedName{Name: "test-vpamanager"},
+	})
+	require.NoError(t, err, "invalid specs should not be retried until they change")
+
+	vpaList := newVPAList()
+	err = fakeClient.List(ctx, vpaList, client.InNamespace("test-ns"))
+	require.NoError(t, err)
+	assert.Len(t, vpaList.Items, 0, "should not create VPAs the VPA admission controller would reject")
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.ReconcileTotal.WithLabelValues("test-vpamanager", metrics.ResultError, metrics.ErrorTypeValidation)))
+}
+
+func TestReconcile_SurfacesVPAAdmissionRejection(t *testing.T) {
+	scheme := setupScheme(t)
+	ctx := context.Background()
+
+	namespace := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "test-ns",
+			Labels: map[string]string{"vpa-enabled": "true"},
+		},
+	}
+
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-deployment",
+			Namespace: "test-ns",
+			Labels:    map[string]string{"vpa-enabled": "true"},
+			UID:       "uid-1",
+		},
+		Spec: createDeploymentSpec(),
+	}
+
+	vpaManager := &autoscalingv1.VpaManager{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-vpamanager"},
+		Spec: autoscalingv1.VpaManagerSpec{
+			Enabled:    true,
+			UpdateMode: "Auto",
+			DeploymentSelector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{"vpa-enabled": "true"},
+			},
+		},
+	}
+
+	rejection := apierrors.NewForbidden(
+		schema.GroupResource{Group: "autoscaling.k8s.io", Resource: "verticalpodautoscalers"},
+		"test-deployment-vpa",
+		fmt.Errorf(`admission webhook "vpa.k8s.io" denied the request: %s`, strings.Repeat("x", 400)),
+	)
+
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(namespace, deployment, vpaManager).
+		WithStatusSubresource(vpaManager).
+		WithInterceptorFuncs(interceptor.Funcs{
+			Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+				if _, ok := obj.(*unstructured.Unstructured); ok {
+					return rejection
+				}
+				return c.Create(ctx, obj, opts...)
+			},
+		}).
+		Build()
+
+	m := createTestMetrics()
+	recorder := record.NewFakeRecorder(10)
+	reconciler := &VpaManagerReconciler{Client: fakeClient, Scheme: scheme, Metrics: m, Recorder: recorder, WorkloadConfigs: DefaultWorkloadConfigs()}
+
+	_, err := reconciler.Reconcile(ctx, reconcile.Request{
+		NamespacedName: types.NamespacedName{Name: "test-vpamanager"},
+	})
+	require.NoError(t, err)
+
+	require.Len(t, recorder.Events, 1)
+	event := <-recorder.Events
+	assert.Contains(t, event, "Warning VPARejected")
+	assert.Contains(t, event, "denied the request")
+
+	updated := &autoscalingv1.VpaManager{}
+	require.NoError(t, fakeClient.Get(ctx, types.NamespacedName{Name: "test-vpamanager"}, updated))
+	require.Len(t, updated.Status.RejectedVPAs, 1)
+	assert.Equal(t, "Deployment", updated.Status.RejectedVPAs[0].Kind)
+	assert.Equal(t, "test-deployment", updated.Status.RejectedVPAs[0].Name)
+	assert.Equal(t, "test-ns", updated.Status.RejectedVPAs[0].Namespace)
+	assert.Len(t, updated.Status.RejectedVPAs[0].Message, maxRejectionMessageLength)
+	assert.Equal(t, 0, updated.Status.ManagedVPAs)
+
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.VPAOperationErrorsTotal.WithLabelValues("create", "test-vpamanager", metrics.ErrorTypeValidation)))
+}
+
+func TestReconcile_SkipsForbiddenNamespaces(t *testing.T) {
+	scheme := setupScheme(t)
+	ctx := context.Background()
+
+	allowedNS := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{Name: "allowed", Labels: map[string]string{"vpa-enabled": "true"}},
+	}
+	restrictedNS := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{Name: "restricted", Labels: map[string]string{"vpa-enabled": "true"}},
+	}
+
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "app",
+			Namespace: "allowed",
+			Labels:    map[string]string{"vpa-enabled": "true"},
+			UID:       "uid-1",
+		},
+		Spec: createDeploymentSpec(),
+	}
+
+	// VPA created in an earlier cycle, before RBAC was tightened
+	existingVPA := createUnstructuredVPA("hidden-vpa", "restricted", "hidden")
+	existingVPA.SetLabels(map[string]string{
+		"app.kubernetes.io/managed-by": "vpa-operator",
+		"app.kubernetes.io/created-by": "test-vpamanager",
+	})
+
+	vpaManager := &autoscalingv1.VpaManager{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-vpamanager"},
+		Spec: autoscalingv1.VpaManagerSpec{
+			Enabled:    true,
+			UpdateMode: "Auto",
+			NamespaceSelector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{"vpa-enabled": "true"},
+			},
+			DeploymentSelector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{"vpa-enabled": "true"},
+			},
+		},
+	}
+
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(allowedNS, restrictedNS, deployment, existingVPA, vpaManager).
+		WithStatusSubresource(vpaManager).
+		WithInterceptorFuncs(interceptor.Funcs{
+			List: func(ctx context.Context, c client.WithWatch, list client.ObjectList, opts ...client.ListOption) error {
+				listOpts := &client.ListOptions{}
+				listOpts.ApplyOptions(opts)
+				if _, ok := list.(*appsv1.DeploymentList); ok && listOpts.Namespace == "restricted" {
+					return apierrors.NewForbidden(schema.GroupResource{Group: "apps", Resource: "deployments"}, "", fmt.Errorf("RBAC: access denied"))
+				}
+				return c.List(ctx, list, opts...)
+			},
+		}).
+		Build()
+
+	m := createTestMetrics()
+	reconciler := &VpaManagerReconciler{Client: fakeClient, Scheme: scheme, Metrics: m, WorkloadConfigs: DefaultWorkloadConfigs()}
+
+	_, err := reconciler.Reconcile(ctx, reconcile.Request{
+		NamespacedName: types.NamespacedName{Name: "test-vpamanager"},
+	})
+	require.NoError(t, err)
+
+	vpa := &unstructured.Unstructured{}
+	vpa.SetGroupVersionKind(vpaGVK)
+	require.NoError(t, fakeClient.Get(ctx, types.NamespacedName{Name: "app-vpa", Namespace: "allowed"}, vpa), "allowed namespaces should still be processed")
+	require.NoError(t, fakeClient.Get(ctx, types.NamespacedName{Name: "hidden-vpa", Namespace: "restricted"}, vpa), "VPAs in forbidden namespaces must not be treated as orphans")
+
+	updated := &autoscalingv1.VpaManager{}
+	require.NoError(t, fakeClient.Get(ctx, types.NamespacedName{Name: "test-vpamanager"}, updated))
+	assert.Equal(t, []string{"restricted"}, updated.Status.ForbiddenNamespaces)
+	assert.Equal(t, 1, updated.Status.ManagedVPAs)
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.ForbiddenNamespaces.WithLabelValues("test-vpamanager")))
+}
+
+func TestReconcile_PropagatesWorkloadAnnotationsToVPA(t *testing.T) {
+	scheme := setupScheme(t)
+	ctx := context.Background()
+
+	namespace := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "test-ns",
+			Labels: map[string]string{"vpa-enabled": "true"},
+		},
+	}
+
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-deployment",
+			Namespace: "test-ns",
+			Labels:    map[string]string{"vpa-enabled": "true"},
+			Annotations: map[string]string{
+				"team":         "payments",
+				"service-tier": "gold",
+				"internal":     "not-copied",
+			},
+			UID: "uid-1",
+		},
+		Spec: createDeploymentSpec(),
+	}
+
+	vpaManager := &autoscalingv1.VpaManager{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-vpamanager"},
+		Spec: autoscalingv1.VpaManagerSpec{
+			Enabled:    true,
+			UpdateMode: "Auto",
+			DeploymentSelector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{"vpa-enabled": "true"},
+			},
+			PropagateAnnotations: []string{"team", "service-tier", "change-ticket"},
+		},
+	}
+
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(namespace, deployment, vpaManager).
+		WithStatusSubresource(vpaManager).
+		Build()
+
+	reconciler := &VpaManagerReconciler{Client: fakeClient, Scheme: scheme, Metrics: createTestMetrics(), WorkloadConfigs: DefaultWorkloadConfigs()}
+	req := reconcile.Request{NamespacedName: types.NamespacedName{Name: "test-vpamanager"}}
+
+	_, err := reconciler.Reconcile(ctx, req)
+	require.NoError(t, err)
+
+	vpa := &unstructured.Unstructured{}
+	vpa.SetGroupVersionKind(vpaGVK)
+	require.NoError(t, fakeClient.Get(ctx, types.NamespacedName{Name: "test-deployment-vpa", Namespace: "test-ns"}, vpa))
+	annotations := vpa.GetAnnotations()
+	assert.Equal(t, "uid-1", annotations["vpa-operator.io/source-uid"])
+	assert.Equal(t, "payments", annotations["team"])
+	assert.Equal(t, "gold", annotations["service-tier"])
+	assert.NotContains(t, annotations, "internal")
+	assert.NotContains(t, annotations, "change-ticket")
+
+	// A new change ticket on the workload is echoed even though the VPA spec is unchanged
+	require.NoError(t, fakeClient.Get(ctx, types.NamespacedName{Name: "test-deployment", Namespace: "test-ns"}, deployment))
+	deployment.Annotations["change-ticket"] = "CHG-1234"
+	require.NoError(t, fakeClient.Update(ctx, deployment))
+
+	_, err = reconciler.Reconcile(ctx, req)
+	require.NoError(t, err)
+
+	require.NoError(t, fakeClient.Get(ctx, types.NamespacedName{Name: "test-deployment-vpa", Namespace: "test-ns"}, vpa))
+	assert.Equal(t, "CHG-1234", vpa.GetAnnotations()["change-ticket"])
+}
+
+func TestReconcile_SetsRecommendationTuningAnnotations(t *testing.T) {
+	scheme := setupScheme(t)
+	ctx := context.Background()
+
+	namespace := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "test-ns",
+			Labels: map[string]string{"vpa-enabled": "true"},
+		},
+	}
+
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-deployment",
+			Namespace: "test-ns",
+			Labels:    map[string]string{"vpa-enabled": "true"},
+			UID:       "uid-1",
+		},
+		Spec: createDeploymentSpec(),
+	}
+
+	vpaManager := &autoscalingv1.VpaManager{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-vpamanager"},
+		Spec: autoscalingv1.VpaManagerSpec{
+			Enabled:    true,
+			UpdateMode: "Auto",
+			DeploymentSelector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{"vpa-enabled": "true"},
+			},
+			Recommenders: []autoscalingv1.RecommenderSelector{{Name: "tuned"}},
+			RecommendationTuning: &autoscalingv1.RecommendationTuning{
+				TargetCPUPercentile: "0.9",
+				Parameters:          map[string]string{"history-length": "8d"},
+			},
+		},
+	}
+
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(namespace, deployment, vpaManager).
+		WithStatusSubresource(vpaManager).
+		Build()
+
+	reconciler := &VpaManagerReconciler{Client: fakeClient, Scheme: scheme, Metrics: createTestMetrics(), WorkloadConfigs: DefaultWorkloadConfigs()}
+	req := reconcile.Request{NamespacedName: types.NamespacedName{Name: "test-vpamanager"}}
+
+	_, err := reconciler.Reconcile(ctx, req)
+	require.NoError(t, err)
+
+	vpaObj := &unstructured.Unstructured{}
+	vpaObj.SetGroupVersionKind(vpaGVK)
+	vpaKey := types.NamespacedName{Name: "test-deployment-vpa", Namespace: "test-ns"}
+	require.NoError(t, fakeClient.Get(ctx, vpaKey, vpaObj))
+	annotations := vpaObj.GetAnnotations()
+	assert.Equal(t, "0.9", annotations["recommender.vpa-operator.io/target-cpu-percentile"])
+	assert.Equal(t, "8d", annotations["recommender.vpa-operator.io/history-length"])
+
+	// Dropping a setting removes its annotation even though the VPA spec is unchanged
+	require.NoError(t, fakeClient.Get(ctx, types.NamespacedName{Name: "test-vpamanager"}, vpaManager))
+	vpaManager.Spec.RecommendationTuning.Parameters = nil
+	require.NoError(t, fakeClient.Update(ctx, vpaManager))
+
+	_, err = reconciler.Reconcile(ctx, req)
+	require.NoError(t, err)
+
+	require.NoError(t, fakeClient.Get(ctx, vpaKey, vpaObj))
+	assert.Equal(t, "0.9", vpaObj.GetAnnotations()["recommender.vpa-operator.io/target-cpu-percentile"])
+	assert.NotContains(t, vpaObj.GetAnnotations(), "recommender.vpa-operator.io/history-length")
+}
+
+func TestReconcile_AppliesSelectorDefaults(t *testing.T) {
+	scheme := setupScheme(t)
+	ctx := context.Background()
+
+	optedIn := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{Name: "opted-in", Labels: map[string]string{"vpa-enabled": "true"}},
+	}
+	other := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{Name: "other"},
+	}
+
+	newDeployment := func(name, namespace string, labels map[string]string) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, Labels: labels, UID: types.UID(namespace + "-" + name)},
+			Spec:       createDeploymentSpec(),
+		}
+	}
+	optInLabels := map[string]string{"vpa-enabled": "true"}
+
+	vpaManager := &autoscalingv1.VpaManager{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-vpamanager"},
+		Spec: autoscalingv1.VpaManagerSpec{
+			Enabled:    true,
+			UpdateMode: "Off",
+		},
+	}
+
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(optedIn, other, vpaManager,
+			newDeployment("labeled", "opted-in", optInLabels),
+			newDeployment("unlabeled", "opted-in", nil),
+			newDeployment("labeled", "other", optInLabels),
+		).
+		WithStatusSubresource(vpaManager).
+		Build()
+
+	defaults, err := policy.NewSelectorDefaults(policy.DefaultSelectorLabel, policy.DefaultSelectorLabel)
+	require.NoError(t, err)
+	reconciler := &VpaManagerReconciler{
+		Client:           fakeClient,
+		Scheme:           scheme,
+		Metrics:          createTestMetrics(),
+		WorkloadConfigs:  DefaultWorkloadConfigs(),
+		SelectorDefaults: defaults,
+	}
+
+	_, err = reconciler.Reconcile(ctx, reconcile.Request{
+		NamespacedName: types.NamespacedName{Name: "test-vpamanager"},
+	})
+	require.NoError(t, err)
+
+	vpaList := newVPAList()
+	require.NoError(t, fakeClient.List(ctx, vpaList))
+	require.Len(t, vpaList.Items, 1, "only opted-in deployments in opted-in namespaces should get a VPA")
+	assert.Equal(t, "labeled-vpa", vpaList.Items[0].GetName())
+	assert.Equal(t, "opted-in", vpaList.Items[0].GetNamespace())
+
+	stored := &autoscalingv1.VpaManager{}
+	require.NoError(t, fakeClient.Get(ctx, types.NamespacedName{Name: "test-vpamanager"}, stored))
+	assert.Nil(t, stored.Spec.NamespaceSelector, "defaults must not be written back to the spec")
+}
+
+func TestReconcile_MatchAllSemantics(t *testing.T) {
+	optIn := &metav1.LabelSelector{MatchLabels: map[string]string{"vpa-enabled": "true"}}
+
+	tests := []struct {
+		name         string
+		strict       bool
+		spec         autoscalingv1.VpaManagerSpec
+		expectedVPAs []string
+	}{
+		{
+			name:         "legacy omitted namespace selector matches every namespace",
+			spec:         autoscalingv1.VpaManagerSpec{DeploymentSelector: optIn},
+			expectedVPAs: []string{"labeled-vpa"},
+		},
+		{
+			name:   "strict omitted namespace selector matches nothing",
+			strict: true,
+			spec:   autoscalingv1.VpaManagerSpec{DeploymentSelector: optIn},
+		},
+		{
+			name:         "strict with matchAllNamespaces",
+			strict:       true,
+			spec:         autoscalingv1.VpaManagerSpec{MatchAllNamespaces: true, DeploymentSelector: optIn},
+			expectedVPAs: []string{"labeled-vpa"},
+		},
+		{
+			name:         "matchAllWorkloads selects unlabeled workloads",
+			strict:       true,
+			spec:         autoscalingv1.VpaManagerSpec{MatchAllNamespaces: true, MatchAllWorkloads: true},
+			expectedVPAs: []string{"labeled-vpa", "unlabeled-vpa"},
+		},
+		{
+			name:   "omitted workload selectors match nothing",
+			strict: true,
+			spec:   autoscalingv1.VpaManagerSpec{MatchAllNamespaces: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheme := setupScheme(t)
+			ctx := context.Background()
+
+			namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "test-ns"}}
+			labeled := &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Name: "labeled", Namespace: "test-ns", Labels: optIn.MatchLabels, UID: "uid-1"},
+				Spec:       createDeploymentSpec(),
+			}
+			unlabeled := &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Name: "unlabeled", Namespace: "test-ns", UID: "uid-2"},
+				Spec:       createDeploymentSpec(),
+			}
+
+			spec := tt.spec
+			spec.Enabled = true
+			spec.UpdateMode = "Off"
+			vpaManager := &autoscalingv1.VpaManager{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-vpamanager"},
+				Spec:       spec,
+			}
+
+			fakeClient := fake.NewClientBuilder().
+				WithScheme(scheme).
+				WithObjects(namespace, labeled, unlabeled, vpaManager).
+				WithStatusSubresource(vpaManager).
+				Build()
+
+			reconciler := &VpaManagerReconciler{
+				Client:          fakeClient,
+				Scheme:          scheme,
+				Metrics:         createTestMetrics(),
+				WorkloadConfigs: DefaultWorkloadConfigs(),
+				StrictSelectors: tt.strict,
+			}
+
+			_, err := reconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: types.NamespacedName{Name: "test-vpamanager"},
+			})
+			require.NoError(t, err)
+
+			vpaList := newVPAList()
+			require.NoError(t, fakeClient.List(ctx, vpaList))
+			names := []string{}
+			for _, item := range vpaList.Items {
+				names = append(names, item.GetName())
+			}
+			assert.ElementsMatch(t, tt.expectedVPAs, names)
+		})
+	}
+}
+
+func TestReconcile_ResolvesInheritFrom(t *testing.T) {
+	scheme := setupScheme(t)
+	ctx := context.Background()
+
+	namespace := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-ns", Labels: map[string]string{"vpa-enabled": "true"}},
+	}
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-deployment",
+			Namespace: "test-ns",
+			Labels:    map[string]string{"vpa-enabled": "true"},
+			UID:       "uid-1",
+		},
+		Spec: createDeploymentSpec(),
+	}
+
+	// A disabled base policy acts as a template only
+	base := &autoscalingv1.VpaManager{
+		ObjectMeta: metav1.ObjectMeta{Name: "base"},
+		Spec: autoscalingv1.VpaManagerSpec{
+			Enabled:    false,
+			UpdateMode: "Off",
+			NamespaceSelector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{"vpa-enabled": "true"},
+			},
+			DeploymentSelector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{"vpa-enabled": "true"},
+			},
+			ResourcePolicy: &autoscalingv1.ResourcePolicy{
+				ContainerPolicies: []autoscalingv1.ContainerResourcePolicy{{
+					ContainerName: "*",
+					MaxAllowed:    map[string]string{"memory": "4Gi"},
+				}},
+			},
+		},
+	}
+	team := &autoscalingv1.VpaManager{
+		ObjectMeta: metav1.ObjectMeta{Name: "team"},
+		Spec: autoscalingv1.VpaManagerSpec{
+			Enabled:     true,
+			UpdateMode:  "Auto",
+			InheritFrom: "base",
+		},
+	}
+
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(namespace, deployment, base, team).
+		WithStatusSubresource(base, team).
+		Build()
+
+	reconciler := &VpaManagerReconciler{Client: fakeClient, Scheme: scheme, Metrics: createTestMetrics(), WorkloadConfigs: DefaultWorkloadConfigs()}
+
+	_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: "team"}})
+	require.NoError(t, err)
+
+	vpa := &unstructured.Unstructured{}
+	vpa.SetGroupVersionKind(vpaGVK)
+	require.NoError(t, fakeClient.Get(ctx, types.NamespacedName{Name: "test-deployment-vpa", Namespace: "test-ns"}, vpa))
+	updateMode, _, _ := unstructured.NestedString(vpa.Object, "spec", "updatePolicy", "updateMode")
+	assert.Equal(t, "Auto", updateMode)
+	policies, _, _ := unstructured.NestedSlice(vpa.Object, "spec", "resourcePolicy", "containerPolicies")
+	require.Len(t, policies, 1, "resource policy should be inherited from the base")
+	assert.Equal(t, "team", vpa.GetLabels()["app.kubernetes.io/created-by"])
+
+	// A cycle is reported without retrying
+	require.NoError(t, fakeClient.Get(ctx, types.NamespacedName{Name: "team"}, team))
+	team.Spec.InheritFrom = "team"
+	require.NoError(t, fakeClient.Update(ctx, team))
+	_, err = reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: "team"}})
+	assert.NoError(t, err)
+}
+
+func TestFindInheritingVpaManagers(t *testing.T) {
+	scheme := setupScheme(t)
+
+	newManager := func(name, inheritFrom string) *autoscalingv1.VpaManager {
+		return &autoscalingv1.VpaManager{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec:       autoscalingv1.VpaManagerSpec{InheritFrom: inheritFrom},
+		}
+	}
+
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(
+			newManager("base", ""),
+			newManager("org", "base"),
+			newManager("team-a", "org"),
+			newManager("team-b", "base"),
+			newManager("unrelated", ""),
+		).
+		Build()
+
+	reconciler := &VpaManagerReconciler{Client: fakeClient, Scheme: scheme, Metrics: createTestMetrics()}
+
+	requests := reconciler.findInheritingVpaManagers(context.Background(), newManager("base", ""))
+	names := []string{}
+	for _, req := range requests {
+		names = append(names, req.Name)
+	}
+	assert.ElementsMatch(t, []string{"org", "team-a", "team-b"}, names)
+}
+
+func TestFindVpaManagersForProfile(t *testing.T) {
+	scheme := setupScheme(t)
+
+	profileRef := &autoscalingv1.ProfileReference{Namespace: "platform", Name: "vpa-defaults"}
+	newManager := func(name, inheritFrom string, ref *autoscalingv1.ProfileReference) *autoscalingv1.VpaManager {
+		return &autoscalingv1.VpaManager{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec:       autoscalingv1.VpaManagerSpec{InheritFrom: inheritFrom, ProfileRef: ref},
+		}
+	}
+
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(
+			newManager("base", "", profileRef),
+			newManager("team-a", "base", nil),
+			newManager("team-b", "", profileRef),
+			newManager("other-profile", "", &autoscalingv1.ProfileReference{Namespace: "platform", Name: "batch"}),
+			newManager("unrelated", "", nil),
+		).
+		Build()
+	reconciler := &VpaManagerReconciler{Client: fakeClient, Scheme: scheme, Metrics: createTestMetrics()}
+
+	tests := []struct {
+		name      string
+		namespace string
+		expected  []string
+	}{
+		{name: "referenced profile", namespace: "platform", expected: []string{"base", "team-a", "team-b"}},
+		{name: "same name in another namespace", namespace: "default", expected: []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			profile := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "vpa-defaults", Namespace: tt.namespace}}
+			names := []string{}
+			for _, req := range reconciler.findVpaManagersForProfile(context.Background(), profile) {
+				names = append(names, req.Name)
+			}
+			assert.ElementsMatch(t, tt.expected, names)
+		})
+	}
+}
+
+func TestReconcile_RechecksTenantScope(t *testing.T) {
+	tests := []struct {
+		name         string
+		selector     map[string]string
+		expectedVPAs []string
+	}{
+		{
+			name:         "selector within tenant",
+			selector:     map[string]string{validation.TenantLabel: "payments"},
+			expectedVPAs: []string{"app-vpa"},
+		},
+		{
+			name:     "selector widened past the webhook",
+			selector: map[string]string{"vpa-enabled": "true"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheme := setupScheme(t)
+			ctx := context.Background()
+
+			own := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+				Name:   "payments",
+				Labels: map[string]string{validation.TenantLabel: "payments", "vpa-enabled": "true"},
+			}}
+			foreign := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+				Name:   "checkout",
+				Labels: map[string]string{validation.TenantLabel: "checkout", "vpa-enabled": "true"},
+			}}
+			newDeployment := func(namespace string) *appsv1.Deployment {
+				return &appsv1.Deployment{
+					ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: namespace, UID: types.UID(namespace)},
+					Spec:       createDeploymentSpec(),
+				}
+			}
+
+			vpaManager := &autoscalingv1.VpaManager{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:   "payments",
+					Labels: map[string]string{validation.TenantLabel: "payments"},
+				},
+				Spec: autoscalingv1.VpaManagerSpec{
+					Enabled:            true,
+					UpdateMode:         "Off",
+					NamespaceSelector:  &metav1.LabelSelector{MatchLabels: tt.selector},
+					DeploymentSelector: &metav1.LabelSelector{},
+				},
+			}
+
+			fakeClient := fake.NewClientBuilder().
+				WithScheme(scheme).
+				WithObjects(own, foreign, newDeployment("payments"), newDeployment("checkout"), vpaManager).
+				WithStatusSubresource(vpaManager).
+				Build()
+
+			reconciler := &VpaManagerReconciler{Client: fakeClient, Scheme: scheme, Metrics: createTestMetrics(), WorkloadConfigs: DefaultWorkloadConfigs()}
+
+			_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: "payments"}})
+			require.NoError(t, err)
+
+			vpaList := newVPAList()
+			require.NoError(t, fakeClient.List(ctx, vpaList))
+			names := []string{}
+			for _, item := range vpaList.Items {
+				assert.Equal(t, "payments", item.GetNamespace(), "VPAs must never be created outside the tenant")
+				names = append(names, item.GetName())
+			}
+			assert.ElementsMatch(t, tt.expectedVPAs, names)
+		})
+	}
+}
+
+// Test: A mass orphan deletion is held back until confirmed
+func TestReconcile_HoldsBackOrphanDeletionBurst(t *testing.T) {
+	scheme := setupScheme(t)
+	ctx := context.Background()
+
+	namespace := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-ns", Labels: map[string]string{"vpa-enabled": "true"}},
+	}
+
+	vpaManager := &autoscalingv1.VpaManager{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-vpamanager"},
+		Spec: autoscalingv1.VpaManagerSpec{
+			Enabled:    true,
+			UpdateMode: "Auto",
+			NamespaceSelector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{"vpa-enabled": "true"},
+			},
+			DeploymentSelector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{"vpa-enabled": "true"},
+			},
+		},
+	}
+
+	// Deployments whose selector label was dropped leave their VPAs orphaned
+	objects := []client.Object{namespace, vpaManager}
+	for i := 0; i < 3; i++ {
+		name := fmt.Sprintf("app-%d", i)
+		objects = append(objects, createUnstructuredVPA(name+"-vpa", "test-ns", name))
+	}
+
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(objects...).
+		WithStatusSubresource(vpaManager).
+		Build()
+
+	m := createTestMetrics()
+	reconciler := &VpaManagerReconciler{
+		Client:           fakeClient,
+		Scheme:           scheme,
+		Metrics:          m,
+		WorkloadConfigs:  DefaultWorkloadConfigs(),
+		OrphanBurstGuard: &OrphanBurstGuard{MaxDeletions: 2, GracePeriod: time.Hour},
+	}
+	req := reconcile.Request{NamespacedName: types.NamespacedName{Name: "test-vpamanager"}}
+
+	_, err := reconciler.Reconcile(ctx, req)
+	require.NoError(t, err)
+
+	vpaList := newVPAList()
+	require.NoError(t, fakeClient.List(ctx, vpaList, client.InNamespace("test-ns")))
+	assert.Len(t, vpaList.Items, 3, "orphans above the limit should be held back")
+
+	updated := &autoscalingv1.VpaManager{}
+	require.NoError(t, fakeClient.Get(ctx, req.NamespacedName, updated))
+	assert.Equal(t, 3, updated.Status.PendingOrphanDeletions)
+	require.NotNil(t, updated.Status.OrphanDeletionsBlockedSince)
+	assert.Equal(t, float64(3), testutil.ToFloat64(m.PendingOrphanDeletions.WithLabelValues("test-vpamanager")))
+	assert.Equal(t, float64(0), testutil.ToFloat64(m.OrphanVPAsDeletedTotal.WithLabelValues("test-vpamanager")))
+
+	// Confirming the burst deletes the orphans and consumes the annotation
+	updated.Annotations = map[string]string{ConfirmOrphanDeletionAnnotation: "true"}
+	require.NoError(t, fakeClient.Update(ctx, updated))
+
+	_, err = reconciler.Reconcile(ctx, req)
+	require.NoError(t, err)
+
+	require.NoError(t, fakeClient.List(ctx, vpaList, client.InNamespace("test-ns")))
+	assert.Len(t, vpaList.Items, 0, "confirmed orphans should be deleted")
+	assert.Equal(t, float64(3), testutil.ToFloat64(m.OrphanVPAsDeletedTotal.WithLabelValues("test-vpamanager")))
+	assert.Equal(t, 1, testutil.CollectAndCount(m.OrphanScanDuration, "vpa_operator_orphan_scan_duration_seconds"))
+	// Every phase of both reconciles was timed
+	assert.Equal(t, 5, testutil.CollectAndCount(m.ReconcilePhaseDuration, "vpa_operator_reconcile_phase_duration_seconds"))
+
+	require.NoError(t, fakeClient.Get(ctx, req.NamespacedName, updated))
+	assert.NotContains(t, updated.Annotations, ConfirmOrphanDeletionAnnotation)
+	assert.Equal(t, 0, updated.Status.PendingOrphanDeletions)
+	assert.Nil(t, updated.Status.OrphanDeletionsBlockedSince)
+}
+
+// Test: Reconcile failures are summarized in status.lastError
+func TestReconcile_RecordsLastError(t *testing.T) {
+	deploymentSelector := &metav1.LabelSelector{MatchLabels: map[string]string{"vpa-enabled": "true"}}
+
+	tests := []struct {
+		name          string
+		spec          autoscalingv1.VpaManagerSpec
+		interceptors  interceptor.Funcs
+		expectedError string
+	}{
+		{
+			name: "invalid spec",
+			spec: autoscalingv1.VpaManagerSpec{
+				Enabled:            true,
+				UpdateMode:         "Auto",
+				MatchAllNamespaces: true,
+				DeploymentSelector: deploymentSelector,
+				ResourcePolicy: &autoscalingv1.ResourcePolicy{
+					ContainerPolicies: []autoscalingv1.ContainerResourcePolicy{{
+						ContainerName: "*",
+						MinAllowed:    map[string]string{"cpu": "2"},
+						MaxAllowed:    map[string]string{"cpu": "1"},
+					}},
+				},
+			},
+			expectedError: "validation: spec.resourcePolicy.containerPolicies[0].minAllowed[cpu]",
+		},
+		{
+			name: "failed VPA write",
+			spec: autoscalingv1.VpaManagerSpec{
+				Enabled:            true,
+				UpdateMode:         "Auto",
+				MatchAllNamespaces: true,
+				DeploymentSelector: deploymentSelector,
+			},
+			interceptors: interceptor.Funcs{
+				Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+					if _, ok := obj.(*unstructured.Unstructured); ok {
+						return fmt.Errorf("dial tcp 10.0.0.1:443: connection refused")
+					}
+					return c.Create(ctx, obj, opts...)
+				},
+			},
+			expectedError: "api_server: deployment test-ns/test-deployment: ",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheme := setupScheme(t)
+			ctx := context.Background()
+
+			namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "test-ns"}}
+			deployment := &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-deployment",
+					Namespace: "test-ns",
+					Labels:    map[string]string{"vpa-enabled": "true"},
+					UID:       "uid-1",
+				},
+				Spec: createDeploymentSpec(),
+			}
+			vpaManager := &autoscalingv1.VpaManager{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-vpamanager"},
+				Spec:       tt.spec,
+			}
+
+			fakeClient := fake.NewClientBuilder().
+				WithScheme(scheme).
+				WithObjects(namespace, deployment, vpaManager).
+				WithStatusSubresource(vpaManager).
+				WithInterceptorFuncs(tt.interceptors).
+				Build()
+
+			reconciler := &VpaManagerReconciler{Client: fakeClient, Scheme: scheme, Metrics: createTestMetrics(), WorkloadConfigs: DefaultWorkloadConfigs()}
+			_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: "test-vpamanager"}})
+			require.NoError(t, err)
+
+			updated := &autoscalingv1.VpaManager{}
+			require.NoError(t, fakeClient.Get(ctx, types.NamespacedName{Name: "test-vpamanager"}, updated))
+			assert.True(t, strings.HasPrefix(updated.Status.LastError, tt.expectedError), "lastError = %q", updated.Status.LastError)
+			assert.NotNil(t, updated.Status.LastErrorTime)
+			healthy := meta.FindStatusCondition(updated.Status.Conditions, autoscalingv1.ConditionHealthy)
+			require.NotNil(t, healthy)
+			assert.Equal(t, metav1.ConditionFalse, healthy.Status)
+			assert.Equal(t, "ReconcileFailed", healthy.Reason)
+			assert.Equal(t, updated.Status.LastError, healthy.Message)
+		})
+	}
+}
+
+func TestSetLastError_Truncates(t *testing.T) {
+	status := &autoscalingv1.VpaManagerStatus{}
+	setLastError(status, fmt.Errorf("%s", strings.Repeat("x", 2*maxLastErrorLength)), metav1.Now())
+	assert.Len(t, status.LastError, maxLastErrorLength)
+	assert.True(t, strings.HasPrefix(status.LastError, "unknown: "))
+}
+
+// Test: spec.recommenders is propagated into generated VPAs
+func TestReconcile_SetsVPARecommenders(t *testing.T) {
+	testCases := []struct {
+		name         string
+		recommenders []autoscalingv1.RecommenderSelector
+		expected     []interface{}
+	}{
+		{name: "default recommender"},
+		{
+			name:         "custom recommender",
+			recommenders: []autoscalingv1.RecommenderSelector{{Name: "gpu-recommender"}},
+			expected:     []interface{}{map[string]interface{}{"name": "gpu-recommender"}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			scheme := setupScheme(t)
+			ctx := context.Background()
+
+			namespace := &corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-ns", Labels: map[string]string{"vpa-enabled": "true"}},
+			}
+			deployment := &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-deployment",
+					Namespace: "test-ns",
+					Labels:    map[string]string{"vpa-enabled": "true"},
+					UID:       "uid-1",
+				},
+				Spec: createDeploymentSpec(),
+			}
+			vpaManager := &autoscalingv1.VpaManager{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-vpamanager"},
+				Spec: autoscalingv1.VpaManagerSpec{
+					Enabled:    true,
+					UpdateMode: "Off",
+					NamespaceSelector: &metav1.LabelSelector{
+						MatchLabels: map[string]string{"vpa-enabled": "true"},
+					},
+					DeploymentSelector: &metav1.LabelSelector{
+						MatchLabels: map[string]string{"vpa-enabled": "true"},
+					},
+					Recommenders: tc.recommenders,
+				},
+			}
+
+			fakeClient := fake.NewClientBuilder().
+				WithScheme(scheme).
+				WithObjects(namespace, deployment, vpaManager).
+				WithStatusSubresource(vpaManager).
+				Build()
+
+			reconciler := &VpaManagerReconciler{Client: fakeClient, Scheme: scheme, Metrics: createTestMetrics(), WorkloadConfigs: DefaultWorkloadConfigs()}
+			_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: "test-vpamanager"}})
+			require.NoError(t, err)
+
+			vpaList := newVPAList()
+			require.NoError(t, fakeClient.List(ctx, vpaList, client.InNamespace("test-ns")))
+			require.Len(t, vpaList.Items, 1)
+
+			recommenders, found, err := unstructured.NestedSlice(vpaList.Items[0].Object, "spec", "recommenders")
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected != nil, found)
+			assert.Equal(t, tc.expected, recommenders)
+		})
+	}
+}
+
+// Test: The operator's own Deployment never gets a VPA, whatever the selectors say
+func TestReconcile_ExcludesOperatorWorkload(t *testing.T) {
+	scheme := setupScheme(t)
+	ctx := context.Background()
+
+	namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "vpa-system"}}
+	operator := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "vpa-operator", Namespace: "vpa-system", UID: "uid-op"},
+		Spec:       createDeploymentSpec(),
+	}
+	neighbour := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "neighbour", Namespace: "vpa-system", UID: "uid-2"},
+		Spec:       createDeploymentSpec(),
+	}
+	// VPA created for the operator before self-protection existed
+	staleVPA := createUnstructuredVPA("vpa-operator-vpa", "vpa-system", "vpa-operator")
+
+	vpaManager := &autoscalingv1.VpaManager{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-vpamanager"},
+		Spec: autoscalingv1.VpaManagerSpec{
+			Enabled:            true,
+			UpdateMode:         "Auto",
+			MatchAllNamespaces: true,
+			MatchAllWorkloads:  true,
+		},
+	}
+
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(namespace, operator, neighbour, staleVPA, vpaManager).
+		WithStatusSubresource(vpaManager).
+		Build()
+
+	reconciler := &VpaManagerReconciler{
+		Client:          fakeClient,
+		Scheme:          scheme,
+		Metrics:         createTestMetrics(),
+		WorkloadConfigs: DefaultWorkloadConfigs(),
+		Self:            &workload.Self{Namespace: "vpa-system", Kind: "Deployment", Name: "vpa-operator"},
+	}
+
+	_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: "test-vpamanager"}})
+	require.NoError(t, err)
+
+	vpaList := newVPAList()
+	require.NoError(t, fakeClient.List(ctx, vpaList, client.InNamespace("vpa-system")))
+	require.Len(t, vpaList.Items, 1, "only the neighbour should keep a VPA")
+	assert.Equal(t, "neighbour-vpa", vpaList.Items[0].GetName())
+
+	updated := &autoscalingv1.VpaManager{}
+	require.NoError(t, fakeClient.Get(ctx, types.NamespacedName{Name: "test-vpamanager"}, updated))
+	assert.Equal(t, "Deployment vpa-system/vpa-operator", updated.Status.OperatorWorkloadExcluded)
+	assert.Equal(t, 1, updated.Status.ManagedVPAs)
+}
+
+// Test: Workloads left without a VPA on purpose are counted by reason and sampled in the status
+func TestReconcile_ReportsSkippedWorkloads(t *testing.T) {
+	scheme := setupScheme(t)
+	ctx := context.Background()
+
+	namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "vpa-system"}}
+	operator := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "vpa-operator", Namespace: "vpa-system", UID: "uid-op"},
+		Spec:       createDeploymentSpec(),
+	}
+	optedOut := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "batch", Namespace: "vpa-system", UID: "uid-2",
+			Annotations: map[string]string{workload.ExcludeAnnotation: "true"}},
+		Spec: createDeploymentSpec(),
+	}
+	managed := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "vpa-system", UID: "uid-3"},
+		Spec:       createDeploymentSpec(),
+	}
+
+	vpaManager := &autoscalingv1.VpaManager{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-vpamanager"},
+		Spec: autoscalingv1.VpaManagerSpec{
+			Enabled:            true,
+			UpdateMode:         "Auto",
+			MatchAllNamespaces: true,
+			MatchAllWorkloads:  true,
+		},
+	}
+
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(namespace, operator, optedOut, managed, vpaManager).
+		WithStatusSubresource(vpaManager).
+		Build()
+
+	reconciler := &VpaManagerReconciler{
+		Client:          fakeClient,
+		Scheme:          scheme,
+		Metrics:         createTestMetrics(),
+		WorkloadConfigs: DefaultWorkloadConfigs(),
+		Self:            &workload.Self{Namespace: "vpa-system", Kind: "Deployment", Name: "vpa-operator"},
+	}
+
+	_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: "test-vpamanager"}})
+	require.NoError(t, err)
+
+	updated := &autoscalingv1.VpaManager{}
+	require.NoError(t, fakeClient.Get(ctx, types.NamespacedName{Name: "test-vpamanager"}, updated))
+	assert.ElementsMatch(t, []autoscalingv1.SkippedWorkload{
+		{Kind: "Deployment", Name: "vpa-operator", Namespace: "vpa-system", Reason: "OperatorWorkload"},
+		{Kind: "Deployment", Name: "batch", Namespace: "vpa-system", Reason: "ExcludedByAnnotation"},
+	}, updated.Status.SkippedWorkloads)
+	assert.Equal(t, 1, updated.Status.ManagedVPAs)
+
+	skips := reconciler.Metrics.WorkloadsSkippedTotal
+	assert.Equal(t, float64(1), testutil.ToFloat64(skips.WithLabelValues("test-vpamanager", "OperatorWorkload")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(skips.WithLabelValues("test-vpamanager", "ExcludedByAnnotation")))
+}
+
+func TestReconcile_RendersTemplatedResourceBounds(t *testing.T) {
+	testCases := []struct {
+		name        string
+		maxAllowed  string
+		expected    interface{}
+		wantLastErr bool
+	}{
+		{
+			name:       "proportional to the container request",
+			maxAllowed: "{{ .Requests.memory | multiply 2 }}",
+			expected:   map[string]interface{}{"memory": "512Mi"},
+		},
+		{
+			name:        "request missing",
+			maxAllowed:  "{{ .Requests.cpu | multiply 2 }}",
+			wantLastErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			scheme := setupScheme(t)
+			ctx := context.Background()
+
+			namespace := &corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-ns", Labels: map[string]string{"vpa-enabled": "true"}},
+			}
+			spec := createDeploymentSpec()
+			spec.Template.Spec.Containers[0].Resources.Requests = corev1.ResourceList{
+				corev1.ResourceMemory: resource.MustParse("256Mi"),
+			}
+			deployment := &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-deployment",
+					Namespace: "test-ns",
+					Labels:    map[string]string{"vpa-enabled": "true"},
+					UID:       "uid-1",
+				},
+				Spec: spec,
+			}
+			vpaManager := &autoscalingv1.VpaManager{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-vpamanager"},
+				Spec: autoscalingv1.VpaManagerSpec{
+					Enabled:            true,
+					UpdateMode:         "Off",
+					NamespaceSelector:  &metav1.LabelSelector{MatchLabels: map[string]string{"vpa-enabled": "true"}},
+					DeploymentSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"vpa-enabled": "true"}},
+					ResourcePolicy: &autoscalingv1.ResourcePolicy{
+						ContainerPolicies: []autoscalingv1.ContainerResourcePolicy{{
+							ContainerName: "main",
+							MaxAllowed:    autoscalingv1.ResourceBounds{"memory": tc.maxAllowed},
+						}},
+					},
+				},
+			}
+
+			fakeClient := fake.NewClientBuilder().
+				WithScheme(scheme).
+				WithObjects(namespace, deployment, vpaManager).
+				WithStatusSubresource(vpaManager).
+				Build()
+
+			reconciler := &VpaManagerReconciler{Client: fakeClient, Scheme: scheme, Metrics: createTestMetrics(), WorkloadConfigs: DefaultWorkloadConfigs()}
+			_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: "test-vpamanager"}})
+			require.NoError(t, err)
+
+			vpaList := newVPAList()
+			require.NoError(t, fakeClient.List(ctx, vpaList, client.InNamespace("test-ns")))
+			updated := &autoscalingv1.VpaManager{}
+			require.NoError(t, fakeClient.Get(ctx, types.NamespacedName{Name: "test-vpamanager"}, updated))
+
+			if tc.wantLastErr {
+				assert.Empty(t, vpaList.Items)
+				assert.Contains(t, updated.Status.LastError, "container main: maxAllowed[memory]")
+				return
+			}
+			require.Len(t, vpaList.Items, 1)
+			policies, _, err := unstructured.NestedSlice(vpaList.Items[0].Object, "spec", "resourcePolicy", "containerPolicies")
+			require.NoError(t, err)
+			require.Len(t, policies, 1)
+			assert.Equal(t, tc.expected, policies[0].(map[string]interface{})["maxAllowed"])
+		})
+	}
+}
+
+// Test: maxAllowedFromQuota caps every container at a share of the namespace ResourceQuota
+func TestReconcile_CapsMaxAllowedFromQuota(t *testing.T) {
+	scheme := setupScheme(t)
+	ctx := context.Background()
+
+	selected := map[string]string{"vpa-enabled": "true"}
+	namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "test-ns", Labels: selected}}
+	quota := &corev1.ResourceQuota{
+		ObjectMeta: metav1.ObjectMeta{Name: "compute", Namespace: "test-ns"},
+		Spec: corev1.ResourceQuotaSpec{Hard: corev1.ResourceList{
+			corev1.ResourceRequestsCPU:    resource.MustParse("4"),
+			corev1.ResourceRequestsMemory: resource.MustParse("8Gi"),
+		}},
+	}
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-deployment", Namespace: "test-ns", Labels: selected, UID: "uid-1"},
+		Spec:       createDeploymentSpec(),
+	}
+	vpaManager := &autoscalingv1.VpaManager{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-vpamanager"},
+		Spec: autoscalingv1.VpaManagerSpec{
+			Enabled:             true,
+			UpdateMode:          "Off",
+			NamespaceSelector:   &metav1.LabelSelector{MatchLabels: selected},
+			DeploymentSelector:  &metav1.LabelSelector{MatchLabels: selected},
+			MaxAllowedFromQuota: &autoscalingv1.QuotaFraction{CPUFraction: "0.25", MemoryFraction: "0.25"},
+		},
+	}
+
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(namespace, quota, deployment, vpaManager).
+		WithStatusSubresource(vpaManager).
+		Build()
+
+	reconciler := &VpaManagerReconciler{Client: fakeClient, Scheme: scheme, Metrics: createTestMetrics(), WorkloadConfigs: DefaultWorkloadConfigs()}
+	_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: "test-vpamanager"}})
+	require.NoError(t, err)
+
+	vpaList := newVPAList()
+	require.NoError(t, fakeClient.List(ctx, vpaList, client.InNamespace("test-ns")))
+	require.Len(t, vpaList.Items, 1)
+	policies, _, err := unstructured.NestedSlice(vpaList.Items[0].Object, "spec", "resourcePolicy", "containerPolicies")
+	require.NoError(t, err)
+	require.Len(t, policies, 1)
+	policy := policies[0].(map[string]interface{})
+	assert.Equal(t, "*", policy["containerName"])
+	assert.Equal(t, map[string]interface{}{"cpu": "1", "memory": "2Gi"}, policy["maxAllowed"])
+}
+
+// Test: a VpaOverride replaces the update mode and resource policy of one workload's VPA
+func TestReconcile_AppliesVpaOverride(t *testing.T) {
+	scheme := setupScheme(t)
+	ctx := context.Background()
+
+	selected := map[string]string{"vpa-enabled": "true"}
+	namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "test-ns", Labels: selected}}
+	quota := &corev1.ResourceQuota{
+		ObjectMeta: metav1.ObjectMeta{Name: "compute", Namespace: "test-ns"},
+		Spec:       corev1.ResourceQuotaSpec{Hard: corev1.ResourceList{corev1.ResourceRequestsCPU: resource.MustParse("4")}},
+	}
+	deployments := []client.Object{}
+	for _, name := range []string{"api", "web"} {
+		deployments = append(deployments, &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "test-ns", Labels: selected, UID: types.UID("uid-" + name)},
+			Spec:       createDeploymentSpec(),
+		})
+	}
+	override := &autoscalingv1.VpaOverride{
+		ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "test-ns"},
+		Spec: autoscalingv1.VpaOverrideSpec{
+			TargetRef:  autoscalingv1.OverrideTargetReference{Kind: "Deployment", Name: "api"},
+			UpdateMode: "Initial",
+			ResourcePolicy: &autoscalingv1.ResourcePolicy{ContainerPolicies: []autoscalingv1.ContainerResourcePolicy{
+				{ContainerName: "*", MaxAllowed: autoscalingv1.ResourceBounds{"cpu": "8"}},
+			}},
+		},
+	}
+	vpaManager := &autoscalingv1.VpaManager{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-vpamanager"},
+		Spec: autoscalingv1.VpaManagerSpec{
+			Enabled:             true,
+			UpdateMode:          "Off",
+			NamespaceSelector:   &metav1.LabelSelector{MatchLabels: selected},
+			DeploymentSelector:  &metav1.LabelSelector{MatchLabels: selected},
+			MaxAllowedFromQuota: &autoscalingv1.QuotaFraction{CPUFraction: "0.5"},
+		},
+	}
+
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(append(deployments, namespace, quota, override, vpaManager)...).
+		WithStatusSubresource(vpaManager).
+		Build()
+
+	reconciler := &VpaManagerReconciler{Client: fakeClient, Scheme: scheme, Metrics: createTestMetrics(), WorkloadConfigs: DefaultWorkloadConfigs()}
+	_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: "test-vpamanager"}})
+	require.NoError(t, err)
+
+	tests := []struct {
+		vpaName      string
+		expectedMode string
+		expectedCPU  string
+	}{
+		{vpaName: "api-vpa", expectedMode: "Initial", expectedCPU: "8"},
+		{vpaName: "web-vpa", expectedMode: "Off", expectedCPU: "2"},
+	}
+	for _, tt := range tests {
+		vpaObj := &unstructured.Unstructured{}
+		vpaObj.SetGroupVersionKind(vpaGVK)
+		require.NoError(t, fakeClient.Get(ctx, types.NamespacedName{Namespace: "test-ns", Name: tt.vpaName}, vpaObj))
+		mode, _, _ := unstructured.NestedString(vpaObj.Object, "spec", "updatePolicy", "updateMode")
+		assert.Equal(t, tt.expectedMode, mode, tt.vpaName)
+		policies, _, _ := unstructured.NestedSlice(vpaObj.Object, "spec", "resourcePolicy", "containerPolicies")
+		require.Len(t, policies, 1, tt.vpaName)
+		assert.Equal(t, tt.expectedCPU, policies[0].(map[string]interface{})["maxAllowed"].(map[string]interface{})["cpu"], tt.vpaName)
+	}
+
+	requests := reconciler.findVpaManagersForOverride(ctx, override)
+	assert.Equal(t, []reconcile.Request{{NamespacedName: types.NamespacedName{Name: "test-vpamanager"}}}, requests)
+}
+
+func TestFindVpaManagersForWorkload(t *testing.T) {
+	scheme := setupScheme(t)
+	ctx := context.Background()
+
+	namespace := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{Name: "team-a", Labels: map[string]string{"vpa-enabled": "true"}},
+	}
+	optIn := &metav1.LabelSelector{MatchLabels: map[string]string{"vpa-enabled": "true"}}
+	managers := []client.Object{
+		&autoscalingv1.VpaManager{
+			ObjectMeta: metav1.ObjectMeta{Name: "matching"},
+			Spec:       autoscalingv1.VpaManagerSpec{Enabled: true, NamespaceSelector: optIn, DeploymentSelector: optIn},
+		},
+		&autoscalingv1.VpaManager{
+			ObjectMeta: metav1.ObjectMeta{Name: "other-namespaces"},
+			Spec: autoscalingv1.VpaManagerSpec{
+				Enabled:            true,
+				NamespaceSelector:  &metav1.LabelSelector{MatchLabels: map[string]string{"tier": "gold"}},
+				DeploymentSelector: optIn,
+			},
+		},
+		&autoscalingv1.VpaManager{
+			ObjectMeta: metav1.ObjectMeta{Name: "statefulsets-only"},
+			Spec:       autoscalingv1.VpaManagerSpec{Enabled: true, NamespaceSelector: optIn, StatefulSetSelector: optIn},
+		},
+		&autoscalingv1.VpaManager{
+			ObjectMeta: metav1.ObjectMeta{Name: "disabled"},
+			Spec:       autoscalingv1.VpaManagerSpec{Enabled: false, MatchAllNamespaces: true, MatchAllWorkloads: true},
+		},
+		&autoscalingv1.VpaManager{
+			ObjectMeta: metav1.ObjectMeta{Name: "previous-owner"},
+			Spec: autoscalingv1.VpaManagerSpec{
+				Enabled:            true,
+				NamespaceSelector:  optIn,
+				DeploymentSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"team": "legacy"}},
+			},
+		},
+	}
+
+	testCases := []struct {
+		name      string
+		namespace string
+		labels    map[string]string
+		vpaOwner  string
+		expected  []string
+	}{
+		{
+			name:      "matching workload",
+			namespace: "team-a",
+			labels:    map[string]string{"vpa-enabled": "true"},
+			expected:  []string{"matching"},
+		},
+		{
+			name:      "workload matching no manager",
+			namespace: "team-a",
+			labels:    map[string]string{"app": "web"},
+			expected:  []string{},
+		},
+		{
+			name:      "manager that created the current VPA",
+			namespace: "team-a",
+			labels:    map[string]string{"app": "web"},
+			vpaOwner:  "previous-owner",
+			expected:  []string{"previous-owner"},
+		},
+		{
+			name:      "unknown namespace falls back to every enabled manager",
+			namespace: "missing",
+			labels:    map[string]string{"app": "web"},
+			expected:  []string{"matching", "other-namespaces", "statefulsets-only", "previous-owner"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			objects := append([]client.Object{namespace}, managers...)
+			if tc.vpaOwner != "" {
+				vpa := createUnstructuredVPA("web-vpa", tc.namespace, "web")
+				vpa.SetLabels(map[string]string{"app.kubernetes.io/managed-by": "vpa-operator", "app.kubernetes.io/created-by": tc.vpaOwner})
+				objects = append(objects, vpa)
+			}
+			fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build()
+			reconciler := &VpaManagerReconciler{Client: fakeClient, Scheme: scheme, Metrics: createTestMetrics(), WorkloadConfigs: DefaultWorkloadConfigs()}
+
+			deployment := &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: tc.namespace, Labels: tc.labels},
+				Spec:       createDeploymentSpec(),
+			}
+			names := []string{}
+			for _, req := range reconciler.findVpaManagersForWorkload(ctx, deployment) {
+				names = append(names, req.Name)
+			}
+			assert.ElementsMatch(t, tc.expected, names)
+		})
+	}
+}
+
+// Helper functions
+
 func createTestMetrics() *metrics.Metrics {
 	reg := prometheus.NewRegistry()
 	return metrics.NewMetrics(reg)
@@ -1533,3 +4242,534 @@ func createUnstructuredVPA(name, namespace, targetDeployment string) *unstructur
 	}
 	return vpa
 }
+
+// Test: listed namespaces are managed without labels, excluded ones never are, whether
+// excluded by name or by labels
+func TestReconcile_NamespaceLists(t *testing.T) {
+	scheme := setupScheme(t)
+	ctx := context.Background()
+
+	optIn := map[string]string{"vpa-enabled": "true"}
+	vpaManager := &autoscalingv1.VpaManager{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-vpamanager"},
+		Spec: autoscalingv1.VpaManagerSpec{
+			Enabled:                  true,
+			UpdateMode:               "Off",
+			MatchAllWorkloads:        true,
+			NamespaceSelector:        &metav1.LabelSelector{MatchLabels: optIn},
+			Namespaces:               []string{"prod-a", "monitoring"},
+			ExcludeNamespaces:        []string{"kube-system"},
+			NamespaceExcludeSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"tier": "system"}},
+		},
+	}
+	namespaces := map[string]map[string]string{
+		"prod-a":      nil,
+		"prod-b":      optIn,
+		"kube-system": optIn,
+		"monitoring":  {"vpa-enabled": "true", "tier": "system"},
+		"other":       nil,
+	}
+	objects := []client.Object{vpaManager}
+	for name, nsLabels := range namespaces {
+		objects = append(objects,
+			&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: nsLabels}},
+			&appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: name, UID: types.UID(name + "-api")},
+				Spec:       createDeploymentSpec(),
+			},
+		)
+	}
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(objects...).
+		WithStatusSubresource(vpaManager).
+		Build()
+	reconciler := &VpaManagerReconciler{
+		Client:          fakeClient,
+		Scheme:          scheme,
+		Metrics:         createTestMetrics(),
+		WorkloadConfigs: DefaultWorkloadConfigs(),
+	}
+
+	_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: "test-vpamanager"}})
+	require.NoError(t, err)
+
+	vpaList := newVPAList()
+	require.NoError(t, fakeClient.List(ctx, vpaList))
+	var managed []string
+	for _, item := range vpaList.Items {
+		managed = append(managed, item.GetNamespace())
+	}
+	assert.ElementsMatch(t, []string{"prod-a", "prod-b"}, managed)
+
+	// Namespace events only re-enqueue the VpaManager for namespaces it selects
+	for name := range namespaces {
+		ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: namespaces[name]}}
+		requests := reconciler.findVpaManagersForNamespace(ctx, ns)
+		assert.Equal(t, name == "prod-a" || name == "prod-b", len(requests) == 1, "namespace %s", name)
+	}
+}
+
+// Test: workloadNamePatterns narrow the workloads of every kind by name, exclude patterns
+// winning over include patterns
+func TestReconcile_WorkloadNamePatterns(t *testing.T) {
+	scheme := setupScheme(t)
+	ctx := context.Background()
+
+	vpaManager := &autoscalingv1.VpaManager{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-vpamanager"},
+		Spec: autoscalingv1.VpaManagerSpec{
+			Enabled:            true,
+			UpdateMode:         "Off",
+			MatchAllNamespaces: true,
+			MatchAllWorkloads:  true,
+			WorkloadNamePatterns: &autoscalingv1.NamePatterns{
+				Include: []string{"legacy-.*"},
+				Exclude: []string{".*-canary"},
+			},
+		},
+	}
+	names := []string{"legacy-billing", "legacy-billing-canary", "billing", "my-legacy-app"}
+	objects := []client.Object{vpaManager, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "shop"}}}
+	deployments := map[string]*appsv1.Deployment{}
+	for _, name := range names {
+		deployments[name] = &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "shop", UID: types.UID(name)},
+			Spec:       createDeploymentSpec(),
+		}
+		objects = append(objects, deployments[name])
+	}
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(objects...).
+		WithStatusSubresource(vpaManager).
+		Build()
+	reconciler := &VpaManagerReconciler{
+		Client:          fakeClient,
+		Scheme:          scheme,
+		Metrics:         createTestMetrics(),
+		WorkloadConfigs: DefaultWorkloadConfigs(),
+	}
+
+	_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: "test-vpamanager"}})
+	require.NoError(t, err)
+
+	vpaList := newVPAList()
+	require.NoError(t, fakeClient.List(ctx, vpaList))
+	var managed []string
+	for _, item := range vpaList.Items {
+		managed = append(managed, item.GetName())
+	}
+	assert.ElementsMatch(t, []string{"legacy-billing-vpa"}, managed)
+
+	// Workload events only re-enqueue the VpaManager for workloads it selects
+	for _, name := range names {
+		requests := reconciler.findVpaManagersForWorkload(ctx, deployments[name])
+		assert.Equal(t, name == "legacy-billing", len(requests) == 1, "deployment %s", name)
+	}
+}
+
+// Test: VPAs are named by the vpaNameTemplate, and changing it replaces the VPAs named by
+// the previous template
+func TestReconcile_VPANameTemplate(t *testing.T) {
+	scheme := setupScheme(t)
+	ctx := context.Background()
+
+	vpaManager := &autoscalingv1.VpaManager{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-vpamanager"},
+		Spec: autoscalingv1.VpaManagerSpec{
+			Enabled:            true,
+			UpdateMode:         "Off",
+			MatchAllNamespaces: true,
+			MatchAllWorkloads:  true,
+		},
+	}
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "shop", UID: "web-uid"},
+		Spec:       createDeploymentSpec(),
+	}
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(vpaManager, deployment, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "shop"}}).
+		WithStatusSubresource(vpaManager).
+		Build()
+	reconciler := &VpaManagerReconciler{
+		Client:          fakeClient,
+		Scheme:          scheme,
+		Metrics:         createTestMetrics(),
+		WorkloadConfigs: DefaultWorkloadConfigs(),
+	}
+	req := reconcile.Request{NamespacedName: types.NamespacedName{Name: "test-vpamanager"}}
+	vpaNames := func() []string {
+		vpaList := newVPAList()
+		require.NoError(t, fakeClient.List(ctx, vpaList))
+		var names []string
+		for _, item := range vpaList.Items {
+			names = append(names, item.GetName())
+		}
+		return names
+	}
+
+	_, err := reconciler.Reconcile(ctx, req)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"web-vpa"}, vpaNames())
+
+	current := &autoscalingv1.VpaManager{}
+	require.NoError(t, fakeClient.Get(ctx, req.NamespacedName, current))
+	current.Spec.VPANameTemplate = "{{ .Kind | lower }}-{{ .Name }}-vpa"
+	require.NoError(t, fakeClient.Update(ctx, current))
+
+	_, err = reconciler.Reconcile(ctx, req)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"deployment-web-vpa"}, vpaNames())
+
+	// The VPA's creator is found under its templated name
+	requests := reconciler.findVpaManagersForWorkload(ctx, deployment)
+	assert.Equal(t, []reconcile.Request{req}, requests)
+	listed := &autoscalingv1.VpaManagerList{}
+	require.NoError(t, fakeClient.List(ctx, listed))
+	assert.Equal(t, "test-vpamanager", reconciler.vpaCreator(ctx, deployment, "Deployment", listed.Items))
+}
+
+// Test: vpaLabels and vpaAnnotations are put on the VPA next to the ownership labels and
+// removed again when dropped from the VpaManager
+func TestReconcile_VPALabelsAndAnnotations(t *testing.T) {
+	scheme := setupScheme(t)
+	ctx := context.Background()
+
+	vpaManager := &autoscalingv1.VpaManager{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-vpamanager"},
+		Spec: autoscalingv1.VpaManagerSpec{
+			Enabled:            true,
+			UpdateMode:         "Off",
+			MatchAllNamespaces: true,
+			MatchAllWorkloads:  true,
+			VPALabels:          map[string]string{"team": "payments"},
+			VPAAnnotations:     map[string]string{"cost-center": "cc-42"},
+		},
+	}
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "shop", UID: "web-uid"},
+		Spec:       createDeploymentSpec(),
+	}
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(vpaManager, deployment, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "shop"}}).
+		WithStatusSubresource(vpaManager).
+		Build()
+	reconciler := &VpaManagerReconciler{
+		Client:          fakeClient,
+		Scheme:          scheme,
+		Metrics:         createTestMetrics(),
+		WorkloadConfigs: DefaultWorkloadConfigs(),
+	}
+	req := reconcile.Request{NamespacedName: types.NamespacedName{Name: "test-vpamanager"}}
+	getVPA := func() *unstructured.Unstructured {
+		vpaObj := &unstructured.Unstructured{}
+		vpaObj.SetGroupVersionKind(vpaGVK)
+		require.NoError(t, fakeClient.Get(ctx, types.NamespacedName{Name: "web-vpa", Namespace: "shop"}, vpaObj))
+		return vpaObj
+	}
+
+	_, err := reconciler.Reconcile(ctx, req)
+	require.NoError(t, err)
+	created := getVPA()
+	assert.Equal(t, "payments", created.GetLabels()["team"])
+	assert.Equal(t, vpa.DefaultManagedByValue, created.GetLabels()[vpa.ManagedByLabel])
+	assert.Equal(t, "test-vpamanager", created.GetLabels()[vpa.CreatedByLabel])
+	assert.Equal(t, "cc-42", created.GetAnnotations()["cost-center"])
+
+	current := &autoscalingv1.VpaManager{}
+	require.NoError(t, fakeClient.Get(ctx, req.NamespacedName, current))
+	current.Spec.VPALabels = map[string]string{"team": "billing"}
+	current.Spec.VPAAnnotations = nil
+	require.NoError(t, fakeClient.Update(ctx, current))
+
+	_, err = reconciler.Reconcile(ctx, req)
+	require.NoError(t, err)
+	updated := getVPA()
+	assert.Equal(t, "billing", updated.GetLabels()["team"])
+	assert.NotContains(t, updated.GetAnnotations(), "cost-center")
+	assert.Equal(t, "test-vpamanager", updated.GetLabels()[vpa.CreatedByLabel])
+}
+
+// Test: updatePolicy.minReplicas is rendered into the VPA updatePolicy only when set
+func TestReconcile_SetsUpdatePolicyMinReplicas(t *testing.T) {
+	two := int32(2)
+
+	tests := []struct {
+		name         string
+		updatePolicy *autoscalingv1.UpdatePolicy
+		expected     interface{}
+	}{
+		{name: "unset"},
+		{name: "without minReplicas", updatePolicy: &autoscalingv1.UpdatePolicy{}},
+		{name: "minReplicas", updatePolicy: &autoscalingv1.UpdatePolicy{MinReplicas: &two}, expected: int64(2)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheme := setupScheme(t)
+			ctx := context.Background()
+
+			selected := map[string]string{"vpa-enabled": "true"}
+			vpaManager := &autoscalingv1.VpaManager{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-vpamanager"},
+				Spec: autoscalingv1.VpaManagerSpec{
+					Enabled:            true,
+					UpdateMode:         "Auto",
+					UpdatePolicy:       tt.updatePolicy,
+					NamespaceSelector:  &metav1.LabelSelector{MatchLabels: selected},
+					DeploymentSelector: &metav1.LabelSelector{MatchLabels: selected},
+				},
+			}
+			fakeClient := fake.NewClientBuilder().
+				WithScheme(scheme).
+				WithObjects(
+					vpaManager,
+					&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "test-ns", Labels: selected}},
+					&appsv1.Deployment{
+						ObjectMeta: metav1.ObjectMeta{Name: "test-deployment", Namespace: "test-ns", Labels: selected},
+						Spec:       createDeploymentSpec(),
+					},
+				).
+				WithStatusSubresource(vpaManager).
+				Build()
+			reconciler := &VpaManagerReconciler{Client: fakeClient, Scheme: scheme, Metrics: createTestMetrics(), WorkloadConfigs: DefaultWorkloadConfigs()}
+
+			_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: "test-vpamanager"}})
+			require.NoError(t, err)
+
+			vpaList := newVPAList()
+			require.NoError(t, fakeClient.List(ctx, vpaList, client.InNamespace("test-ns")))
+			require.Len(t, vpaList.Items, 1)
+			updatePolicy := vpaList.Items[0].Object["spec"].(map[string]interface{})["updatePolicy"].(map[string]interface{})
+			assert.Equal(t, "Auto", updatePolicy["updateMode"])
+			assert.Equal(t, tt.expected, updatePolicy["minReplicas"])
+		})
+	}
+}
+
+// Test: namespaces and workloads matching any of several selectors are managed
+func TestReconcile_AlternativeSelectors(t *testing.T) {
+	teamA := metav1.LabelSelector{MatchLabels: map[string]string{"team": "a"}}
+	shared := metav1.LabelSelector{MatchLabels: map[string]string{"shared": "true"}}
+	web := metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}}
+	frontend := metav1.LabelSelector{MatchLabels: map[string]string{"tier": "frontend"}}
+
+	tests := []struct {
+		name         string
+		spec         autoscalingv1.VpaManagerSpec
+		expectedVPAs []string
+	}{
+		{
+			name: "alternatives only",
+			spec: autoscalingv1.VpaManagerSpec{
+				NamespaceSelectors:  []metav1.LabelSelector{teamA, shared},
+				DeploymentSelectors: []metav1.LabelSelector{web, frontend},
+			},
+			expectedVPAs: []string{
+				"shared/both-vpa", "shared/frontend-vpa", "shared/web-vpa",
+				"team-a/both-vpa", "team-a/frontend-vpa", "team-a/web-vpa",
+			},
+		},
+		{
+			name: "selector and alternatives",
+			spec: autoscalingv1.VpaManagerSpec{
+				NamespaceSelector:   &teamA,
+				DeploymentSelector:  &web,
+				DeploymentSelectors: []metav1.LabelSelector{frontend},
+			},
+			expectedVPAs: []string{"team-a/both-vpa", "team-a/frontend-vpa", "team-a/web-vpa"},
+		},
+		{
+			name: "single selectors",
+			spec: autoscalingv1.VpaManagerSpec{
+				NamespaceSelector:  &shared,
+				DeploymentSelector: &frontend,
+			},
+			expectedVPAs: []string{"shared/both-vpa", "shared/frontend-vpa"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheme := setupScheme(t)
+			ctx := context.Background()
+
+			spec := tt.spec
+			spec.Enabled = true
+			spec.UpdateMode = "Off"
+			vpaManager := &autoscalingv1.VpaManager{ObjectMeta: metav1.ObjectMeta{Name: "test-vpamanager"}, Spec: spec}
+			objects := []client.Object{
+				vpaManager,
+				&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-a", Labels: teamA.MatchLabels}},
+				&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "shared", Labels: shared.MatchLabels}},
+				&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "other"}},
+			}
+			for _, ns := range []string{"team-a", "shared", "other"} {
+				for name, labels := range map[string]map[string]string{
+					"web":      {"app": "web"},
+					"frontend": {"tier": "frontend"},
+					"both":     {"app": "web", "tier": "frontend"},
+					"batch":    {"app": "batch"},
+				} {
+					objects = append(objects, &appsv1.Deployment{
+						ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: ns, Labels: labels},
+						Spec:       createDeploymentSpec(),
+					})
+				}
+			}
+			fakeClient := fake.NewClientBuilder().
+				WithScheme(scheme).
+				WithObjects(objects...).
+				WithStatusSubresource(vpaManager).
+				Build()
+			reconciler := &VpaManagerReconciler{Client: fakeClient, Scheme: scheme, Metrics: createTestMetrics(), WorkloadConfigs: DefaultWorkloadConfigs()}
+
+			_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: "test-vpamanager"}})
+			require.NoError(t, err)
+
+			vpaList := newVPAList()
+			require.NoError(t, fakeClient.List(ctx, vpaList))
+			var names []string
+			for _, item := range vpaList.Items {
+				names = append(names, item.GetNamespace()+"/"+item.GetName())
+			}
+			assert.ElementsMatch(t, tt.expectedVPAs, names)
+
+			updated := &autoscalingv1.VpaManager{}
+			require.NoError(t, fakeClient.Get(ctx, types.NamespacedName{Name: "test-vpamanager"}, updated))
+			assert.Equal(t, len(tt.expectedVPAs), updated.Status.ManagedVPAs)
+		})
+	}
+}
+
+// Test: the decision log traces a VPA from creation to its deletion as an orphan
+func TestReconcile_RecordsDecisions(t *testing.T) {
+	scheme := setupScheme(t)
+	ctx := context.Background()
+
+	selected := map[string]string{"vpa-enabled": "true"}
+	vpaManager := &autoscalingv1.VpaManager{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-vpamanager"},
+		Spec: autoscalingv1.VpaManagerSpec{
+			Enabled:            true,
+			UpdateMode:         "Off",
+			NamespaceSelector:  &metav1.LabelSelector{MatchLabels: selected},
+			DeploymentSelector: &metav1.LabelSelector{MatchLabels: selected},
+		},
+	}
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-deployment", Namespace: "test-ns", Labels: selected},
+		Spec:       createDeploymentSpec(),
+	}
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(vpaManager, deployment, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "test-ns", Labels: selected}}).
+		WithStatusSubresource(vpaManager).
+		Build()
+	reconciler := &VpaManagerReconciler{
+		Client:          fakeClient,
+		Scheme:          scheme,
+		Metrics:         createTestMetrics(),
+		WorkloadConfigs: DefaultWorkloadConfigs(),
+		Decisions:       decisions.NewLog(10),
+	}
+	req := reconcile.Request{NamespacedName: types.NamespacedName{Name: "test-vpamanager"}}
+
+	_, err := reconciler.Reconcile(ctx, req)
+	require.NoError(t, err)
+	// An unchanged VPA records nothing
+	_, err = reconciler.Reconcile(ctx, req)
+	require.NoError(t, err)
+
+	require.NoError(t, fakeClient.Get(ctx, client.ObjectKeyFromObject(deployment), deployment))
+	deployment.Labels = nil
+	require.NoError(t, fakeClient.Update(ctx, deployment))
+	_, err = reconciler.Reconcile(ctx, req)
+	require.NoError(t, err)
+
+	got := reconciler.Decisions.Decisions()
+	require.Len(t, got, 2)
+	assert.Equal(t, decisions.Decision{
+		Time:       got[0].Time,
+		Source:     decisions.SourceReconciler,
+		VpaManager: "test-vpamanager",
+		Kind:       "Deployment",
+		Namespace:  "test-ns",
+		Name:       "test-deployment",
+		VPA:        "test-deployment-vpa",
+		Action:     decisions.ActionCreated,
+		Reason:     "Selected",
+	}, got[0])
+	assert.Equal(t, decisions.Decision{
+		Time:       got[1].Time,
+		Source:     decisions.SourceReconciler,
+		VpaManager: "test-vpamanager",
+		Kind:       "Deployment",
+		Namespace:  "test-ns",
+		Name:       "test-deployment",
+		VPA:        "test-deployment-vpa",
+		Action:     decisions.ActionDeleted,
+		Reason:     "NoLongerSelected",
+	}, got[1])
+}
+
+// Test: a workload carrying the exclude annotation gets no VPA, and one it had is deleted
+func TestReconcile_ExcludeAnnotation(t *testing.T) {
+	scheme := setupScheme(t)
+	ctx := context.Background()
+
+	selected := map[string]string{"vpa-enabled": "true"}
+	vpaManager := &autoscalingv1.VpaManager{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-vpamanager"},
+		Spec: autoscalingv1.VpaManagerSpec{
+			Enabled:            true,
+			UpdateMode:         "Off",
+			NamespaceSelector:  &metav1.LabelSelector{MatchLabels: selected},
+			DeploymentSelector: &metav1.LabelSelector{MatchLabels: selected},
+		},
+	}
+	kept := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "kept", Namespace: "test-ns", Labels: selected},
+		Spec:       createDeploymentSpec(),
+	}
+	optedOut := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "opted-out", Namespace: "test-ns", Labels: selected},
+		Spec:       createDeploymentSpec(),
+	}
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(vpaManager, kept, optedOut, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "test-ns", Labels: selected}}).
+		WithStatusSubresource(vpaManager).
+		Build()
+	reconciler := &VpaManagerReconciler{Client: fakeClient, Scheme: scheme, Metrics: createTestMetrics(), WorkloadConfigs: DefaultWorkloadConfigs()}
+	req := reconcile.Request{NamespacedName: types.NamespacedName{Name: "test-vpamanager"}}
+
+	vpaNames := func() []string {
+		vpaList := newVPAList()
+		require.NoError(t, fakeClient.List(ctx, vpaList, client.InNamespace("test-ns")))
+		var names []string
+		for _, item := range vpaList.Items {
+			names = append(names, item.GetName())
+		}
+		return names
+	}
+
+	_, err := reconciler.Reconcile(ctx, req)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"kept-vpa", "opted-out-vpa"}, vpaNames())
+
+	require.NoError(t, fakeClient.Get(ctx, client.ObjectKeyFromObject(optedOut), optedOut))
+	optedOut.Annotations = map[string]string{workload.ExcludeAnnotation: "true"}
+	require.NoError(t, fakeClient.Update(ctx, optedOut))
+
+	_, err = reconciler.Reconcile(ctx, req)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"kept-vpa"}, vpaNames())
+
+	updated := &autoscalingv1.VpaManager{}
+	require.NoError(t, fakeClient.Get(ctx, req.NamespacedName, updated))
+	assert.Equal(t, 1, updated.Status.ManagedVPAs)
+}
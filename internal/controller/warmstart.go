@@ -0,0 +1,51 @@
+package controller
+
+import (
+	"context"
+
+	autoscalingv1 "github.com/joaomo/k8s_op_vpa/api/v1"
+	"github.com/joaomo/k8s_op_vpa/internal/decisions"
+	"github.com/joaomo/k8s_op_vpa/internal/vpa"
+	"github.com/joaomo/k8s_op_vpa/internal/workload"
+)
+
+// previousVPA returns the VPA a workload is migrated from when CheckpointWarmStart is set:
+// another VPA, e.g. a hand-made one, that targets the workload under a different name.
+// Failures are logged and only cost the warm start.
+func (r *VpaManagerReconciler) previousVPA(ctx context.Context, wl workload.Workload, vpaName string) string {
+	if !r.CheckpointWarmStart {
+		return ""
+	}
+	previous, err := vpa.PreviousVPA(ctx, r.Client, wl.GetNamespace(), wl.GetKind(), wl.GetName(), vpaName)
+	if err != nil {
+		r.Log.Error(err, "unable to look for a previous VPA, not warm-starting", "namespace", wl.GetNamespace(), "vpa", vpaName)
+		return ""
+	}
+	return previous
+}
+
+// warmStart copies the recommender checkpoints of the previous VPA to the VPA just created
+// for the same workload, so the recommender keeps its usage history across the migration.
+// The previous VPA is then switched to updateMode Off, so the two VPAs do not both act on
+// the workload's pods.
+func (r *VpaManagerReconciler) warmStart(ctx context.Context, vpaManager *autoscalingv1.VpaManager, wl workload.Workload, previous, vpaName string) {
+	namespace := wl.GetNamespace()
+	copied, err := vpa.CopyCheckpoints(ctx, r.Client, namespace, previous, vpaName)
+	r.Metrics.RecordCheckpointsCopied(vpaManager.Name, copied)
+	if err != nil {
+		r.Log.Error(err, "unable to copy VPA checkpoints, the recommender starts without history",
+			"namespace", namespace, "from", previous, "vpa", vpaName)
+	} else {
+		r.Log.Info("warm-started VPA from the checkpoints of the previous VPA", "namespace", namespace,
+			"from", previous, "vpa", vpaName, "checkpoints", copied)
+	}
+
+	if err := vpa.Supersede(ctx, r.Client, namespace, previous, vpaName); err != nil {
+		r.Log.Error(err, "unable to disable the previous VPA, two VPAs target the workload",
+			"namespace", namespace, "previous", previous, "vpa", vpaName)
+		r.recordDecision(vpaManager, wl, previous, decisions.ActionFailed, "SupersedeFailed")
+		return
+	}
+	r.Log.Info("disabled the previous VPA of the workload", "namespace", namespace, "previous", previous, "vpa", vpaName)
+	r.recordDecision(vpaManager, wl, previous, decisions.ActionUpdated, "Superseded")
+}
@@ -0,0 +1,114 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	autoscalingv1 "github.com/joaomo/k8s_op_vpa/api/v1"
+	"github.com/joaomo/k8s_op_vpa/internal/vpa"
+)
+
+// Test: a VPA created for a workload that a hand-made VPA targets starts from the
+// recommender checkpoints of that VPA, which is switched Off
+func TestReconcile_WarmStartsFromPreviousVPA(t *testing.T) {
+	tests := []struct {
+		name                string
+		warmStart           bool
+		expectedCheckpoints int
+	}{
+		{name: "enabled", warmStart: true, expectedCheckpoints: 2},
+		{name: "disabled", warmStart: false, expectedCheckpoints: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheme := setupScheme(t)
+			ctx := context.Background()
+
+			selected := map[string]string{"vpa-enabled": "true"}
+			vpaManager := &autoscalingv1.VpaManager{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-vpamanager"},
+				Spec: autoscalingv1.VpaManagerSpec{
+					Enabled:            true,
+					UpdateMode:         "Off",
+					NamespaceSelector:  &metav1.LabelSelector{MatchLabels: selected},
+					DeploymentSelector: &metav1.LabelSelector{MatchLabels: selected},
+				},
+			}
+			handMade := createUnstructuredVPA("api-legacy", "test-ns", "api")
+			handMade.SetLabels(nil)
+			handMade.Object["spec"].(map[string]interface{})["updatePolicy"] = map[string]interface{}{"updateMode": "Auto"}
+			checkpoint := &unstructured.Unstructured{Object: map[string]interface{}{
+				"spec":   map[string]interface{}{"vpaObjectName": "api-legacy", "containerName": "app"},
+				"status": map[string]interface{}{"totalSamplesCount": int64(42)},
+			}}
+			checkpoint.SetAPIVersion("autoscaling.k8s.io/v1")
+			checkpoint.SetKind("VerticalPodAutoscalerCheckpoint")
+			checkpoint.SetName("api-legacy-app")
+			checkpoint.SetNamespace("test-ns")
+
+			fakeClient := fake.NewClientBuilder().
+				WithScheme(scheme).
+				WithObjects(
+					vpaManager,
+					&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "test-ns", Labels: selected}},
+					&appsv1.Deployment{
+						ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "test-ns", Labels: selected},
+						Spec:       createDeploymentSpec(),
+					},
+					handMade,
+					checkpoint,
+				).
+				WithStatusSubresource(vpaManager).
+				Build()
+			m := createTestMetrics()
+			reconciler := &VpaManagerReconciler{
+				Client:              fakeClient,
+				Scheme:              scheme,
+				Metrics:             m,
+				WorkloadConfigs:     DefaultWorkloadConfigs(),
+				CheckpointWarmStart: tt.warmStart,
+			}
+
+			_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: "test-vpamanager"}})
+			require.NoError(t, err)
+
+			created := &unstructured.Unstructured{}
+			created.SetGroupVersionKind(vpaGVK)
+			require.NoError(t, fakeClient.Get(ctx, types.NamespacedName{Namespace: "test-ns", Name: "api-vpa"}, created))
+
+			checkpoints := &unstructured.UnstructuredList{}
+			checkpoints.SetAPIVersion("autoscaling.k8s.io/v1")
+			checkpoints.SetKind("VerticalPodAutoscalerCheckpointList")
+			require.NoError(t, fakeClient.List(ctx, checkpoints))
+			assert.Len(t, checkpoints.Items, tt.expectedCheckpoints)
+
+			previous := &unstructured.Unstructured{}
+			previous.SetGroupVersionKind(vpaGVK)
+			require.NoError(t, fakeClient.Get(ctx, types.NamespacedName{Namespace: "test-ns", Name: "api-legacy"}, previous))
+			previousSpec, _ := previous.Object["spec"].(map[string]interface{})
+
+			if tt.warmStart {
+				assert.Equal(t, "api-legacy", created.GetAnnotations()[vpa.WarmStartedFromAnnotation])
+				assert.Equal(t, float64(1), testutil.ToFloat64(m.CheckpointsCopiedTotal.WithLabelValues("test-vpamanager")))
+				assert.Equal(t, "Off", vpa.UpdateMode(previousSpec))
+				assert.Equal(t, "api-vpa", previous.GetAnnotations()[vpa.SupersededByAnnotation])
+			} else {
+				assert.NotContains(t, created.GetAnnotations(), vpa.WarmStartedFromAnnotation)
+				assert.Equal(t, "Auto", vpa.UpdateMode(previousSpec))
+				assert.NotContains(t, previous.GetAnnotations(), vpa.SupersededByAnnotation)
+			}
+		})
+	}
+}
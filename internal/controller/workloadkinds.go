@@ -0,0 +1,144 @@
+package controller
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	ctrlbuilder "sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	"github.com/joaomo/k8s_op_vpa/internal/metrics"
+)
+
+// DefaultKindRecheckInterval is how often workload kinds whose API is missing are checked again
+const DefaultKindRecheckInterval = 5 * time.Minute
+
+// workloadKinds records which configured workload kinds the API server serves. Kinds are
+// only ever enabled: a kind whose API appears is watched from then on. A nil workloadKinds
+// enables every kind.
+type workloadKinds struct {
+	mu      sync.RWMutex
+	enabled map[string]bool
+}
+
+// Enabled reports whether workloads of kind are watched and managed
+func (k *workloadKinds) Enabled(kind string) bool {
+	if k == nil {
+		return true
+	}
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	return k.enabled[kind]
+}
+
+// enable marks kind as served
+func (k *workloadKinds) enable(kind string) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.enabled[kind] = true
+}
+
+// kindServed returns an error when the API server does not serve the provider's kind
+func kindServed(mapper meta.RESTMapper, scheme *runtime.Scheme, wc WorkloadConfig) error {
+	gvk, err := apiutil.GVKForObject(wc.Provider.NewObject(), scheme)
+	if err != nil {
+		return err
+	}
+	_, err = mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	return err
+}
+
+// kindWatcher periodically checks the workload kinds that were not served when the
+// controller was set up, and starts watching each one as soon as its API appears.
+// Reconciles include the kind from then on; the watch's initial events enqueue the
+// VpaManagers that select its workloads.
+type kindWatcher struct {
+	controller controller.Controller
+	cache      cache.Cache
+	mapper     meta.RESTMapper
+	scheme     *runtime.Scheme
+	kinds      *workloadKinds
+	metrics    *metrics.Metrics
+	// topologyGuard passes the workload updates the topology guard depends on
+	topologyGuard bool
+	handler       handler.EventHandler
+	interval      time.Duration
+	missing       []WorkloadConfig
+	log           logr.Logger
+}
+
+// Start rechecks the missing kinds until all of them are watched
+func (w *kindWatcher) Start(ctx context.Context) error {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for len(w.missing) > 0 {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			w.missing = w.recheck()
+		}
+	}
+	return nil
+}
+
+// NeedLeaderElection ties the watcher to the controller it adds watches to
+func (w *kindWatcher) NeedLeaderElection() bool {
+	return true
+}
+
+// recheck starts watching every missing kind that is served now and returns the rest
+func (w *kindWatcher) recheck() []WorkloadConfig {
+	var stillMissing []WorkloadConfig
+	for _, wc := range w.missing {
+		kind := wc.Provider.Kind()
+		if err := kindServed(w.mapper, w.scheme, wc); err != nil {
+			stillMissing = append(stillMissing, wc)
+			continue
+		}
+		src := source.Kind(w.cache, wc.Provider.NewObject())
+		if err := w.controller.Watch(src, w.handler, workloadChangePredicate(w.topologyGuard)); err != nil {
+			w.log.Error(err, "unable to watch workload kind", "kind", kind)
+			stillMissing = append(stillMissing, wc)
+			continue
+		}
+		w.kinds.enable(kind)
+		w.metrics.SetWorkloadKindEnabled(kind, true)
+		w.log.Info("workload kind is served now, managing it", "kind", kind)
+	}
+	return stillMissing
+}
+
+// setupWorkloadWatches watches every configured workload kind the API server serves and
+// returns the kinds it does not, so one build runs on clusters with different APIs
+func (r *VpaManagerReconciler) setupWorkloadWatches(mgr ctrl.Manager, builder *ctrlbuilder.Builder) (*ctrlbuilder.Builder, []WorkloadConfig) {
+	r.kinds = &workloadKinds{enabled: map[string]bool{}}
+	var missing []WorkloadConfig
+	for _, wc := range r.WorkloadConfigs {
+		kind := wc.Provider.Kind()
+		if err := kindServed(mgr.GetRESTMapper(), mgr.GetScheme(), wc); err != nil {
+			r.Log.Info("workload kind is not served, not managing it", "kind", kind, "reason", err.Error())
+			r.Metrics.SetWorkloadKindEnabled(kind, false)
+			missing = append(missing, wc)
+			continue
+		}
+		r.kinds.enable(kind)
+		r.Metrics.SetWorkloadKindEnabled(kind, true)
+		builder = builder.Watches(
+			wc.Provider.NewObject(),
+			handler.EnqueueRequestsFromMapFunc(r.findVpaManagersForWorkload),
+			ctrlbuilder.WithPredicates(workloadChangePredicate(r.TopologyGuard)),
+		)
+	}
+	return builder, missing
+}
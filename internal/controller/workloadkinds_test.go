@@ -0,0 +1,128 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	autoscalingv1 "github.com/joaomo/k8s_op_vpa/api/v1"
+)
+
+// watchRecorder is a controller that only records the watches added to it
+type watchRecorder struct {
+	controller.Controller
+	watches int
+}
+
+func (w *watchRecorder) Watch(_ source.Source, _ handler.EventHandler, _ ...predicate.Predicate) error {
+	w.watches++
+	return nil
+}
+
+// Test: workload kinds the API server does not serve are left out of reconciles
+func TestReconcile_SkipsUnservedWorkloadKinds(t *testing.T) {
+	scheme := setupScheme(t)
+	ctx := context.Background()
+
+	selected := map[string]string{"vpa-enabled": "true"}
+	vpaManager := &autoscalingv1.VpaManager{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-vpamanager"},
+		Spec: autoscalingv1.VpaManagerSpec{
+			Enabled:             true,
+			UpdateMode:          "Off",
+			NamespaceSelector:   &metav1.LabelSelector{MatchLabels: selected},
+			DeploymentSelector:  &metav1.LabelSelector{MatchLabels: selected},
+			StatefulSetSelector: &metav1.LabelSelector{MatchLabels: selected},
+		},
+	}
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(
+			vpaManager,
+			&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "test-ns", Labels: selected}},
+			&appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "test-ns", Labels: selected},
+				Spec:       createDeploymentSpec(),
+			},
+			&appsv1.StatefulSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "test-ns", Labels: selected},
+				Spec:       createStatefulSetSpec(),
+			},
+		).
+		WithStatusSubresource(vpaManager).
+		Build()
+	reconciler := &VpaManagerReconciler{
+		Client:          fakeClient,
+		Scheme:          scheme,
+		Metrics:         createTestMetrics(),
+		WorkloadConfigs: DefaultWorkloadConfigs(),
+		kinds:           &workloadKinds{enabled: map[string]bool{"Deployment": true}},
+	}
+
+	_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: "test-vpamanager"}})
+	require.NoError(t, err)
+
+	vpaList := newVPAList()
+	require.NoError(t, fakeClient.List(ctx, vpaList))
+	names := []string{}
+	for _, item := range vpaList.Items {
+		names = append(names, item.GetName())
+	}
+	assert.Equal(t, []string{"api-vpa"}, names)
+}
+
+// Test: the kind watcher starts watching kinds once their API is served
+func TestKindWatcher_Recheck(t *testing.T) {
+	tests := []struct {
+		name            string
+		served          bool
+		expectedWatches int
+		expectedMissing int
+	}{
+		{name: "still missing", expectedMissing: 1},
+		{name: "served now", served: true, expectedWatches: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheme := setupScheme(t)
+			mapper := meta.NewDefaultRESTMapper(nil)
+			if tt.served {
+				mapper.Add(appsv1.SchemeGroupVersion.WithKind("StatefulSet"), meta.RESTScopeNamespace)
+			}
+			statefulSets := DefaultWorkloadConfigs()[1]
+			recorder := &watchRecorder{}
+			m := createTestMetrics()
+			watcher := &kindWatcher{
+				controller: recorder,
+				mapper:     mapper,
+				scheme:     scheme,
+				kinds:      &workloadKinds{enabled: map[string]bool{}},
+				metrics:    m,
+				missing:    []WorkloadConfig{statefulSets},
+			}
+
+			watcher.missing = watcher.recheck()
+			assert.Len(t, watcher.missing, tt.expectedMissing)
+			assert.Equal(t, tt.expectedWatches, recorder.watches)
+			assert.Equal(t, tt.served, watcher.kinds.Enabled("StatefulSet"))
+			if tt.served {
+				assert.Equal(t, float64(1), testutil.ToFloat64(m.WorkloadKindEnabled.WithLabelValues("StatefulSet")))
+			}
+		})
+	}
+}
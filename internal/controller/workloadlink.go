@@ -0,0 +1,44 @@
+package controller
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/joaomo/k8s_op_vpa/internal/vpa"
+)
+
+// linkWorkload patches the VPA link annotation of a workload to value, or removes it when
+// value is empty. Nothing is written when the annotation already holds value.
+func (r *VpaManagerReconciler) linkWorkload(ctx context.Context, obj client.Object, value string) error {
+	patched := obj.DeepCopyObject().(client.Object)
+	if !vpa.SetWorkloadVPA(patched, value) {
+		return nil
+	}
+	return r.Patch(ctx, patched, client.MergeFrom(obj))
+}
+
+// unlinkWorkload removes the VPA link annotation from the target of a deleted VPA, unless
+// it already names another VPA. A target that is gone needs no cleanup.
+func (r *VpaManagerReconciler) unlinkWorkload(ctx context.Context, vpaObj *unstructured.Unstructured) error {
+	kind, _, _ := unstructured.NestedString(vpaObj.Object, "spec", "targetRef", "kind")
+	name, _, _ := unstructured.NestedString(vpaObj.Object, "spec", "targetRef", "name")
+	wc, ok := workloadConfigIn(r.WorkloadConfigs, kind)
+	if !ok || name == "" {
+		return nil
+	}
+	wl, err := wc.Provider.Get(ctx, r.Client, vpaObj.GetNamespace(), name)
+	if err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	value, ok := wl.GetObject().GetAnnotations()[vpa.WorkloadVPAAnnotation]
+	if !ok || vpa.WorkloadVPAName(value) != vpaObj.GetName() {
+		return nil
+	}
+	if err := r.linkWorkload(ctx, wl.GetObject(), ""); err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	autoscalingv1 "github.com/joaomo/k8s_op_vpa/api/v1"
+	"github.com/joaomo/k8s_op_vpa/internal/vpa"
+)
+
+// Test: managed workloads are annotated with their VPA, patched only on change, and the
+// annotation is removed with an orphaned VPA
+func TestReconcile_AnnotatesWorkloads(t *testing.T) {
+	tests := []struct {
+		name     string
+		annotate bool
+		expected map[string]string
+	}{
+		{
+			name:     "enabled",
+			annotate: true,
+			expected: map[string]string{vpa.WorkloadVPAAnnotation: "api-vpa,mode=Off"},
+		},
+		{
+			name: "disabled",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheme := setupScheme(t)
+			ctx := context.Background()
+
+			selected := map[string]string{"vpa-enabled": "true"}
+			vpaManager := &autoscalingv1.VpaManager{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-vpamanager"},
+				Spec: autoscalingv1.VpaManagerSpec{
+					Enabled:            true,
+					UpdateMode:         "Off",
+					NamespaceSelector:  &metav1.LabelSelector{MatchLabels: selected},
+					DeploymentSelector: &metav1.LabelSelector{MatchLabels: selected},
+				},
+			}
+			fakeClient := fake.NewClientBuilder().
+				WithScheme(scheme).
+				WithObjects(
+					vpaManager,
+					&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "test-ns", Labels: selected}},
+					&appsv1.Deployment{
+						ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "test-ns", Labels: selected, UID: "api-uid"},
+						Spec:       createDeploymentSpec(),
+					},
+				).
+				WithStatusSubresource(vpaManager).
+				Build()
+			reconciler := &VpaManagerReconciler{
+				Client:            fakeClient,
+				Scheme:            scheme,
+				Metrics:           createTestMetrics(),
+				WorkloadConfigs:   DefaultWorkloadConfigs(),
+				AnnotateWorkloads: tt.annotate,
+			}
+			request := reconcile.Request{NamespacedName: types.NamespacedName{Name: "test-vpamanager"}}
+			key := types.NamespacedName{Name: "api", Namespace: "test-ns"}
+
+			_, err := reconciler.Reconcile(ctx, request)
+			require.NoError(t, err)
+			deployment := &appsv1.Deployment{}
+			require.NoError(t, fakeClient.Get(ctx, key, deployment))
+			assert.Equal(t, tt.expected, deployment.Annotations)
+
+			// An unchanged annotation is not patched again
+			resourceVersion := deployment.ResourceVersion
+			_, err = reconciler.Reconcile(ctx, request)
+			require.NoError(t, err)
+			require.NoError(t, fakeClient.Get(ctx, key, deployment))
+			assert.Equal(t, resourceVersion, deployment.ResourceVersion)
+
+			// Deselecting the workload deletes its VPA and the annotation
+			deployment.Labels = nil
+			require.NoError(t, fakeClient.Update(ctx, deployment))
+			_, err = reconciler.Reconcile(ctx, request)
+			require.NoError(t, err)
+			require.NoError(t, fakeClient.Get(ctx, key, deployment))
+			assert.Empty(t, deployment.Annotations)
+			vpaList := newVPAList()
+			require.NoError(t, fakeClient.List(ctx, vpaList))
+			assert.Empty(t, vpaList.Items)
+		})
+	}
+}
@@ -0,0 +1,182 @@
+// Package decisions keeps the recent decisions the reconciler, the orphan sweep and the
+// webhooks made about the VPA of a workload in memory, so the fate of a VPA can be traced
+// after the fact without raising the log verbosity in advance.
+package decisions
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/go-logr/logr"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// Path is where the decision log is served on the metrics server
+const Path = "/debug/decisions"
+
+// DefaultSize is the default number of decisions kept
+const DefaultSize = 1000
+
+// Actions taken on the VPA of a workload
+const (
+	ActionCreated  = "created"
+	ActionUpdated  = "updated"
+	ActionReset    = "reset"
+	ActionDeleted  = "deleted"
+	ActionSkipped  = "skipped"
+	ActionFailed   = "failed"
+	ActionHeldBack = "heldBack"
+)
+
+// Sources of decisions
+const (
+	SourceReconciler = "reconciler"
+	SourceSweep      = "sweep"
+	SourceWebhook    = "webhook"
+)
+
+// Decision is what was done with the VPA of one workload, and why
+type Decision struct {
+	Time       time.Time `json:"time"`
+	Source     string    `json:"source"`
+	VpaManager string    `json:"vpaManager,omitempty"`
+	Kind       string    `json:"kind,omitempty"`
+	Namespace  string    `json:"namespace"`
+	// Name is the workload, empty when only the VPA is known, e.g. for orphans
+	Name   string `json:"name,omitempty"`
+	VPA    string `json:"vpa,omitempty"`
+	Action string `json:"action"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// Log is a ring buffer of the last decisions. A nil Log records nothing.
+type Log struct {
+	mu      sync.Mutex
+	entries []Decision
+	next    int
+	full    bool
+}
+
+// NewLog returns a Log keeping the last size decisions, nil when size is not positive
+func NewLog(size int) *Log {
+	if size <= 0 {
+		return nil
+	}
+	return &Log{entries: make([]Decision, size)}
+}
+
+// Record adds a decision, overwriting the oldest once the log is full. A zero Time is set to now.
+func (l *Log) Record(d Decision) {
+	if l == nil {
+		return
+	}
+	if d.Time.IsZero() {
+		d.Time = time.Now()
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.entries[l.next] = d
+	l.next = (l.next + 1) % len(l.entries)
+	if l.next == 0 {
+		l.full = true
+	}
+}
+
+// Decisions returns the kept decisions, oldest first
+func (l *Log) Decisions() []Decision {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if !l.full {
+		return append([]Decision(nil), l.entries[:l.next]...)
+	}
+	out := make([]Decision, 0, len(l.entries))
+	out = append(out, l.entries[l.next:]...)
+	return append(out, l.entries[:l.next]...)
+}
+
+// Filter selects decisions; empty fields match everything
+type Filter struct {
+	VpaManager string
+	Namespace  string
+	// Name matches the workload or the VPA name
+	Name string
+}
+
+// Matches reports whether a decision passes the filter
+func (f Filter) Matches(d Decision) bool {
+	if f.VpaManager != "" && d.VpaManager != f.VpaManager {
+		return false
+	}
+	if f.Namespace != "" && d.Namespace != f.Namespace {
+		return false
+	}
+	return f.Name == "" || d.Name == f.Name || d.VPA == f.Name
+}
+
+// ServeHTTP serves the decisions as a JSON array, oldest first, filtered by the vpamanager,
+// namespace and name query parameters
+func (l *Log) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		http.Error(w, "only GET is supported", http.StatusMethodNotAllowed)
+		return
+	}
+	query := req.URL.Query()
+	filter := Filter{VpaManager: query.Get("vpamanager"), Namespace: query.Get("namespace"), Name: query.Get("name")}
+	out := []Decision{}
+	for _, d := range l.Decisions() {
+		if filter.Matches(d) {
+			out = append(out, d)
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(out); err != nil {
+		ctrl.LoggerFrom(req.Context()).Error(err, "failed to write decisions")
+	}
+}
+
+// Dump logs every kept decision, oldest first
+func (l *Log) Dump(log logr.Logger) {
+	kept := l.Decisions()
+	log.Info("dumping decision log", "decisions", len(kept))
+	for _, d := range kept {
+		log.Info("decision", "time", d.Time, "source", d.Source, "vpaManager", d.VpaManager, "kind", d.Kind,
+			"namespace", d.Namespace, "name", d.Name, "vpa", d.VPA, "action", d.Action, "reason", d.Reason)
+	}
+}
+
+// SignalDumper dumps the decision log whenever the process receives SIGUSR1
+type SignalDumper struct {
+	Log    *Log
+	Logger logr.Logger
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable; every replica dumps its own log
+func (d *SignalDumper) NeedLeaderElection() bool {
+	return false
+}
+
+// Start implements manager.Runnable
+func (d *SignalDumper) Start(ctx context.Context) error {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGUSR1)
+	defer signal.Stop(signals)
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-signals:
+			d.Log.Dump(d.Logger)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package decisions
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLog_Decisions(t *testing.T) {
+	tests := []struct {
+		name     string
+		size     int
+		recorded int
+		want     []string
+	}{
+		{name: "empty", size: 3},
+		{name: "partially filled", size: 3, recorded: 2, want: []string{"wl-0", "wl-1"}},
+		{name: "exactly full", size: 3, recorded: 3, want: []string{"wl-0", "wl-1", "wl-2"}},
+		{name: "wrapped around", size: 3, recorded: 5, want: []string{"wl-2", "wl-3", "wl-4"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := NewLog(tt.size)
+			for i := 0; i < tt.recorded; i++ {
+				l.Record(Decision{Name: fmt.Sprintf("wl-%d", i), Action: ActionCreated})
+			}
+
+			var got []string
+			for _, d := range l.Decisions() {
+				assert.False(t, d.Time.IsZero())
+				got = append(got, d.Name)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestLog_Disabled(t *testing.T) {
+	l := NewLog(0)
+	assert.Nil(t, l)
+
+	// A nil log records nothing and does not panic
+	l.Record(Decision{Name: "web"})
+	assert.Empty(t, l.Decisions())
+}
+
+func TestLog_ServeHTTP(t *testing.T) {
+	l := NewLog(10)
+	l.Record(Decision{VpaManager: "prod", Namespace: "shop", Name: "web", VPA: "web-vpa", Action: ActionCreated})
+	l.Record(Decision{VpaManager: "prod", Namespace: "shop", VPA: "cart-vpa", Action: ActionDeleted})
+	l.Record(Decision{VpaManager: "dev", Namespace: "sandbox", Name: "web", VPA: "web-vpa", Action: ActionSkipped})
+
+	tests := []struct {
+		name       string
+		method     string
+		query      string
+		wantStatus int
+		want       []string
+	}{
+		{name: "all", method: http.MethodGet, wantStatus: http.StatusOK, want: []string{ActionCreated, ActionDeleted, ActionSkipped}},
+		{name: "by VpaManager", method: http.MethodGet, query: "?vpamanager=prod", wantStatus: http.StatusOK, want: []string{ActionCreated, ActionDeleted}},
+		{name: "by namespace and workload", method: http.MethodGet, query: "?namespace=shop&name=web", wantStatus: http.StatusOK, want: []string{ActionCreated}},
+		{name: "by VPA name", method: http.MethodGet, query: "?name=cart-vpa", wantStatus: http.StatusOK, want: []string{ActionDeleted}},
+		{name: "no match", method: http.MethodGet, query: "?namespace=other", wantStatus: http.StatusOK, want: []string{}},
+		{name: "wrong method", method: http.MethodPost, wantStatus: http.StatusMethodNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			l.ServeHTTP(rec, httptest.NewRequest(tt.method, Path+tt.query, nil))
+
+			require.Equal(t, tt.wantStatus, rec.Code)
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			var got []Decision
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &got))
+			actions := []string{}
+			for _, d := range got {
+				actions = append(actions, d.Action)
+			}
+			assert.Equal(t, tt.want, actions)
+		})
+	}
+}
@@ -0,0 +1,127 @@
+package export
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// Exporter ships snapshots to an external system
+type Exporter interface {
+	Export(ctx context.Context, snapshots []Snapshot) error
+}
+
+// DefaultTimeout bounds an export request when HTTPExporter has no Client
+const DefaultTimeout = 30 * time.Second
+
+var defaultClient = &http.Client{Timeout: DefaultTimeout}
+
+// HTTPExporter posts snapshots as a JSON array to a URL
+type HTTPExporter struct {
+	URL     string
+	Headers map[string]string
+	// Client sends the requests; nil uses a client with DefaultTimeout
+	Client *http.Client
+}
+
+// ParseHeader parses an HTTP header given as "Name: value"
+func ParseHeader(header string) (string, string, error) {
+	name, value, ok := strings.Cut(header, ":")
+	name = strings.TrimSpace(name)
+	if !ok || name == "" || strings.ContainsAny(name, " \t") {
+		return "", "", fmt.Errorf("invalid header %q, expected \"Name: value\"", header)
+	}
+	return name, strings.TrimSpace(value), nil
+}
+
+// Export implements the Exporter interface
+func (e *HTTPExporter) Export(ctx context.Context, snapshots []Snapshot) error {
+	body, err := json.Marshal(snapshots)
+	if err != nil {
+		return fmt.Errorf("failed to encode snapshots: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range e.Headers {
+		req.Header.Set(k, v)
+	}
+
+	httpClient := e.Client
+	if httpClient == nil {
+		httpClient = defaultClient
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("export endpoint returned %s", resp.Status)
+	}
+	return nil
+}
+
+// Runner periodically collects and exports snapshots, it implements manager.Runnable
+type Runner struct {
+	Collector *Collector
+	Exporter  Exporter
+	Interval  time.Duration
+	Log       logr.Logger
+}
+
+// Start runs the export loop until the context is cancelled
+func (r *Runner) Start(ctx context.Context) error {
+	if r.Log.GetSink() == nil {
+		r.Log = ctrl.Log.WithName("export")
+	}
+
+	ticker := time.NewTicker(r.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			r.runOnce(ctx)
+		}
+	}
+}
+
+// NeedLeaderElection ensures only the leader exports, avoiding duplicate data points
+func (r *Runner) NeedLeaderElection() bool {
+	return true
+}
+
+// runOnce collects and exports a single batch of snapshots. A run never outlasts the
+// interval, so a hanging endpoint cannot stall the following runs.
+func (r *Runner) runOnce(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, r.Interval)
+	defer cancel()
+
+	snapshots, err := r.Collector.Collect(ctx)
+	if err != nil {
+		r.Log.Error(err, "failed to collect snapshots")
+		return
+	}
+	if len(snapshots) == 0 {
+		return
+	}
+	if err := r.Exporter.Export(ctx, snapshots); err != nil {
+		r.Log.Error(err, "failed to export snapshots", "count", len(snapshots))
+		return
+	}
+	r.Log.V(1).Info("exported snapshots", "count", len(snapshots))
+}
@@ -0,0 +1,100 @@
+package export
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTPExporter_PostsSnapshots(t *testing.T) {
+	var received []Snapshot
+	var contentType, auth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		contentType = r.Header.Get("Content-Type")
+		auth = r.Header.Get("Authorization")
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	exporter := &HTTPExporter{
+		URL:     server.URL,
+		Headers: map[string]string{"Authorization": "Bearer token"},
+	}
+	snapshots := []Snapshot{{
+		VpaManager: "default",
+		Namespace:  "test-ns",
+		Kind:       "Deployment",
+		Name:       "web",
+		Container:  "main",
+		Target:     map[string]string{"cpu": "250m"},
+	}}
+
+	err := exporter.Export(context.Background(), snapshots)
+	require.NoError(t, err)
+	assert.Equal(t, "application/json", contentType)
+	assert.Equal(t, "Bearer token", auth)
+	require.Len(t, received, 1)
+	assert.Equal(t, "web", received[0].Name)
+	assert.Equal(t, "250m", received[0].Target["cpu"])
+}
+
+func TestHTTPExporter_ReturnsErrorOnNon2xx(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	exporter := &HTTPExporter{URL: server.URL}
+	err := exporter.Export(context.Background(), []Snapshot{{Name: "web"}})
+	assert.Error(t, err)
+}
+
+func TestParseHeader(t *testing.T) {
+	tests := []struct {
+		header        string
+		expectedName  string
+		expectedValue string
+		expectError   bool
+	}{
+		{header: "Authorization: Bearer token", expectedName: "Authorization", expectedValue: "Bearer token"},
+		{header: "X-Team:payments", expectedName: "X-Team", expectedValue: "payments"},
+		{header: "X-Empty:", expectedName: "X-Empty"},
+		{header: "Authorization", expectError: true},
+		{header: ": value", expectError: true},
+		{header: "X Team: payments", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.header, func(t *testing.T) {
+			name, value, err := ParseHeader(tt.header)
+			if tt.expectError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedName, name)
+			assert.Equal(t, tt.expectedValue, value)
+		})
+	}
+}
+
+func TestHTTPExporter_StopsAtContextDeadline(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	exporter := &HTTPExporter{URL: server.URL}
+	assert.ErrorIs(t, exporter.Export(ctx, []Snapshot{{Name: "web"}}), context.DeadlineExceeded)
+}
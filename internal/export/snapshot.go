@@ -0,0 +1,145 @@
+package export
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/joaomo/k8s_op_vpa/internal/workload"
+)
+
+// Snapshot is a point-in-time view of one container's requests and VPA recommendation
+type Snapshot struct {
+	Timestamp      time.Time         `json:"timestamp"`
+	VpaManager     string            `json:"vpaManager"`
+	Namespace      string            `json:"namespace"`
+	Kind           string            `json:"kind"`
+	Name           string            `json:"name"`
+	Container      string            `json:"container"`
+	Requests       map[string]string `json:"requests,omitempty"`
+	Limits         map[string]string `json:"limits,omitempty"`
+	Target         map[string]string `json:"target,omitempty"`
+	LowerBound     map[string]string `json:"lowerBound,omitempty"`
+	UpperBound     map[string]string `json:"upperBound,omitempty"`
+	UncappedTarget map[string]string `json:"uncappedTarget,omitempty"`
+}
+
+// Collector builds snapshots from the VPAs managed by the operator
+type Collector struct {
+	Client    client.Client
+	Providers []workload.Provider
+	// ManagedByLabels selects the VPAs owned by this operator
+	ManagedByLabels map[string]string
+}
+
+// Collect lists all managed VPAs cluster-wide and returns one snapshot per recommended container
+func (c *Collector) Collect(ctx context.Context) ([]Snapshot, error) {
+	providers := make(map[string]workload.Provider, len(c.Providers))
+	for _, p := range c.Providers {
+		providers[p.Kind()] = p
+	}
+
+	now := time.Now().UTC()
+	var snapshots []Snapshot
+	var continueToken string
+
+	for {
+		vpaList := &unstructured.UnstructuredList{}
+		vpaList.SetGroupVersionKind(schema.GroupVersionKind{
+			Group:   "autoscaling.k8s.io",
+			Version: "v1",
+			Kind:    "VerticalPodAutoscalerList",
+		})
+		opts := []client.ListOption{client.MatchingLabels(c.ManagedByLabels), client.Limit(workload.PageSize)}
+		if continueToken != "" {
+			opts = append(opts, client.Continue(continueToken))
+		}
+		if err := c.Client.List(ctx, vpaList, opts...); err != nil {
+			return nil, fmt.Errorf("failed to list VPAs: %w", err)
+		}
+
+		for i := range vpaList.Items {
+			snapshots = append(snapshots, c.snapshotsForVPA(ctx, providers, &vpaList.Items[i], now)...)
+		}
+
+		continueToken = vpaList.GetContinue()
+		if continueToken == "" {
+			break
+		}
+	}
+
+	return snapshots, nil
+}
+
+// snapshotsForVPA returns snapshots for every container recommended by a VPA
+func (c *Collector) snapshotsForVPA(ctx context.Context, providers map[string]workload.Provider, vpa *unstructured.Unstructured, now time.Time) []Snapshot {
+	kind, _, _ := unstructured.NestedString(vpa.Object, "spec", "targetRef", "kind")
+	name, _, _ := unstructured.NestedString(vpa.Object, "spec", "targetRef", "name")
+	recommendations, _, _ := unstructured.NestedSlice(vpa.Object, "status", "recommendation", "containerRecommendations")
+	if len(recommendations) == 0 {
+		return nil
+	}
+
+	// Requests are best effort, a missing workload still exports the recommendation
+	containers := map[string]corev1.ResourceRequirements{}
+	if provider, ok := providers[kind]; ok {
+		if wl, err := provider.Get(ctx, c.Client, vpa.GetNamespace(), name); err == nil {
+			for _, ctr := range wl.GetPodTemplateSpec().Spec.Containers {
+				containers[ctr.Name] = ctr.Resources
+			}
+		}
+	}
+
+	snapshots := make([]Snapshot, 0, len(recommendations))
+	for _, r := range recommendations {
+		rec, ok := r.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		container, _, _ := unstructured.NestedString(rec, "containerName")
+		snapshot := Snapshot{
+			Timestamp:      now,
+			VpaManager:     vpa.GetLabels()["app.kubernetes.io/created-by"],
+			Namespace:      vpa.GetNamespace(),
+			Kind:           kind,
+			Name:           name,
+			Container:      container,
+			Target:         stringMap(rec, "target"),
+			LowerBound:     stringMap(rec, "lowerBound"),
+			UpperBound:     stringMap(rec, "upperBound"),
+			UncappedTarget: stringMap(rec, "uncappedTarget"),
+		}
+		if res, ok := containers[container]; ok {
+			snapshot.Requests = quantities(res.Requests)
+			snapshot.Limits = quantities(res.Limits)
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+	return snapshots
+}
+
+// stringMap reads a map of resource quantities from a recommendation
+func stringMap(obj map[string]interface{}, field string) map[string]string {
+	m, found, err := unstructured.NestedStringMap(obj, field)
+	if err != nil || !found {
+		return nil
+	}
+	return m
+}
+
+// quantities converts a resource list to its string representation
+func quantities(list corev1.ResourceList) map[string]string {
+	if len(list) == 0 {
+		return nil
+	}
+	m := make(map[string]string, len(list))
+	for name, q := range list {
+		m[string(name)] = q.String()
+	}
+	return m
+}
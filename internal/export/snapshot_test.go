@@ -0,0 +1,98 @@
+package export
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/joaomo/k8s_op_vpa/internal/workload"
+)
+
+func TestCollector_CollectsManagedVPARecommendations(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, appsv1.AddToScheme(scheme))
+
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "test-ns"},
+		Spec: appsv1.DeploymentSpec{
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{
+						Name: "main",
+						Resources: corev1.ResourceRequirements{
+							Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("500m")},
+						},
+					}},
+				},
+			},
+		},
+	}
+
+	managed := newVPA("web-vpa", "test-ns", "web", map[string]string{
+		"app.kubernetes.io/managed-by": "vpa-operator",
+		"app.kubernetes.io/created-by": "default",
+	})
+	// VPAs not created by the operator are ignored
+	unmanaged := newVPA("other-vpa", "test-ns", "other", nil)
+
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(deployment, managed, unmanaged).
+		Build()
+
+	collector := &Collector{
+		Client:          fakeClient,
+		Providers:       []workload.Provider{&workload.DeploymentProvider{}},
+		ManagedByLabels: map[string]string{"app.kubernetes.io/managed-by": "vpa-operator"},
+	}
+
+	snapshots, err := collector.Collect(context.Background())
+	require.NoError(t, err)
+	require.Len(t, snapshots, 1)
+
+	s := snapshots[0]
+	assert.Equal(t, "default", s.VpaManager)
+	assert.Equal(t, "Deployment", s.Kind)
+	assert.Equal(t, "web", s.Name)
+	assert.Equal(t, "main", s.Container)
+	assert.Equal(t, "500m", s.Requests["cpu"])
+	assert.Equal(t, "250m", s.Target["cpu"])
+	assert.Equal(t, "100m", s.LowerBound["cpu"])
+}
+
+func newVPA(name, namespace, target string, labels map[string]string) *unstructured.Unstructured {
+	vpa := &unstructured.Unstructured{}
+	vpa.SetAPIVersion("autoscaling.k8s.io/v1")
+	vpa.SetKind("VerticalPodAutoscaler")
+	vpa.SetName(name)
+	vpa.SetNamespace(namespace)
+	vpa.SetLabels(labels)
+	vpa.Object["spec"] = map[string]interface{}{
+		"targetRef": map[string]interface{}{
+			"apiVersion": "apps/v1",
+			"kind":       "Deployment",
+			"name":       target,
+		},
+	}
+	vpa.Object["status"] = map[string]interface{}{
+		"recommendation": map[string]interface{}{
+			"containerRecommendations": []interface{}{
+				map[string]interface{}{
+					"containerName": "main",
+					"target":        map[string]interface{}{"cpu": "250m"},
+					"lowerBound":    map[string]interface{}{"cpu": "100m"},
+				},
+			},
+		},
+	}
+	return vpa
+}
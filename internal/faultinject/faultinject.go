@@ -0,0 +1,338 @@
+// Package faultinject wraps a controller-runtime client to simulate API server failures:
+// errors, conflicts and slow responses for chosen verbs and resources. It backs the
+// resilience tests and the --fault-injection flag of builds with the faultinjection tag.
+package faultinject
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+// Fault is the API error a rule returns
+type Fault string
+
+const (
+	// FaultConflict returns a 409 Conflict, as for a stale resourceVersion
+	FaultConflict Fault = "conflict"
+	// FaultTimeout returns a 504 server timeout
+	FaultTimeout Fault = "timeout"
+	// FaultForbidden returns a 403 Forbidden, as for missing RBAC
+	FaultForbidden Fault = "forbidden"
+	// FaultNotFound returns a 404 NotFound
+	FaultNotFound Fault = "notfound"
+	// FaultUnavailable returns a 503 Service Unavailable
+	FaultUnavailable Fault = "unavailable"
+)
+
+// Wildcard matches every verb or kind in a rule
+const Wildcard = "*"
+
+// Rule selects API calls and the fault injected into them. Subresource writes use the
+// verb "<verb>-<subresource>", for example "patch-status".
+type Rule struct {
+	// Verb is get, list, create, update, patch, delete or a subresource verb; * matches all
+	Verb string
+	// Kind of the object or of the list items, for example VerticalPodAutoscaler; * matches all
+	Kind string
+	// Namespace and Name restrict the rule to one namespace or object when set
+	Namespace string
+	Name      string
+	// Fault is returned by matching calls; empty only delays them
+	Fault Fault
+	// Delay is waited before the call proceeds or fails, bounded by the call context
+	Delay time.Duration
+	// Times bounds how many calls the rule applies to; 0 applies it to every call
+	Times int
+}
+
+// matches reports whether the rule applies to a call
+func (r *Rule) matches(verb, kind, namespace, name string) bool {
+	return (r.Verb == Wildcard || strings.EqualFold(r.Verb, verb)) &&
+		(r.Kind == Wildcard || strings.EqualFold(r.Kind, kind)) &&
+		(r.Namespace == "" || r.Namespace == namespace) &&
+		(r.Name == "" || r.Name == name)
+}
+
+// ParseRules parses semicolon-separated rules of the form
+// VERB/KIND[/NAMESPACE[/NAME]]:OPTIONS, where OPTIONS are comma-separated
+// error=<fault>, delay=<duration> and times=<count>. For example
+// "update/VerticalPodAutoscaler:error=conflict,times=1;list/Deployment/team-a:delay=2s".
+func ParseRules(spec string) ([]Rule, error) {
+	var rules []Rule
+	for _, item := range strings.Split(spec, ";") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		rule, err := parseRule(item)
+		if err != nil {
+			return nil, fmt.Errorf("fault injection rule %q: %w", item, err)
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// parseRule parses one rule, see ParseRules
+func parseRule(item string) (Rule, error) {
+	target, options, ok := strings.Cut(item, ":")
+	if !ok {
+		return Rule{}, errors.New("missing options after ':'")
+	}
+	parts := strings.Split(target, "/")
+	if len(parts) < 2 || len(parts) > 4 || parts[0] == "" || parts[1] == "" {
+		return Rule{}, errors.New("target must be VERB/KIND[/NAMESPACE[/NAME]]")
+	}
+	rule := Rule{Verb: parts[0], Kind: parts[1]}
+	if len(parts) > 2 {
+		rule.Namespace = parts[2]
+	}
+	if len(parts) > 3 {
+		rule.Name = parts[3]
+	}
+
+	for _, option := range strings.Split(options, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(option), "=")
+		switch key {
+		case "error":
+			switch fault := Fault(value); fault {
+			case FaultConflict, FaultTimeout, FaultForbidden, FaultNotFound, FaultUnavailable:
+				rule.Fault = fault
+			default:
+				return Rule{}, fmt.Errorf("unknown error %q", value)
+			}
+		case "delay":
+			delay, err := time.ParseDuration(value)
+			if err != nil || delay < 0 {
+				return Rule{}, fmt.Errorf("invalid delay %q", value)
+			}
+			rule.Delay = delay
+		case "times":
+			times, err := strconv.Atoi(value)
+			if err != nil || times < 0 {
+				return Rule{}, fmt.Errorf("invalid times %q", value)
+			}
+			rule.Times = times
+		default:
+			return Rule{}, fmt.Errorf("unknown option %q", key)
+		}
+	}
+	if rule.Fault == "" && rule.Delay == 0 {
+		return Rule{}, errors.New("rule needs an error or a delay")
+	}
+	return rule, nil
+}
+
+// Injector applies rules to the calls of the clients it wraps. The first matching rule
+// with calls left applies; later rules are not consulted.
+type Injector struct {
+	mu    sync.Mutex
+	rules []Rule
+	// used counts the calls each rule applied to
+	used []int
+}
+
+// New returns an injector applying rules in order
+func New(rules ...Rule) *Injector {
+	return &Injector{rules: rules, used: make([]int, len(rules))}
+}
+
+// Injected returns how many calls faults were injected into
+func (i *Injector) Injected() int {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	total := 0
+	for _, n := range i.used {
+		total += n
+	}
+	return total
+}
+
+// Wrap returns a client that injects faults into the calls made through c
+func (i *Injector) Wrap(c client.Client) client.Client {
+	return &faultyClient{Client: c, injector: i}
+}
+
+// inject applies the first matching rule to a call, returning the error to fail it with
+func (i *Injector) inject(ctx context.Context, verb string, gvk schema.GroupVersionKind, namespace, name string) error {
+	rule, ok := i.match(verb, gvk.Kind, namespace, name)
+	if !ok {
+		return nil
+	}
+	if rule.Delay > 0 {
+		timer := time.NewTimer(rule.Delay)
+		defer timer.Stop()
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+
+	resource := schema.GroupResource{Group: gvk.Group, Resource: strings.ToLower(gvk.Kind) + "s"}
+	cause := errors.New("injected fault")
+	switch rule.Fault {
+	case FaultConflict:
+		return apierrors.NewConflict(resource, name, cause)
+	case FaultTimeout:
+		return apierrors.NewTimeoutError(fmt.Sprintf("injected timeout on %s %s", verb, resource), 1)
+	case FaultForbidden:
+		return apierrors.NewForbidden(resource, name, cause)
+	case FaultNotFound:
+		return apierrors.NewNotFound(resource, name)
+	case FaultUnavailable:
+		return apierrors.NewServiceUnavailable(cause.Error())
+	}
+	return nil
+}
+
+// match returns the first matching rule with calls left, counting the call against it
+func (i *Injector) match(verb, kind, namespace, name string) (Rule, bool) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	for idx := range i.rules {
+		rule := &i.rules[idx]
+		if rule.Times > 0 && i.used[idx] >= rule.Times {
+			continue
+		}
+		if rule.matches(verb, kind, namespace, name) {
+			i.used[idx]++
+			return *rule, true
+		}
+	}
+	return Rule{}, false
+}
+
+// faultyClient injects faults before delegating to the wrapped client
+type faultyClient struct {
+	client.Client
+	injector *Injector
+}
+
+// gvk resolves the kind of an object, or of the items of a list
+func (c *faultyClient) gvk(obj interface{}) schema.GroupVersionKind {
+	o, ok := obj.(client.Object)
+	if !ok {
+		list, isList := obj.(client.ObjectList)
+		if !isList {
+			return schema.GroupVersionKind{}
+		}
+		gvk, err := apiutil.GVKForObject(list, c.Scheme())
+		if err != nil {
+			return schema.GroupVersionKind{}
+		}
+		gvk.Kind = strings.TrimSuffix(gvk.Kind, "List")
+		return gvk
+	}
+	gvk, err := apiutil.GVKForObject(o, c.Scheme())
+	if err != nil {
+		return schema.GroupVersionKind{}
+	}
+	return gvk
+}
+
+func (c *faultyClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+	if err := c.injector.inject(ctx, "get", c.gvk(obj), key.Namespace, key.Name); err != nil {
+		return err
+	}
+	return c.Client.Get(ctx, key, obj, opts...)
+}
+
+func (c *faultyClient) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	listOpts := &client.ListOptions{}
+	listOpts.ApplyOptions(opts)
+	if err := c.injector.inject(ctx, "list", c.gvk(list), listOpts.Namespace, ""); err != nil {
+		return err
+	}
+	return c.Client.List(ctx, list, opts...)
+}
+
+func (c *faultyClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	if err := c.injector.inject(ctx, "create", c.gvk(obj), obj.GetNamespace(), obj.GetName()); err != nil {
+		return err
+	}
+	return c.Client.Create(ctx, obj, opts...)
+}
+
+func (c *faultyClient) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	if err := c.injector.inject(ctx, "update", c.gvk(obj), obj.GetNamespace(), obj.GetName()); err != nil {
+		return err
+	}
+	return c.Client.Update(ctx, obj, opts...)
+}
+
+func (c *faultyClient) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	if err := c.injector.inject(ctx, "patch", c.gvk(obj), obj.GetNamespace(), obj.GetName()); err != nil {
+		return err
+	}
+	return c.Client.Patch(ctx, obj, patch, opts...)
+}
+
+func (c *faultyClient) Delete(ctx context.Context, obj client.Object, opts ...client.DeleteOption) error {
+	if err := c.injector.inject(ctx, "delete", c.gvk(obj), obj.GetNamespace(), obj.GetName()); err != nil {
+		return err
+	}
+	return c.Client.Delete(ctx, obj, opts...)
+}
+
+func (c *faultyClient) Status() client.SubResourceWriter {
+	return c.SubResource("status")
+}
+
+func (c *faultyClient) SubResource(subResource string) client.SubResourceClient {
+	return &faultySubResourceClient{
+		SubResourceClient: c.Client.SubResource(subResource),
+		client:            c,
+		subResource:       subResource,
+	}
+}
+
+// faultySubResourceClient injects faults into subresource calls, with the verb
+// suffixed by the subresource name
+type faultySubResourceClient struct {
+	client.SubResourceClient
+	client      *faultyClient
+	subResource string
+}
+
+func (c *faultySubResourceClient) inject(ctx context.Context, verb string, obj client.Object) error {
+	return c.client.injector.inject(ctx, verb+"-"+c.subResource, c.client.gvk(obj), obj.GetNamespace(), obj.GetName())
+}
+
+func (c *faultySubResourceClient) Get(ctx context.Context, obj, subResource client.Object, opts ...client.SubResourceGetOption) error {
+	if err := c.inject(ctx, "get", obj); err != nil {
+		return err
+	}
+	return c.SubResourceClient.Get(ctx, obj, subResource, opts...)
+}
+
+func (c *faultySubResourceClient) Create(ctx context.Context, obj, subResource client.Object, opts ...client.SubResourceCreateOption) error {
+	if err := c.inject(ctx, "create", obj); err != nil {
+		return err
+	}
+	return c.SubResourceClient.Create(ctx, obj, subResource, opts...)
+}
+
+func (c *faultySubResourceClient) Update(ctx context.Context, obj client.Object, opts ...client.SubResourceUpdateOption) error {
+	if err := c.inject(ctx, "update", obj); err != nil {
+		return err
+	}
+	return c.SubResourceClient.Update(ctx, obj, opts...)
+}
+
+func (c *faultySubResourceClient) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.SubResourcePatchOption) error {
+	if err := c.inject(ctx, "patch", obj); err != nil {
+		return err
+	}
+	return c.SubResourceClient.Patch(ctx, obj, patch, opts...)
+}
@@ -0,0 +1,153 @@
+package faultinject
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestParseRules(t *testing.T) {
+	tests := []struct {
+		name        string
+		spec        string
+		expected    []Rule
+		expectError bool
+	}{
+		{name: "empty", spec: ""},
+		{
+			name: "error and times",
+			spec: "update/VerticalPodAutoscaler:error=conflict,times=1",
+			expected: []Rule{
+				{Verb: "update", Kind: "VerticalPodAutoscaler", Fault: FaultConflict, Times: 1},
+			},
+		},
+		{
+			name: "several rules with namespace and name",
+			spec: "list/Deployment/team-a:delay=2s; get/*/team-b/api:error=timeout,delay=100ms",
+			expected: []Rule{
+				{Verb: "list", Kind: "Deployment", Namespace: "team-a", Delay: 2 * time.Second},
+				{Verb: "get", Kind: "*", Namespace: "team-b", Name: "api", Fault: FaultTimeout, Delay: 100 * time.Millisecond},
+			},
+		},
+		{name: "missing options", spec: "get/Deployment", expectError: true},
+		{name: "missing kind", spec: "get:error=timeout", expectError: true},
+		{name: "unknown error", spec: "get/Deployment:error=boom", expectError: true},
+		{name: "invalid delay", spec: "get/Deployment:delay=soon", expectError: true},
+		{name: "invalid times", spec: "get/Deployment:error=timeout,times=-1", expectError: true},
+		{name: "unknown option", spec: "get/Deployment:error=timeout,rate=0.5", expectError: true},
+		{name: "no fault", spec: "get/Deployment:times=1", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules, err := ParseRules(tt.spec)
+			if tt.expectError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, rules)
+		})
+	}
+}
+
+func TestInjector(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, corev1.AddToScheme(scheme))
+	require.NoError(t, appsv1.AddToScheme(scheme))
+	deployment := func(namespace, name string) *appsv1.Deployment {
+		return &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace}}
+	}
+
+	tests := []struct {
+		name     string
+		rules    []Rule
+		call     func(ctx context.Context, c client.Client) error
+		expected func(error) bool
+	}{
+		{
+			name:  "conflict on update",
+			rules: []Rule{{Verb: "update", Kind: "Deployment", Fault: FaultConflict}},
+			call: func(ctx context.Context, c client.Client) error {
+				return c.Update(ctx, deployment("team-a", "api"))
+			},
+			expected: apierrors.IsConflict,
+		},
+		{
+			name:  "forbidden list of one namespace",
+			rules: []Rule{{Verb: "list", Kind: "Deployment", Namespace: "team-a", Fault: FaultForbidden}},
+			call: func(ctx context.Context, c client.Client) error {
+				return c.List(ctx, &appsv1.DeploymentList{}, client.InNamespace("team-a"))
+			},
+			expected: apierrors.IsForbidden,
+		},
+		{
+			name:  "other namespaces are not affected",
+			rules: []Rule{{Verb: "list", Kind: "Deployment", Namespace: "team-a", Fault: FaultForbidden}},
+			call: func(ctx context.Context, c client.Client) error {
+				return c.List(ctx, &appsv1.DeploymentList{}, client.InNamespace("team-b"))
+			},
+			expected: func(err error) bool { return err == nil },
+		},
+		{
+			name:  "status subresource verb",
+			rules: []Rule{{Verb: "patch-status", Kind: "Deployment", Fault: FaultTimeout}},
+			call: func(ctx context.Context, c client.Client) error {
+				d := deployment("team-a", "api")
+				return c.Status().Patch(ctx, d, client.MergeFrom(d.DeepCopy()))
+			},
+			expected: apierrors.IsTimeout,
+		},
+		{
+			name:  "wildcard verb and kind",
+			rules: []Rule{{Verb: Wildcard, Kind: Wildcard, Fault: FaultUnavailable}},
+			call: func(ctx context.Context, c client.Client) error {
+				return c.Get(ctx, types.NamespacedName{Name: "team-a"}, &corev1.Namespace{})
+			},
+			expected: apierrors.IsServiceUnavailable,
+		},
+		{
+			name:  "delay is bounded by the call context",
+			rules: []Rule{{Verb: "get", Kind: "Deployment", Delay: time.Minute}},
+			call: func(ctx context.Context, c client.Client) error {
+				ctx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+				defer cancel()
+				return c.Get(ctx, types.NamespacedName{Namespace: "team-a", Name: "api"}, &appsv1.Deployment{})
+			},
+			expected: func(err error) bool { return err == context.DeadlineExceeded },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(deployment("team-a", "api")).Build()
+			c := New(tt.rules...).Wrap(fakeClient)
+			err := tt.call(context.Background(), c)
+			assert.True(t, tt.expected(err), "unexpected error: %v", err)
+		})
+	}
+}
+
+func TestInjector_Times(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, corev1.AddToScheme(scheme))
+	ctx := context.Background()
+
+	injector := New(Rule{Verb: "create", Kind: "Namespace", Fault: FaultTimeout, Times: 1})
+	c := injector.Wrap(fake.NewClientBuilder().WithScheme(scheme).Build())
+
+	assert.True(t, apierrors.IsTimeout(c.Create(ctx, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "a"}})))
+	assert.NoError(t, c.Create(ctx, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "a"}}), "the fault should apply once")
+	assert.Equal(t, 1, injector.Injected())
+}
@@ -0,0 +1,34 @@
+//go:build faultinjection
+
+package faultinject
+
+import (
+	"flag"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// rulesFlag holds --fault-injection. The flag only exists in builds with the
+// faultinjection tag, so release images cannot be started with injected faults.
+var rulesFlag string
+
+// BindFlags registers --fault-injection
+func BindFlags(fs *flag.FlagSet) {
+	fs.StringVar(&rulesFlag, "fault-injection", "",
+		"Testing only: inject API failures into the operator's client, as semicolon-separated "+
+			"VERB/KIND[/NAMESPACE[/NAME]]:error=<fault>,delay=<duration>,times=<count> rules.")
+}
+
+// FromFlags wraps c with the rules of --fault-injection
+func FromFlags(c client.Client) (client.Client, error) {
+	if rulesFlag == "" {
+		return c, nil
+	}
+	rules, err := ParseRules(rulesFlag)
+	if err != nil {
+		return nil, err
+	}
+	ctrl.Log.WithName("faultinject").Info("injecting API faults, never run this build in production", "rules", rulesFlag)
+	return New(rules...).Wrap(c), nil
+}
@@ -0,0 +1,17 @@
+//go:build !faultinjection
+
+package faultinject
+
+import (
+	"flag"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// BindFlags registers nothing; --fault-injection only exists in builds with the faultinjection tag
+func BindFlags(fs *flag.FlagSet) {}
+
+// FromFlags returns c unchanged
+func FromFlags(c client.Client) (client.Client, error) {
+	return c, nil
+}
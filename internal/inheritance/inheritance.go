@@ -0,0 +1,235 @@
+// Package inheritance resolves VpaManager inheritFrom chains and profiles into effective specs
+package inheritance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	autoscalingv1 "github.com/joaomo/k8s_op_vpa/api/v1"
+)
+
+// ErrCycle is returned when an inheritFrom chain loops back on itself
+var ErrCycle = errors.New("inheritFrom cycle detected")
+
+// ResolveSpec returns the spec of a VpaManager merged with its inheritFrom chain and the
+// profiles the chain references. A profile sits between a VpaManager and its parent: it
+// fills what the VpaManager omits and overrides what the parent sets. It fails when a
+// parent or profile is missing, a profile is invalid or the chain contains a cycle.
+func ResolveSpec(ctx context.Context, c client.Reader, vpaManager *autoscalingv1.VpaManager) (*autoscalingv1.VpaManagerSpec, error) {
+	if vpaManager.Spec.InheritFrom == "" && vpaManager.Spec.ProfileRef == nil {
+		return &vpaManager.Spec, nil
+	}
+
+	// Walk up to the root, remembering the chain so it can be applied top-down
+	chain := []*autoscalingv1.VpaManagerSpec{&vpaManager.Spec}
+	path := []string{vpaManager.Name}
+	visited := map[string]bool{vpaManager.Name: true}
+	for parentName := vpaManager.Spec.InheritFrom; parentName != ""; {
+		if visited[parentName] {
+			return nil, fmt.Errorf("%w: %s -> %s", ErrCycle, strings.Join(path, " -> "), parentName)
+		}
+		visited[parentName] = true
+		path = append(path, parentName)
+
+		parent := &autoscalingv1.VpaManager{}
+		if err := c.Get(ctx, types.NamespacedName{Name: parentName}, parent); err != nil {
+			return nil, fmt.Errorf("failed to get parent VpaManager %q: %w", parentName, err)
+		}
+		chain = append(chain, &parent.Spec)
+		parentName = parent.Spec.InheritFrom
+	}
+
+	var resolved *autoscalingv1.VpaManagerSpec
+	for i := len(chain) - 1; i >= 0; i-- {
+		if ref := chain[i].ProfileRef; ref != nil {
+			profile, err := LoadProfile(ctx, c, ref)
+			if err != nil {
+				return nil, err
+			}
+			resolved = overlay(resolved, profile)
+		}
+		resolved = overlay(resolved, chain[i])
+	}
+	return resolved, nil
+}
+
+// overlay merges spec onto base, or copies spec when nothing is below it
+func overlay(base, spec *autoscalingv1.VpaManagerSpec) *autoscalingv1.VpaManagerSpec {
+	if base == nil {
+		return spec.DeepCopy()
+	}
+	return MergeSpec(base, spec)
+}
+
+// MergeSpec overlays child on parent. Omitted child fields are taken from the parent;
+// namespace selection is inherited as a whole so selectors and matchAllNamespaces never conflict.
+func MergeSpec(parent, child *autoscalingv1.VpaManagerSpec) *autoscalingv1.VpaManagerSpec {
+	out := child.DeepCopy()
+	out.InheritFrom = ""
+	out.ProfileRef = nil
+
+	if out.UpdateMode == "" {
+		out.UpdateMode = parent.UpdateMode
+	}
+	out.UpdateModes = mergeUpdateModes(parent.UpdateModes, child.UpdateModes)
+	if len(out.Recommenders) == 0 {
+		out.Recommenders = append([]autoscalingv1.RecommenderSelector(nil), parent.Recommenders...)
+	}
+	if out.RecommendationTuning == nil {
+		out.RecommendationTuning = parent.RecommendationTuning.DeepCopy()
+	}
+	if out.VPANameTemplate == "" {
+		out.VPANameTemplate = parent.VPANameTemplate
+	}
+	if out.ContainerPolicyMerge == "" {
+		out.ContainerPolicyMerge = parent.ContainerPolicyMerge
+	}
+	if out.UpdatePolicy == nil {
+		out.UpdatePolicy = parent.UpdatePolicy.DeepCopy()
+	}
+	if out.Dormancy == nil {
+		out.Dormancy = parent.Dormancy.DeepCopy()
+	}
+	if out.MaxAllowedFromQuota == nil {
+		out.MaxAllowedFromQuota = parent.MaxAllowedFromQuota.DeepCopy()
+	}
+
+	if len(child.NamespaceSelectorTerms()) == 0 && !child.MatchAllNamespaces && len(child.Namespaces) == 0 {
+		out.NamespaceSelector = parent.NamespaceSelector.DeepCopy()
+		out.NamespaceSelectors = copySelectors(parent.NamespaceSelectors)
+		out.MatchAllNamespaces = parent.MatchAllNamespaces
+		out.Namespaces = append([]string(nil), parent.Namespaces...)
+	}
+	// Exclusions only ever add up, so a child cannot re-include a namespace its parent excludes
+	out.ExcludeNamespaces = mergeStrings(parent.ExcludeNamespaces, child.ExcludeNamespaces)
+	// Two selectors cannot be combined into one that matches either, so a child's replaces its parent's
+	if out.NamespaceExcludeSelector == nil {
+		out.NamespaceExcludeSelector = parent.NamespaceExcludeSelector.DeepCopy()
+	}
+
+	// A kind's selector and its alternatives are inherited together
+	if len(child.WorkloadSelectorTerms("Deployment")) == 0 {
+		out.DeploymentSelector = parent.DeploymentSelector.DeepCopy()
+		out.DeploymentSelectors = copySelectors(parent.DeploymentSelectors)
+	}
+	if len(child.WorkloadSelectorTerms("StatefulSet")) == 0 {
+		out.StatefulSetSelector = parent.StatefulSetSelector.DeepCopy()
+		out.StatefulSetSelectors = copySelectors(parent.StatefulSetSelectors)
+	}
+	if len(child.WorkloadSelectorTerms("DaemonSet")) == 0 {
+		out.DaemonSetSelector = parent.DaemonSetSelector.DeepCopy()
+		out.DaemonSetSelectors = copySelectors(parent.DaemonSetSelectors)
+	}
+	out.MatchAllWorkloads = child.MatchAllWorkloads || parent.MatchAllWorkloads
+	if out.WorkloadNamePatterns == nil {
+		out.WorkloadNamePatterns = parent.WorkloadNamePatterns.DeepCopy()
+	}
+
+	out.ResourcePolicy = mergeResourcePolicy(parent.ResourcePolicy, child.ResourcePolicy)
+	if len(out.DefaultControlledResources) == 0 {
+		out.DefaultControlledResources = append([]string(nil), parent.DefaultControlledResources...)
+	}
+	out.ExcludeWellKnownSidecars = child.ExcludeWellKnownSidecars || parent.ExcludeWellKnownSidecars
+	out.PropagateAnnotations = mergeStrings(parent.PropagateAnnotations, child.PropagateAnnotations)
+	out.VPALabels = mergeMaps(parent.VPALabels, out.VPALabels)
+	out.VPAAnnotations = mergeMaps(parent.VPAAnnotations, out.VPAAnnotations)
+
+	return out
+}
+
+// mergeUpdateModes combines update modes per kind, child modes replacing parent ones
+func mergeUpdateModes(parent, child *autoscalingv1.UpdateModesByKind) *autoscalingv1.UpdateModesByKind {
+	if parent == nil {
+		return child.DeepCopy()
+	}
+	out := parent.DeepCopy()
+	if child == nil {
+		return out
+	}
+	if child.Deployment != "" {
+		out.Deployment = child.Deployment
+	}
+	if child.StatefulSet != "" {
+		out.StatefulSet = child.StatefulSet
+	}
+	if child.DaemonSet != "" {
+		out.DaemonSet = child.DaemonSet
+	}
+	return out
+}
+
+// mergeResourcePolicy combines container policies by name, child entries replacing parent ones
+func mergeResourcePolicy(parent, child *autoscalingv1.ResourcePolicy) *autoscalingv1.ResourcePolicy {
+	if parent == nil {
+		return child.DeepCopy()
+	}
+	if child == nil {
+		return parent.DeepCopy()
+	}
+
+	childPolicies := make(map[string]autoscalingv1.ContainerResourcePolicy, len(child.ContainerPolicies))
+	for _, cp := range child.ContainerPolicies {
+		childPolicies[cp.ContainerName] = cp
+	}
+
+	out := &autoscalingv1.ResourcePolicy{IgnoreInitContainers: parent.IgnoreInitContainers || child.IgnoreInitContainers}
+	seen := map[string]bool{}
+	for _, cp := range parent.ContainerPolicies {
+		if override, ok := childPolicies[cp.ContainerName]; ok {
+			cp = override
+		}
+		out.ContainerPolicies = append(out.ContainerPolicies, *cp.DeepCopy())
+		seen[cp.ContainerName] = true
+	}
+	for _, cp := range child.ContainerPolicies {
+		if !seen[cp.ContainerName] {
+			out.ContainerPolicies = append(out.ContainerPolicies, *cp.DeepCopy())
+		}
+	}
+	return out
+}
+
+// mergeStrings returns the union of two lists, preserving order
+func mergeStrings(parent, child []string) []string {
+	if len(parent) == 0 {
+		return child
+	}
+	out := make([]string, 0, len(parent)+len(child))
+	seen := map[string]bool{}
+	for _, s := range append(append([]string{}, parent...), child...) {
+		if !seen[s] {
+			out = append(out, s)
+			seen[s] = true
+		}
+	}
+	return out
+}
+
+// mergeMaps combines parent and child entries, child values replacing parent ones
+func mergeMaps(parent, child map[string]string) map[string]string {
+	if len(parent) == 0 {
+		return child
+	}
+	out := maps.Clone(parent)
+	maps.Copy(out, child)
+	return out
+}
+
+// copySelectors deep-copies a list of selectors, nil when it is empty
+func copySelectors(selectors []metav1.LabelSelector) []metav1.LabelSelector {
+	if len(selectors) == 0 {
+		return nil
+	}
+	out := make([]metav1.LabelSelector, len(selectors))
+	for i := range selectors {
+		selectors[i].DeepCopyInto(&out[i])
+	}
+	return out
+}
@@ -0,0 +1,484 @@
+package inheritance
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	autoscalingv1 "github.com/joaomo/k8s_op_vpa/api/v1"
+)
+
+func TestMergeSpec(t *testing.T) {
+	optIn := &metav1.LabelSelector{MatchLabels: map[string]string{"vpa-enabled": "true"}}
+	team := &metav1.LabelSelector{MatchLabels: map[string]string{"team": "payments"}}
+
+	two, three := int32(2), int32(3)
+	parent := &autoscalingv1.VpaManagerSpec{
+		Enabled:            false,
+		UpdateMode:         "Initial",
+		UpdateModes:        &autoscalingv1.UpdateModesByKind{StatefulSet: "Off", DaemonSet: "Off"},
+		UpdatePolicy:       &autoscalingv1.UpdatePolicy{MinReplicas: &two},
+		NamespaceSelector:  optIn,
+		DeploymentSelector: optIn,
+		ResourcePolicy: &autoscalingv1.ResourcePolicy{
+			ContainerPolicies: []autoscalingv1.ContainerResourcePolicy{
+				{ContainerName: "*", MaxAllowed: map[string]string{"memory": "4Gi"}},
+				{ContainerName: "istio-proxy", MaxAllowed: map[string]string{"cpu": "500m"}},
+			},
+		},
+		PropagateAnnotations: []string{"team"},
+		Recommenders:         []autoscalingv1.RecommenderSelector{{Name: "gpu"}},
+		RecommendationTuning: &autoscalingv1.RecommendationTuning{TargetCPUPercentile: "0.9"},
+		ContainerPolicyMerge: autoscalingv1.ContainerPolicyMergeByContainerName,
+		Dormancy:             &autoscalingv1.DormancyPolicy{After: metav1.Duration{Duration: 6 * time.Hour}},
+		MaxAllowedFromQuota:  &autoscalingv1.QuotaFraction{CPUFraction: "0.25"},
+		ExcludeNamespaces:    []string{"kube-system"},
+	}
+
+	tests := []struct {
+		name   string
+		child  autoscalingv1.VpaManagerSpec
+		verify func(t *testing.T, got *autoscalingv1.VpaManagerSpec)
+	}{
+		{
+			name:  "omitted fields are inherited except enabled",
+			child: autoscalingv1.VpaManagerSpec{Enabled: true},
+			verify: func(t *testing.T, got *autoscalingv1.VpaManagerSpec) {
+				assert.True(t, got.Enabled)
+				assert.Equal(t, "Initial", got.UpdateMode)
+				assert.Equal(t, parent.UpdateModes, got.UpdateModes)
+				assert.Equal(t, parent.UpdatePolicy, got.UpdatePolicy)
+				assert.Equal(t, optIn, got.NamespaceSelector)
+				assert.Equal(t, optIn, got.DeploymentSelector)
+				assert.Equal(t, parent.ResourcePolicy, got.ResourcePolicy)
+				assert.Equal(t, []string{"team"}, got.PropagateAnnotations)
+				assert.Equal(t, parent.Recommenders, got.Recommenders)
+				assert.Equal(t, parent.RecommendationTuning, got.RecommendationTuning)
+				assert.Equal(t, autoscalingv1.ContainerPolicyMergeByContainerName, got.ContainerPolicyMerge)
+				assert.Equal(t, parent.Dormancy, got.Dormancy)
+				assert.Equal(t, parent.MaxAllowedFromQuota, got.MaxAllowedFromQuota)
+				assert.Equal(t, []string{"kube-system"}, got.ExcludeNamespaces)
+			},
+		},
+		{
+			name: "child values override",
+			child: autoscalingv1.VpaManagerSpec{
+				UpdateMode:           "Auto",
+				UpdateModes:          &autoscalingv1.UpdateModesByKind{StatefulSet: "Initial"},
+				UpdatePolicy:         &autoscalingv1.UpdatePolicy{MinReplicas: &three},
+				NamespaceSelector:    team,
+				DeploymentSelector:   team,
+				Recommenders:         []autoscalingv1.RecommenderSelector{{Name: "batch"}},
+				RecommendationTuning: &autoscalingv1.RecommendationTuning{SafetyMarginFraction: "0.2"},
+			},
+			verify: func(t *testing.T, got *autoscalingv1.VpaManagerSpec) {
+				assert.Equal(t, "Auto", got.UpdateMode)
+				assert.Equal(t, &autoscalingv1.UpdateModesByKind{StatefulSet: "Initial", DaemonSet: "Off"}, got.UpdateModes)
+				assert.Equal(t, &autoscalingv1.UpdatePolicy{MinReplicas: &three}, got.UpdatePolicy)
+				assert.Equal(t, &autoscalingv1.RecommendationTuning{SafetyMarginFraction: "0.2"}, got.RecommendationTuning)
+				assert.Equal(t, []autoscalingv1.RecommenderSelector{{Name: "batch"}}, got.Recommenders)
+				assert.Equal(t, team, got.NamespaceSelector)
+				assert.Equal(t, team, got.DeploymentSelector)
+			},
+		},
+		{
+			name:  "matchAllNamespaces replaces the inherited namespace selector",
+			child: autoscalingv1.VpaManagerSpec{MatchAllNamespaces: true},
+			verify: func(t *testing.T, got *autoscalingv1.VpaManagerSpec) {
+				assert.True(t, got.MatchAllNamespaces)
+				assert.Nil(t, got.NamespaceSelector)
+			},
+		},
+		{
+			name:  "namespaces replace the inherited namespace selector and exclusions add up",
+			child: autoscalingv1.VpaManagerSpec{Namespaces: []string{"prod-a"}, ExcludeNamespaces: []string{"monitoring"}},
+			verify: func(t *testing.T, got *autoscalingv1.VpaManagerSpec) {
+				assert.Equal(t, []string{"prod-a"}, got.Namespaces)
+				assert.Nil(t, got.NamespaceSelector)
+				assert.Equal(t, []string{"kube-system", "monitoring"}, got.ExcludeNamespaces)
+			},
+		},
+		{
+			name: "alternative selectors replace the inherited selectors",
+			child: autoscalingv1.VpaManagerSpec{
+				NamespaceSelectors:  []metav1.LabelSelector{*team, {MatchLabels: map[string]string{"shared": "true"}}},
+				DeploymentSelectors: []metav1.LabelSelector{*team},
+			},
+			verify: func(t *testing.T, got *autoscalingv1.VpaManagerSpec) {
+				assert.Nil(t, got.NamespaceSelector)
+				assert.Len(t, got.NamespaceSelectors, 2)
+				assert.Nil(t, got.DeploymentSelector)
+				assert.Equal(t, []metav1.LabelSelector{*team}, got.DeploymentSelectors)
+			},
+		},
+		{
+			name: "container policies merge by name",
+			child: autoscalingv1.VpaManagerSpec{
+				ResourcePolicy: &autoscalingv1.ResourcePolicy{
+					ContainerPolicies: []autoscalingv1.ContainerResourcePolicy{
+						{ContainerName: "*", MaxAllowed: map[string]string{"memory": "8Gi"}},
+						{ContainerName: "app", MinAllowed: map[string]string{"cpu": "100m"}},
+					},
+				},
+				PropagateAnnotations: []string{"service-tier", "team"},
+			},
+			verify: func(t *testing.T, got *autoscalingv1.VpaManagerSpec) {
+				require.Len(t, got.ResourcePolicy.ContainerPolicies, 3)
+				assert.Equal(t, "8Gi", got.ResourcePolicy.ContainerPolicies[0].MaxAllowed["memory"])
+				assert.Equal(t, "istio-proxy", got.ResourcePolicy.ContainerPolicies[1].ContainerName)
+				assert.Equal(t, "app", got.ResourcePolicy.ContainerPolicies[2].ContainerName)
+				assert.Equal(t, []string{"team", "service-tier"}, got.PropagateAnnotations)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.verify(t, MergeSpec(parent, &tt.child))
+		})
+	}
+}
+
+func TestResolveSpec(t *testing.T) {
+	newManager := func(name, inheritFrom, updateMode string) *autoscalingv1.VpaManager {
+		return &autoscalingv1.VpaManager{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec:       autoscalingv1.VpaManagerSpec{InheritFrom: inheritFrom, UpdateMode: updateMode},
+		}
+	}
+
+	tests := []struct {
+		name       string
+		managers   []*autoscalingv1.VpaManager
+		target     string
+		wantMode   string
+		wantErr    error
+		isNotFound bool
+	}{
+		{
+			name:     "no parent",
+			managers: []*autoscalingv1.VpaManager{newManager("team", "", "Auto")},
+			target:   "team",
+			wantMode: "Auto",
+		},
+		{
+			name: "multi-level chain",
+			managers: []*autoscalingv1.VpaManager{
+				newManager("base", "", "Initial"),
+				newManager("org", "base", ""),
+				newManager("team", "org", ""),
+			},
+			target:   "team",
+			wantMode: "Initial",
+		},
+		{
+			name: "cycle",
+			managers: []*autoscalingv1.VpaManager{
+				newManager("a", "b", ""),
+				newManager("b", "a", ""),
+			},
+			target:  "a",
+			wantErr: ErrCycle,
+		},
+		{
+			name:     "self reference",
+			managers: []*autoscalingv1.VpaManager{newManager("a", "a", "")},
+			target:   "a",
+			wantErr:  ErrCycle,
+		},
+		{
+			name:       "missing parent",
+			managers:   []*autoscalingv1.VpaManager{newManager("team", "base", "")},
+			target:     "team",
+			isNotFound: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			require.NoError(t, autoscalingv1.AddToScheme(scheme))
+			builder := fake.NewClientBuilder().WithScheme(scheme)
+			var target *autoscalingv1.VpaManager
+			for _, vm := range tt.managers {
+				builder = builder.WithObjects(vm)
+				if vm.Name == tt.target {
+					target = vm
+				}
+			}
+			c := builder.Build()
+
+			got, err := ResolveSpec(context.Background(), c, target)
+			switch {
+			case tt.wantErr != nil:
+				assert.True(t, errors.Is(err, tt.wantErr), "got %v", err)
+			case tt.isNotFound:
+				assert.True(t, apierrors.IsNotFound(err), "got %v", err)
+			default:
+				require.NoError(t, err)
+				assert.Equal(t, tt.wantMode, got.UpdateMode)
+				assert.Empty(t, got.InheritFrom)
+			}
+		})
+	}
+}
+
+func TestResolveSpec_Profiles(t *testing.T) {
+	profileRef := &autoscalingv1.ProfileReference{Namespace: "platform", Name: "vpa-defaults"}
+	newProfile := func(data map[string]string) *corev1.ConfigMap {
+		return &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "vpa-defaults", Namespace: "platform", Labels: map[string]string{ProfileLabel: "true"}},
+			Data:       data,
+		}
+	}
+	initial := newProfile(map[string]string{ProfileKey: "enabled: true\nupdateMode: Initial\nexcludeNamespaces: [kube-system]\n"})
+
+	tests := []struct {
+		name        string
+		objects     []client.Object
+		spec        autoscalingv1.VpaManagerSpec
+		wantMode    string
+		wantExclude []string
+		wantErr     error
+		isNotFound  bool
+	}{
+		{
+			name:        "profile fills omitted fields",
+			objects:     []client.Object{initial},
+			spec:        autoscalingv1.VpaManagerSpec{ProfileRef: profileRef, ExcludeNamespaces: []string{"monitoring"}},
+			wantMode:    "Initial",
+			wantExclude: []string{"kube-system", "monitoring"},
+		},
+		{
+			name:        "own values override the profile",
+			objects:     []client.Object{initial},
+			spec:        autoscalingv1.VpaManagerSpec{ProfileRef: profileRef, UpdateMode: "Auto"},
+			wantMode:    "Auto",
+			wantExclude: []string{"kube-system"},
+		},
+		{
+			name: "profile overrides the parent",
+			objects: []client.Object{initial, &autoscalingv1.VpaManager{
+				ObjectMeta: metav1.ObjectMeta{Name: "base"},
+				Spec:       autoscalingv1.VpaManagerSpec{UpdateMode: "Off"},
+			}},
+			spec:        autoscalingv1.VpaManagerSpec{InheritFrom: "base", ProfileRef: profileRef},
+			wantMode:    "Initial",
+			wantExclude: []string{"kube-system"},
+		},
+		{
+			name: "profile of a parent",
+			objects: []client.Object{initial, &autoscalingv1.VpaManager{
+				ObjectMeta: metav1.ObjectMeta{Name: "base"},
+				Spec:       autoscalingv1.VpaManagerSpec{ProfileRef: profileRef},
+			}},
+			spec:        autoscalingv1.VpaManagerSpec{InheritFrom: "base"},
+			wantMode:    "Initial",
+			wantExclude: []string{"kube-system"},
+		},
+		{
+			name:       "missing profile",
+			spec:       autoscalingv1.VpaManagerSpec{ProfileRef: profileRef},
+			isNotFound: true,
+		},
+		{
+			name:    "ConfigMap without a profile",
+			objects: []client.Object{newProfile(map[string]string{"other.yaml": "updateMode: Auto"})},
+			spec:    autoscalingv1.VpaManagerSpec{ProfileRef: profileRef},
+			wantErr: ErrInvalidProfile,
+		},
+		{
+			name:    "unknown field in profile",
+			objects: []client.Object{newProfile(map[string]string{ProfileKey: "updateMod: Auto"})},
+			spec:    autoscalingv1.VpaManagerSpec{ProfileRef: profileRef},
+			wantErr: ErrInvalidProfile,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			require.NoError(t, autoscalingv1.AddToScheme(scheme))
+			require.NoError(t, corev1.AddToScheme(scheme))
+			c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(tt.objects...).Build()
+			target := &autoscalingv1.VpaManager{ObjectMeta: metav1.ObjectMeta{Name: "team"}, Spec: tt.spec}
+
+			got, err := ResolveSpec(context.Background(), c, target)
+			switch {
+			case tt.wantErr != nil:
+				assert.True(t, errors.Is(err, tt.wantErr), "got %v", err)
+			case tt.isNotFound:
+				assert.True(t, apierrors.IsNotFound(err), "got %v", err)
+			default:
+				require.NoError(t, err)
+				assert.Equal(t, tt.wantMode, got.UpdateMode)
+				assert.Equal(t, tt.wantExclude, got.ExcludeNamespaces)
+				assert.False(t, got.Enabled, "profiles never enable a VpaManager")
+				assert.Nil(t, got.ProfileRef)
+			}
+		})
+	}
+}
+
+func TestMergeSpec_NamespaceExcludeSelector(t *testing.T) {
+	system := &metav1.LabelSelector{MatchLabels: map[string]string{"tier": "system"}}
+	observability := &metav1.LabelSelector{MatchLabels: map[string]string{"tier": "observability"}}
+
+	tests := []struct {
+		name     string
+		parent   *metav1.LabelSelector
+		child    *metav1.LabelSelector
+		expected *metav1.LabelSelector
+	}{
+		{name: "neither sets one"},
+		{name: "inherited from the parent", parent: system, expected: system},
+		{name: "set by the child only", child: observability, expected: observability},
+		{name: "child replaces the parent's", parent: system, child: observability, expected: observability},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := MergeSpec(
+				&autoscalingv1.VpaManagerSpec{NamespaceExcludeSelector: tt.parent},
+				&autoscalingv1.VpaManagerSpec{NamespaceExcludeSelector: tt.child},
+			)
+			assert.Equal(t, tt.expected, got.NamespaceExcludeSelector)
+		})
+	}
+}
+
+func TestMergeSpec_WorkloadNamePatterns(t *testing.T) {
+	legacy := &autoscalingv1.NamePatterns{Include: []string{"legacy-.*"}}
+	noCanaries := &autoscalingv1.NamePatterns{Exclude: []string{".*-canary"}}
+
+	tests := []struct {
+		name     string
+		parent   *autoscalingv1.NamePatterns
+		child    *autoscalingv1.NamePatterns
+		expected *autoscalingv1.NamePatterns
+	}{
+		{name: "neither sets them"},
+		{name: "inherited from the parent", parent: legacy, expected: legacy},
+		{name: "set by the child only", child: noCanaries, expected: noCanaries},
+		{name: "child replaces the parent's", parent: legacy, child: noCanaries, expected: noCanaries},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := MergeSpec(
+				&autoscalingv1.VpaManagerSpec{WorkloadNamePatterns: tt.parent},
+				&autoscalingv1.VpaManagerSpec{WorkloadNamePatterns: tt.child},
+			)
+			assert.Equal(t, tt.expected, got.WorkloadNamePatterns)
+		})
+	}
+}
+
+func TestMergeSpec_DefaultControlledResources(t *testing.T) {
+	tests := []struct {
+		name     string
+		parent   []string
+		child    []string
+		expected []string
+	}{
+		{name: "neither sets them"},
+		{name: "inherited from the parent", parent: []string{"cpu"}, expected: []string{"cpu"}},
+		{name: "child replaces the parent's", parent: []string{"cpu"}, child: []string{"cpu", "memory"}, expected: []string{"cpu", "memory"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := MergeSpec(
+				&autoscalingv1.VpaManagerSpec{DefaultControlledResources: tt.parent},
+				&autoscalingv1.VpaManagerSpec{DefaultControlledResources: tt.child},
+			)
+			assert.ElementsMatch(t, tt.expected, got.DefaultControlledResources)
+		})
+	}
+}
+
+func TestMergeSpec_IgnoreInitContainers(t *testing.T) {
+	tests := []struct {
+		name     string
+		parent   *autoscalingv1.ResourcePolicy
+		child    *autoscalingv1.ResourcePolicy
+		expected bool
+	}{
+		{name: "neither sets it", parent: &autoscalingv1.ResourcePolicy{}, child: &autoscalingv1.ResourcePolicy{}},
+		{name: "inherited from the parent", parent: &autoscalingv1.ResourcePolicy{IgnoreInitContainers: true}, child: &autoscalingv1.ResourcePolicy{}, expected: true},
+		{name: "set by the child", parent: &autoscalingv1.ResourcePolicy{}, child: &autoscalingv1.ResourcePolicy{IgnoreInitContainers: true}, expected: true},
+		{name: "parent without a resource policy", child: &autoscalingv1.ResourcePolicy{IgnoreInitContainers: true}, expected: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := MergeSpec(
+				&autoscalingv1.VpaManagerSpec{ResourcePolicy: tt.parent},
+				&autoscalingv1.VpaManagerSpec{ResourcePolicy: tt.child},
+			)
+			assert.Equal(t, tt.expected, got.ResourcePolicy.IgnoreInitContainers)
+		})
+	}
+}
+
+func TestMergeSpec_VPANameTemplate(t *testing.T) {
+	tests := []struct {
+		name     string
+		parent   string
+		child    string
+		expected string
+	}{
+		{name: "neither sets one"},
+		{name: "inherited from the parent", parent: "{{ .Name }}", expected: "{{ .Name }}"},
+		{name: "child replaces the parent's", parent: "{{ .Name }}", child: "{{ .Kind | lower }}-{{ .Name }}", expected: "{{ .Kind | lower }}-{{ .Name }}"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := MergeSpec(
+				&autoscalingv1.VpaManagerSpec{VPANameTemplate: tt.parent},
+				&autoscalingv1.VpaManagerSpec{VPANameTemplate: tt.child},
+			)
+			assert.Equal(t, tt.expected, got.VPANameTemplate)
+		})
+	}
+}
+
+func TestMergeSpec_VPALabelsAndAnnotations(t *testing.T) {
+	tests := []struct {
+		name     string
+		parent   map[string]string
+		child    map[string]string
+		expected map[string]string
+	}{
+		{name: "neither sets any"},
+		{name: "inherited from the parent", parent: map[string]string{"team": "payments"}, expected: map[string]string{"team": "payments"}},
+		{
+			name:     "child values win",
+			parent:   map[string]string{"team": "payments", "cost-center": "cc-1"},
+			child:    map[string]string{"cost-center": "cc-42"},
+			expected: map[string]string{"team": "payments", "cost-center": "cc-42"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parent := &autoscalingv1.VpaManagerSpec{VPALabels: tt.parent, VPAAnnotations: tt.parent}
+			child := &autoscalingv1.VpaManagerSpec{VPALabels: tt.child, VPAAnnotations: tt.child}
+			got := MergeSpec(parent, child)
+			assert.Equal(t, tt.expected, got.VPALabels)
+			assert.Equal(t, tt.expected, got.VPAAnnotations)
+			assert.Equal(t, tt.parent, parent.VPALabels, "the parent is not modified")
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package inheritance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	autoscalingv1 "github.com/joaomo/k8s_op_vpa/api/v1"
+)
+
+const (
+	// ProfileKey is the ConfigMap key holding a profile, a partial VpaManager spec in YAML
+	ProfileKey = "profile.yaml"
+
+	// ProfileLabel marks the ConfigMaps that hold profiles. The operator only caches and
+	// watches ConfigMaps with this label set to "true".
+	ProfileLabel = "vpa-operator.io/profile"
+)
+
+// ErrInvalidProfile is returned when a profile ConfigMap has no profile or it does not parse
+var ErrInvalidProfile = errors.New("invalid profile")
+
+// LoadProfile reads the partial spec of the profile ConfigMap ref points at. Enabled,
+// inheritFrom and profileRef are ignored in profiles, so a profile cannot turn managers
+// on or chain to other profiles.
+func LoadProfile(ctx context.Context, c client.Reader, ref *autoscalingv1.ProfileReference) (*autoscalingv1.VpaManagerSpec, error) {
+	cm := &corev1.ConfigMap{}
+	if err := c.Get(ctx, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}, cm); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, fmt.Errorf("profile ConfigMap %s/%s not found, it must exist and be labeled %s=true: %w",
+				ref.Namespace, ref.Name, ProfileLabel, err)
+		}
+		return nil, fmt.Errorf("failed to get profile ConfigMap %s/%s: %w", ref.Namespace, ref.Name, err)
+	}
+
+	data, ok := cm.Data[ProfileKey]
+	if !ok {
+		return nil, fmt.Errorf("%w: ConfigMap %s/%s has no %s key", ErrInvalidProfile, ref.Namespace, ref.Name, ProfileKey)
+	}
+	profile := &autoscalingv1.VpaManagerSpec{}
+	if err := yaml.UnmarshalStrict([]byte(data), profile); err != nil {
+		return nil, fmt.Errorf("%w: ConfigMap %s/%s: %v", ErrInvalidProfile, ref.Namespace, ref.Name, err)
+	}
+	profile.Enabled = false
+	profile.InheritFrom = ""
+	profile.ProfileRef = nil
+	return profile, nil
+}
@@ -0,0 +1,142 @@
+// Package labelselector compiles the label selectors of VpaManagers once and reuses them.
+// Reconciles match every namespace and workload kind against the same selectors, and the
+// webhooks match every admitted workload against the selectors of every VpaManager, so
+// parsing them on each use is measurable on clusters with high admission rates.
+package labelselector
+
+import (
+	"fmt"
+	"sync"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// Cache holds compiled selectors per VpaManager and selector field. An entry is reused while
+// the VpaManager generation and the selector are unchanged; comparing the selector covers
+// selectors inherited from a parent or filled in by defaults, which change without the
+// generation. A nil Cache compiles on every call.
+type Cache struct {
+	mu      sync.RWMutex
+	entries map[key]*entry
+}
+
+type key struct {
+	owner string
+	field string
+}
+
+type entry struct {
+	generation int64
+	source     *metav1.LabelSelector
+	compiled   labels.Selector
+	err        error
+}
+
+// NewCache returns an empty cache
+func NewCache() *Cache {
+	return &Cache{entries: map[key]*entry{}}
+}
+
+// Compile returns the compiled form of sel, the selector in field of the VpaManager owner at
+// generation. Invalid selectors are cached too and keep returning their error. sel must not
+// be nil; callers decide what an omitted selector means.
+func (c *Cache) Compile(owner string, generation int64, field string, sel *metav1.LabelSelector) (labels.Selector, error) {
+	if c == nil {
+		return metav1.LabelSelectorAsSelector(sel)
+	}
+	k := key{owner: owner, field: field}
+
+	c.mu.RLock()
+	e, ok := c.entries[k]
+	c.mu.RUnlock()
+	if ok && e.generation == generation && equal(e.source, sel) {
+		return e.compiled, e.err
+	}
+
+	compiled, err := metav1.LabelSelectorAsSelector(sel)
+	c.mu.Lock()
+	c.entries[k] = &entry{generation: generation, source: sel.DeepCopy(), compiled: compiled, err: err}
+	c.mu.Unlock()
+	return compiled, err
+}
+
+// Any is a set of alternative selectors; labels are selected when they match any of them
+type Any []labels.Selector
+
+// Matches reports whether set matches any of the selectors
+func (a Any) Matches(set labels.Labels) bool {
+	for _, selector := range a {
+		if selector.Matches(set) {
+			return true
+		}
+	}
+	return false
+}
+
+// CompileAny compiles sels, the alternative selectors in field of the VpaManager owner at
+// generation. The first is cached under field like Compile, the others under field and
+// their index. It fails on the first invalid selector.
+func (c *Cache) CompileAny(owner string, generation int64, field string, sels []*metav1.LabelSelector) (Any, error) {
+	compiled := make(Any, 0, len(sels))
+	for i, sel := range sels {
+		name := field
+		if i > 0 {
+			name = fmt.Sprintf("%s[%d]", field, i)
+		}
+		selector, err := c.Compile(owner, generation, name, sel)
+		if err != nil {
+			return nil, err
+		}
+		compiled = append(compiled, selector)
+	}
+	return compiled, nil
+}
+
+// Forget drops the selectors of a deleted VpaManager
+func (c *Cache) Forget(owner string) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for k := range c.entries {
+		if k.owner == owner {
+			delete(c.entries, k)
+		}
+	}
+}
+
+// Len returns the number of cached selectors
+func (c *Cache) Len() int {
+	if c == nil {
+		return 0
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return len(c.entries)
+}
+
+// equal compares two selectors field by field, much cheaper than compiling them
+func equal(a, b *metav1.LabelSelector) bool {
+	if len(a.MatchLabels) != len(b.MatchLabels) || len(a.MatchExpressions) != len(b.MatchExpressions) {
+		return false
+	}
+	for k, v := range a.MatchLabels {
+		if other, ok := b.MatchLabels[k]; !ok || other != v {
+			return false
+		}
+	}
+	for i := range a.MatchExpressions {
+		ea, eb := &a.MatchExpressions[i], &b.MatchExpressions[i]
+		if ea.Key != eb.Key || ea.Operator != eb.Operator || len(ea.Values) != len(eb.Values) {
+			return false
+		}
+		for j := range ea.Values {
+			if ea.Values[j] != eb.Values[j] {
+				return false
+			}
+		}
+	}
+	return true
+}
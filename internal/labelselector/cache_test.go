@@ -0,0 +1,155 @@
+package labelselector
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+func TestCache_Compile(t *testing.T) {
+	app := &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}}
+	tier := &metav1.LabelSelector{MatchLabels: map[string]string{"tier": "frontend"}}
+	invalid := &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "app", Operator: "Bogus"}}}
+
+	type call struct {
+		owner      string
+		generation int64
+		selector   *metav1.LabelSelector
+	}
+	tests := []struct {
+		name           string
+		first          call
+		second         call
+		expectReused   bool
+		expectErr      bool
+		expectedLabels labels.Set
+	}{
+		{
+			name:           "same generation and selector",
+			first:          call{"vm", 1, app},
+			second:         call{"vm", 1, app.DeepCopy()},
+			expectReused:   true,
+			expectedLabels: labels.Set{"app": "web"},
+		},
+		{
+			name:           "new generation",
+			first:          call{"vm", 1, app},
+			second:         call{"vm", 2, tier},
+			expectedLabels: labels.Set{"tier": "frontend"},
+		},
+		{
+			name:           "selector changed without a new generation",
+			first:          call{"vm", 1, app},
+			second:         call{"vm", 1, tier},
+			expectedLabels: labels.Set{"tier": "frontend"},
+		},
+		{
+			name:           "other VpaManager",
+			first:          call{"vm", 1, app},
+			second:         call{"other", 1, app},
+			expectedLabels: labels.Set{"app": "web"},
+		},
+		{
+			name:      "invalid selector",
+			first:     call{"vm", 1, invalid},
+			second:    call{"vm", 1, invalid},
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cache := NewCache()
+			first, _ := cache.Compile(tt.first.owner, tt.first.generation, "namespaceSelector", tt.first.selector)
+			second, err := cache.Compile(tt.second.owner, tt.second.generation, "namespaceSelector", tt.second.selector)
+			if tt.expectErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			// Compiled selectors are slices of requirements; reuse shares the backing array
+			reused := reflect.ValueOf(first).Pointer() == reflect.ValueOf(second).Pointer()
+			assert.Equal(t, tt.expectReused, reused, "compiled selector reuse")
+			assert.True(t, second.Matches(tt.expectedLabels))
+		})
+	}
+}
+
+func TestCache_Forget(t *testing.T) {
+	cache := NewCache()
+	selector := &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}}
+	for _, field := range []string{"namespaceSelector", "Deployment"} {
+		_, err := cache.Compile("vm", 1, field, selector)
+		require.NoError(t, err)
+	}
+	_, err := cache.Compile("other", 1, "Deployment", selector)
+	require.NoError(t, err)
+
+	cache.Forget("vm")
+	assert.Equal(t, 1, cache.Len())
+
+	var nilCache *Cache
+	compiled, err := nilCache.Compile("vm", 1, "Deployment", selector)
+	require.NoError(t, err)
+	assert.True(t, compiled.Matches(labels.Set{"app": "web"}))
+	nilCache.Forget("vm")
+	assert.Equal(t, 0, nilCache.Len())
+}
+
+func BenchmarkCache_Compile(b *testing.B) {
+	selector := &metav1.LabelSelector{
+		MatchLabels: map[string]string{"vpa-enabled": "true", "team": "payments"},
+		MatchExpressions: []metav1.LabelSelectorRequirement{
+			{Key: "tier", Operator: metav1.LabelSelectorOpIn, Values: []string{"frontend", "backend"}},
+		},
+	}
+	b.Run("uncached", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			_, _ = metav1.LabelSelectorAsSelector(selector)
+		}
+	})
+	b.Run("cached", func(b *testing.B) {
+		cache := NewCache()
+		for i := 0; i < b.N; i++ {
+			_, _ = cache.Compile("vm", 1, "Deployment", selector)
+		}
+	})
+}
+
+func TestCache_CompileAny(t *testing.T) {
+	app := &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}}
+	tier := &metav1.LabelSelector{MatchLabels: map[string]string{"tier": "frontend"}}
+	invalid := &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "app", Operator: "Bogus"}}}
+
+	tests := []struct {
+		name        string
+		selectors   []*metav1.LabelSelector
+		labels      labels.Set
+		expectMatch bool
+		expectErr   bool
+	}{
+		{name: "none", labels: labels.Set{"app": "web"}},
+		{name: "first matches", selectors: []*metav1.LabelSelector{app, tier}, labels: labels.Set{"app": "web"}, expectMatch: true},
+		{name: "second matches", selectors: []*metav1.LabelSelector{app, tier}, labels: labels.Set{"tier": "frontend"}, expectMatch: true},
+		{name: "neither matches", selectors: []*metav1.LabelSelector{app, tier}, labels: labels.Set{"app": "api"}},
+		{name: "invalid alternative", selectors: []*metav1.LabelSelector{app, invalid}, expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cache := NewCache()
+			compiled, err := cache.CompileAny("vm", 1, "namespaceSelector", tt.selectors)
+			if tt.expectErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectMatch, compiled.Matches(tt.labels))
+			assert.Equal(t, len(tt.selectors), cache.Len())
+		})
+	}
+}
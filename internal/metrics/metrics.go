@@ -4,6 +4,7 @@ import (
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 )
 
 // Error types for metrics classification
@@ -22,6 +23,12 @@ const (
 	ResultError   = "error"
 )
 
+// Objects of the write conflicts metric
+const (
+	ConflictObjectVPA    = "vpa"
+	ConflictObjectStatus = "status"
+)
+
 // Metrics holds all the Prometheus metrics for the VPA operator
 // Following RED principle: Rate, Errors, Duration
 type Metrics struct {
@@ -31,6 +38,9 @@ type Metrics struct {
 	// ReconcileDuration is the duration of reconciliation in seconds (RED: Duration)
 	ReconcileDuration *prometheus.HistogramVec
 
+	// ReconcilePhaseDuration is the time a reconcile spent in each phase in seconds
+	ReconcilePhaseDuration *prometheus.HistogramVec
+
 	// ManagedVPAs is the number of VPAs managed by the operator (operator state gauge)
 	ManagedVPAs *prometheus.GaugeVec
 
@@ -45,6 +55,99 @@ type Metrics struct {
 
 	// VPAOperationsTotal is the total number of VPA lifecycle operations
 	VPAOperationsTotal *prometheus.CounterVec
+
+	// NamespaceVPAOperationsTotal is the number of VPA lifecycle operations per workload kind and namespace
+	NamespaceVPAOperationsTotal *prometheus.CounterVec
+
+	// VPAWriteDuration is the API latency of VPA create/update/delete calls, excluding operator logic
+	VPAWriteDuration *prometheus.HistogramVec
+
+	// VPAOperationErrorsTotal is the total number of failed VPA writes (RED: Errors)
+	VPAOperationErrorsTotal *prometheus.CounterVec
+
+	// RightsizingScore is the ratio of VPA targets to container requests per VpaManager (operator state gauge)
+	RightsizingScore *prometheus.GaugeVec
+
+	// WebhookTimeoutsTotal is the number of webhook requests whose client calls ran out of time
+	WebhookTimeoutsTotal *prometheus.CounterVec
+
+	// WebhookRateLimitedTotal is the number of VPA writes the webhooks skipped over the per-namespace rate limit
+	WebhookRateLimitedTotal *prometheus.CounterVec
+
+	// WebhookSkippedUpdatesTotal is the number of workload updates the webhooks skipped because they could not change the VPA
+	WebhookSkippedUpdatesTotal *prometheus.CounterVec
+
+	// UpdateModeTransitionsTotal is the number of update mode changes the operator wrote to VPAs by deciding layer
+	UpdateModeTransitionsTotal *prometheus.CounterVec
+
+	// ReconcileChunksTotal is the number of reconciles that stopped at the reconcile budget and requeued the rest of their pass
+	ReconcileChunksTotal *prometheus.CounterVec
+
+	// WorkloadsSkippedTotal is the number of selected workloads a reconcile deliberately did not manage a VPA for, by reason
+	WorkloadsSkippedTotal *prometheus.CounterVec
+
+	// CheckpointsCopiedTotal is the number of recommender checkpoints copied to new VPAs from the VPA their workload was migrated from
+	CheckpointsCopiedTotal *prometheus.CounterVec
+
+	// WriteConflictsTotal is the number of reconciler writes that conflicted with a concurrent write and were retried
+	WriteConflictsTotal *prometheus.CounterVec
+
+	// ForbiddenNamespaces is the number of namespaces the operator may not list workloads in (operator state gauge)
+	ForbiddenNamespaces *prometheus.GaugeVec
+
+	// PausedNamespaces is the number of matching namespaces paused by annotation (operator state gauge)
+	PausedNamespaces *prometheus.GaugeVec
+
+	// ForeignVPAs is the number of selected workloads whose VPA belongs to another operator instance (operator state gauge)
+	ForeignVPAs *prometheus.GaugeVec
+
+	// ConflictingWorkloads is the number of selected workloads other VpaManagers select too (operator state gauge)
+	ConflictingWorkloads *prometheus.GaugeVec
+
+	// PendingOrphanDeletions is the number of orphaned VPAs held back by burst protection (operator state gauge)
+	PendingOrphanDeletions *prometheus.GaugeVec
+
+	// OrphanVPAsDeletedTotal is the total number of orphaned VPAs deleted by reconciles per VpaManager
+	OrphanVPAsDeletedTotal *prometheus.CounterVec
+
+	// OrphanScanDuration is the duration of the orphan VPA scan of a reconcile in seconds
+	OrphanScanDuration *prometheus.HistogramVec
+
+	// SimulationsTotal is the total number of simulation requests by outcome (RED: Rate + Errors)
+	SimulationsTotal *prometheus.CounterVec
+
+	// OrphanSweepsTotal is the total number of cluster-wide orphan sweeps by outcome (RED: Rate + Errors)
+	OrphanSweepsTotal *prometheus.CounterVec
+
+	// OrphanSweepDeletionsTotal is the total number of VPAs deleted by the orphan sweeper by reason
+	OrphanSweepDeletionsTotal *prometheus.CounterVec
+
+	// OrphanSweepUnverifiable is the number of managed VPAs whose target the last sweep could not read (operator state gauge)
+	OrphanSweepUnverifiable prometheus.Gauge
+
+	// VPAServed is 1 while the API server serves VerticalPodAutoscalers, 0 while the CRD is missing (operator state gauge)
+	VPAServed prometheus.Gauge
+
+	// BootstrapVPAs is the number of Off-mode VPAs in place for the autoscaling stack with --bootstrap-vpas (operator state gauge)
+	BootstrapVPAs prometheus.Gauge
+
+	// WorkloadKindEnabled is 1 per workload kind the API server serves and the operator manages, 0 while it is missing (operator state gauge)
+	WorkloadKindEnabled *prometheus.GaugeVec
+
+	// MissingRBAC is 1 per permission the startup self-check found missing, 0 once granted (operator state gauge)
+	MissingRBAC *prometheus.GaugeVec
+
+	// ClusterManagedVPAs is the number of VPAs counted against --max-managed-vpas (operator state gauge)
+	ClusterManagedVPAs prometheus.Gauge
+
+	// ClusterVPALimit is the --max-managed-vpas cap, 0 when there is none (operator state gauge)
+	ClusterVPALimit prometheus.Gauge
+
+	// WebhookCertExpiry is the expiry of the webhook serving certificate as a Unix timestamp
+	WebhookCertExpiry prometheus.Gauge
+
+	// namespaces caps the distinct namespace label values
+	namespaces *namespaceLimiter
 }
 
 // NewMetrics creates and registers all metrics with the given registry
@@ -54,6 +157,8 @@ type Metrics struct {
 // - Duration: histogram of operation latencies
 func NewMetrics(reg prometheus.Registerer) *Metrics {
 	m := &Metrics{
+		namespaces: newNamespaceLimiter(DefaultMaxNamespaceLabels),
+
 		// RED: Rate + Errors (combined via result label)
 		ReconcileTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
 			Name: "vpa_operator_reconcile_total",
@@ -67,6 +172,12 @@ func NewMetrics(reg prometheus.Registerer) *Metrics {
 			Buckets: prometheus.DefBuckets,
 		}, []string{"vpamanager", "result"}),
 
+		ReconcilePhaseDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "vpa_operator_reconcile_phase_duration_seconds",
+			Help:    "Time a reconcile spent in each phase (list_namespaces, list_workloads, ensure_vpas, cleanup, status) in seconds",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"vpamanager", "phase"}),
+
 		// Operator state gauges (not RED, but useful for capacity planning)
 		ManagedVPAs: prometheus.NewGaugeVec(prometheus.GaugeOpts{
 			Name: "vpa_operator_managed_vpas",
@@ -81,31 +192,239 @@ func NewMetrics(reg prometheus.Registerer) *Metrics {
 		// RED: Rate + Errors (combined via result label)
 		WebhookRequestsTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
 			Name: "vpa_operator_webhook_requests_total",
-			Help: "Total number of webhook requests by operation, result, and error type",
-		}, []string{"operation", "result", "error_type"}),
+			Help: "Total number of webhook requests by operation, VpaManager, result, and error type",
+		}, []string{"operation", "vpamanager", "result", "error_type"}),
 
 		// RED: Duration
 		WebhookDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
 			Name:    "vpa_operator_webhook_duration_seconds",
-			Help:    "Duration of webhook operations in seconds",
+			Help:    "Duration of webhook operations in seconds by operation, VpaManager, and result",
 			Buckets: prometheus.DefBuckets,
-		}, []string{"operation", "result"}),
+		}, []string{"operation", "vpamanager", "result"}),
+
+		// VPA write latency, separate from reconcile duration to tell API server slowness from operator time
+		VPAWriteDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "vpa_operator_vpa_write_duration_seconds",
+			Help:    "Duration of VPA create, update and delete API calls in seconds by VpaManager",
+			Buckets: prometheus.ExponentialBuckets(0.005, 2, 12),
+		}, []string{"operation", "vpamanager"}),
 
 		// VPA lifecycle operations
 		VPAOperationsTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
 			Name: "vpa_operator_vpa_operations_total",
 			Help: "Total number of VPA lifecycle operations (create, delete, update)",
 		}, []string{"operation", "vpamanager"}),
+
+		// Per-namespace breakdown, namespaces past the label limit are counted as _other
+		NamespaceVPAOperationsTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "vpa_operator_namespace_vpa_operations_total",
+			Help: "VPA lifecycle operations by workload kind and namespace, with namespaces past the label limit counted as _other",
+		}, []string{"vpamanager", "kind", "namespace", "operation"}),
+
+		// RED: Errors for VPA writes, e.g. rejections by the VPA admission controller
+		VPAOperationErrorsTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "vpa_operator_vpa_operation_errors_total",
+			Help: "Total number of failed VPA lifecycle operations by error type",
+		}, []string{"operation", "vpamanager", "error_type"}),
+
+		// Fleet efficiency rollup, 1 means requests match recommendations exactly
+		RightsizingScore: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "vpa_operator_rightsizing_score",
+			Help: "Weighted ratio (0-1) of how closely container requests match VPA target recommendations per VpaManager",
+		}, []string{"vpamanager"}),
+
+		// Webhook client calls cut short to stay within the admission timeout
+		WebhookTimeoutsTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "vpa_operator_webhook_timeouts_total",
+			Help: "Total number of webhook requests that returned early because client calls exceeded their deadline",
+		}, []string{"webhook", "vpamanager"}),
+
+		// Webhook VPA writes left to the reconciler by the per-namespace rate limit
+		WebhookRateLimitedTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "vpa_operator_webhook_rate_limited_total",
+			Help: "Total number of VPA writes skipped by the webhooks over the per-namespace rate limit and left to the reconciler",
+		}, []string{"webhook", "vpamanager"}),
+
+		// Workload updates, e.g. status-only ones, that leave every VPA input unchanged
+		WebhookSkippedUpdatesTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "vpa_operator_webhook_skipped_updates_total",
+			Help: "Total number of workload updates the webhooks skipped because spec, labels and annotations were unchanged",
+		}, []string{"webhook"}),
+
+		// Update mode changes of existing VPAs, to detect a flapping mode
+		UpdateModeTransitionsTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "vpa_operator_update_mode_transitions_total",
+			Help: "Total number of updateMode changes written to existing VPAs by previous mode, new mode and the policy layer that decided it",
+		}, []string{"vpamanager", "from", "to", "layer"}),
+
+		// Passes split across reconciles by the reconcile budget
+		ReconcileChunksTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "vpa_operator_reconcile_chunks_total",
+			Help: "Total number of reconciles that exceeded the reconcile budget or namespace limit and continued their pass in a requeue",
+		}, []string{"vpamanager"}),
+
+		// Workloads deliberately left without a VPA of the VpaManager
+		WorkloadsSkippedTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "vpa_operator_workloads_skipped_total",
+			Help: "Total number of selected workloads a reconcile deliberately did not manage a VPA for, by VpaManager and reason",
+		}, []string{"vpamanager", "reason"}),
+
+		// Checkpoint warm-start of VPAs replacing another VPA of the same workload
+		CheckpointsCopiedTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "vpa_operator_checkpoints_copied_total",
+			Help: "Total number of VPA recommender checkpoints copied to new VPAs from the VPA their workload was migrated from",
+		}, []string{"vpamanager"}),
+
+		// Reconciler writes racing the webhooks or another reconcile
+		WriteConflictsTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "vpa_operator_write_conflicts_total",
+			Help: "Total number of reconciler writes retried after conflicting with a concurrent write, by written object",
+		}, []string{"vpamanager", "object"}),
+
+		// Partial RBAC: namespaces skipped because listing workloads was forbidden
+		ForbiddenNamespaces: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "vpa_operator_forbidden_namespaces",
+			Help: "Number of matching namespaces where listing workloads was forbidden per VpaManager",
+		}, []string{"vpamanager"}),
+
+		// Incident response: namespaces whose VPAs are frozen by annotation
+		PausedNamespaces: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "vpa_operator_paused_namespaces",
+			Help: "Number of matching namespaces whose VPAs are frozen by the paused annotation per VpaManager",
+		}, []string{"vpamanager"}),
+
+		// Multi-instance safety: VPAs generated by another operator instance
+		ForeignVPAs: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "vpa_operator_foreign_vpas",
+			Help: "Number of selected workloads whose VPA was generated by another operator instance per VpaManager",
+		}, []string{"vpamanager"}),
+
+		// Overlap detection: workloads several VpaManagers select
+		ConflictingWorkloads: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "vpa_operator_conflicting_workloads",
+			Help: "Number of selected workloads that other enabled VpaManagers select too per VpaManager",
+		}, []string{"vpamanager"}),
+
+		// Burst protection: orphan deletions awaiting confirmation or the grace period
+		PendingOrphanDeletions: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "vpa_operator_pending_orphan_deletions",
+			Help: "Number of orphaned VPAs held back by burst protection per VpaManager",
+		}, []string{"vpamanager"}),
+
+		// Orphan cleanup of reconciles, apart from the generic delete operations
+		OrphanVPAsDeletedTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "vpa_operator_orphan_vpas_deleted_total",
+			Help: "Total number of orphaned VPAs deleted by reconciles per VpaManager",
+		}, []string{"vpamanager"}),
+
+		OrphanScanDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "vpa_operator_orphan_scan_duration_seconds",
+			Help:    "Duration of listing the managed VPAs of a VpaManager to find orphans, in seconds",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"vpamanager"}),
+
+		// Dry-run evaluations of workload manifests, e.g. from CI pipelines
+		SimulationsTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "vpa_operator_simulations_total",
+			Help: "Total number of simulation requests by result (matched, no_match, error)",
+		}, []string{"result"}),
+
+		// Cluster-wide orphan sweeper
+		OrphanSweepsTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "vpa_operator_orphan_sweeps_total",
+			Help: "Total number of cluster-wide orphan sweeps by result (success, error, forbidden)",
+		}, []string{"result"}),
+
+		OrphanSweepDeletionsTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "vpa_operator_orphan_sweep_deletions_total",
+			Help: "Total number of orphaned VPAs deleted by the orphan sweeper by reason",
+		}, []string{"reason"}),
+
+		OrphanSweepUnverifiable: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "vpa_operator_orphan_sweep_unverifiable_vpas",
+			Help: "Number of managed VPAs the last orphan sweep kept because their target could not be read",
+		}),
+
+		// Graceful degradation while the VPA CRD is missing
+		VPAServed: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "vpa_operator_vpa_crd_served",
+			Help: "Whether the API server serves VerticalPodAutoscalers (1) or the VPA CRD is missing (0), as last discovered",
+		}),
+
+		// Off-mode VPAs of the autoscaling stack itself
+		BootstrapVPAs: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "vpa_operator_bootstrap_vpas",
+			Help: "Number of Off-mode VPAs in place for the VPA components and the operator, as last ensured with --bootstrap-vpas",
+		}),
+
+		// Workload kinds enabled by cluster capability
+		WorkloadKindEnabled: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "vpa_operator_workload_kind_enabled",
+			Help: "Whether the API server serves a configured workload kind and the operator manages it (1) or not (0)",
+		}, []string{"kind"}),
+
+		// Startup RBAC self-check
+		MissingRBAC: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "vpa_operator_missing_rbac",
+			Help: "Whether the operator lacks a permission it needs (1) or has it (0), by resource and verb, as last checked",
+		}, []string{"resource", "verb"}),
+
+		// Cluster-wide cap on managed VPAs
+		ClusterManagedVPAs: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "vpa_operator_cluster_managed_vpas",
+			Help: "Number of VPAs managed by this operator instance across the cluster, as counted against the cap",
+		}),
+		ClusterVPALimit: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "vpa_operator_cluster_vpa_limit",
+			Help: "Maximum number of VPAs this operator instance manages across the cluster, 0 when unlimited",
+		}),
+
+		// Webhook serving certificate
+		WebhookCertExpiry: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "vpa_operator_webhook_cert_expiry_timestamp_seconds",
+			Help: "Expiry of the webhook serving certificate as a Unix timestamp",
+		}),
 	}
 
 	reg.MustRegister(
 		m.ReconcileTotal,
 		m.ReconcileDuration,
+		m.ReconcilePhaseDuration,
 		m.ManagedVPAs,
 		m.WatchedDeployments,
 		m.WebhookRequestsTotal,
 		m.WebhookDuration,
 		m.VPAOperationsTotal,
+		m.NamespaceVPAOperationsTotal,
+		m.VPAWriteDuration,
+		m.VPAOperationErrorsTotal,
+		m.RightsizingScore,
+		m.WebhookTimeoutsTotal,
+		m.WebhookRateLimitedTotal,
+		m.WebhookSkippedUpdatesTotal,
+		m.UpdateModeTransitionsTotal,
+		m.ReconcileChunksTotal,
+		m.WorkloadsSkippedTotal,
+		m.CheckpointsCopiedTotal,
+		m.WriteConflictsTotal,
+		m.ForbiddenNamespaces,
+		m.PausedNamespaces,
+		m.ForeignVPAs,
+		m.ConflictingWorkloads,
+		m.PendingOrphanDeletions,
+		m.OrphanVPAsDeletedTotal,
+		m.OrphanScanDuration,
+		m.SimulationsTotal,
+		m.OrphanSweepsTotal,
+		m.OrphanSweepDeletionsTotal,
+		m.OrphanSweepUnverifiable,
+		m.VPAServed,
+		m.BootstrapVPAs,
+		m.WorkloadKindEnabled,
+		m.MissingRBAC,
+		m.ClusterManagedVPAs,
+		m.ClusterVPALimit,
+		m.WebhookCertExpiry,
 	)
 
 	return m
@@ -120,13 +439,33 @@ func (m *Metrics) RecordReconcile(vpaManagerName string, start time.Time, err er
 	m.ReconcileDuration.WithLabelValues(vpaManagerName, result).Observe(duration)
 }
 
-// RecordWebhookRequest records a webhook request following RED principle
-func (m *Metrics) RecordWebhookRequest(operation string, start time.Time, err error) {
+// Reconcile phases of vpa_operator_reconcile_phase_duration_seconds
+const (
+	PhaseListNamespaces = "list_namespaces"
+	PhaseListWorkloads  = "list_workloads"
+	PhaseEnsureVPAs     = "ensure_vpas"
+	PhaseCleanup        = "cleanup"
+	PhaseStatus         = "status"
+)
+
+// ObserveReconcilePhase records the time a reconcile of a VpaManager spent in a phase
+func (m *Metrics) ObserveReconcilePhase(vpaManagerName, phase string, duration time.Duration) {
+	m.ReconcilePhaseDuration.WithLabelValues(vpaManagerName, phase).Observe(duration.Seconds())
+}
+
+// RecordWebhookRequest records a webhook request following RED principle. vpaManagerName
+// is the VpaManager the request was attributed to, empty when none matched.
+func (m *Metrics) RecordWebhookRequest(operation, vpaManagerName string, start time.Time, err error) {
 	duration := time.Since(start).Seconds()
 	result, errorType := classifyResult(err)
 
-	m.WebhookRequestsTotal.WithLabelValues(operation, result, errorType).Inc()
-	m.WebhookDuration.WithLabelValues(operation, result).Observe(duration)
+	m.WebhookRequestsTotal.WithLabelValues(operation, vpaManagerName, result, errorType).Inc()
+	m.WebhookDuration.WithLabelValues(operation, vpaManagerName, result).Observe(duration)
+}
+
+// ObserveVPAWrite records the latency of a VPA create, update or delete call made for a VpaManager
+func (m *Metrics) ObserveVPAWrite(operation, vpaManagerName string, start time.Time) {
+	m.VPAWriteDuration.WithLabelValues(operation, vpaManagerName).Observe(time.Since(start).Seconds())
 }
 
 // UpdateManagedResources updates the managed VPAs and watched deployments gauges
@@ -140,6 +479,170 @@ func (m *Metrics) RecordVPAOperation(operation, vpaManagerName string) {
 	m.VPAOperationsTotal.WithLabelValues(operation, vpaManagerName).Inc()
 }
 
+// RecordVPAOperationError records a failed VPA lifecycle operation classified by error type
+func (m *Metrics) RecordVPAOperationError(operation, vpaManagerName string, err error) {
+	m.VPAOperationErrorsTotal.WithLabelValues(operation, vpaManagerName, ClassifyError(err)).Inc()
+}
+
+// SetRightsizingScore records the rightsizing score (0-1) of a VpaManager
+func (m *Metrics) SetRightsizingScore(vpaManagerName string, score float64) {
+	m.RightsizingScore.WithLabelValues(vpaManagerName).Set(score)
+}
+
+// ClearRightsizingScore removes the rightsizing score of a VpaManager that has none or is gone
+func (m *Metrics) ClearRightsizingScore(vpaManagerName string) {
+	m.RightsizingScore.DeleteLabelValues(vpaManagerName)
+}
+
+// RecordWebhookTimeout records a webhook request that returned early after its client deadline
+// passed. vpaManagerName is empty when the deadline passed before a VpaManager matched.
+func (m *Metrics) RecordWebhookTimeout(webhook, vpaManagerName string) {
+	m.WebhookTimeoutsTotal.WithLabelValues(webhook, vpaManagerName).Inc()
+}
+
+// RecordWebhookRateLimited records a VPA write a webhook skipped over the per-namespace rate limit
+func (m *Metrics) RecordWebhookRateLimited(webhook, vpaManagerName string) {
+	m.WebhookRateLimitedTotal.WithLabelValues(webhook, vpaManagerName).Inc()
+}
+
+// RecordWebhookUpdateSkipped records a workload update a webhook skipped because it could not change the VPA
+func (m *Metrics) RecordWebhookUpdateSkipped(webhook string) {
+	m.WebhookSkippedUpdatesTotal.WithLabelValues(webhook).Inc()
+}
+
+// RecordUpdateModeTransition records an update mode change written to an existing VPA and the
+// policy layer that decided the new mode
+func (m *Metrics) RecordUpdateModeTransition(vpaManagerName, from, to, layer string) {
+	m.UpdateModeTransitionsTotal.WithLabelValues(vpaManagerName, from, to, layer).Inc()
+}
+
+// RecordWriteConflict records a reconciler write of object that conflicted and is retried
+func (m *Metrics) RecordWriteConflict(vpaManagerName, object string) {
+	m.WriteConflictsTotal.WithLabelValues(vpaManagerName, object).Inc()
+}
+
+// RecordReconcileChunk records a reconcile that stopped at the budget and requeued the rest of its pass
+func (m *Metrics) RecordReconcileChunk(vpaManagerName string) {
+	m.ReconcileChunksTotal.WithLabelValues(vpaManagerName).Inc()
+}
+
+// RecordWorkloadSkipped records a selected workload a reconcile deliberately did not manage a VPA for
+func (m *Metrics) RecordWorkloadSkipped(vpaManagerName, reason string) {
+	m.WorkloadsSkippedTotal.WithLabelValues(vpaManagerName, reason).Inc()
+}
+
+// RecordCheckpointsCopied records recommender checkpoints copied to a new VPA
+func (m *Metrics) RecordCheckpointsCopied(vpaManagerName string, count int) {
+	m.CheckpointsCopiedTotal.WithLabelValues(vpaManagerName).Add(float64(count))
+}
+
+// SetForbiddenNamespaces records how many namespaces a VpaManager was denied access to
+func (m *Metrics) SetForbiddenNamespaces(vpaManagerName string, count int) {
+	m.ForbiddenNamespaces.WithLabelValues(vpaManagerName).Set(float64(count))
+}
+
+// SetPausedNamespaces records how many of a VpaManager's namespaces are paused
+func (m *Metrics) SetPausedNamespaces(vpaManagerName string, count int) {
+	m.PausedNamespaces.WithLabelValues(vpaManagerName).Set(float64(count))
+}
+
+// SetForeignVPAs records how many selected workloads have a VPA of another operator instance
+func (m *Metrics) SetForeignVPAs(vpaManagerName string, count int) {
+	m.ForeignVPAs.WithLabelValues(vpaManagerName).Set(float64(count))
+}
+
+// SetConflictingWorkloads records how many selected workloads other VpaManagers select too
+func (m *Metrics) SetConflictingWorkloads(vpaManagerName string, count int) {
+	m.ConflictingWorkloads.WithLabelValues(vpaManagerName).Set(float64(count))
+}
+
+// SetPendingOrphanDeletions records how many orphaned VPAs a VpaManager is holding back
+func (m *Metrics) SetPendingOrphanDeletions(vpaManagerName string, count int) {
+	m.PendingOrphanDeletions.WithLabelValues(vpaManagerName).Set(float64(count))
+}
+
+// ObserveOrphanScan records how long a VpaManager took to find its orphaned VPAs
+func (m *Metrics) ObserveOrphanScan(vpaManagerName string, start time.Time) {
+	m.OrphanScanDuration.WithLabelValues(vpaManagerName).Observe(time.Since(start).Seconds())
+}
+
+// RecordOrphanVPAsDeleted records orphaned VPAs deleted by a reconcile
+func (m *Metrics) RecordOrphanVPAsDeleted(vpaManagerName string, count int) {
+	m.OrphanVPAsDeletedTotal.WithLabelValues(vpaManagerName).Add(float64(count))
+}
+
+// RecordSimulation records the outcome of a simulation request
+func (m *Metrics) RecordSimulation(matched bool, err error) {
+	result := "no_match"
+	switch {
+	case err != nil:
+		result = ResultError
+	case matched:
+		result = "matched"
+	}
+	m.SimulationsTotal.WithLabelValues(result).Inc()
+}
+
+// RecordOrphanSweep records the outcome of an orphan sweep and how many VPAs it could not verify
+func (m *Metrics) RecordOrphanSweep(result string, unverifiable int) {
+	m.OrphanSweepsTotal.WithLabelValues(result).Inc()
+	m.OrphanSweepUnverifiable.Set(float64(unverifiable))
+}
+
+// SetVPAServed records whether the API server serves VerticalPodAutoscalers
+func (m *Metrics) SetVPAServed(served bool) {
+	value := 0.0
+	if served {
+		value = 1
+	}
+	m.VPAServed.Set(value)
+}
+
+// SetBootstrapVPAs records the number of Off-mode VPAs in place for the autoscaling stack
+func (m *Metrics) SetBootstrapVPAs(count int) {
+	m.BootstrapVPAs.Set(float64(count))
+}
+
+// SetWorkloadKindEnabled records whether the operator manages workloads of kind
+func (m *Metrics) SetWorkloadKindEnabled(kind string, enabled bool) {
+	value := 0.0
+	if enabled {
+		value = 1
+	}
+	m.WorkloadKindEnabled.WithLabelValues(kind).Set(value)
+}
+
+// SetMissingRBAC records whether the operator lacks the permission to verb resource
+func (m *Metrics) SetMissingRBAC(resource, verb string, missing bool) {
+	value := 0.0
+	if missing {
+		value = 1
+	}
+	m.MissingRBAC.WithLabelValues(resource, verb).Set(value)
+}
+
+// SetClusterCapacity records the VPAs counted against the cluster-wide cap and the cap itself
+func (m *Metrics) SetClusterCapacity(count, limit int) {
+	m.ClusterManagedVPAs.Set(float64(count))
+	m.ClusterVPALimit.Set(float64(limit))
+}
+
+// SetMaxNamespaceLabels sets the number of distinct namespace label values, later namespaces
+// are recorded as OtherNamespace
+func (m *Metrics) SetMaxNamespaceLabels(max int) {
+	m.namespaces.setMax(max)
+}
+
+// SetWebhookCertExpiry records the expiry of the webhook serving certificate
+func (m *Metrics) SetWebhookCertExpiry(notAfter time.Time) {
+	m.WebhookCertExpiry.Set(float64(notAfter.Unix()))
+}
+
+// RecordOrphanSweepDeletion records a VPA deleted by the orphan sweeper
+func (m *Metrics) RecordOrphanSweepDeletion(reason string) {
+	m.OrphanSweepDeletionsTotal.WithLabelValues(reason).Inc()
+}
+
 // classifyResult returns the result label and error type for a given error
 func classifyResult(err error) (result, errorType string) {
 	if err == nil {
@@ -154,6 +657,18 @@ func ClassifyError(err error) string {
 		return ""
 	}
 
+	// API status errors carry their reason, whatever their message says
+	switch {
+	case apierrors.IsNotFound(err):
+		return ErrorTypeNotFound
+	case apierrors.IsConflict(err), apierrors.IsAlreadyExists(err):
+		return ErrorTypeConflict
+	case apierrors.IsInvalid(err):
+		return ErrorTypeValidation
+	case apierrors.IsTimeout(err), apierrors.IsServerTimeout(err), apierrors.IsServiceUnavailable(err), apierrors.IsTooManyRequests(err):
+		return ErrorTypeAPIServer
+	}
+
 	errStr := err.Error()
 
 	// Check for common Kubernetes API error patterns
@@ -162,7 +677,7 @@ func ClassifyError(err error) string {
 		return ErrorTypeNotFound
 	case containsAny(errStr, "conflict", "Conflict", "already exists"):
 		return ErrorTypeConflict
-	case containsAny(errStr, "validation", "invalid", "Invalid"):
+	case containsAny(errStr, "validation", "invalid", "Invalid", "denied the request"):
 		return ErrorTypeValidation
 	case containsAny(errStr, "connection refused", "timeout", "context deadline"):
 		return ErrorTypeAPIServer
@@ -1,6 +1,8 @@
 package metrics
 
 import (
+	"errors"
+	"fmt"
 	"testing"
 	"time"
 
@@ -8,6 +10,8 @@ import (
 	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 )
 
 // Test: vpa_operator_reconcile_total metric (RED: Rate + Errors)
@@ -75,18 +79,18 @@ func TestMetrics_WebhookRequestsTotal(t *testing.T) {
 	m := NewMetrics(reg)
 
 	// Track requests by operation type and result
-	m.WebhookRequestsTotal.WithLabelValues("CREATE", ResultSuccess, "").Inc()
-	m.WebhookRequestsTotal.WithLabelValues("CREATE", ResultSuccess, "").Inc()
-	m.WebhookRequestsTotal.WithLabelValues("DELETE", ResultSuccess, "").Inc()
-	m.WebhookRequestsTotal.WithLabelValues("CREATE", ResultError, ErrorTypeNotFound).Inc()
+	m.WebhookRequestsTotal.WithLabelValues("CREATE", "test-manager", ResultSuccess, "").Inc()
+	m.WebhookRequestsTotal.WithLabelValues("CREATE", "test-manager", ResultSuccess, "").Inc()
+	m.WebhookRequestsTotal.WithLabelValues("DELETE", "test-manager", ResultSuccess, "").Inc()
+	m.WebhookRequestsTotal.WithLabelValues("CREATE", "test-manager", ResultError, ErrorTypeNotFound).Inc()
 
-	createSuccessCount := testutil.ToFloat64(m.WebhookRequestsTotal.WithLabelValues("CREATE", ResultSuccess, ""))
+	createSuccessCount := testutil.ToFloat64(m.WebhookRequestsTotal.WithLabelValues("CREATE", "test-manager", ResultSuccess, ""))
 	assert.Equal(t, float64(2), createSuccessCount)
 
-	deleteSuccessCount := testutil.ToFloat64(m.WebhookRequestsTotal.WithLabelValues("DELETE", ResultSuccess, ""))
+	deleteSuccessCount := testutil.ToFloat64(m.WebhookRequestsTotal.WithLabelValues("DELETE", "test-manager", ResultSuccess, ""))
 	assert.Equal(t, float64(1), deleteSuccessCount)
 
-	createErrorCount := testutil.ToFloat64(m.WebhookRequestsTotal.WithLabelValues("CREATE", ResultError, ErrorTypeNotFound))
+	createErrorCount := testutil.ToFloat64(m.WebhookRequestsTotal.WithLabelValues("CREATE", "test-manager", ResultError, ErrorTypeNotFound))
 	assert.Equal(t, float64(1), createErrorCount)
 }
 
@@ -95,9 +99,9 @@ func TestMetrics_WebhookDuration(t *testing.T) {
 	reg := prometheus.NewRegistry()
 	m := NewMetrics(reg)
 
-	m.WebhookDuration.WithLabelValues("CREATE", ResultSuccess).Observe(0.05)
-	m.WebhookDuration.WithLabelValues("CREATE", ResultSuccess).Observe(0.02)
-	m.WebhookDuration.WithLabelValues("DELETE", ResultError).Observe(0.01)
+	m.WebhookDuration.WithLabelValues("CREATE", "test-manager", ResultSuccess).Observe(0.05)
+	m.WebhookDuration.WithLabelValues("CREATE", "test-manager", ResultSuccess).Observe(0.02)
+	m.WebhookDuration.WithLabelValues("DELETE", "test-manager", ResultError).Observe(0.01)
 
 	count := testutil.CollectAndCount(m.WebhookDuration)
 	assert.Equal(t, 2, count, "should have histogram metrics for each operation/result combination")
@@ -135,6 +139,36 @@ func TestMetrics_AllMetricsRegistered(t *testing.T) {
 		"vpa_operator_webhook_requests_total",
 		"vpa_operator_webhook_duration_seconds",
 		"vpa_operator_vpa_operations_total",
+		"vpa_operator_vpa_write_duration_seconds",
+		"vpa_operator_vpa_operation_errors_total",
+		"vpa_operator_rightsizing_score",
+		"vpa_operator_webhook_timeouts_total",
+		"vpa_operator_webhook_rate_limited_total",
+		"vpa_operator_webhook_skipped_updates_total",
+		"vpa_operator_update_mode_transitions_total",
+		"vpa_operator_write_conflicts_total",
+		"vpa_operator_forbidden_namespaces",
+		"vpa_operator_paused_namespaces",
+		"vpa_operator_pending_orphan_deletions",
+		"vpa_operator_orphan_vpas_deleted_total",
+		"vpa_operator_orphan_scan_duration_seconds",
+		"vpa_operator_reconcile_phase_duration_seconds",
+		"vpa_operator_foreign_vpas",
+		"vpa_operator_conflicting_workloads",
+		"vpa_operator_orphan_sweeps_total",
+		"vpa_operator_orphan_sweep_deletions_total",
+		"vpa_operator_orphan_sweep_unverifiable_vpas",
+		"vpa_operator_vpa_crd_served",
+		"vpa_operator_bootstrap_vpas",
+		"vpa_operator_workload_kind_enabled",
+		"vpa_operator_missing_rbac",
+		"vpa_operator_reconcile_chunks_total",
+		"vpa_operator_workloads_skipped_total",
+		"vpa_operator_checkpoints_copied_total",
+		"vpa_operator_cluster_managed_vpas",
+		"vpa_operator_cluster_vpa_limit",
+		"vpa_operator_webhook_cert_expiry_timestamp_seconds",
+		"vpa_operator_namespace_vpa_operations_total",
 	}
 
 	// Initialize all label combinations to ensure they appear
@@ -142,9 +176,33 @@ func TestMetrics_AllMetricsRegistered(t *testing.T) {
 	m.ReconcileDuration.WithLabelValues("test", ResultSuccess)
 	m.ManagedVPAs.WithLabelValues("test")
 	m.WatchedDeployments.WithLabelValues("test")
-	m.WebhookRequestsTotal.WithLabelValues("CREATE", ResultSuccess, "")
-	m.WebhookDuration.WithLabelValues("CREATE", ResultSuccess)
+	m.WebhookRequestsTotal.WithLabelValues("CREATE", "test-manager", ResultSuccess, "")
+	m.WebhookDuration.WithLabelValues("CREATE", "test-manager", ResultSuccess)
 	m.VPAOperationsTotal.WithLabelValues("create", "test")
+	m.NamespaceVPAOperationsTotal.WithLabelValues("test", "Deployment", "default", "create")
+	m.VPAOperationErrorsTotal.WithLabelValues("create", "test", ErrorTypeValidation)
+	m.RightsizingScore.WithLabelValues("test")
+	m.WebhookTimeoutsTotal.WithLabelValues("deployment", "test")
+	m.WebhookRateLimitedTotal.WithLabelValues("deployment", "test")
+	m.WebhookSkippedUpdatesTotal.WithLabelValues("deployment")
+	m.UpdateModeTransitionsTotal.WithLabelValues("test", "Auto", "Off", "dormancy")
+	m.WriteConflictsTotal.WithLabelValues("test", ConflictObjectVPA)
+	m.ForbiddenNamespaces.WithLabelValues("test")
+	m.PausedNamespaces.WithLabelValues("test")
+	m.VPAWriteDuration.WithLabelValues("create", "test")
+	m.PendingOrphanDeletions.WithLabelValues("test")
+	m.OrphanVPAsDeletedTotal.WithLabelValues("test")
+	m.OrphanScanDuration.WithLabelValues("test")
+	m.ReconcilePhaseDuration.WithLabelValues("test", PhaseEnsureVPAs)
+	m.ForeignVPAs.WithLabelValues("test")
+	m.ConflictingWorkloads.WithLabelValues("test")
+	m.OrphanSweepsTotal.WithLabelValues(ResultSuccess)
+	m.OrphanSweepDeletionsTotal.WithLabelValues("target_missing")
+	m.MissingRBAC.WithLabelValues("namespaces", "list")
+	m.WorkloadKindEnabled.WithLabelValues("Deployment")
+	m.ReconcileChunksTotal.WithLabelValues("test")
+	m.WorkloadsSkippedTotal.WithLabelValues("test", "ExcludedByAnnotation")
+	m.CheckpointsCopiedTotal.WithLabelValues("test")
 
 	metrics, err = reg.Gather()
 	require.NoError(t, err)
@@ -186,15 +244,15 @@ func TestMetrics_RecordWebhookRequest(t *testing.T) {
 
 	start := time.Now()
 	time.Sleep(5 * time.Millisecond)
-	m.RecordWebhookRequest("CREATE", start, nil)
+	m.RecordWebhookRequest("CREATE", "test-manager", start, nil)
 
-	assert.Equal(t, float64(1), testutil.ToFloat64(m.WebhookRequestsTotal.WithLabelValues("CREATE", ResultSuccess, "")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.WebhookRequestsTotal.WithLabelValues("CREATE", "test-manager", ResultSuccess, "")))
 
 	// Record with error
 	start = time.Now()
-	m.RecordWebhookRequest("DELETE", start, assert.AnError)
+	m.RecordWebhookRequest("DELETE", "", start, assert.AnError)
 
-	assert.Equal(t, float64(1), testutil.ToFloat64(m.WebhookRequestsTotal.WithLabelValues("DELETE", ResultError, ErrorTypeUnknown)))
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.WebhookRequestsTotal.WithLabelValues("DELETE", "", ResultError, ErrorTypeUnknown)))
 }
 
 func TestMetrics_UpdateManagedResources(t *testing.T) {
@@ -219,6 +277,31 @@ func TestMetrics_RecordVPAOperation(t *testing.T) {
 	assert.Equal(t, float64(1), testutil.ToFloat64(m.VPAOperationsTotal.WithLabelValues("delete", "manager-1")))
 }
 
+func TestMetrics_RecordVPAOperationError(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	m := NewMetrics(reg)
+
+	m.RecordVPAOperationError("create", "manager-1", errors.New(`admission webhook "vpa.k8s.io" denied the request: bad policy`))
+	m.RecordVPAOperationError("update", "manager-1", assert.AnError)
+
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.VPAOperationErrorsTotal.WithLabelValues("create", "manager-1", ErrorTypeValidation)))
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.VPAOperationErrorsTotal.WithLabelValues("update", "manager-1", ErrorTypeUnknown)))
+}
+
+func TestMetrics_SetRightsizingScore(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	m := NewMetrics(reg)
+
+	m.SetRightsizingScore("manager-1", 0.82)
+	m.SetRightsizingScore("manager-2", 0.4)
+
+	assert.Equal(t, 0.82, testutil.ToFloat64(m.RightsizingScore.WithLabelValues("manager-1")))
+	assert.Equal(t, 0.4, testutil.ToFloat64(m.RightsizingScore.WithLabelValues("manager-2")))
+
+	m.ClearRightsizingScore("manager-2")
+	assert.Equal(t, 1, testutil.CollectAndCount(m.RightsizingScore), "the cleared series is no longer exported")
+}
+
 // Test: Metrics descriptions match README documentation
 func TestMetrics_DescriptionsMatchDocumentation(t *testing.T) {
 	reg := prometheus.NewRegistry()
@@ -228,8 +311,8 @@ func TestMetrics_DescriptionsMatchDocumentation(t *testing.T) {
 	m.ReconcileTotal.WithLabelValues("test", ResultSuccess, "")
 	m.ReconcileDuration.WithLabelValues("test", ResultSuccess)
 	m.ManagedVPAs.WithLabelValues("test")
-	m.WebhookRequestsTotal.WithLabelValues("CREATE", ResultSuccess, "")
-	m.WebhookDuration.WithLabelValues("CREATE", ResultSuccess)
+	m.WebhookRequestsTotal.WithLabelValues("CREATE", "test-manager", ResultSuccess, "")
+	m.WebhookDuration.WithLabelValues("CREATE", "test-manager", ResultSuccess)
 
 	metrics, err := reg.Gather()
 	require.NoError(t, err)
@@ -271,7 +354,7 @@ func TestMetrics_ConcurrentAccess(t *testing.T) {
 	// Concurrent webhook metrics updates
 	go func() {
 		for i := 0; i < 100; i++ {
-			m.WebhookRequestsTotal.WithLabelValues("CREATE", ResultSuccess, "").Inc()
+			m.WebhookRequestsTotal.WithLabelValues("CREATE", "test-manager", ResultSuccess, "").Inc()
 		}
 		done <- true
 	}()
@@ -283,7 +366,7 @@ func TestMetrics_ConcurrentAccess(t *testing.T) {
 
 	// Verify reconcile count
 	assert.Equal(t, float64(100), testutil.ToFloat64(m.ReconcileTotal.WithLabelValues("test-manager", ResultSuccess, "")))
-	assert.Equal(t, float64(100), testutil.ToFloat64(m.WebhookRequestsTotal.WithLabelValues("CREATE", ResultSuccess, "")))
+	assert.Equal(t, float64(100), testutil.ToFloat64(m.WebhookRequestsTotal.WithLabelValues("CREATE", "test-manager", ResultSuccess, "")))
 }
 
 // Test: Error classification
@@ -295,6 +378,11 @@ func TestMetrics_ClassifyError(t *testing.T) {
 	}{
 		{"nil error", nil, ""},
 		{"not found error", assert.AnError, ErrorTypeUnknown},
+		{"connection refused", errors.New("dial tcp 10.0.0.1:443: connection refused"), ErrorTypeAPIServer},
+		{"API conflict", apierrors.NewConflict(schema.GroupResource{Resource: "verticalpodautoscalers"}, "app-vpa", errors.New("stale")), ErrorTypeConflict},
+		{"wrapped API not found", fmt.Errorf("deployment team-a/app: %w", apierrors.NewNotFound(schema.GroupResource{Resource: "deployments"}, "app")), ErrorTypeNotFound},
+		{"API server timeout", apierrors.NewServerTimeout(schema.GroupResource{Resource: "deployments"}, "list", 1), ErrorTypeAPIServer},
+		{"API unavailable", apierrors.NewServiceUnavailable("etcd leader changed"), ErrorTypeAPIServer},
 	}
 
 	for _, tt := range tests {
@@ -312,3 +400,250 @@ func TestMetrics_ContainsAny(t *testing.T) {
 	assert.False(t, containsAny("success", "error", "failed"))
 	assert.False(t, containsAny("", "error"))
 }
+
+func TestMetrics_SetPausedNamespaces(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	m := NewMetrics(reg)
+
+	m.SetPausedNamespaces("manager-1", 1)
+	m.SetPausedNamespaces("manager-1", 0)
+	m.SetPausedNamespaces("manager-2", 2)
+
+	assert.Equal(t, float64(0), testutil.ToFloat64(m.PausedNamespaces.WithLabelValues("manager-1")))
+	assert.Equal(t, float64(2), testutil.ToFloat64(m.PausedNamespaces.WithLabelValues("manager-2")))
+}
+
+func TestMetrics_SetForbiddenNamespaces(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	m := NewMetrics(reg)
+
+	m.SetForbiddenNamespaces("manager-1", 2)
+	m.SetForbiddenNamespaces("manager-1", 0)
+	m.SetForbiddenNamespaces("manager-2", 3)
+
+	assert.Equal(t, float64(0), testutil.ToFloat64(m.ForbiddenNamespaces.WithLabelValues("manager-1")))
+	assert.Equal(t, float64(3), testutil.ToFloat64(m.ForbiddenNamespaces.WithLabelValues("manager-2")))
+}
+
+func TestMetrics_OrphanCleanup(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	m := NewMetrics(reg)
+
+	m.RecordOrphanVPAsDeleted("manager-1", 3)
+	m.RecordOrphanVPAsDeleted("manager-1", 0)
+	m.RecordOrphanVPAsDeleted("manager-2", 1)
+	m.ObserveOrphanScan("manager-1", time.Now().Add(-20*time.Millisecond))
+	m.ObserveReconcilePhase("manager-1", PhaseCleanup, 30*time.Millisecond)
+
+	assert.Equal(t, float64(3), testutil.ToFloat64(m.OrphanVPAsDeletedTotal.WithLabelValues("manager-1")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.OrphanVPAsDeletedTotal.WithLabelValues("manager-2")))
+	assert.Equal(t, 1, testutil.CollectAndCount(m.OrphanScanDuration, "vpa_operator_orphan_scan_duration_seconds"))
+	assert.Equal(t, 1, testutil.CollectAndCount(m.ReconcilePhaseDuration, "vpa_operator_reconcile_phase_duration_seconds"))
+}
+
+func TestMetrics_ObserveVPAWrite(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	m := NewMetrics(reg)
+
+	start := time.Now().Add(-20 * time.Millisecond)
+	m.ObserveVPAWrite("create", "manager-1", start)
+	m.ObserveVPAWrite("create", "manager-1", start)
+	m.ObserveVPAWrite("delete", "manager-2", start)
+
+	families, err := reg.Gather()
+	require.NoError(t, err)
+	counts := map[string]uint64{}
+	for _, mf := range families {
+		if mf.GetName() != "vpa_operator_vpa_write_duration_seconds" {
+			continue
+		}
+		for _, metric := range mf.GetMetric() {
+			labels := map[string]string{}
+			for _, label := range metric.GetLabel() {
+				labels[label.GetName()] = label.GetValue()
+			}
+			counts[labels["operation"]+"/"+labels["vpamanager"]] = metric.GetHistogram().GetSampleCount()
+		}
+	}
+	assert.Equal(t, map[string]uint64{"create/manager-1": 2, "delete/manager-2": 1}, counts)
+}
+
+func TestMetrics_SetPendingOrphanDeletions(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	m := NewMetrics(reg)
+
+	m.SetPendingOrphanDeletions("manager-1", 40)
+	m.SetPendingOrphanDeletions("manager-2", 0)
+
+	assert.Equal(t, float64(40), testutil.ToFloat64(m.PendingOrphanDeletions.WithLabelValues("manager-1")))
+	assert.Equal(t, float64(0), testutil.ToFloat64(m.PendingOrphanDeletions.WithLabelValues("manager-2")))
+}
+
+func TestMetrics_SetForeignVPAs(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	m := NewMetrics(reg)
+
+	m.SetForeignVPAs("manager-1", 3)
+	m.SetForeignVPAs("manager-1", 1)
+
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.ForeignVPAs.WithLabelValues("manager-1")))
+}
+
+func TestMetrics_SetConflictingWorkloads(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	m := NewMetrics(reg)
+
+	m.SetConflictingWorkloads("manager-1", 4)
+	m.SetConflictingWorkloads("manager-1", 0)
+
+	assert.Equal(t, float64(0), testutil.ToFloat64(m.ConflictingWorkloads.WithLabelValues("manager-1")))
+}
+
+func TestMetrics_RecordOrphanSweep(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	m := NewMetrics(reg)
+
+	m.RecordOrphanSweep(ResultSuccess, 3)
+	m.RecordOrphanSweep(ResultError, 1)
+	m.RecordOrphanSweepDeletion("vpamanager_missing")
+	m.RecordOrphanSweepDeletion("target_missing")
+	m.RecordOrphanSweepDeletion("target_missing")
+
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.OrphanSweepsTotal.WithLabelValues(ResultSuccess)))
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.OrphanSweepsTotal.WithLabelValues(ResultError)))
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.OrphanSweepUnverifiable))
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.OrphanSweepDeletionsTotal.WithLabelValues("vpamanager_missing")))
+	assert.Equal(t, float64(2), testutil.ToFloat64(m.OrphanSweepDeletionsTotal.WithLabelValues("target_missing")))
+}
+
+func TestMetrics_SetVPAServed(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	m := NewMetrics(reg)
+
+	m.SetVPAServed(true)
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.VPAServed))
+	m.SetVPAServed(false)
+	assert.Equal(t, float64(0), testutil.ToFloat64(m.VPAServed))
+}
+
+func TestMetrics_RecordReconcileChunk(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	m := NewMetrics(reg)
+
+	m.RecordReconcileChunk("manager-1")
+	m.RecordReconcileChunk("manager-1")
+
+	assert.Equal(t, float64(2), testutil.ToFloat64(m.ReconcileChunksTotal.WithLabelValues("manager-1")))
+}
+
+func TestMetrics_RecordCheckpointsCopied(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	m := NewMetrics(reg)
+
+	m.RecordCheckpointsCopied("manager-1", 2)
+	m.RecordCheckpointsCopied("manager-1", 1)
+
+	assert.Equal(t, float64(3), testutil.ToFloat64(m.CheckpointsCopiedTotal.WithLabelValues("manager-1")))
+}
+
+func TestMetrics_SetWorkloadKindEnabled(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	m := NewMetrics(reg)
+
+	m.SetWorkloadKindEnabled("Rollout", false)
+	assert.Equal(t, float64(0), testutil.ToFloat64(m.WorkloadKindEnabled.WithLabelValues("Rollout")))
+	m.SetWorkloadKindEnabled("Rollout", true)
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.WorkloadKindEnabled.WithLabelValues("Rollout")))
+}
+
+func TestMetrics_SetMissingRBAC(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	m := NewMetrics(reg)
+
+	m.SetMissingRBAC("deployments.apps", "list", true)
+	m.SetMissingRBAC("namespaces", "get", false)
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.MissingRBAC.WithLabelValues("deployments.apps", "list")))
+	assert.Equal(t, float64(0), testutil.ToFloat64(m.MissingRBAC.WithLabelValues("namespaces", "get")))
+
+	m.SetMissingRBAC("deployments.apps", "list", false)
+	assert.Equal(t, float64(0), testutil.ToFloat64(m.MissingRBAC.WithLabelValues("deployments.apps", "list")))
+}
+
+func TestMetrics_SetClusterCapacity(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	m := NewMetrics(reg)
+
+	m.SetClusterCapacity(42, 100)
+
+	assert.Equal(t, float64(42), testutil.ToFloat64(m.ClusterManagedVPAs))
+	assert.Equal(t, float64(100), testutil.ToFloat64(m.ClusterVPALimit))
+}
+
+func TestMetrics_SetWebhookCertExpiry(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	m := NewMetrics(reg)
+
+	m.SetWebhookCertExpiry(time.Unix(1800000000, 0))
+
+	assert.Equal(t, float64(1800000000), testutil.ToFloat64(m.WebhookCertExpiry))
+}
+
+func TestMetrics_RecordWebhookUpdateSkipped(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	m := NewMetrics(reg)
+
+	m.RecordWebhookUpdateSkipped("deployment")
+	m.RecordWebhookUpdateSkipped("deployment")
+
+	assert.Equal(t, float64(2), testutil.ToFloat64(m.WebhookSkippedUpdatesTotal.WithLabelValues("deployment")))
+}
+
+func TestMetrics_RecordWriteConflict(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	m := NewMetrics(reg)
+
+	m.RecordWriteConflict("test", ConflictObjectVPA)
+	m.RecordWriteConflict("test", ConflictObjectVPA)
+	m.RecordWriteConflict("test", ConflictObjectStatus)
+
+	assert.Equal(t, float64(2), testutil.ToFloat64(m.WriteConflictsTotal.WithLabelValues("test", ConflictObjectVPA)))
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.WriteConflictsTotal.WithLabelValues("test", ConflictObjectStatus)))
+}
+
+func TestMetrics_RecordUpdateModeTransition(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	m := NewMetrics(reg)
+
+	m.RecordUpdateModeTransition("manager-1", "Auto", "Off", "dormancy")
+	m.RecordUpdateModeTransition("manager-1", "Off", "Auto", "vpaManager")
+	m.RecordUpdateModeTransition("manager-1", "Auto", "Off", "dormancy")
+
+	assert.Equal(t, float64(2), testutil.ToFloat64(m.UpdateModeTransitionsTotal.WithLabelValues("manager-1", "Auto", "Off", "dormancy")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.UpdateModeTransitionsTotal.WithLabelValues("manager-1", "Off", "Auto", "vpaManager")))
+}
+
+func TestMetrics_RecordWebhookTimeout(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	m := NewMetrics(reg)
+
+	m.RecordWebhookTimeout("deployment", "manager-1")
+	m.RecordWebhookTimeout("deployment", "manager-1")
+	m.RecordWebhookTimeout("statefulset", "")
+
+	assert.Equal(t, float64(2), testutil.ToFloat64(m.WebhookTimeoutsTotal.WithLabelValues("deployment", "manager-1")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.WebhookTimeoutsTotal.WithLabelValues("statefulset", "")))
+}
+
+func TestMetrics_RecordSimulation(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	m := NewMetrics(reg)
+
+	m.RecordSimulation(true, nil)
+	m.RecordSimulation(false, nil)
+	m.RecordSimulation(false, nil)
+	m.RecordSimulation(false, errors.New("invalid workload manifest"))
+
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.SimulationsTotal.WithLabelValues("matched")))
+	assert.Equal(t, float64(2), testutil.ToFloat64(m.SimulationsTotal.WithLabelValues("no_match")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.SimulationsTotal.WithLabelValues(ResultError)))
+}
@@ -0,0 +1,98 @@
+package metrics
+
+import (
+	"sync"
+	"time"
+)
+
+// OtherNamespace is the namespace label of namespaces recorded after the label limit was reached
+const OtherNamespace = "_other"
+
+// DefaultMaxNamespaceLabels is the default number of distinct namespace label values
+const DefaultMaxNamespaceLabels = 100
+
+// namespaceLimiter caps the distinct namespace label values of the metrics. The first namespaces
+// seen keep their name, later ones share OtherNamespace, so clusters with thousands of
+// namespaces cannot explode the series count.
+type namespaceLimiter struct {
+	mu   sync.Mutex
+	max  int
+	seen map[string]struct{}
+}
+
+func newNamespaceLimiter(max int) *namespaceLimiter {
+	return &namespaceLimiter{max: max, seen: make(map[string]struct{})}
+}
+
+// label returns the namespace label value of namespace
+func (l *namespaceLimiter) label(namespace string) string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, ok := l.seen[namespace]; ok {
+		return namespace
+	}
+	if len(l.seen) >= l.max {
+		return OtherNamespace
+	}
+	l.seen[namespace] = struct{}{}
+	return namespace
+}
+
+// setMax changes the limit. Namespaces already labeled keep their name.
+func (l *namespaceLimiter) setMax(max int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.max = max
+}
+
+// Scope records metrics on behalf of one VpaManager and, once known, the kind and namespace of
+// the workload at hand. Scopes are values: the With methods return copies, so a scope can be
+// narrowed per workload and shared between goroutines without locking.
+type Scope struct {
+	m          *Metrics
+	vpaManager string
+	kind       string
+	namespace  string
+}
+
+// For returns the scope of a VpaManager
+func (m *Metrics) For(vpaManagerName string) Scope {
+	return Scope{m: m, vpaManager: vpaManagerName}
+}
+
+// WithKind returns a copy of the scope for a workload kind
+func (s Scope) WithKind(kind string) Scope {
+	s.kind = kind
+	return s
+}
+
+// WithNamespace returns a copy of the scope for a namespace
+func (s Scope) WithNamespace(namespace string) Scope {
+	s.namespace = namespace
+	return s
+}
+
+// RecordVPAOperation records a VPA lifecycle operation. Operations of a scope with a kind and
+// namespace are also counted per namespace, within the namespace label limit.
+func (s Scope) RecordVPAOperation(operation string) {
+	s.m.RecordVPAOperation(operation, s.vpaManager)
+	if s.kind == "" || s.namespace == "" {
+		return
+	}
+	s.m.NamespaceVPAOperationsTotal.WithLabelValues(s.vpaManager, s.kind, s.m.namespaces.label(s.namespace), operation).Inc()
+}
+
+// RecordVPAOperationError records a failed VPA lifecycle operation
+func (s Scope) RecordVPAOperationError(operation string, err error) {
+	s.m.RecordVPAOperationError(operation, s.vpaManager, err)
+}
+
+// ObserveVPAWrite records the latency of a VPA create, update or delete call
+func (s Scope) ObserveVPAWrite(operation string, start time.Time) {
+	s.m.ObserveVPAWrite(operation, s.vpaManager, start)
+}
+
+// RecordUpdateModeTransition records an update mode change written to an existing VPA
+func (s Scope) RecordUpdateModeTransition(from, to, layer string) {
+	s.m.RecordUpdateModeTransition(s.vpaManager, from, to, layer)
+}
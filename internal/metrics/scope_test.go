@@ -0,0 +1,120 @@
+package metrics
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScope_RecordVPAOperation(t *testing.T) {
+	tests := []struct {
+		name          string
+		scope         func(m *Metrics) Scope
+		wantNamespace float64
+	}{
+		{
+			name:          "kind and namespace",
+			scope:         func(m *Metrics) Scope { return m.For("test").WithKind("Deployment").WithNamespace("default") },
+			wantNamespace: 1,
+		},
+		{
+			name:  "without namespace",
+			scope: func(m *Metrics) Scope { return m.For("test").WithKind("Deployment") },
+		},
+		{
+			name:  "without kind",
+			scope: func(m *Metrics) Scope { return m.For("test").WithNamespace("default") },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewMetrics(prometheus.NewRegistry())
+
+			tt.scope(m).RecordVPAOperation("create")
+
+			assert.Equal(t, float64(1), testutil.ToFloat64(m.VPAOperationsTotal.WithLabelValues("create", "test")))
+			assert.Equal(t, tt.wantNamespace, testutil.ToFloat64(m.NamespaceVPAOperationsTotal.WithLabelValues("test", "Deployment", "default", "create")))
+		})
+	}
+}
+
+func TestScope_WithDoesNotModifyParent(t *testing.T) {
+	m := NewMetrics(prometheus.NewRegistry())
+	parent := m.For("test").WithKind("Deployment")
+
+	parent.WithNamespace("team-a").RecordVPAOperation("create")
+	parent.RecordVPAOperation("delete")
+
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.NamespaceVPAOperationsTotal.WithLabelValues("test", "Deployment", "team-a", "create")))
+	assert.Equal(t, 1, testutil.CollectAndCount(m.NamespaceVPAOperationsTotal))
+}
+
+func TestScope_NamespaceLabelLimit(t *testing.T) {
+	tests := []struct {
+		name       string
+		max        int
+		namespaces []string
+		want       map[string]float64
+	}{
+		{
+			name:       "under the limit",
+			max:        2,
+			namespaces: []string{"a", "b", "a"},
+			want:       map[string]float64{"a": 2, "b": 1},
+		},
+		{
+			name:       "over the limit",
+			max:        2,
+			namespaces: []string{"a", "b", "c", "d", "a"},
+			want:       map[string]float64{"a": 2, "b": 1, OtherNamespace: 2},
+		},
+		{
+			name:       "no namespace labels",
+			max:        0,
+			namespaces: []string{"a", "b"},
+			want:       map[string]float64{OtherNamespace: 2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewMetrics(prometheus.NewRegistry())
+			m.SetMaxNamespaceLabels(tt.max)
+
+			for _, ns := range tt.namespaces {
+				m.For("test").WithKind("Deployment").WithNamespace(ns).RecordVPAOperation("create")
+			}
+
+			assert.Equal(t, len(tt.want), testutil.CollectAndCount(m.NamespaceVPAOperationsTotal))
+			for ns, want := range tt.want {
+				assert.Equal(t, want, testutil.ToFloat64(m.NamespaceVPAOperationsTotal.WithLabelValues("test", "Deployment", ns, "create")), ns)
+			}
+		})
+	}
+}
+
+func TestScope_ConcurrentNamespaces(t *testing.T) {
+	m := NewMetrics(prometheus.NewRegistry())
+	m.SetMaxNamespaceLabels(10)
+	scope := m.For("test").WithKind("Deployment")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			scope.WithNamespace(fmt.Sprintf("ns-%d", i)).RecordVPAOperation("create")
+		}(i)
+	}
+	wg.Wait()
+
+	// Ten namespaces keep their name, the other forty share one series
+	assert.Equal(t, 11, testutil.CollectAndCount(m.NamespaceVPAOperationsTotal))
+	assert.Equal(t, float64(40), testutil.ToFloat64(m.NamespaceVPAOperationsTotal.WithLabelValues("test", "Deployment", OtherNamespace, "create")))
+	assert.Equal(t, float64(50), testutil.ToFloat64(m.VPAOperationsTotal.WithLabelValues("create", "test")))
+}
@@ -0,0 +1,59 @@
+package policy
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	autoscalingv1 "github.com/joaomo/k8s_op_vpa/api/v1"
+)
+
+// DefaultSelectorLabel is the opt-in label used by the default selectors when none are configured
+const DefaultSelectorLabel = "vpa-enabled=true"
+
+// SelectorDefaults holds operator-level selectors applied to VpaManagers that omit them.
+// Without defaults an omitted namespaceSelector matches every namespace.
+type SelectorDefaults struct {
+	// Namespace is used when a VpaManager has no namespaceSelector
+	Namespace *metav1.LabelSelector
+
+	// Deployment is used when a VpaManager has no workload selectors at all
+	Deployment *metav1.LabelSelector
+}
+
+// NewSelectorDefaults parses selectors in kubectl label selector syntax (e.g. "vpa-enabled=true")
+func NewSelectorDefaults(namespace, deployment string) (*SelectorDefaults, error) {
+	nsSelector, err := metav1.ParseToLabelSelector(namespace)
+	if err != nil {
+		return nil, err
+	}
+	deploySelector, err := metav1.ParseToLabelSelector(deployment)
+	if err != nil {
+		return nil, err
+	}
+	return &SelectorDefaults{Namespace: nsSelector, Deployment: deploySelector}, nil
+}
+
+// Apply returns the spec with default selectors filled in. The spec is returned
+// as-is when defaults are disabled or nothing needs defaulting.
+func (d *SelectorDefaults) Apply(spec *autoscalingv1.VpaManagerSpec) *autoscalingv1.VpaManagerSpec {
+	if d == nil {
+		return spec
+	}
+
+	defaultNamespace := len(spec.NamespaceSelectorTerms()) == 0 && !spec.MatchAllNamespaces && len(spec.Namespaces) == 0 && d.Namespace != nil
+	// Only default workloads when none are selected, so a manager scoped to
+	// StatefulSets does not start managing Deployments
+	defaultDeployment := len(spec.WorkloadSelectorTerms("Deployment")) == 0 && len(spec.WorkloadSelectorTerms("StatefulSet")) == 0 &&
+		len(spec.WorkloadSelectorTerms("DaemonSet")) == 0 && !spec.MatchAllWorkloads && d.Deployment != nil
+	if !defaultNamespace && !defaultDeployment {
+		return spec
+	}
+
+	out := spec.DeepCopy()
+	if defaultNamespace {
+		out.NamespaceSelector = d.Namespace.DeepCopy()
+	}
+	if defaultDeployment {
+		out.DeploymentSelector = d.Deployment.DeepCopy()
+	}
+	return out
+}
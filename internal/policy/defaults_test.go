@@ -0,0 +1,79 @@
+package policy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	autoscalingv1 "github.com/joaomo/k8s_op_vpa/api/v1"
+)
+
+func TestSelectorDefaults_Apply(t *testing.T) {
+	defaults, err := NewSelectorDefaults(DefaultSelectorLabel, DefaultSelectorLabel)
+	require.NoError(t, err)
+
+	optIn := defaults.Namespace
+	team := &metav1.LabelSelector{MatchLabels: map[string]string{"team": "payments"}}
+
+	tests := []struct {
+		name               string
+		defaults           *SelectorDefaults
+		spec               autoscalingv1.VpaManagerSpec
+		expectedNamespace  *metav1.LabelSelector
+		expectedDeployment *metav1.LabelSelector
+	}{
+		{
+			name:     "disabled keeps omitted selectors",
+			defaults: nil,
+			spec:     autoscalingv1.VpaManagerSpec{},
+		},
+		{
+			name:               "fills omitted selectors",
+			defaults:           defaults,
+			spec:               autoscalingv1.VpaManagerSpec{},
+			expectedNamespace:  optIn,
+			expectedDeployment: optIn,
+		},
+		{
+			name:               "keeps explicit selectors",
+			defaults:           defaults,
+			spec:               autoscalingv1.VpaManagerSpec{NamespaceSelector: team, DeploymentSelector: team},
+			expectedNamespace:  team,
+			expectedDeployment: team,
+		},
+		{
+			name:     "matchAll flags opt out of defaults",
+			defaults: defaults,
+			spec:     autoscalingv1.VpaManagerSpec{MatchAllNamespaces: true, MatchAllWorkloads: true},
+		},
+		{
+			name:               "namespaces opt out of the default namespace selector",
+			defaults:           defaults,
+			spec:               autoscalingv1.VpaManagerSpec{Namespaces: []string{"prod-a"}},
+			expectedDeployment: optIn,
+		},
+		{
+			name:              "does not add deployments to a manager scoped to other workloads",
+			defaults:          defaults,
+			spec:              autoscalingv1.VpaManagerSpec{StatefulSetSelector: team},
+			expectedNamespace: optIn,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			original := tt.spec.DeepCopy()
+			got := tt.defaults.Apply(&tt.spec)
+			assert.Equal(t, tt.expectedNamespace, got.NamespaceSelector)
+			assert.Equal(t, tt.expectedDeployment, got.DeploymentSelector)
+			assert.Equal(t, original, &tt.spec, "the input spec must not be mutated")
+		})
+	}
+}
+
+func TestNewSelectorDefaults_InvalidSelector(t *testing.T) {
+	_, err := NewSelectorDefaults("vpa-enabled in (", DefaultSelectorLabel)
+	assert.Error(t, err)
+}
@@ -0,0 +1,321 @@
+// Package policy resolves the settings the VPA of a single workload is generated from.
+// Settings come from layers applied in increasing precedence:
+//
+//  1. operator defaults
+//  2. the VpaManager, after inheritance and selector defaults, with its update mode for the
+//     workload's kind taking precedence over its global one
+//  3. namespace policy: maxAllowed capped by the namespace ResourceQuotas
+//  4. workload annotations
+//  5. the VpaOverride targeting the workload
+//
+// Every layer that sets a value records a Decision, so the result can be explained.
+package policy
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	autoscalingv1 "github.com/joaomo/k8s_op_vpa/api/v1"
+	"github.com/joaomo/k8s_op_vpa/internal/validation"
+	"github.com/joaomo/k8s_op_vpa/internal/vpa"
+)
+
+// UpdateModeAnnotation on a workload sets the update mode of its VPA
+const UpdateModeAnnotation = "vpa-operator.io/update-mode"
+
+// DefaultUpdateMode is the update mode of VpaManagers that set none
+const DefaultUpdateMode = "Off"
+
+// Layer is a source of settings
+type Layer string
+
+// Layers in increasing precedence
+const (
+	LayerDefaults   Layer = "defaults"
+	LayerVpaManager Layer = "vpaManager"
+	LayerNamespace  Layer = "namespace"
+	LayerWorkload   Layer = "workload"
+	LayerOverride   Layer = "override"
+)
+
+// LayerDormancy decides the update mode of a VPA its dormancy policy turned Off. It applies
+// after the layers above, to the generated VPA rather than to the resolved settings.
+const LayerDormancy Layer = "dormancy"
+
+// LayerTopology decides the update mode of a VPA turned Off because evicted pods of its target
+// could not be rescheduled. Like LayerDormancy it applies to the generated VPA.
+const LayerTopology Layer = "topology"
+
+// Settings recorded in a Trace; quota caps are recorded as "maxAllowed[<resource>]"
+const (
+	SettingUpdateMode            = "updateMode"
+	SettingResourcePolicy        = "resourcePolicy"
+	SettingControlledResources   = "controlledResources"
+	SettingExcludedSidecars      = "excludedSidecars"
+	SettingIgnoredInitContainers = "ignoredInitContainers"
+)
+
+// Decision records that a layer set a setting
+type Decision struct {
+	Setting string `json:"setting"`
+	Layer   Layer  `json:"layer"`
+	// Source names the object or annotation the value came from
+	Source string `json:"source"`
+	Value  string `json:"value"`
+}
+
+// Trace lists decisions in the order they were applied; for each setting, the last one wins
+type Trace []Decision
+
+// Winner returns the decision that set the effective value of setting, nil when no layer set it
+func (t Trace) Winner(setting string) *Decision {
+	for i := len(t) - 1; i >= 0; i-- {
+		if t[i].Setting == setting {
+			return &t[i]
+		}
+	}
+	return nil
+}
+
+// Input is what the settings of one workload are resolved from
+type Input struct {
+	// VpaManager holds the effective spec, after inheritance and selector defaults
+	VpaManager *autoscalingv1.VpaManager
+	// Kind is the kind of the workload
+	Kind string
+	// Workload is the metadata of the workload
+	Workload metav1.Object
+	// PodTemplate is used to render templated bounds; nil for workloads without one
+	PodTemplate *corev1.PodTemplateSpec
+	// Quotas are the ResourceQuotas of the workload's namespace
+	Quotas []corev1.ResourceQuota
+	// Overrides are the VpaOverrides of the workload's namespace
+	Overrides []autoscalingv1.VpaOverride
+}
+
+// Result holds the resolved settings of a workload
+type Result struct {
+	// VpaManager is a copy of the input VpaManager with the effective update mode and a
+	// rendered, capped resource policy
+	VpaManager *autoscalingv1.VpaManager
+	Trace      Trace
+}
+
+// UpdateModeSource returns the layer and source that decided the update mode of vpaObj, a VPA
+// generated from r: the pod anti-affinity or dormancy policy while it holds the VPA Off,
+// otherwise the winning layer
+func (r *Result) UpdateModeSource(vpaObj metav1.Object) (Layer, string) {
+	if topologyKey, blocked := vpaObj.GetAnnotations()[vpa.EvictionBlockedAnnotation]; blocked {
+		return LayerTopology, "required pod anti-affinity on " + topologyKey
+	}
+	if _, dormant := vpaObj.GetAnnotations()[vpa.DormantAnnotation]; dormant {
+		return LayerDormancy, "dormancy policy of VpaManager " + r.VpaManager.Name
+	}
+	if winner := r.Trace.Winner(SettingUpdateMode); winner != nil {
+		return winner.Layer, winner.Source
+	}
+	return LayerDefaults, "operator"
+}
+
+// Resolve reads the ResourceQuotas and VpaOverrides of the workload's namespace and evaluates
+// the settings of the workload. A missing VpaOverride CRD means no overrides.
+func Resolve(ctx context.Context, c client.Reader, vpaManager *autoscalingv1.VpaManager, kind string, wl metav1.Object, podTemplate *corev1.PodTemplateSpec) (*Result, error) {
+	in := Input{VpaManager: vpaManager, Kind: kind, Workload: wl, PodTemplate: podTemplate}
+
+	if vpaManager.Spec.MaxAllowedFromQuota != nil {
+		quotas := &corev1.ResourceQuotaList{}
+		if err := c.List(ctx, quotas, client.InNamespace(wl.GetNamespace())); err != nil {
+			return nil, fmt.Errorf("listing resource quotas: %w", err)
+		}
+		in.Quotas = quotas.Items
+	}
+
+	overrides := &autoscalingv1.VpaOverrideList{}
+	if err := c.List(ctx, overrides, client.InNamespace(wl.GetNamespace())); err != nil {
+		if !meta.IsNoMatchError(err) {
+			return nil, fmt.Errorf("listing VPA overrides: %w", err)
+		}
+	}
+	in.Overrides = overrides.Items
+
+	return Evaluate(in)
+}
+
+// Evaluate applies the layers to in.VpaManager. It fails when a template cannot be
+// rendered, a minAllowed exceeds the quota cap, or the workload annotation or VpaOverride
+// is invalid. in.VpaManager is not modified.
+func Evaluate(in Input) (*Result, error) {
+	var trace Trace
+	namespace := in.Workload.GetNamespace()
+
+	override := Select(in.Overrides, in.Kind, in.Workload.GetName())
+	overrideSource := ""
+	if override != nil {
+		overrideSource = fmt.Sprintf("VpaOverride %s/%s", namespace, override.Name)
+		if errs := validation.ValidateVpaOverrideSpec(&override.Spec); len(errs) > 0 {
+			return nil, fmt.Errorf("%s is invalid: %v", overrideSource, errs.ToAggregate())
+		}
+	}
+	// A resource policy override replaces every lower layer's bounds, which are then not evaluated
+	policyOverridden := override != nil && override.Spec.ResourcePolicy != nil
+
+	out := in.VpaManager.DeepCopy()
+	managerSource := "VpaManager " + out.Name
+
+	// Operator defaults and VpaManager
+	if out.Spec.UpdateMode == "" {
+		out.Spec.UpdateMode = DefaultUpdateMode
+		trace = append(trace, Decision{Setting: SettingUpdateMode, Layer: LayerDefaults, Source: "operator", Value: DefaultUpdateMode})
+	} else {
+		trace = append(trace, Decision{Setting: SettingUpdateMode, Layer: LayerVpaManager, Source: managerSource, Value: out.Spec.UpdateMode})
+	}
+	if mode := out.Spec.UpdateModes.ForKind(in.Kind); mode != "" {
+		out.Spec.UpdateMode = mode
+		trace = append(trace, Decision{Setting: SettingUpdateMode, Layer: LayerVpaManager,
+			Source: managerSource + " updateModes." + strings.ToLower(in.Kind), Value: mode})
+	}
+	if !policyOverridden && out.Spec.ResourcePolicy != nil {
+		rendered, err := vpa.WithRenderedResourcePolicy(vpa.WithExpandedContainerPatterns(out, in.PodTemplate), in.PodTemplate)
+		if err != nil {
+			return nil, err
+		}
+		out = rendered
+		trace = append(trace, Decision{Setting: SettingResourcePolicy, Layer: LayerVpaManager, Source: managerSource, Value: containerNames(out.Spec.ResourcePolicy)})
+	}
+
+	// Namespace policy
+	if !policyOverridden {
+		caps := vpa.QuotaCaps(in.Quotas, out.Spec.MaxAllowedFromQuota)
+		capped, err := vpa.CapMaxAllowed(out, caps)
+		if err != nil {
+			return nil, err
+		}
+		out = capped
+		for _, name := range sortedResourceNames(caps) {
+			limit := caps[name]
+			trace = append(trace, Decision{
+				Setting: fmt.Sprintf("maxAllowed[%s]", name),
+				Layer:   LayerNamespace,
+				Source:  "ResourceQuotas of namespace " + namespace,
+				Value:   limit.String(),
+			})
+		}
+	}
+
+	// Workload annotations
+	if mode, ok := in.Workload.GetAnnotations()[UpdateModeAnnotation]; ok {
+		if !validUpdateMode(mode) {
+			return nil, fmt.Errorf("annotation %s=%q must be one of Off, Initial, Auto", UpdateModeAnnotation, mode)
+		}
+		out.Spec.UpdateMode = mode
+		trace = append(trace, Decision{Setting: SettingUpdateMode, Layer: LayerWorkload, Source: "annotation " + UpdateModeAnnotation, Value: mode})
+	}
+
+	// VpaOverride
+	if override != nil && override.Spec.UpdateMode != "" {
+		out.Spec.UpdateMode = override.Spec.UpdateMode
+		trace = append(trace, Decision{Setting: SettingUpdateMode, Layer: LayerOverride, Source: overrideSource, Value: override.Spec.UpdateMode})
+	}
+	if policyOverridden {
+		out.Spec.ResourcePolicy = override.Spec.ResourcePolicy.DeepCopy()
+		rendered, err := vpa.WithRenderedResourcePolicy(vpa.WithExpandedContainerPatterns(out, in.PodTemplate), in.PodTemplate)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", overrideSource, err)
+		}
+		out = rendered
+		trace = append(trace, Decision{Setting: SettingResourcePolicy, Layer: LayerOverride, Source: overrideSource, Value: containerNames(out.Spec.ResourcePolicy)})
+	}
+
+	// Default controlled resources apply to the container policies of every layer listing none
+	if defaults := out.Spec.DefaultControlledResources; len(defaults) > 0 {
+		out.Spec.ResourcePolicy = vpa.WithDefaultControlledResources(out.Spec.ResourcePolicy, defaults)
+		trace = append(trace, Decision{Setting: SettingControlledResources, Layer: LayerVpaManager,
+			Source: managerSource + " defaultControlledResources", Value: strings.Join(defaults, ",")})
+	}
+
+	// Well-known sidecars are turned Off unless a layer gave them a policy of their own
+	if out.Spec.ExcludeWellKnownSidecars {
+		var excluded []string
+		out.Spec.ResourcePolicy, excluded = vpa.WithWellKnownSidecarsExcluded(out.Spec.ResourcePolicy, in.PodTemplate)
+		if len(excluded) > 0 {
+			trace = append(trace, Decision{Setting: SettingExcludedSidecars, Layer: LayerVpaManager,
+				Source: managerSource + " excludeWellKnownSidecars", Value: strings.Join(excluded, ",")})
+		}
+	}
+
+	// Init containers are turned Off when the winning resource policy ignores them
+	var ignored []string
+	out.Spec.ResourcePolicy, ignored = vpa.WithInitContainersIgnored(out.Spec.ResourcePolicy, in.PodTemplate)
+	if len(ignored) > 0 {
+		layer, source := LayerVpaManager, managerSource
+		if policyOverridden {
+			layer, source = LayerOverride, overrideSource
+		}
+		trace = append(trace, Decision{Setting: SettingIgnoredInitContainers, Layer: layer,
+			Source: source + " resourcePolicy.ignoreInitContainers", Value: strings.Join(ignored, ",")})
+	}
+
+	return &Result{VpaManager: out, Trace: trace}, nil
+}
+
+// Select returns the override targeting the workload of kind and name, or nil. When several
+// do, the oldest wins, and the name breaks ties, so the choice is stable across reconciles.
+func Select(overrides []autoscalingv1.VpaOverride, kind, name string) *autoscalingv1.VpaOverride {
+	var matching []*autoscalingv1.VpaOverride
+	for i := range overrides {
+		target := overrides[i].Spec.TargetRef
+		if target.Kind == kind && target.Name == name {
+			matching = append(matching, &overrides[i])
+		}
+	}
+	if len(matching) == 0 {
+		return nil
+	}
+	sort.Slice(matching, func(i, j int) bool {
+		ti, tj := matching[i].CreationTimestamp, matching[j].CreationTimestamp
+		if !ti.Equal(&tj) {
+			return ti.Before(&tj)
+		}
+		return matching[i].Name < matching[j].Name
+	})
+	return matching[0]
+}
+
+// validUpdateMode reports whether mode is an update mode the VpaManager API accepts
+func validUpdateMode(mode string) bool {
+	switch mode {
+	case "Off", "Initial", "Auto":
+		return true
+	default:
+		return false
+	}
+}
+
+// containerNames summarizes a resource policy by the containers it covers
+func containerNames(policy *autoscalingv1.ResourcePolicy) string {
+	if policy == nil {
+		return ""
+	}
+	names := make([]string, 0, len(policy.ContainerPolicies))
+	for _, cp := range policy.ContainerPolicies {
+		names = append(names, cp.ContainerName)
+	}
+	return strings.Join(names, ",")
+}
+
+// sortedResourceNames returns the names of list in a stable order
+func sortedResourceNames(list corev1.ResourceList) []corev1.ResourceName {
+	names := make([]corev1.ResourceName, 0, len(list))
+	for name := range list {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool { return names[i] < names[j] })
+	return names
+}
@@ -0,0 +1,478 @@
+package policy
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	autoscalingv1 "github.com/joaomo/k8s_op_vpa/api/v1"
+	"github.com/joaomo/k8s_op_vpa/internal/vpa"
+)
+
+func override(name, kind, target string, created time.Time, spec autoscalingv1.VpaOverrideSpec) autoscalingv1.VpaOverride {
+	spec.TargetRef = autoscalingv1.OverrideTargetReference{Kind: kind, Name: target}
+	return autoscalingv1.VpaOverride{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "team-a", CreationTimestamp: metav1.NewTime(created)},
+		Spec:       spec,
+	}
+}
+
+func policies(cps ...autoscalingv1.ContainerResourcePolicy) *autoscalingv1.ResourcePolicy {
+	return &autoscalingv1.ResourcePolicy{ContainerPolicies: cps}
+}
+
+func TestSelect(t *testing.T) {
+	t0 := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	off := autoscalingv1.VpaOverrideSpec{UpdateMode: "Off"}
+
+	tests := []struct {
+		name      string
+		overrides []autoscalingv1.VpaOverride
+		expected  string
+	}{
+		{name: "none"},
+		{
+			name:      "other workload",
+			overrides: []autoscalingv1.VpaOverride{override("web", "Deployment", "web", t0, off)},
+		},
+		{
+			name:      "same name, other kind",
+			overrides: []autoscalingv1.VpaOverride{override("api", "StatefulSet", "api", t0, off)},
+		},
+		{
+			name:      "matching",
+			overrides: []autoscalingv1.VpaOverride{override("web", "Deployment", "web", t0, off), override("api", "Deployment", "api", t0, off)},
+			expected:  "api",
+		},
+		{
+			name: "oldest wins",
+			overrides: []autoscalingv1.VpaOverride{
+				override("newer", "Deployment", "api", t0.Add(time.Hour), off),
+				override("older", "Deployment", "api", t0, off),
+			},
+			expected: "older",
+		},
+		{
+			name: "name breaks ties",
+			overrides: []autoscalingv1.VpaOverride{
+				override("b", "Deployment", "api", t0, off),
+				override("a", "Deployment", "api", t0, off),
+			},
+			expected: "a",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Select(tt.overrides, "Deployment", "api")
+			if tt.expected == "" {
+				assert.Nil(t, got)
+				return
+			}
+			require.NotNil(t, got)
+			assert.Equal(t, tt.expected, got.Name)
+		})
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	t0 := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	podTemplate := &corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: []corev1.Container{{
+		Name:      "main",
+		Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("256Mi")}},
+	}, {
+		Name: "istio-proxy",
+	}}, InitContainers: []corev1.Container{{
+		Name: "migrate",
+	}}}}
+	cpuQuota := []corev1.ResourceQuota{{Spec: corev1.ResourceQuotaSpec{Hard: corev1.ResourceList{corev1.ResourceRequestsCPU: resource.MustParse("4")}}}}
+	halfCPU := &autoscalingv1.QuotaFraction{CPUFraction: "0.5"}
+	bounded := policies(autoscalingv1.ContainerResourcePolicy{ContainerName: "*", MaxAllowed: autoscalingv1.ResourceBounds{"cpu": "4"}})
+	templated := policies(autoscalingv1.ContainerResourcePolicy{ContainerName: "main", MaxAllowed: autoscalingv1.ResourceBounds{"memory": "{{ .Requests.memory | multiply 2 }}"}})
+	broken := policies(autoscalingv1.ContainerResourcePolicy{ContainerName: "main", MaxAllowed: autoscalingv1.ResourceBounds{"cpu": "{{ .Requests.cpu | multiply 2 }}"}})
+	large := policies(autoscalingv1.ContainerResourcePolicy{ContainerName: "*", MaxAllowed: autoscalingv1.ResourceBounds{"cpu": "8"}})
+
+	tests := []struct {
+		name        string
+		spec        autoscalingv1.VpaManagerSpec
+		annotations map[string]string
+		quotas      []corev1.ResourceQuota
+		overrides   []autoscalingv1.VpaOverride
+		// expectedMode and expectedPolicy are the effective settings
+		expectedMode   string
+		expectedPolicy *autoscalingv1.ResourcePolicy
+		// expectedLayers maps each setting to the layer that set it last
+		expectedLayers map[string]Layer
+		expectError    string
+	}{
+		{
+			name:           "operator default update mode",
+			expectedMode:   DefaultUpdateMode,
+			expectedLayers: map[string]Layer{SettingUpdateMode: LayerDefaults},
+		},
+		{
+			name:           "VpaManager settings",
+			spec:           autoscalingv1.VpaManagerSpec{UpdateMode: "Auto", ResourcePolicy: bounded},
+			expectedMode:   "Auto",
+			expectedPolicy: bounded,
+			expectedLayers: map[string]Layer{SettingUpdateMode: LayerVpaManager, SettingResourcePolicy: LayerVpaManager},
+		},
+		{
+			name: "container name patterns are expanded against the pod template",
+			spec: autoscalingv1.VpaManagerSpec{UpdateMode: "Auto", ResourcePolicy: policies(
+				autoscalingv1.ContainerResourcePolicy{ContainerName: "ma*", MaxAllowed: autoscalingv1.ResourceBounds{"cpu": "4"}},
+				autoscalingv1.ContainerResourcePolicy{ContainerName: "*-sidecar", MaxAllowed: autoscalingv1.ResourceBounds{"cpu": "1"}},
+			)},
+			expectedMode:   "Auto",
+			expectedPolicy: policies(autoscalingv1.ContainerResourcePolicy{ContainerName: "main", MaxAllowed: autoscalingv1.ResourceBounds{"cpu": "4"}}),
+			expectedLayers: map[string]Layer{SettingUpdateMode: LayerVpaManager, SettingResourcePolicy: LayerVpaManager},
+		},
+		{
+			name:           "VpaManager update mode for the workload's kind",
+			spec:           autoscalingv1.VpaManagerSpec{UpdateMode: "Auto", UpdateModes: &autoscalingv1.UpdateModesByKind{Deployment: "Initial"}},
+			expectedMode:   "Initial",
+			expectedLayers: map[string]Layer{SettingUpdateMode: LayerVpaManager},
+		},
+		{
+			name:           "VpaManager update mode for another kind",
+			spec:           autoscalingv1.VpaManagerSpec{UpdateMode: "Auto", UpdateModes: &autoscalingv1.UpdateModesByKind{StatefulSet: "Initial"}},
+			expectedMode:   "Auto",
+			expectedLayers: map[string]Layer{SettingUpdateMode: LayerVpaManager},
+		},
+		{
+			name:           "workload annotation beats the update mode for the kind",
+			spec:           autoscalingv1.VpaManagerSpec{UpdateMode: "Auto", UpdateModes: &autoscalingv1.UpdateModesByKind{Deployment: "Initial"}},
+			annotations:    map[string]string{UpdateModeAnnotation: "Off"},
+			expectedMode:   "Off",
+			expectedLayers: map[string]Layer{SettingUpdateMode: LayerWorkload},
+		},
+		{
+			name:         "VpaManager templates are rendered",
+			spec:         autoscalingv1.VpaManagerSpec{UpdateMode: "Auto", ResourcePolicy: templated},
+			expectedMode: "Auto",
+			expectedPolicy: policies(autoscalingv1.ContainerResourcePolicy{
+				ContainerName: "main", MaxAllowed: autoscalingv1.ResourceBounds{"memory": "512Mi"},
+			}),
+			expectedLayers: map[string]Layer{SettingUpdateMode: LayerVpaManager, SettingResourcePolicy: LayerVpaManager},
+		},
+		{
+			name:        "VpaManager template that cannot be rendered",
+			spec:        autoscalingv1.VpaManagerSpec{UpdateMode: "Auto", ResourcePolicy: broken},
+			expectError: "maxAllowed[cpu]",
+		},
+		{
+			name:           "namespace quota caps the VpaManager bounds",
+			spec:           autoscalingv1.VpaManagerSpec{UpdateMode: "Auto", ResourcePolicy: bounded, MaxAllowedFromQuota: halfCPU},
+			quotas:         cpuQuota,
+			expectedMode:   "Auto",
+			expectedPolicy: policies(autoscalingv1.ContainerResourcePolicy{ContainerName: "*", MaxAllowed: autoscalingv1.ResourceBounds{"cpu": "2"}}),
+			expectedLayers: map[string]Layer{
+				SettingUpdateMode:     LayerVpaManager,
+				SettingResourcePolicy: LayerVpaManager,
+				"maxAllowed[cpu]":     LayerNamespace,
+			},
+		},
+		{
+			name: "namespace quota below minAllowed",
+			spec: autoscalingv1.VpaManagerSpec{
+				UpdateMode:          "Auto",
+				ResourcePolicy:      policies(autoscalingv1.ContainerResourcePolicy{ContainerName: "*", MinAllowed: autoscalingv1.ResourceBounds{"cpu": "3"}}),
+				MaxAllowedFromQuota: halfCPU,
+			},
+			quotas:      cpuQuota,
+			expectError: "exceeds the quota share",
+		},
+		{
+			name:           "workload annotation beats the VpaManager",
+			spec:           autoscalingv1.VpaManagerSpec{UpdateMode: "Auto"},
+			annotations:    map[string]string{UpdateModeAnnotation: "Initial"},
+			expectedMode:   "Initial",
+			expectedLayers: map[string]Layer{SettingUpdateMode: LayerWorkload},
+		},
+		{
+			name:        "invalid workload annotation",
+			spec:        autoscalingv1.VpaManagerSpec{UpdateMode: "Auto"},
+			annotations: map[string]string{UpdateModeAnnotation: "Recreate"},
+			expectError: UpdateModeAnnotation,
+		},
+		{
+			name:           "VpaOverride beats the workload annotation",
+			spec:           autoscalingv1.VpaManagerSpec{UpdateMode: "Auto"},
+			annotations:    map[string]string{UpdateModeAnnotation: "Initial"},
+			overrides:      []autoscalingv1.VpaOverride{override("api", "Deployment", "api", t0, autoscalingv1.VpaOverrideSpec{UpdateMode: "Off"})},
+			expectedMode:   "Off",
+			expectedLayers: map[string]Layer{SettingUpdateMode: LayerOverride},
+		},
+		{
+			name:           "VpaOverride for another workload is ignored",
+			spec:           autoscalingv1.VpaManagerSpec{UpdateMode: "Auto"},
+			overrides:      []autoscalingv1.VpaOverride{override("web", "Deployment", "web", t0, autoscalingv1.VpaOverrideSpec{UpdateMode: "Off"})},
+			expectedMode:   "Auto",
+			expectedLayers: map[string]Layer{SettingUpdateMode: LayerVpaManager},
+		},
+		{
+			name:           "VpaOverride resource policy replaces the VpaManager and quota bounds",
+			spec:           autoscalingv1.VpaManagerSpec{UpdateMode: "Auto", ResourcePolicy: broken, MaxAllowedFromQuota: halfCPU},
+			quotas:         cpuQuota,
+			overrides:      []autoscalingv1.VpaOverride{override("api", "Deployment", "api", t0, autoscalingv1.VpaOverrideSpec{ResourcePolicy: large})},
+			expectedMode:   "Auto",
+			expectedPolicy: large,
+			expectedLayers: map[string]Layer{SettingUpdateMode: LayerVpaManager, SettingResourcePolicy: LayerOverride},
+		},
+		{
+			name:         "VpaOverride templates are rendered",
+			spec:         autoscalingv1.VpaManagerSpec{UpdateMode: "Auto"},
+			overrides:    []autoscalingv1.VpaOverride{override("api", "Deployment", "api", t0, autoscalingv1.VpaOverrideSpec{ResourcePolicy: templated})},
+			expectedMode: "Auto",
+			expectedPolicy: policies(autoscalingv1.ContainerResourcePolicy{
+				ContainerName: "main", MaxAllowed: autoscalingv1.ResourceBounds{"memory": "512Mi"},
+			}),
+			expectedLayers: map[string]Layer{SettingUpdateMode: LayerVpaManager, SettingResourcePolicy: LayerOverride},
+		},
+		{
+			name:        "VpaOverride template that cannot be rendered",
+			spec:        autoscalingv1.VpaManagerSpec{UpdateMode: "Auto"},
+			overrides:   []autoscalingv1.VpaOverride{override("api", "Deployment", "api", t0, autoscalingv1.VpaOverrideSpec{ResourcePolicy: broken})},
+			expectError: "VpaOverride team-a/api",
+		},
+		{
+			name:         "default controlled resources",
+			spec:         autoscalingv1.VpaManagerSpec{UpdateMode: "Auto", ResourcePolicy: bounded, DefaultControlledResources: []string{"cpu"}},
+			expectedMode: "Auto",
+			expectedPolicy: policies(autoscalingv1.ContainerResourcePolicy{
+				ContainerName: "*", MaxAllowed: autoscalingv1.ResourceBounds{"cpu": "4"}, ControlledResources: []string{"cpu"},
+			}),
+			expectedLayers: map[string]Layer{
+				SettingUpdateMode:          LayerVpaManager,
+				SettingResourcePolicy:      LayerVpaManager,
+				SettingControlledResources: LayerVpaManager,
+			},
+		},
+		{
+			name:         "default controlled resources apply to the VpaOverride resource policy",
+			spec:         autoscalingv1.VpaManagerSpec{UpdateMode: "Auto", DefaultControlledResources: []string{"cpu"}},
+			overrides:    []autoscalingv1.VpaOverride{override("api", "Deployment", "api", t0, autoscalingv1.VpaOverrideSpec{ResourcePolicy: large})},
+			expectedMode: "Auto",
+			expectedPolicy: policies(autoscalingv1.ContainerResourcePolicy{
+				ContainerName: "*", MaxAllowed: autoscalingv1.ResourceBounds{"cpu": "8"}, ControlledResources: []string{"cpu"},
+			}),
+			expectedLayers: map[string]Layer{
+				SettingUpdateMode:          LayerVpaManager,
+				SettingResourcePolicy:      LayerOverride,
+				SettingControlledResources: LayerVpaManager,
+			},
+		},
+		{
+			name:         "well-known sidecars are excluded",
+			spec:         autoscalingv1.VpaManagerSpec{UpdateMode: "Auto", ResourcePolicy: bounded, ExcludeWellKnownSidecars: true},
+			expectedMode: "Auto",
+			expectedPolicy: policies(
+				autoscalingv1.ContainerResourcePolicy{ContainerName: "*", MaxAllowed: autoscalingv1.ResourceBounds{"cpu": "4"}},
+				autoscalingv1.ContainerResourcePolicy{ContainerName: "istio-proxy", Mode: autoscalingv1.ContainerScalingModeOff},
+			),
+			expectedLayers: map[string]Layer{
+				SettingUpdateMode:       LayerVpaManager,
+				SettingResourcePolicy:   LayerVpaManager,
+				SettingExcludedSidecars: LayerVpaManager,
+			},
+		},
+		{
+			name: "a VpaOverride policy for a sidecar is kept",
+			spec: autoscalingv1.VpaManagerSpec{UpdateMode: "Auto", ExcludeWellKnownSidecars: true},
+			overrides: []autoscalingv1.VpaOverride{override("api", "Deployment", "api", t0, autoscalingv1.VpaOverrideSpec{ResourcePolicy: policies(
+				autoscalingv1.ContainerResourcePolicy{ContainerName: "istio-proxy", MaxAllowed: autoscalingv1.ResourceBounds{"cpu": "1"}},
+			)})},
+			expectedMode: "Auto",
+			expectedPolicy: policies(
+				autoscalingv1.ContainerResourcePolicy{ContainerName: "istio-proxy", MaxAllowed: autoscalingv1.ResourceBounds{"cpu": "1"}},
+			),
+			expectedLayers: map[string]Layer{SettingUpdateMode: LayerVpaManager, SettingResourcePolicy: LayerOverride},
+		},
+		{
+			name: "init containers ignored by the VpaOverride resource policy",
+			spec: autoscalingv1.VpaManagerSpec{UpdateMode: "Auto", ResourcePolicy: bounded},
+			overrides: []autoscalingv1.VpaOverride{override("api", "Deployment", "api", t0, autoscalingv1.VpaOverrideSpec{ResourcePolicy: &autoscalingv1.ResourcePolicy{
+				ContainerPolicies:    large.ContainerPolicies,
+				IgnoreInitContainers: true,
+			}})},
+			expectedMode: "Auto",
+			expectedPolicy: &autoscalingv1.ResourcePolicy{
+				ContainerPolicies: []autoscalingv1.ContainerResourcePolicy{
+					{ContainerName: "*", MaxAllowed: autoscalingv1.ResourceBounds{"cpu": "8"}},
+					{ContainerName: "migrate", Mode: autoscalingv1.ContainerScalingModeOff},
+				},
+				IgnoreInitContainers: true,
+			},
+			expectedLayers: map[string]Layer{
+				SettingUpdateMode:            LayerVpaManager,
+				SettingResourcePolicy:        LayerOverride,
+				SettingIgnoredInitContainers: LayerOverride,
+			},
+		},
+		{
+			name:        "invalid VpaOverride",
+			spec:        autoscalingv1.VpaManagerSpec{UpdateMode: "Auto"},
+			overrides:   []autoscalingv1.VpaOverride{override("api", "Deployment", "api", t0, autoscalingv1.VpaOverrideSpec{})},
+			expectError: "VpaOverride team-a/api is invalid",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vpaManager := &autoscalingv1.VpaManager{ObjectMeta: metav1.ObjectMeta{Name: "prod"}, Spec: tt.spec}
+			original := vpaManager.DeepCopy()
+			in := Input{
+				VpaManager:  vpaManager,
+				Kind:        "Deployment",
+				Workload:    &metav1.ObjectMeta{Name: "api", Namespace: "team-a", Annotations: tt.annotations},
+				PodTemplate: podTemplate,
+				Quotas:      tt.quotas,
+				Overrides:   tt.overrides,
+			}
+
+			result, err := Evaluate(in)
+			assert.Equal(t, original, vpaManager, "the VpaManager must not be modified")
+			if tt.expectError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedMode, result.VpaManager.Spec.UpdateMode)
+			assert.Equal(t, tt.expectedPolicy, result.VpaManager.Spec.ResourcePolicy)
+
+			layers := map[string]Layer{}
+			for _, d := range result.Trace {
+				layers[d.Setting] = result.Trace.Winner(d.Setting).Layer
+			}
+			assert.Equal(t, tt.expectedLayers, layers)
+		})
+	}
+}
+
+func TestEvaluate_TraceIsOrderedByPrecedence(t *testing.T) {
+	result, err := Evaluate(Input{
+		VpaManager: &autoscalingv1.VpaManager{
+			ObjectMeta: metav1.ObjectMeta{Name: "prod"},
+			Spec: autoscalingv1.VpaManagerSpec{
+				ResourcePolicy:      policies(autoscalingv1.ContainerResourcePolicy{ContainerName: "*"}),
+				MaxAllowedFromQuota: &autoscalingv1.QuotaFraction{CPUFraction: "0.5"},
+			},
+		},
+		Kind:     "Deployment",
+		Workload: &metav1.ObjectMeta{Name: "api", Namespace: "team-a", Annotations: map[string]string{UpdateModeAnnotation: "Initial"}},
+		Quotas:   []corev1.ResourceQuota{{Spec: corev1.ResourceQuotaSpec{Hard: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("2")}}}},
+		Overrides: []autoscalingv1.VpaOverride{
+			override("api", "Deployment", "api", time.Time{}, autoscalingv1.VpaOverrideSpec{UpdateMode: "Auto"}),
+		},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, Trace{
+		{Setting: SettingUpdateMode, Layer: LayerDefaults, Source: "operator", Value: "Off"},
+		{Setting: SettingResourcePolicy, Layer: LayerVpaManager, Source: "VpaManager prod", Value: "*"},
+		{Setting: "maxAllowed[cpu]", Layer: LayerNamespace, Source: "ResourceQuotas of namespace team-a", Value: "1"},
+		{Setting: SettingUpdateMode, Layer: LayerWorkload, Source: "annotation " + UpdateModeAnnotation, Value: "Initial"},
+		{Setting: SettingUpdateMode, Layer: LayerOverride, Source: "VpaOverride team-a/api", Value: "Auto"},
+	}, result.Trace)
+	assert.Nil(t, result.Trace.Winner("recommenders"))
+}
+
+func TestResult_UpdateModeSource(t *testing.T) {
+	vpaManager := &autoscalingv1.VpaManager{ObjectMeta: metav1.ObjectMeta{Name: "prod"}}
+
+	tests := []struct {
+		name           string
+		trace          Trace
+		annotations    map[string]string
+		expectedLayer  Layer
+		expectedSource string
+	}{
+		{
+			name:           "no decision",
+			expectedLayer:  LayerDefaults,
+			expectedSource: "operator",
+		},
+		{
+			name: "winning layer",
+			trace: Trace{
+				{Setting: SettingUpdateMode, Layer: LayerVpaManager, Source: "VpaManager prod", Value: "Auto"},
+				{Setting: SettingUpdateMode, Layer: LayerOverride, Source: "VpaOverride team-a/api", Value: "Initial"},
+				{Setting: SettingResourcePolicy, Layer: LayerWorkload, Source: "annotation", Value: "*"},
+			},
+			expectedLayer:  LayerOverride,
+			expectedSource: "VpaOverride team-a/api",
+		},
+		{
+			name:           "dormant VPA",
+			trace:          Trace{{Setting: SettingUpdateMode, Layer: LayerVpaManager, Source: "VpaManager prod", Value: "Auto"}},
+			annotations:    map[string]string{vpa.DormantAnnotation: "Auto"},
+			expectedLayer:  LayerDormancy,
+			expectedSource: "dormancy policy of VpaManager prod",
+		},
+		{
+			name:           "eviction blocked",
+			trace:          Trace{{Setting: SettingUpdateMode, Layer: LayerVpaManager, Source: "VpaManager prod", Value: "Auto"}},
+			annotations:    map[string]string{vpa.EvictionBlockedAnnotation: "kubernetes.io/hostname"},
+			expectedLayer:  LayerTopology,
+			expectedSource: "required pod anti-affinity on kubernetes.io/hostname",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := &Result{VpaManager: vpaManager, Trace: tt.trace}
+			layer, source := result.UpdateModeSource(&metav1.ObjectMeta{Annotations: tt.annotations})
+			assert.Equal(t, tt.expectedLayer, layer)
+			assert.Equal(t, tt.expectedSource, source)
+		})
+	}
+}
+
+func TestResolve(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, corev1.AddToScheme(scheme))
+	require.NoError(t, autoscalingv1.AddToScheme(scheme))
+
+	quota := &corev1.ResourceQuota{
+		ObjectMeta: metav1.ObjectMeta{Name: "compute", Namespace: "team-a"},
+		Spec:       corev1.ResourceQuotaSpec{Hard: corev1.ResourceList{corev1.ResourceRequestsCPU: resource.MustParse("4")}},
+	}
+	otherNamespace := override("api", "Deployment", "api", time.Time{}, autoscalingv1.VpaOverrideSpec{UpdateMode: "Off"})
+	otherNamespace.Namespace = "team-b"
+	initial := override("api", "Deployment", "api", time.Time{}, autoscalingv1.VpaOverrideSpec{UpdateMode: "Initial"})
+
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(quota, &otherNamespace, &initial).Build()
+	vpaManager := &autoscalingv1.VpaManager{Spec: autoscalingv1.VpaManagerSpec{
+		UpdateMode:          "Auto",
+		MaxAllowedFromQuota: &autoscalingv1.QuotaFraction{CPUFraction: "0.25"},
+	}}
+
+	result, err := Resolve(context.Background(), c, vpaManager, "Deployment", &metav1.ObjectMeta{Name: "api", Namespace: "team-a"}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "Initial", result.VpaManager.Spec.UpdateMode)
+	assert.Equal(t, policies(autoscalingv1.ContainerResourcePolicy{ContainerName: "*", MaxAllowed: autoscalingv1.ResourceBounds{"cpu": "1"}}),
+		result.VpaManager.Spec.ResourcePolicy)
+}
+
+func TestResolve_WithoutOverrideCRD(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, corev1.AddToScheme(scheme))
+	require.NoError(t, autoscalingv1.AddToScheme(scheme))
+	// The API server does not serve VpaOverrides
+	c := fake.NewClientBuilder().WithScheme(scheme).WithRESTMapper(meta.NewDefaultRESTMapper(nil)).Build()
+
+	vpaManager := &autoscalingv1.VpaManager{Spec: autoscalingv1.VpaManagerSpec{UpdateMode: "Auto"}}
+	result, err := Resolve(context.Background(), c, vpaManager, "Deployment", &metav1.ObjectMeta{Name: "api", Namespace: "team-a"}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "Auto", result.VpaManager.Spec.UpdateMode)
+}
@@ -0,0 +1,83 @@
+package validation
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	utilvalidation "k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	autoscalingv1 "github.com/joaomo/k8s_op_vpa/api/v1"
+)
+
+// hugePagesPrefix prefixes huge page resources, e.g. hugepages-2Mi
+const hugePagesPrefix = "hugepages-"
+
+// standardResources are the unprefixed resource names the VPA recommender understands
+var standardResources = map[string]bool{
+	"cpu":    true,
+	"memory": true,
+}
+
+// isExtendedResource reports whether name is a domain-prefixed resource such as nvidia.com/gpu
+func isExtendedResource(name string) bool {
+	return strings.Contains(name, "/")
+}
+
+// ValidateResourceName checks that name is cpu, memory, hugepages-<size> or a qualified
+// extended resource name. Unknown unprefixed names are left to Warnings.
+func ValidateResourceName(name string, path *field.Path) field.ErrorList {
+	if standardResources[name] {
+		return nil
+	}
+	if size, ok := strings.CutPrefix(name, hugePagesPrefix); ok {
+		if _, err := resource.ParseQuantity(size); err != nil {
+			return field.ErrorList{field.Invalid(path, name, "huge page resources must be named hugepages-<size>, e.g. hugepages-2Mi")}
+		}
+		return nil
+	}
+	if msgs := utilvalidation.IsQualifiedName(name); len(msgs) > 0 {
+		return field.ErrorList{field.Invalid(path, name, strings.Join(msgs, "; "))}
+	}
+	return nil
+}
+
+// validateResourceQuantity checks resource-specific constraints on a parsed quantity
+func validateResourceQuantity(name, value string, q resource.Quantity, path *field.Path) field.ErrorList {
+	if isExtendedResource(name) && q.MilliValue()%1000 != 0 {
+		return field.ErrorList{field.Invalid(path, value, fmt.Sprintf("extended resource %s must be a whole number", name))}
+	}
+	return nil
+}
+
+// resourceWarnings flags unprefixed resource names the VPA recommender does not know,
+// which are passed through unchanged but are most likely typos
+func resourceWarnings(spec *autoscalingv1.VpaManagerSpec) []string {
+	if spec.ResourcePolicy == nil {
+		return nil
+	}
+	var warnings []string
+	policiesPath := field.NewPath("spec", "resourcePolicy", "containerPolicies")
+	for i, cp := range spec.ResourcePolicy.ContainerPolicies {
+		for _, bounds := range []struct {
+			field  string
+			values autoscalingv1.ResourceBounds
+		}{{"minAllowed", cp.MinAllowed}, {"maxAllowed", cp.MaxAllowed}} {
+			names := make([]string, 0, len(bounds.values))
+			for name := range bounds.values {
+				if !standardResources[name] && !isExtendedResource(name) && !strings.HasPrefix(name, hugePagesPrefix) {
+					names = append(names, name)
+				}
+			}
+			sort.Strings(names)
+			for _, name := range names {
+				warnings = append(warnings, fmt.Sprintf("%s: unknown resource %q is passed to the VPA unchanged; "+
+					"use cpu, memory, hugepages-<size> or a domain-prefixed extended resource such as nvidia.com/gpu",
+					policiesPath.Index(i).Child(bounds.field).Key(name), name))
+			}
+		}
+	}
+	return warnings
+}
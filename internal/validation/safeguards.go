@@ -0,0 +1,69 @@
+package validation
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	autoscalingv1 "github.com/joaomo/k8s_op_vpa/api/v1"
+)
+
+// AutoSafeguardMode selects how VpaManagers using Auto mode without an eviction safeguard
+// are admitted
+type AutoSafeguardMode string
+
+const (
+	// AutoSafeguardsOff admits them silently
+	AutoSafeguardsOff AutoSafeguardMode = "off"
+
+	// AutoSafeguardsWarn admits them with a warning
+	AutoSafeguardsWarn AutoSafeguardMode = "warn"
+
+	// AutoSafeguardsEnforce rejects them
+	AutoSafeguardsEnforce AutoSafeguardMode = "enforce"
+)
+
+// minSafeReplicas is the smallest updatePolicy.minReplicas that keeps the VPA updater from
+// evicting the only pod of a workload
+const minSafeReplicas = 2
+
+// ParseAutoSafeguardMode parses the --auto-safeguards flag
+func ParseAutoSafeguardMode(value string) (AutoSafeguardMode, error) {
+	switch mode := AutoSafeguardMode(value); mode {
+	case AutoSafeguardsOff, AutoSafeguardsWarn, AutoSafeguardsEnforce:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("unknown auto safeguard mode %q, must be one of %s, %s, %s",
+			value, AutoSafeguardsOff, AutoSafeguardsWarn, AutoSafeguardsEnforce)
+	}
+}
+
+// UsesAutoMode reports whether a spec puts VPAs of any workload kind in Auto mode
+func UsesAutoMode(spec *autoscalingv1.VpaManagerSpec) bool {
+	if spec.UpdateMode == "Auto" {
+		return true
+	}
+	if spec.UpdateModes == nil {
+		return false
+	}
+	return spec.UpdateModes.Deployment == "Auto" || spec.UpdateModes.StatefulSet == "Auto" || spec.UpdateModes.DaemonSet == "Auto"
+}
+
+// ValidateAutoSafeguards checks that a spec using Auto mode keeps the VPA updater from
+// evicting the last running pods of a workload. updatePolicy.minReplicas of at least 2 is
+// the safeguard the VpaManager API offers.
+func ValidateAutoSafeguards(spec *autoscalingv1.VpaManagerSpec) field.ErrorList {
+	if !UsesAutoMode(spec) {
+		return nil
+	}
+	path := field.NewPath("spec", "updatePolicy", "minReplicas")
+	if spec.UpdatePolicy == nil || spec.UpdatePolicy.MinReplicas == nil {
+		return field.ErrorList{field.Required(path,
+			fmt.Sprintf("Auto mode evicts pods to apply recommendations; set at least %d so workloads with fewer replicas are never evicted", minSafeReplicas))}
+	}
+	if *spec.UpdatePolicy.MinReplicas < minSafeReplicas {
+		return field.ErrorList{field.Invalid(path, *spec.UpdatePolicy.MinReplicas,
+			fmt.Sprintf("must be at least %d in Auto mode so a workload's only pod is never evicted", minSafeReplicas))}
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package validation
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	autoscalingv1 "github.com/joaomo/k8s_op_vpa/api/v1"
+)
+
+// TenantLabel scopes a VpaManager to a tenant. Namespaces carrying the same label
+// value belong to that tenant, and a tenant-scoped manager may only select them.
+const TenantLabel = "vpa-operator.io/tenant"
+
+// ValidateTenantScope checks that a tenant-scoped VpaManager cannot select namespaces
+// outside its tenant. The namespaceSelector must be set on the manager itself, since
+// an omitted or inherited selector could be widened without touching this object, and
+// every entry of namespaceSelectors must be scoped to the tenant as well.
+func ValidateTenantScope(vpaManager *autoscalingv1.VpaManager) field.ErrorList {
+	tenant, ok := vpaManager.Labels[TenantLabel]
+	if !ok {
+		return nil
+	}
+
+	var errs field.ErrorList
+	specPath := field.NewPath("spec")
+	if vpaManager.Spec.MatchAllNamespaces {
+		errs = append(errs, field.Forbidden(specPath.Child("matchAllNamespaces"),
+			fmt.Sprintf("tenant-scoped VpaManagers (label %s=%s) cannot select every namespace", TenantLabel, tenant)))
+	}
+	if !selectorRequiresTenant(vpaManager.Spec.NamespaceSelector, tenant) {
+		errs = append(errs, field.Required(specPath.Child("namespaceSelector"),
+			fmt.Sprintf("tenant-scoped VpaManagers must set namespaceSelector with matchLabels %s: %s", TenantLabel, tenant)))
+	}
+	for i := range vpaManager.Spec.NamespaceSelectors {
+		if !selectorRequiresTenant(&vpaManager.Spec.NamespaceSelectors[i], tenant) {
+			errs = append(errs, field.Required(specPath.Child("namespaceSelectors").Index(i),
+				fmt.Sprintf("tenant-scoped VpaManagers must set matchLabels %s: %s in every namespace selector", TenantLabel, tenant)))
+		}
+	}
+	return errs
+}
+
+// ValidateTenantUpdate rejects removing or changing the tenant label, which would lift the scope check
+func ValidateTenantUpdate(oldManager, newManager *autoscalingv1.VpaManager) field.ErrorList {
+	oldTenant, scoped := oldManager.Labels[TenantLabel]
+	if !scoped {
+		return nil
+	}
+	if newManager.Labels[TenantLabel] != oldTenant {
+		return field.ErrorList{field.Forbidden(field.NewPath("metadata", "labels").Key(TenantLabel),
+			fmt.Sprintf("is immutable once set (was %q)", oldTenant))}
+	}
+	return nil
+}
+
+// NamespaceInTenant reports whether a namespace may be managed by a VpaManager,
+// which is always true for managers without a tenant
+func NamespaceInTenant(vpaManager *autoscalingv1.VpaManager, ns *corev1.Namespace) bool {
+	tenant, ok := vpaManager.Labels[TenantLabel]
+	if !ok {
+		return true
+	}
+	return ns.Labels[TenantLabel] == tenant
+}
+
+// selectorRequiresTenant reports whether every namespace matched by selector must carry the tenant label
+func selectorRequiresTenant(selector *metav1.LabelSelector, tenant string) bool {
+	if selector == nil {
+		return false
+	}
+	if value, ok := selector.MatchLabels[TenantLabel]; ok && value == tenant {
+		return true
+	}
+	for _, req := range selector.MatchExpressions {
+		if req.Key == TenantLabel && req.Operator == metav1.LabelSelectorOpIn &&
+			len(req.Values) == 1 && req.Values[0] == tenant {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,132 @@
+package validation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	autoscalingv1 "github.com/joaomo/k8s_op_vpa/api/v1"
+)
+
+func TestValidateTenantScope(t *testing.T) {
+	tenantLabels := map[string]string{TenantLabel: "payments"}
+
+	tests := []struct {
+		name       string
+		labels     map[string]string
+		spec       autoscalingv1.VpaManagerSpec
+		wantFields []string
+	}{
+		{
+			name: "manager without tenant is not restricted",
+			spec: autoscalingv1.VpaManagerSpec{MatchAllNamespaces: true},
+		},
+		{
+			name:   "tenant selector via matchLabels",
+			labels: tenantLabels,
+			spec: autoscalingv1.VpaManagerSpec{
+				NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{TenantLabel: "payments", "env": "prod"}},
+			},
+		},
+		{
+			name:   "tenant selector via matchExpressions",
+			labels: tenantLabels,
+			spec: autoscalingv1.VpaManagerSpec{
+				NamespaceSelector: &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{
+					{Key: TenantLabel, Operator: metav1.LabelSelectorOpIn, Values: []string{"payments"}},
+				}},
+			},
+		},
+		{
+			name:       "omitted selector",
+			labels:     tenantLabels,
+			wantFields: []string{"spec.namespaceSelector"},
+		},
+		{
+			name:   "selector for another tenant",
+			labels: tenantLabels,
+			spec: autoscalingv1.VpaManagerSpec{
+				NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{TenantLabel: "checkout"}},
+			},
+			wantFields: []string{"spec.namespaceSelector"},
+		},
+		{
+			name:   "selector widened to several tenants",
+			labels: tenantLabels,
+			spec: autoscalingv1.VpaManagerSpec{
+				NamespaceSelector: &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{
+					{Key: TenantLabel, Operator: metav1.LabelSelectorOpIn, Values: []string{"payments", "checkout"}},
+				}},
+			},
+			wantFields: []string{"spec.namespaceSelector"},
+		},
+		{
+			name:   "alternative selector outside the tenant",
+			labels: tenantLabels,
+			spec: autoscalingv1.VpaManagerSpec{
+				NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{TenantLabel: "payments"}},
+				NamespaceSelectors: []metav1.LabelSelector{
+					{MatchLabels: map[string]string{TenantLabel: "payments", "team": "ledger"}},
+					{MatchLabels: map[string]string{"team": "ledger"}},
+				},
+			},
+			wantFields: []string{"spec.namespaceSelectors[1]"},
+		},
+		{
+			name:       "match all namespaces",
+			labels:     tenantLabels,
+			spec:       autoscalingv1.VpaManagerSpec{MatchAllNamespaces: true},
+			wantFields: []string{"spec.matchAllNamespaces", "spec.namespaceSelector"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vm := &autoscalingv1.VpaManager{
+				ObjectMeta: metav1.ObjectMeta{Name: "team", Labels: tt.labels},
+				Spec:       tt.spec,
+			}
+			fields := []string{}
+			for _, err := range ValidateTenantScope(vm) {
+				fields = append(fields, err.Field)
+			}
+			assert.ElementsMatch(t, tt.wantFields, fields)
+		})
+	}
+}
+
+func TestValidateTenantUpdate(t *testing.T) {
+	tests := []struct {
+		name      string
+		oldLabels map[string]string
+		newLabels map[string]string
+		wantErr   bool
+	}{
+		{name: "unscoped manager may gain a tenant", newLabels: map[string]string{TenantLabel: "payments"}},
+		{name: "tenant kept", oldLabels: map[string]string{TenantLabel: "payments"}, newLabels: map[string]string{TenantLabel: "payments"}},
+		{name: "tenant removed", oldLabels: map[string]string{TenantLabel: "payments"}, wantErr: true},
+		{name: "tenant changed", oldLabels: map[string]string{TenantLabel: "payments"}, newLabels: map[string]string{TenantLabel: "checkout"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			oldManager := &autoscalingv1.VpaManager{ObjectMeta: metav1.ObjectMeta{Labels: tt.oldLabels}}
+			newManager := &autoscalingv1.VpaManager{ObjectMeta: metav1.ObjectMeta{Labels: tt.newLabels}}
+			assert.Equal(t, tt.wantErr, len(ValidateTenantUpdate(oldManager, newManager)) > 0)
+		})
+	}
+}
+
+func TestNamespaceInTenant(t *testing.T) {
+	scoped := &autoscalingv1.VpaManager{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{TenantLabel: "payments"}}}
+	unscoped := &autoscalingv1.VpaManager{}
+	own := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{TenantLabel: "payments"}}}
+	foreign := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{TenantLabel: "checkout"}}}
+
+	assert.True(t, NamespaceInTenant(scoped, own))
+	assert.False(t, NamespaceInTenant(scoped, foreign))
+	assert.False(t, NamespaceInTenant(scoped, &corev1.Namespace{}))
+	assert.True(t, NamespaceInTenant(unscoped, foreign))
+}
@@ -0,0 +1,403 @@
+// Package validation contains VpaManager spec validation shared by the
+// validating webhook and the reconciler
+package validation
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilvalidation "k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	autoscalingv1 "github.com/joaomo/k8s_op_vpa/api/v1"
+	"github.com/joaomo/k8s_op_vpa/internal/vpa"
+	"github.com/joaomo/k8s_op_vpa/internal/workload"
+)
+
+// ValidateVpaManagerSpec returns every problem found in a VpaManager spec
+func ValidateVpaManagerSpec(spec *autoscalingv1.VpaManagerSpec) field.ErrorList {
+	var errs field.ErrorList
+	specPath := field.NewPath("spec")
+
+	errs = append(errs, validateSelector(spec.NamespaceSelector, specPath.Child("namespaceSelector"))...)
+	errs = append(errs, validateSelector(spec.DeploymentSelector, specPath.Child("deploymentSelector"))...)
+	errs = append(errs, validateSelector(spec.StatefulSetSelector, specPath.Child("statefulSetSelector"))...)
+	errs = append(errs, validateSelector(spec.DaemonSetSelector, specPath.Child("daemonSetSelector"))...)
+	errs = append(errs, validateSelectors(spec.NamespaceSelectors, specPath.Child("namespaceSelectors"))...)
+	errs = append(errs, validateSelectors(spec.DeploymentSelectors, specPath.Child("deploymentSelectors"))...)
+	errs = append(errs, validateSelectors(spec.StatefulSetSelectors, specPath.Child("statefulSetSelectors"))...)
+	errs = append(errs, validateSelectors(spec.DaemonSetSelectors, specPath.Child("daemonSetSelectors"))...)
+
+	if spec.MatchAllNamespaces && spec.NamespaceSelector != nil {
+		errs = append(errs, field.Forbidden(specPath.Child("matchAllNamespaces"),
+			"cannot be combined with namespaceSelector; remove one of them"))
+	}
+	if spec.MatchAllNamespaces && len(spec.NamespaceSelectors) > 0 {
+		errs = append(errs, field.Forbidden(specPath.Child("matchAllNamespaces"),
+			"cannot be combined with namespaceSelectors; remove one of them"))
+	}
+	if spec.MatchAllNamespaces && len(spec.Namespaces) > 0 {
+		errs = append(errs, field.Forbidden(specPath.Child("matchAllNamespaces"),
+			"cannot be combined with namespaces; remove one of them"))
+	}
+	errs = append(errs, validateNamespaceNames(spec.Namespaces, specPath.Child("namespaces"))...)
+	errs = append(errs, validateNamespaceNames(spec.ExcludeNamespaces, specPath.Child("excludeNamespaces"))...)
+	errs = append(errs, validateSelector(spec.NamespaceExcludeSelector, specPath.Child("namespaceExcludeSelector"))...)
+	if patterns := spec.WorkloadNamePatterns; patterns != nil {
+		patternsPath := specPath.Child("workloadNamePatterns")
+		errs = append(errs, validateNamePatterns(patterns.Include, patternsPath.Child("include"))...)
+		errs = append(errs, validateNamePatterns(patterns.Exclude, patternsPath.Child("exclude"))...)
+	}
+	errs = append(errs, validateControlledResources(spec.DefaultControlledResources, specPath.Child("defaultControlledResources"))...)
+	errs = append(errs, validateVPANameTemplate(spec.VPANameTemplate, specPath.Child("vpaNameTemplate"))...)
+	errs = append(errs, validateVPALabels(spec.VPALabels, specPath.Child("vpaLabels"))...)
+	errs = append(errs, validateVPAAnnotations(spec.VPAAnnotations, specPath.Child("vpaAnnotations"))...)
+
+	if ref := spec.ProfileRef; ref != nil {
+		for _, msg := range utilvalidation.IsDNS1123Label(ref.Namespace) {
+			errs = append(errs, field.Invalid(specPath.Child("profileRef", "namespace"), ref.Namespace, msg))
+		}
+		for _, msg := range utilvalidation.IsDNS1123Subdomain(ref.Name) {
+			errs = append(errs, field.Invalid(specPath.Child("profileRef", "name"), ref.Name, msg))
+		}
+	}
+
+	annotationsPath := specPath.Child("propagateAnnotations")
+	for i, key := range spec.PropagateAnnotations {
+		for _, msg := range utilvalidation.IsQualifiedName(key) {
+			errs = append(errs, field.Invalid(annotationsPath.Index(i), key, msg))
+		}
+	}
+
+	recommendersPath := specPath.Child("recommenders")
+	seenRecommenders := map[string]bool{}
+	for i, rec := range spec.Recommenders {
+		switch {
+		case rec.Name == "":
+			errs = append(errs, field.Required(recommendersPath.Index(i).Child("name"), "recommender name must be set"))
+		case seenRecommenders[rec.Name]:
+			errs = append(errs, field.Duplicate(recommendersPath.Index(i).Child("name"), rec.Name))
+		}
+		seenRecommenders[rec.Name] = true
+	}
+
+	errs = append(errs, validateRecommendationTuning(spec.RecommendationTuning, specPath.Child("recommendationTuning"))...)
+
+	if spec.Dormancy != nil && spec.Dormancy.After.Duration <= 0 {
+		errs = append(errs, field.Invalid(specPath.Child("dormancy", "after"), spec.Dormancy.After.Duration.String(), "must be positive"))
+	}
+
+	if q := spec.MaxAllowedFromQuota; q != nil {
+		fractions := []struct{ name, value string }{
+			{"cpuFraction", q.CPUFraction},
+			{"memoryFraction", q.MemoryFraction},
+		}
+		for _, fr := range fractions {
+			if fr.value == "" {
+				continue
+			}
+			if f, err := strconv.ParseFloat(fr.value, 64); err != nil || f <= 0 || f > 1 {
+				errs = append(errs, field.Invalid(specPath.Child("maxAllowedFromQuota", fr.name), fr.value, "must be a number greater than 0 and at most 1"))
+			}
+		}
+	}
+
+	if spec.ResourcePolicy != nil {
+		policiesPath := specPath.Child("resourcePolicy", "containerPolicies")
+		for i := range spec.ResourcePolicy.ContainerPolicies {
+			errs = append(errs, ValidateContainerPolicy(&spec.ResourcePolicy.ContainerPolicies[i], policiesPath.Index(i))...)
+		}
+	}
+
+	return errs
+}
+
+// ValidateVpaOverrideSpec returns every problem found in a VpaOverride spec
+func ValidateVpaOverrideSpec(spec *autoscalingv1.VpaOverrideSpec) field.ErrorList {
+	var errs field.ErrorList
+	specPath := field.NewPath("spec")
+
+	if spec.TargetRef.Name == "" {
+		errs = append(errs, field.Required(specPath.Child("targetRef", "name"), "workload name must be set"))
+	}
+	if spec.UpdateMode == "" && spec.ResourcePolicy == nil {
+		errs = append(errs, field.Required(specPath, "at least one of updateMode and resourcePolicy must be set"))
+	}
+	if spec.ResourcePolicy != nil {
+		policiesPath := specPath.Child("resourcePolicy", "containerPolicies")
+		for i := range spec.ResourcePolicy.ContainerPolicies {
+			errs = append(errs, ValidateContainerPolicy(&spec.ResourcePolicy.ContainerPolicies[i], policiesPath.Index(i))...)
+		}
+	}
+
+	return errs
+}
+
+// Warnings returns non-fatal notices about a VpaManager spec, such as deprecated behavior
+func Warnings(spec *autoscalingv1.VpaManagerSpec) []string {
+	var warnings []string
+	if len(spec.NamespaceSelectorTerms()) == 0 && !spec.MatchAllNamespaces && len(spec.Namespaces) == 0 {
+		warnings = append(warnings, "spec.namespaceSelector is omitted and currently matches every namespace; "+
+			"this is deprecated and will match no namespaces in a future release. "+
+			"Set spec.matchAllNamespaces: true to keep the current behavior, or add a namespaceSelector")
+	}
+	if spec.RecommendationTuning != nil && len(spec.Recommenders) == 0 {
+		warnings = append(warnings, "spec.recommendationTuning is ignored by the default VPA recommender; "+
+			"set spec.recommenders to a recommender that reads "+vpa.TuningAnnotationPrefix+" annotations")
+	}
+	warnings = append(warnings, resourceWarnings(spec)...)
+	return warnings
+}
+
+// validateNamespaceNames checks that every entry is a namespace name and listed once
+func validateNamespaceNames(names []string, path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	seen := map[string]bool{}
+	for i, name := range names {
+		for _, msg := range utilvalidation.IsDNS1123Label(name) {
+			errs = append(errs, field.Invalid(path.Index(i), name, msg))
+		}
+		if seen[name] {
+			errs = append(errs, field.Duplicate(path.Index(i), name))
+		}
+		seen[name] = true
+	}
+	return errs
+}
+
+// validateVPANameTemplate checks that a VPA name template renders valid names that differ
+// per workload, so two workloads never share a VPA
+func validateVPANameTemplate(nameTemplate string, path *field.Path) field.ErrorList {
+	if nameTemplate == "" {
+		return nil
+	}
+	first, err := vpa.RenderName(nameTemplate, vpa.NameTemplateData{Kind: "Deployment", Name: "web", Namespace: "default"})
+	if err != nil {
+		return field.ErrorList{field.Invalid(path, nameTemplate, err.Error())}
+	}
+	second, err := vpa.RenderName(nameTemplate, vpa.NameTemplateData{Kind: "Deployment", Name: "api", Namespace: "default"})
+	if err != nil {
+		return field.ErrorList{field.Invalid(path, nameTemplate, err.Error())}
+	}
+	if first == second {
+		return field.ErrorList{field.Invalid(path, nameTemplate, "must include the workload name, {{ .Name }}")}
+	}
+	return nil
+}
+
+// validateVPALabels checks that vpaLabels are valid labels that do not collide with the
+// ones the operator sets itself
+func validateVPALabels(labels map[string]string, path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	for _, key := range sortedKeys(labels) {
+		for _, msg := range utilvalidation.IsQualifiedName(key) {
+			errs = append(errs, field.Invalid(path.Key(key), key, msg))
+		}
+		for _, msg := range utilvalidation.IsValidLabelValue(labels[key]) {
+			errs = append(errs, field.Invalid(path.Key(key), labels[key], msg))
+		}
+		if (vpa.Ownership{}).ReservedLabel(key) {
+			errs = append(errs, field.Forbidden(path.Key(key), "is set by the operator"))
+		}
+	}
+	return errs
+}
+
+// validateVPAAnnotations checks that vpaAnnotations keys are valid and outside the
+// operator's own prefix
+func validateVPAAnnotations(annotations map[string]string, path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	for _, key := range sortedKeys(annotations) {
+		for _, msg := range utilvalidation.IsQualifiedName(key) {
+			errs = append(errs, field.Invalid(path.Key(key), key, msg))
+		}
+		if strings.HasPrefix(key, vpa.ReservedKeyPrefix) {
+			errs = append(errs, field.Forbidden(path.Key(key), "is set by the operator"))
+		}
+	}
+	return errs
+}
+
+// sortedKeys returns the keys of m in order, so errors are reported deterministically
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// validateNamePatterns checks that every name pattern is a valid regular expression
+func validateNamePatterns(patterns []string, path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	for i, pattern := range patterns {
+		if _, err := workload.CompileNamePatterns([]string{pattern}, nil); err != nil {
+			errs = append(errs, field.Invalid(path.Index(i), pattern, err.Error()))
+		}
+	}
+	return errs
+}
+
+// ValidateContainerPolicy checks that all quantities parse and minAllowed <= maxAllowed,
+// for requests and for limits. Templated bounds are only checked for syntax and are not
+// compared; limit bounds must be literal and need limits to be scaled with requests. A
+// container name pattern must be a valid glob.
+func ValidateContainerPolicy(cp *autoscalingv1.ContainerResourcePolicy, path *field.Path) field.ErrorList {
+	errs := validateBounds(cp.MinAllowed, cp.MaxAllowed, path)
+	if vpa.IsContainerPattern(cp.ContainerName) && !vpa.ValidContainerPattern(cp.ContainerName) {
+		errs = append(errs, field.Invalid(path.Child("containerName"), cp.ContainerName, "not a valid glob pattern"))
+	}
+	if cp.Limits != nil {
+		limitsPath := path.Child("limits")
+		for _, bounds := range []struct {
+			field  string
+			values autoscalingv1.ResourceBounds
+		}{{"minAllowed", cp.Limits.MinAllowed}, {"maxAllowed", cp.Limits.MaxAllowed}} {
+			for name, value := range bounds.values {
+				if vpa.IsBoundTemplate(value) {
+					errs = append(errs, field.Invalid(limitsPath.Child(bounds.field).Key(name), value,
+						"must be a literal quantity; limit bounds are not templated"))
+				}
+			}
+		}
+		errs = append(errs, validateBounds(cp.Limits.MinAllowed, cp.Limits.MaxAllowed, limitsPath)...)
+		if cp.ControlledValues == autoscalingv1.ControlledValuesRequestsOnly {
+			errs = append(errs, field.Invalid(limitsPath, "", "must not be set with controlledValues RequestsOnly; "+
+				"limit bounds are converted into request bounds assuming limits are scaled with requests"))
+		}
+	}
+	errs = append(errs, validateControlledResources(cp.ControlledResources, path.Child("controlledResources"))...)
+	return errs
+}
+
+// validateControlledResources checks that controlled resources are cpu or memory, each listed once
+func validateControlledResources(names []string, path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	seen := map[string]bool{}
+	for i, name := range names {
+		resourcePath := path.Index(i)
+		if name != string(corev1.ResourceCPU) && name != string(corev1.ResourceMemory) {
+			errs = append(errs, field.NotSupported(resourcePath, name, []string{string(corev1.ResourceCPU), string(corev1.ResourceMemory)}))
+		}
+		if seen[name] {
+			errs = append(errs, field.Duplicate(resourcePath, name))
+		}
+		seen[name] = true
+	}
+	return errs
+}
+
+// validateBounds checks that all quantities parse and minAllowed <= maxAllowed
+func validateBounds(minValues, maxValues autoscalingv1.ResourceBounds, path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+
+	minAllowed, minErrs := parseQuantities(minValues, path.Child("minAllowed"))
+	errs = append(errs, minErrs...)
+	maxAllowed, maxErrs := parseQuantities(maxValues, path.Child("maxAllowed"))
+	errs = append(errs, maxErrs...)
+
+	for name, minQ := range minAllowed {
+		maxQ, ok := maxAllowed[name]
+		if !ok {
+			continue
+		}
+		if minQ.Cmp(maxQ) > 0 {
+			errs = append(errs, field.Invalid(path.Child("minAllowed").Key(name), minValues[name],
+				fmt.Sprintf("must be less than or equal to maxAllowed %s (%s)", name, maxValues[name])))
+		}
+	}
+
+	return errs
+}
+
+// parseQuantities parses a map of resource quantities, reporting each value that does not parse
+func parseQuantities(values map[string]string, path *field.Path) (map[string]resource.Quantity, field.ErrorList) {
+	var errs field.ErrorList
+	parsed := make(map[string]resource.Quantity, len(values))
+	for name, value := range values {
+		if nameErrs := ValidateResourceName(name, path.Key(name)); len(nameErrs) > 0 {
+			errs = append(errs, nameErrs...)
+			continue
+		}
+		if vpa.IsBoundTemplate(value) {
+			// Evaluated per workload when the VPA is built, so only the syntax is checked here
+			if _, err := vpa.ParseBoundTemplate(value); err != nil {
+				errs = append(errs, field.Invalid(path.Key(name), value, fmt.Sprintf("invalid template: %v", err)))
+			}
+			continue
+		}
+		q, err := resource.ParseQuantity(value)
+		if err != nil {
+			errs = append(errs, field.Invalid(path.Key(name), value,
+				"must be a valid resource quantity, e.g. \"100m\" or \"1\" for cpu and \"128Mi\" or \"1Gi\" for memory"))
+			continue
+		}
+		if q.Sign() < 0 {
+			errs = append(errs, field.Invalid(path.Key(name), value, "must not be negative"))
+			continue
+		}
+		if quantityErrs := validateResourceQuantity(name, value, q, path.Key(name)); len(quantityErrs) > 0 {
+			errs = append(errs, quantityErrs...)
+			continue
+		}
+		parsed[name] = q
+	}
+	return parsed, errs
+}
+
+// validateRecommendationTuning checks that percentiles are in (0, 1], the safety margin is
+// not negative and every parameter makes a valid annotation key
+func validateRecommendationTuning(tuning *autoscalingv1.RecommendationTuning, path *field.Path) field.ErrorList {
+	if tuning == nil {
+		return nil
+	}
+	var errs field.ErrorList
+	percentiles := []struct{ name, value string }{
+		{"targetCPUPercentile", tuning.TargetCPUPercentile},
+		{"targetMemoryPercentile", tuning.TargetMemoryPercentile},
+	}
+	for _, p := range percentiles {
+		if p.value == "" {
+			continue
+		}
+		if f, err := strconv.ParseFloat(p.value, 64); err != nil || f <= 0 || f > 1 {
+			errs = append(errs, field.Invalid(path.Child(p.name), p.value, "must be a number greater than 0 and at most 1"))
+		}
+	}
+	if value := tuning.SafetyMarginFraction; value != "" {
+		if f, err := strconv.ParseFloat(value, 64); err != nil || f < 0 {
+			errs = append(errs, field.Invalid(path.Child("safetyMarginFraction"), value, "must be a non-negative number"))
+		}
+	}
+	for name := range tuning.Parameters {
+		for _, msg := range utilvalidation.IsQualifiedName(vpa.TuningAnnotationPrefix + name) {
+			errs = append(errs, field.Invalid(path.Child("parameters").Key(name), name, msg))
+		}
+	}
+	return errs
+}
+
+// validateSelector checks that a label selector can be converted to a selector
+func validateSelectors(selectors []metav1.LabelSelector, path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	for i := range selectors {
+		errs = append(errs, validateSelector(&selectors[i], path.Index(i))...)
+	}
+	return errs
+}
+
+func validateSelector(selector *metav1.LabelSelector, path *field.Path) field.ErrorList {
+	if selector == nil {
+		return nil
+	}
+	if _, err := metav1.LabelSelectorAsSelector(selector); err != nil {
+		return field.ErrorList{field.Invalid(path, selector, err.Error())}
+	}
+	return nil
+}
@@ -0,0 +1,637 @@
+package validation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	autoscalingv1 "github.com/joaomo/k8s_op_vpa/api/v1"
+)
+
+func TestValidateVpaManagerSpec(t *testing.T) {
+	tests := []struct {
+		name       string
+		spec       autoscalingv1.VpaManagerSpec
+		wantFields []string
+	}{
+		{
+			name: "valid spec",
+			spec: autoscalingv1.VpaManagerSpec{
+				Enabled:    true,
+				UpdateMode: "Auto",
+				ResourcePolicy: &autoscalingv1.ResourcePolicy{
+					ContainerPolicies: []autoscalingv1.ContainerResourcePolicy{{
+						ContainerName: "*",
+						MinAllowed:    map[string]string{"cpu": "100m", "memory": "100Mi"},
+						MaxAllowed:    map[string]string{"cpu": "1", "memory": "1Gi"},
+					}},
+				},
+			},
+		},
+		{
+			name: "unparseable quantity",
+			spec: autoscalingv1.VpaManagerSpec{
+				ResourcePolicy: &autoscalingv1.ResourcePolicy{
+					ContainerPolicies: []autoscalingv1.ContainerResourcePolicy{{
+						ContainerName: "*",
+						MaxAllowed:    map[string]string{"memory": "1 gigabyte"},
+					}},
+				},
+			},
+			wantFields: []string{"spec.resourcePolicy.containerPolicies[0].maxAllowed[memory]"},
+		},
+		{
+			name: "negative quantity",
+			spec: autoscalingv1.VpaManagerSpec{
+				ResourcePolicy: &autoscalingv1.ResourcePolicy{
+					ContainerPolicies: []autoscalingv1.ContainerResourcePolicy{{
+						ContainerName: "*",
+						MinAllowed:    map[string]string{"cpu": "-1"},
+					}},
+				},
+			},
+			wantFields: []string{"spec.resourcePolicy.containerPolicies[0].minAllowed[cpu]"},
+		},
+		{
+			name: "min greater than max",
+			spec: autoscalingv1.VpaManagerSpec{
+				ResourcePolicy: &autoscalingv1.ResourcePolicy{
+					ContainerPolicies: []autoscalingv1.ContainerResourcePolicy{
+						{ContainerName: "main"},
+						{
+							ContainerName: "*",
+							MinAllowed:    map[string]string{"cpu": "2", "memory": "64Mi"},
+							MaxAllowed:    map[string]string{"cpu": "1500m", "memory": "1Gi"},
+						},
+					},
+				},
+			},
+			wantFields: []string{"spec.resourcePolicy.containerPolicies[1].minAllowed[cpu]"},
+		},
+		{
+			name: "invalid selector operator",
+			spec: autoscalingv1.VpaManagerSpec{
+				DeploymentSelector: &metav1.LabelSelector{
+					MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "tier", Operator: "Like"}},
+				},
+			},
+			wantFields: []string{"spec.deploymentSelector"},
+		},
+		{
+			name: "container name patterns",
+			spec: autoscalingv1.VpaManagerSpec{
+				ResourcePolicy: &autoscalingv1.ResourcePolicy{
+					ContainerPolicies: []autoscalingv1.ContainerResourcePolicy{
+						{ContainerName: "*-sidecar"},
+						{ContainerName: "worker-[0-9"},
+					},
+				},
+			},
+			wantFields: []string{"spec.resourcePolicy.containerPolicies[1].containerName"},
+		},
+		{
+			name: "invalid propagated annotation key",
+			spec: autoscalingv1.VpaManagerSpec{
+				PropagateAnnotations: []string{"team", "example.com/change-ticket", "not a key"},
+			},
+			wantFields: []string{"spec.propagateAnnotations[2]"},
+		},
+		{
+			name: "valid VPA labels and annotations",
+			spec: autoscalingv1.VpaManagerSpec{
+				VPALabels:      map[string]string{"team": "payments", "example.com/tier": "gold"},
+				VPAAnnotations: map[string]string{"cost-center": "cc-42, shared"},
+			},
+		},
+		{
+			name: "invalid VPA labels",
+			spec: autoscalingv1.VpaManagerSpec{
+				VPALabels: map[string]string{
+					"team":                         "payments and billing",
+					"not a key":                    "x",
+					"app.kubernetes.io/managed-by": "argocd",
+					"vpa-operator.io/tier":         "gold",
+				},
+			},
+			wantFields: []string{
+				"spec.vpaLabels[app.kubernetes.io/managed-by]",
+				"spec.vpaLabels[not a key]",
+				"spec.vpaLabels[team]",
+				"spec.vpaLabels[vpa-operator.io/tier]",
+			},
+		},
+		{
+			name: "invalid VPA annotations",
+			spec: autoscalingv1.VpaManagerSpec{
+				VPAAnnotations: map[string]string{"not a key": "x", "vpa-operator.io/source-uid": "forged"},
+			},
+			wantFields: []string{"spec.vpaAnnotations[not a key]", "spec.vpaAnnotations[vpa-operator.io/source-uid]"},
+		},
+		{
+			name: "matchAllNamespaces with namespaceSelector",
+			spec: autoscalingv1.VpaManagerSpec{
+				MatchAllNamespaces: true,
+				NamespaceSelector:  &metav1.LabelSelector{MatchLabels: map[string]string{"vpa-enabled": "true"}},
+			},
+			wantFields: []string{"spec.matchAllNamespaces"},
+		},
+		{
+			name: "matchAllNamespaces with namespaceSelectors",
+			spec: autoscalingv1.VpaManagerSpec{
+				MatchAllNamespaces: true,
+				NamespaceSelectors: []metav1.LabelSelector{{MatchLabels: map[string]string{"team": "a"}}},
+			},
+			wantFields: []string{"spec.matchAllNamespaces"},
+		},
+		{
+			name: "invalid alternative selectors",
+			spec: autoscalingv1.VpaManagerSpec{
+				MatchAllNamespaces: true,
+				DeploymentSelectors: []metav1.LabelSelector{
+					{MatchLabels: map[string]string{"team": "a"}},
+					{MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "team", Operator: "Bogus"}}},
+				},
+				StatefulSetSelectors: []metav1.LabelSelector{
+					{MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "team", Operator: metav1.LabelSelectorOpIn}}},
+				},
+			},
+			wantFields: []string{"spec.deploymentSelectors[1]", "spec.statefulSetSelectors[0]"},
+		},
+		{
+			name: "namespace lists",
+			spec: autoscalingv1.VpaManagerSpec{
+				Namespaces:        []string{"prod-a", "prod-b"},
+				ExcludeNamespaces: []string{"kube-system"},
+			},
+		},
+		{
+			name: "invalid namespace exclude selector",
+			spec: autoscalingv1.VpaManagerSpec{
+				NamespaceExcludeSelector: &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "tier", Operator: "Bogus"}}},
+			},
+			wantFields: []string{"spec.namespaceExcludeSelector"},
+		},
+		{
+			name: "VPA name template",
+			spec: autoscalingv1.VpaManagerSpec{VPANameTemplate: "{{ .Kind | lower }}-{{ .Name }}-vpa"},
+		},
+		{
+			name:       "VPA name template that cannot be parsed",
+			spec:       autoscalingv1.VpaManagerSpec{VPANameTemplate: "{{ .Name "},
+			wantFields: []string{"spec.vpaNameTemplate"},
+		},
+		{
+			name:       "VPA name template rendering an invalid name",
+			spec:       autoscalingv1.VpaManagerSpec{VPANameTemplate: "{{ .Kind }}_{{ .Name }}"},
+			wantFields: []string{"spec.vpaNameTemplate"},
+		},
+		{
+			name:       "VPA name template without the workload name",
+			spec:       autoscalingv1.VpaManagerSpec{VPANameTemplate: "{{ .Namespace }}-vpa"},
+			wantFields: []string{"spec.vpaNameTemplate"},
+		},
+		{
+			name: "default controlled resources",
+			spec: autoscalingv1.VpaManagerSpec{DefaultControlledResources: []string{"cpu"}},
+		},
+		{
+			name:       "invalid default controlled resources",
+			spec:       autoscalingv1.VpaManagerSpec{DefaultControlledResources: []string{"cpu", "nvidia.com/gpu", "cpu"}},
+			wantFields: []string{"spec.defaultControlledResources[1]", "spec.defaultControlledResources[2]"},
+		},
+		{
+			name: "workload name patterns",
+			spec: autoscalingv1.VpaManagerSpec{
+				WorkloadNamePatterns: &autoscalingv1.NamePatterns{Include: []string{"legacy-.*"}, Exclude: []string{".*-canary"}},
+			},
+		},
+		{
+			name: "invalid workload name patterns",
+			spec: autoscalingv1.VpaManagerSpec{
+				WorkloadNamePatterns: &autoscalingv1.NamePatterns{Include: []string{"legacy-.*", "legacy-("}, Exclude: []string{"*-canary"}},
+			},
+			wantFields: []string{"spec.workloadNamePatterns.include[1]", "spec.workloadNamePatterns.exclude[0]"},
+		},
+		{
+			name:       "matchAllNamespaces with namespaces",
+			spec:       autoscalingv1.VpaManagerSpec{MatchAllNamespaces: true, Namespaces: []string{"prod-a"}},
+			wantFields: []string{"spec.matchAllNamespaces"},
+		},
+		{
+			name: "invalid or duplicated namespace names",
+			spec: autoscalingv1.VpaManagerSpec{
+				Namespaces:        []string{"prod-a", "Prod_B", "prod-a"},
+				ExcludeNamespaces: []string{""},
+			},
+			wantFields: []string{"spec.namespaces[1]", "spec.namespaces[2]", "spec.excludeNamespaces[0]"},
+		},
+		{
+			name: "profile reference",
+			spec: autoscalingv1.VpaManagerSpec{
+				ProfileRef: &autoscalingv1.ProfileReference{Namespace: "platform", Name: "vpa.defaults"},
+			},
+		},
+		{
+			name: "invalid profile reference",
+			spec: autoscalingv1.VpaManagerSpec{
+				ProfileRef: &autoscalingv1.ProfileReference{Namespace: "Platform", Name: ""},
+			},
+			wantFields: []string{"spec.profileRef.namespace", "spec.profileRef.name"},
+		},
+		{
+			name: "recommenders without name or duplicated",
+			spec: autoscalingv1.VpaManagerSpec{
+				Recommenders: []autoscalingv1.RecommenderSelector{{Name: "gpu"}, {Name: ""}, {Name: "gpu"}},
+			},
+			wantFields: []string{"spec.recommenders[1].name", "spec.recommenders[2].name"},
+		},
+		{
+			name: "recommendation tuning out of range",
+			spec: autoscalingv1.VpaManagerSpec{
+				RecommendationTuning: &autoscalingv1.RecommendationTuning{
+					TargetCPUPercentile:    "0",
+					TargetMemoryPercentile: "1.5",
+					SafetyMarginFraction:   "-0.1",
+					Parameters:             map[string]string{"history length": "8d"},
+				},
+			},
+			wantFields: []string{
+				"spec.recommendationTuning.targetCPUPercentile",
+				"spec.recommendationTuning.targetMemoryPercentile",
+				"spec.recommendationTuning.safetyMarginFraction",
+				"spec.recommendationTuning.parameters[history length]",
+			},
+		},
+		{
+			name: "dormancy without a positive duration",
+			spec: autoscalingv1.VpaManagerSpec{
+				Dormancy: &autoscalingv1.DormancyPolicy{},
+			},
+			wantFields: []string{"spec.dormancy.after"},
+		},
+		{
+			name: "quota fractions out of range",
+			spec: autoscalingv1.VpaManagerSpec{
+				MaxAllowedFromQuota: &autoscalingv1.QuotaFraction{CPUFraction: "0", MemoryFraction: "1.5"},
+			},
+			wantFields: []string{
+				"spec.maxAllowedFromQuota.cpuFraction",
+				"spec.maxAllowedFromQuota.memoryFraction",
+			},
+		},
+		{
+			name: "valid recommendation tuning",
+			spec: autoscalingv1.VpaManagerSpec{
+				RecommendationTuning: &autoscalingv1.RecommendationTuning{
+					TargetCPUPercentile:    "0.9",
+					TargetMemoryPercentile: "1",
+					SafetyMarginFraction:   "0.15",
+					Parameters:             map[string]string{"history-length": "8d"},
+				},
+			},
+		},
+		{
+			name: "extended and huge page resources",
+			spec: autoscalingv1.VpaManagerSpec{
+				ResourcePolicy: &autoscalingv1.ResourcePolicy{
+					ContainerPolicies: []autoscalingv1.ContainerResourcePolicy{{
+						ContainerName: "trainer",
+						MinAllowed:    map[string]string{"nvidia.com/gpu": "1", "hugepages-2Mi": "64Mi"},
+						MaxAllowed:    map[string]string{"nvidia.com/gpu": "4", "hugepages-2Mi": "1Gi"},
+					}},
+				},
+			},
+		},
+		{
+			name: "templated bounds",
+			spec: autoscalingv1.VpaManagerSpec{
+				ResourcePolicy: &autoscalingv1.ResourcePolicy{
+					ContainerPolicies: []autoscalingv1.ContainerResourcePolicy{{
+						ContainerName: "*",
+						MinAllowed:    map[string]string{"memory": "{{ .Requests.memory | multiply 4 }}"},
+						MaxAllowed:    map[string]string{"memory": "1Gi", "cpu": "{{ .Requests.cpu | multiply 2"},
+					}},
+				},
+			},
+			wantFields: []string{"spec.resourcePolicy.containerPolicies[0].maxAllowed[cpu]"},
+		},
+		{
+			name: "malformed resource names and fractional extended resource",
+			spec: autoscalingv1.VpaManagerSpec{
+				ResourcePolicy: &autoscalingv1.ResourcePolicy{
+					ContainerPolicies: []autoscalingv1.ContainerResourcePolicy{{
+						ContainerName: "trainer",
+						MinAllowed:    map[string]string{"nvidia.com/gpu": "500m", "hugepages-huge": "1Gi"},
+						MaxAllowed:    map[string]string{"example.com/": "1"},
+					}},
+				},
+			},
+			wantFields: []string{
+				"spec.resourcePolicy.containerPolicies[0].minAllowed[nvidia.com/gpu]",
+				"spec.resourcePolicy.containerPolicies[0].minAllowed[hugepages-huge]",
+				"spec.resourcePolicy.containerPolicies[0].maxAllowed[example.com/]",
+			},
+		},
+		{
+			name: "limit bounds",
+			spec: autoscalingv1.VpaManagerSpec{
+				ResourcePolicy: &autoscalingv1.ResourcePolicy{
+					ContainerPolicies: []autoscalingv1.ContainerResourcePolicy{{
+						ContainerName: "*",
+						Limits: &autoscalingv1.LimitBounds{
+							MinAllowed: map[string]string{"cpu": "100m"},
+							MaxAllowed: map[string]string{"cpu": "2", "memory": "4Gi"},
+						},
+					}},
+				},
+			},
+		},
+		{
+			name: "invalid limit bounds",
+			spec: autoscalingv1.VpaManagerSpec{
+				ResourcePolicy: &autoscalingv1.ResourcePolicy{
+					ContainerPolicies: []autoscalingv1.ContainerResourcePolicy{{
+						ContainerName: "*",
+						Limits: &autoscalingv1.LimitBounds{
+							MinAllowed: map[string]string{"cpu": "4", "memory": "{{ .Limits.memory }}"},
+							MaxAllowed: map[string]string{"cpu": "2", "memory": "lots"},
+						},
+					}},
+				},
+			},
+			wantFields: []string{
+				"spec.resourcePolicy.containerPolicies[0].limits.minAllowed[cpu]",
+				"spec.resourcePolicy.containerPolicies[0].limits.minAllowed[memory]",
+				"spec.resourcePolicy.containerPolicies[0].limits.maxAllowed[memory]",
+			},
+		},
+		{
+			name: "memory-only policy",
+			spec: autoscalingv1.VpaManagerSpec{
+				ResourcePolicy: &autoscalingv1.ResourcePolicy{
+					ContainerPolicies: []autoscalingv1.ContainerResourcePolicy{{
+						ContainerName:       "*",
+						ControlledResources: []string{"memory"},
+					}},
+				},
+			},
+		},
+		{
+			name: "unsupported and duplicate controlled resources",
+			spec: autoscalingv1.VpaManagerSpec{
+				ResourcePolicy: &autoscalingv1.ResourcePolicy{
+					ContainerPolicies: []autoscalingv1.ContainerResourcePolicy{{
+						ContainerName:       "*",
+						ControlledResources: []string{"memory", "nvidia.com/gpu", "memory"},
+					}},
+				},
+			},
+			wantFields: []string{
+				"spec.resourcePolicy.containerPolicies[0].controlledResources[1]",
+				"spec.resourcePolicy.containerPolicies[0].controlledResources[2]",
+			},
+		},
+		{
+			name: "requests only",
+			spec: autoscalingv1.VpaManagerSpec{
+				ResourcePolicy: &autoscalingv1.ResourcePolicy{
+					ContainerPolicies: []autoscalingv1.ContainerResourcePolicy{{
+						ContainerName:    "*",
+						MaxAllowed:       map[string]string{"memory": "2Gi"},
+						ControlledValues: autoscalingv1.ControlledValuesRequestsOnly,
+					}},
+				},
+			},
+		},
+		{
+			name: "limit bounds with requests only",
+			spec: autoscalingv1.VpaManagerSpec{
+				ResourcePolicy: &autoscalingv1.ResourcePolicy{
+					ContainerPolicies: []autoscalingv1.ContainerResourcePolicy{{
+						ContainerName:    "*",
+						Limits:           &autoscalingv1.LimitBounds{MaxAllowed: map[string]string{"memory": "4Gi"}},
+						ControlledValues: autoscalingv1.ControlledValuesRequestsOnly,
+					}},
+				},
+			},
+			wantFields: []string{"spec.resourcePolicy.containerPolicies[0].limits"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := ValidateVpaManagerSpec(&tt.spec)
+			fields := make([]string, 0, len(errs))
+			for _, err := range errs {
+				fields = append(fields, err.Field)
+			}
+			assert.ElementsMatch(t, tt.wantFields, fields)
+		})
+	}
+}
+
+func TestValidateVpaOverrideSpec(t *testing.T) {
+	target := autoscalingv1.OverrideTargetReference{Kind: "Deployment", Name: "api"}
+
+	tests := []struct {
+		name       string
+		spec       autoscalingv1.VpaOverrideSpec
+		wantFields []string
+	}{
+		{
+			name: "valid update mode override",
+			spec: autoscalingv1.VpaOverrideSpec{TargetRef: target, UpdateMode: "Off"},
+		},
+		{
+			name:       "nothing overridden",
+			spec:       autoscalingv1.VpaOverrideSpec{TargetRef: target},
+			wantFields: []string{"spec"},
+		},
+		{
+			name:       "target without a name",
+			spec:       autoscalingv1.VpaOverrideSpec{TargetRef: autoscalingv1.OverrideTargetReference{Kind: "Deployment"}, UpdateMode: "Off"},
+			wantFields: []string{"spec.targetRef.name"},
+		},
+		{
+			name: "minAllowed above maxAllowed",
+			spec: autoscalingv1.VpaOverrideSpec{
+				TargetRef: target,
+				ResourcePolicy: &autoscalingv1.ResourcePolicy{
+					ContainerPolicies: []autoscalingv1.ContainerResourcePolicy{{
+						ContainerName: "*",
+						MinAllowed:    map[string]string{"cpu": "2"},
+						MaxAllowed:    map[string]string{"cpu": "1"},
+					}},
+				},
+			},
+			wantFields: []string{"spec.resourcePolicy.containerPolicies[0].minAllowed[cpu]"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := ValidateVpaOverrideSpec(&tt.spec)
+			fields := make([]string, 0, len(errs))
+			for _, err := range errs {
+				fields = append(fields, err.Field)
+			}
+			assert.ElementsMatch(t, tt.wantFields, fields)
+		})
+	}
+}
+
+func TestValidateContainerPolicy_MessageIsActionable(t *testing.T) {
+	spec := autoscalingv1.VpaManagerSpec{
+		ResourcePolicy: &autoscalingv1.ResourcePolicy{
+			ContainerPolicies: []autoscalingv1.ContainerResourcePolicy{{
+				ContainerName: "*",
+				MinAllowed:    map[string]string{"memory": "2Gi"},
+				MaxAllowed:    map[string]string{"memory": "1Gi"},
+			}},
+		},
+	}
+
+	errs := ValidateVpaManagerSpec(&spec)
+	assert.Len(t, errs, 1)
+	assert.Contains(t, errs.ToAggregate().Error(), "must be less than or equal to maxAllowed memory (1Gi)")
+}
+
+func TestWarnings(t *testing.T) {
+	tests := []struct {
+		name         string
+		spec         autoscalingv1.VpaManagerSpec
+		wantWarnings int
+	}{
+		{
+			name:         "omitted namespace selector is deprecated",
+			spec:         autoscalingv1.VpaManagerSpec{},
+			wantWarnings: 1,
+		},
+		{
+			name: "explicit namespace selector",
+			spec: autoscalingv1.VpaManagerSpec{
+				NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"vpa-enabled": "true"}},
+			},
+		},
+		{
+			name: "explicit match all",
+			spec: autoscalingv1.VpaManagerSpec{MatchAllNamespaces: true},
+		},
+		{
+			name: "alternative namespace selectors only",
+			spec: autoscalingv1.VpaManagerSpec{
+				NamespaceSelectors: []metav1.LabelSelector{{MatchLabels: map[string]string{"team": "a"}}},
+			},
+		},
+		{
+			name: "namespaces listed by name",
+			spec: autoscalingv1.VpaManagerSpec{Namespaces: []string{"prod-a"}},
+		},
+		{
+			name: "unknown unprefixed resource",
+			spec: autoscalingv1.VpaManagerSpec{
+				MatchAllNamespaces: true,
+				ResourcePolicy: &autoscalingv1.ResourcePolicy{
+					ContainerPolicies: []autoscalingv1.ContainerResourcePolicy{{
+						ContainerName: "*",
+						MaxAllowed:    map[string]string{"cpus": "2", "nvidia.com/gpu": "1", "memory": "1Gi"},
+					}},
+				},
+			},
+			wantWarnings: 1,
+		},
+		{
+			name: "recommendation tuning for the default recommender",
+			spec: autoscalingv1.VpaManagerSpec{
+				MatchAllNamespaces:   true,
+				RecommendationTuning: &autoscalingv1.RecommendationTuning{TargetCPUPercentile: "0.9"},
+			},
+			wantWarnings: 1,
+		},
+		{
+			name: "recommendation tuning for a custom recommender",
+			spec: autoscalingv1.VpaManagerSpec{
+				MatchAllNamespaces:   true,
+				Recommenders:         []autoscalingv1.RecommenderSelector{{Name: "tuned"}},
+				RecommendationTuning: &autoscalingv1.RecommendationTuning{TargetCPUPercentile: "0.9"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Len(t, Warnings(&tt.spec), tt.wantWarnings)
+		})
+	}
+}
+
+func TestValidateAutoSafeguards(t *testing.T) {
+	one, two := int32(1), int32(2)
+
+	tests := []struct {
+		name       string
+		spec       autoscalingv1.VpaManagerSpec
+		wantFields []string
+	}{
+		{
+			name: "off mode",
+			spec: autoscalingv1.VpaManagerSpec{UpdateMode: "Off"},
+		},
+		{
+			name:       "auto without minReplicas",
+			spec:       autoscalingv1.VpaManagerSpec{UpdateMode: "Auto"},
+			wantFields: []string{"spec.updatePolicy.minReplicas"},
+		},
+		{
+			name:       "auto with an empty updatePolicy",
+			spec:       autoscalingv1.VpaManagerSpec{UpdateMode: "Auto", UpdatePolicy: &autoscalingv1.UpdatePolicy{}},
+			wantFields: []string{"spec.updatePolicy.minReplicas"},
+		},
+		{
+			name:       "auto with minReplicas 1",
+			spec:       autoscalingv1.VpaManagerSpec{UpdateMode: "Auto", UpdatePolicy: &autoscalingv1.UpdatePolicy{MinReplicas: &one}},
+			wantFields: []string{"spec.updatePolicy.minReplicas"},
+		},
+		{
+			name: "auto with minReplicas 2",
+			spec: autoscalingv1.VpaManagerSpec{UpdateMode: "Auto", UpdatePolicy: &autoscalingv1.UpdatePolicy{MinReplicas: &two}},
+		},
+		{
+			name: "auto for one kind only",
+			spec: autoscalingv1.VpaManagerSpec{
+				UpdateMode:  "Initial",
+				UpdateModes: &autoscalingv1.UpdateModesByKind{StatefulSet: "Auto"},
+			},
+			wantFields: []string{"spec.updatePolicy.minReplicas"},
+		},
+		{
+			name: "non-auto kind modes",
+			spec: autoscalingv1.VpaManagerSpec{
+				UpdateMode:  "Off",
+				UpdateModes: &autoscalingv1.UpdateModesByKind{Deployment: "Initial"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := ValidateAutoSafeguards(&tt.spec)
+			var fields []string
+			for _, err := range errs {
+				fields = append(fields, err.Field)
+			}
+			assert.Equal(t, tt.wantFields, fields)
+		})
+	}
+}
+
+func TestParseAutoSafeguardMode(t *testing.T) {
+	for _, value := range []string{"off", "warn", "enforce"} {
+		mode, err := ParseAutoSafeguardMode(value)
+		assert.NoError(t, err)
+		assert.Equal(t, AutoSafeguardMode(value), mode)
+	}
+	_, err := ParseAutoSafeguardMode("strict")
+	assert.Error(t, err)
+}
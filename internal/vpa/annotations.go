@@ -0,0 +1,42 @@
+package vpa
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// SourceUIDAnnotation records the UID of the workload a VPA was generated for
+const SourceUIDAnnotation = "vpa-operator.io/source-uid"
+
+// TraceAnnotations returns the annotations that tie a VPA to its source workload:
+// the workload UID plus every allow-listed workload annotation that is set
+func TraceAnnotations(source metav1.Object, allowList []string) map[string]string {
+	trace := map[string]string{
+		SourceUIDAnnotation: string(source.GetUID()),
+	}
+	sourceAnnotations := source.GetAnnotations()
+	for _, key := range allowList {
+		if value, ok := sourceAnnotations[key]; ok {
+			trace[key] = value
+		}
+	}
+	return trace
+}
+
+// ApplyTraceAnnotations merges trace annotations into target and reports whether anything changed
+func ApplyTraceAnnotations(target metav1.Object, trace map[string]string) bool {
+	annotations := target.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string, len(trace))
+	}
+	changed := false
+	for k, v := range trace {
+		if current, ok := annotations[k]; !ok || current != v {
+			annotations[k] = v
+			changed = true
+		}
+	}
+	if changed {
+		target.SetAnnotations(annotations)
+	}
+	return changed
+}
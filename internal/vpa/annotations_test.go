@@ -0,0 +1,87 @@
+package vpa
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestTraceAnnotations(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		allowList   []string
+		expected    map[string]string
+	}{
+		{
+			name:     "uid only without allow-list",
+			expected: map[string]string{SourceUIDAnnotation: "uid-1"},
+		},
+		{
+			name:        "copies allow-listed annotations",
+			annotations: map[string]string{"team": "payments", "service-tier": "gold", "unrelated": "x"},
+			allowList:   []string{"team", "service-tier"},
+			expected:    map[string]string{SourceUIDAnnotation: "uid-1", "team": "payments", "service-tier": "gold"},
+		},
+		{
+			name:        "skips allow-listed annotations that are not set",
+			annotations: map[string]string{"team": "payments"},
+			allowList:   []string{"team", "change-ticket"},
+			expected:    map[string]string{SourceUIDAnnotation: "uid-1", "team": "payments"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source := &metav1.ObjectMeta{UID: "uid-1", Annotations: tt.annotations}
+			assert.Equal(t, tt.expected, TraceAnnotations(source, tt.allowList))
+		})
+	}
+}
+
+func TestApplyTraceAnnotations(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		trace       map[string]string
+		expected    map[string]string
+		changed     bool
+	}{
+		{
+			name:     "adds to empty annotations",
+			trace:    map[string]string{"team": "payments"},
+			expected: map[string]string{"team": "payments"},
+			changed:  true,
+		},
+		{
+			name:        "keeps unrelated annotations",
+			annotations: map[string]string{"vpa-operator.io/spec-hash": "abc"},
+			trace:       map[string]string{"team": "payments"},
+			expected:    map[string]string{"vpa-operator.io/spec-hash": "abc", "team": "payments"},
+			changed:     true,
+		},
+		{
+			name:        "overwrites stale values",
+			annotations: map[string]string{"team": "payments"},
+			trace:       map[string]string{"team": "checkout"},
+			expected:    map[string]string{"team": "checkout"},
+			changed:     true,
+		},
+		{
+			name:        "unchanged when already applied",
+			annotations: map[string]string{"team": "payments"},
+			trace:       map[string]string{"team": "payments"},
+			expected:    map[string]string{"team": "payments"},
+			changed:     false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target := &metav1.ObjectMeta{Annotations: tt.annotations}
+			assert.Equal(t, tt.changed, ApplyTraceAnnotations(target, tt.trace))
+			assert.Equal(t, tt.expected, target.GetAnnotations())
+		})
+	}
+}
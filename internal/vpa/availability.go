@@ -0,0 +1,98 @@
+package vpa
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/discovery"
+)
+
+// CRDName is the name of the VerticalPodAutoscaler CustomResourceDefinition
+const CRDName = "verticalpodautoscalers.autoscaling.k8s.io"
+
+// DefaultAvailabilityTTL is how long a discovery answer is trusted when no CRD event arrives
+const DefaultAvailabilityTTL = time.Minute
+
+// ErrNotServed is returned while the API server does not serve VerticalPodAutoscalers
+var ErrNotServed = errors.New("VerticalPodAutoscalers are not served, install the " + CRDName + " CRD")
+
+// Availability caches whether the API server serves VerticalPodAutoscalers, so the operator
+// degrades to doing nothing while the VPA CRD is missing and recovers once it is installed,
+// without a restart. Answers are trusted for a TTL; a watch on the CRD can Invalidate them
+// earlier. A nil Availability reports VPAs as always served.
+type Availability struct {
+	discovery discovery.DiscoveryInterface
+	ttl       time.Duration
+
+	mu        sync.Mutex
+	served    bool
+	checkedAt time.Time
+	now       func() time.Time
+}
+
+// NewAvailability returns an Availability asking d at most once per ttl,
+// DefaultAvailabilityTTL when ttl is not positive
+func NewAvailability(d discovery.DiscoveryInterface, ttl time.Duration) *Availability {
+	if ttl <= 0 {
+		ttl = DefaultAvailabilityTTL
+	}
+	return &Availability{discovery: d, ttl: ttl, now: time.Now}
+}
+
+// Served reports whether the API server serves VerticalPodAutoscalers, asking discovery when
+// the cached answer expired. Discovery errors are returned and not cached.
+func (a *Availability) Served() (bool, error) {
+	if a == nil {
+		return true, nil
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if !a.checkedAt.IsZero() && a.now().Sub(a.checkedAt) < a.ttl {
+		return a.served, nil
+	}
+	served, err := a.discover()
+	if err != nil {
+		return false, err
+	}
+	a.served = served
+	a.checkedAt = a.now()
+	return served, nil
+}
+
+// TTL returns how long an answer is trusted, 0 for a nil Availability
+func (a *Availability) TTL() time.Duration {
+	if a == nil {
+		return 0
+	}
+	return a.ttl
+}
+
+// Invalidate drops the cached answer, so the next call to Served asks discovery
+func (a *Availability) Invalidate() {
+	if a == nil {
+		return
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.checkedAt = time.Time{}
+}
+
+// discover asks the API server whether the VPA group version lists verticalpodautoscalers
+func (a *Availability) discover() (bool, error) {
+	resources, err := a.discovery.ServerResourcesForGroupVersion(vpaListGVK.GroupVersion().String())
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	for _, resource := range resources.APIResources {
+		if resource.Name == "verticalpodautoscalers" {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,96 @@
+package vpa
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	clienttesting "k8s.io/client-go/testing"
+)
+
+// failingDiscovery fails every resource lookup
+type failingDiscovery struct {
+	*fakediscovery.FakeDiscovery
+}
+
+func (failingDiscovery) ServerResourcesForGroupVersion(string) (*metav1.APIResourceList, error) {
+	return nil, errors.New("connection refused")
+}
+
+func TestAvailability_Served(t *testing.T) {
+	vpaResources := &metav1.APIResourceList{
+		GroupVersion: "autoscaling.k8s.io/v1",
+		APIResources: []metav1.APIResource{{Name: "verticalpodautoscalers"}, {Name: "verticalpodautoscalercheckpoints"}},
+	}
+
+	tests := []struct {
+		name      string
+		resources []*metav1.APIResourceList
+		expected  bool
+	}{
+		{name: "group not served"},
+		{
+			name:      "group served without VPAs",
+			resources: []*metav1.APIResourceList{{GroupVersion: "autoscaling.k8s.io/v1"}},
+		},
+		{
+			name:      "VPAs served",
+			resources: []*metav1.APIResourceList{vpaResources},
+			expected:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &fakediscovery.FakeDiscovery{Fake: &clienttesting.Fake{Resources: tt.resources}}
+			served, err := NewAvailability(d, time.Minute).Served()
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, served)
+		})
+	}
+}
+
+func TestAvailability_CachesUntilTTLOrInvalidate(t *testing.T) {
+	fake := &clienttesting.Fake{}
+	a := NewAvailability(&fakediscovery.FakeDiscovery{Fake: fake}, time.Minute)
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	a.now = func() time.Time { return now }
+
+	served, err := a.Served()
+	require.NoError(t, err)
+	assert.False(t, served)
+
+	// The CRD is installed; the cached answer holds until the TTL passes
+	fake.Resources = []*metav1.APIResourceList{{
+		GroupVersion: "autoscaling.k8s.io/v1",
+		APIResources: []metav1.APIResource{{Name: "verticalpodautoscalers"}},
+	}}
+	served, _ = a.Served()
+	assert.False(t, served)
+	now = now.Add(time.Minute)
+	served, _ = a.Served()
+	assert.True(t, served)
+
+	// Invalidate asks again right away
+	fake.Resources = nil
+	a.Invalidate()
+	served, _ = a.Served()
+	assert.False(t, served)
+	assert.Len(t, fake.Actions(), 3)
+}
+
+func TestAvailability_DiscoveryErrorsAreNotCached(t *testing.T) {
+	a := NewAvailability(failingDiscovery{}, time.Minute)
+	_, err := a.Served()
+	require.Error(t, err)
+	assert.True(t, a.checkedAt.IsZero())
+
+	var nilAvailability *Availability
+	served, err := nilAvailability.Served()
+	require.NoError(t, err)
+	assert.True(t, served)
+}
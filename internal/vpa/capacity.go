@@ -0,0 +1,110 @@
+package vpa
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// capacityCountTTL is how long a count of managed VPAs is trusted before it is listed again
+const capacityCountTTL = time.Minute
+
+// vpaListGVK is the list kind of VerticalPodAutoscalers
+var vpaListGVK = schema.GroupVersionKind{Group: "autoscaling.k8s.io", Version: "v1", Kind: "VerticalPodAutoscalerList"}
+
+// ClusterCapacity caps the VPAs an operator instance manages across the cluster, protecting
+// etcd from runaway VPA creation by an over-broad selector. Every creation reserves a slot.
+// The managed VPAs are counted at most once per capacityCountTTL and the count grows with
+// every reservation in between, so deleted VPAs only free their slot at the next count.
+// A nil ClusterCapacity allows every creation.
+type ClusterCapacity struct {
+	max       int
+	ownership Ownership
+
+	mu        sync.Mutex
+	count     int
+	countedAt time.Time
+	now       func() time.Time
+}
+
+// NewClusterCapacity returns a cap of max VPAs carrying the ownership label; it returns
+// nil, allowing every creation, when max is not positive
+func NewClusterCapacity(max int, ownership Ownership) *ClusterCapacity {
+	if max <= 0 {
+		return nil
+	}
+	return &ClusterCapacity{max: max, ownership: ownership, now: time.Now}
+}
+
+// Reserve takes a slot for a new VPA and reports whether the cap allowed it. The managed
+// VPAs are listed with reader when the last count is stale.
+func (c *ClusterCapacity) Reserve(ctx context.Context, reader client.Reader) (bool, error) {
+	if c == nil {
+		return true, nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.countedAt.IsZero() || c.now().Sub(c.countedAt) >= capacityCountTTL {
+		count, err := c.countManaged(ctx, reader)
+		if err != nil {
+			return false, err
+		}
+		c.count = count
+		c.countedAt = c.now()
+	}
+	if c.count >= c.max {
+		return false, nil
+	}
+	c.count++
+	return true, nil
+}
+
+// Release returns a slot reserved for a VPA that was not created
+func (c *ClusterCapacity) Release() {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.count > 0 {
+		c.count--
+	}
+}
+
+// Usage returns the managed VPAs, as last counted plus the reservations since, and the cap
+func (c *ClusterCapacity) Usage() (count, max int) {
+	if c == nil {
+		return 0, 0
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.count, c.max
+}
+
+// countManaged lists the VPAs of this operator instance across the cluster
+func (c *ClusterCapacity) countManaged(ctx context.Context, reader client.Reader) (int, error) {
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(vpaListGVK)
+	opts := []client.ListOption{client.MatchingLabels(c.ownership.Selector()), client.Limit(500)}
+
+	count := 0
+	for {
+		if err := reader.List(ctx, list, opts...); err != nil {
+			return 0, err
+		}
+		for i := range list.Items {
+			if c.ownership.Owns(&list.Items[i]) {
+				count++
+			}
+		}
+		if list.GetContinue() == "" {
+			return count, nil
+		}
+		opts = []client.ListOption{client.MatchingLabels(c.ownership.Selector()), client.Limit(500), client.Continue(list.GetContinue())}
+	}
+}
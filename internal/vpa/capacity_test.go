@@ -0,0 +1,119 @@
+package vpa
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestClusterCapacity_Reserve(t *testing.T) {
+	managedVPA := func(name string, labels map[string]string) client.Object {
+		obj := &unstructured.Unstructured{}
+		obj.SetAPIVersion("autoscaling.k8s.io/v1")
+		obj.SetKind("VerticalPodAutoscaler")
+		obj.SetName(name)
+		obj.SetNamespace("test-ns")
+		obj.SetLabels(labels)
+		return obj
+	}
+	owned := map[string]string{ManagedByLabel: DefaultManagedByValue}
+
+	tests := []struct {
+		name     string
+		max      int
+		existing []client.Object
+		reserve  int
+		expected []bool
+	}{
+		{
+			name:     "no cap",
+			existing: []client.Object{managedVPA("a", owned)},
+			reserve:  2,
+			expected: []bool{true, true},
+		},
+		{
+			name:     "reserves up to the cap",
+			max:      3,
+			existing: []client.Object{managedVPA("a", owned)},
+			reserve:  3,
+			expected: []bool{true, true, false},
+		},
+		{
+			name:     "VPAs of other operators do not count",
+			max:      1,
+			existing: []client.Object{managedVPA("a", map[string]string{ManagedByLabel: "other"}), managedVPA("b", nil)},
+			reserve:  1,
+			expected: []bool{true},
+		},
+		{
+			name:     "already over the cap",
+			max:      1,
+			existing: []client.Object{managedVPA("a", owned), managedVPA("b", owned)},
+			reserve:  1,
+			expected: []bool{false},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := fake.NewClientBuilder().WithScheme(runtime.NewScheme()).WithObjects(tt.existing...).Build()
+			capacity := NewClusterCapacity(tt.max, Ownership{})
+
+			var got []bool
+			for i := 0; i < tt.reserve; i++ {
+				allowed, err := capacity.Reserve(context.Background(), c)
+				require.NoError(t, err)
+				got = append(got, allowed)
+			}
+			assert.Equal(t, tt.expected, got)
+		})
+	}
+}
+
+func TestClusterCapacity_RecountsAfterTTL(t *testing.T) {
+	ctx := context.Background()
+	owned := map[string]string{ManagedByLabel: DefaultManagedByValue}
+	c := fake.NewClientBuilder().WithScheme(runtime.NewScheme()).Build()
+	create := func(name string) {
+		obj := &unstructured.Unstructured{}
+		obj.SetAPIVersion("autoscaling.k8s.io/v1")
+		obj.SetKind("VerticalPodAutoscaler")
+		obj.SetName(name)
+		obj.SetNamespace("test-ns")
+		obj.SetLabels(owned)
+		require.NoError(t, c.Create(ctx, obj))
+	}
+
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	capacity := NewClusterCapacity(2, Ownership{})
+	capacity.now = func() time.Time { return now }
+
+	allowed, err := capacity.Reserve(ctx, c)
+	require.NoError(t, err)
+	require.True(t, allowed)
+	capacity.Release()
+
+	// VPAs created by someone else are only seen at the next count
+	for i := 0; i < 2; i++ {
+		create(fmt.Sprintf("vpa-%d", i))
+	}
+	allowed, err = capacity.Reserve(ctx, c)
+	require.NoError(t, err)
+	assert.True(t, allowed)
+
+	now = now.Add(capacityCountTTL)
+	allowed, err = capacity.Reserve(ctx, c)
+	require.NoError(t, err)
+	assert.False(t, allowed)
+	count, max := capacity.Usage()
+	assert.Equal(t, 2, count)
+	assert.Equal(t, 2, max)
+}
@@ -0,0 +1,115 @@
+package vpa
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// WarmStartedFromAnnotation is set on a new VPA to the VPA its workload was migrated from,
+// whose recommender checkpoints were copied to it
+const WarmStartedFromAnnotation = "vpa-operator.io/warm-started-from"
+
+// SupersededByAnnotation is set on the VPA a workload was migrated from to the VPA that
+// replaced it, once its update mode was switched to Off
+const SupersededByAnnotation = "vpa-operator.io/superseded-by"
+
+var (
+	// checkpointGVK is the kind of the recommender's VerticalPodAutoscalerCheckpoints
+	checkpointGVK = schema.GroupVersionKind{Group: "autoscaling.k8s.io", Version: "v1", Kind: "VerticalPodAutoscalerCheckpoint"}
+
+	// checkpointListGVK is the list kind of VerticalPodAutoscalerCheckpoints
+	checkpointListGVK = schema.GroupVersionKind{Group: "autoscaling.k8s.io", Version: "v1", Kind: "VerticalPodAutoscalerCheckpointList"}
+
+	// vpaGVK is the kind of VerticalPodAutoscalers
+	vpaGVK = schema.GroupVersionKind{Group: "autoscaling.k8s.io", Version: "v1", Kind: "VerticalPodAutoscaler"}
+)
+
+// PreviousVPA returns the name of a VPA in namespace other than vpaName that targets the
+// workload of the given kind and name, i.e. the VPA the workload is migrated from, or ""
+// when there is none. Of several, the first by name is returned.
+func PreviousVPA(ctx context.Context, reader client.Reader, namespace, kind, name, vpaName string) (string, error) {
+	targeting, err := TargetingVPAs(ctx, reader, namespace, kind, name)
+	if err != nil {
+		return "", err
+	}
+	for _, candidate := range targeting {
+		if candidate != vpaName {
+			return candidate, nil
+		}
+	}
+	return "", nil
+}
+
+// CopyCheckpoints copies the recommender checkpoints of the VPA from to the VPA to, both in
+// namespace, so the recommender keeps the usage history of the workload instead of starting
+// over. Copies are named "<vpa>-<container>" like the recommender names checkpoints, and
+// checkpoints to already has are kept. It returns the number of checkpoints copied.
+func CopyCheckpoints(ctx context.Context, c client.Client, namespace, from, to string) (int, error) {
+	checkpointList := &unstructured.UnstructuredList{}
+	checkpointList.SetGroupVersionKind(checkpointListGVK)
+	if err := c.List(ctx, checkpointList, client.InNamespace(namespace)); err != nil {
+		return 0, fmt.Errorf("listing VPA checkpoints: %w", err)
+	}
+
+	copied := 0
+	for _, item := range checkpointList.Items {
+		vpaName, _, _ := unstructured.NestedString(item.Object, "spec", "vpaObjectName")
+		containerName, _, _ := unstructured.NestedString(item.Object, "spec", "containerName")
+		if vpaName != from || containerName == "" {
+			continue
+		}
+
+		checkpoint := &unstructured.Unstructured{Object: map[string]interface{}{
+			"spec": map[string]interface{}{
+				"vpaObjectName": to,
+				"containerName": containerName,
+			},
+		}}
+		checkpoint.SetGroupVersionKind(checkpointGVK)
+		checkpoint.SetNamespace(namespace)
+		checkpoint.SetName(fmt.Sprintf("%s-%s", to, containerName))
+		if status, ok := item.Object["status"]; ok {
+			checkpoint.Object["status"] = runtime.DeepCopyJSONValue(status)
+		}
+		if err := c.Create(ctx, checkpoint); err != nil {
+			if errors.IsAlreadyExists(err) {
+				continue
+			}
+			return copied, fmt.Errorf("copying VPA checkpoint %s: %w", item.GetName(), err)
+		}
+		copied++
+	}
+	return copied, nil
+}
+
+// Supersede switches the VPA previous in namespace to updateMode Off and records the VPA by
+// that replaced it, so two VPAs never act on the pods of one workload. The previous VPA
+// keeps its recommendations; only the updater and admission controller stop applying them.
+func Supersede(ctx context.Context, c client.Client, namespace, previous, by string) error {
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(vpaGVK)
+	if err := c.Get(ctx, client.ObjectKey{Namespace: namespace, Name: previous}, obj); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+
+	patched := obj.DeepCopy()
+	if err := unstructured.SetNestedField(patched.Object, "Off", "spec", "updatePolicy", "updateMode"); err != nil {
+		return fmt.Errorf("disabling VPA %s: %w", previous, err)
+	}
+	annotations := patched.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[SupersededByAnnotation] = by
+	patched.SetAnnotations(annotations)
+	if err := c.Patch(ctx, patched, client.MergeFrom(obj)); err != nil {
+		return fmt.Errorf("disabling VPA %s: %w", previous, err)
+	}
+	return nil
+}
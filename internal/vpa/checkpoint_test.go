@@ -0,0 +1,118 @@
+package vpa
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newTestVPA(name, kind, target string) client.Object {
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{
+			"targetRef": map[string]interface{}{"apiVersion": "apps/v1", "kind": kind, "name": target},
+		},
+	}}
+	obj.SetAPIVersion("autoscaling.k8s.io/v1")
+	obj.SetKind("VerticalPodAutoscaler")
+	obj.SetName(name)
+	obj.SetNamespace("test-ns")
+	return obj
+}
+
+func newTestCheckpoint(name, vpaName, container string, samples int64) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec":   map[string]interface{}{"vpaObjectName": vpaName, "containerName": container},
+		"status": map[string]interface{}{"totalSamplesCount": samples},
+	}}
+	obj.SetGroupVersionKind(checkpointGVK)
+	obj.SetName(name)
+	obj.SetNamespace("test-ns")
+	return obj
+}
+
+func TestPreviousVPA(t *testing.T) {
+	tests := []struct {
+		name     string
+		existing []client.Object
+		expected string
+	}{
+		{
+			name:     "no other VPA",
+			existing: []client.Object{newTestVPA("api-vpa", "Deployment", "api")},
+		},
+		{
+			name:     "hand-made VPA of the workload",
+			existing: []client.Object{newTestVPA("api-vpa", "Deployment", "api"), newTestVPA("api", "Deployment", "api")},
+			expected: "api",
+		},
+		{
+			name:     "first by name of several",
+			existing: []client.Object{newTestVPA("legacy-b", "Deployment", "api"), newTestVPA("legacy-a", "Deployment", "api")},
+			expected: "legacy-a",
+		},
+		{
+			name:     "VPA of a workload of another kind",
+			existing: []client.Object{newTestVPA("api", "StatefulSet", "api")},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := fake.NewClientBuilder().WithScheme(runtime.NewScheme()).WithObjects(tt.existing...).Build()
+			previous, err := PreviousVPA(context.Background(), c, "test-ns", "Deployment", "api", "api-vpa")
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, previous)
+		})
+	}
+}
+
+func TestCopyCheckpoints(t *testing.T) {
+	c := fake.NewClientBuilder().WithScheme(runtime.NewScheme()).WithObjects(
+		newTestCheckpoint("api-app", "api", "app", 42),
+		newTestCheckpoint("api-istio-proxy", "api", "istio-proxy", 7),
+		newTestCheckpoint("other-app", "other", "app", 1),
+		newTestCheckpoint("api-vpa-istio-proxy", "api-vpa", "istio-proxy", 3),
+	).Build()
+	ctx := context.Background()
+
+	copied, err := CopyCheckpoints(ctx, c, "test-ns", "api", "api-vpa")
+	require.NoError(t, err)
+	assert.Equal(t, 1, copied, "checkpoints the new VPA already has are kept")
+
+	checkpoint := &unstructured.Unstructured{}
+	checkpoint.SetGroupVersionKind(checkpointGVK)
+	require.NoError(t, c.Get(ctx, types.NamespacedName{Namespace: "test-ns", Name: "api-vpa-app"}, checkpoint))
+	vpaName, _, _ := unstructured.NestedString(checkpoint.Object, "spec", "vpaObjectName")
+	samples, _, _ := unstructured.NestedInt64(checkpoint.Object, "status", "totalSamplesCount")
+	assert.Equal(t, "api-vpa", vpaName)
+	assert.Equal(t, int64(42), samples)
+
+	require.NoError(t, c.Get(ctx, types.NamespacedName{Namespace: "test-ns", Name: "api-vpa-istio-proxy"}, checkpoint))
+	samples, _, _ = unstructured.NestedInt64(checkpoint.Object, "status", "totalSamplesCount")
+	assert.Equal(t, int64(3), samples)
+}
+
+func TestSupersede(t *testing.T) {
+	previous := newTestVPA("api", "Deployment", "api")
+	c := fake.NewClientBuilder().WithScheme(runtime.NewScheme()).WithObjects(previous).Build()
+	ctx := context.Background()
+
+	require.NoError(t, Supersede(ctx, c, "test-ns", "api", "api-vpa"))
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(vpaGVK)
+	require.NoError(t, c.Get(ctx, types.NamespacedName{Namespace: "test-ns", Name: "api"}, obj))
+	spec, _ := obj.Object["spec"].(map[string]interface{})
+	assert.Equal(t, "Off", UpdateMode(spec))
+	assert.Equal(t, "api-vpa", obj.GetAnnotations()[SupersededByAnnotation])
+	targetName, _, _ := unstructured.NestedString(obj.Object, "spec", "targetRef", "name")
+	assert.Equal(t, "api", targetName)
+
+	assert.NoError(t, Supersede(ctx, c, "test-ns", "gone", "api-vpa"), "a VPA deleted meanwhile needs no disabling")
+}
@@ -0,0 +1,76 @@
+package vpa
+
+import (
+	"path"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+
+	autoscalingv1 "github.com/joaomo/k8s_op_vpa/api/v1"
+)
+
+// IsContainerPattern reports whether a container policy name is a glob pattern, such as
+// "*-sidecar", rather than a container name or the "*" wildcard
+func IsContainerPattern(name string) bool {
+	return name != WildcardContainer && strings.ContainsAny(name, "*?[")
+}
+
+// ValidContainerPattern reports whether a container pattern is well-formed
+func ValidContainerPattern(pattern string) bool {
+	_, err := path.Match(pattern, "")
+	return err == nil
+}
+
+// WithExpandedContainerPatterns returns vpaManager with every container policy whose name
+// is a glob pattern replaced by one policy per matching container or init container of
+// podTemplate. The VPA
+// only knows container names and "*", so patterns are resolved before it is built.
+// Containers with a policy of their own, or matched by an earlier pattern, are skipped;
+// a pattern matching no container is dropped. It is returned as-is without patterns.
+func WithExpandedContainerPatterns(vpaManager *autoscalingv1.VpaManager, podTemplate *corev1.PodTemplateSpec) *autoscalingv1.VpaManager {
+	policy := vpaManager.Spec.ResourcePolicy
+	if policy == nil || !hasContainerPattern(policy.ContainerPolicies) {
+		return vpaManager
+	}
+
+	claimed := map[string]bool{}
+	for _, cp := range policy.ContainerPolicies {
+		if !IsContainerPattern(cp.ContainerName) {
+			claimed[cp.ContainerName] = true
+		}
+	}
+
+	expanded := make([]autoscalingv1.ContainerResourcePolicy, 0, len(policy.ContainerPolicies))
+	for _, cp := range policy.ContainerPolicies {
+		if !IsContainerPattern(cp.ContainerName) {
+			expanded = append(expanded, *cp.DeepCopy())
+			continue
+		}
+		for _, c := range allContainers(podTemplate) {
+			if claimed[c.Name] {
+				continue
+			}
+			if matched, _ := path.Match(cp.ContainerName, c.Name); !matched {
+				continue
+			}
+			claimed[c.Name] = true
+			out := *cp.DeepCopy()
+			out.ContainerName = c.Name
+			expanded = append(expanded, out)
+		}
+	}
+
+	out := vpaManager.DeepCopy()
+	out.Spec.ResourcePolicy.ContainerPolicies = expanded
+	return out
+}
+
+// hasContainerPattern reports whether any policy is keyed by a container pattern
+func hasContainerPattern(policies []autoscalingv1.ContainerResourcePolicy) bool {
+	for _, cp := range policies {
+		if IsContainerPattern(cp.ContainerName) {
+			return true
+		}
+	}
+	return false
+}
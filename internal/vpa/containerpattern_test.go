@@ -0,0 +1,131 @@
+package vpa
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	autoscalingv1 "github.com/joaomo/k8s_op_vpa/api/v1"
+)
+
+func TestWithExpandedContainerPatterns(t *testing.T) {
+	podTemplate := &corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+		Containers: []corev1.Container{
+			{Name: "app"}, {Name: "envoy-sidecar"}, {Name: "log-sidecar"}, {Name: "worker-1"},
+		},
+		InitContainers: []corev1.Container{{Name: "init-db"}, {Name: "init-cache"}},
+	}}
+	capped := autoscalingv1.ResourceBounds{"cpu": "100m"}
+
+	tests := []struct {
+		name        string
+		policies    []autoscalingv1.ContainerResourcePolicy
+		podTemplate *corev1.PodTemplateSpec
+		expected    []autoscalingv1.ContainerResourcePolicy
+	}{
+		{
+			name:        "names and wildcard are kept",
+			policies:    []autoscalingv1.ContainerResourcePolicy{{ContainerName: "app"}, {ContainerName: "*"}},
+			podTemplate: podTemplate,
+			expected:    []autoscalingv1.ContainerResourcePolicy{{ContainerName: "app"}, {ContainerName: "*"}},
+		},
+		{
+			name:        "suffix pattern",
+			policies:    []autoscalingv1.ContainerResourcePolicy{{ContainerName: "*-sidecar", MaxAllowed: capped}, {ContainerName: "*"}},
+			podTemplate: podTemplate,
+			expected: []autoscalingv1.ContainerResourcePolicy{
+				{ContainerName: "envoy-sidecar", MaxAllowed: capped},
+				{ContainerName: "log-sidecar", MaxAllowed: capped},
+				{ContainerName: "*"},
+			},
+		},
+		{
+			name: "containers with their own policy are skipped",
+			policies: []autoscalingv1.ContainerResourcePolicy{
+				{ContainerName: "*-sidecar", MaxAllowed: capped},
+				{ContainerName: "envoy-sidecar"},
+			},
+			podTemplate: podTemplate,
+			expected: []autoscalingv1.ContainerResourcePolicy{
+				{ContainerName: "log-sidecar", MaxAllowed: capped},
+				{ContainerName: "envoy-sidecar"},
+			},
+		},
+		{
+			name: "the first matching pattern wins",
+			policies: []autoscalingv1.ContainerResourcePolicy{
+				{ContainerName: "log-*", MaxAllowed: capped},
+				{ContainerName: "*-sidecar"},
+			},
+			podTemplate: podTemplate,
+			expected: []autoscalingv1.ContainerResourcePolicy{
+				{ContainerName: "log-sidecar", MaxAllowed: capped},
+				{ContainerName: "envoy-sidecar"},
+			},
+		},
+		{
+			name:        "character class",
+			policies:    []autoscalingv1.ContainerResourcePolicy{{ContainerName: "worker-[0-9]"}},
+			podTemplate: podTemplate,
+			expected:    []autoscalingv1.ContainerResourcePolicy{{ContainerName: "worker-1"}},
+		},
+		{
+			name:        "init containers are matched",
+			policies:    []autoscalingv1.ContainerResourcePolicy{{ContainerName: "init-*", MaxAllowed: capped}},
+			podTemplate: podTemplate,
+			expected: []autoscalingv1.ContainerResourcePolicy{
+				{ContainerName: "init-db", MaxAllowed: capped},
+				{ContainerName: "init-cache", MaxAllowed: capped},
+			},
+		},
+		{
+			name:        "pattern matching no container is dropped",
+			policies:    []autoscalingv1.ContainerResourcePolicy{{ContainerName: "app"}, {ContainerName: "*-proxy"}},
+			podTemplate: podTemplate,
+			expected:    []autoscalingv1.ContainerResourcePolicy{{ContainerName: "app"}},
+		},
+		{
+			name:     "no pod template",
+			policies: []autoscalingv1.ContainerResourcePolicy{{ContainerName: "app"}, {ContainerName: "*-sidecar"}},
+			expected: []autoscalingv1.ContainerResourcePolicy{{ContainerName: "app"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vpaManager := &autoscalingv1.VpaManager{
+				ObjectMeta: metav1.ObjectMeta{Name: "prod"},
+				Spec:       autoscalingv1.VpaManagerSpec{ResourcePolicy: &autoscalingv1.ResourcePolicy{ContainerPolicies: tt.policies}},
+			}
+			original := vpaManager.DeepCopy()
+
+			got := WithExpandedContainerPatterns(vpaManager, tt.podTemplate)
+			assert.Equal(t, tt.expected, got.Spec.ResourcePolicy.ContainerPolicies)
+			assert.Equal(t, original, vpaManager, "the VpaManager passed in is not modified")
+		})
+	}
+}
+
+func TestIsContainerPattern(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected bool
+		valid    bool
+	}{
+		{name: "app", valid: true},
+		{name: "*", valid: true},
+		{name: "*-sidecar", expected: true, valid: true},
+		{name: "worker-?", expected: true, valid: true},
+		{name: "worker-[0-9]", expected: true, valid: true},
+		{name: "worker-[0-9", expected: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, IsContainerPattern(tt.name))
+			assert.Equal(t, tt.valid, ValidContainerPattern(tt.name))
+		})
+	}
+}
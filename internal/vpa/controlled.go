@@ -0,0 +1,36 @@
+package vpa
+
+import (
+	autoscalingv1 "github.com/joaomo/k8s_op_vpa/api/v1"
+)
+
+// WithDefaultControlledResources returns a copy of policy in which every container policy
+// listing no controlledResources controls defaults. Containers without a policy of their
+// own get a "*" policy controlling defaults when policy has none. policy is returned
+// unchanged when defaults is empty.
+func WithDefaultControlledResources(policy *autoscalingv1.ResourcePolicy, defaults []string) *autoscalingv1.ResourcePolicy {
+	if len(defaults) == 0 {
+		return policy
+	}
+	out := policy.DeepCopy()
+	if out == nil {
+		out = &autoscalingv1.ResourcePolicy{}
+	}
+	wildcard := false
+	for i := range out.ContainerPolicies {
+		cp := &out.ContainerPolicies[i]
+		if cp.ContainerName == WildcardContainer {
+			wildcard = true
+		}
+		if len(cp.ControlledResources) == 0 {
+			cp.ControlledResources = append([]string(nil), defaults...)
+		}
+	}
+	if !wildcard {
+		out.ContainerPolicies = append(out.ContainerPolicies, autoscalingv1.ContainerResourcePolicy{
+			ContainerName:       WildcardContainer,
+			ControlledResources: append([]string(nil), defaults...),
+		})
+	}
+	return out
+}
@@ -0,0 +1,66 @@
+package vpa
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	autoscalingv1 "github.com/joaomo/k8s_op_vpa/api/v1"
+)
+
+func TestWithDefaultControlledResources(t *testing.T) {
+	cpuOnly := []string{"cpu"}
+
+	tests := []struct {
+		name     string
+		policy   *autoscalingv1.ResourcePolicy
+		defaults []string
+		expected *autoscalingv1.ResourcePolicy
+	}{
+		{
+			name:   "no defaults",
+			policy: &autoscalingv1.ResourcePolicy{ContainerPolicies: []autoscalingv1.ContainerResourcePolicy{{ContainerName: "app"}}},
+			expected: &autoscalingv1.ResourcePolicy{ContainerPolicies: []autoscalingv1.ContainerResourcePolicy{
+				{ContainerName: "app"},
+			}},
+		},
+		{
+			name:     "no resource policy",
+			defaults: cpuOnly,
+			expected: &autoscalingv1.ResourcePolicy{ContainerPolicies: []autoscalingv1.ContainerResourcePolicy{
+				{ContainerName: "*", ControlledResources: cpuOnly},
+			}},
+		},
+		{
+			name: "named policy without controlled resources",
+			policy: &autoscalingv1.ResourcePolicy{ContainerPolicies: []autoscalingv1.ContainerResourcePolicy{
+				{ContainerName: "app", MaxAllowed: autoscalingv1.ResourceBounds{"cpu": "2"}},
+			}},
+			defaults: cpuOnly,
+			expected: &autoscalingv1.ResourcePolicy{ContainerPolicies: []autoscalingv1.ContainerResourcePolicy{
+				{ContainerName: "app", MaxAllowed: autoscalingv1.ResourceBounds{"cpu": "2"}, ControlledResources: cpuOnly},
+				{ContainerName: "*", ControlledResources: cpuOnly},
+			}},
+		},
+		{
+			name: "container policy keeps its own controlled resources",
+			policy: &autoscalingv1.ResourcePolicy{ContainerPolicies: []autoscalingv1.ContainerResourcePolicy{
+				{ContainerName: "cache", ControlledResources: []string{"cpu", "memory"}},
+				{ContainerName: "*"},
+			}},
+			defaults: cpuOnly,
+			expected: &autoscalingv1.ResourcePolicy{ContainerPolicies: []autoscalingv1.ContainerResourcePolicy{
+				{ContainerName: "cache", ControlledResources: []string{"cpu", "memory"}},
+				{ContainerName: "*", ControlledResources: cpuOnly},
+			}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			original := tt.policy.DeepCopy()
+			assert.Equal(t, tt.expected, WithDefaultControlledResources(tt.policy, tt.defaults))
+			assert.Equal(t, original, tt.policy, "the policy passed in is not modified")
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package vpa
+
+import (
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	autoscalingv1 "github.com/joaomo/k8s_op_vpa/api/v1"
+)
+
+const (
+	// ScaledToZeroSinceAnnotation records, in RFC3339, when the operator first saw the
+	// target of a VPA at zero replicas
+	ScaledToZeroSinceAnnotation = "vpa-operator.io/scaled-to-zero-since"
+	// DormantAnnotation marks a VPA switched to updateMode Off because its target stayed
+	// at zero replicas. The value is the update mode restored when the target scales up.
+	DormantAnnotation = "vpa-operator.io/dormant"
+)
+
+// ApplyDormancy tracks how long the target of a VPA has been scaled to zero and, once that
+// exceeds the policy, switches spec to updateMode Off. A target that scaled up, or a nil
+// policy, clears the tracking so spec keeps the VpaManager's update mode. spec is modified
+// in place; it returns whether the annotations of obj changed.
+func ApplyDormancy(obj metav1.Object, spec map[string]interface{}, scaledToZero bool, policy *autoscalingv1.DormancyPolicy, now time.Time) bool {
+	annotations := obj.GetAnnotations()
+	if policy == nil || policy.After.Duration <= 0 || !scaledToZero {
+		_, tracked := annotations[ScaledToZeroSinceAnnotation]
+		_, dormant := annotations[DormantAnnotation]
+		if !tracked && !dormant {
+			return false
+		}
+		delete(annotations, ScaledToZeroSinceAnnotation)
+		delete(annotations, DormantAnnotation)
+		obj.SetAnnotations(annotations)
+		return true
+	}
+
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	changed := false
+	since, err := time.Parse(time.RFC3339, annotations[ScaledToZeroSinceAnnotation])
+	if err != nil {
+		since = now
+		annotations[ScaledToZeroSinceAnnotation] = now.UTC().Format(time.RFC3339)
+		changed = true
+	}
+
+	if now.Sub(since) >= policy.After.Duration {
+		updatePolicy := map[string]interface{}{}
+		if existing, ok := spec["updatePolicy"].(map[string]interface{}); ok {
+			for key, value := range existing {
+				updatePolicy[key] = value
+			}
+		}
+		restored, _ := updatePolicy["updateMode"].(string)
+		updatePolicy["updateMode"] = "Off"
+		spec["updatePolicy"] = updatePolicy
+		if annotations[DormantAnnotation] != restored {
+			annotations[DormantAnnotation] = restored
+			changed = true
+		}
+	}
+	obj.SetAnnotations(annotations)
+	return changed
+}
@@ -0,0 +1,110 @@
+package vpa
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	autoscalingv1 "github.com/joaomo/k8s_op_vpa/api/v1"
+)
+
+func TestApplyDormancy(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	policy := &autoscalingv1.DormancyPolicy{After: metav1.Duration{Duration: time.Hour}}
+	since := func(d time.Duration) string { return now.Add(-d).Format(time.RFC3339) }
+
+	tests := []struct {
+		name                string
+		annotations         map[string]string
+		scaledToZero        bool
+		policy              *autoscalingv1.DormancyPolicy
+		expectedAnnotations map[string]string
+		expectedMode        string
+		expectedChanged     bool
+	}{
+		{
+			name:         "running workload",
+			policy:       policy,
+			expectedMode: "Auto",
+		},
+		{
+			name:                "first seen at zero replicas",
+			scaledToZero:        true,
+			policy:              policy,
+			expectedAnnotations: map[string]string{ScaledToZeroSinceAnnotation: since(0)},
+			expectedMode:        "Auto",
+			expectedChanged:     true,
+		},
+		{
+			name:                "at zero replicas for less than the threshold",
+			annotations:         map[string]string{ScaledToZeroSinceAnnotation: since(30 * time.Minute)},
+			scaledToZero:        true,
+			policy:              policy,
+			expectedAnnotations: map[string]string{ScaledToZeroSinceAnnotation: since(30 * time.Minute)},
+			expectedMode:        "Auto",
+		},
+		{
+			name:         "turns dormant past the threshold",
+			annotations:  map[string]string{ScaledToZeroSinceAnnotation: since(2 * time.Hour)},
+			scaledToZero: true,
+			policy:       policy,
+			expectedAnnotations: map[string]string{
+				ScaledToZeroSinceAnnotation: since(2 * time.Hour),
+				DormantAnnotation:           "Auto",
+			},
+			expectedMode:    "Off",
+			expectedChanged: true,
+		},
+		{
+			name: "stays dormant",
+			annotations: map[string]string{
+				ScaledToZeroSinceAnnotation: since(2 * time.Hour),
+				DormantAnnotation:           "Auto",
+			},
+			scaledToZero: true,
+			policy:       policy,
+			expectedAnnotations: map[string]string{
+				ScaledToZeroSinceAnnotation: since(2 * time.Hour),
+				DormantAnnotation:           "Auto",
+			},
+			expectedMode: "Off",
+		},
+		{
+			name: "scaled up",
+			annotations: map[string]string{
+				ScaledToZeroSinceAnnotation: since(2 * time.Hour),
+				DormantAnnotation:           "Auto",
+			},
+			policy:              policy,
+			expectedAnnotations: map[string]string{},
+			expectedMode:        "Auto",
+			expectedChanged:     true,
+		},
+		{
+			name: "policy removed",
+			annotations: map[string]string{
+				ScaledToZeroSinceAnnotation: since(2 * time.Hour),
+				DormantAnnotation:           "Auto",
+			},
+			scaledToZero:        true,
+			expectedAnnotations: map[string]string{},
+			expectedMode:        "Auto",
+			expectedChanged:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			obj := &metav1.ObjectMeta{Annotations: tt.annotations}
+			spec := map[string]interface{}{"updatePolicy": map[string]interface{}{"updateMode": "Auto"}}
+
+			changed := ApplyDormancy(obj, spec, tt.scaledToZero, tt.policy, now)
+
+			assert.Equal(t, tt.expectedChanged, changed)
+			assert.Equal(t, tt.expectedAnnotations, obj.Annotations)
+			assert.Equal(t, tt.expectedMode, spec["updatePolicy"].(map[string]interface{})["updateMode"])
+		})
+	}
+}
@@ -0,0 +1,19 @@
+package vpa
+
+import (
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+)
+
+// IsAdmissionRejection reports whether a VPA write was rejected by the API server's
+// validation or by an admission webhook such as the VPA admission controller
+func IsAdmissionRejection(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.IsInvalid(err) {
+		return true
+	}
+	return (errors.IsForbidden(err) || errors.IsBadRequest(err)) && strings.Contains(err.Error(), "admission webhook")
+}
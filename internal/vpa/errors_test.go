@@ -0,0 +1,36 @@
+package vpa
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+func TestIsAdmissionRejection(t *testing.T) {
+	gr := schema.GroupResource{Group: "autoscaling.k8s.io", Resource: "verticalpodautoscalers"}
+
+	tests := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{"nil", nil, false},
+		{"invalid", errors.NewInvalid(schema.GroupKind{Group: gr.Group, Kind: "VerticalPodAutoscaler"}, "web-vpa",
+			field.ErrorList{field.Invalid(field.NewPath("spec", "updatePolicy", "updateMode"), "Sometimes", "unsupported")}), true},
+		{"webhook denial", errors.NewForbidden(gr, "web-vpa",
+			fmt.Errorf(`admission webhook "vpa.k8s.io" denied the request: maxAllowed must be greater than minAllowed`)), true},
+		{"rbac forbidden", errors.NewForbidden(gr, "web-vpa", fmt.Errorf("user cannot create resource")), false},
+		{"conflict", errors.NewConflict(gr, "web-vpa", fmt.Errorf("object was modified")), false},
+		{"plain error", fmt.Errorf("connection refused"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, IsAdmissionRejection(tt.err))
+		})
+	}
+}
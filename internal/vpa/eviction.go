@@ -0,0 +1,51 @@
+package vpa
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// EvictionBlockedAnnotation marks a VPA switched to updateMode Off because pods of its target
+// evicted by the VPA updater could not be rescheduled. The value is the topology key of the
+// pod anti-affinity term that allows no more pods.
+const EvictionBlockedAnnotation = "vpa-operator.io/eviction-blocked"
+
+// evictingUpdateModes are the update modes in which the VPA updater evicts pods
+var evictingUpdateModes = map[string]bool{
+	"Auto":     true,
+	"Recreate": true,
+}
+
+// ApplyEvictionGuard switches spec to updateMode Off while eviction of the target's pods is
+// blocked on topologyKey, if spec has an update mode that evicts pods. Otherwise spec keeps
+// its update mode and the mark is cleared. spec is modified in place; it returns whether the
+// annotations of obj changed.
+func ApplyEvictionGuard(obj metav1.Object, spec map[string]interface{}, topologyKey string, blocked bool) bool {
+	annotations := obj.GetAnnotations()
+	if !blocked || !evictingUpdateModes[UpdateMode(spec)] {
+		if _, marked := annotations[EvictionBlockedAnnotation]; !marked {
+			return false
+		}
+		delete(annotations, EvictionBlockedAnnotation)
+		obj.SetAnnotations(annotations)
+		return true
+	}
+
+	updatePolicy := map[string]interface{}{}
+	if existing, ok := spec["updatePolicy"].(map[string]interface{}); ok {
+		for key, value := range existing {
+			updatePolicy[key] = value
+		}
+	}
+	updatePolicy["updateMode"] = "Off"
+	spec["updatePolicy"] = updatePolicy
+
+	if value, marked := annotations[EvictionBlockedAnnotation]; marked && value == topologyKey {
+		return false
+	}
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[EvictionBlockedAnnotation] = topologyKey
+	obj.SetAnnotations(annotations)
+	return true
+}
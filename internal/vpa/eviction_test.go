@@ -0,0 +1,71 @@
+package vpa
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestApplyEvictionGuard(t *testing.T) {
+	const hostname = "kubernetes.io/hostname"
+
+	tests := []struct {
+		name                string
+		annotations         map[string]string
+		mode                string
+		blocked             bool
+		expectedAnnotations map[string]string
+		expectedMode        string
+		expectedChanged     bool
+	}{
+		{
+			name:         "eviction possible",
+			mode:         "Auto",
+			expectedMode: "Auto",
+		},
+		{
+			name:                "blocked in Auto",
+			mode:                "Auto",
+			blocked:             true,
+			expectedAnnotations: map[string]string{EvictionBlockedAnnotation: hostname},
+			expectedMode:        "Off",
+			expectedChanged:     true,
+		},
+		{
+			name:                "stays blocked",
+			annotations:         map[string]string{EvictionBlockedAnnotation: hostname},
+			mode:                "Recreate",
+			blocked:             true,
+			expectedAnnotations: map[string]string{EvictionBlockedAnnotation: hostname},
+			expectedMode:        "Off",
+		},
+		{
+			name:         "blocked in a mode that does not evict",
+			mode:         "Initial",
+			blocked:      true,
+			expectedMode: "Initial",
+		},
+		{
+			name:                "unblocked",
+			annotations:         map[string]string{EvictionBlockedAnnotation: hostname},
+			mode:                "Auto",
+			expectedAnnotations: map[string]string{},
+			expectedMode:        "Auto",
+			expectedChanged:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			obj := &metav1.ObjectMeta{Annotations: tt.annotations}
+			spec := map[string]interface{}{"updatePolicy": map[string]interface{}{"updateMode": tt.mode}}
+
+			changed := ApplyEvictionGuard(obj, spec, hostname, tt.blocked)
+
+			assert.Equal(t, tt.expectedChanged, changed)
+			assert.Equal(t, tt.expectedAnnotations, obj.Annotations)
+			assert.Equal(t, tt.expectedMode, UpdateMode(spec))
+		})
+	}
+}
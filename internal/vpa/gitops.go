@@ -0,0 +1,39 @@
+package vpa
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// GitOps controllers that may own a VPA
+const (
+	GitOpsArgoCD = "argocd"
+	GitOpsFlux   = "flux"
+)
+
+// gitOpsAnnotations maps ownership annotations to the GitOps controller that sets them
+var gitOpsAnnotations = map[string]string{
+	"argocd.argoproj.io/tracking-id": GitOpsArgoCD,
+}
+
+// gitOpsLabels maps ownership labels to the GitOps controller that sets them
+var gitOpsLabels = map[string]string{
+	"argocd.argoproj.io/instance":      GitOpsArgoCD,
+	"kustomize.toolkit.fluxcd.io/name": GitOpsFlux,
+	"helm.toolkit.fluxcd.io/name":      GitOpsFlux,
+}
+
+// GitOpsOwner returns the GitOps controller managing the object, or an empty
+// string if the object carries no known Argo CD or Flux ownership metadata
+func GitOpsOwner(obj metav1.Object) string {
+	for key, owner := range gitOpsAnnotations {
+		if _, ok := obj.GetAnnotations()[key]; ok {
+			return owner
+		}
+	}
+	for key, owner := range gitOpsLabels {
+		if _, ok := obj.GetLabels()[key]; ok {
+			return owner
+		}
+	}
+	return ""
+}
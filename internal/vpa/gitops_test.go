@@ -0,0 +1,54 @@
+package vpa
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestGitOpsOwner(t *testing.T) {
+	tests := []struct {
+		name        string
+		labels      map[string]string
+		annotations map[string]string
+		expected    string
+	}{
+		{
+			name:     "no metadata",
+			expected: "",
+		},
+		{
+			name:        "argocd tracking annotation",
+			annotations: map[string]string{"argocd.argoproj.io/tracking-id": "app:autoscaling.k8s.io/VerticalPodAutoscaler:ns/web-vpa"},
+			expected:    GitOpsArgoCD,
+		},
+		{
+			name:     "argocd instance label",
+			labels:   map[string]string{"argocd.argoproj.io/instance": "app"},
+			expected: GitOpsArgoCD,
+		},
+		{
+			name:     "flux kustomization label",
+			labels:   map[string]string{"kustomize.toolkit.fluxcd.io/name": "apps"},
+			expected: GitOpsFlux,
+		},
+		{
+			name:     "flux helm release label",
+			labels:   map[string]string{"helm.toolkit.fluxcd.io/name": "web"},
+			expected: GitOpsFlux,
+		},
+		{
+			name:     "unrelated labels",
+			labels:   map[string]string{"app.kubernetes.io/managed-by": "vpa-operator"},
+			expected: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			obj := &metav1.ObjectMeta{Labels: tt.labels, Annotations: tt.annotations}
+			assert.Equal(t, tt.expected, GitOpsOwner(obj))
+		})
+	}
+}
@@ -0,0 +1,32 @@
+package vpa
+
+import (
+	corev1 "k8s.io/api/core/v1"
+
+	autoscalingv1 "github.com/joaomo/k8s_op_vpa/api/v1"
+)
+
+// WithInitContainersIgnored returns policy with a mode Off policy for every init container
+// of podTemplate without a policy of its own when policy sets ignoreInitContainers, and the
+// names of those init containers. policy is returned unchanged otherwise.
+func WithInitContainersIgnored(policy *autoscalingv1.ResourcePolicy, podTemplate *corev1.PodTemplateSpec) (*autoscalingv1.ResourcePolicy, []string) {
+	if policy == nil || !policy.IgnoreInitContainers || podTemplate == nil {
+		return policy, nil
+	}
+	names := make([]string, 0, len(podTemplate.Spec.InitContainers))
+	for _, c := range podTemplate.Spec.InitContainers {
+		names = append(names, c.Name)
+	}
+	return withScalingOff(policy, names)
+}
+
+// allContainers returns the containers of podTemplate followed by its init containers,
+// which container policies name the same way
+func allContainers(podTemplate *corev1.PodTemplateSpec) []corev1.Container {
+	if podTemplate == nil {
+		return nil
+	}
+	out := make([]corev1.Container, 0, len(podTemplate.Spec.Containers)+len(podTemplate.Spec.InitContainers))
+	out = append(out, podTemplate.Spec.Containers...)
+	return append(out, podTemplate.Spec.InitContainers...)
+}
@@ -0,0 +1,78 @@
+package vpa
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+
+	autoscalingv1 "github.com/joaomo/k8s_op_vpa/api/v1"
+)
+
+func TestWithInitContainersIgnored(t *testing.T) {
+	podTemplate := &corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+		Containers:     []corev1.Container{{Name: "app"}},
+		InitContainers: []corev1.Container{{Name: "migrate"}, {Name: "warm-cache"}},
+	}}
+	off := func(name string) autoscalingv1.ContainerResourcePolicy {
+		return autoscalingv1.ContainerResourcePolicy{ContainerName: name, Mode: autoscalingv1.ContainerScalingModeOff}
+	}
+	wildcard := autoscalingv1.ContainerResourcePolicy{ContainerName: "*", MaxAllowed: autoscalingv1.ResourceBounds{"cpu": "2"}}
+	migrate := autoscalingv1.ContainerResourcePolicy{ContainerName: "migrate", MaxAllowed: autoscalingv1.ResourceBounds{"memory": "1Gi"}}
+
+	tests := []struct {
+		name            string
+		policy          *autoscalingv1.ResourcePolicy
+		podTemplate     *corev1.PodTemplateSpec
+		expected        *autoscalingv1.ResourcePolicy
+		expectedIgnored []string
+	}{
+		{
+			name:        "init containers are kept by default",
+			policy:      &autoscalingv1.ResourcePolicy{ContainerPolicies: []autoscalingv1.ContainerResourcePolicy{wildcard}},
+			podTemplate: podTemplate,
+			expected:    &autoscalingv1.ResourcePolicy{ContainerPolicies: []autoscalingv1.ContainerResourcePolicy{wildcard}},
+		},
+		{
+			name:        "init containers are turned off",
+			policy:      &autoscalingv1.ResourcePolicy{ContainerPolicies: []autoscalingv1.ContainerResourcePolicy{wildcard}, IgnoreInitContainers: true},
+			podTemplate: podTemplate,
+			expected: &autoscalingv1.ResourcePolicy{
+				ContainerPolicies:    []autoscalingv1.ContainerResourcePolicy{wildcard, off("migrate"), off("warm-cache")},
+				IgnoreInitContainers: true,
+			},
+			expectedIgnored: []string{"migrate", "warm-cache"},
+		},
+		{
+			name:        "init containers with a policy of their own are kept",
+			policy:      &autoscalingv1.ResourcePolicy{ContainerPolicies: []autoscalingv1.ContainerResourcePolicy{migrate}, IgnoreInitContainers: true},
+			podTemplate: podTemplate,
+			expected: &autoscalingv1.ResourcePolicy{
+				ContainerPolicies:    []autoscalingv1.ContainerResourcePolicy{migrate, off("warm-cache")},
+				IgnoreInitContainers: true,
+			},
+			expectedIgnored: []string{"warm-cache"},
+		},
+		{
+			name:        "no init containers",
+			policy:      &autoscalingv1.ResourcePolicy{IgnoreInitContainers: true},
+			podTemplate: &corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "app"}}}},
+			expected:    &autoscalingv1.ResourcePolicy{IgnoreInitContainers: true},
+		},
+		{
+			name:        "no resource policy",
+			podTemplate: podTemplate,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			original := tt.policy.DeepCopy()
+
+			got, ignored := WithInitContainersIgnored(tt.policy, tt.podTemplate)
+			assert.Equal(t, tt.expected, got)
+			assert.Equal(t, tt.expectedIgnored, ignored)
+			assert.Equal(t, original, tt.policy, "the policy passed in is not modified")
+		})
+	}
+}
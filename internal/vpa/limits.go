@@ -0,0 +1,61 @@
+package vpa
+
+import (
+	"math"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	autoscalingv1 "github.com/joaomo/k8s_op_vpa/api/v1"
+)
+
+// applyLimitBounds converts limit bounds into request bounds of cp through the limit to
+// request ratio of container, which the VPA keeps when it scales requests. The tighter of
+// a converted bound and an existing one wins. Resources the container has no request and
+// limit for are left unbounded, since the VPA sets no limit for them.
+func applyLimitBounds(cp *autoscalingv1.ContainerResourcePolicy, limits *autoscalingv1.LimitBounds, container *corev1.Container) {
+	if limits == nil || container == nil {
+		return
+	}
+	for name, value := range limits.MinAllowed {
+		if bound, ok := requestBound(container, name, value, math.Ceil); ok {
+			cp.MinAllowed = tighterBound(cp.MinAllowed, name, bound, 1)
+		}
+	}
+	for name, value := range limits.MaxAllowed {
+		if bound, ok := requestBound(container, name, value, math.Floor); ok {
+			cp.MaxAllowed = tighterBound(cp.MaxAllowed, name, bound, -1)
+		}
+	}
+}
+
+// requestBound returns the request at which the VPA would set the limit of resource name
+// to value, rounded with round to whole millicores for CPU and whole units otherwise
+func requestBound(container *corev1.Container, name, value string, round func(float64) float64) (resource.Quantity, bool) {
+	request, hasRequest := container.Resources.Requests[corev1.ResourceName(name)]
+	limit, hasLimit := container.Resources.Limits[corev1.ResourceName(name)]
+	bound, err := resource.ParseQuantity(value)
+	if !hasRequest || !hasLimit || request.Sign() <= 0 || limit.Sign() <= 0 || err != nil {
+		return resource.Quantity{}, false
+	}
+	ratio := request.AsApproximateFloat64() / limit.AsApproximateFloat64()
+	if corev1.ResourceName(name) == corev1.ResourceCPU {
+		return *resource.NewMilliQuantity(int64(round(float64(bound.MilliValue())*ratio)), bound.Format), true
+	}
+	return *resource.NewQuantity(int64(round(bound.AsApproximateFloat64()*ratio)), bound.Format), true
+}
+
+// tighterBound sets bounds[name] to bound unless the existing bound compares to it as want
+// (1 for larger, -1 for smaller) already
+func tighterBound(bounds autoscalingv1.ResourceBounds, name string, bound resource.Quantity, want int) autoscalingv1.ResourceBounds {
+	if existing, ok := bounds[name]; ok {
+		if q, err := resource.ParseQuantity(existing); err == nil && q.Cmp(bound) != -want {
+			return bounds
+		}
+	}
+	if bounds == nil {
+		bounds = autoscalingv1.ResourceBounds{}
+	}
+	bounds[name] = bound.String()
+	return bounds
+}
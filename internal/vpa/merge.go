@@ -0,0 +1,149 @@
+package vpa
+
+import (
+	"sort"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	autoscalingv1 "github.com/joaomo/k8s_op_vpa/api/v1"
+)
+
+// ManagedContainerPoliciesAnnotation lists, comma-separated, the container names whose
+// policies the operator wrote to a VPA. Other container policies are hand-tuned.
+const ManagedContainerPoliciesAnnotation = "vpa-operator.io/managed-container-policies"
+
+// ManagedContainerPolicies returns the container names whose policies the operator wrote to
+// an existing VPA. VPAs generated before the annotation existed are fully operator-written;
+// VPAs created by hand and adopted have no operator-written policies.
+func ManagedContainerPolicies(existing metav1.Object, existingSpec map[string]interface{}) map[string]bool {
+	managed := map[string]bool{}
+	if value, ok := existing.GetAnnotations()[ManagedContainerPoliciesAnnotation]; ok {
+		for _, name := range strings.Split(value, ",") {
+			if name != "" {
+				managed[name] = true
+			}
+		}
+		return managed
+	}
+	if _, generated := existing.GetLabels()[CreatedByLabel]; generated {
+		for _, policy := range containerPolicies(existingSpec) {
+			managed[containerName(policy)] = true
+		}
+	}
+	return managed
+}
+
+// MergeContainerPolicies returns the spec to write to an existing VPA: the desired spec with
+// the existing hand-tuned container policies combined according to strategy, plus the
+// container names whose policies the operator wrote. managed holds the names the operator
+// wrote before, see ManagedContainerPolicies.
+//   - OperatorWins (or empty) replaces all container policies with the generated ones.
+//   - ExistingWins keeps the existing container policies when any is hand-tuned.
+//   - MergeByContainerName keeps hand-tuned policies and adds generated ones for the other containers.
+func MergeContainerPolicies(strategy string, desired, existing map[string]interface{}, managed map[string]bool) (map[string]interface{}, []string) {
+	generated := containerPolicies(desired)
+	var handTuned []map[string]interface{}
+	for _, policy := range containerPolicies(existing) {
+		if !managed[containerName(policy)] {
+			handTuned = append(handTuned, policy)
+		}
+	}
+
+	if len(handTuned) == 0 || strategy == "" || strategy == autoscalingv1.ContainerPolicyMergeOperatorWins {
+		return desired, containerNames(generated)
+	}
+
+	var merged []map[string]interface{}
+	switch strategy {
+	case autoscalingv1.ContainerPolicyMergeExistingWins:
+		merged = containerPolicies(existing)
+		var kept []map[string]interface{}
+		for _, policy := range merged {
+			if managed[containerName(policy)] {
+				kept = append(kept, policy)
+			}
+		}
+		return withContainerPolicies(desired, merged), containerNames(kept)
+	case autoscalingv1.ContainerPolicyMergeByContainerName:
+		tuned := map[string]bool{}
+		for _, policy := range handTuned {
+			tuned[containerName(policy)] = true
+		}
+		var written []map[string]interface{}
+		for _, policy := range generated {
+			if !tuned[containerName(policy)] {
+				written = append(written, policy)
+			}
+		}
+		merged = append(append(merged, written...), handTuned...)
+		return withContainerPolicies(desired, merged), containerNames(written)
+	default:
+		return desired, containerNames(generated)
+	}
+}
+
+// SetManagedContainerPolicies records the container names whose policies the operator wrote
+func SetManagedContainerPolicies(target metav1.Object, names []string) {
+	annotations := target.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[ManagedContainerPoliciesAnnotation] = strings.Join(names, ",")
+	target.SetAnnotations(annotations)
+}
+
+// ContainerPolicyNames returns the sorted container names of the policies in a VPA spec
+func ContainerPolicyNames(spec map[string]interface{}) []string {
+	return containerNames(containerPolicies(spec))
+}
+
+// containerPolicies returns the container policies of a VPA spec
+func containerPolicies(spec map[string]interface{}) []map[string]interface{} {
+	resourcePolicy, _ := spec["resourcePolicy"].(map[string]interface{})
+	items, _ := resourcePolicy["containerPolicies"].([]interface{})
+	policies := make([]map[string]interface{}, 0, len(items))
+	for _, item := range items {
+		if policy, ok := item.(map[string]interface{}); ok {
+			policies = append(policies, policy)
+		}
+	}
+	return policies
+}
+
+// withContainerPolicies returns a copy of spec with its container policies replaced
+func withContainerPolicies(spec map[string]interface{}, policies []map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(spec)+1)
+	for key, value := range spec {
+		out[key] = value
+	}
+	resourcePolicy := map[string]interface{}{}
+	if existing, ok := spec["resourcePolicy"].(map[string]interface{}); ok {
+		for key, value := range existing {
+			resourcePolicy[key] = value
+		}
+	}
+	items := make([]interface{}, 0, len(policies))
+	for _, policy := range policies {
+		items = append(items, policy)
+	}
+	resourcePolicy["containerPolicies"] = items
+	out["resourcePolicy"] = resourcePolicy
+	return out
+}
+
+// containerName returns the container a policy applies to
+func containerName(policy map[string]interface{}) string {
+	name, _ := policy["containerName"].(string)
+	return name
+}
+
+// containerNames returns the sorted container names of policies
+func containerNames(policies []map[string]interface{}) []string {
+	names := make([]string, 0, len(policies))
+	for _, policy := range policies {
+		names = append(names, containerName(policy))
+	}
+	sort.Strings(names)
+	return names
+}
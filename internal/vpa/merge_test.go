@@ -0,0 +1,135 @@
+package vpa
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	autoscalingv1 "github.com/joaomo/k8s_op_vpa/api/v1"
+)
+
+func TestMergeContainerPolicies(t *testing.T) {
+	policy := func(name, maxMemory string) interface{} {
+		return map[string]interface{}{"containerName": name, "maxAllowed": map[string]interface{}{"memory": maxMemory}}
+	}
+	spec := func(policies ...interface{}) map[string]interface{} {
+		s := map[string]interface{}{"updatePolicy": map[string]interface{}{"updateMode": "Off"}}
+		if len(policies) > 0 {
+			s["resourcePolicy"] = map[string]interface{}{"containerPolicies": policies}
+		}
+		return s
+	}
+	desired := spec(policy("*", "4Gi"), policy("app", "2Gi"))
+	existing := spec(policy("*", "8Gi"), policy("app", "1Gi"), policy("sidecar", "128Mi"))
+
+	tests := []struct {
+		name            string
+		strategy        string
+		existing        map[string]interface{}
+		managed         map[string]bool
+		expected        map[string]interface{}
+		expectedWritten []string
+	}{
+		{
+			name:            "operator wins by default",
+			existing:        existing,
+			expected:        desired,
+			expectedWritten: []string{"*", "app"},
+		},
+		{
+			name:            "existing wins keeps hand-tuned policies",
+			strategy:        autoscalingv1.ContainerPolicyMergeExistingWins,
+			existing:        existing,
+			managed:         map[string]bool{"*": true},
+			expected:        spec(policy("*", "8Gi"), policy("app", "1Gi"), policy("sidecar", "128Mi")),
+			expectedWritten: []string{"*"},
+		},
+		{
+			name:            "existing wins without hand-tuned policies",
+			strategy:        autoscalingv1.ContainerPolicyMergeExistingWins,
+			existing:        existing,
+			managed:         map[string]bool{"*": true, "app": true, "sidecar": true},
+			expected:        desired,
+			expectedWritten: []string{"*", "app"},
+		},
+		{
+			name:            "merge keeps hand-tuned policies by container name",
+			strategy:        autoscalingv1.ContainerPolicyMergeByContainerName,
+			existing:        existing,
+			managed:         map[string]bool{"*": true},
+			expected:        spec(policy("*", "4Gi"), policy("app", "1Gi"), policy("sidecar", "128Mi")),
+			expectedWritten: []string{"*"},
+		},
+		{
+			name:            "merge drops policies the operator no longer generates",
+			strategy:        autoscalingv1.ContainerPolicyMergeByContainerName,
+			existing:        spec(policy("*", "8Gi"), policy("old", "1Gi"), policy("sidecar", "128Mi")),
+			managed:         map[string]bool{"*": true, "old": true},
+			expected:        spec(policy("*", "4Gi"), policy("app", "2Gi"), policy("sidecar", "128Mi")),
+			expectedWritten: []string{"*", "app"},
+		},
+		{
+			name:            "merge into a VPA without policies",
+			strategy:        autoscalingv1.ContainerPolicyMergeByContainerName,
+			existing:        spec(),
+			expected:        desired,
+			expectedWritten: []string{"*", "app"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, written := MergeContainerPolicies(tt.strategy, desired, tt.existing, tt.managed)
+			assert.Equal(t, tt.expected, got)
+			assert.Equal(t, tt.expectedWritten, written)
+		})
+	}
+	assert.Equal(t, spec(policy("*", "4Gi"), policy("app", "2Gi")), desired, "desired spec should not be modified")
+}
+
+func TestManagedContainerPolicies(t *testing.T) {
+	spec := map[string]interface{}{
+		"resourcePolicy": map[string]interface{}{
+			"containerPolicies": []interface{}{
+				map[string]interface{}{"containerName": "*"},
+				map[string]interface{}{"containerName": "app"},
+			},
+		},
+	}
+
+	tests := []struct {
+		name        string
+		labels      map[string]string
+		annotations map[string]string
+		expected    map[string]bool
+	}{
+		{
+			name:        "recorded policies",
+			labels:      map[string]string{CreatedByLabel: "vm"},
+			annotations: map[string]string{ManagedContainerPoliciesAnnotation: "*"},
+			expected:    map[string]bool{"*": true},
+		},
+		{
+			name:        "recorded without policies",
+			annotations: map[string]string{ManagedContainerPoliciesAnnotation: ""},
+			expected:    map[string]bool{},
+		},
+		{
+			name:     "generated before policies were recorded",
+			labels:   map[string]string{CreatedByLabel: "vm"},
+			expected: map[string]bool{"*": true, "app": true},
+		},
+		{
+			name:     "created by hand",
+			expected: map[string]bool{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			obj := &metav1.ObjectMeta{Labels: tt.labels, Annotations: tt.annotations}
+			assert.Equal(t, tt.expected, ManagedContainerPolicies(obj, spec))
+		})
+	}
+}
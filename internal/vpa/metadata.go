@@ -0,0 +1,113 @@
+package vpa
+
+import (
+	"sort"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Annotations listing the keys of the vpaLabels and vpaAnnotations last applied to a VPA,
+// so keys dropped from the VpaManager are removed again
+const (
+	CustomLabelsAnnotation      = "vpa-operator.io/custom-labels"
+	CustomAnnotationsAnnotation = "vpa-operator.io/custom-annotations"
+)
+
+// ReservedKeyPrefix is the prefix of the labels and annotations the operator sets itself
+const ReservedKeyPrefix = "vpa-operator.io/"
+
+// ReservedLabel reports whether key is a label the operator sets on its VPAs, which
+// vpaLabels never override
+func (o Ownership) ReservedLabel(key string) bool {
+	o = o.orDefault()
+	return key == o.Key || key == ManagedByLabel || key == CreatedByLabel || strings.HasPrefix(key, ReservedKeyPrefix)
+}
+
+// ApplyCustomMetadata merges the vpaLabels and vpaAnnotations of a VpaManager onto target,
+// removes the ones it applied before that are no longer listed, and reports whether
+// anything changed. Labels and annotations the operator sets itself are never touched.
+func ApplyCustomMetadata(target metav1.Object, labels, annotations map[string]string, ownership Ownership) bool {
+	current := target.GetAnnotations()
+	if current == nil {
+		current = map[string]string{}
+	}
+	previousLabels := splitKeys(current[CustomLabelsAnnotation])
+	previousAnnotations := splitKeys(current[CustomAnnotationsAnnotation])
+
+	desiredLabels := map[string]string{}
+	for key, value := range labels {
+		if !ownership.ReservedLabel(key) {
+			desiredLabels[key] = value
+		}
+	}
+	desiredAnnotations := map[string]string{}
+	for key, value := range annotations {
+		if !strings.HasPrefix(key, ReservedKeyPrefix) {
+			desiredAnnotations[key] = value
+		}
+	}
+
+	targetLabels := target.GetLabels()
+	if targetLabels == nil {
+		targetLabels = map[string]string{}
+	}
+	labelsChanged := mergeKeys(targetLabels, desiredLabels, previousLabels)
+	annotationsChanged := mergeKeys(current, desiredAnnotations, previousAnnotations)
+	annotationsChanged = setKeyList(current, CustomLabelsAnnotation, desiredLabels) || annotationsChanged
+	annotationsChanged = setKeyList(current, CustomAnnotationsAnnotation, desiredAnnotations) || annotationsChanged
+	if labelsChanged {
+		target.SetLabels(targetLabels)
+	}
+	if annotationsChanged {
+		target.SetAnnotations(current)
+	}
+	return labelsChanged || annotationsChanged
+}
+
+// mergeKeys sets desired in target and deletes the previous keys no longer desired
+func mergeKeys(target, desired map[string]string, previous []string) bool {
+	changed := false
+	for _, key := range previous {
+		if _, ok := desired[key]; ok {
+			continue
+		}
+		if _, ok := target[key]; ok {
+			delete(target, key)
+			changed = true
+		}
+	}
+	for key, value := range desired {
+		if current, ok := target[key]; !ok || current != value {
+			target[key] = value
+			changed = true
+		}
+	}
+	return changed
+}
+
+// setKeyList records the sorted keys of desired in the annotation key, removing it when
+// desired is empty
+func setKeyList(annotations map[string]string, key string, desired map[string]string) bool {
+	keys := make([]string, 0, len(desired))
+	for k := range desired {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	value := strings.Join(keys, ",")
+	current, ok := annotations[key]
+	if value == "" {
+		delete(annotations, key)
+		return ok
+	}
+	annotations[key] = value
+	return !ok || current != value
+}
+
+// splitKeys parses a comma-separated key list
+func splitKeys(value string) []string {
+	if value == "" {
+		return nil
+	}
+	return strings.Split(value, ",")
+}
@@ -0,0 +1,82 @@
+package vpa
+
+import (
+	"maps"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestApplyCustomMetadata(t *testing.T) {
+	ownership := Ownership{}
+	owned := ownership.Labels("prod")
+
+	tests := []struct {
+		name                string
+		existingLabels      map[string]string
+		existingAnnotations map[string]string
+		vpaLabels           map[string]string
+		vpaAnnotations      map[string]string
+		expectedLabels      map[string]string
+		expectedAnnotations map[string]string
+		expectedChanged     bool
+	}{
+		{
+			name:           "nothing to apply",
+			existingLabels: owned,
+			expectedLabels: owned,
+		},
+		{
+			name:           "applied to a new VPA",
+			existingLabels: owned,
+			vpaLabels:      map[string]string{"team": "payments"},
+			vpaAnnotations: map[string]string{"cost-center": "cc-42"},
+			expectedLabels: map[string]string{ManagedByLabel: DefaultManagedByValue, CreatedByLabel: "prod", "team": "payments"},
+			expectedAnnotations: map[string]string{
+				"cost-center":               "cc-42",
+				CustomLabelsAnnotation:      "team",
+				CustomAnnotationsAnnotation: "cost-center",
+			},
+			expectedChanged: true,
+		},
+		{
+			name:                "already applied",
+			existingLabels:      map[string]string{ManagedByLabel: DefaultManagedByValue, "team": "payments"},
+			existingAnnotations: map[string]string{CustomLabelsAnnotation: "team"},
+			vpaLabels:           map[string]string{"team": "payments"},
+			expectedLabels:      map[string]string{ManagedByLabel: DefaultManagedByValue, "team": "payments"},
+			expectedAnnotations: map[string]string{CustomLabelsAnnotation: "team"},
+		},
+		{
+			name:                "keys dropped from the VpaManager are removed",
+			existingLabels:      map[string]string{ManagedByLabel: DefaultManagedByValue, "team": "payments", "app": "web"},
+			existingAnnotations: map[string]string{"cost-center": "cc-42", "note": "by hand", CustomLabelsAnnotation: "team", CustomAnnotationsAnnotation: "cost-center"},
+			expectedLabels:      map[string]string{ManagedByLabel: DefaultManagedByValue, "app": "web"},
+			expectedAnnotations: map[string]string{"note": "by hand"},
+			expectedChanged:     true,
+		},
+		{
+			name:           "reserved keys are never overridden",
+			existingLabels: owned,
+			vpaLabels:      map[string]string{ManagedByLabel: "argocd", CreatedByLabel: "other", InstanceLabel: "blue"},
+			vpaAnnotations: map[string]string{SourceUIDAnnotation: "forged"},
+			expectedLabels: owned,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			obj := &metav1.ObjectMeta{Labels: maps.Clone(tt.existingLabels), Annotations: maps.Clone(tt.existingAnnotations)}
+
+			changed := ApplyCustomMetadata(obj, tt.vpaLabels, tt.vpaAnnotations, ownership)
+			assert.Equal(t, tt.expectedChanged, changed)
+			assert.Equal(t, tt.expectedLabels, obj.Labels)
+			if len(tt.expectedAnnotations) == 0 {
+				assert.Empty(t, obj.Annotations)
+			} else {
+				assert.Equal(t, tt.expectedAnnotations, obj.Annotations)
+			}
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package vpa
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+
+	utilvalidation "k8s.io/apimachinery/pkg/util/validation"
+)
+
+// NameTemplateData is what a vpaNameTemplate is evaluated against, e.g.
+// "{{ .Kind | lower }}-{{ .Name }}-vpa"
+type NameTemplateData struct {
+	// Kind is the workload kind, e.g. Deployment
+	Kind string
+
+	// Name is the workload name
+	Name string
+
+	// Namespace is the workload namespace
+	Namespace string
+}
+
+// nameFuncs are the functions available in VPA name templates
+var nameFuncs = template.FuncMap{
+	"lower": strings.ToLower,
+	"upper": strings.ToUpper,
+}
+
+// ParseNameTemplate parses a vpaNameTemplate
+func ParseNameTemplate(nameTemplate string) (*template.Template, error) {
+	return template.New("vpaName").Funcs(nameFuncs).Option("missingkey=error").Parse(nameTemplate)
+}
+
+// RenderName returns the name of the VPA of a workload: nameTemplate evaluated against
+// data, or "<name>-vpa" when nameTemplate is empty. It fails when the result is not a valid
+// object name.
+func RenderName(nameTemplate string, data NameTemplateData) (string, error) {
+	if nameTemplate == "" {
+		return data.Name + "-vpa", nil
+	}
+	tmpl, err := ParseNameTemplate(nameTemplate)
+	if err != nil {
+		return "", err
+	}
+	var out bytes.Buffer
+	if err := tmpl.Execute(&out, data); err != nil {
+		return "", err
+	}
+	name := strings.TrimSpace(out.String())
+	if msgs := utilvalidation.IsDNS1123Subdomain(name); len(msgs) > 0 {
+		return "", fmt.Errorf("rendered %q is not a valid VPA name: %s", name, strings.Join(msgs, "; "))
+	}
+	return name, nil
+}
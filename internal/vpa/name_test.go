@@ -0,0 +1,43 @@
+package vpa
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderName(t *testing.T) {
+	web := NameTemplateData{Kind: "Deployment", Name: "web", Namespace: "shop"}
+
+	tests := []struct {
+		name         string
+		nameTemplate string
+		data         NameTemplateData
+		expected     string
+		expectError  string
+	}{
+		{name: "default", data: web, expected: "web-vpa"},
+		{name: "kind prefix", nameTemplate: "{{ .Kind | lower }}-{{ .Name }}-vpa", data: web, expected: "deployment-web-vpa"},
+		{name: "namespace and name", nameTemplate: "{{ .Namespace }}.{{ .Name }}", data: web, expected: "shop.web"},
+		{name: "surrounding spaces are trimmed", nameTemplate: " {{ .Name }}-vpa\n", data: web, expected: "web-vpa"},
+		{name: "unknown field", nameTemplate: "{{ .Team }}-vpa", data: web, expectError: "Team"},
+		{name: "syntax error", nameTemplate: "{{ .Name ", data: web, expectError: "unclosed action"},
+		{name: "invalid name", nameTemplate: "{{ .Kind }}-{{ .Name }}", data: web, expectError: "not a valid VPA name"},
+		{name: "too long", nameTemplate: "{{ .Name }}-vpa", data: NameTemplateData{Name: strings.Repeat("a", 250)}, expectError: "not a valid VPA name"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := RenderName(tt.nameTemplate, tt.data)
+			if tt.expectError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, got)
+		})
+	}
+}
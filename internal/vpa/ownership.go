@@ -0,0 +1,95 @@
+package vpa
+
+import (
+	"fmt"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// Labels the operator sets on every VPA it generates
+const (
+	ManagedByLabel = "app.kubernetes.io/managed-by"
+	CreatedByLabel = "app.kubernetes.io/created-by"
+)
+
+// InstanceLabel holds the ID of the operator instance that generated a VPA; it is only
+// set by instances started with an instance ID
+const InstanceLabel = "vpa-operator.io/instance"
+
+// DefaultManagedByValue is the ManagedByLabel value of an operator with the default ownership
+const DefaultManagedByValue = "vpa-operator"
+
+// Ownership is the label that marks the VPAs of one operator instance. Instances with
+// different ownership labels or instance IDs never update or delete each other's VPAs.
+// The zero value is the default app.kubernetes.io/managed-by=vpa-operator without an instance ID.
+type Ownership struct {
+	Key   string
+	Value string
+
+	// Instance is the ID of the operator instance, stamped on VPAs as InstanceLabel
+	Instance string
+}
+
+// NewOwnership validates an ownership label and instance ID; an empty key or value falls
+// back to the default, an empty instance means no instance ID
+func NewOwnership(key, value, instance string) (Ownership, error) {
+	o := Ownership{Key: key, Value: value, Instance: instance}.orDefault()
+	if errs := validation.IsQualifiedName(o.Key); len(errs) > 0 {
+		return Ownership{}, fmt.Errorf("invalid ownership label key %q: %s", o.Key, strings.Join(errs, "; "))
+	}
+	if errs := validation.IsValidLabelValue(o.Value); len(errs) > 0 {
+		return Ownership{}, fmt.Errorf("invalid ownership label value %q: %s", o.Value, strings.Join(errs, "; "))
+	}
+	if o.Key == CreatedByLabel || o.Key == InstanceLabel {
+		return Ownership{}, fmt.Errorf("ownership label key must not be %s", o.Key)
+	}
+	if errs := validation.IsValidLabelValue(o.Instance); len(errs) > 0 {
+		return Ownership{}, fmt.Errorf("invalid instance ID %q: %s", o.Instance, strings.Join(errs, "; "))
+	}
+	return o, nil
+}
+
+// orDefault fills in the default key and value when they are empty
+func (o Ownership) orDefault() Ownership {
+	if o.Key == "" {
+		o.Key = ManagedByLabel
+	}
+	if o.Value == "" {
+		o.Value = DefaultManagedByValue
+	}
+	return o
+}
+
+// Selector returns the labels that select every VPA of this operator instance. Without an
+// instance ID it also selects VPAs of instances that have one; filter those out with Owns.
+func (o Ownership) Selector() map[string]string {
+	o = o.orDefault()
+	selector := map[string]string{o.Key: o.Value}
+	if o.Instance != "" {
+		selector[InstanceLabel] = o.Instance
+	}
+	return selector
+}
+
+// Labels returns the labels of a VPA generated for the named VpaManager
+func (o Ownership) Labels(vpaManagerName string) map[string]string {
+	labels := o.Selector()
+	labels[CreatedByLabel] = vpaManagerName
+	return labels
+}
+
+// Owns reports whether obj was generated by this operator instance
+func (o Ownership) Owns(obj metav1.Object) bool {
+	o = o.orDefault()
+	value, ok := obj.GetLabels()[o.Key]
+	return ok && value == o.Value && obj.GetLabels()[InstanceLabel] == o.Instance
+}
+
+// Foreign reports whether obj was generated by another operator instance, as opposed to
+// this instance or a VPA created by hand
+func (o Ownership) Foreign(obj metav1.Object) bool {
+	_, generated := obj.GetLabels()[CreatedByLabel]
+	return generated && !o.Owns(obj)
+}
@@ -0,0 +1,86 @@
+package vpa
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestNewOwnership(t *testing.T) {
+	tests := []struct {
+		name     string
+		key      string
+		value    string
+		instance string
+		expected Ownership
+		wantErr  bool
+	}{
+		{name: "defaults", expected: Ownership{Key: ManagedByLabel, Value: DefaultManagedByValue}},
+		{name: "custom value", value: "vpa-operator-staging", expected: Ownership{Key: ManagedByLabel, Value: "vpa-operator-staging"}},
+		{name: "custom key", key: "example.com/vpa-owner", value: "prod", expected: Ownership{Key: "example.com/vpa-owner", Value: "prod"}},
+		{name: "instance ID", instance: "prod", expected: Ownership{Key: ManagedByLabel, Value: DefaultManagedByValue, Instance: "prod"}},
+		{name: "invalid key", key: "not a key", wantErr: true},
+		{name: "invalid value", value: "no spaces allowed", wantErr: true},
+		{name: "created-by key", key: CreatedByLabel, wantErr: true},
+		{name: "instance key", key: InstanceLabel, wantErr: true},
+		{name: "invalid instance ID", instance: "prod/eu", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o, err := NewOwnership(tt.key, tt.value, tt.instance)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, o)
+		})
+	}
+}
+
+func TestOwnership_Owns(t *testing.T) {
+	staging := Ownership{Key: ManagedByLabel, Value: "vpa-operator-staging"}
+
+	tests := []struct {
+		name      string
+		ownership Ownership
+		labels    map[string]string
+		expected  bool
+	}{
+		{name: "zero value owns default label", labels: map[string]string{ManagedByLabel: DefaultManagedByValue}, expected: true},
+		{name: "no labels", expected: false},
+		{name: "other instance", ownership: staging, labels: map[string]string{ManagedByLabel: DefaultManagedByValue}, expected: false},
+		{name: "same instance", ownership: staging, labels: map[string]string{ManagedByLabel: "vpa-operator-staging"}, expected: true},
+		{name: "same instance ID", ownership: Ownership{Instance: "prod"}, labels: map[string]string{ManagedByLabel: DefaultManagedByValue, InstanceLabel: "prod"}, expected: true},
+		{name: "other instance ID", ownership: Ownership{Instance: "prod"}, labels: map[string]string{ManagedByLabel: DefaultManagedByValue, InstanceLabel: "staging"}, expected: false},
+		{name: "missing instance ID", ownership: Ownership{Instance: "prod"}, labels: map[string]string{ManagedByLabel: DefaultManagedByValue}, expected: false},
+		{name: "instance ID without one configured", labels: map[string]string{ManagedByLabel: DefaultManagedByValue, InstanceLabel: "prod"}, expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			obj := &metav1.ObjectMeta{Labels: tt.labels}
+			assert.Equal(t, tt.expected, tt.ownership.Owns(obj))
+		})
+	}
+}
+
+func TestOwnership_Foreign(t *testing.T) {
+	o := Ownership{Instance: "prod"}
+
+	assert.False(t, o.Foreign(&metav1.ObjectMeta{}), "hand-made VPA")
+	assert.False(t, o.Foreign(&metav1.ObjectMeta{Labels: o.Labels("web")}), "own VPA")
+	assert.True(t, o.Foreign(&metav1.ObjectMeta{Labels: Ownership{Instance: "staging"}.Labels("web")}), "VPA of another instance")
+}
+
+func TestOwnership_Labels(t *testing.T) {
+	o := Ownership{Key: "example.com/vpa-owner", Value: "prod"}
+
+	assert.Equal(t, map[string]string{"example.com/vpa-owner": "prod", CreatedByLabel: "web"}, o.Labels("web"))
+	assert.Equal(t, map[string]string{"example.com/vpa-owner": "prod"}, o.Selector())
+	assert.Equal(t, map[string]string{ManagedByLabel: DefaultManagedByValue}, Ownership{}.Selector())
+	assert.Equal(t, map[string]string{ManagedByLabel: DefaultManagedByValue, InstanceLabel: "prod"}, Ownership{Instance: "prod"}.Selector())
+}
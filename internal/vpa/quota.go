@@ -0,0 +1,104 @@
+package vpa
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	autoscalingv1 "github.com/joaomo/k8s_op_vpa/api/v1"
+)
+
+// quotaKeys are the ResourceQuota hard limits that bound a resource, tightest wins
+var quotaKeys = map[corev1.ResourceName][]corev1.ResourceName{
+	corev1.ResourceCPU:    {corev1.ResourceRequestsCPU, corev1.ResourceCPU, corev1.ResourceLimitsCPU},
+	corev1.ResourceMemory: {corev1.ResourceRequestsMemory, corev1.ResourceMemory, corev1.ResourceLimitsMemory},
+}
+
+// QuotaCaps returns the per-container maxAllowed derived from the hard limits of a
+// namespace's ResourceQuotas: the tightest CPU and memory limit times the configured
+// fraction. Resources without a fraction or without a quota are left out.
+func QuotaCaps(quotas []corev1.ResourceQuota, fraction *autoscalingv1.QuotaFraction) corev1.ResourceList {
+	if fraction == nil {
+		return nil
+	}
+	fractions := map[corev1.ResourceName]string{
+		corev1.ResourceCPU:    fraction.CPUFraction,
+		corev1.ResourceMemory: fraction.MemoryFraction,
+	}
+
+	caps := corev1.ResourceList{}
+	for name, value := range fractions {
+		share, err := strconv.ParseFloat(value, 64)
+		if err != nil || share <= 0 {
+			continue
+		}
+		var tightest *resource.Quantity
+		for i := range quotas {
+			for _, key := range quotaKeys[name] {
+				hard, ok := quotas[i].Spec.Hard[key]
+				if ok && (tightest == nil || hard.Cmp(*tightest) < 0) {
+					tightest = &hard
+				}
+			}
+		}
+		if tightest == nil {
+			continue
+		}
+		if name == corev1.ResourceCPU {
+			caps[name] = *resource.NewMilliQuantity(int64(math.Floor(float64(tightest.MilliValue())*share)), resource.DecimalSI)
+		} else {
+			caps[name] = *resource.NewQuantity(int64(math.Floor(float64(tightest.Value())*share)), resource.BinarySI)
+		}
+	}
+	if len(caps) == 0 {
+		return nil
+	}
+	return caps
+}
+
+// CapMaxAllowed returns vpaManager with every container policy's maxAllowed lowered to caps.
+// Containers without a policy are capped through a "*" policy, added when missing. A
+// minAllowed above its cap is an error, since the VPA admission controller would reject it.
+func CapMaxAllowed(vpaManager *autoscalingv1.VpaManager, caps corev1.ResourceList) (*autoscalingv1.VpaManager, error) {
+	if len(caps) == 0 {
+		return vpaManager, nil
+	}
+	out := vpaManager.DeepCopy()
+	if out.Spec.ResourcePolicy == nil {
+		out.Spec.ResourcePolicy = &autoscalingv1.ResourcePolicy{}
+	}
+	policies := out.Spec.ResourcePolicy.ContainerPolicies
+	hasWildcard := false
+	for _, cp := range policies {
+		hasWildcard = hasWildcard || cp.ContainerName == WildcardContainer
+	}
+	if !hasWildcard {
+		policies = append(policies, autoscalingv1.ContainerResourcePolicy{ContainerName: WildcardContainer})
+	}
+
+	for i := range policies {
+		cp := &policies[i]
+		for name, limit := range caps {
+			key := string(name)
+			if value, ok := cp.MinAllowed[key]; ok {
+				if lower, err := resource.ParseQuantity(value); err == nil && lower.Cmp(limit) > 0 {
+					return nil, fmt.Errorf("container %s: minAllowed[%s] %s exceeds the quota share %s", cp.ContainerName, key, value, limit.String())
+				}
+			}
+			if value, ok := cp.MaxAllowed[key]; ok {
+				if upper, err := resource.ParseQuantity(value); err == nil && upper.Cmp(limit) <= 0 {
+					continue
+				}
+			}
+			if cp.MaxAllowed == nil {
+				cp.MaxAllowed = autoscalingv1.ResourceBounds{}
+			}
+			cp.MaxAllowed[key] = limit.String()
+		}
+	}
+	out.Spec.ResourcePolicy.ContainerPolicies = policies
+	return out, nil
+}
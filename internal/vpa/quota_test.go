@@ -0,0 +1,125 @@
+package vpa
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	autoscalingv1 "github.com/joaomo/k8s_op_vpa/api/v1"
+)
+
+func TestQuotaCaps(t *testing.T) {
+	quota := func(hard corev1.ResourceList) corev1.ResourceQuota {
+		return corev1.ResourceQuota{Spec: corev1.ResourceQuotaSpec{Hard: hard}}
+	}
+
+	tests := []struct {
+		name     string
+		quotas   []corev1.ResourceQuota
+		fraction *autoscalingv1.QuotaFraction
+		expected map[string]string
+	}{
+		{
+			name:     "no fraction",
+			quotas:   []corev1.ResourceQuota{quota(corev1.ResourceList{corev1.ResourceRequestsCPU: resource.MustParse("4")})},
+			expected: nil,
+		},
+		{
+			name:     "no quota",
+			fraction: &autoscalingv1.QuotaFraction{CPUFraction: "0.5"},
+			expected: nil,
+		},
+		{
+			name: "share of requests",
+			quotas: []corev1.ResourceQuota{quota(corev1.ResourceList{
+				corev1.ResourceRequestsCPU:    resource.MustParse("4"),
+				corev1.ResourceRequestsMemory: resource.MustParse("8Gi"),
+			})},
+			fraction: &autoscalingv1.QuotaFraction{CPUFraction: "0.25", MemoryFraction: "0.25"},
+			expected: map[string]string{"cpu": "1", "memory": "2Gi"},
+		},
+		{
+			name: "tightest limit across quotas wins",
+			quotas: []corev1.ResourceQuota{
+				quota(corev1.ResourceList{corev1.ResourceLimitsCPU: resource.MustParse("3")}),
+				quota(corev1.ResourceList{corev1.ResourceRequestsCPU: resource.MustParse("2"), corev1.ResourcePods: resource.MustParse("10")}),
+			},
+			fraction: &autoscalingv1.QuotaFraction{CPUFraction: "0.5"},
+			expected: map[string]string{"cpu": "1"},
+		},
+		{
+			name:     "resource without a fraction is not capped",
+			quotas:   []corev1.ResourceQuota{quota(corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1"), corev1.ResourceMemory: resource.MustParse("1Gi")})},
+			fraction: &autoscalingv1.QuotaFraction{CPUFraction: "0.3"},
+			expected: map[string]string{"cpu": "300m"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			caps := QuotaCaps(tt.quotas, tt.fraction)
+			if tt.expected == nil {
+				assert.Nil(t, caps)
+				return
+			}
+			got := map[string]string{}
+			for name, q := range caps {
+				got[string(name)] = q.String()
+			}
+			assert.Equal(t, tt.expected, got)
+		})
+	}
+}
+
+func TestCapMaxAllowed(t *testing.T) {
+	caps := corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1")}
+
+	tests := []struct {
+		name        string
+		policy      *autoscalingv1.ResourcePolicy
+		expected    []autoscalingv1.ContainerResourcePolicy
+		expectError bool
+	}{
+		{
+			name:     "no policy adds a wildcard",
+			expected: []autoscalingv1.ContainerResourcePolicy{{ContainerName: "*", MaxAllowed: autoscalingv1.ResourceBounds{"cpu": "1"}}},
+		},
+		{
+			name: "looser maxAllowed is lowered and tighter kept",
+			policy: &autoscalingv1.ResourcePolicy{ContainerPolicies: []autoscalingv1.ContainerResourcePolicy{
+				{ContainerName: "*", MaxAllowed: autoscalingv1.ResourceBounds{"cpu": "2", "memory": "1Gi"}},
+				{ContainerName: "sidecar", MaxAllowed: autoscalingv1.ResourceBounds{"cpu": "200m"}},
+			}},
+			expected: []autoscalingv1.ContainerResourcePolicy{
+				{ContainerName: "*", MaxAllowed: autoscalingv1.ResourceBounds{"cpu": "1", "memory": "1Gi"}},
+				{ContainerName: "sidecar", MaxAllowed: autoscalingv1.ResourceBounds{"cpu": "200m"}},
+			},
+		},
+		{
+			name: "minAllowed above the cap",
+			policy: &autoscalingv1.ResourcePolicy{ContainerPolicies: []autoscalingv1.ContainerResourcePolicy{
+				{ContainerName: "main", MinAllowed: autoscalingv1.ResourceBounds{"cpu": "2"}},
+			}},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vpaManager := &autoscalingv1.VpaManager{Spec: autoscalingv1.VpaManagerSpec{ResourcePolicy: tt.policy}}
+			original := vpaManager.DeepCopy()
+
+			got, err := CapMaxAllowed(vpaManager, caps)
+			if tt.expectError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, got.Spec.ResourcePolicy.ContainerPolicies)
+			assert.Equal(t, original, vpaManager, "the input must not be modified")
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package vpa
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// Recommendation bounds of a VPA's container recommendations
+const (
+	RecommendationTarget     = "target"
+	RecommendationLowerBound = "lowerBound"
+	RecommendationUpperBound = "upperBound"
+)
+
+// Recommendations extracts one bound of the recommendation per container from a VPA's
+// status, nil when the VPA has no recommendation yet. Unparseable quantities are skipped.
+func Recommendations(obj *unstructured.Unstructured, bound string) map[string]corev1.ResourceList {
+	recommendations, found, err := unstructured.NestedSlice(obj.Object, "status", "recommendation", "containerRecommendations")
+	if err != nil || !found {
+		return nil
+	}
+
+	out := make(map[string]corev1.ResourceList, len(recommendations))
+	for _, r := range recommendations {
+		rec, ok := r.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		name, _, _ := unstructured.NestedString(rec, "containerName")
+		values, _, _ := unstructured.NestedMap(rec, bound)
+		resources := corev1.ResourceList{}
+		for k, v := range values {
+			str, ok := v.(string)
+			if !ok {
+				continue
+			}
+			q, err := resource.ParseQuantity(str)
+			if err != nil {
+				continue
+			}
+			resources[corev1.ResourceName(k)] = q
+		}
+		out[name] = resources
+	}
+	return out
+}
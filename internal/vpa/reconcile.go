@@ -0,0 +1,47 @@
+package vpa
+
+import (
+	"context"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Annotations stamped on every VPA write, so users debugging a stale VPA can tell when
+// the operator last wrote it and find that reconcile or admission request in the logs.
+// They fall under ReservedKeyPrefix, so vpaAnnotations cannot override them.
+const (
+	LastReconciledAtAnnotation = "vpa-operator.io/last-reconciled-at"
+	ReconcileIDAnnotation      = "vpa-operator.io/reconcile-id"
+)
+
+// reconcileIDKey is the context key of the reconcile ID
+type reconcileIDKey struct{}
+
+// WithReconcileID returns a context whose VPA writes are stamped with id
+func WithReconcileID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, reconcileIDKey{}, id)
+}
+
+// ReconcileIDFrom returns the reconcile ID of ctx, empty when none was set
+func ReconcileIDFrom(ctx context.Context) string {
+	id, _ := ctx.Value(reconcileIDKey{}).(string)
+	return id
+}
+
+// StampReconcile records on target that it is being written now by the reconcile or
+// admission request of ctx. Call it only for writes that happen anyway, since a stamp
+// alone never justifies an update.
+func StampReconcile(ctx context.Context, target metav1.Object, now time.Time) {
+	annotations := target.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[LastReconciledAtAnnotation] = now.UTC().Format(time.RFC3339)
+	if id := ReconcileIDFrom(ctx); id != "" {
+		annotations[ReconcileIDAnnotation] = id
+	} else {
+		delete(annotations, ReconcileIDAnnotation)
+	}
+	target.SetAnnotations(annotations)
+}
@@ -0,0 +1,66 @@
+package vpa
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestStampReconcile(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 30, 0, 0, time.FixedZone("CET", 3600))
+
+	tests := []struct {
+		name        string
+		ctx         context.Context
+		annotations map[string]string
+		expected    map[string]string
+	}{
+		{
+			name: "with reconcile ID",
+			ctx:  WithReconcileID(context.Background(), "abc"),
+			expected: map[string]string{
+				LastReconciledAtAnnotation: "2026-03-01T11:30:00Z",
+				ReconcileIDAnnotation:      "abc",
+			},
+		},
+		{
+			name:        "replaces the previous stamp",
+			ctx:         WithReconcileID(context.Background(), "def"),
+			annotations: map[string]string{"team": "payments", ReconcileIDAnnotation: "abc", LastReconciledAtAnnotation: "2026-01-01T00:00:00Z"},
+			expected: map[string]string{
+				"team":                     "payments",
+				LastReconciledAtAnnotation: "2026-03-01T11:30:00Z",
+				ReconcileIDAnnotation:      "def",
+			},
+		},
+		{
+			name:        "without reconcile ID drops a stale one",
+			ctx:         context.Background(),
+			annotations: map[string]string{ReconcileIDAnnotation: "abc"},
+			expected:    map[string]string{LastReconciledAtAnnotation: "2026-03-01T11:30:00Z"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			obj := &metav1.ObjectMeta{Annotations: tt.annotations}
+			StampReconcile(tt.ctx, obj, now)
+			assert.Equal(t, tt.expected, obj.Annotations)
+		})
+	}
+}
+
+func TestStampReconcile_NotOverriddenByVPAAnnotations(t *testing.T) {
+	target := &metav1.ObjectMeta{}
+	StampReconcile(WithReconcileID(context.Background(), "abc"), target, time.Date(2026, 3, 1, 12, 30, 0, 0, time.UTC))
+
+	ApplyCustomMetadata(target, nil, map[string]string{
+		LastReconciledAtAnnotation: "2020-01-01T00:00:00Z",
+		ReconcileIDAnnotation:      "forged",
+	}, Ownership{})
+	assert.Equal(t, "2026-03-01T12:30:00Z", target.Annotations[LastReconciledAtAnnotation])
+	assert.Equal(t, "abc", target.Annotations[ReconcileIDAnnotation])
+}
@@ -0,0 +1,62 @@
+package vpa
+
+import (
+	"slices"
+
+	corev1 "k8s.io/api/core/v1"
+
+	autoscalingv1 "github.com/joaomo/k8s_op_vpa/api/v1"
+)
+
+// WellKnownSidecars are the container names service mesh and secret injectors give their
+// sidecars. Their resources are set by the injector, so a VPA resizing them fights it.
+var WellKnownSidecars = []string{"envoy", "istio-proxy", "linkerd-proxy", "vault-agent"}
+
+// WithWellKnownSidecarsExcluded returns a copy of policy with a mode Off policy for every
+// well-known sidecar of podTemplate, including native sidecars declared as init containers,
+// and the names of those sidecars. Sidecars with a policy of their own are left alone.
+// policy is returned unchanged when there are none.
+func WithWellKnownSidecarsExcluded(policy *autoscalingv1.ResourcePolicy, podTemplate *corev1.PodTemplateSpec) (*autoscalingv1.ResourcePolicy, []string) {
+	var sidecars []string
+	for _, c := range allContainers(podTemplate) {
+		if slices.Contains(WellKnownSidecars, c.Name) {
+			sidecars = append(sidecars, c.Name)
+		}
+	}
+	return withScalingOff(policy, sidecars)
+}
+
+// withScalingOff returns a copy of policy with a mode Off policy for every container in
+// names without a policy of its own, and the names of those containers. policy is
+// returned unchanged when there are none.
+func withScalingOff(policy *autoscalingv1.ResourcePolicy, names []string) (*autoscalingv1.ResourcePolicy, []string) {
+	claimed := map[string]bool{}
+	if policy != nil {
+		for _, cp := range policy.ContainerPolicies {
+			claimed[cp.ContainerName] = true
+		}
+	}
+
+	var off []string
+	for _, name := range names {
+		if !claimed[name] {
+			claimed[name] = true
+			off = append(off, name)
+		}
+	}
+	if len(off) == 0 {
+		return policy, nil
+	}
+
+	out := policy.DeepCopy()
+	if out == nil {
+		out = &autoscalingv1.ResourcePolicy{}
+	}
+	for _, name := range off {
+		out.ContainerPolicies = append(out.ContainerPolicies, autoscalingv1.ContainerResourcePolicy{
+			ContainerName: name,
+			Mode:          autoscalingv1.ContainerScalingModeOff,
+		})
+	}
+	return out, off
+}
@@ -0,0 +1,81 @@
+package vpa
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+
+	autoscalingv1 "github.com/joaomo/k8s_op_vpa/api/v1"
+)
+
+func TestWithWellKnownSidecarsExcluded(t *testing.T) {
+	podTemplate := &corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: []corev1.Container{
+		{Name: "app"}, {Name: "istio-proxy"}, {Name: "vault-agent"},
+	}}}
+	off := func(name string) autoscalingv1.ContainerResourcePolicy {
+		return autoscalingv1.ContainerResourcePolicy{ContainerName: name, Mode: autoscalingv1.ContainerScalingModeOff}
+	}
+
+	tests := []struct {
+		name             string
+		policy           *autoscalingv1.ResourcePolicy
+		podTemplate      *corev1.PodTemplateSpec
+		expected         *autoscalingv1.ResourcePolicy
+		expectedExcluded []string
+	}{
+		{
+			name:             "sidecars are excluded without a policy",
+			podTemplate:      podTemplate,
+			expected:         &autoscalingv1.ResourcePolicy{ContainerPolicies: []autoscalingv1.ContainerResourcePolicy{off("istio-proxy"), off("vault-agent")}},
+			expectedExcluded: []string{"istio-proxy", "vault-agent"},
+		},
+		{
+			name:        "sidecars are excluded alongside the wildcard",
+			policy:      &autoscalingv1.ResourcePolicy{ContainerPolicies: []autoscalingv1.ContainerResourcePolicy{{ContainerName: "*", MaxAllowed: autoscalingv1.ResourceBounds{"cpu": "2"}}}},
+			podTemplate: podTemplate,
+			expected: &autoscalingv1.ResourcePolicy{ContainerPolicies: []autoscalingv1.ContainerResourcePolicy{
+				{ContainerName: "*", MaxAllowed: autoscalingv1.ResourceBounds{"cpu": "2"}}, off("istio-proxy"), off("vault-agent"),
+			}},
+			expectedExcluded: []string{"istio-proxy", "vault-agent"},
+		},
+		{
+			name:        "sidecars with a policy of their own are kept",
+			policy:      &autoscalingv1.ResourcePolicy{ContainerPolicies: []autoscalingv1.ContainerResourcePolicy{{ContainerName: "istio-proxy", MaxAllowed: autoscalingv1.ResourceBounds{"cpu": "1"}}}},
+			podTemplate: podTemplate,
+			expected: &autoscalingv1.ResourcePolicy{ContainerPolicies: []autoscalingv1.ContainerResourcePolicy{
+				{ContainerName: "istio-proxy", MaxAllowed: autoscalingv1.ResourceBounds{"cpu": "1"}}, off("vault-agent"),
+			}},
+			expectedExcluded: []string{"vault-agent"},
+		},
+		{
+			name:        "no sidecars",
+			policy:      &autoscalingv1.ResourcePolicy{ContainerPolicies: []autoscalingv1.ContainerResourcePolicy{{ContainerName: "app"}}},
+			podTemplate: &corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "app"}}}},
+			expected:    &autoscalingv1.ResourcePolicy{ContainerPolicies: []autoscalingv1.ContainerResourcePolicy{{ContainerName: "app"}}},
+		},
+		{
+			name: "native sidecars declared as init containers are excluded",
+			podTemplate: &corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+				Containers:     []corev1.Container{{Name: "app"}},
+				InitContainers: []corev1.Container{{Name: "linkerd-proxy"}},
+			}},
+			expected:         &autoscalingv1.ResourcePolicy{ContainerPolicies: []autoscalingv1.ContainerResourcePolicy{off("linkerd-proxy")}},
+			expectedExcluded: []string{"linkerd-proxy"},
+		},
+		{
+			name: "no pod template",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			original := tt.policy.DeepCopy()
+
+			got, excluded := WithWellKnownSidecarsExcluded(tt.policy, tt.podTemplate)
+			assert.Equal(t, tt.expected, got)
+			assert.Equal(t, tt.expectedExcluded, excluded)
+			assert.Equal(t, original, tt.policy, "the policy passed in is not modified")
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package vpa
+
+import (
+	autoscalingv1 "github.com/joaomo/k8s_op_vpa/api/v1"
+)
+
+// BuildSpec returns the VPA spec a VpaManager generates for the workload of the given kind
+// and name. The reconciler and the webhooks build their VPAs from it, so both write the same
+// spec.
+func BuildSpec(vpaManager *autoscalingv1.VpaManager, kind, name string) map[string]interface{} {
+	updatePolicy := map[string]interface{}{
+		"updateMode": vpaManager.Spec.UpdateMode,
+	}
+	if vpaManager.Spec.UpdatePolicy != nil && vpaManager.Spec.UpdatePolicy.MinReplicas != nil {
+		updatePolicy["minReplicas"] = int64(*vpaManager.Spec.UpdatePolicy.MinReplicas)
+	}
+	spec := map[string]interface{}{
+		"targetRef": map[string]interface{}{
+			"apiVersion": "apps/v1",
+			"kind":       kind,
+			"name":       name,
+		},
+		"updatePolicy": updatePolicy,
+	}
+
+	// Add resource policy if specified
+	if vpaManager.Spec.ResourcePolicy != nil && len(vpaManager.Spec.ResourcePolicy.ContainerPolicies) > 0 {
+		containerPolicies := make([]interface{}, 0, len(vpaManager.Spec.ResourcePolicy.ContainerPolicies))
+		for _, cp := range vpaManager.Spec.ResourcePolicy.ContainerPolicies {
+			policy := map[string]interface{}{
+				"containerName": cp.ContainerName,
+			}
+			if cp.MinAllowed != nil {
+				minAllowed := make(map[string]interface{})
+				for k, v := range cp.MinAllowed {
+					minAllowed[k] = v
+				}
+				policy["minAllowed"] = minAllowed
+			}
+			if cp.MaxAllowed != nil {
+				maxAllowed := make(map[string]interface{})
+				for k, v := range cp.MaxAllowed {
+					maxAllowed[k] = v
+				}
+				policy["maxAllowed"] = maxAllowed
+			}
+			if len(cp.ControlledResources) > 0 {
+				controlledResources := make([]interface{}, 0, len(cp.ControlledResources))
+				for _, name := range cp.ControlledResources {
+					controlledResources = append(controlledResources, name)
+				}
+				policy["controlledResources"] = controlledResources
+			}
+			if cp.ControlledValues != "" {
+				policy["controlledValues"] = cp.ControlledValues
+			}
+			if cp.Mode != "" {
+				policy["mode"] = cp.Mode
+			}
+			containerPolicies = append(containerPolicies, policy)
+		}
+		spec["resourcePolicy"] = map[string]interface{}{
+			"containerPolicies": containerPolicies,
+		}
+	}
+
+	// Route to specific recommenders if specified
+	if len(vpaManager.Spec.Recommenders) > 0 {
+		recommenders := make([]interface{}, 0, len(vpaManager.Spec.Recommenders))
+		for _, rec := range vpaManager.Spec.Recommenders {
+			recommenders = append(recommenders, map[string]interface{}{"name": rec.Name})
+		}
+		spec["recommenders"] = recommenders
+	}
+	return spec
+}
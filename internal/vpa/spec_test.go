@@ -0,0 +1,48 @@
+package vpa
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	autoscalingv1 "github.com/joaomo/k8s_op_vpa/api/v1"
+)
+
+func TestBuildSpec(t *testing.T) {
+	minReplicas := int32(2)
+	vpaManager := &autoscalingv1.VpaManager{
+		Spec: autoscalingv1.VpaManagerSpec{
+			UpdateMode:   "Auto",
+			UpdatePolicy: &autoscalingv1.UpdatePolicy{MinReplicas: &minReplicas},
+			ResourcePolicy: &autoscalingv1.ResourcePolicy{ContainerPolicies: []autoscalingv1.ContainerResourcePolicy{{
+				ContainerName:       "app",
+				MinAllowed:          autoscalingv1.ResourceBounds{"cpu": "100m"},
+				MaxAllowed:          autoscalingv1.ResourceBounds{"memory": "1Gi"},
+				ControlledResources: []string{"memory"},
+				ControlledValues:    autoscalingv1.ControlledValuesRequestsAndLimits,
+				Mode:                "Auto",
+			}}},
+			Recommenders: []autoscalingv1.RecommenderSelector{{Name: "frugal"}},
+		},
+	}
+
+	assert.Equal(t, map[string]interface{}{
+		"targetRef":    map[string]interface{}{"apiVersion": "apps/v1", "kind": "StatefulSet", "name": "db"},
+		"updatePolicy": map[string]interface{}{"updateMode": "Auto", "minReplicas": int64(2)},
+		"resourcePolicy": map[string]interface{}{"containerPolicies": []interface{}{map[string]interface{}{
+			"containerName":       "app",
+			"minAllowed":          map[string]interface{}{"cpu": "100m"},
+			"maxAllowed":          map[string]interface{}{"memory": "1Gi"},
+			"controlledResources": []interface{}{"memory"},
+			"controlledValues":    "RequestsAndLimits",
+			"mode":                "Auto",
+		}}},
+		"recommenders": []interface{}{map[string]interface{}{"name": "frugal"}},
+	}, BuildSpec(vpaManager, "StatefulSet", "db"))
+
+	minimal := &autoscalingv1.VpaManager{Spec: autoscalingv1.VpaManagerSpec{UpdateMode: "Off"}}
+	assert.Equal(t, map[string]interface{}{
+		"targetRef":    map[string]interface{}{"apiVersion": "apps/v1", "kind": "Deployment", "name": "web"},
+		"updatePolicy": map[string]interface{}{"updateMode": "Off"},
+	}, BuildSpec(minimal, "Deployment", "web"))
+}
@@ -0,0 +1,67 @@
+package vpa
+
+import (
+	"context"
+	"sort"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// StaleTarget reports whether a VPA was generated for an earlier object of the given kind
+// and name than the workload with uid, i.e. the workload was deleted and recreated under
+// the same name. The SourceUIDAnnotation is checked first, then the controller owner
+// reference of that kind and name. VPAs carrying neither are never stale.
+func StaleTarget(obj metav1.Object, kind, name string, uid types.UID) bool {
+	if uid == "" {
+		return false
+	}
+	if source, ok := obj.GetAnnotations()[SourceUIDAnnotation]; ok && source != "" {
+		return types.UID(source) != uid
+	}
+	for _, ref := range obj.GetOwnerReferences() {
+		if ref.Kind == kind && ref.Name == name && ref.Controller != nil && *ref.Controller {
+			return ref.UID != uid
+		}
+	}
+	return false
+}
+
+// ReplaceOwnerReferences replaces the owner references of obj that point at the same kind
+// and name as refs, whatever their UID, and keeps the others
+func ReplaceOwnerReferences(obj metav1.Object, refs []metav1.OwnerReference) {
+	replaced := map[string]bool{}
+	for _, ref := range refs {
+		replaced[ref.Kind+"/"+ref.Name] = true
+	}
+	var kept []metav1.OwnerReference
+	for _, ref := range obj.GetOwnerReferences() {
+		if !replaced[ref.Kind+"/"+ref.Name] {
+			kept = append(kept, ref)
+		}
+	}
+	obj.SetOwnerReferences(append(kept, refs...))
+}
+
+// TargetingVPAs returns the names, sorted, of the VPAs in namespace whose targetRef is the
+// workload of the given kind and name, whoever created them
+func TargetingVPAs(ctx context.Context, reader client.Reader, namespace, kind, name string) ([]string, error) {
+	vpaList := &unstructured.UnstructuredList{}
+	vpaList.SetGroupVersionKind(vpaListGVK)
+	if err := reader.List(ctx, vpaList, client.InNamespace(namespace)); err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, item := range vpaList.Items {
+		targetKind, _, _ := unstructured.NestedString(item.Object, "spec", "targetRef", "kind")
+		targetName, _, _ := unstructured.NestedString(item.Object, "spec", "targetRef", "name")
+		if targetKind == kind && targetName == name {
+			names = append(names, item.GetName())
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
@@ -0,0 +1,73 @@
+package vpa
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestStaleTarget(t *testing.T) {
+	controller := true
+	owner := func(kind, name string, uid types.UID) metav1.OwnerReference {
+		return metav1.OwnerReference{APIVersion: "apps/v1", Kind: kind, Name: name, UID: uid, Controller: &controller}
+	}
+
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		owners      []metav1.OwnerReference
+		uid         types.UID
+		expected    bool
+	}{
+		{name: "hand-made VPA", uid: "new", expected: false},
+		{
+			name:        "same source UID",
+			annotations: map[string]string{SourceUIDAnnotation: "new"},
+			owners:      []metav1.OwnerReference{owner("Deployment", "web", "new")},
+			uid:         "new",
+			expected:    false,
+		},
+		{
+			name:        "source UID of a deleted workload",
+			annotations: map[string]string{SourceUIDAnnotation: "old"},
+			uid:         "new",
+			expected:    true,
+		},
+		{
+			name:     "owner reference to a deleted workload",
+			owners:   []metav1.OwnerReference{owner("Deployment", "web", "old")},
+			uid:      "new",
+			expected: true,
+		},
+		{
+			name:     "owner reference of another workload",
+			owners:   []metav1.OwnerReference{owner("StatefulSet", "web", "old")},
+			uid:      "new",
+			expected: false,
+		},
+		{
+			name:        "workload UID unknown",
+			annotations: map[string]string{SourceUIDAnnotation: "old"},
+			expected:    false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			obj := &metav1.ObjectMeta{Annotations: tt.annotations, OwnerReferences: tt.owners}
+			assert.Equal(t, tt.expected, StaleTarget(obj, "Deployment", "web", tt.uid))
+		})
+	}
+}
+
+func TestReplaceOwnerReferences(t *testing.T) {
+	manager := metav1.OwnerReference{APIVersion: "operators.joaomo.io/v1", Kind: "VpaManager", Name: "default", UID: "vm"}
+	old := metav1.OwnerReference{APIVersion: "apps/v1", Kind: "Deployment", Name: "web", UID: "old"}
+	current := metav1.OwnerReference{APIVersion: "apps/v1", Kind: "Deployment", Name: "web", UID: "new"}
+
+	obj := &metav1.ObjectMeta{OwnerReferences: []metav1.OwnerReference{manager, old}}
+	ReplaceOwnerReferences(obj, []metav1.OwnerReference{current})
+	assert.Equal(t, []metav1.OwnerReference{manager, current}, obj.OwnerReferences)
+}
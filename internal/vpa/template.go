@@ -0,0 +1,315 @@
+package vpa
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"strings"
+	"text/template"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	autoscalingv1 "github.com/joaomo/k8s_op_vpa/api/v1"
+)
+
+// WildcardContainer is the container name of a policy that applies to every container
+const WildcardContainer = "*"
+
+// BoundTemplateData is what a templated minAllowed or maxAllowed value is evaluated
+// against, e.g. "{{ .Requests.memory | multiply 2 }}"
+type BoundTemplateData struct {
+	// ContainerName is the container the bound is rendered for
+	ContainerName string
+
+	// Requests are the container's resource requests by resource name
+	Requests map[string]string
+
+	// Limits are the container's resource limits by resource name
+	Limits map[string]string
+}
+
+// boundFuncs are the functions available in resource bound templates. Quantities are
+// passed last so they can be piped: {{ .Requests.cpu | multiply 1.5 }}
+var boundFuncs = template.FuncMap{
+	"multiply": func(factor interface{}, quantity string) (string, error) {
+		f, err := toFloat(factor)
+		if err != nil {
+			return "", err
+		}
+		return scaleQuantity(quantity, f)
+	},
+	"divide": func(divisor interface{}, quantity string) (string, error) {
+		d, err := toFloat(divisor)
+		if err != nil {
+			return "", err
+		}
+		if d == 0 {
+			return "", fmt.Errorf("division by zero")
+		}
+		return scaleQuantity(quantity, 1/d)
+	},
+	"max": func(a, b string) (string, error) {
+		return pickQuantity(a, b, 1)
+	},
+	"min": func(a, b string) (string, error) {
+		return pickQuantity(a, b, -1)
+	},
+}
+
+// IsBoundTemplate reports whether a resource bound is a template evaluated per workload
+func IsBoundTemplate(value string) bool {
+	return strings.Contains(value, "{{")
+}
+
+// ParseBoundTemplate parses a templated resource bound
+func ParseBoundTemplate(value string) (*template.Template, error) {
+	return template.New("bound").Funcs(boundFuncs).Option("missingkey=error").Parse(value)
+}
+
+// RenderBound evaluates a templated resource bound and checks the result is a valid,
+// non-negative quantity. Values that are not templates are returned unchanged.
+func RenderBound(value string, data BoundTemplateData) (string, error) {
+	if !IsBoundTemplate(value) {
+		return value, nil
+	}
+	tmpl, err := ParseBoundTemplate(value)
+	if err != nil {
+		return "", err
+	}
+	var out bytes.Buffer
+	if err := tmpl.Execute(&out, data); err != nil {
+		return "", err
+	}
+	rendered := strings.TrimSpace(out.String())
+	q, err := resource.ParseQuantity(rendered)
+	if err != nil {
+		return "", fmt.Errorf("rendered %q is not a resource quantity", rendered)
+	}
+	if q.Sign() < 0 {
+		return "", fmt.Errorf("rendered %q is negative", rendered)
+	}
+	return rendered, nil
+}
+
+// WithRenderedResourcePolicy returns vpaManager with its templated resource bounds
+// evaluated against the containers of podTemplate and its limit bounds converted into
+// request bounds. It is returned as-is when no bound is templated or set on limits.
+//
+// A templated policy for a named container or init container is rendered against that
+// container, and its templated bounds are left out when the container does not exist. A
+// templated "*" policy is expanded into one policy per container, not init container,
+// without a policy of its own; the "*" policy keeps only its literal bounds, for
+// containers added later.
+func WithRenderedResourcePolicy(vpaManager *autoscalingv1.VpaManager, podTemplate *corev1.PodTemplateSpec) (*autoscalingv1.VpaManager, error) {
+	policy := vpaManager.Spec.ResourcePolicy
+	if policy == nil || !needsRendering(policy.ContainerPolicies) {
+		return vpaManager, nil
+	}
+
+	containers := map[string]*corev1.Container{}
+	var containerNames []string
+	if podTemplate != nil {
+		for i := range podTemplate.Spec.Containers {
+			c := &podTemplate.Spec.Containers[i]
+			containers[c.Name] = c
+			containerNames = append(containerNames, c.Name)
+		}
+		for i := range podTemplate.Spec.InitContainers {
+			c := &podTemplate.Spec.InitContainers[i]
+			containers[c.Name] = c
+		}
+	}
+	explicit := map[string]bool{}
+	for _, cp := range policy.ContainerPolicies {
+		explicit[cp.ContainerName] = true
+	}
+
+	rendered := make([]autoscalingv1.ContainerResourcePolicy, 0, len(policy.ContainerPolicies))
+	for _, cp := range policy.ContainerPolicies {
+		if !needsRendering([]autoscalingv1.ContainerResourcePolicy{cp}) {
+			rendered = append(rendered, *cp.DeepCopy())
+			continue
+		}
+		if cp.ContainerName != WildcardContainer {
+			out, err := renderContainerPolicy(cp, cp.ContainerName, containers[cp.ContainerName])
+			if err != nil {
+				return nil, err
+			}
+			rendered = append(rendered, out)
+			continue
+		}
+		for _, name := range containerNames {
+			if explicit[name] {
+				continue
+			}
+			out, err := renderContainerPolicy(cp, name, containers[name])
+			if err != nil {
+				return nil, err
+			}
+			rendered = append(rendered, out)
+		}
+		rendered = append(rendered, literalBounds(cp))
+	}
+
+	out := vpaManager.DeepCopy()
+	out.Spec.ResourcePolicy.ContainerPolicies = rendered
+	return out, nil
+}
+
+// renderContainerPolicy renders the bounds of cp for one container. Templated bounds and
+// limit bounds are dropped when the container does not exist.
+func renderContainerPolicy(cp autoscalingv1.ContainerResourcePolicy, name string, container *corev1.Container) (autoscalingv1.ContainerResourcePolicy, error) {
+	if container == nil {
+		out := literalBounds(cp)
+		out.ContainerName = name
+		return out, nil
+	}
+
+	data := BoundTemplateData{
+		ContainerName: name,
+		Requests:      quantityStrings(container.Resources.Requests),
+		Limits:        quantityStrings(container.Resources.Limits),
+	}
+	out := autoscalingv1.ContainerResourcePolicy{
+		ContainerName:       name,
+		ControlledResources: cp.ControlledResources,
+		ControlledValues:    cp.ControlledValues,
+		Mode:                cp.Mode,
+	}
+	var err error
+	if out.MinAllowed, err = renderBounds(cp.MinAllowed, data, "minAllowed"); err != nil {
+		return out, err
+	}
+	if out.MaxAllowed, err = renderBounds(cp.MaxAllowed, data, "maxAllowed"); err != nil {
+		return out, err
+	}
+	applyLimitBounds(&out, cp.Limits, container)
+	for resourceName, minValue := range out.MinAllowed {
+		maxValue, ok := out.MaxAllowed[resourceName]
+		if !ok {
+			continue
+		}
+		minQ, minErr := resource.ParseQuantity(minValue)
+		maxQ, maxErr := resource.ParseQuantity(maxValue)
+		if minErr == nil && maxErr == nil && minQ.Cmp(maxQ) > 0 {
+			return out, fmt.Errorf("container %s: rendered minAllowed %s (%s) is greater than maxAllowed (%s)",
+				name, resourceName, minValue, maxValue)
+		}
+	}
+	return out, nil
+}
+
+// renderBounds renders every bound in values for one container
+func renderBounds(values autoscalingv1.ResourceBounds, data BoundTemplateData, field string) (autoscalingv1.ResourceBounds, error) {
+	if values == nil {
+		return nil, nil
+	}
+	out := make(autoscalingv1.ResourceBounds, len(values))
+	for name, value := range values {
+		rendered, err := RenderBound(value, data)
+		if err != nil {
+			return nil, fmt.Errorf("container %s: %s[%s]: %w", data.ContainerName, field, name, err)
+		}
+		out[name] = rendered
+	}
+	return out, nil
+}
+
+// literalBounds returns cp without its templated bounds and limit bounds
+func literalBounds(cp autoscalingv1.ContainerResourcePolicy) autoscalingv1.ContainerResourcePolicy {
+	out := autoscalingv1.ContainerResourcePolicy{
+		ContainerName:       cp.ContainerName,
+		ControlledResources: cp.ControlledResources,
+		ControlledValues:    cp.ControlledValues,
+		Mode:                cp.Mode,
+	}
+	out.MinAllowed = filterLiteral(cp.MinAllowed)
+	out.MaxAllowed = filterLiteral(cp.MaxAllowed)
+	return out
+}
+
+// filterLiteral returns the bounds that are not templates, nil when there are none
+func filterLiteral(values autoscalingv1.ResourceBounds) autoscalingv1.ResourceBounds {
+	var out autoscalingv1.ResourceBounds
+	for name, value := range values {
+		if IsBoundTemplate(value) {
+			continue
+		}
+		if out == nil {
+			out = autoscalingv1.ResourceBounds{}
+		}
+		out[name] = value
+	}
+	return out
+}
+
+// needsRendering reports whether any policy has a templated bound or limit bounds
+func needsRendering(policies []autoscalingv1.ContainerResourcePolicy) bool {
+	for _, cp := range policies {
+		if cp.Limits != nil {
+			return true
+		}
+		for _, values := range []autoscalingv1.ResourceBounds{cp.MinAllowed, cp.MaxAllowed} {
+			for _, value := range values {
+				if IsBoundTemplate(value) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// quantityStrings converts a resource list into template data
+func quantityStrings(list corev1.ResourceList) map[string]string {
+	out := make(map[string]string, len(list))
+	for name, q := range list {
+		out[string(name)] = q.String()
+	}
+	return out
+}
+
+// scaleQuantity multiplies a quantity by factor, keeping its format
+func scaleQuantity(quantity string, factor float64) (string, error) {
+	q, err := resource.ParseQuantity(quantity)
+	if err != nil {
+		return "", fmt.Errorf("%q is not a resource quantity", quantity)
+	}
+	scaled := math.Round(float64(q.MilliValue()) * factor)
+	// float64(math.MaxInt64) rounds up to 2^63, which int64 cannot hold
+	if scaled >= math.MaxInt64 || scaled < math.MinInt64 {
+		return "", fmt.Errorf("%s scaled by %v overflows", quantity, factor)
+	}
+	return resource.NewMilliQuantity(int64(scaled), q.Format).String(), nil
+}
+
+// pickQuantity returns a if it compares to b as want (1 for larger, -1 for smaller), else b
+func pickQuantity(a, b string, want int) (string, error) {
+	qa, err := resource.ParseQuantity(a)
+	if err != nil {
+		return "", fmt.Errorf("%q is not a resource quantity", a)
+	}
+	qb, err := resource.ParseQuantity(b)
+	if err != nil {
+		return "", fmt.Errorf("%q is not a resource quantity", b)
+	}
+	if qa.Cmp(qb) == want {
+		return a, nil
+	}
+	return b, nil
+}
+
+// toFloat converts a template number argument
+func toFloat(v interface{}) (float64, error) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), nil
+	case int64:
+		return float64(n), nil
+	case float64:
+		return n, nil
+	default:
+		return 0, fmt.Errorf("%v is not a number", v)
+	}
+}
@@ -0,0 +1,336 @@
+package vpa
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	autoscalingv1 "github.com/joaomo/k8s_op_vpa/api/v1"
+)
+
+func TestRenderBound(t *testing.T) {
+	data := BoundTemplateData{
+		ContainerName: "app",
+		Requests:      map[string]string{"cpu": "250m", "memory": "256Mi"},
+		Limits:        map[string]string{"memory": "1Gi"},
+	}
+
+	tests := []struct {
+		name     string
+		value    string
+		expected string
+		wantErr  bool
+	}{
+		{name: "literal", value: "100m", expected: "100m"},
+		{name: "multiply memory", value: "{{ .Requests.memory | multiply 2 }}", expected: "512Mi"},
+		{name: "multiply cpu by fraction", value: "{{ .Requests.cpu | multiply 1.5 }}", expected: "375m"},
+		{name: "divide", value: "{{ .Limits.memory | divide 4 }}", expected: "256Mi"},
+		{name: "max", value: `{{ max "300m" .Requests.cpu }}`, expected: "300m"},
+		{name: "min", value: `{{ min "300m" .Requests.cpu }}`, expected: "250m"},
+		{name: "missing request", value: "{{ .Requests.ephemeral }}", wantErr: true},
+		{name: "not a quantity", value: "{{ .ContainerName }}", wantErr: true},
+		{name: "divide by zero", value: "{{ .Requests.cpu | divide 0 }}", wantErr: true},
+		{name: "non-numeric factor", value: `{{ .Requests.cpu | multiply "two" }}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rendered, err := RenderBound(tt.value, data)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, rendered)
+		})
+	}
+}
+
+func TestScaleQuantity_Overflow(t *testing.T) {
+	// 2^62 millis still fit into an int64
+	scaled, err := scaleQuantity("1m", 1<<62)
+	require.NoError(t, err)
+	assert.Equal(t, "4611686018427387904m", scaled)
+
+	// Exactly 2^63 millis, one past the largest int64, would wrap around to the smallest
+	_, err = scaleQuantity("1m", 1<<63)
+	assert.ErrorContains(t, err, "overflows")
+}
+
+func TestWithRenderedResourcePolicy(t *testing.T) {
+	podTemplate := &corev1.PodTemplateSpec{
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{
+					Name: "app",
+					Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{
+						corev1.ResourceMemory: resource.MustParse("256Mi"),
+					}},
+				},
+				{
+					Name: "sidecar",
+					Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{
+						corev1.ResourceMemory: resource.MustParse("64Mi"),
+					}},
+				},
+			},
+			InitContainers: []corev1.Container{
+				{
+					Name: "migrate",
+					Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{
+						corev1.ResourceMemory: resource.MustParse("128Mi"),
+					}},
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		policies []autoscalingv1.ContainerResourcePolicy
+		expected []autoscalingv1.ContainerResourcePolicy
+		wantErr  bool
+	}{
+		{
+			name: "literal policies are unchanged",
+			policies: []autoscalingv1.ContainerResourcePolicy{
+				{ContainerName: "*", MaxAllowed: autoscalingv1.ResourceBounds{"memory": "1Gi"}},
+			},
+			expected: []autoscalingv1.ContainerResourcePolicy{
+				{ContainerName: "*", MaxAllowed: autoscalingv1.ResourceBounds{"memory": "1Gi"}},
+			},
+		},
+		{
+			name: "named container",
+			policies: []autoscalingv1.ContainerResourcePolicy{
+				{ContainerName: "app", MaxAllowed: autoscalingv1.ResourceBounds{"memory": "{{ .Requests.memory | multiply 2 }}", "cpu": "2"}},
+			},
+			expected: []autoscalingv1.ContainerResourcePolicy{
+				{ContainerName: "app", MaxAllowed: autoscalingv1.ResourceBounds{"memory": "512Mi", "cpu": "2"}},
+			},
+		},
+		{
+			name: "named init container",
+			policies: []autoscalingv1.ContainerResourcePolicy{
+				{ContainerName: "migrate", MaxAllowed: autoscalingv1.ResourceBounds{"memory": "{{ .Requests.memory | multiply 2 }}"}},
+			},
+			expected: []autoscalingv1.ContainerResourcePolicy{
+				{ContainerName: "migrate", MaxAllowed: autoscalingv1.ResourceBounds{"memory": "256Mi"}},
+			},
+		},
+		{
+			name: "wildcard expands per container without its own policy, not per init container",
+			policies: []autoscalingv1.ContainerResourcePolicy{
+				{ContainerName: "sidecar", MaxAllowed: autoscalingv1.ResourceBounds{"memory": "128Mi"}},
+				{
+					ContainerName: "*",
+					MinAllowed:    autoscalingv1.ResourceBounds{"cpu": "10m"},
+					MaxAllowed:    autoscalingv1.ResourceBounds{"memory": "{{ .Requests.memory | multiply 4 }}"},
+				},
+			},
+			expected: []autoscalingv1.ContainerResourcePolicy{
+				{ContainerName: "sidecar", MaxAllowed: autoscalingv1.ResourceBounds{"memory": "128Mi"}},
+				{
+					ContainerName: "app",
+					MinAllowed:    autoscalingv1.ResourceBounds{"cpu": "10m"},
+					MaxAllowed:    autoscalingv1.ResourceBounds{"memory": "1Gi"},
+				},
+				{ContainerName: "*", MinAllowed: autoscalingv1.ResourceBounds{"cpu": "10m"}},
+			},
+		},
+		{
+			name: "missing container keeps literal bounds",
+			policies: []autoscalingv1.ContainerResourcePolicy{
+				{ContainerName: "worker", MinAllowed: autoscalingv1.ResourceBounds{"cpu": "10m", "memory": "{{ .Requests.memory }}"}},
+			},
+			expected: []autoscalingv1.ContainerResourcePolicy{
+				{ContainerName: "worker", MinAllowed: autoscalingv1.ResourceBounds{"cpu": "10m"}},
+			},
+		},
+		{
+			name: "controlled resources and values are kept per container",
+			policies: []autoscalingv1.ContainerResourcePolicy{
+				{ContainerName: "app", MaxAllowed: autoscalingv1.ResourceBounds{"memory": "{{ .Requests.memory | multiply 2 }}"}, ControlledResources: []string{"memory"}},
+				{ContainerName: "worker", MinAllowed: autoscalingv1.ResourceBounds{"memory": "{{ .Requests.memory }}"}, ControlledValues: "RequestsOnly"},
+			},
+			expected: []autoscalingv1.ContainerResourcePolicy{
+				{ContainerName: "app", MaxAllowed: autoscalingv1.ResourceBounds{"memory": "512Mi"}, ControlledResources: []string{"memory"}},
+				{ContainerName: "worker", ControlledValues: "RequestsOnly"},
+			},
+		},
+		{
+			name: "rendered min above max",
+			policies: []autoscalingv1.ContainerResourcePolicy{
+				{
+					ContainerName: "app",
+					MinAllowed:    autoscalingv1.ResourceBounds{"memory": "{{ .Requests.memory | multiply 8 }}"},
+					MaxAllowed:    autoscalingv1.ResourceBounds{"memory": "1Gi"},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "missing request",
+			policies: []autoscalingv1.ContainerResourcePolicy{
+				{ContainerName: "app", MaxAllowed: autoscalingv1.ResourceBounds{"cpu": "{{ .Requests.cpu | multiply 2 }}"}},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vpaManager := &autoscalingv1.VpaManager{
+				ObjectMeta: metav1.ObjectMeta{Name: "test"},
+				Spec: autoscalingv1.VpaManagerSpec{
+					ResourcePolicy: &autoscalingv1.ResourcePolicy{ContainerPolicies: tt.policies},
+				},
+			}
+			original := vpaManager.DeepCopy()
+
+			rendered, err := WithRenderedResourcePolicy(vpaManager, podTemplate)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, rendered.Spec.ResourcePolicy.ContainerPolicies)
+			assert.Equal(t, original, vpaManager, "input must not be modified")
+		})
+	}
+}
+
+func TestWithRenderedResourcePolicy_LimitBounds(t *testing.T) {
+	podTemplate := &corev1.PodTemplateSpec{
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{
+					Name: "app",
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse("250m"),
+							corev1.ResourceMemory: resource.MustParse("256Mi"),
+						},
+						Limits: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse("1"),
+							corev1.ResourceMemory: resource.MustParse("512Mi"),
+						},
+					},
+				},
+				{
+					Name: "sidecar",
+					Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{
+						corev1.ResourceMemory: resource.MustParse("64Mi"),
+					}},
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		policies []autoscalingv1.ContainerResourcePolicy
+		expected []autoscalingv1.ContainerResourcePolicy
+		wantErr  bool
+	}{
+		{
+			name: "limit bounds convert through the ratio",
+			policies: []autoscalingv1.ContainerResourcePolicy{
+				{ContainerName: "app", Limits: &autoscalingv1.LimitBounds{
+					MinAllowed: autoscalingv1.ResourceBounds{"cpu": "200m"},
+					MaxAllowed: autoscalingv1.ResourceBounds{"cpu": "2", "memory": "2Gi"},
+				}},
+			},
+			expected: []autoscalingv1.ContainerResourcePolicy{
+				{
+					ContainerName: "app",
+					MinAllowed:    autoscalingv1.ResourceBounds{"cpu": "50m"},
+					MaxAllowed:    autoscalingv1.ResourceBounds{"cpu": "500m", "memory": "1Gi"},
+				},
+			},
+		},
+		{
+			name: "tighter request bound wins",
+			policies: []autoscalingv1.ContainerResourcePolicy{
+				{
+					ContainerName: "app",
+					MinAllowed:    autoscalingv1.ResourceBounds{"cpu": "100m"},
+					MaxAllowed:    autoscalingv1.ResourceBounds{"memory": "512Mi"},
+					Limits: &autoscalingv1.LimitBounds{
+						MinAllowed: autoscalingv1.ResourceBounds{"cpu": "200m"},
+						MaxAllowed: autoscalingv1.ResourceBounds{"memory": "2Gi"},
+					},
+				},
+			},
+			expected: []autoscalingv1.ContainerResourcePolicy{
+				{
+					ContainerName: "app",
+					MinAllowed:    autoscalingv1.ResourceBounds{"cpu": "100m"},
+					MaxAllowed:    autoscalingv1.ResourceBounds{"memory": "512Mi"},
+				},
+			},
+		},
+		{
+			name: "resources without a limit are left unbounded",
+			policies: []autoscalingv1.ContainerResourcePolicy{
+				{ContainerName: "*", Limits: &autoscalingv1.LimitBounds{
+					MaxAllowed: autoscalingv1.ResourceBounds{"memory": "1Gi"},
+				}},
+			},
+			expected: []autoscalingv1.ContainerResourcePolicy{
+				{ContainerName: "app", MaxAllowed: autoscalingv1.ResourceBounds{"memory": "512Mi"}},
+				{ContainerName: "sidecar"},
+				{ContainerName: "*"},
+			},
+		},
+		{
+			name: "missing container drops limit bounds",
+			policies: []autoscalingv1.ContainerResourcePolicy{
+				{
+					ContainerName: "worker",
+					MaxAllowed:    autoscalingv1.ResourceBounds{"cpu": "1"},
+					Limits:        &autoscalingv1.LimitBounds{MaxAllowed: autoscalingv1.ResourceBounds{"cpu": "2"}},
+				},
+			},
+			expected: []autoscalingv1.ContainerResourcePolicy{
+				{ContainerName: "worker", MaxAllowed: autoscalingv1.ResourceBounds{"cpu": "1"}},
+			},
+		},
+		{
+			name: "converted min above max",
+			policies: []autoscalingv1.ContainerResourcePolicy{
+				{
+					ContainerName: "app",
+					MaxAllowed:    autoscalingv1.ResourceBounds{"memory": "256Mi"},
+					Limits:        &autoscalingv1.LimitBounds{MinAllowed: autoscalingv1.ResourceBounds{"memory": "1Gi"}},
+				},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vpaManager := &autoscalingv1.VpaManager{
+				ObjectMeta: metav1.ObjectMeta{Name: "test"},
+				Spec: autoscalingv1.VpaManagerSpec{
+					ResourcePolicy: &autoscalingv1.ResourcePolicy{ContainerPolicies: tt.policies},
+				},
+			}
+			original := vpaManager.DeepCopy()
+
+			rendered, err := WithRenderedResourcePolicy(vpaManager, podTemplate)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, rendered.Spec.ResourcePolicy.ContainerPolicies)
+			assert.Equal(t, original, vpaManager, "input must not be modified")
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package vpa
+
+import (
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	autoscalingv1 "github.com/joaomo/k8s_op_vpa/api/v1"
+)
+
+// TuningAnnotationPrefix prefixes every recommendation tuning annotation on a VPA
+const TuningAnnotationPrefix = "recommender.vpa-operator.io/"
+
+// Annotations recommender variants read for the typed recommendation tuning fields
+const (
+	TargetCPUPercentileAnnotation    = TuningAnnotationPrefix + "target-cpu-percentile"
+	TargetMemoryPercentileAnnotation = TuningAnnotationPrefix + "target-memory-percentile"
+	SafetyMarginFractionAnnotation   = TuningAnnotationPrefix + "safety-margin-fraction"
+)
+
+// TuningAnnotations returns the annotations that pass tuning to the recommender.
+// Typed fields take precedence over parameters of the same name.
+func TuningAnnotations(tuning *autoscalingv1.RecommendationTuning) map[string]string {
+	annotations := map[string]string{}
+	if tuning == nil {
+		return annotations
+	}
+	for name, value := range tuning.Parameters {
+		annotations[TuningAnnotationPrefix+name] = value
+	}
+	for key, value := range map[string]string{
+		TargetCPUPercentileAnnotation:    tuning.TargetCPUPercentile,
+		TargetMemoryPercentileAnnotation: tuning.TargetMemoryPercentile,
+		SafetyMarginFractionAnnotation:   tuning.SafetyMarginFraction,
+	} {
+		if value != "" {
+			annotations[key] = value
+		}
+	}
+	return annotations
+}
+
+// ApplyTuningAnnotations sets the tuning annotations on target, removes tuning annotations
+// that are no longer configured, and reports whether anything changed
+func ApplyTuningAnnotations(target metav1.Object, tuning *autoscalingv1.RecommendationTuning) bool {
+	desired := TuningAnnotations(tuning)
+	annotations := target.GetAnnotations()
+	changed := false
+	for key := range annotations {
+		if _, ok := desired[key]; !ok && strings.HasPrefix(key, TuningAnnotationPrefix) {
+			delete(annotations, key)
+			changed = true
+		}
+	}
+	if len(desired) > 0 && annotations == nil {
+		annotations = make(map[string]string, len(desired))
+	}
+	for key, value := range desired {
+		if current, ok := annotations[key]; !ok || current != value {
+			annotations[key] = value
+			changed = true
+		}
+	}
+	if changed {
+		target.SetAnnotations(annotations)
+	}
+	return changed
+}
@@ -0,0 +1,102 @@
+package vpa
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	autoscalingv1 "github.com/joaomo/k8s_op_vpa/api/v1"
+)
+
+func TestTuningAnnotations(t *testing.T) {
+	tests := []struct {
+		name     string
+		tuning   *autoscalingv1.RecommendationTuning
+		expected map[string]string
+	}{
+		{name: "nil tuning", expected: map[string]string{}},
+		{
+			name: "typed fields",
+			tuning: &autoscalingv1.RecommendationTuning{
+				TargetCPUPercentile:  "0.9",
+				SafetyMarginFraction: "0.15",
+			},
+			expected: map[string]string{
+				TargetCPUPercentileAnnotation:  "0.9",
+				SafetyMarginFractionAnnotation: "0.15",
+			},
+		},
+		{
+			name: "typed fields override parameters",
+			tuning: &autoscalingv1.RecommendationTuning{
+				TargetMemoryPercentile: "0.95",
+				Parameters: map[string]string{
+					"target-memory-percentile": "0.5",
+					"history-length":           "8d",
+				},
+			},
+			expected: map[string]string{
+				TargetMemoryPercentileAnnotation:          "0.95",
+				TuningAnnotationPrefix + "history-length": "8d",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, TuningAnnotations(tt.tuning))
+		})
+	}
+}
+
+func TestApplyTuningAnnotations(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		tuning      *autoscalingv1.RecommendationTuning
+		expected    map[string]string
+		changed     bool
+	}{
+		{
+			name:     "adds to empty annotations",
+			tuning:   &autoscalingv1.RecommendationTuning{TargetCPUPercentile: "0.9"},
+			expected: map[string]string{TargetCPUPercentileAnnotation: "0.9"},
+			changed:  true,
+		},
+		{
+			name:        "removes tuning that is no longer configured",
+			annotations: map[string]string{TargetCPUPercentileAnnotation: "0.9", "team": "payments"},
+			expected:    map[string]string{"team": "payments"},
+			changed:     true,
+		},
+		{
+			name:        "overwrites stale values",
+			annotations: map[string]string{TargetCPUPercentileAnnotation: "0.9"},
+			tuning:      &autoscalingv1.RecommendationTuning{TargetCPUPercentile: "0.8"},
+			expected:    map[string]string{TargetCPUPercentileAnnotation: "0.8"},
+			changed:     true,
+		},
+		{
+			name:        "unchanged when already applied",
+			annotations: map[string]string{TargetCPUPercentileAnnotation: "0.9"},
+			tuning:      &autoscalingv1.RecommendationTuning{TargetCPUPercentile: "0.9"},
+			expected:    map[string]string{TargetCPUPercentileAnnotation: "0.9"},
+			changed:     false,
+		},
+		{
+			name:        "unchanged without tuning",
+			annotations: map[string]string{"team": "payments"},
+			expected:    map[string]string{"team": "payments"},
+			changed:     false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target := &metav1.ObjectMeta{Annotations: tt.annotations}
+			assert.Equal(t, tt.changed, ApplyTuningAnnotations(target, tt.tuning))
+			assert.Equal(t, tt.expected, target.GetAnnotations())
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package vpa
+
+import (
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// UpdateModeSourceAnnotation records the policy layer and source that set the current
+	// update mode of a VPA, as "<layer>: <source>"
+	UpdateModeSourceAnnotation = "vpa-operator.io/update-mode-source"
+	// UpdateModeChangedAtAnnotation records, in RFC3339, when the operator last set the
+	// update mode of a VPA to a new value
+	UpdateModeChangedAtAnnotation = "vpa-operator.io/update-mode-changed-at"
+)
+
+// UpdateMode returns the updatePolicy.updateMode of a VPA spec, empty when unset
+func UpdateMode(spec map[string]interface{}) string {
+	updatePolicy, _ := spec["updatePolicy"].(map[string]interface{})
+	mode, _ := updatePolicy["updateMode"].(string)
+	return mode
+}
+
+// RecordUpdateModeChange stamps obj with the layer and source of its update mode when the mode
+// moves from previous to current; previous is empty for a VPA being created. It reports
+// whether the mode changed.
+func RecordUpdateModeChange(obj metav1.Object, previous, current, layer, source string, now time.Time) bool {
+	if previous == current {
+		return false
+	}
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string, 2)
+	}
+	annotations[UpdateModeSourceAnnotation] = layer + ": " + source
+	annotations[UpdateModeChangedAtAnnotation] = now.UTC().Format(time.RFC3339)
+	obj.SetAnnotations(annotations)
+	return true
+}
@@ -0,0 +1,91 @@
+package vpa
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestUpdateMode(t *testing.T) {
+	tests := []struct {
+		name     string
+		spec     map[string]interface{}
+		expected string
+	}{
+		{name: "no update policy", spec: map[string]interface{}{}},
+		{name: "no update mode", spec: map[string]interface{}{"updatePolicy": map[string]interface{}{}}},
+		{
+			name:     "update mode",
+			spec:     map[string]interface{}{"updatePolicy": map[string]interface{}{"updateMode": "Auto"}},
+			expected: "Auto",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, UpdateMode(tt.spec))
+		})
+	}
+}
+
+func TestRecordUpdateModeChange(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	earlier := func() map[string]string {
+		return map[string]string{
+			UpdateModeSourceAnnotation:    "vpaManager: VpaManager prod",
+			UpdateModeChangedAtAnnotation: "2026-02-01T12:00:00Z",
+		}
+	}
+
+	tests := []struct {
+		name                string
+		annotations         map[string]string
+		previous            string
+		current             string
+		expectedAnnotations map[string]string
+		expectedChanged     bool
+	}{
+		{
+			name:                "unchanged mode keeps the last change",
+			annotations:         earlier(),
+			previous:            "Auto",
+			current:             "Auto",
+			expectedAnnotations: earlier(),
+		},
+		{
+			name:     "new VPA",
+			previous: "",
+			current:  "Initial",
+			expectedAnnotations: map[string]string{
+				UpdateModeSourceAnnotation:    "dormancy: dormancy policy of VpaManager prod",
+				UpdateModeChangedAtAnnotation: "2026-03-01T12:00:00Z",
+			},
+			expectedChanged: true,
+		},
+		{
+			name:        "changed mode",
+			annotations: earlier(),
+			previous:    "Auto",
+			current:     "Off",
+			expectedAnnotations: map[string]string{
+				UpdateModeSourceAnnotation:    "dormancy: dormancy policy of VpaManager prod",
+				UpdateModeChangedAtAnnotation: "2026-03-01T12:00:00Z",
+			},
+			expectedChanged: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			obj := &unstructured.Unstructured{}
+			obj.SetAnnotations(tt.annotations)
+
+			changed := RecordUpdateModeChange(obj, tt.previous, tt.current, "dormancy", "dormancy policy of VpaManager prod", now)
+
+			assert.Equal(t, tt.expectedChanged, changed)
+			assert.Equal(t, tt.expectedAnnotations, obj.GetAnnotations())
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package vpa
+
+import (
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// WorkloadVPAAnnotation is set on a managed workload to its VPA and effective update mode,
+// as "<vpa>,mode=<mode>", so app teams see the VPA from their own objects
+const WorkloadVPAAnnotation = "vpa-operator.io/vpa"
+
+// WorkloadVPAValue returns the WorkloadVPAAnnotation value for a VPA and its update mode
+func WorkloadVPAValue(vpaName, mode string) string {
+	if mode == "" {
+		return vpaName
+	}
+	return vpaName + ",mode=" + mode
+}
+
+// WorkloadVPAName returns the VPA named by a WorkloadVPAAnnotation value
+func WorkloadVPAName(value string) string {
+	name, _, _ := strings.Cut(value, ",")
+	return name
+}
+
+// WorkloadAnnotationsEqual reports whether two annotation sets of a workload are equal apart
+// from the WorkloadVPAAnnotation, which the operator writes itself and which therefore never
+// calls for a new reconcile
+func WorkloadAnnotationsEqual(a, b map[string]string) bool {
+	if len(a)-countWorkloadVPA(a) != len(b)-countWorkloadVPA(b) {
+		return false
+	}
+	for key, value := range a {
+		if key == WorkloadVPAAnnotation {
+			continue
+		}
+		if other, ok := b[key]; !ok || other != value {
+			return false
+		}
+	}
+	return true
+}
+
+func countWorkloadVPA(annotations map[string]string) int {
+	if _, ok := annotations[WorkloadVPAAnnotation]; ok {
+		return 1
+	}
+	return 0
+}
+
+// SetWorkloadVPA sets the WorkloadVPAAnnotation of obj to value, removing it when value is
+// empty, and reports whether it changed
+func SetWorkloadVPA(obj metav1.Object, value string) bool {
+	annotations := obj.GetAnnotations()
+	current, ok := annotations[WorkloadVPAAnnotation]
+	if value == "" {
+		if !ok {
+			return false
+		}
+		delete(annotations, WorkloadVPAAnnotation)
+		obj.SetAnnotations(annotations)
+		return true
+	}
+	if ok && current == value {
+		return false
+	}
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[WorkloadVPAAnnotation] = value
+	obj.SetAnnotations(annotations)
+	return true
+}
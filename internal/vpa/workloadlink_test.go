@@ -0,0 +1,71 @@
+package vpa
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+)
+
+func TestWorkloadVPAValue(t *testing.T) {
+	assert.Equal(t, "web-vpa,mode=Auto", WorkloadVPAValue("web-vpa", "Auto"))
+	assert.Equal(t, "web-vpa", WorkloadVPAValue("web-vpa", ""))
+	assert.Equal(t, "web-vpa", WorkloadVPAName(WorkloadVPAValue("web-vpa", "Off")))
+	assert.Equal(t, "web-vpa", WorkloadVPAName("web-vpa"))
+}
+
+func TestWorkloadAnnotationsEqual(t *testing.T) {
+	assert.True(t, WorkloadAnnotationsEqual(nil, map[string]string{}))
+	assert.True(t, WorkloadAnnotationsEqual(map[string]string{"team": "shop"}, map[string]string{"team": "shop", WorkloadVPAAnnotation: "web-vpa,mode=Auto"}))
+	assert.True(t, WorkloadAnnotationsEqual(map[string]string{WorkloadVPAAnnotation: "web-vpa,mode=Auto"}, map[string]string{WorkloadVPAAnnotation: "web-vpa,mode=Off"}))
+	assert.False(t, WorkloadAnnotationsEqual(map[string]string{"team": "shop"}, map[string]string{"team": "search"}))
+	assert.False(t, WorkloadAnnotationsEqual(map[string]string{WorkloadVPAAnnotation: "web-vpa"}, map[string]string{"team": "shop"}))
+}
+
+func TestSetWorkloadVPA(t *testing.T) {
+	tests := []struct {
+		name            string
+		annotations     map[string]string
+		value           string
+		expectedChanged bool
+		expected        map[string]string
+	}{
+		{
+			name:            "set on a workload without annotations",
+			value:           "web-vpa,mode=Auto",
+			expectedChanged: true,
+			expected:        map[string]string{WorkloadVPAAnnotation: "web-vpa,mode=Auto"},
+		},
+		{
+			name:        "unchanged",
+			annotations: map[string]string{WorkloadVPAAnnotation: "web-vpa,mode=Auto", "team": "shop"},
+			value:       "web-vpa,mode=Auto",
+			expected:    map[string]string{WorkloadVPAAnnotation: "web-vpa,mode=Auto", "team": "shop"},
+		},
+		{
+			name:            "mode changed",
+			annotations:     map[string]string{WorkloadVPAAnnotation: "web-vpa,mode=Auto"},
+			value:           "web-vpa,mode=Off",
+			expectedChanged: true,
+			expected:        map[string]string{WorkloadVPAAnnotation: "web-vpa,mode=Off"},
+		},
+		{
+			name:            "removed",
+			annotations:     map[string]string{WorkloadVPAAnnotation: "web-vpa,mode=Auto", "team": "shop"},
+			expectedChanged: true,
+			expected:        map[string]string{"team": "shop"},
+		},
+		{
+			name: "nothing to remove",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			obj := &appsv1.Deployment{}
+			obj.SetAnnotations(tt.annotations)
+			assert.Equal(t, tt.expectedChanged, SetWorkloadVPA(obj, tt.value))
+			assert.Equal(t, tt.expected, obj.GetAnnotations())
+		})
+	}
+}
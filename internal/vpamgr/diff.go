@@ -0,0 +1,117 @@
+// Package vpamgr implements the subcommands of the kubectl-vpamgr plugin, which shows what
+// the operator would do to a cluster before it does it
+package vpamgr
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+
+	"github.com/joaomo/k8s_op_vpa/internal/controller"
+	"github.com/joaomo/k8s_op_vpa/internal/vpa"
+)
+
+// diffContextLines is the number of unchanged lines shown around each change
+const diffContextLines = 3
+
+// volatileAnnotations change on every write, so they are left out of diffs
+var volatileAnnotations = []string{
+	vpa.LastReconciledAtAnnotation,
+	vpa.ReconcileIDAnnotation,
+	"vpa-operator.io/spec-hash",
+}
+
+// WriteDiff writes a unified diff of each planned change to w, from the live VPA to the one the
+// operator would write, and reports whether any change remained. Changes that only touch
+// volatile annotations are skipped.
+func WriteDiff(w io.Writer, changes []controller.PlannedChange) (bool, error) {
+	differs := false
+	for _, change := range changes {
+		live, err := render(change.Live)
+		if err != nil {
+			return differs, err
+		}
+		desired, err := render(change.Desired)
+		if err != nil {
+			return differs, err
+		}
+		if live == desired {
+			continue
+		}
+
+		path := objectPath(change)
+		diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+			A:        lines(live),
+			B:        lines(desired),
+			FromFile: "live/" + path,
+			ToFile:   "planned/" + path,
+			Context:  diffContextLines,
+		})
+		if err != nil {
+			return differs, err
+		}
+		if _, err := fmt.Fprintf(w, "# VpaManager %s would %s VPA %s\n%s", change.VpaManager, change.Operation, path, diff); err != nil {
+			return differs, err
+		}
+		differs = true
+	}
+	return differs, nil
+}
+
+// lines splits s into lines that keep their newline
+func lines(s string) []string {
+	split := strings.SplitAfter(s, "\n")
+	if split[len(split)-1] == "" {
+		split = split[:len(split)-1]
+	}
+	return split
+}
+
+// objectPath returns namespace/name of the VPA a change writes
+func objectPath(change controller.PlannedChange) string {
+	obj := change.Desired
+	if obj == nil {
+		obj = change.Live
+	}
+	return obj.GetNamespace() + "/" + obj.GetName()
+}
+
+// render returns the YAML of the fields of a VPA the operator manages, empty for nil. Server
+// populated metadata and status are left out.
+func render(obj *unstructured.Unstructured) (string, error) {
+	if obj == nil {
+		return "", nil
+	}
+	metadata := map[string]interface{}{
+		"name":      obj.GetName(),
+		"namespace": obj.GetNamespace(),
+	}
+	if labels := obj.GetLabels(); len(labels) > 0 {
+		metadata["labels"] = labels
+	}
+	annotations := obj.GetAnnotations()
+	for _, key := range volatileAnnotations {
+		delete(annotations, key)
+	}
+	if len(annotations) > 0 {
+		metadata["annotations"] = annotations
+	}
+	if owners, found, _ := unstructured.NestedSlice(obj.Object, "metadata", "ownerReferences"); found {
+		metadata["ownerReferences"] = owners
+	}
+
+	out, err := yaml.Marshal(map[string]interface{}{
+		"apiVersion": obj.GetAPIVersion(),
+		"kind":       obj.GetKind(),
+		"metadata":   metadata,
+		"spec":       obj.Object["spec"],
+	})
+	if err != nil {
+		return "", fmt.Errorf("rendering VPA %s/%s: %w", obj.GetNamespace(), obj.GetName(), err)
+	}
+	return string(out), nil
+}
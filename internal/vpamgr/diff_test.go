@@ -0,0 +1,111 @@
+package vpamgr
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/joaomo/k8s_op_vpa/internal/controller"
+	"github.com/joaomo/k8s_op_vpa/internal/vpa"
+)
+
+func testVPA(mode string, annotations map[string]string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "autoscaling.k8s.io/v1",
+		"kind":       "VerticalPodAutoscaler",
+		"spec":       map[string]interface{}{"updatePolicy": map[string]interface{}{"updateMode": mode}},
+		"status":     map[string]interface{}{"conditions": []interface{}{}},
+	}}
+	obj.SetName("web-vpa")
+	obj.SetNamespace("shop")
+	obj.SetResourceVersion("42")
+	obj.SetAnnotations(annotations)
+	return obj
+}
+
+func TestWriteDiff(t *testing.T) {
+	stamp := func(id string) map[string]string {
+		return map[string]string{vpa.ReconcileIDAnnotation: id, vpa.LastReconciledAtAnnotation: id}
+	}
+
+	tests := []struct {
+		name            string
+		changes         []controller.PlannedChange
+		expectedDiffers bool
+		expected        string
+	}{
+		{
+			name: "no changes",
+		},
+		{
+			name: "only volatile annotations changed",
+			changes: []controller.PlannedChange{{
+				VpaManager: "prod",
+				Operation:  controller.PlanUpdate,
+				Live:       testVPA("Auto", stamp("a")),
+				Desired:    testVPA("Auto", stamp("b")),
+			}},
+		},
+		{
+			name: "update",
+			changes: []controller.PlannedChange{{
+				VpaManager: "prod",
+				Operation:  controller.PlanUpdate,
+				Live:       testVPA("Auto", stamp("a")),
+				Desired:    testVPA("Off", map[string]string{vpa.UpdateModeSourceAnnotation: "override: VpaOverride shop/web"}),
+			}},
+			expectedDiffers: true,
+			expected: `# VpaManager prod would update VPA shop/web-vpa
+--- live/shop/web-vpa
++++ planned/shop/web-vpa
+@@ -1,8 +1,10 @@
+ apiVersion: autoscaling.k8s.io/v1
+ kind: VerticalPodAutoscaler
+ metadata:
++  annotations:
++    vpa-operator.io/update-mode-source: 'override: VpaOverride shop/web'
+   name: web-vpa
+   namespace: shop
+ spec:
+   updatePolicy:
+-    updateMode: Auto
++    updateMode: "Off"
+`,
+		},
+		{
+			name: "delete",
+			changes: []controller.PlannedChange{{
+				VpaManager: "prod",
+				Operation:  controller.PlanDelete,
+				Live:       testVPA("Auto", nil),
+			}},
+			expectedDiffers: true,
+			expected: `# VpaManager prod would delete VPA shop/web-vpa
+--- live/shop/web-vpa
++++ planned/shop/web-vpa
+@@ -1,8 +0,0 @@
+-apiVersion: autoscaling.k8s.io/v1
+-kind: VerticalPodAutoscaler
+-metadata:
+-  name: web-vpa
+-  namespace: shop
+-spec:
+-  updatePolicy:
+-    updateMode: Auto
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			differs, err := WriteDiff(&out, tt.changes)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedDiffers, differs)
+			assert.Equal(t, tt.expected, out.String())
+		})
+	}
+}
@@ -0,0 +1,322 @@
+package vpamgr
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"slices"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/yaml"
+
+	autoscalingv1 "github.com/joaomo/k8s_op_vpa/api/v1"
+	"github.com/joaomo/k8s_op_vpa/internal/validation"
+	"github.com/joaomo/k8s_op_vpa/internal/vpa"
+)
+
+// Severities of lint findings
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// Finding is a problem lint found in an object of a manifest
+type Finding struct {
+	// Object is the kind and name of the object, e.g. VpaManager/prod
+	Object   string
+	Severity string
+	Message  string
+}
+
+// LintOptions are the operator settings manifests are linted against
+type LintOptions struct {
+	// AutoSafeguards is the operator's --auto-safeguards mode. Enforced safeguards are
+	// reported as errors, warned ones as warnings.
+	AutoSafeguards validation.AutoSafeguardMode
+}
+
+// updateModes are the update modes the CRD schemas allow
+var updateModes = []string{"Off", "Initial", "Auto"}
+
+// Lint checks the VpaManagers and VpaOverrides of a YAML or JSON manifest without a
+// cluster: unknown fields and values the CRD schema rejects, the checks of the validating
+// webhook, and selectors or container policies that do not do what they seem to. Other
+// kinds are skipped. Parents named by inheritFrom are not read, so every VpaManager is
+// linted as written. It only fails when the manifest cannot be read.
+func Lint(r io.Reader, opts LintOptions) ([]Finding, error) {
+	reader := utilyaml.NewYAMLReader(bufio.NewReader(r))
+	var findings []Finding
+	for doc := 1; ; doc++ {
+		data, err := reader.Read()
+		if err == io.EOF {
+			return findings, nil
+		}
+		if err != nil {
+			return findings, err
+		}
+		var obj metav1.PartialObjectMetadata
+		if err := yaml.Unmarshal(data, &obj); err != nil {
+			findings = append(findings, Finding{Object: fmt.Sprintf("document %d", doc), Severity: SeverityError, Message: err.Error()})
+			continue
+		}
+		gvk := obj.GroupVersionKind()
+		if gvk.Group != autoscalingv1.GroupVersion.Group {
+			continue
+		}
+		name := fmt.Sprintf("%s/%s", gvk.Kind, obj.Name)
+		if obj.Name == "" {
+			name = fmt.Sprintf("%s (document %d)", gvk.Kind, doc)
+		}
+		if gvk.Version != autoscalingv1.GroupVersion.Version {
+			findings = append(findings, Finding{Object: name, Severity: SeverityError,
+				Message: fmt.Sprintf("apiVersion: %s is not served, use %s", obj.APIVersion, autoscalingv1.GroupVersion)})
+			continue
+		}
+
+		var errs, warnings []string
+		switch gvk.Kind {
+		case "VpaManager":
+			errs, warnings = lintVpaManager(data, opts)
+		case "VpaOverride":
+			errs, warnings = lintVpaOverride(data)
+		default:
+			continue
+		}
+		for _, err := range errs {
+			findings = append(findings, Finding{Object: name, Severity: SeverityError, Message: err})
+		}
+		for _, warning := range warnings {
+			findings = append(findings, Finding{Object: name, Severity: SeverityWarning, Message: warning})
+		}
+	}
+}
+
+// WriteLint writes findings to w, one per line prefixed with source, and reports whether
+// any is an error
+func WriteLint(w io.Writer, source string, findings []Finding) (bool, error) {
+	failed := false
+	for _, f := range findings {
+		if f.Severity == SeverityError {
+			failed = true
+		}
+		if _, err := fmt.Fprintf(w, "%s: %s: %s: %s\n", source, f.Object, f.Severity, f.Message); err != nil {
+			return failed, err
+		}
+	}
+	return failed, nil
+}
+
+// lintVpaManager returns the errors and warnings of a VpaManager document
+func lintVpaManager(data []byte, opts LintOptions) ([]string, []string) {
+	vm := &autoscalingv1.VpaManager{}
+	var errs field.ErrorList
+	schemaErr, ok := decodeStrict(data, vm)
+	if !ok {
+		return schemaErr, nil
+	}
+	if vm.Name == "" {
+		errs = append(errs, field.Required(field.NewPath("metadata", "name"), ""))
+	}
+
+	spec := &vm.Spec
+	errs = append(errs, managerEnumErrors(spec)...)
+	errs = append(errs, validation.ValidateVpaManagerSpec(spec)...)
+	errs = append(errs, validation.ValidateTenantScope(vm)...)
+
+	warnings := validation.Warnings(spec)
+	warnings = append(warnings, selectorWarnings(spec)...)
+	warnings = append(warnings, precedenceWarnings(spec)...)
+	for _, err := range validation.ValidateAutoSafeguards(spec) {
+		switch opts.AutoSafeguards {
+		case validation.AutoSafeguardsEnforce:
+			errs = append(errs, err)
+		case validation.AutoSafeguardsWarn:
+			warnings = append(warnings, err.Error())
+		}
+	}
+	return append(schemaErr, messages(errs)...), warnings
+}
+
+// lintVpaOverride returns the errors and warnings of a VpaOverride document
+func lintVpaOverride(data []byte) ([]string, []string) {
+	override := &autoscalingv1.VpaOverride{}
+	var errs field.ErrorList
+	schemaErr, ok := decodeStrict(data, override)
+	if !ok {
+		return schemaErr, nil
+	}
+	if override.Name == "" {
+		errs = append(errs, field.Required(field.NewPath("metadata", "name"), ""))
+	}
+
+	specPath := field.NewPath("spec")
+	errs = append(errs, enumError(specPath.Child("updateMode"), override.Spec.UpdateMode, updateModes)...)
+	errs = append(errs, enumError(specPath.Child("targetRef", "kind"), override.Spec.TargetRef.Kind,
+		[]string{"Deployment", "StatefulSet", "DaemonSet"})...)
+	errs = append(errs, policyEnumErrors(override.Spec.ResourcePolicy, specPath.Child("resourcePolicy"))...)
+	errs = append(errs, validation.ValidateVpaOverrideSpec(&override.Spec)...)
+	var warnings []string
+	if override.Spec.ResourcePolicy != nil {
+		warnings = duplicatePolicyWarnings(override.Spec.ResourcePolicy, specPath.Child("resourcePolicy"))
+	}
+	return append(schemaErr, messages(errs)...), warnings
+}
+
+// decodeStrict decodes data into obj. Unknown and duplicate fields, which the API server
+// drops, are reported and the object is still decoded; values of the wrong type leave
+// nothing to lint and ok is false.
+func decodeStrict(data []byte, obj interface{}) (errs []string, ok bool) {
+	err := yaml.UnmarshalStrict(data, obj)
+	if err == nil {
+		return nil, true
+	}
+	errs = []string{"does not match the CRD schema: " + err.Error()}
+	return errs, yaml.Unmarshal(data, obj) == nil
+}
+
+// messages returns the messages of errs
+func messages(errs field.ErrorList) []string {
+	out := make([]string, 0, len(errs))
+	for _, err := range errs {
+		out = append(out, err.Error())
+	}
+	return out
+}
+
+// managerEnumErrors checks the VpaManager fields whose values the CRD schema enumerates
+func managerEnumErrors(spec *autoscalingv1.VpaManagerSpec) field.ErrorList {
+	specPath := field.NewPath("spec")
+	errs := enumError(specPath.Child("updateMode"), spec.UpdateMode, updateModes)
+	if modes := spec.UpdateModes; modes != nil {
+		modesPath := specPath.Child("updateModes")
+		errs = append(errs, enumError(modesPath.Child("deployment"), modes.Deployment, updateModes)...)
+		errs = append(errs, enumError(modesPath.Child("statefulset"), modes.StatefulSet, updateModes)...)
+		errs = append(errs, enumError(modesPath.Child("daemonset"), modes.DaemonSet, updateModes)...)
+	}
+	errs = append(errs, enumError(specPath.Child("containerPolicyMerge"), spec.ContainerPolicyMerge, []string{
+		autoscalingv1.ContainerPolicyMergeOperatorWins,
+		autoscalingv1.ContainerPolicyMergeExistingWins,
+		autoscalingv1.ContainerPolicyMergeByContainerName,
+	})...)
+	return append(errs, policyEnumErrors(spec.ResourcePolicy, specPath.Child("resourcePolicy"))...)
+}
+
+// policyEnumErrors checks the container policy fields whose values the CRD schema enumerates
+func policyEnumErrors(policy *autoscalingv1.ResourcePolicy, path *field.Path) field.ErrorList {
+	if policy == nil {
+		return nil
+	}
+	var errs field.ErrorList
+	for i, cp := range policy.ContainerPolicies {
+		cpPath := path.Child("containerPolicies").Index(i)
+		errs = append(errs, enumError(cpPath.Child("controlledValues"), cp.ControlledValues, []string{
+			autoscalingv1.ControlledValuesRequestsAndLimits,
+			autoscalingv1.ControlledValuesRequestsOnly,
+		})...)
+		errs = append(errs, enumError(cpPath.Child("mode"), cp.Mode, []string{
+			autoscalingv1.ContainerScalingModeAuto,
+			autoscalingv1.ContainerScalingModeOff,
+		})...)
+	}
+	return errs
+}
+
+// enumError reports a set value that is not one of allowed
+func enumError(path *field.Path, value string, allowed []string) field.ErrorList {
+	if value == "" || slices.Contains(allowed, value) {
+		return nil
+	}
+	return field.ErrorList{field.NotSupported(path, value, allowed)}
+}
+
+// selectorWarnings reports selectors that select more than they seem to
+func selectorWarnings(spec *autoscalingv1.VpaManagerSpec) []string {
+	var warnings []string
+	specPath := field.NewPath("spec")
+	if emptySelector(spec.NamespaceSelector) {
+		warnings = append(warnings, specPath.Child("namespaceSelector").String()+
+			": an empty selector matches every namespace; set matchAllNamespaces: true to say so")
+	}
+	for _, kind := range []struct {
+		field    string
+		selector *metav1.LabelSelector
+	}{
+		{"deploymentSelector", spec.DeploymentSelector},
+		{"statefulSetSelector", spec.StatefulSetSelector},
+		{"daemonSetSelector", spec.DaemonSetSelector},
+	} {
+		if emptySelector(kind.selector) {
+			warnings = append(warnings, specPath.Child(kind.field).String()+
+				": an empty selector matches every workload of its kind; set matchAllWorkloads: true to say so")
+		}
+	}
+	for i, name := range spec.Namespaces {
+		if slices.Contains(spec.ExcludeNamespaces, name) {
+			warnings = append(warnings, fmt.Sprintf("%s: %q is also listed in excludeNamespaces, which wins",
+				specPath.Child("namespaces").Index(i), name))
+		}
+	}
+	return warnings
+}
+
+// emptySelector reports whether a selector is set but matches everything
+func emptySelector(selector *metav1.LabelSelector) bool {
+	return selector != nil && len(selector.MatchLabels) == 0 && len(selector.MatchExpressions) == 0
+}
+
+// precedenceWarnings reports settings that other settings take precedence over, so they
+// have no effect or not the one intended
+func precedenceWarnings(spec *autoscalingv1.VpaManagerSpec) []string {
+	specPath := field.NewPath("spec")
+	var warnings []string
+	if modes := spec.UpdateModes; modes != nil && modes.Deployment != "" && modes.StatefulSet != "" && modes.DaemonSet != "" &&
+		spec.UpdateMode != "" {
+		warnings = append(warnings, specPath.Child("updateMode").String()+
+			": updateModes sets the mode of every workload kind, so updateMode has no effect")
+	}
+
+	policy := spec.ResourcePolicy
+	if policy == nil {
+		return warnings
+	}
+	policiesPath := specPath.Child("resourcePolicy", "containerPolicies")
+	warnings = append(warnings, duplicatePolicyWarnings(policy, specPath.Child("resourcePolicy"))...)
+	if spec.ExcludeWellKnownSidecars {
+		for i, cp := range policy.ContainerPolicies {
+			if slices.Contains(vpa.WellKnownSidecars, cp.ContainerName) && cp.Mode != autoscalingv1.ContainerScalingModeOff {
+				warnings = append(warnings, fmt.Sprintf("%s: the policy for %s takes precedence over excludeWellKnownSidecars, so the VPA scales it",
+					policiesPath.Index(i), cp.ContainerName))
+			}
+		}
+	}
+	if len(spec.DefaultControlledResources) > 0 && len(policy.ContainerPolicies) > 0 {
+		wildcard, overridden := false, true
+		for _, cp := range policy.ContainerPolicies {
+			wildcard = wildcard || cp.ContainerName == vpa.WildcardContainer
+			overridden = overridden && len(cp.ControlledResources) > 0
+		}
+		if wildcard && overridden {
+			warnings = append(warnings, specPath.Child("defaultControlledResources").String()+
+				": every container policy, including \"*\", sets controlledResources, so defaultControlledResources has no effect")
+		}
+	}
+	return warnings
+}
+
+// duplicatePolicyWarnings reports container policies for a container name or pattern that
+// an earlier policy already covers; the VPA uses the first policy for a container
+func duplicatePolicyWarnings(policy *autoscalingv1.ResourcePolicy, path *field.Path) []string {
+	var warnings []string
+	first := map[string]int{}
+	for i, cp := range policy.ContainerPolicies {
+		if j, ok := first[cp.ContainerName]; ok {
+			warnings = append(warnings, fmt.Sprintf("%s: %q is also the containerName of containerPolicies[%d], which takes precedence",
+				path.Child("containerPolicies").Index(i).Child("containerName"), cp.ContainerName, j))
+			continue
+		}
+		first[cp.ContainerName] = i
+	}
+	return warnings
+}
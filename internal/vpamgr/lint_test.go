@@ -0,0 +1,182 @@
+package vpamgr
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/joaomo/k8s_op_vpa/internal/validation"
+)
+
+const lintManager = `apiVersion: operators.joaomo.io/v1
+kind: VpaManager
+metadata:
+  name: prod
+spec:
+  enabled: true
+  updateMode: "Off"
+  namespaceSelector:
+    matchLabels:
+      vpa-enabled: "true"
+`
+
+func TestLint(t *testing.T) {
+	tests := []struct {
+		name           string
+		manifest       string
+		autoSafeguards validation.AutoSafeguardMode
+		expected       []Finding
+	}{
+		{
+			name:     "valid VpaManager",
+			manifest: lintManager,
+		},
+		{
+			name: "other kinds are skipped",
+			manifest: `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+data:
+  unknown: field
+---
+` + lintManager,
+		},
+		{
+			name:     "unknown field",
+			manifest: lintManager + "  updateMod: Auto\n",
+			expected: []Finding{{Object: "VpaManager/prod", Severity: SeverityError,
+				Message: `does not match the CRD schema: error unmarshaling JSON: while decoding JSON: json: unknown field "updateMod"`}},
+		},
+		{
+			name: "invalid quantity",
+			manifest: lintManager + `  resourcePolicy:
+    containerPolicies:
+    - containerName: "*"
+      maxAllowed:
+        cpu: lots
+`,
+			expected: []Finding{{Object: "VpaManager/prod", Severity: SeverityError,
+				Message: `spec.resourcePolicy.containerPolicies[0].maxAllowed[cpu]: Invalid value: "lots": must be a valid resource quantity, e.g. "100m" or "1" for cpu and "128Mi" or "1Gi" for memory`}},
+		},
+		{
+			name:     "unsupported update mode",
+			manifest: strings.Replace(lintManager, `updateMode: "Off"`, "updateMode: Recreate", 1),
+			expected: []Finding{{Object: "VpaManager/prod", Severity: SeverityError,
+				Message: `spec.updateMode: Unsupported value: "Recreate": supported values: "Off", "Initial", "Auto"`}},
+		},
+		{
+			name:     "missing name",
+			manifest: strings.Replace(lintManager, "  name: prod\n", "  labels:\n    team: shop\n", 1),
+			expected: []Finding{{Object: "VpaManager (document 1)", Severity: SeverityError,
+				Message: "metadata.name: Required value"}},
+		},
+		{
+			name:     "unserved version",
+			manifest: strings.Replace(lintManager, "operators.joaomo.io/v1", "operators.joaomo.io/v1beta1", 1),
+			expected: []Finding{{Object: "VpaManager/prod", Severity: SeverityError,
+				Message: "apiVersion: operators.joaomo.io/v1beta1 is not served, use operators.joaomo.io/v1"}},
+		},
+		{
+			name: "empty selector",
+			manifest: lintManager + `  deploymentSelector: {}
+`,
+			expected: []Finding{{Object: "VpaManager/prod", Severity: SeverityWarning,
+				Message: "spec.deploymentSelector: an empty selector matches every workload of its kind; set matchAllWorkloads: true to say so"}},
+		},
+		{
+			name: "excluded namespace",
+			manifest: lintManager + `  namespaces: [shop]
+  excludeNamespaces: [shop]
+`,
+			expected: []Finding{{Object: "VpaManager/prod", Severity: SeverityWarning,
+				Message: `spec.namespaces[0]: "shop" is also listed in excludeNamespaces, which wins`}},
+		},
+		{
+			name: "duplicate container policy",
+			manifest: lintManager + `  resourcePolicy:
+    containerPolicies:
+    - containerName: app
+      controlledValues: RequestsOnly
+    - containerName: app
+`,
+			expected: []Finding{{Object: "VpaManager/prod", Severity: SeverityWarning,
+				Message: `spec.resourcePolicy.containerPolicies[1].containerName: "app" is also the containerName of containerPolicies[0], which takes precedence`}},
+		},
+		{
+			name: "sidecar policy overrides excludeWellKnownSidecars",
+			manifest: lintManager + `  excludeWellKnownSidecars: true
+  resourcePolicy:
+    containerPolicies:
+    - containerName: istio-proxy
+      controlledValues: RequestsOnly
+`,
+			expected: []Finding{{Object: "VpaManager/prod", Severity: SeverityWarning,
+				Message: "spec.resourcePolicy.containerPolicies[0]: the policy for istio-proxy takes precedence over excludeWellKnownSidecars, so the VPA scales it"}},
+		},
+		{
+			name:           "auto safeguards off",
+			manifest:       strings.Replace(lintManager, `updateMode: "Off"`, "updateMode: Auto", 1),
+			autoSafeguards: validation.AutoSafeguardsOff,
+		},
+		{
+			name: "VpaOverride",
+			manifest: `apiVersion: operators.joaomo.io/v1
+kind: VpaOverride
+metadata:
+  name: web
+  namespace: shop
+spec:
+  targetRef:
+    kind: CronJob
+    name: web
+  updateMode: Initial
+`,
+			expected: []Finding{{Object: "VpaOverride/web", Severity: SeverityError,
+				Message: `spec.targetRef.kind: Unsupported value: "CronJob": supported values: "Deployment", "StatefulSet", "DaemonSet"`}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			findings, err := Lint(strings.NewReader(tt.manifest), LintOptions{AutoSafeguards: tt.autoSafeguards})
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, findings)
+		})
+	}
+}
+
+func TestLint_AutoSafeguards(t *testing.T) {
+	manifest := strings.Replace(lintManager, `updateMode: "Off"`, "updateMode: Auto", 1)
+
+	warned, err := Lint(strings.NewReader(manifest), LintOptions{AutoSafeguards: validation.AutoSafeguardsWarn})
+	require.NoError(t, err)
+	require.NotEmpty(t, warned)
+	enforced, err := Lint(strings.NewReader(manifest), LintOptions{AutoSafeguards: validation.AutoSafeguardsEnforce})
+	require.NoError(t, err)
+	require.Len(t, enforced, len(warned))
+	for i := range warned {
+		assert.Equal(t, SeverityWarning, warned[i].Severity)
+		assert.Equal(t, SeverityError, enforced[i].Severity)
+		assert.Equal(t, warned[i].Message, enforced[i].Message)
+	}
+}
+
+func TestWriteLint(t *testing.T) {
+	var out bytes.Buffer
+	failed, err := WriteLint(&out, "prod.yaml", []Finding{
+		{Object: "VpaManager/prod", Severity: SeverityWarning, Message: "spec.deploymentSelector: empty"},
+	})
+	require.NoError(t, err)
+	assert.False(t, failed)
+	assert.Equal(t, "prod.yaml: VpaManager/prod: warning: spec.deploymentSelector: empty\n", out.String())
+
+	failed, err = WriteLint(&out, "prod.yaml", []Finding{
+		{Object: "VpaManager/prod", Severity: SeverityError, Message: "spec.updateMode: Unsupported value"},
+	})
+	require.NoError(t, err)
+	assert.True(t, failed)
+}
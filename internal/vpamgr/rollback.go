@@ -0,0 +1,91 @@
+package vpamgr
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	autoscalingv1 "github.com/joaomo/k8s_op_vpa/api/v1"
+)
+
+// RollbackRevision picks the revision of vm's status.specHistory to roll back to: the one of
+// generation, or when generation is 0 the newest one whose spec differs from the current spec
+func RollbackRevision(vm *autoscalingv1.VpaManager, generation int64) (*autoscalingv1.SpecRevision, error) {
+	history := vm.Status.SpecHistory
+	if len(history) == 0 {
+		return nil, fmt.Errorf("VpaManager %s has no spec history yet", vm.Name)
+	}
+	if generation != 0 {
+		for i := range history {
+			if history[i].Generation == generation {
+				return &history[i], nil
+			}
+		}
+		return nil, fmt.Errorf("VpaManager %s has no revision of generation %d in its spec history", vm.Name, generation)
+	}
+
+	current, err := json.Marshal(vm.Spec)
+	if err != nil {
+		return nil, err
+	}
+	for i := len(history) - 1; i >= 0; i-- {
+		if history[i].Spec != string(current) {
+			return &history[i], nil
+		}
+	}
+	return nil, fmt.Errorf("VpaManager %s has no earlier spec in its spec history", vm.Name)
+}
+
+// Rollback re-applies a previous spec of the VpaManager name from its status.specHistory,
+// see RollbackRevision, and returns the revision applied. The patch fails if the VpaManager
+// changed since it was read. With dryRun the API server validates the patch without
+// persisting it.
+func Rollback(ctx context.Context, c client.Client, name string, generation int64, dryRun bool) (*autoscalingv1.SpecRevision, error) {
+	vm := &autoscalingv1.VpaManager{}
+	if err := c.Get(ctx, client.ObjectKey{Name: name}, vm); err != nil {
+		return nil, err
+	}
+	revision, err := RollbackRevision(vm, generation)
+	if err != nil {
+		return nil, err
+	}
+
+	var spec autoscalingv1.VpaManagerSpec
+	if err := json.Unmarshal([]byte(revision.Spec), &spec); err != nil {
+		return nil, fmt.Errorf("spec of generation %d cannot be decoded: %w", revision.Generation, err)
+	}
+	patched := vm.DeepCopy()
+	patched.Spec = spec
+	var opts []client.PatchOption
+	if dryRun {
+		opts = append(opts, client.DryRunAll)
+	}
+	if err := c.Patch(ctx, patched, client.MergeFromWithOptions(vm, client.MergeFromWithOptimisticLock{}), opts...); err != nil {
+		return nil, err
+	}
+	return revision, nil
+}
+
+// WriteSpecHistory writes the status.specHistory of vm as a table, oldest first
+func WriteSpecHistory(w io.Writer, vm *autoscalingv1.VpaManager) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(tw, "GENERATION\tHASH\tFIRST RECONCILED\tMANAGED VPAS\tLAST ERROR")
+	for _, revision := range vm.Status.SpecHistory {
+		lastError := revision.LastError
+		if lastError == "" {
+			lastError = "<none>"
+		}
+		current := ""
+		if revision.Generation == vm.Generation {
+			current = " (current)"
+		}
+		fmt.Fprintf(tw, "%d%s\t%s\t%s\t%d\t%s\n", revision.Generation, current, revision.Hash,
+			revision.FirstReconcileTime.UTC().Format(time.RFC3339), revision.ManagedVPAs, lastError)
+	}
+	return tw.Flush()
+}
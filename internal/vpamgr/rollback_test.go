@@ -0,0 +1,129 @@
+package vpamgr
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	autoscalingv1 "github.com/joaomo/k8s_op_vpa/api/v1"
+)
+
+func specRevision(t *testing.T, generation int64, updateMode string) autoscalingv1.SpecRevision {
+	data, err := json.Marshal(autoscalingv1.VpaManagerSpec{UpdateMode: updateMode})
+	require.NoError(t, err)
+	return autoscalingv1.SpecRevision{Generation: generation, Hash: updateMode, Spec: string(data), ManagedVPAs: 3}
+}
+
+func historyVpaManager(t *testing.T, updateMode string, history ...autoscalingv1.SpecRevision) *autoscalingv1.VpaManager {
+	return &autoscalingv1.VpaManager{
+		ObjectMeta: metav1.ObjectMeta{Name: "prod", Generation: history[len(history)-1].Generation},
+		Spec:       autoscalingv1.VpaManagerSpec{UpdateMode: updateMode},
+		Status:     autoscalingv1.VpaManagerStatus{SpecHistory: history},
+	}
+}
+
+func TestRollbackRevision(t *testing.T) {
+	tests := []struct {
+		name               string
+		vm                 *autoscalingv1.VpaManager
+		generation         int64
+		expectedGeneration int64
+		expectedErr        string
+	}{
+		{
+			name:        "no history",
+			vm:          &autoscalingv1.VpaManager{ObjectMeta: metav1.ObjectMeta{Name: "prod"}},
+			expectedErr: "has no spec history yet",
+		},
+		{
+			name:               "newest different spec by default",
+			vm:                 historyVpaManager(t, "Auto", specRevision(t, 1, "Off"), specRevision(t, 2, "Initial"), specRevision(t, 3, "Auto")),
+			expectedGeneration: 2,
+		},
+		{
+			name: "skips generations with the same spec",
+			vm: historyVpaManager(t, "Auto",
+				specRevision(t, 1, "Off"), specRevision(t, 2, "Auto"), specRevision(t, 3, "Auto")),
+			expectedGeneration: 1,
+		},
+		{
+			name:               "explicit generation",
+			vm:                 historyVpaManager(t, "Auto", specRevision(t, 1, "Off"), specRevision(t, 2, "Initial"), specRevision(t, 3, "Auto")),
+			generation:         1,
+			expectedGeneration: 1,
+		},
+		{
+			name:        "unknown generation",
+			vm:          historyVpaManager(t, "Auto", specRevision(t, 1, "Off"), specRevision(t, 2, "Auto")),
+			generation:  7,
+			expectedErr: "no revision of generation 7",
+		},
+		{
+			name:        "only the current spec",
+			vm:          historyVpaManager(t, "Auto", specRevision(t, 1, "Auto")),
+			expectedErr: "no earlier spec",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			revision, err := RollbackRevision(tt.vm, tt.generation)
+			if tt.expectedErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedGeneration, revision.Generation)
+		})
+	}
+}
+
+func TestRollback(t *testing.T) {
+	tests := []struct {
+		name         string
+		dryRun       bool
+		expectedMode string
+	}{
+		{name: "applies the previous spec", expectedMode: "Off"},
+		{name: "dry run", dryRun: true, expectedMode: "Auto"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			require.NoError(t, autoscalingv1.AddToScheme(scheme))
+			vm := historyVpaManager(t, "Auto", specRevision(t, 1, "Off"), specRevision(t, 2, "Auto"))
+			c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(vm).WithStatusSubresource(vm).Build()
+
+			revision, err := Rollback(context.Background(), c, "prod", 0, tt.dryRun)
+			require.NoError(t, err)
+			assert.Equal(t, int64(1), revision.Generation)
+
+			got := &autoscalingv1.VpaManager{}
+			require.NoError(t, c.Get(context.Background(), client.ObjectKey{Name: "prod"}, got))
+			assert.Equal(t, tt.expectedMode, got.Spec.UpdateMode)
+		})
+	}
+}
+
+func TestWriteSpecHistory(t *testing.T) {
+	failed := specRevision(t, 2, "Auto")
+	failed.LastError = "namespaces forbidden"
+	vm := historyVpaManager(t, "Auto", specRevision(t, 1, "Off"), failed)
+
+	var out bytes.Buffer
+	require.NoError(t, WriteSpecHistory(&out, vm))
+	assert.Equal(t, `GENERATION    HASH   FIRST RECONCILED       MANAGED VPAS   LAST ERROR
+1             Off    0001-01-01T00:00:00Z   3              <none>
+2 (current)   Auto   0001-01-01T00:00:00Z   3              namespaces forbidden
+`, out.String())
+}
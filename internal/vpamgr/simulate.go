@@ -0,0 +1,397 @@
+package vpamgr
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math/big"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	"github.com/joaomo/k8s_op_vpa/internal/vpa"
+	"github.com/joaomo/k8s_op_vpa/internal/workload"
+)
+
+// simulatedResources are the resources the VPA recommends
+var simulatedResources = []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory}
+
+// workloadAliases maps the kubectl names of workload kinds to their providers
+var workloadAliases = map[string]workload.Provider{
+	"deploy":       &workload.DeploymentProvider{},
+	"deployment":   &workload.DeploymentProvider{},
+	"deployments":  &workload.DeploymentProvider{},
+	"sts":          &workload.StatefulSetProvider{},
+	"statefulset":  &workload.StatefulSetProvider{},
+	"statefulsets": &workload.StatefulSetProvider{},
+	"ds":           &workload.DaemonSetProvider{},
+	"daemonset":    &workload.DaemonSetProvider{},
+	"daemonsets":   &workload.DaemonSetProvider{},
+}
+
+// Simulation is what applying the target recommendation of a workload's VPA would change
+type Simulation struct {
+	Kind      string
+	Namespace string
+	Name      string
+
+	// VPA is the VPA targeting the workload
+	VPA string
+
+	// Containers compares every container's resources with its recommendation
+	Containers []ContainerImpact
+
+	// PodSpec is the workload's pod spec with the recommendation applied
+	PodSpec *corev1.PodSpec
+
+	// CurrentRequests and AppliedRequests are the pod's effective requests before and after
+	CurrentRequests corev1.ResourceList
+	AppliedRequests corev1.ResourceList
+
+	// NodeSizes are the sizes of the schedulable nodes the pod may run on, largest first
+	NodeSizes []NodeFit
+}
+
+// ContainerImpact compares the resources of one container with its recommendation
+type ContainerImpact struct {
+	Name    string
+	Current corev1.ResourceRequirements
+	// Target is the recommendation, nil when the VPA has none for the container
+	Target  corev1.ResourceList
+	Applied corev1.ResourceRequirements
+}
+
+// NodeFit tells whether the pod fits on the nodes of one allocatable size
+type NodeFit struct {
+	Allocatable corev1.ResourceList
+	Nodes       int
+	CurrentFits bool
+	AppliedFits bool
+}
+
+// FitsAnyNode reports whether the pod fits on at least one node size once the
+// recommendation is applied, true when no node was found
+func (s *Simulation) FitsAnyNode() bool {
+	if len(s.NodeSizes) == 0 {
+		return true
+	}
+	for _, size := range s.NodeSizes {
+		if size.AppliedFits {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseWorkloadRef parses a kubectl style workload reference such as deploy/web
+func ParseWorkloadRef(ref string) (workload.Provider, string, error) {
+	kind, name, ok := strings.Cut(ref, "/")
+	if !ok || name == "" {
+		return nil, "", fmt.Errorf("workload %q is not of the form <kind>/<name>, e.g. deploy/web", ref)
+	}
+	provider, ok := workloadAliases[strings.ToLower(kind)]
+	if !ok {
+		return nil, "", fmt.Errorf("unsupported workload kind %q, use deploy, sts or ds", kind)
+	}
+	return provider, name, nil
+}
+
+// Simulate reads a workload, its VPA and the cluster's nodes and computes what applying the
+// VPA's target recommendation would change. The VPA is the one named by the workload's
+// vpa-operator.io/vpa annotation, else the first VPA targeting the workload.
+func Simulate(ctx context.Context, c client.Client, provider workload.Provider, namespace, name string) (*Simulation, error) {
+	wl, err := provider.Get(ctx, c, namespace, name)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s %s/%s: %w", provider.Kind(), namespace, name, err)
+	}
+	vpaObj, err := findVPA(ctx, c, wl)
+	if err != nil {
+		return nil, err
+	}
+
+	sim := &Simulation{Kind: wl.GetKind(), Namespace: namespace, Name: name, VPA: vpaObj.GetName()}
+	targets := vpa.Recommendations(vpaObj, vpa.RecommendationTarget)
+	podSpec := wl.GetPodTemplateSpec().Spec.DeepCopy()
+	for i := range podSpec.Containers {
+		container := &podSpec.Containers[i]
+		impact := ContainerImpact{
+			Name:    container.Name,
+			Current: *container.Resources.DeepCopy(),
+			Target:  targets[container.Name],
+		}
+		impact.Applied = applyRecommendation(container.Resources, impact.Target, containerPolicy(vpaObj, container.Name))
+		container.Resources = impact.Applied
+		sim.Containers = append(sim.Containers, impact)
+	}
+	sim.PodSpec = podSpec
+	sim.CurrentRequests = podRequests(&wl.GetPodTemplateSpec().Spec)
+	sim.AppliedRequests = podRequests(podSpec)
+
+	nodes := &corev1.NodeList{}
+	if err := c.List(ctx, nodes); err != nil {
+		return nil, fmt.Errorf("listing nodes: %w", err)
+	}
+	sim.NodeSizes = nodeFit(nodes.Items, podSpec.NodeSelector, sim.CurrentRequests, sim.AppliedRequests)
+	return sim, nil
+}
+
+// findVPA returns the VPA of a workload
+func findVPA(ctx context.Context, c client.Client, wl workload.Workload) (*unstructured.Unstructured, error) {
+	name := vpa.WorkloadVPAName(wl.GetObject().GetAnnotations()[vpa.WorkloadVPAAnnotation])
+	if name == "" {
+		targeting, err := vpa.TargetingVPAs(ctx, c, wl.GetNamespace(), wl.GetKind(), wl.GetName())
+		if err != nil {
+			return nil, fmt.Errorf("listing VPAs: %w", err)
+		}
+		if len(targeting) == 0 {
+			return nil, fmt.Errorf("no VPA targets %s %s/%s", wl.GetKind(), wl.GetNamespace(), wl.GetName())
+		}
+		name = targeting[0]
+	}
+
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion("autoscaling.k8s.io/v1")
+	obj.SetKind("VerticalPodAutoscaler")
+	if err := c.Get(ctx, types.NamespacedName{Namespace: wl.GetNamespace(), Name: name}, obj); err != nil {
+		return nil, fmt.Errorf("reading VPA %s/%s: %w", wl.GetNamespace(), name, err)
+	}
+	return obj, nil
+}
+
+// containerPolicy returns the VPA's container policy for a container, falling back to the
+// "*" policy, nil when there is neither
+func containerPolicy(vpaObj *unstructured.Unstructured, container string) map[string]interface{} {
+	policies, _, _ := unstructured.NestedSlice(vpaObj.Object, "spec", "resourcePolicy", "containerPolicies")
+	var wildcard map[string]interface{}
+	for _, p := range policies {
+		policy, ok := p.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		switch policy["containerName"] {
+		case container:
+			return policy
+		case "*":
+			wildcard = policy
+		}
+	}
+	return wildcard
+}
+
+// applyRecommendation returns the resources the VPA updater would give a container: requests
+// set to the target, and limits scaled to keep their ratio to the requests unless the policy
+// controls requests only. Resources outside the policy's controlledResources are kept.
+func applyRecommendation(current corev1.ResourceRequirements, target corev1.ResourceList, policy map[string]interface{}) corev1.ResourceRequirements {
+	applied := *current.DeepCopy()
+	if mode, _, _ := unstructured.NestedString(policy, "mode"); mode == "Off" {
+		return applied
+	}
+	controlled := simulatedResources
+	if names, found, _ := unstructured.NestedStringSlice(policy, "controlledResources"); found {
+		controlled = nil
+		for _, name := range names {
+			controlled = append(controlled, corev1.ResourceName(name))
+		}
+	}
+	controlledValues, _, _ := unstructured.NestedString(policy, "controlledValues")
+
+	for _, res := range controlled {
+		recommended, ok := target[res]
+		if !ok {
+			continue
+		}
+		request, hasRequest := current.Requests[res]
+		if applied.Requests == nil {
+			applied.Requests = corev1.ResourceList{}
+		}
+		applied.Requests[res] = recommended
+		limit, hasLimit := current.Limits[res]
+		if !hasLimit || controlledValues == "RequestsOnly" {
+			continue
+		}
+		if !hasRequest || request.IsZero() {
+			// Without a request the API server defaulted it to the limit
+			request = limit
+		}
+		applied.Limits[res] = scaleProportionally(res, limit, request, recommended)
+	}
+	return applied
+}
+
+// scaleProportionally returns limit * recommended / request, rounded down to millicores for
+// CPU and to whole units otherwise
+func scaleProportionally(res corev1.ResourceName, limit, request, recommended resource.Quantity) resource.Quantity {
+	if request.IsZero() {
+		return limit
+	}
+	scaled := new(big.Int).Mul(big.NewInt(limit.MilliValue()), big.NewInt(recommended.MilliValue()))
+	scaled.Quo(scaled, big.NewInt(request.MilliValue()))
+	if !scaled.IsInt64() {
+		return limit
+	}
+	if res == corev1.ResourceCPU {
+		return *resource.NewMilliQuantity(scaled.Int64(), limit.Format)
+	}
+	return *resource.NewQuantity(scaled.Int64()/1000, limit.Format)
+}
+
+// podRequests returns the effective requests of a pod: the sum of its containers, or the
+// largest init container when that is higher
+func podRequests(spec *corev1.PodSpec) corev1.ResourceList {
+	out := corev1.ResourceList{}
+	for _, res := range simulatedResources {
+		total := resource.Quantity{}
+		for _, c := range spec.Containers {
+			if q, ok := c.Resources.Requests[res]; ok {
+				total.Add(q)
+			}
+		}
+		for _, c := range spec.InitContainers {
+			if q, ok := c.Resources.Requests[res]; ok && q.Cmp(total) > 0 {
+				total = q.DeepCopy()
+			}
+		}
+		out[res] = total
+	}
+	return out
+}
+
+// nodeFit groups the schedulable nodes matching the pod's node selector by allocatable CPU
+// and memory and checks the pod's requests against each size. Other pods on the nodes are
+// not taken into account: the question is whether the pod fits the node sizes at all.
+func nodeFit(nodes []corev1.Node, nodeSelector map[string]string, current, applied corev1.ResourceList) []NodeFit {
+	selector := labels.SelectorFromSet(nodeSelector)
+	sizes := map[string]*NodeFit{}
+	for _, node := range nodes {
+		if node.Spec.Unschedulable || !selector.Matches(labels.Set(node.Labels)) {
+			continue
+		}
+		cpu, memory := node.Status.Allocatable[corev1.ResourceCPU], node.Status.Allocatable[corev1.ResourceMemory]
+		key := cpu.String() + "/" + memory.String()
+		if size, ok := sizes[key]; ok {
+			size.Nodes++
+			continue
+		}
+		allocatable := corev1.ResourceList{corev1.ResourceCPU: cpu, corev1.ResourceMemory: memory}
+		sizes[key] = &NodeFit{
+			Allocatable: allocatable,
+			Nodes:       1,
+			CurrentFits: fits(current, allocatable),
+			AppliedFits: fits(applied, allocatable),
+		}
+	}
+
+	out := make([]NodeFit, 0, len(sizes))
+	for _, size := range sizes {
+		out = append(out, *size)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		ci, cj := out[i].Allocatable[corev1.ResourceCPU], out[j].Allocatable[corev1.ResourceCPU]
+		if c := ci.Cmp(cj); c != 0 {
+			return c > 0
+		}
+		mi, mj := out[i].Allocatable[corev1.ResourceMemory], out[j].Allocatable[corev1.ResourceMemory]
+		return mi.Cmp(mj) > 0
+	})
+	return out
+}
+
+// fits reports whether requests fit in allocatable
+func fits(requests, allocatable corev1.ResourceList) bool {
+	for _, res := range simulatedResources {
+		request := requests[res]
+		available := allocatable[res]
+		if request.Cmp(available) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// WriteSimulation writes a simulation as a resource table, the resulting pod spec and the
+// node fit analysis
+func WriteSimulation(w io.Writer, sim *Simulation) error {
+	if _, err := fmt.Fprintf(w, "%s %s/%s, VPA %s\n\n", sim.Kind, sim.Namespace, sim.Name, sim.VPA); err != nil {
+		return err
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(tw, "CONTAINER\tRESOURCE\tREQUEST\tTARGET\tAPPLIED REQUEST\tLIMIT\tAPPLIED LIMIT")
+	for _, c := range sim.Containers {
+		for _, res := range simulatedResources {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", c.Name, res,
+				quantity(c.Current.Requests, res), quantity(c.Target, res), quantity(c.Applied.Requests, res),
+				quantity(c.Current.Limits, res), quantity(c.Applied.Limits, res))
+		}
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	podSpec, err := renderPodSpec(sim.PodSpec)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(w, "\nPod spec if applied:\n%s\n", podSpec); err != nil {
+		return err
+	}
+
+	if _, err := fmt.Fprintf(w, "Node fit (pod requests cpu %s -> %s, memory %s -> %s):\n",
+		quantity(sim.CurrentRequests, corev1.ResourceCPU), quantity(sim.AppliedRequests, corev1.ResourceCPU),
+		quantity(sim.CurrentRequests, corev1.ResourceMemory), quantity(sim.AppliedRequests, corev1.ResourceMemory)); err != nil {
+		return err
+	}
+	if len(sim.NodeSizes) == 0 {
+		_, err := fmt.Fprintln(w, "no schedulable nodes match the pod's node selector")
+		return err
+	}
+	tw = tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(tw, "NODES\tALLOCATABLE CPU\tALLOCATABLE MEMORY\tFITS NOW\tFITS IF APPLIED")
+	for _, size := range sim.NodeSizes {
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\n", size.Nodes,
+			quantity(size.Allocatable, corev1.ResourceCPU), quantity(size.Allocatable, corev1.ResourceMemory),
+			yesNo(size.CurrentFits), yesNo(size.AppliedFits))
+	}
+	return tw.Flush()
+}
+
+// renderPodSpec returns the YAML of the containers of a pod spec with their resources
+func renderPodSpec(spec *corev1.PodSpec) (string, error) {
+	containers := make([]map[string]interface{}, 0, len(spec.Containers))
+	for _, c := range spec.Containers {
+		containers = append(containers, map[string]interface{}{
+			"name":      c.Name,
+			"image":     c.Image,
+			"resources": c.Resources,
+		})
+	}
+	out, err := yaml.Marshal(map[string]interface{}{"containers": containers})
+	if err != nil {
+		return "", fmt.Errorf("rendering pod spec: %w", err)
+	}
+	return string(out), nil
+}
+
+// quantity formats one resource of a list, "-" when it is not set
+func quantity(list corev1.ResourceList, name corev1.ResourceName) string {
+	q, ok := list[name]
+	if !ok {
+		return "-"
+	}
+	return q.String()
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
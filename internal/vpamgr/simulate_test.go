@@ -0,0 +1,200 @@
+package vpamgr
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/joaomo/k8s_op_vpa/internal/vpa"
+)
+
+func resources(values ...string) corev1.ResourceList {
+	list := corev1.ResourceList{}
+	for i := 0; i < len(values); i += 2 {
+		list[corev1.ResourceName(values[i])] = resource.MustParse(values[i+1])
+	}
+	return list
+}
+
+func TestParseWorkloadRef(t *testing.T) {
+	tests := []struct {
+		ref          string
+		expectedKind string
+		expectedName string
+		expectedErr  bool
+	}{
+		{ref: "deploy/web", expectedKind: "Deployment", expectedName: "web"},
+		{ref: "StatefulSet/db", expectedKind: "StatefulSet", expectedName: "db"},
+		{ref: "ds/agent", expectedKind: "DaemonSet", expectedName: "agent"},
+		{ref: "web", expectedErr: true},
+		{ref: "deploy/", expectedErr: true},
+		{ref: "cronjob/backup", expectedErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ref, func(t *testing.T) {
+			provider, name, err := ParseWorkloadRef(tt.ref)
+			if tt.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedKind, provider.Kind())
+			assert.Equal(t, tt.expectedName, name)
+		})
+	}
+}
+
+func TestApplyRecommendation(t *testing.T) {
+	current := corev1.ResourceRequirements{
+		Requests: resources("cpu", "100m", "memory", "128Mi"),
+		Limits:   resources("cpu", "200m", "memory", "256Mi"),
+	}
+	target := resources("cpu", "250m", "memory", "64Mi")
+
+	tests := []struct {
+		name     string
+		policy   map[string]interface{}
+		expected corev1.ResourceRequirements
+	}{
+		{
+			name: "requests and limits keep their ratio",
+			expected: corev1.ResourceRequirements{
+				Requests: resources("cpu", "250m", "memory", "64Mi"),
+				Limits:   resources("cpu", "500m", "memory", "128Mi"),
+			},
+		},
+		{
+			name:   "requests only",
+			policy: map[string]interface{}{"containerName": "*", "controlledValues": "RequestsOnly"},
+			expected: corev1.ResourceRequirements{
+				Requests: resources("cpu", "250m", "memory", "64Mi"),
+				Limits:   resources("cpu", "200m", "memory", "256Mi"),
+			},
+		},
+		{
+			name:   "controlled resources",
+			policy: map[string]interface{}{"containerName": "app", "controlledResources": []interface{}{"cpu"}},
+			expected: corev1.ResourceRequirements{
+				Requests: resources("cpu", "250m", "memory", "128Mi"),
+				Limits:   resources("cpu", "500m", "memory", "256Mi"),
+			},
+		},
+		{
+			name:     "mode off",
+			policy:   map[string]interface{}{"containerName": "app", "mode": "Off"},
+			expected: current,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			applied := applyRecommendation(current, target, tt.policy)
+			for _, res := range simulatedResources {
+				assert.Equal(t, quantity(tt.expected.Requests, res), quantity(applied.Requests, res), "request %s", res)
+				assert.Equal(t, quantity(tt.expected.Limits, res), quantity(applied.Limits, res), "limit %s", res)
+			}
+		})
+	}
+}
+
+func TestSimulate(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, clientgoscheme.AddToScheme(scheme))
+
+	replicas := int32(2)
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "web",
+			Namespace:   "shop",
+			Annotations: map[string]string{vpa.WorkloadVPAAnnotation: "web-vpa,mode=Auto"},
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "web"}},
+				Spec: corev1.PodSpec{
+					NodeSelector: map[string]string{"pool": "general"},
+					Containers: []corev1.Container{{
+						Name:      "app",
+						Image:     "web:1.0",
+						Resources: corev1.ResourceRequirements{Requests: resources("cpu", "500m", "memory", "1Gi")},
+					}},
+				},
+			},
+		},
+	}
+	vpaObj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{
+			"targetRef": map[string]interface{}{"apiVersion": "apps/v1", "kind": "Deployment", "name": "web"},
+		},
+		"status": map[string]interface{}{
+			"recommendation": map[string]interface{}{
+				"containerRecommendations": []interface{}{
+					map[string]interface{}{"containerName": "app", "target": map[string]interface{}{"cpu": "3", "memory": "2Gi"}},
+				},
+			},
+		},
+	}}
+	vpaObj.SetAPIVersion("autoscaling.k8s.io/v1")
+	vpaObj.SetKind("VerticalPodAutoscaler")
+	vpaObj.SetName("web-vpa")
+	vpaObj.SetNamespace("shop")
+	node := func(name, pool, cpu, memory string, unschedulable bool) *corev1.Node {
+		return &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{"pool": pool}},
+			Spec:       corev1.NodeSpec{Unschedulable: unschedulable},
+			Status:     corev1.NodeStatus{Allocatable: resources("cpu", cpu, "memory", memory)},
+		}
+	}
+
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		deployment, vpaObj,
+		node("small-1", "general", "2", "8Gi", false),
+		node("small-2", "general", "2", "8Gi", false),
+		node("large-1", "general", "8", "32Gi", false),
+		node("cordoned", "general", "16", "64Gi", true),
+		node("gpu-1", "gpu", "16", "64Gi", false),
+	).Build()
+
+	provider, name, err := ParseWorkloadRef("deploy/web")
+	require.NoError(t, err)
+	sim, err := Simulate(context.Background(), c, provider, "shop", name)
+	require.NoError(t, err)
+
+	assert.Equal(t, "web-vpa", sim.VPA)
+	require.Len(t, sim.Containers, 1)
+	assert.Equal(t, "3", sim.PodSpec.Containers[0].Resources.Requests.Cpu().String())
+	assert.Equal(t, "2Gi", sim.PodSpec.Containers[0].Resources.Requests.Memory().String())
+	require.Len(t, sim.NodeSizes, 2, "cordoned nodes and nodes outside the node selector are left out")
+	sizes := []string{}
+	for _, size := range sim.NodeSizes {
+		sizes = append(sizes, fmt.Sprintf("%d x %s/%s fits now %t, if applied %t", size.Nodes,
+			quantity(size.Allocatable, corev1.ResourceCPU), quantity(size.Allocatable, corev1.ResourceMemory), size.CurrentFits, size.AppliedFits))
+	}
+	assert.Equal(t, []string{
+		"1 x 8/32Gi fits now true, if applied true",
+		"2 x 2/8Gi fits now true, if applied false",
+	}, sizes)
+	assert.True(t, sim.FitsAnyNode())
+
+	var out bytes.Buffer
+	require.NoError(t, WriteSimulation(&out, sim))
+	assert.Contains(t, out.String(), "Deployment shop/web, VPA web-vpa")
+	assert.Contains(t, out.String(), "Node fit (pod requests cpu 500m -> 3, memory 1Gi -> 2Gi):")
+	assert.Regexp(t, `app\s+cpu\s+500m\s+3\s+3\s+-\s+-`, out.String())
+	assert.Regexp(t, `2\s+2\s+8Gi\s+yes\s+no`, out.String())
+}
@@ -0,0 +1,93 @@
+package webhook
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	"github.com/joaomo/k8s_op_vpa/internal/metrics"
+)
+
+// DefaultCertRenewalWindow is how long before expiry the serving certificate counts as
+// expiring. It is shorter than cert-manager's default renewBefore of 30 days, so a
+// certificate inside the window is one whose rotation did not happen.
+const DefaultCertRenewalWindow = 14 * 24 * time.Hour
+
+// DefaultCertExpiryInterval is how often the CertExpiryMonitor re-reads the certificate
+const DefaultCertExpiryInterval = time.Minute
+
+// CertExpiryMonitor exports the expiry of the webhook serving certificate and reports when
+// it is within the renewal window. The certificate is re-read on every check, so rotated
+// certificates are picked up like the webhook server's own certificate watcher does.
+type CertExpiryMonitor struct {
+	// CertFile is the serving certificate
+	CertFile string
+
+	Metrics *metrics.Metrics
+
+	// RenewalWindow is how long before expiry the certificate is expiring,
+	// DefaultCertRenewalWindow when zero
+	RenewalWindow time.Duration
+
+	// Interval is how often the certificate is checked, DefaultCertExpiryInterval when zero
+	Interval time.Duration
+
+	mu       sync.Mutex
+	notAfter time.Time
+}
+
+// NeedLeaderElection runs the monitor on every replica, each serves its own certificate
+func (m *CertExpiryMonitor) NeedLeaderElection() bool {
+	return false
+}
+
+// Start checks the certificate until ctx is cancelled
+func (m *CertExpiryMonitor) Start(ctx context.Context) error {
+	log := ctrl.LoggerFrom(ctx).WithName("webhook-cert-expiry").WithValues("certFile", m.CertFile)
+
+	interval := m.Interval
+	if interval == 0 {
+		interval = DefaultCertExpiryInterval
+	}
+
+	wait.UntilWithContext(ctx, func(ctx context.Context) {
+		if err := m.check(); err != nil {
+			log.V(1).Info("cannot read serving certificate", "reason", err.Error())
+		}
+	}, interval)
+	return nil
+}
+
+// check reads the certificate and records its expiry
+func (m *CertExpiryMonitor) check() error {
+	cert, err := loadCertificate(m.CertFile)
+	if err != nil {
+		return err
+	}
+	m.mu.Lock()
+	m.notAfter = cert.NotAfter
+	m.mu.Unlock()
+	if m.Metrics != nil {
+		m.Metrics.SetWebhookCertExpiry(cert.NotAfter)
+	}
+	return nil
+}
+
+// Expiring returns the expiry of the certificate and whether it is within the renewal
+// window at now. It reports false until the certificate has been read.
+func (m *CertExpiryMonitor) Expiring(now time.Time) (time.Time, bool) {
+	m.mu.Lock()
+	notAfter := m.notAfter
+	m.mu.Unlock()
+	if notAfter.IsZero() {
+		return notAfter, false
+	}
+	window := m.RenewalWindow
+	if window == 0 {
+		window = DefaultCertRenewalWindow
+	}
+	return notAfter, now.Add(window).After(notAfter)
+}
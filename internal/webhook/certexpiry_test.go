@@ -0,0 +1,57 @@
+package webhook
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/joaomo/k8s_op_vpa/internal/metrics"
+)
+
+func TestCertExpiryMonitor_Expiring(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+
+	tests := []struct {
+		name          string
+		notAfter      time.Time
+		renewalWindow time.Duration
+		expiring      bool
+	}{
+		{name: "outside default window", notAfter: now.Add(30 * 24 * time.Hour)},
+		{name: "inside default window", notAfter: now.Add(7 * 24 * time.Hour), expiring: true},
+		{name: "outside custom window", notAfter: now.Add(7 * 24 * time.Hour), renewalWindow: 24 * time.Hour},
+		{name: "expired", notAfter: now.Add(-time.Hour), expiring: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := metrics.NewMetrics(prometheus.NewRegistry())
+			monitor := &CertExpiryMonitor{
+				CertFile:      writeCertificate(t, t.TempDir(), now.Add(-time.Hour), tt.notAfter),
+				Metrics:       m,
+				RenewalWindow: tt.renewalWindow,
+			}
+
+			_, expiring := monitor.Expiring(now)
+			assert.False(t, expiring, "nothing is reported before the certificate is read")
+
+			require.NoError(t, monitor.check())
+			notAfter, expiring := monitor.Expiring(now)
+			assert.Equal(t, tt.expiring, expiring)
+			assert.True(t, tt.notAfter.Equal(notAfter))
+			assert.Equal(t, float64(tt.notAfter.Unix()), testutil.ToFloat64(m.WebhookCertExpiry))
+		})
+	}
+}
+
+func TestCertExpiryMonitor_MissingCertificate(t *testing.T) {
+	monitor := &CertExpiryMonitor{CertFile: "/nonexistent/tls.crt"}
+
+	assert.Error(t, monitor.check())
+	_, expiring := monitor.Expiring(time.Now())
+	assert.False(t, expiring)
+}
@@ -0,0 +1,21 @@
+package webhook
+
+import (
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/joaomo/k8s_op_vpa/internal/vpa"
+)
+
+// vpaInputsChanged reports whether an update of a workload can change its VPA. The VPA is
+// generated from the workload's spec, labels and annotations; updates that change none of
+// them, such as status updates when the webhook is registered broadly, are skipped. A spec
+// change bumps metadata.generation. Objects without a generation are always processed. The
+// operator's own vpa.WorkloadVPAAnnotation is ignored.
+func vpaInputsChanged(oldObj, newObj metav1.Object) bool {
+	if oldObj.GetGeneration() == 0 || oldObj.GetGeneration() != newObj.GetGeneration() {
+		return true
+	}
+	return !equality.Semantic.DeepEqual(oldObj.GetLabels(), newObj.GetLabels()) ||
+		!vpa.WorkloadAnnotationsEqual(oldObj.GetAnnotations(), newObj.GetAnnotations())
+}
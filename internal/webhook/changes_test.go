@@ -0,0 +1,72 @@
+package webhook
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/joaomo/k8s_op_vpa/internal/vpa"
+)
+
+func TestVpaInputsChanged(t *testing.T) {
+	base := func() *metav1.ObjectMeta {
+		return &metav1.ObjectMeta{
+			Generation:  3,
+			Labels:      map[string]string{"app": "web"},
+			Annotations: map[string]string{"team": "shop"},
+		}
+	}
+
+	tests := []struct {
+		name     string
+		mutate   func(oldObj, newObj *metav1.ObjectMeta)
+		expected bool
+	}{
+		{
+			name:     "status only",
+			mutate:   func(_, _ *metav1.ObjectMeta) {},
+			expected: false,
+		},
+		{
+			name:     "unrelated metadata",
+			mutate:   func(_, newObj *metav1.ObjectMeta) { newObj.ResourceVersion = "43" },
+			expected: false,
+		},
+		{
+			name:     "spec changed",
+			mutate:   func(_, newObj *metav1.ObjectMeta) { newObj.Generation = 4 },
+			expected: true,
+		},
+		{
+			name:     "label changed",
+			mutate:   func(_, newObj *metav1.ObjectMeta) { newObj.Labels["vpa-enabled"] = "true" },
+			expected: true,
+		},
+		{
+			name:     "annotation removed",
+			mutate:   func(_, newObj *metav1.ObjectMeta) { newObj.Annotations = nil },
+			expected: true,
+		},
+		{
+			name: "operator annotation only",
+			mutate: func(_, newObj *metav1.ObjectMeta) {
+				newObj.Annotations[vpa.WorkloadVPAAnnotation] = "web-vpa,mode=Auto"
+			},
+			expected: false,
+		},
+		{
+			name:     "no generation",
+			mutate:   func(oldObj, newObj *metav1.ObjectMeta) { oldObj.Generation, newObj.Generation = 0, 0 },
+			expected: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			oldObj, newObj := base(), base()
+			tt.mutate(oldObj, newObj)
+			assert.Equal(t, tt.expected, vpaInputsChanged(oldObj, newObj))
+		})
+	}
+}
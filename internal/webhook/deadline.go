@@ -0,0 +1,28 @@
+package webhook
+
+import (
+	"context"
+	"time"
+)
+
+// DefaultClientTimeout bounds all client calls made while handling one admission request
+const DefaultClientTimeout = 3 * time.Second
+
+// deadlineHeadroom is the share of the remaining admission deadline kept for building the response
+const deadlineHeadroom = 0.2
+
+// clientContext derives the context for client calls of one admission request. It expires
+// after timeout, or earlier when the request itself carries a closer deadline, so a slow
+// API server cannot push admission latency over the webhook timeout.
+func clientContext(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		timeout = DefaultClientTimeout
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		remaining := time.Duration(float64(time.Until(deadline)) * (1 - deadlineHeadroom))
+		if remaining < timeout {
+			timeout = remaining
+		}
+	}
+	return context.WithTimeout(ctx, timeout)
+}
@@ -0,0 +1,66 @@
+package webhook
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientContext(t *testing.T) {
+	tests := []struct {
+		name           string
+		requestTimeout time.Duration
+		clientTimeout  time.Duration
+		wantAtMost     time.Duration
+		wantAtLeast    time.Duration
+	}{
+		{
+			name:        "default timeout without request deadline",
+			wantAtMost:  DefaultClientTimeout,
+			wantAtLeast: DefaultClientTimeout - time.Second,
+		},
+		{
+			name:          "configured timeout without request deadline",
+			clientTimeout: 500 * time.Millisecond,
+			wantAtMost:    500 * time.Millisecond,
+			wantAtLeast:   400 * time.Millisecond,
+		},
+		{
+			name:           "request deadline closer than timeout leaves headroom",
+			requestTimeout: time.Second,
+			clientTimeout:  5 * time.Second,
+			wantAtMost:     800 * time.Millisecond,
+			wantAtLeast:    700 * time.Millisecond,
+		},
+		{
+			name:           "timeout closer than request deadline",
+			requestTimeout: 10 * time.Second,
+			clientTimeout:  time.Second,
+			wantAtMost:     time.Second,
+			wantAtLeast:    900 * time.Millisecond,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parent := context.Background()
+			if tt.requestTimeout > 0 {
+				var cancel context.CancelFunc
+				parent, cancel = context.WithTimeout(parent, tt.requestTimeout)
+				defer cancel()
+			}
+
+			ctx, cancel := clientContext(parent, tt.clientTimeout)
+			defer cancel()
+
+			deadline, ok := ctx.Deadline()
+			require.True(t, ok)
+			remaining := time.Until(deadline)
+			assert.LessOrEqual(t, remaining, tt.wantAtMost)
+			assert.GreaterOrEqual(t, remaining, tt.wantAtLeast)
+		})
+	}
+}
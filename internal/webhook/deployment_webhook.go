@@ -21,7 +21,14 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 
 	autoscalingv1 "github.com/joaomo/k8s_op_vpa/api/v1"
+	"github.com/joaomo/k8s_op_vpa/internal/decisions"
+	"github.com/joaomo/k8s_op_vpa/internal/inheritance"
+	"github.com/joaomo/k8s_op_vpa/internal/labelselector"
 	"github.com/joaomo/k8s_op_vpa/internal/metrics"
+	"github.com/joaomo/k8s_op_vpa/internal/policy"
+	"github.com/joaomo/k8s_op_vpa/internal/validation"
+	"github.com/joaomo/k8s_op_vpa/internal/vpa"
+	"github.com/joaomo/k8s_op_vpa/internal/workload"
 )
 
 var (
@@ -38,6 +45,44 @@ type DeploymentWebhookHandler struct {
 	Scheme  *runtime.Scheme
 	Metrics *metrics.Metrics
 	decoder *admission.Decoder
+
+	// StrictSelectors makes omitted selectors match nothing unless the matching
+	// matchAll flag is set, mirroring the reconciler
+	StrictSelectors bool
+
+	// ClientTimeout bounds client calls per admission request, DefaultClientTimeout when zero
+	ClientTimeout time.Duration
+
+	// Self is the operator's own workload, which never gets a VPA; nil disables the check
+	Self *workload.Self
+
+	// SelectorDefaults fills in the selectors a VpaManager omits, like the reconciler; nil
+	// leaves them omitted
+	SelectorDefaults *policy.SelectorDefaults
+
+	// Bootstrapped are the VPA components with a bootstrap VPA, which get no other VPA; nil
+	// disables the check
+	Bootstrapped *workload.Bootstrapped
+
+	// Ownership is the label that marks this instance's VPAs; VPAs without it are never deleted
+	Ownership vpa.Ownership
+
+	// RateLimiter bounds VPA writes per namespace; writes over the limit are left to the
+	// reconciler. nil allows every write.
+	RateLimiter *NamespaceRateLimiter
+
+	// Capacity caps the VPAs managed across the cluster; nil creates VPAs without a cap
+	Capacity *vpa.ClusterCapacity
+
+	// Selectors caches compiled label selectors per VpaManager generation; nil compiles them per request
+	Selectors *labelselector.Cache
+
+	// TopologyGuard turns VPAs Off while pods they evict could not be rescheduled because
+	// of required pod anti-affinity, like the reconciler
+	TopologyGuard bool
+
+	// Decisions keeps the recent per-workload decisions for debugging; nil records nothing
+	Decisions *decisions.Log
 }
 
 // Handle implements the admission.Handler interface
@@ -45,21 +90,37 @@ func (h *DeploymentWebhookHandler) Handle(ctx context.Context, req admission.Req
 	start := time.Now()
 	log := ctrl.LoggerFrom(ctx).WithValues("webhook", "deployment", "operation", req.Operation)
 
+	// vpaManagerName attributes the request to the VpaManager it matched, if any
+	var vpaManagerName string
 	var err error
 	defer func() {
-		h.Metrics.RecordWebhookRequest(string(req.Operation), start, err)
+		h.Metrics.RecordWebhookRequest(string(req.Operation), vpaManagerName, start, err)
 	}()
 
+	// The webhook is registered with sideEffects NoneOnDryRun, so dry runs must not touch VPAs
+	if req.DryRun != nil && *req.DryRun {
+		return admission.Allowed("deployment dry run, no VPA changes")
+	}
+
+	ctx, cancel := clientContext(ctx, h.ClientTimeout)
+	defer cancel()
+	// VPAs written for this request are stamped with its UID
+	ctx = vpa.WithReconcileID(ctx, string(req.UID))
+
 	switch req.Operation {
 	case admissionv1.Create:
-		err = h.handleCreate(ctx, req)
+		vpaManagerName, err = h.handleCreate(ctx, req)
 	case admissionv1.Update:
-		err = h.handleUpdate(ctx, req)
+		vpaManagerName, err = h.handleUpdate(ctx, req)
 	case admissionv1.Delete:
-		err = h.handleDelete(ctx, req)
+		vpaManagerName, err = h.handleDelete(ctx, req)
 	}
 
-	if err != nil {
+	if err != nil && ctx.Err() == context.DeadlineExceeded {
+		// The reconciler will converge the VPA, admission latency matters more
+		log.Info("webhook client calls exceeded their deadline, allowing without VPA changes", "error", err.Error())
+		h.Metrics.RecordWebhookTimeout("deployment", vpaManagerName)
+	} else if err != nil {
 		log.Error(err, "webhook handler error")
 		// Still allow the deployment operation, just log the error
 	}
@@ -67,109 +128,168 @@ func (h *DeploymentWebhookHandler) Handle(ctx context.Context, req admission.Req
 	return admission.Allowed("deployment processed")
 }
 
-// handleCreate handles deployment creation
-func (h *DeploymentWebhookHandler) handleCreate(ctx context.Context, req admission.Request) error {
+// handleCreate handles deployment creation and returns the name of the matching VpaManager
+func (h *DeploymentWebhookHandler) handleCreate(ctx context.Context, req admission.Request) (string, error) {
 	deployment := &appsv1.Deployment{}
 	if err := json.Unmarshal(req.Object.Raw, deployment); err != nil {
-		return fmt.Errorf("failed to decode deployment: %w", err)
+		return "", fmt.Errorf("failed to decode deployment: %w", err)
 	}
 
 	// Find matching VpaManager
 	vpaManager, err := h.findMatchingVpaManager(ctx, deployment)
 	if err != nil {
-		return err
+		return "", err
 	}
 	if vpaManager == nil {
-		return nil // No matching VpaManager
+		return "", nil // No matching VpaManager
 	}
 
 	// Create VPA for this deployment
-	vpaName := fmt.Sprintf("%s-vpa", deployment.Name)
+	vpaName, err := vpaNameFor(vpaManager, "Deployment", deployment)
+	if err != nil {
+		return vpaManager.Name, err
+	}
 	if err := h.createVPA(ctx, vpaManager, deployment, vpaName); err != nil {
-		return err
+		return vpaManager.Name, err
 	}
 
-	h.Metrics.RecordVPAOperation("create", vpaManager.Name)
-	return nil
+	h.scope(vpaManager.Name, deployment.Namespace).RecordVPAOperation("create")
+	h.recordDecision(vpaManager.Name, deployment, vpaName, decisions.ActionCreated, "Selected")
+	return vpaManager.Name, nil
 }
 
-// handleUpdate handles deployment updates
-func (h *DeploymentWebhookHandler) handleUpdate(ctx context.Context, req admission.Request) error {
+// handleUpdate handles deployment updates and returns the name of the VpaManager that now
+// matches, or previously matched when none does anymore
+func (h *DeploymentWebhookHandler) handleUpdate(ctx context.Context, req admission.Request) (string, error) {
 	newDeployment := &appsv1.Deployment{}
 	if err := json.Unmarshal(req.Object.Raw, newDeployment); err != nil {
-		return fmt.Errorf("failed to decode new deployment: %w", err)
+		return "", fmt.Errorf("failed to decode new deployment: %w", err)
 	}
 
 	oldDeployment := &appsv1.Deployment{}
 	if err := json.Unmarshal(req.OldObject.Raw, oldDeployment); err != nil {
-		return fmt.Errorf("failed to decode old deployment: %w", err)
+		return "", fmt.Errorf("failed to decode old deployment: %w", err)
+	}
+
+	// Updates that leave spec, labels and annotations alone cannot change the VPA
+	if !vpaInputsChanged(oldDeployment, newDeployment) {
+		h.Metrics.RecordWebhookUpdateSkipped("deployment")
+		return "", nil
 	}
 
 	// Check if deployment now matches a VpaManager
 	newVpaManager, err := h.findMatchingVpaManager(ctx, newDeployment)
 	if err != nil {
-		return err
+		return "", err
 	}
 
 	// Check if deployment previously matched
 	oldVpaManager, err := h.findMatchingVpaManager(ctx, oldDeployment)
 	if err != nil {
-		return err
+		return "", err
 	}
 
-	vpaName := fmt.Sprintf("%s-vpa", newDeployment.Name)
+	vpaManagerName := ""
+	if newVpaManager != nil {
+		vpaManagerName = newVpaManager.Name
+	} else if oldVpaManager != nil {
+		vpaManagerName = oldVpaManager.Name
+	}
 
 	// Handle state transitions
 	if oldVpaManager == nil && newVpaManager != nil {
 		// Deployment now matches - create VPA
+		vpaName, err := vpaNameFor(newVpaManager, "Deployment", newDeployment)
+		if err != nil {
+			return vpaManagerName, err
+		}
 		if err := h.createVPA(ctx, newVpaManager, newDeployment, vpaName); err != nil {
-			return err
+			return vpaManagerName, err
 		}
-		h.Metrics.RecordVPAOperation("create", newVpaManager.Name)
+		h.scope(newVpaManager.Name, newDeployment.Namespace).RecordVPAOperation("create")
+		h.recordDecision(newVpaManager.Name, newDeployment, vpaName, decisions.ActionCreated, "Selected")
 	} else if oldVpaManager != nil && newVpaManager == nil {
 		// Deployment no longer matches - delete VPA
-		if err := h.deleteVPA(ctx, newDeployment.Namespace, vpaName); err != nil {
-			return err
+		vpaName, err := vpaNameFor(oldVpaManager, "Deployment", newDeployment)
+		if err != nil {
+			return vpaManagerName, err
 		}
-		h.Metrics.RecordVPAOperation("delete", oldVpaManager.Name)
+		if err := h.deleteVPA(ctx, oldVpaManager.Name, newDeployment.Namespace, vpaName); err != nil {
+			return vpaManagerName, err
+		}
+		h.scope(oldVpaManager.Name, newDeployment.Namespace).RecordVPAOperation("delete")
+		h.recordDecision(oldVpaManager.Name, newDeployment, vpaName, decisions.ActionDeleted, "NoLongerSelected")
 	} else if newVpaManager != nil {
 		// Still matches - update VPA if needed
+		vpaName, err := vpaNameFor(newVpaManager, "Deployment", newDeployment)
+		if err != nil {
+			return vpaManagerName, err
+		}
 		if err := h.updateVPA(ctx, newVpaManager, newDeployment, vpaName); err != nil {
-			return err
+			return vpaManagerName, err
 		}
 	}
 
-	return nil
+	return vpaManagerName, nil
 }
 
-// handleDelete handles deployment deletion
-func (h *DeploymentWebhookHandler) handleDelete(ctx context.Context, req admission.Request) error {
+// handleDelete handles deployment deletion and returns the name of the matching VpaManager
+func (h *DeploymentWebhookHandler) handleDelete(ctx context.Context, req admission.Request) (string, error) {
 	deployment := &appsv1.Deployment{}
 	if err := json.Unmarshal(req.OldObject.Raw, deployment); err != nil {
-		return fmt.Errorf("failed to decode deployment: %w", err)
+		return "", fmt.Errorf("failed to decode deployment: %w", err)
 	}
 
 	// Only delete VPA if deployment was managed by an enabled VpaManager
 	vpaManager, err := h.findMatchingVpaManager(ctx, deployment)
 	if err != nil {
-		return err
+		return "", err
 	}
 	if vpaManager == nil {
-		return nil // No enabled manager, skip deletion
+		return "", nil // No enabled manager, skip deletion
 	}
 
 	// Delete the VPA for this deployment
-	vpaName := fmt.Sprintf("%s-vpa", deployment.Name)
-	if err := h.deleteVPA(ctx, deployment.Namespace, vpaName); err != nil {
-		return err
+	vpaName, err := vpaNameFor(vpaManager, "Deployment", deployment)
+	if err != nil {
+		return vpaManager.Name, err
+	}
+	if err := h.deleteVPA(ctx, vpaManager.Name, deployment.Namespace, vpaName); err != nil {
+		return vpaManager.Name, err
 	}
 
-	h.Metrics.RecordVPAOperation("delete", vpaManager.Name)
-	return nil
+	h.scope(vpaManager.Name, deployment.Namespace).RecordVPAOperation("delete")
+	h.recordDecision(vpaManager.Name, deployment, vpaName, decisions.ActionDeleted, "WorkloadDeleted")
+	return vpaManager.Name, nil
+}
+
+// scope returns the metrics scope of a Deployment in namespace managed by a VpaManager
+func (h *DeploymentWebhookHandler) scope(vpaManagerName, namespace string) metrics.Scope {
+	return h.Metrics.For(vpaManagerName).WithKind("Deployment").WithNamespace(namespace)
+}
+
+// recordDecision records the decision made about the VPA of a Deployment
+func (h *DeploymentWebhookHandler) recordDecision(vpaManagerName string, obj client.Object, vpaName, action, reason string) {
+	h.Decisions.Record(decisions.Decision{
+		Source:     decisions.SourceWebhook,
+		VpaManager: vpaManagerName,
+		Kind:       "Deployment",
+		Namespace:  obj.GetNamespace(),
+		Name:       obj.GetName(),
+		VPA:        vpaName,
+		Action:     action,
+		Reason:     reason,
+	})
 }
 
-// findMatchingVpaManager finds a VpaManager that matches the deployment
+// findMatchingVpaManager finds the VpaManager of the highest priority that matches the
+// deployment, none in a paused namespace
 func (h *DeploymentWebhookHandler) findMatchingVpaManager(ctx context.Context, deployment *appsv1.Deployment) (*autoscalingv1.VpaManager, error) {
+	if h.Self.MatchesObject("Deployment", deployment.Namespace, deployment.Name) || h.Bootstrapped.MatchesObject("Deployment", deployment.Namespace, deployment.Name) ||
+		workload.Excluded(deployment) {
+		return nil, nil
+	}
+
 	vpaManagerList := &autoscalingv1.VpaManagerList{}
 	if err := h.Client.List(ctx, vpaManagerList); err != nil {
 		return nil, err
@@ -180,35 +300,65 @@ func (h *DeploymentWebhookHandler) findMatchingVpaManager(ctx context.Context, d
 	if err := h.Client.Get(ctx, types.NamespacedName{Name: deployment.Namespace}, namespace); err != nil {
 		return nil, err
 	}
+	// VPAs in paused namespaces are left alone until the namespace is resumed
+	if workload.NamespacePaused(namespace) {
+		return nil, nil
+	}
 
+	// The matching VpaManager of the highest priority manages the workload
+	autoscalingv1.SortByPriority(vpaManagerList.Items)
 	for _, vm := range vpaManagerList.Items {
 		if !vm.Spec.Enabled {
 			continue
 		}
 
-		// Check namespace selector
-		if !h.matchesSelector(namespace.Labels, vm.Spec.NamespaceSelector) {
+		// Managers with a broken inheritFrom chain or profile are skipped, the reconciler reports them
+		spec, err := inheritance.ResolveSpec(ctx, h.Client, &vm)
+		if err != nil {
+			continue
+		}
+		// So are invalid specs, which the reconciler refuses to act on
+		errs := validation.ValidateVpaManagerSpec(spec)
+		errs = append(errs, validation.ValidateTenantScope(&vm)...)
+		if len(errs) > 0 {
+			continue
+		}
+		spec = h.SelectorDefaults.Apply(spec)
+
+		if !validation.NamespaceInTenant(&vm, namespace) {
+			continue
+		}
+
+		// Check namespace lists and selectors
+		if excludedByLabels(h.Selectors, &vm, spec, namespace.Labels) || !selectsNamespace(spec, namespace.Name, func() bool {
+			return h.matchesOptionalSelector(&vm, "namespaceSelector", namespace.Labels, spec.NamespaceSelectorTerms())
+		}) {
 			continue
 		}
 
 		// Check deployment selector
-		if !h.matchesSelector(deployment.Labels, vm.Spec.DeploymentSelector) {
+		if !spec.MatchAllWorkloads && !h.matchesOptionalSelector(&vm, "Deployment", deployment.Labels, spec.WorkloadSelectorTerms("Deployment")) {
+			continue
+		}
+		if !matchesWorkloadName(spec, deployment.Name) {
 			continue
 		}
 
-		return &vm, nil
+		resolved := vm.DeepCopy()
+		resolved.Spec = *spec
+		return resolved, nil
 	}
 
 	return nil, nil
 }
 
-// matchesSelector checks if labels match a selector
-func (h *DeploymentWebhookHandler) matchesSelector(objLabels map[string]string, selector *metav1.LabelSelector) bool {
-	if selector == nil {
-		return true
+// matchesOptionalSelector checks labels against the selectors in field of vm, of which any
+// must match; omitted selectors match everything unless StrictSelectors is set
+func (h *DeploymentWebhookHandler) matchesOptionalSelector(vm *autoscalingv1.VpaManager, field string, objLabels map[string]string, selectors []*metav1.LabelSelector) bool {
+	if len(selectors) == 0 {
+		return !h.StrictSelectors
 	}
-
-	labelSelector, err := metav1.LabelSelectorAsSelector(selector)
+	labelSelector, err := h.Selectors.CompileAny(vm.Name, vm.Generation, field, selectors)
 	if err != nil {
 		return false
 	}
@@ -230,8 +380,39 @@ func (h *DeploymentWebhookHandler) createVPA(ctx context.Context, vpaManager *au
 		return err
 	}
 
-	vpa := h.buildVPA(vpaManager, deployment, vpaName)
-	return h.Client.Create(ctx, vpa)
+	resolved, err := policy.Resolve(ctx, h.Client, vpaManager, "Deployment", deployment, &deployment.Spec.Template)
+	if err != nil {
+		return err
+	}
+	vpaManager = resolved.VpaManager
+	vpaObj := h.buildVPA(vpaManager, deployment, vpaName)
+	spec, _ := vpaObj.Object["spec"].(map[string]interface{})
+	vpa.SetManagedContainerPolicies(vpaObj, vpa.ContainerPolicyNames(spec))
+	vpa.ApplyTraceAnnotations(vpaObj, vpa.TraceAnnotations(deployment, vpaManager.Spec.PropagateAnnotations))
+	vpa.ApplyTuningAnnotations(vpaObj, vpaManager.Spec.RecommendationTuning)
+	vpa.ApplyCustomMetadata(vpaObj, vpaManager.Spec.VPALabels, vpaManager.Spec.VPAAnnotations, h.Ownership)
+	vpa.ApplyDormancy(vpaObj, spec, workload.ScaledToZero(&workload.DeploymentWorkload{Deployment: deployment}), vpaManager.Spec.Dormancy, time.Now())
+	if err := applyEvictionGuard(ctx, h.Client, h.TopologyGuard, vpaObj, spec, &workload.DeploymentWorkload{Deployment: deployment}); err != nil {
+		return err
+	}
+	layer, source := resolved.UpdateModeSource(vpaObj)
+	vpa.RecordUpdateModeChange(vpaObj, "", vpa.UpdateMode(spec), string(layer), source, time.Now())
+	if !allowWrite(h.RateLimiter, h.Metrics, "deployment", deployment.Namespace, vpaManager.Name) {
+		return nil
+	}
+	// Over the cluster-wide cap the VPA is not created; the reconciler reports it
+	allowed, err := h.Capacity.Reserve(ctx, h.Client)
+	if err != nil || !allowed {
+		return err
+	}
+	writeStart := time.Now()
+	vpa.StampReconcile(ctx, vpaObj, writeStart)
+	err = h.Client.Create(ctx, vpaObj)
+	h.scope(vpaManager.Name, deployment.Namespace).ObserveVPAWrite("create", writeStart)
+	if err != nil {
+		h.Capacity.Release()
+	}
+	return err
 }
 
 // updateVPA updates a VPA for a deployment
@@ -247,20 +428,87 @@ func (h *DeploymentWebhookHandler) updateVPA(ctx context.Context, vpaManager *au
 		return err
 	}
 
+	// Leave a VPA being deleted alone, the reconciler recreates it once it is gone
+	if existing.GetDeletionTimestamp() != nil {
+		return nil
+	}
+
+	// Leave VPAs of another operator instance alone, the reconciler reports the conflict
+	if h.Ownership.Foreign(existing) {
+		return nil
+	}
+
+	// Leave VPAs owned by a GitOps controller alone, the reconciler reports drift
+	if vpa.GitOpsOwner(existing) != "" {
+		return nil
+	}
+
 	// Update VPA spec
+	resolved, err := policy.Resolve(ctx, h.Client, vpaManager, "Deployment", deployment, &deployment.Spec.Template)
+	if err != nil {
+		return err
+	}
+	vpaManager = resolved.VpaManager
 	newVPA := h.buildVPA(vpaManager, deployment, vpaName)
-	existing.Object["spec"] = newVPA.Object["spec"]
-	return h.Client.Update(ctx, existing)
+	newSpec, _ := newVPA.Object["spec"].(map[string]interface{})
+	existingSpec, _ := existing.Object["spec"].(map[string]interface{})
+	strategy := vpaManager.Spec.ContainerPolicyMerge
+	// A VPA left behind by a deleted deployment of the same name is regenerated for this one
+	if vpa.StaleTarget(existing, "Deployment", deployment.Name, deployment.UID) {
+		strategy = autoscalingv1.ContainerPolicyMergeOperatorWins
+		vpa.ReplaceOwnerReferences(existing, newVPA.GetOwnerReferences())
+	}
+	managed := vpa.ManagedContainerPolicies(existing, existingSpec)
+	spec, written := vpa.MergeContainerPolicies(strategy, newSpec, existingSpec, managed)
+	vpa.ApplyDormancy(existing, spec, workload.ScaledToZero(&workload.DeploymentWorkload{Deployment: deployment}), vpaManager.Spec.Dormancy, time.Now())
+	if err := applyEvictionGuard(ctx, h.Client, h.TopologyGuard, existing, spec, &workload.DeploymentWorkload{Deployment: deployment}); err != nil {
+		return err
+	}
+	previousMode, desiredMode := vpa.UpdateMode(existingSpec), vpa.UpdateMode(spec)
+	modeLayer, modeSource := resolved.UpdateModeSource(existing)
+	modeChanged := vpa.RecordUpdateModeChange(existing, previousMode, desiredMode, string(modeLayer), modeSource, time.Now())
+	existing.Object["spec"] = spec
+	vpa.SetManagedContainerPolicies(existing, written)
+	vpa.ApplyTraceAnnotations(existing, vpa.TraceAnnotations(deployment, vpaManager.Spec.PropagateAnnotations))
+	vpa.ApplyTuningAnnotations(existing, vpaManager.Spec.RecommendationTuning)
+	vpa.ApplyCustomMetadata(existing, vpaManager.Spec.VPALabels, vpaManager.Spec.VPAAnnotations, h.Ownership)
+	if !allowWrite(h.RateLimiter, h.Metrics, "deployment", deployment.Namespace, vpaManager.Name) {
+		return nil
+	}
+	writeStart := time.Now()
+	vpa.StampReconcile(ctx, existing, writeStart)
+	err = h.Client.Update(ctx, existing)
+	scope := h.scope(vpaManager.Name, deployment.Namespace)
+	scope.ObserveVPAWrite("update", writeStart)
+	if err == nil && modeChanged {
+		scope.RecordUpdateModeTransition(previousMode, desiredMode, string(modeLayer))
+	}
+	return err
 }
 
-// deleteVPA deletes a VPA
-func (h *DeploymentWebhookHandler) deleteVPA(ctx context.Context, namespace, vpaName string) error {
-	vpa := &unstructured.Unstructured{}
-	vpa.SetGroupVersionKind(vpaGVK)
-	vpa.SetName(vpaName)
-	vpa.SetNamespace(namespace)
+// deleteVPA deletes a VPA generated for the named VpaManager. VPAs of other operator
+// instances or created by hand under the same name are left alone.
+func (h *DeploymentWebhookHandler) deleteVPA(ctx context.Context, vpaManagerName, namespace, vpaName string) error {
+	existing := &unstructured.Unstructured{}
+	existing.SetGroupVersionKind(vpaGVK)
+	err := h.Client.Get(ctx, types.NamespacedName{Name: vpaName, Namespace: namespace}, existing)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	if !h.Ownership.Owns(existing) {
+		return nil
+	}
 
-	err := h.Client.Delete(ctx, vpa)
+	if !allowWrite(h.RateLimiter, h.Metrics, "deployment", namespace, vpaManagerName) {
+		return nil
+	}
+	uid := existing.GetUID()
+	writeStart := time.Now()
+	err = h.Client.Delete(ctx, existing, client.Preconditions{UID: &uid})
+	h.scope(vpaManagerName, namespace).ObserveVPAWrite("delete", writeStart)
 	if errors.IsNotFound(err) {
 		return nil
 	}
@@ -269,21 +517,18 @@ func (h *DeploymentWebhookHandler) deleteVPA(ctx context.Context, namespace, vpa
 
 // buildVPA creates a VPA unstructured object
 func (h *DeploymentWebhookHandler) buildVPA(vpaManager *autoscalingv1.VpaManager, deployment *appsv1.Deployment, vpaName string) *unstructured.Unstructured {
-	vpa := &unstructured.Unstructured{}
-	vpa.SetGroupVersionKind(vpaGVK)
-	vpa.SetName(vpaName)
-	vpa.SetNamespace(deployment.Namespace)
+	vpaObj := &unstructured.Unstructured{}
+	vpaObj.SetGroupVersionKind(vpaGVK)
+	vpaObj.SetName(vpaName)
+	vpaObj.SetNamespace(deployment.Namespace)
 
 	// Set labels
-	vpa.SetLabels(map[string]string{
-		"app.kubernetes.io/managed-by": "vpa-operator",
-		"app.kubernetes.io/created-by": vpaManager.Name,
-	})
+	vpaObj.SetLabels(h.Ownership.Labels(vpaManager.Name))
 
 	// Set owner reference to deployment for garbage collection
 	controller := true
 	blockOwnerDeletion := true
-	vpa.SetOwnerReferences([]metav1.OwnerReference{
+	vpaObj.SetOwnerReferences([]metav1.OwnerReference{
 		{
 			APIVersion:         "apps/v1",
 			Kind:               "Deployment",
@@ -294,48 +539,8 @@ func (h *DeploymentWebhookHandler) buildVPA(vpaManager *autoscalingv1.VpaManager
 		},
 	})
 
-	// Build spec
-	spec := map[string]interface{}{
-		"targetRef": map[string]interface{}{
-			"apiVersion": "apps/v1",
-			"kind":       "Deployment",
-			"name":       deployment.Name,
-		},
-		"updatePolicy": map[string]interface{}{
-			"updateMode": vpaManager.Spec.UpdateMode,
-		},
-	}
-
-	// Add resource policy if specified
-	if vpaManager.Spec.ResourcePolicy != nil && len(vpaManager.Spec.ResourcePolicy.ContainerPolicies) > 0 {
-		containerPolicies := make([]interface{}, 0, len(vpaManager.Spec.ResourcePolicy.ContainerPolicies))
-		for _, cp := range vpaManager.Spec.ResourcePolicy.ContainerPolicies {
-			policy := map[string]interface{}{
-				"containerName": cp.ContainerName,
-			}
-			if cp.MinAllowed != nil {
-				minAllowed := make(map[string]interface{})
-				for k, v := range cp.MinAllowed {
-					minAllowed[k] = v
-				}
-				policy["minAllowed"] = minAllowed
-			}
-			if cp.MaxAllowed != nil {
-				maxAllowed := make(map[string]interface{})
-				for k, v := range cp.MaxAllowed {
-					maxAllowed[k] = v
-				}
-				policy["maxAllowed"] = maxAllowed
-			}
-			containerPolicies = append(containerPolicies, policy)
-		}
-		spec["resourcePolicy"] = map[string]interface{}{
-			"containerPolicies": containerPolicies,
-		}
-	}
-
-	vpa.Object["spec"] = spec
-	return vpa
+	vpaObj.Object["spec"] = vpa.BuildSpec(vpaManager, "Deployment", deployment.Name)
+	return vpaObj
 }
 
 // InjectDecoder injects the decoder
@@ -344,13 +549,56 @@ func (h *DeploymentWebhookHandler) InjectDecoder(d *admission.Decoder) error {
 	return nil
 }
 
-// matchesLabelSelector checks if labels match a selector (shared helper)
-func matchesLabelSelector(objLabels map[string]string, selector *metav1.LabelSelector) bool {
-	if selector == nil {
+// selectsNamespace reports whether spec selects a namespace: excluded namespaces never are,
+// listed ones always are, and the rest need matchAllNamespaces or matchesSelector. Listed
+// namespaces without a namespaceSelector select only themselves.
+func selectsNamespace(spec *autoscalingv1.VpaManagerSpec, namespace string, matchesSelector func() bool) bool {
+	switch {
+	case spec.ExcludesNamespace(namespace):
+		return false
+	case spec.ListsNamespace(namespace), spec.MatchAllNamespaces:
+		return true
+	case len(spec.NamespaceSelectorTerms()) == 0 && len(spec.Namespaces) > 0:
+		return false
+	}
+	return matchesSelector()
+}
+
+// excludedByLabels reports whether the namespaceExcludeSelector of spec matches nsLabels.
+// A selector that cannot be compiled excludes every namespace rather than none.
+func excludedByLabels(cache *labelselector.Cache, vm *autoscalingv1.VpaManager, spec *autoscalingv1.VpaManagerSpec, nsLabels map[string]string) bool {
+	if spec.NamespaceExcludeSelector == nil {
+		return false
+	}
+	selector, err := cache.Compile(vm.Name, vm.Generation, "namespaceExcludeSelector", spec.NamespaceExcludeSelector)
+	if err != nil {
+		return true
+	}
+	return selector.Matches(labels.Set(nsLabels))
+}
+
+// vpaNameFor names the VPA a VpaManager generates for a workload of kind, see vpa.RenderName
+func vpaNameFor(vpaManager *autoscalingv1.VpaManager, kind string, obj metav1.Object) (string, error) {
+	return vpa.RenderName(vpaManager.Spec.VPANameTemplate, vpa.NameTemplateData{Kind: kind, Name: obj.GetName(), Namespace: obj.GetNamespace()})
+}
+
+// matchesWorkloadName reports whether name matches the workloadNamePatterns of spec. Patterns
+// that cannot be compiled match no name.
+func matchesWorkloadName(spec *autoscalingv1.VpaManagerSpec, name string) bool {
+	if spec.WorkloadNamePatterns == nil {
+		return true
+	}
+	names, err := workload.CompileNamePatterns(spec.WorkloadNamePatterns.Include, spec.WorkloadNamePatterns.Exclude)
+	return err == nil && names.Matches(name)
+}
+
+// matchesLabelSelector checks if labels match any of the selectors in field of vm (shared helper)
+func matchesLabelSelector(cache *labelselector.Cache, vm *autoscalingv1.VpaManager, field string, objLabels map[string]string, selectors []*metav1.LabelSelector) bool {
+	if len(selectors) == 0 {
 		return false // Require explicit selector for webhooks
 	}
 
-	labelSelector, err := metav1.LabelSelectorAsSelector(selector)
+	labelSelector, err := cache.CompileAny(vm.Name, vm.Generation, field, selectors)
 	if err != nil {
 		return false
 	}
@@ -4,8 +4,10 @@ import (
 	"context"
 	"encoding/json"
 	"testing"
+	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	admissionv1 "k8s.io/api/admission/v1"
@@ -17,10 +19,14 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 
 	autoscalingv1 "github.com/joaomo/k8s_op_vpa/api/v1"
 	"github.com/joaomo/k8s_op_vpa/internal/metrics"
+	"github.com/joaomo/k8s_op_vpa/internal/policy"
+	"github.com/joaomo/k8s_op_vpa/internal/vpa"
+	"github.com/joaomo/k8s_op_vpa/internal/workload"
 )
 
 // Test: Webhook creates VPA for new deployment
@@ -81,6 +87,8 @@ func TestDeploymentWebhook_CreatesVPAOnDeploymentCreate(t *testing.T) {
 	require.NoError(t, err)
 	assert.Len(t, vpaList.Items, 1, "VPA should be created for new deployment")
 	assert.Equal(t, "new-deployment-vpa", vpaList.Items[0].GetName())
+	assert.Equal(t, "test-request-uid", vpaList.Items[0].GetAnnotations()[vpa.ReconcileIDAnnotation])
+	assert.Contains(t, vpaList.Items[0].GetAnnotations(), vpa.LastReconciledAtAnnotation)
 }
 
 // Test: Webhook does not create VPA for non-matching deployment
@@ -217,6 +225,107 @@ func TestDeploymentWebhook_RemovesVPAOnDeploymentDelete(t *testing.T) {
 	assert.Len(t, vpaList.Items, 0, "VPA should be deleted when deployment is deleted")
 }
 
+// Test: Webhook names VPAs with the vpaNameTemplate on create and delete
+func TestDeploymentWebhook_VPANameTemplate(t *testing.T) {
+	scheme := setupScheme(t)
+	ctx := context.Background()
+
+	namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "test-ns"}}
+	vpaManager := &autoscalingv1.VpaManager{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-vpamanager"},
+		Spec: autoscalingv1.VpaManagerSpec{
+			Enabled:            true,
+			UpdateMode:         "Off",
+			MatchAllNamespaces: true,
+			MatchAllWorkloads:  true,
+			VPANameTemplate:    "{{ .Kind | lower }}-{{ .Name }}",
+		},
+	}
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(namespace, vpaManager).
+		Build()
+	handler := &DeploymentWebhookHandler{
+		Client:  fakeClient,
+		Scheme:  scheme,
+		Metrics: createTestMetrics(),
+	}
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "test-ns", UID: "web-uid"},
+		Spec:       createDeploymentSpec(),
+	}
+
+	resp := handler.Handle(ctx, createAdmissionRequest(t, admissionv1.Create, deployment, nil))
+	assert.True(t, resp.Allowed)
+	vpaList := newVPAList()
+	require.NoError(t, fakeClient.List(ctx, vpaList, client.InNamespace("test-ns")))
+	require.Len(t, vpaList.Items, 1)
+	assert.Equal(t, "deployment-web", vpaList.Items[0].GetName())
+
+	resp = handler.Handle(ctx, createAdmissionRequest(t, admissionv1.Delete, nil, deployment))
+	assert.True(t, resp.Allowed)
+	vpaList = newVPAList()
+	require.NoError(t, fakeClient.List(ctx, vpaList, client.InNamespace("test-ns")))
+	assert.Empty(t, vpaList.Items)
+}
+
+// Test: Webhook keeps a VPA carrying another operator instance's ownership label
+func TestDeploymentWebhook_KeepsVPAOfOtherInstanceOnDelete(t *testing.T) {
+	scheme := setupScheme(t)
+	ctx := context.Background()
+
+	namespace := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "test-ns",
+			Labels: map[string]string{"vpa-enabled": "true"},
+		},
+	}
+	vpaManager := &autoscalingv1.VpaManager{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-vpamanager"},
+		Spec: autoscalingv1.VpaManagerSpec{
+			Enabled:    true,
+			UpdateMode: "Auto",
+			NamespaceSelector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{"vpa-enabled": "true"},
+			},
+			DeploymentSelector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{"vpa-enabled": "true"},
+			},
+		},
+	}
+
+	// Generated by the default instance, not by the staging instance under test
+	existingVPA := createUnstructuredVPA("existing-deployment-vpa", "test-ns", "existing-deployment")
+
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(namespace, vpaManager, existingVPA).
+		Build()
+
+	handler := &DeploymentWebhookHandler{
+		Client:    fakeClient,
+		Scheme:    scheme,
+		Metrics:   createTestMetrics(),
+		Ownership: vpa.Ownership{Key: "app.kubernetes.io/managed-by", Value: "vpa-operator-staging"},
+	}
+
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "existing-deployment",
+			Namespace: "test-ns",
+			Labels:    map[string]string{"vpa-enabled": "true"},
+		},
+		Spec: createDeploymentSpec(),
+	}
+
+	resp := handler.Handle(ctx, createAdmissionRequest(t, admissionv1.Delete, nil, deployment))
+	assert.True(t, resp.Allowed, "delete should be allowed")
+
+	vpaList := newVPAList()
+	require.NoError(t, fakeClient.List(ctx, vpaList, client.InNamespace("test-ns")))
+	assert.Len(t, vpaList.Items, 1, "VPA of another operator instance should be kept")
+}
+
 // Test: Webhook skips delete when VpaManager is disabled
 func TestDeploymentWebhook_SkipsDeleteWhenDisabled(t *testing.T) {
 	scheme := setupScheme(t)
@@ -343,6 +452,52 @@ func TestDeploymentWebhook_HandlesDeploymentUpdate(t *testing.T) {
 	assert.Len(t, vpaList.Items, 1, "VPA should be created when deployment label is added")
 }
 
+// Test: Webhook skips updates that only change the deployment status
+func TestDeploymentWebhook_SkipsStatusOnlyUpdate(t *testing.T) {
+	scheme := setupScheme(t)
+	ctx := context.Background()
+
+	namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default"}}
+	vpaManager := &autoscalingv1.VpaManager{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-vpamanager"},
+		Spec:       autoscalingv1.VpaManagerSpec{Enabled: true, UpdateMode: "Auto", MatchAllNamespaces: true, MatchAllWorkloads: true},
+	}
+
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(namespace, vpaManager).
+		Build()
+
+	m := createTestMetrics()
+	handler := &DeploymentWebhookHandler{
+		Client:  fakeClient,
+		Scheme:  scheme,
+		Metrics: m,
+	}
+
+	oldDeployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default", UID: types.UID("web"), Generation: 2},
+		Spec:       createDeploymentSpec(),
+	}
+	newDeployment := oldDeployment.DeepCopy()
+	newDeployment.Status.ReadyReplicas = 1
+
+	resp := handler.Handle(ctx, createAdmissionRequest(t, admissionv1.Update, newDeployment, oldDeployment))
+	assert.True(t, resp.Allowed)
+
+	vpaList := newVPAList()
+	require.NoError(t, fakeClient.List(ctx, vpaList, client.InNamespace("default")))
+	assert.Empty(t, vpaList.Items, "a status update should not touch VPAs")
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.WebhookSkippedUpdatesTotal.WithLabelValues("deployment")))
+
+	// A spec change is processed
+	newDeployment.Generation = 3
+	resp = handler.Handle(ctx, createAdmissionRequest(t, admissionv1.Update, newDeployment, oldDeployment))
+	assert.True(t, resp.Allowed)
+	require.NoError(t, fakeClient.List(ctx, vpaList, client.InNamespace("default")))
+	assert.Len(t, vpaList.Items, 1)
+}
+
 // Test: Webhook removes VPA when deployment label is removed
 func TestDeploymentWebhook_RemovesVPAWhenLabelRemoved(t *testing.T) {
 	scheme := setupScheme(t)
@@ -416,6 +571,151 @@ func TestDeploymentWebhook_RemovesVPAWhenLabelRemoved(t *testing.T) {
 	assert.Len(t, vpaList.Items, 0, "VPA should be deleted when deployment label is removed")
 }
 
+// Test: Webhook skips deployments carrying the exclude annotation and deletes their VPA
+func TestDeploymentWebhook_ExcludeAnnotation(t *testing.T) {
+	selected := map[string]string{"vpa-enabled": "true"}
+	deployment := func(annotations map[string]string) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "test-deployment",
+				Namespace:   "test-ns",
+				Labels:      selected,
+				Annotations: annotations,
+				UID:         "test-uid",
+			},
+			Spec: createDeploymentSpec(),
+		}
+	}
+	excluded := map[string]string{workload.ExcludeAnnotation: "true"}
+
+	tests := []struct {
+		name        string
+		operation   admissionv1.Operation
+		newObj      *appsv1.Deployment
+		oldObj      *appsv1.Deployment
+		existingVPA bool
+		wantVPAs    int
+	}{
+		{
+			name:      "created excluded",
+			operation: admissionv1.Create,
+			newObj:    deployment(excluded),
+		},
+		{
+			name:      "created with the annotation set to false",
+			operation: admissionv1.Create,
+			newObj:    deployment(map[string]string{workload.ExcludeAnnotation: "false"}),
+			wantVPAs:  1,
+		},
+		{
+			name:        "annotation added",
+			operation:   admissionv1.Update,
+			newObj:      deployment(excluded),
+			oldObj:      deployment(nil),
+			existingVPA: true,
+		},
+		{
+			name:      "annotation removed",
+			operation: admissionv1.Update,
+			newObj:    deployment(nil),
+			oldObj:    deployment(excluded),
+			wantVPAs:  1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheme := setupScheme(t)
+			ctx := context.Background()
+
+			objects := []client.Object{
+				&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "test-ns", Labels: selected}},
+				&autoscalingv1.VpaManager{
+					ObjectMeta: metav1.ObjectMeta{Name: "test-vpamanager"},
+					Spec: autoscalingv1.VpaManagerSpec{
+						Enabled:            true,
+						UpdateMode:         "Auto",
+						NamespaceSelector:  &metav1.LabelSelector{MatchLabels: selected},
+						DeploymentSelector: &metav1.LabelSelector{MatchLabels: selected},
+					},
+				},
+			}
+			if tt.existingVPA {
+				objects = append(objects, createUnstructuredVPA("test-deployment-vpa", "test-ns", "test-deployment"))
+			}
+			fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build()
+			handler := &DeploymentWebhookHandler{Client: fakeClient, Scheme: scheme, Metrics: createTestMetrics()}
+
+			resp := handler.Handle(ctx, createAdmissionRequest(t, tt.operation, tt.newObj, tt.oldObj))
+			assert.True(t, resp.Allowed)
+
+			vpaList := newVPAList()
+			require.NoError(t, fakeClient.List(ctx, vpaList, client.InNamespace("test-ns")))
+			assert.Len(t, vpaList.Items, tt.wantVPAs)
+		})
+	}
+}
+
+// Test: VPAs in a paused namespace are neither created, updated nor deleted
+func TestDeploymentWebhook_PausedNamespace(t *testing.T) {
+	selected := map[string]string{"vpa-enabled": "true"}
+	deployment := func(labels map[string]string) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-deployment", Namespace: "test-ns", Labels: labels, UID: "test-uid"},
+			Spec:       createDeploymentSpec(),
+		}
+	}
+
+	tests := []struct {
+		name        string
+		operation   admissionv1.Operation
+		newObj      *appsv1.Deployment
+		oldObj      *appsv1.Deployment
+		existingVPA bool
+		wantVPAs    int
+	}{
+		{name: "created", operation: admissionv1.Create, newObj: deployment(selected)},
+		{name: "no longer selected", operation: admissionv1.Update, newObj: deployment(nil), oldObj: deployment(selected), existingVPA: true, wantVPAs: 1},
+		{name: "deleted", operation: admissionv1.Delete, oldObj: deployment(selected), existingVPA: true, wantVPAs: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheme := setupScheme(t)
+			ctx := context.Background()
+
+			objects := []client.Object{
+				&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+					Name:        "test-ns",
+					Labels:      selected,
+					Annotations: map[string]string{workload.PausedAnnotation: "true"},
+				}},
+				&autoscalingv1.VpaManager{
+					ObjectMeta: metav1.ObjectMeta{Name: "test-vpamanager"},
+					Spec: autoscalingv1.VpaManagerSpec{
+						Enabled:            true,
+						UpdateMode:         "Auto",
+						NamespaceSelector:  &metav1.LabelSelector{MatchLabels: selected},
+						DeploymentSelector: &metav1.LabelSelector{MatchLabels: selected},
+					},
+				},
+			}
+			if tt.existingVPA {
+				objects = append(objects, createUnstructuredVPA("test-deployment-vpa", "test-ns", "test-deployment"))
+			}
+			fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build()
+			handler := &DeploymentWebhookHandler{Client: fakeClient, Scheme: scheme, Metrics: createTestMetrics()}
+
+			resp := handler.Handle(ctx, createAdmissionRequest(t, tt.operation, tt.newObj, tt.oldObj))
+			assert.True(t, resp.Allowed)
+
+			vpaList := newVPAList()
+			require.NoError(t, fakeClient.List(ctx, vpaList, client.InNamespace("test-ns")))
+			assert.Len(t, vpaList.Items, tt.wantVPAs)
+		})
+	}
+}
+
 // Test: Webhook does not fail if no VpaManager exists
 func TestDeploymentWebhook_AllowsDeploymentWhenNoVpaManager(t *testing.T) {
 	scheme := setupScheme(t)
@@ -472,11 +772,15 @@ func TestDeploymentWebhook_AppliesResourcePolicy(t *testing.T) {
 		},
 	}
 
+	minReplicas := int32(2)
 	vpaManager := &autoscalingv1.VpaManager{
 		ObjectMeta: metav1.ObjectMeta{Name: "test-vpamanager"},
 		Spec: autoscalingv1.VpaManagerSpec{
 			Enabled:    true,
 			UpdateMode: "Initial",
+			UpdatePolicy: &autoscalingv1.UpdatePolicy{
+				MinReplicas: &minReplicas,
+			},
 			NamespaceSelector: &metav1.LabelSelector{
 				MatchLabels: map[string]string{"vpa-enabled": "true"},
 			},
@@ -495,6 +799,9 @@ func TestDeploymentWebhook_AppliesResourcePolicy(t *testing.T) {
 							"cpu":    "2",
 							"memory": "2Gi",
 						},
+						ControlledResources: []string{"memory"},
+						ControlledValues:    autoscalingv1.ControlledValuesRequestsOnly,
+						Mode:                autoscalingv1.ContainerScalingModeAuto,
 					},
 				},
 			},
@@ -539,6 +846,7 @@ func TestDeploymentWebhook_AppliesResourcePolicy(t *testing.T) {
 	// Verify update mode
 	updatePolicy := spec["updatePolicy"].(map[string]interface{})
 	assert.Equal(t, "Initial", updatePolicy["updateMode"])
+	assert.Equal(t, int64(2), updatePolicy["minReplicas"])
 
 	// Verify resource policy
 	resourcePolicy := spec["resourcePolicy"].(map[string]interface{})
@@ -549,6 +857,9 @@ func TestDeploymentWebhook_AppliesResourcePolicy(t *testing.T) {
 	minAllowed := policy["minAllowed"].(map[string]interface{})
 	assert.Equal(t, "50m", minAllowed["cpu"])
 	assert.Equal(t, "64Mi", minAllowed["memory"])
+	assert.Equal(t, []interface{}{"memory"}, policy["controlledResources"])
+	assert.Equal(t, "RequestsOnly", policy["controlledValues"])
+	assert.Equal(t, "Auto", policy["mode"])
 }
 
 // Test: Webhook handles multiple VpaManagers (uses first enabled matching one)
@@ -627,6 +938,119 @@ func TestDeploymentWebhook_HandlesMultipleVpaManagers(t *testing.T) {
 	assert.Equal(t, "Auto", updatePolicy["updateMode"], "should use enabled manager's updateMode")
 }
 
+// Test: Of several matching VpaManagers, the one of the highest priority generates the VPA
+func TestDeploymentWebhook_PrefersHighestPriorityVpaManager(t *testing.T) {
+	scheme := setupScheme(t)
+	ctx := context.Background()
+
+	namespace := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "test-ns",
+			Labels: map[string]string{"vpa-enabled": "true"},
+		},
+	}
+	manager := func(name, mode string, priority int32) *autoscalingv1.VpaManager {
+		return &autoscalingv1.VpaManager{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec: autoscalingv1.VpaManagerSpec{
+				Enabled:    true,
+				Priority:   priority,
+				UpdateMode: mode,
+				NamespaceSelector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{"vpa-enabled": "true"},
+				},
+			},
+		}
+	}
+
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(namespace, manager("a-manager", "Off", 0), manager("b-manager", "Auto", 10), manager("c-manager", "Initial", 10)).
+		Build()
+
+	handler := &DeploymentWebhookHandler{
+		Client:  fakeClient,
+		Scheme:  scheme,
+		Metrics: createTestMetrics(),
+	}
+
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-deployment",
+			Namespace: "test-ns",
+			UID:       "test-uid",
+		},
+		Spec: createDeploymentSpec(),
+	}
+
+	req := createAdmissionRequest(t, admissionv1.Create, deployment, nil)
+	resp := handler.Handle(ctx, req)
+	assert.True(t, resp.Allowed)
+
+	vpaList := newVPAList()
+	err := fakeClient.List(ctx, vpaList, client.InNamespace("test-ns"))
+	require.NoError(t, err)
+	require.Len(t, vpaList.Items, 1)
+
+	// b-manager and c-manager share the highest priority, the name breaks the tie
+	vpa := vpaList.Items[0]
+	assert.Equal(t, "b-manager", vpa.GetLabels()["app.kubernetes.io/created-by"])
+	updatePolicy := vpa.Object["spec"].(map[string]interface{})["updatePolicy"].(map[string]interface{})
+	assert.Equal(t, "Auto", updatePolicy["updateMode"])
+}
+
+// Test: a VpaManager with an invalid spec is skipped like the reconciler skips it
+func TestDeploymentWebhook_SkipsInvalidVpaManager(t *testing.T) {
+	scheme := setupScheme(t)
+	ctx := context.Background()
+
+	namespace := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "test-ns",
+			Labels: map[string]string{"vpa-enabled": "true"},
+		},
+	}
+	selector := &metav1.LabelSelector{MatchLabels: map[string]string{"vpa-enabled": "true"}}
+	// matchAllNamespaces cannot be combined with namespaceSelector
+	invalid := &autoscalingv1.VpaManager{
+		ObjectMeta: metav1.ObjectMeta{Name: "invalid-manager"},
+		Spec: autoscalingv1.VpaManagerSpec{
+			Enabled:            true,
+			Priority:           10,
+			UpdateMode:         "Auto",
+			MatchAllNamespaces: true,
+			NamespaceSelector:  selector,
+		},
+	}
+	valid := &autoscalingv1.VpaManager{
+		ObjectMeta: metav1.ObjectMeta{Name: "valid-manager"},
+		Spec:       autoscalingv1.VpaManagerSpec{Enabled: true, UpdateMode: "Off", NamespaceSelector: selector},
+	}
+
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(namespace, invalid, valid).
+		Build()
+
+	handler := &DeploymentWebhookHandler{
+		Client:  fakeClient,
+		Scheme:  scheme,
+		Metrics: createTestMetrics(),
+	}
+
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-deployment", Namespace: "test-ns", UID: "test-uid"},
+		Spec:       createDeploymentSpec(),
+	}
+	resp := handler.Handle(ctx, createAdmissionRequest(t, admissionv1.Create, deployment, nil))
+	assert.True(t, resp.Allowed)
+
+	vpaList := newVPAList()
+	require.NoError(t, fakeClient.List(ctx, vpaList, client.InNamespace("test-ns")))
+	require.Len(t, vpaList.Items, 1)
+	assert.Equal(t, "valid-manager", vpaList.Items[0].GetLabels()["app.kubernetes.io/created-by"])
+}
+
 // Test: Webhook is idempotent - doesn't duplicate VPA on retry
 func TestDeploymentWebhook_IsIdempotent(t *testing.T) {
 	scheme := setupScheme(t)
@@ -692,6 +1116,706 @@ func TestDeploymentWebhook_IsIdempotent(t *testing.T) {
 
 // Helper functions
 
+// Test: Webhook does not overwrite VPAs owned by a GitOps controller
+func TestDeploymentWebhook_SkipsUpdateOfGitOpsManagedVPA(t *testing.T) {
+	scheme := setupScheme(t)
+	ctx := context.Background()
+
+	namespace := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "test-ns",
+			Labels: map[string]string{"vpa-enabled": "true"},
+		},
+	}
+
+	vpaManager := &autoscalingv1.VpaManager{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-vpamanager"},
+		Spec: autoscalingv1.VpaManagerSpec{
+			Enabled:    true,
+			UpdateMode: "Auto",
+			NamespaceSelector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{"vpa-enabled": "true"},
+			},
+			DeploymentSelector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{"vpa-enabled": "true"},
+			},
+		},
+	}
+
+	gitOpsVPA := createUnstructuredVPA("test-deployment-vpa", "test-ns", "test-deployment")
+	gitOpsVPA.SetLabels(map[string]string{"kustomize.toolkit.fluxcd.io/name": "apps"})
+
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(namespace, vpaManager, gitOpsVPA).
+		Build()
+
+	handler := &DeploymentWebhookHandler{
+		Client:  fakeClient,
+		Scheme:  scheme,
+		Metrics: createTestMetrics(),
+	}
+
+	oldDeployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-deployment",
+			Namespace: "test-ns",
+			Labels:    map[string]string{"vpa-enabled": "true"},
+			UID:       "test-uid",
+		},
+		Spec: createDeploymentSpec(),
+	}
+	newDeployment := oldDeployment.DeepCopy()
+	newDeployment.Labels["team"] = "payments"
+
+	req := createAdmissionRequest(t, admissionv1.Update, newDeployment, oldDeployment)
+	resp := handler.Handle(ctx, req)
+	assert.True(t, resp.Allowed)
+
+	vpa := &unstructured.Unstructured{}
+	vpa.SetGroupVersionKind(vpaGVK)
+	err := fakeClient.Get(ctx, types.NamespacedName{Name: "test-deployment-vpa", Namespace: "test-ns"}, vpa)
+	require.NoError(t, err)
+	assert.NotContains(t, vpa.Object["spec"], "updatePolicy", "GitOps-managed VPA spec should not be overwritten")
+}
+
+// Test: Webhook leaves a VPA that is being deleted alone
+func TestDeploymentWebhook_SkipsUpdateOfTerminatingVPA(t *testing.T) {
+	scheme := setupScheme(t)
+	ctx := context.Background()
+
+	selected := map[string]string{"vpa-enabled": "true"}
+	namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "test-ns", Labels: selected}}
+	vpaManager := &autoscalingv1.VpaManager{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-vpamanager"},
+		Spec: autoscalingv1.VpaManagerSpec{
+			Enabled:            true,
+			UpdateMode:         "Auto",
+			NamespaceSelector:  &metav1.LabelSelector{MatchLabels: selected},
+			DeploymentSelector: &metav1.LabelSelector{MatchLabels: selected},
+		},
+	}
+
+	terminatingVPA := createUnstructuredVPA("test-deployment-vpa", "test-ns", "test-deployment")
+	terminatingVPA.SetFinalizers([]string{"example.com/cleanup"})
+	terminatingVPA.SetDeletionTimestamp(&metav1.Time{Time: time.Now()})
+
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(namespace, vpaManager, terminatingVPA).
+		Build()
+
+	handler := &DeploymentWebhookHandler{
+		Client:  fakeClient,
+		Scheme:  scheme,
+		Metrics: createTestMetrics(),
+	}
+
+	oldDeployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-deployment",
+			Namespace: "test-ns",
+			Labels:    map[string]string{"vpa-enabled": "true"},
+			UID:       "test-uid",
+		},
+		Spec: createDeploymentSpec(),
+	}
+	newDeployment := oldDeployment.DeepCopy()
+	newDeployment.Labels["team"] = "payments"
+
+	req := createAdmissionRequest(t, admissionv1.Update, newDeployment, oldDeployment)
+	resp := handler.Handle(ctx, req)
+	assert.True(t, resp.Allowed)
+
+	vpa := &unstructured.Unstructured{}
+	vpa.SetGroupVersionKind(vpaGVK)
+	err := fakeClient.Get(ctx, types.NamespacedName{Name: "test-deployment-vpa", Namespace: "test-ns"}, vpa)
+	require.NoError(t, err)
+	assert.NotContains(t, vpa.Object["spec"], "updatePolicy", "a VPA being deleted should not be updated")
+}
+
+// Test: Updates of a deployment idle at zero replicas keep its dormant VPA Off
+func TestDeploymentWebhook_KeepsDormantVPAOff(t *testing.T) {
+	scheme := setupScheme(t)
+	ctx := context.Background()
+
+	selected := map[string]string{"vpa-enabled": "true"}
+	namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "test-ns", Labels: selected}}
+	vpaManager := &autoscalingv1.VpaManager{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-vpamanager"},
+		Spec: autoscalingv1.VpaManagerSpec{
+			Enabled:            true,
+			UpdateMode:         "Auto",
+			NamespaceSelector:  &metav1.LabelSelector{MatchLabels: selected},
+			DeploymentSelector: &metav1.LabelSelector{MatchLabels: selected},
+			Dormancy:           &autoscalingv1.DormancyPolicy{After: metav1.Duration{Duration: time.Hour}},
+		},
+	}
+	dormantVPA := createUnstructuredVPA("test-deployment-vpa", "test-ns", "test-deployment")
+	dormantVPA.SetAnnotations(map[string]string{
+		vpa.ScaledToZeroSinceAnnotation: time.Now().Add(-2 * time.Hour).UTC().Format(time.RFC3339),
+		vpa.DormantAnnotation:           "Auto",
+	})
+
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(namespace, vpaManager, dormantVPA).
+		Build()
+	handler := &DeploymentWebhookHandler{Client: fakeClient, Scheme: scheme, Metrics: createTestMetrics()}
+
+	oldDeployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-deployment", Namespace: "test-ns", Labels: selected, UID: "test-uid"},
+		Spec:       createDeploymentSpec(),
+	}
+	oldDeployment.Spec.Replicas = new(int32)
+	newDeployment := oldDeployment.DeepCopy()
+	newDeployment.Labels["team"] = "payments"
+
+	resp := handler.Handle(ctx, createAdmissionRequest(t, admissionv1.Update, newDeployment, oldDeployment))
+	assert.True(t, resp.Allowed)
+
+	updated := &unstructured.Unstructured{}
+	updated.SetGroupVersionKind(vpaGVK)
+	require.NoError(t, fakeClient.Get(ctx, types.NamespacedName{Name: "test-deployment-vpa", Namespace: "test-ns"}, updated))
+	mode, _, _ := unstructured.NestedString(updated.Object, "spec", "updatePolicy", "updateMode")
+	assert.Equal(t, "Off", mode)
+	assert.Equal(t, "Auto", updated.GetAnnotations()[vpa.DormantAnnotation])
+}
+
+// Test: A VpaOverride for the deployment takes precedence over the VpaManager
+func TestDeploymentWebhook_AppliesVpaOverride(t *testing.T) {
+	scheme := setupScheme(t)
+	ctx := context.Background()
+
+	selected := map[string]string{"vpa-enabled": "true"}
+	namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "test-ns", Labels: selected}}
+	vpaManager := &autoscalingv1.VpaManager{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-vpamanager"},
+		Spec: autoscalingv1.VpaManagerSpec{
+			Enabled:            true,
+			UpdateMode:         "Auto",
+			NamespaceSelector:  &metav1.LabelSelector{MatchLabels: selected},
+			DeploymentSelector: &metav1.LabelSelector{MatchLabels: selected},
+		},
+	}
+	override := &autoscalingv1.VpaOverride{
+		ObjectMeta: metav1.ObjectMeta{Name: "keep-off", Namespace: "test-ns"},
+		Spec: autoscalingv1.VpaOverrideSpec{
+			TargetRef:  autoscalingv1.OverrideTargetReference{Kind: "Deployment", Name: "test-deployment"},
+			UpdateMode: "Off",
+		},
+	}
+
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(namespace, vpaManager, override).
+		Build()
+	handler := &DeploymentWebhookHandler{Client: fakeClient, Scheme: scheme, Metrics: createTestMetrics()}
+
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-deployment", Namespace: "test-ns", Labels: selected, UID: "test-uid"},
+		Spec:       createDeploymentSpec(),
+	}
+	resp := handler.Handle(ctx, createAdmissionRequest(t, admissionv1.Create, deployment, nil))
+	assert.True(t, resp.Allowed)
+
+	created := &unstructured.Unstructured{}
+	created.SetGroupVersionKind(vpaGVK)
+	require.NoError(t, fakeClient.Get(ctx, types.NamespacedName{Name: "test-deployment-vpa", Namespace: "test-ns"}, created))
+	mode, _, _ := unstructured.NestedString(created.Object, "spec", "updatePolicy", "updateMode")
+	assert.Equal(t, "Off", mode)
+}
+
+// Test: With strict selectors, omitted selectors only match when matchAll is set
+func TestDeploymentWebhook_StrictSelectors(t *testing.T) {
+	tests := []struct {
+		name      string
+		spec      autoscalingv1.VpaManagerSpec
+		expectVPA bool
+	}{
+		{
+			name:      "omitted selectors match nothing",
+			spec:      autoscalingv1.VpaManagerSpec{Enabled: true, UpdateMode: "Off"},
+			expectVPA: false,
+		},
+		{
+			name:      "matchAll flags match everything",
+			spec:      autoscalingv1.VpaManagerSpec{Enabled: true, UpdateMode: "Off", MatchAllNamespaces: true, MatchAllWorkloads: true},
+			expectVPA: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheme := setupScheme(t)
+			ctx := context.Background()
+
+			namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "test-ns"}}
+			vpaManager := &autoscalingv1.VpaManager{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-vpamanager"},
+				Spec:       tt.spec,
+			}
+
+			fakeClient := fake.NewClientBuilder().
+				WithScheme(scheme).
+				WithObjects(namespace, vpaManager).
+				Build()
+
+			handler := &DeploymentWebhookHandler{
+				Client:          fakeClient,
+				Scheme:          scheme,
+				Metrics:         createTestMetrics(),
+				StrictSelectors: true,
+			}
+
+			deployment := &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Name: "new-deployment", Namespace: "test-ns", UID: "new-uid"},
+				Spec:       createDeploymentSpec(),
+			}
+
+			resp := handler.Handle(ctx, createAdmissionRequest(t, admissionv1.Create, deployment, nil))
+			assert.True(t, resp.Allowed)
+
+			vpaList := newVPAList()
+			require.NoError(t, fakeClient.List(ctx, vpaList, client.InNamespace("test-ns")))
+			assert.Equal(t, tt.expectVPA, len(vpaList.Items) == 1)
+		})
+	}
+}
+
+// Test: Namespaces listed by name are selected without labels, excluded ones never are,
+// whether excluded by name or by labels, and workloadNamePatterns narrow the deployments
+func TestDeploymentWebhook_NamespaceLists(t *testing.T) {
+	optIn := &metav1.LabelSelector{MatchLabels: map[string]string{"vpa-enabled": "true"}}
+	systemTier := &metav1.LabelSelector{MatchLabels: map[string]string{"tier": "system"}}
+
+	tests := []struct {
+		name      string
+		spec      autoscalingv1.VpaManagerSpec
+		namespace *corev1.Namespace
+		expectVPA bool
+	}{
+		{
+			name:      "listed namespace without labels",
+			spec:      autoscalingv1.VpaManagerSpec{Namespaces: []string{"prod-a"}},
+			namespace: &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "prod-a"}},
+			expectVPA: true,
+		},
+		{
+			name:      "unlisted namespace without a selector",
+			spec:      autoscalingv1.VpaManagerSpec{Namespaces: []string{"prod-a"}},
+			namespace: &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "prod-b", Labels: optIn.MatchLabels}},
+		},
+		{
+			name:      "unlisted namespace matching the selector",
+			spec:      autoscalingv1.VpaManagerSpec{Namespaces: []string{"prod-a"}, NamespaceSelector: optIn},
+			namespace: &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "prod-b", Labels: optIn.MatchLabels}},
+			expectVPA: true,
+		},
+		{
+			name:      "excluded namespace matching the selector",
+			spec:      autoscalingv1.VpaManagerSpec{NamespaceSelector: optIn, ExcludeNamespaces: []string{"kube-system"}},
+			namespace: &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "kube-system", Labels: optIn.MatchLabels}},
+		},
+		{
+			name:      "excluded namespace under matchAllNamespaces",
+			spec:      autoscalingv1.VpaManagerSpec{MatchAllNamespaces: true, ExcludeNamespaces: []string{"kube-system"}},
+			namespace: &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "kube-system"}},
+		},
+		{
+			name:      "listed namespace matching the exclude selector",
+			spec:      autoscalingv1.VpaManagerSpec{Namespaces: []string{"monitoring"}, NamespaceExcludeSelector: systemTier},
+			namespace: &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "monitoring", Labels: systemTier.MatchLabels}},
+		},
+		{
+			name:      "namespace matching the selector but not the exclude selector",
+			spec:      autoscalingv1.VpaManagerSpec{NamespaceSelector: optIn, NamespaceExcludeSelector: systemTier},
+			namespace: &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "shop", Labels: optIn.MatchLabels}},
+			expectVPA: true,
+		},
+		{
+			name: "deployment name matching an include pattern",
+			spec: autoscalingv1.VpaManagerSpec{
+				MatchAllNamespaces:   true,
+				WorkloadNamePatterns: &autoscalingv1.NamePatterns{Include: []string{"new-.*"}},
+			},
+			namespace: &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "shop"}},
+			expectVPA: true,
+		},
+		{
+			name: "deployment name matching no include pattern",
+			spec: autoscalingv1.VpaManagerSpec{
+				MatchAllNamespaces:   true,
+				WorkloadNamePatterns: &autoscalingv1.NamePatterns{Include: []string{"legacy-.*"}},
+			},
+			namespace: &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "shop"}},
+		},
+		{
+			name: "deployment name matching an exclude pattern",
+			spec: autoscalingv1.VpaManagerSpec{
+				MatchAllNamespaces:   true,
+				WorkloadNamePatterns: &autoscalingv1.NamePatterns{Include: []string{"new-.*"}, Exclude: []string{".*-deployment"}},
+			},
+			namespace: &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "shop"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheme := setupScheme(t)
+			ctx := context.Background()
+
+			tt.spec.Enabled = true
+			tt.spec.UpdateMode = "Off"
+			tt.spec.MatchAllWorkloads = true
+			vpaManager := &autoscalingv1.VpaManager{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-vpamanager"},
+				Spec:       tt.spec,
+			}
+
+			fakeClient := fake.NewClientBuilder().
+				WithScheme(scheme).
+				WithObjects(tt.namespace, vpaManager).
+				Build()
+
+			handler := &DeploymentWebhookHandler{
+				Client:  fakeClient,
+				Scheme:  scheme,
+				Metrics: createTestMetrics(),
+			}
+
+			deployment := &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Name: "new-deployment", Namespace: tt.namespace.Name, UID: "new-uid"},
+				Spec:       createDeploymentSpec(),
+			}
+
+			resp := handler.Handle(ctx, createAdmissionRequest(t, admissionv1.Create, deployment, nil))
+			assert.True(t, resp.Allowed)
+
+			vpaList := newVPAList()
+			require.NoError(t, fakeClient.List(ctx, vpaList, client.InNamespace(tt.namespace.Name)))
+			assert.Equal(t, tt.expectVPA, len(vpaList.Items) == 1)
+		})
+	}
+}
+
+// Test: Namespaces and deployments matching any of several selectors get a VPA
+func TestDeploymentWebhook_AlternativeSelectors(t *testing.T) {
+	spec := autoscalingv1.VpaManagerSpec{
+		Enabled:    true,
+		UpdateMode: "Off",
+		NamespaceSelectors: []metav1.LabelSelector{
+			{MatchLabels: map[string]string{"team": "a"}},
+			{MatchLabels: map[string]string{"shared": "true"}},
+		},
+		DeploymentSelector:  &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+		DeploymentSelectors: []metav1.LabelSelector{{MatchLabels: map[string]string{"tier": "frontend"}}},
+	}
+
+	tests := []struct {
+		name            string
+		namespaceLabels map[string]string
+		labels          map[string]string
+		expectVPA       bool
+	}{
+		{name: "first alternatives", namespaceLabels: map[string]string{"team": "a"}, labels: map[string]string{"app": "web"}, expectVPA: true},
+		{name: "second alternatives", namespaceLabels: map[string]string{"shared": "true"}, labels: map[string]string{"tier": "frontend"}, expectVPA: true},
+		{name: "namespace matching none", namespaceLabels: map[string]string{"team": "b"}, labels: map[string]string{"app": "web"}},
+		{name: "deployment matching none", namespaceLabels: map[string]string{"team": "a"}, labels: map[string]string{"app": "batch"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheme := setupScheme(t)
+			ctx := context.Background()
+
+			vpaManager := &autoscalingv1.VpaManager{ObjectMeta: metav1.ObjectMeta{Name: "test-vpamanager"}, Spec: spec}
+			namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "test-ns", Labels: tt.namespaceLabels}}
+			fakeClient := fake.NewClientBuilder().
+				WithScheme(scheme).
+				WithObjects(namespace, vpaManager).
+				Build()
+
+			handler := &DeploymentWebhookHandler{
+				Client:          fakeClient,
+				Scheme:          scheme,
+				Metrics:         createTestMetrics(),
+				StrictSelectors: true,
+			}
+
+			deployment := &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Name: "new-deployment", Namespace: "test-ns", UID: "new-uid", Labels: tt.labels},
+				Spec:       createDeploymentSpec(),
+			}
+
+			resp := handler.Handle(ctx, createAdmissionRequest(t, admissionv1.Create, deployment, nil))
+			assert.True(t, resp.Allowed)
+
+			vpaList := newVPAList()
+			require.NoError(t, fakeClient.List(ctx, vpaList, client.InNamespace("test-ns")))
+			assert.Equal(t, tt.expectVPA, len(vpaList.Items) == 1)
+		})
+	}
+}
+
+// Test: Over the cluster-wide cap the webhook admits the workload without creating a VPA
+func TestDeploymentWebhook_RespectsClusterCapacity(t *testing.T) {
+	scheme := setupScheme(t)
+	ctx := context.Background()
+
+	selected := map[string]string{"vpa-enabled": "true"}
+	vpaManager := &autoscalingv1.VpaManager{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-vpamanager"},
+		Spec: autoscalingv1.VpaManagerSpec{
+			Enabled:            true,
+			UpdateMode:         "Off",
+			NamespaceSelector:  &metav1.LabelSelector{MatchLabels: selected},
+			DeploymentSelector: &metav1.LabelSelector{MatchLabels: selected},
+		},
+	}
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(vpaManager, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "test-ns", Labels: selected}}).
+		Build()
+
+	handler := &DeploymentWebhookHandler{
+		Client:   fakeClient,
+		Scheme:   scheme,
+		Metrics:  createTestMetrics(),
+		Capacity: vpa.NewClusterCapacity(1, vpa.Ownership{}),
+	}
+	for _, name := range []string{"a", "b"} {
+		deployment := &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "test-ns", Labels: selected},
+			Spec:       createDeploymentSpec(),
+		}
+		resp := handler.Handle(ctx, createAdmissionRequest(t, admissionv1.Create, deployment, nil))
+		assert.True(t, resp.Allowed, "the cap must never reject a workload")
+	}
+
+	vpaList := newVPAList()
+	require.NoError(t, fakeClient.List(ctx, vpaList, client.InNamespace("test-ns")))
+	require.Len(t, vpaList.Items, 1)
+	assert.Equal(t, "a-vpa", vpaList.Items[0].GetName())
+}
+
+// Test: A slow API server makes the webhook return early instead of blocking admission
+func TestDeploymentWebhook_ReturnsEarlyOnClientTimeout(t *testing.T) {
+	scheme := setupScheme(t)
+	ctx := context.Background()
+
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithInterceptorFuncs(interceptor.Funcs{
+			List: func(ctx context.Context, c client.WithWatch, list client.ObjectList, opts ...client.ListOption) error {
+				<-ctx.Done()
+				return ctx.Err()
+			},
+		}).
+		Build()
+
+	m := createTestMetrics()
+	handler := &DeploymentWebhookHandler{
+		Client:        fakeClient,
+		Scheme:        scheme,
+		Metrics:       m,
+		ClientTimeout: 50 * time.Millisecond,
+	}
+
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "new-deployment", Namespace: "test-ns", UID: "new-uid"},
+		Spec:       createDeploymentSpec(),
+	}
+
+	start := time.Now()
+	resp := handler.Handle(ctx, createAdmissionRequest(t, admissionv1.Create, deployment, nil))
+
+	assert.True(t, resp.Allowed, "admission must not be blocked by a slow API server")
+	assert.Less(t, time.Since(start), time.Second)
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.WebhookTimeoutsTotal.WithLabelValues("deployment", "")))
+}
+
+// Test: Webhook metrics are attributed to the VpaManager a request matched
+func TestDeploymentWebhook_AttributesMetricsToVpaManager(t *testing.T) {
+	tests := []struct {
+		name            string
+		labels          map[string]string
+		expectedManager string
+	}{
+		{name: "matching deployment", labels: map[string]string{"vpa-enabled": "true"}, expectedManager: "team-a"},
+		{name: "non-matching deployment", expectedManager: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheme := setupScheme(t)
+			ctx := context.Background()
+
+			namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "test-ns"}}
+			vpaManager := &autoscalingv1.VpaManager{
+				ObjectMeta: metav1.ObjectMeta{Name: "team-a"},
+				Spec: autoscalingv1.VpaManagerSpec{
+					Enabled:            true,
+					UpdateMode:         "Off",
+					MatchAllNamespaces: true,
+					DeploymentSelector: &metav1.LabelSelector{
+						MatchLabels: map[string]string{"vpa-enabled": "true"},
+					},
+				},
+			}
+
+			fakeClient := fake.NewClientBuilder().
+				WithScheme(scheme).
+				WithObjects(namespace, vpaManager).
+				Build()
+
+			m := createTestMetrics()
+			handler := &DeploymentWebhookHandler{Client: fakeClient, Scheme: scheme, Metrics: m}
+
+			deployment := &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Name: "new-deployment", Namespace: "test-ns", Labels: tt.labels, UID: "new-uid"},
+				Spec:       createDeploymentSpec(),
+			}
+
+			resp := handler.Handle(ctx, createAdmissionRequest(t, admissionv1.Create, deployment, nil))
+			assert.True(t, resp.Allowed)
+
+			assert.Equal(t, float64(1), testutil.ToFloat64(
+				m.WebhookRequestsTotal.WithLabelValues(string(admissionv1.Create), tt.expectedManager, metrics.ResultSuccess, "")))
+			writes := 0
+			if tt.expectedManager != "" {
+				writes = 1
+			}
+			assert.Equal(t, writes, testutil.CollectAndCount(m.VPAWriteDuration))
+		})
+	}
+}
+
+// Test: The operator's own Deployment and the VPA components with a bootstrap VPA are never given a VPA
+func TestDeploymentWebhook_SkipsOperatorWorkload(t *testing.T) {
+	scheme := setupScheme(t)
+	ctx := context.Background()
+
+	namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "vpa-system"}}
+	vpaManager := &autoscalingv1.VpaManager{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-vpamanager"},
+		Spec:       autoscalingv1.VpaManagerSpec{Enabled: true, UpdateMode: "Auto", MatchAllNamespaces: true, MatchAllWorkloads: true},
+	}
+
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(namespace, vpaManager).
+		Build()
+
+	handler := &DeploymentWebhookHandler{
+		Client:  fakeClient,
+		Scheme:  scheme,
+		Metrics: createTestMetrics(),
+		Self:    &workload.Self{Namespace: "vpa-system", Kind: "Deployment", Name: "vpa-operator"},
+		// The VPA is installed next to the operator here
+		Bootstrapped: &workload.Bootstrapped{Namespace: "vpa-system", Names: workload.DefaultVPAComponents},
+	}
+
+	for _, name := range []string{"vpa-operator", "vpa-recommender", "neighbour"} {
+		deployment := &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "vpa-system", UID: types.UID(name)},
+			Spec:       createDeploymentSpec(),
+		}
+		resp := handler.Handle(ctx, createAdmissionRequest(t, admissionv1.Create, deployment, nil))
+		assert.True(t, resp.Allowed)
+	}
+
+	vpaList := newVPAList()
+	require.NoError(t, fakeClient.List(ctx, vpaList, client.InNamespace("vpa-system")))
+	require.Len(t, vpaList.Items, 1)
+	assert.Equal(t, "neighbour-vpa", vpaList.Items[0].GetName())
+}
+
+// Test: a VpaManager without selectors only matches what the default selectors select
+func TestDeploymentWebhook_AppliesSelectorDefaults(t *testing.T) {
+	scheme := setupScheme(t)
+	ctx := context.Background()
+
+	vpaManager := &autoscalingv1.VpaManager{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-vpamanager"},
+		Spec:       autoscalingv1.VpaManagerSpec{Enabled: true, UpdateMode: "Auto"},
+	}
+
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(
+			vpaManager,
+			&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "opted-in", Labels: map[string]string{"vpa-enabled": "true"}}},
+			&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default"}},
+		).
+		Build()
+
+	selectorDefaults, err := policy.NewSelectorDefaults("vpa-enabled=true", "vpa-enabled=true")
+	require.NoError(t, err)
+	handler := &DeploymentWebhookHandler{
+		Client:           fakeClient,
+		Scheme:           scheme,
+		Metrics:          createTestMetrics(),
+		SelectorDefaults: selectorDefaults,
+	}
+
+	for _, namespace := range []string{"opted-in", "default"} {
+		deployment := &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "app",
+				Namespace: namespace,
+				UID:       types.UID(namespace + "-app"),
+				Labels:    map[string]string{"vpa-enabled": "true"},
+			},
+			Spec: createDeploymentSpec(),
+		}
+		resp := handler.Handle(ctx, createAdmissionRequest(t, admissionv1.Create, deployment, nil))
+		assert.True(t, resp.Allowed)
+	}
+
+	vpaList := newVPAList()
+	require.NoError(t, fakeClient.List(ctx, vpaList))
+	require.Len(t, vpaList.Items, 1, "the namespace outside the default selector is not matched")
+	assert.Equal(t, "opted-in", vpaList.Items[0].GetNamespace())
+}
+
+func TestDeploymentWebhook_DryRunDoesNotWriteVPA(t *testing.T) {
+	scheme := setupScheme(t)
+	ctx := context.Background()
+
+	namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default"}}
+	vpaManager := &autoscalingv1.VpaManager{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-vpamanager"},
+		Spec:       autoscalingv1.VpaManagerSpec{Enabled: true, UpdateMode: "Auto", MatchAllNamespaces: true, MatchAllWorkloads: true},
+	}
+
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(namespace, vpaManager).
+		Build()
+
+	handler := &DeploymentWebhookHandler{
+		Client:  fakeClient,
+		Scheme:  scheme,
+		Metrics: createTestMetrics(),
+	}
+
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default", UID: types.UID("web")},
+		Spec:       createDeploymentSpec(),
+	}
+	req := createAdmissionRequest(t, admissionv1.Create, deployment, nil)
+	dryRun := true
+	req.DryRun = &dryRun
+
+	resp := handler.Handle(ctx, req)
+	assert.True(t, resp.Allowed)
+
+	vpaList := newVPAList()
+	require.NoError(t, fakeClient.List(ctx, vpaList, client.InNamespace("default")))
+	assert.Empty(t, vpaList.Items)
+}
+
 func setupScheme(t *testing.T) *runtime.Scheme {
 	scheme := runtime.NewScheme()
 	require.NoError(t, autoscalingv1.AddToScheme(scheme))
@@ -0,0 +1,90 @@
+package webhook
+
+import (
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	"github.com/joaomo/k8s_op_vpa/internal/metrics"
+)
+
+// Default per-namespace bounds on VPA writes made by the workload webhooks
+const (
+	DefaultVPAWritesPerSecond = 5
+	DefaultVPAWriteBurst      = 20
+)
+
+// maxIdleBuckets is how many namespace buckets are kept before full ones are dropped
+const maxIdleBuckets = 1024
+
+// NamespaceRateLimiter bounds the VPA writes the workload webhooks make per namespace with
+// a token bucket, so a controller creating and deleting workloads in a tight loop cannot
+// cause a VPA write storm. Writes over the limit are skipped and left to the reconciler,
+// which creates, updates or deletes those VPAs on its next pass. A nil limiter allows
+// every write.
+type NamespaceRateLimiter struct {
+	limit rate.Limit
+	burst int
+
+	mu      sync.Mutex
+	buckets map[string]*rate.Limiter
+}
+
+// NewNamespaceRateLimiter returns a limiter allowing perSecond writes per namespace with
+// bursts of burst writes; it returns nil, allowing every write, when perSecond is not positive
+func NewNamespaceRateLimiter(perSecond float64, burst int) *NamespaceRateLimiter {
+	if perSecond <= 0 {
+		return nil
+	}
+	if burst < 1 {
+		burst = 1
+	}
+	return &NamespaceRateLimiter{
+		limit:   rate.Limit(perSecond),
+		burst:   burst,
+		buckets: map[string]*rate.Limiter{},
+	}
+}
+
+// Allow reports whether a VPA in namespace may be written now, taking a token if so
+func (l *NamespaceRateLimiter) Allow(namespace string) bool {
+	return l.allowAt(namespace, time.Now())
+}
+
+// allowAt is Allow at a given time
+func (l *NamespaceRateLimiter) allowAt(namespace string, now time.Time) bool {
+	if l == nil {
+		return true
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	bucket, ok := l.buckets[namespace]
+	if !ok {
+		if len(l.buckets) >= maxIdleBuckets {
+			l.dropFullBuckets(now)
+		}
+		bucket = rate.NewLimiter(l.limit, l.burst)
+		l.buckets[namespace] = bucket
+	}
+	return bucket.AllowN(now, 1)
+}
+
+// dropFullBuckets forgets namespaces whose bucket has refilled, since a new bucket is full too
+func (l *NamespaceRateLimiter) dropFullBuckets(now time.Time) {
+	for namespace, bucket := range l.buckets {
+		if bucket.TokensAt(now) >= float64(l.burst) {
+			delete(l.buckets, namespace)
+		}
+	}
+}
+
+// allowWrite reports whether a webhook may write a VPA in namespace, counting skipped writes
+func allowWrite(limiter *NamespaceRateLimiter, m *metrics.Metrics, webhook, namespace, vpaManagerName string) bool {
+	if limiter.Allow(namespace) {
+		return true
+	}
+	m.RecordWebhookRateLimited(webhook, vpaManagerName)
+	return false
+}
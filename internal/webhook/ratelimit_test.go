@@ -0,0 +1,139 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	admissionv1 "k8s.io/api/admission/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	autoscalingv1 "github.com/joaomo/k8s_op_vpa/api/v1"
+)
+
+func TestNamespaceRateLimiter(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	type attempt struct {
+		namespace string
+		after     time.Duration
+		allowed   bool
+	}
+	tests := []struct {
+		name      string
+		perSecond float64
+		burst     int
+		attempts  []attempt
+	}{
+		{
+			name:      "disabled allows everything",
+			perSecond: 0,
+			attempts:  []attempt{{"a", 0, true}, {"a", 0, true}, {"a", 0, true}},
+		},
+		{
+			name:      "burst then limited",
+			perSecond: 1,
+			burst:     2,
+			attempts:  []attempt{{"a", 0, true}, {"a", 0, true}, {"a", 0, false}},
+		},
+		{
+			name:      "refills over time",
+			perSecond: 1,
+			burst:     1,
+			attempts:  []attempt{{"a", 0, true}, {"a", 500 * time.Millisecond, false}, {"a", time.Second, true}},
+		},
+		{
+			name:      "namespaces have separate buckets",
+			perSecond: 1,
+			burst:     1,
+			attempts:  []attempt{{"a", 0, true}, {"a", 0, false}, {"b", 0, true}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limiter := NewNamespaceRateLimiter(tt.perSecond, tt.burst)
+			for i, a := range tt.attempts {
+				assert.Equal(t, a.allowed, limiter.allowAt(a.namespace, start.Add(a.after)), "attempt %d", i)
+			}
+		})
+	}
+}
+
+func TestNamespaceRateLimiter_DropsFullBuckets(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	limiter := NewNamespaceRateLimiter(1, 1)
+
+	for i := 0; i < maxIdleBuckets; i++ {
+		limiter.allowAt(fmt.Sprintf("ns-%d", i), start)
+	}
+	require.Len(t, limiter.buckets, maxIdleBuckets)
+
+	// Once every bucket has refilled, a new namespace replaces them
+	assert.True(t, limiter.allowAt("new", start.Add(time.Second)))
+	assert.Len(t, limiter.buckets, 1)
+}
+
+// Test: Webhook VPA writes over the namespace rate limit are skipped and left to the reconciler
+func TestDeploymentWebhook_RateLimitsVPAWritesPerNamespace(t *testing.T) {
+	scheme := setupScheme(t)
+	ctx := context.Background()
+
+	selected := map[string]string{"vpa-enabled": "true"}
+	vpaManager := &autoscalingv1.VpaManager{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-vpamanager"},
+		Spec: autoscalingv1.VpaManagerSpec{
+			Enabled:            true,
+			UpdateMode:         "Off",
+			NamespaceSelector:  &metav1.LabelSelector{MatchLabels: selected},
+			DeploymentSelector: &metav1.LabelSelector{MatchLabels: selected},
+		},
+	}
+
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(
+			vpaManager,
+			&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "busy", Labels: selected}},
+			&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "quiet", Labels: selected}},
+		).
+		Build()
+
+	m := createTestMetrics()
+	handler := &DeploymentWebhookHandler{
+		Client:      fakeClient,
+		Scheme:      scheme,
+		Metrics:     m,
+		RateLimiter: NewNamespaceRateLimiter(0.001, 2),
+	}
+
+	create := func(namespace, name string) {
+		deployment := &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, Labels: selected},
+			Spec:       createDeploymentSpec(),
+		}
+		resp := handler.Handle(ctx, createAdmissionRequest(t, admissionv1.Create, deployment, nil))
+		assert.True(t, resp.Allowed, "rate limiting must never reject a workload")
+	}
+	for _, name := range []string{"a", "b", "c", "d"} {
+		create("busy", name)
+	}
+	create("quiet", "a")
+
+	count := func(namespace string) int {
+		vpaList := newVPAList()
+		require.NoError(t, fakeClient.List(ctx, vpaList, client.InNamespace(namespace)))
+		return len(vpaList.Items)
+	}
+	assert.Equal(t, 2, count("busy"), "writes over the burst should be skipped")
+	assert.Equal(t, 1, count("quiet"), "other namespaces should not be limited")
+	assert.Equal(t, float64(2), testutil.ToFloat64(m.WebhookRateLimitedTotal.WithLabelValues("deployment", "test-vpamanager")))
+}
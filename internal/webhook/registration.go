@@ -0,0 +1,265 @@
+package webhook
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"time"
+
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/healthz"
+)
+
+// Paths the admission handlers are served on
+const (
+	DeploymentWebhookPath = "/mutate-apps-v1-deployment"
+	VpaManagerWebhookPath = "/validate-operators-joaomo-io-v1-vpamanager"
+)
+
+// DefaultRegistrationPollInterval is how often the Registrar checks whether the server is ready
+const DefaultRegistrationPollInterval = 2 * time.Second
+
+// registrationCleanupTimeout bounds removing the configurations on shutdown
+const registrationCleanupTimeout = 10 * time.Second
+
+// Registrar registers the webhook configurations only once the webhook server is serving
+// with a valid certificate, so admission requests never reach an endpoint that cannot
+// answer. In ephemeral mode the configurations are removed again on graceful shutdown.
+type Registrar struct {
+	Client client.Client
+
+	// Name is used for both the mutating and the validating webhook configuration
+	Name string
+
+	// Service is the Service in front of the webhook server
+	Service admissionregistrationv1.ServiceReference
+
+	// CertFile is the serving certificate, which must be valid before registering
+	CertFile string
+
+	// CAFile is the CA bundle given to the API server; CertFile is used when empty or missing
+	CAFile string
+
+	// Started reports whether the webhook server is serving, e.g. the server's StartedChecker
+	Started healthz.Checker
+
+	// Ephemeral removes the configurations on graceful shutdown
+	Ephemeral bool
+
+	// PollInterval is how often readiness is checked, DefaultRegistrationPollInterval when zero
+	PollInterval time.Duration
+}
+
+// NeedLeaderElection makes only the leader manage the configurations
+func (r *Registrar) NeedLeaderElection() bool {
+	return true
+}
+
+// Start waits for the webhook server, registers the configurations and, in ephemeral
+// mode, removes them when ctx is cancelled
+func (r *Registrar) Start(ctx context.Context) error {
+	log := ctrl.LoggerFrom(ctx).WithName("webhook-registrar").WithValues("configuration", r.Name)
+
+	interval := r.PollInterval
+	if interval == 0 {
+		interval = DefaultRegistrationPollInterval
+	}
+
+	err := wait.PollUntilContextCancel(ctx, interval, true, func(ctx context.Context) (bool, error) {
+		if err := r.ready(); err != nil {
+			log.V(1).Info("webhook server not ready, postponing registration", "reason", err.Error())
+			return false, nil
+		}
+		if err := r.register(ctx); err != nil {
+			log.Error(err, "failed to register webhook configurations, retrying")
+			return false, nil
+		}
+		return true, nil
+	})
+	if err != nil {
+		// Shut down before the server became ready, nothing was registered
+		return nil
+	}
+	log.Info("registered webhook configurations")
+
+	<-ctx.Done()
+	if !r.Ephemeral {
+		return nil
+	}
+
+	cleanupCtx, cancel := context.WithTimeout(context.Background(), registrationCleanupTimeout)
+	defer cancel()
+	if err := r.deregister(cleanupCtx); err != nil {
+		return fmt.Errorf("removing webhook configurations: %w", err)
+	}
+	log.Info("removed webhook configurations")
+	return nil
+}
+
+// ready checks that the server is serving and its certificate is currently valid
+func (r *Registrar) ready() error {
+	if r.Started != nil {
+		if err := r.Started(nil); err != nil {
+			return err
+		}
+	}
+	_, err := loadValidCertificate(r.CertFile, time.Now())
+	return err
+}
+
+// register creates or updates both webhook configurations
+func (r *Registrar) register(ctx context.Context) error {
+	caBundle, err := r.caBundle()
+	if err != nil {
+		return err
+	}
+
+	mutating := &admissionregistrationv1.MutatingWebhookConfiguration{ObjectMeta: metav1.ObjectMeta{Name: r.Name}}
+	if _, err := controllerutil.CreateOrUpdate(ctx, r.Client, mutating, func() error {
+		mutating.Labels = registrationLabels(mutating.Labels)
+		mutating.Webhooks = r.mutatingWebhooks(caBundle)
+		return nil
+	}); err != nil {
+		return fmt.Errorf("mutating webhook configuration: %w", err)
+	}
+
+	validating := &admissionregistrationv1.ValidatingWebhookConfiguration{ObjectMeta: metav1.ObjectMeta{Name: r.Name}}
+	if _, err := controllerutil.CreateOrUpdate(ctx, r.Client, validating, func() error {
+		validating.Labels = registrationLabels(validating.Labels)
+		validating.Webhooks = r.validatingWebhooks(caBundle)
+		return nil
+	}); err != nil {
+		return fmt.Errorf("validating webhook configuration: %w", err)
+	}
+	return nil
+}
+
+// deregister deletes both webhook configurations
+func (r *Registrar) deregister(ctx context.Context) error {
+	for _, obj := range []client.Object{
+		&admissionregistrationv1.MutatingWebhookConfiguration{ObjectMeta: metav1.ObjectMeta{Name: r.Name}},
+		&admissionregistrationv1.ValidatingWebhookConfiguration{ObjectMeta: metav1.ObjectMeta{Name: r.Name}},
+	} {
+		if err := r.Client.Delete(ctx, obj); err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// caBundle returns the PEM CA bundle the API server uses to verify the webhook server
+func (r *Registrar) caBundle() ([]byte, error) {
+	if r.CAFile != "" {
+		data, err := os.ReadFile(r.CAFile)
+		if err == nil {
+			return data, nil
+		}
+		if !os.IsNotExist(err) {
+			return nil, err
+		}
+	}
+	// Self-signed serving certificate
+	return os.ReadFile(r.CertFile)
+}
+
+// clientConfig points a webhook at the Service path
+func (r *Registrar) clientConfig(path string, caBundle []byte) admissionregistrationv1.WebhookClientConfig {
+	service := r.Service.DeepCopy()
+	service.Path = &path
+	return admissionregistrationv1.WebhookClientConfig{Service: service, CABundle: caBundle}
+}
+
+// mutatingWebhooks describes the Deployment webhook. Failures are ignored because the
+// reconciler converges VPAs anyway.
+func (r *Registrar) mutatingWebhooks(caBundle []byte) []admissionregistrationv1.MutatingWebhook {
+	failurePolicy := admissionregistrationv1.Ignore
+	sideEffects := admissionregistrationv1.SideEffectClassNoneOnDryRun
+	timeout := int32(5)
+	return []admissionregistrationv1.MutatingWebhook{{
+		Name:                    "deployments.vpa-operator.io",
+		ClientConfig:            r.clientConfig(DeploymentWebhookPath, caBundle),
+		FailurePolicy:           &failurePolicy,
+		SideEffects:             &sideEffects,
+		TimeoutSeconds:          &timeout,
+		AdmissionReviewVersions: []string{"v1"},
+		Rules: []admissionregistrationv1.RuleWithOperations{{
+			Operations: []admissionregistrationv1.OperationType{
+				admissionregistrationv1.Create, admissionregistrationv1.Update, admissionregistrationv1.Delete,
+			},
+			Rule: admissionregistrationv1.Rule{
+				APIGroups:   []string{"apps"},
+				APIVersions: []string{"v1"},
+				Resources:   []string{"deployments"},
+			},
+		}},
+	}}
+}
+
+// validatingWebhooks describes the VpaManager webhook. Failures reject the request: the
+// reconciler re-validates every spec, but only admission sees the old object, so a change of
+// the tenant label would otherwise slip through while the webhook is unavailable.
+func (r *Registrar) validatingWebhooks(caBundle []byte) []admissionregistrationv1.ValidatingWebhook {
+	failurePolicy := admissionregistrationv1.Fail
+	sideEffects := admissionregistrationv1.SideEffectClassNone
+	timeout := int32(5)
+	return []admissionregistrationv1.ValidatingWebhook{{
+		Name:                    "vpamanagers.vpa-operator.io",
+		ClientConfig:            r.clientConfig(VpaManagerWebhookPath, caBundle),
+		FailurePolicy:           &failurePolicy,
+		SideEffects:             &sideEffects,
+		TimeoutSeconds:          &timeout,
+		AdmissionReviewVersions: []string{"v1"},
+		Rules: []admissionregistrationv1.RuleWithOperations{{
+			Operations: []admissionregistrationv1.OperationType{
+				admissionregistrationv1.Create, admissionregistrationv1.Update,
+			},
+			Rule: admissionregistrationv1.Rule{
+				APIGroups:   []string{"operators.joaomo.io"},
+				APIVersions: []string{"v1"},
+				Resources:   []string{"vpamanagers"},
+			},
+		}},
+	}}
+}
+
+// registrationLabels marks a configuration as managed by the operator
+func registrationLabels(labels map[string]string) map[string]string {
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	labels["app.kubernetes.io/managed-by"] = "vpa-operator"
+	return labels
+}
+
+// loadValidCertificate parses the first certificate in certFile and checks it is valid at now
+func loadValidCertificate(certFile string, now time.Time) (*x509.Certificate, error) {
+	cert, err := loadCertificate(certFile)
+	if err != nil {
+		return nil, err
+	}
+	if now.Before(cert.NotBefore) || now.After(cert.NotAfter) {
+		return nil, fmt.Errorf("certificate is valid from %s to %s", cert.NotBefore.Format(time.RFC3339), cert.NotAfter.Format(time.RFC3339))
+	}
+	return cert, nil
+}
+
+// loadCertificate parses the first certificate in certFile
+func loadCertificate(certFile string) (*x509.Certificate, error) {
+	data, err := os.ReadFile(certFile)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("%s contains no PEM certificate", certFile)
+	}
+	return x509.ParseCertificate(block.Bytes)
+}
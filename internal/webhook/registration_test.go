@@ -0,0 +1,166 @@
+package webhook
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// writeCertificate writes a self-signed certificate valid between notBefore and notAfter
+func writeCertificate(t *testing.T, dir string, notBefore, notAfter time.Time) string {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "vpa-operator-webhook.vpa-system.svc"},
+		NotBefore:    notBefore,
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	path := filepath.Join(dir, "tls.crt")
+	require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+	return path
+}
+
+// registrationScheme extends the webhook test scheme with the webhook configuration types
+func registrationScheme(t *testing.T) *runtime.Scheme {
+	scheme := setupScheme(t)
+	require.NoError(t, admissionregistrationv1.AddToScheme(scheme))
+	return scheme
+}
+
+func TestRegistrar_RegistersOnlyWhenReady(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name         string
+		notBefore    time.Time
+		notAfter     time.Time
+		started      error
+		ephemeral    bool
+		wantRegister bool
+		wantRemains  bool
+	}{
+		{
+			name:         "serving with valid certificate",
+			notBefore:    now.Add(-time.Hour),
+			notAfter:     now.Add(time.Hour),
+			wantRegister: true,
+			wantRemains:  true,
+		},
+		{
+			name:         "ephemeral removes on shutdown",
+			notBefore:    now.Add(-time.Hour),
+			notAfter:     now.Add(time.Hour),
+			ephemeral:    true,
+			wantRegister: true,
+		},
+		{
+			name:      "expired certificate",
+			notBefore: now.Add(-2 * time.Hour),
+			notAfter:  now.Add(-time.Hour),
+		},
+		{
+			name:      "server not started",
+			notBefore: now.Add(-time.Hour),
+			notAfter:  now.Add(time.Hour),
+			started:   errors.New("webhook server has not been started yet"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheme := registrationScheme(t)
+			fakeClient := fake.NewClientBuilder().WithScheme(scheme).Build()
+
+			port := int32(443)
+			registrar := &Registrar{
+				Client:       fakeClient,
+				Name:         "vpa-operator",
+				Service:      admissionregistrationv1.ServiceReference{Namespace: "vpa-system", Name: "vpa-operator-webhook", Port: &port},
+				CertFile:     writeCertificate(t, t.TempDir(), tt.notBefore, tt.notAfter),
+				Started:      func(*http.Request) error { return tt.started },
+				Ephemeral:    tt.ephemeral,
+				PollInterval: 10 * time.Millisecond,
+			}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			done := make(chan error)
+			go func() { done <- registrar.Start(ctx) }()
+
+			key := types.NamespacedName{Name: "vpa-operator"}
+			mutating := &admissionregistrationv1.MutatingWebhookConfiguration{}
+			if tt.wantRegister {
+				require.Eventually(t, func() bool {
+					return fakeClient.Get(context.Background(), key, mutating) == nil
+				}, time.Second, 10*time.Millisecond)
+				require.Len(t, mutating.Webhooks, 1)
+				assert.Equal(t, DeploymentWebhookPath, *mutating.Webhooks[0].ClientConfig.Service.Path)
+				assert.NotEmpty(t, mutating.Webhooks[0].ClientConfig.CABundle)
+
+				validating := &admissionregistrationv1.ValidatingWebhookConfiguration{}
+				require.NoError(t, fakeClient.Get(context.Background(), key, validating))
+				assert.Equal(t, VpaManagerWebhookPath, *validating.Webhooks[0].ClientConfig.Service.Path)
+				assert.Equal(t, admissionregistrationv1.Ignore, *mutating.Webhooks[0].FailurePolicy)
+				assert.Equal(t, admissionregistrationv1.Fail, *validating.Webhooks[0].FailurePolicy)
+			} else {
+				time.Sleep(50 * time.Millisecond)
+			}
+
+			cancel()
+			require.NoError(t, <-done)
+
+			err := fakeClient.Get(context.Background(), key, mutating)
+			if tt.wantRemains {
+				assert.NoError(t, err)
+			} else {
+				assert.True(t, apierrors.IsNotFound(err), "configuration should not exist, got %v", err)
+			}
+		})
+	}
+}
+
+func TestRegistrar_UpdatesExistingConfiguration(t *testing.T) {
+	scheme := registrationScheme(t)
+	stale := &admissionregistrationv1.MutatingWebhookConfiguration{}
+	stale.Name = "vpa-operator"
+	stale.Webhooks = []admissionregistrationv1.MutatingWebhook{{Name: "stale.vpa-operator.io"}}
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(stale).Build()
+
+	now := time.Now()
+	registrar := &Registrar{
+		Client:   fakeClient,
+		Name:     "vpa-operator",
+		Service:  admissionregistrationv1.ServiceReference{Namespace: "vpa-system", Name: "vpa-operator-webhook"},
+		CertFile: writeCertificate(t, t.TempDir(), now.Add(-time.Hour), now.Add(time.Hour)),
+	}
+	require.NoError(t, registrar.register(context.Background()))
+
+	updated := &admissionregistrationv1.MutatingWebhookConfiguration{}
+	require.NoError(t, fakeClient.Get(context.Background(), client.ObjectKeyFromObject(stale), updated))
+	require.Len(t, updated.Webhooks, 1)
+	assert.Equal(t, "deployments.vpa-operator.io", updated.Webhooks[0].Name)
+	assert.Equal(t, "vpa-operator", updated.Labels["app.kubernetes.io/managed-by"])
+}
@@ -19,7 +19,14 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 
 	autoscalingv1 "github.com/joaomo/k8s_op_vpa/api/v1"
+	"github.com/joaomo/k8s_op_vpa/internal/decisions"
+	"github.com/joaomo/k8s_op_vpa/internal/inheritance"
+	"github.com/joaomo/k8s_op_vpa/internal/labelselector"
 	"github.com/joaomo/k8s_op_vpa/internal/metrics"
+	"github.com/joaomo/k8s_op_vpa/internal/policy"
+	"github.com/joaomo/k8s_op_vpa/internal/validation"
+	"github.com/joaomo/k8s_op_vpa/internal/vpa"
+	"github.com/joaomo/k8s_op_vpa/internal/workload"
 )
 
 // StatefulSetWebhookHandler handles admission requests for StatefulSets
@@ -28,6 +35,36 @@ type StatefulSetWebhookHandler struct {
 	Scheme  *runtime.Scheme
 	Metrics *metrics.Metrics
 	decoder *admission.Decoder
+
+	// ClientTimeout bounds client calls per admission request, DefaultClientTimeout when zero
+	ClientTimeout time.Duration
+
+	// Self is the operator's own workload, which never gets a VPA; nil disables the check
+	Self *workload.Self
+
+	// SelectorDefaults fills in the selectors a VpaManager omits, like the reconciler; nil
+	// leaves them omitted
+	SelectorDefaults *policy.SelectorDefaults
+
+	// Ownership is the label that marks this instance's VPAs; VPAs without it are never deleted
+	Ownership vpa.Ownership
+
+	// RateLimiter bounds VPA writes per namespace; writes over the limit are left to the
+	// reconciler. nil allows every write.
+	RateLimiter *NamespaceRateLimiter
+
+	// Capacity caps the VPAs managed across the cluster; nil creates VPAs without a cap
+	Capacity *vpa.ClusterCapacity
+
+	// Selectors caches compiled label selectors per VpaManager generation; nil compiles them per request
+	Selectors *labelselector.Cache
+
+	// TopologyGuard turns VPAs Off while pods they evict could not be rescheduled because
+	// of required pod anti-affinity, like the reconciler
+	TopologyGuard bool
+
+	// Decisions keeps the recent per-workload decisions for debugging; nil records nothing
+	Decisions *decisions.Log
 }
 
 // Handle implements the admission.Handler interface
@@ -35,120 +72,194 @@ func (h *StatefulSetWebhookHandler) Handle(ctx context.Context, req admission.Re
 	start := time.Now()
 	log := ctrl.LoggerFrom(ctx).WithValues("webhook", "statefulset", "operation", req.Operation)
 
+	// vpaManagerName attributes the request to the VpaManager it matched, if any
+	var vpaManagerName string
 	var err error
 	defer func() {
-		h.Metrics.RecordWebhookRequest(string(req.Operation), start, err)
+		h.Metrics.RecordWebhookRequest(string(req.Operation), vpaManagerName, start, err)
 	}()
 
+	// The webhook is registered with sideEffects NoneOnDryRun, so dry runs must not touch VPAs
+	if req.DryRun != nil && *req.DryRun {
+		return admission.Allowed("statefulset dry run, no VPA changes")
+	}
+
+	ctx, cancel := clientContext(ctx, h.ClientTimeout)
+	defer cancel()
+	// VPAs written for this request are stamped with its UID
+	ctx = vpa.WithReconcileID(ctx, string(req.UID))
+
 	switch req.Operation {
 	case admissionv1.Create:
-		err = h.handleCreate(ctx, req)
+		vpaManagerName, err = h.handleCreate(ctx, req)
 	case admissionv1.Update:
-		err = h.handleUpdate(ctx, req)
+		vpaManagerName, err = h.handleUpdate(ctx, req)
 	case admissionv1.Delete:
-		err = h.handleDelete(ctx, req)
+		vpaManagerName, err = h.handleDelete(ctx, req)
 	}
 
-	if err != nil {
+	if err != nil && ctx.Err() == context.DeadlineExceeded {
+		// The reconciler will converge the VPA, admission latency matters more
+		log.Info("webhook client calls exceeded their deadline, allowing without VPA changes", "error", err.Error())
+		h.Metrics.RecordWebhookTimeout("statefulset", vpaManagerName)
+	} else if err != nil {
 		log.Error(err, "webhook handler error")
 	}
 
 	return admission.Allowed("statefulset processed")
 }
 
-// handleCreate handles statefulset creation
-func (h *StatefulSetWebhookHandler) handleCreate(ctx context.Context, req admission.Request) error {
+// handleCreate handles statefulset creation and returns the name of the matching VpaManager
+func (h *StatefulSetWebhookHandler) handleCreate(ctx context.Context, req admission.Request) (string, error) {
 	sts := &appsv1.StatefulSet{}
 	if err := json.Unmarshal(req.Object.Raw, sts); err != nil {
-		return fmt.Errorf("failed to decode statefulset: %w", err)
+		return "", fmt.Errorf("failed to decode statefulset: %w", err)
 	}
 
 	vpaManager, err := h.findMatchingVpaManager(ctx, sts)
 	if err != nil {
-		return err
+		return "", err
 	}
 	if vpaManager == nil {
-		return nil
+		return "", nil
 	}
 
-	vpaName := fmt.Sprintf("%s-vpa", sts.Name)
+	vpaName, err := vpaNameFor(vpaManager, "StatefulSet", sts)
+	if err != nil {
+		return vpaManager.Name, err
+	}
 	if err := h.createVPA(ctx, vpaManager, sts, vpaName); err != nil {
-		return err
+		return vpaManager.Name, err
 	}
 
-	h.Metrics.RecordVPAOperation("create", vpaManager.Name)
-	return nil
+	h.scope(vpaManager.Name, sts.Namespace).RecordVPAOperation("create")
+	h.recordDecision(vpaManager.Name, sts, vpaName, decisions.ActionCreated, "Selected")
+	return vpaManager.Name, nil
 }
 
-// handleUpdate handles statefulset updates
-func (h *StatefulSetWebhookHandler) handleUpdate(ctx context.Context, req admission.Request) error {
+// handleUpdate handles statefulset updates and returns the name of the VpaManager that now
+// matches, or previously matched when none does anymore
+func (h *StatefulSetWebhookHandler) handleUpdate(ctx context.Context, req admission.Request) (string, error) {
 	newSts := &appsv1.StatefulSet{}
 	if err := json.Unmarshal(req.Object.Raw, newSts); err != nil {
-		return fmt.Errorf("failed to decode new statefulset: %w", err)
+		return "", fmt.Errorf("failed to decode new statefulset: %w", err)
 	}
 
 	oldSts := &appsv1.StatefulSet{}
 	if err := json.Unmarshal(req.OldObject.Raw, oldSts); err != nil {
-		return fmt.Errorf("failed to decode old statefulset: %w", err)
+		return "", fmt.Errorf("failed to decode old statefulset: %w", err)
+	}
+
+	// Updates that leave spec, labels and annotations alone cannot change the VPA
+	if !vpaInputsChanged(oldSts, newSts) {
+		h.Metrics.RecordWebhookUpdateSkipped("statefulset")
+		return "", nil
 	}
 
 	newVpaManager, err := h.findMatchingVpaManager(ctx, newSts)
 	if err != nil {
-		return err
+		return "", err
 	}
 
 	oldVpaManager, err := h.findMatchingVpaManager(ctx, oldSts)
 	if err != nil {
-		return err
+		return "", err
 	}
 
-	vpaName := fmt.Sprintf("%s-vpa", newSts.Name)
+	vpaManagerName := ""
+	if newVpaManager != nil {
+		vpaManagerName = newVpaManager.Name
+	} else if oldVpaManager != nil {
+		vpaManagerName = oldVpaManager.Name
+	}
 
 	if oldVpaManager == nil && newVpaManager != nil {
+		vpaName, err := vpaNameFor(newVpaManager, "StatefulSet", newSts)
+		if err != nil {
+			return vpaManagerName, err
+		}
 		if err := h.createVPA(ctx, newVpaManager, newSts, vpaName); err != nil {
-			return err
+			return vpaManagerName, err
 		}
-		h.Metrics.RecordVPAOperation("create", newVpaManager.Name)
+		h.scope(newVpaManager.Name, newSts.Namespace).RecordVPAOperation("create")
+		h.recordDecision(newVpaManager.Name, newSts, vpaName, decisions.ActionCreated, "Selected")
 	} else if oldVpaManager != nil && newVpaManager == nil {
-		if err := h.deleteVPA(ctx, newSts.Namespace, vpaName); err != nil {
-			return err
+		vpaName, err := vpaNameFor(oldVpaManager, "StatefulSet", newSts)
+		if err != nil {
+			return vpaManagerName, err
 		}
-		h.Metrics.RecordVPAOperation("delete", oldVpaManager.Name)
+		if err := h.deleteVPA(ctx, oldVpaManager.Name, newSts.Namespace, vpaName); err != nil {
+			return vpaManagerName, err
+		}
+		h.scope(oldVpaManager.Name, newSts.Namespace).RecordVPAOperation("delete")
+		h.recordDecision(oldVpaManager.Name, newSts, vpaName, decisions.ActionDeleted, "NoLongerSelected")
 	} else if newVpaManager != nil {
+		vpaName, err := vpaNameFor(newVpaManager, "StatefulSet", newSts)
+		if err != nil {
+			return vpaManagerName, err
+		}
 		if err := h.updateVPA(ctx, newVpaManager, newSts, vpaName); err != nil {
-			return err
+			return vpaManagerName, err
 		}
 	}
 
-	return nil
+	return vpaManagerName, nil
 }
 
-// handleDelete handles statefulset deletion
-func (h *StatefulSetWebhookHandler) handleDelete(ctx context.Context, req admission.Request) error {
+// handleDelete handles statefulset deletion and returns the name of the matching VpaManager
+func (h *StatefulSetWebhookHandler) handleDelete(ctx context.Context, req admission.Request) (string, error) {
 	sts := &appsv1.StatefulSet{}
 	if err := json.Unmarshal(req.OldObject.Raw, sts); err != nil {
-		return fmt.Errorf("failed to decode statefulset: %w", err)
+		return "", fmt.Errorf("failed to decode statefulset: %w", err)
 	}
 
 	vpaManager, err := h.findMatchingVpaManager(ctx, sts)
 	if err != nil {
-		return err
+		return "", err
 	}
 	if vpaManager == nil {
-		return nil
+		return "", nil
 	}
 
-	vpaName := fmt.Sprintf("%s-vpa", sts.Name)
-	if err := h.deleteVPA(ctx, sts.Namespace, vpaName); err != nil {
-		return err
+	vpaName, err := vpaNameFor(vpaManager, "StatefulSet", sts)
+	if err != nil {
+		return vpaManager.Name, err
+	}
+	if err := h.deleteVPA(ctx, vpaManager.Name, sts.Namespace, vpaName); err != nil {
+		return vpaManager.Name, err
 	}
 
-	h.Metrics.RecordVPAOperation("delete", vpaManager.Name)
-	return nil
+	h.scope(vpaManager.Name, sts.Namespace).RecordVPAOperation("delete")
+	h.recordDecision(vpaManager.Name, sts, vpaName, decisions.ActionDeleted, "WorkloadDeleted")
+	return vpaManager.Name, nil
+}
+
+// scope returns the metrics scope of a StatefulSet in namespace managed by a VpaManager
+func (h *StatefulSetWebhookHandler) scope(vpaManagerName, namespace string) metrics.Scope {
+	return h.Metrics.For(vpaManagerName).WithKind("StatefulSet").WithNamespace(namespace)
+}
+
+// recordDecision records the decision made about the VPA of a StatefulSet
+func (h *StatefulSetWebhookHandler) recordDecision(vpaManagerName string, obj client.Object, vpaName, action, reason string) {
+	h.Decisions.Record(decisions.Decision{
+		Source:     decisions.SourceWebhook,
+		VpaManager: vpaManagerName,
+		Kind:       "StatefulSet",
+		Namespace:  obj.GetNamespace(),
+		Name:       obj.GetName(),
+		VPA:        vpaName,
+		Action:     action,
+		Reason:     reason,
+	})
 }
 
-// findMatchingVpaManager finds a VpaManager that matches the statefulset
+// findMatchingVpaManager finds the VpaManager of the highest priority that matches the
+// statefulset, none in a paused namespace
 func (h *StatefulSetWebhookHandler) findMatchingVpaManager(ctx context.Context, sts *appsv1.StatefulSet) (*autoscalingv1.VpaManager, error) {
+	if h.Self.MatchesObject("StatefulSet", sts.Namespace, sts.Name) || workload.Excluded(sts) {
+		return nil, nil
+	}
+
 	vpaManagerList := &autoscalingv1.VpaManagerList{}
 	if err := h.Client.List(ctx, vpaManagerList); err != nil {
 		return nil, err
@@ -158,21 +269,50 @@ func (h *StatefulSetWebhookHandler) findMatchingVpaManager(ctx context.Context,
 	if err := h.Client.Get(ctx, types.NamespacedName{Name: sts.Namespace}, namespace); err != nil {
 		return nil, err
 	}
+	// VPAs in paused namespaces are left alone until the namespace is resumed
+	if workload.NamespacePaused(namespace) {
+		return nil, nil
+	}
 
+	// The matching VpaManager of the highest priority manages the workload
+	autoscalingv1.SortByPriority(vpaManagerList.Items)
 	for _, vm := range vpaManagerList.Items {
 		if !vm.Spec.Enabled {
 			continue
 		}
 
-		if !matchesLabelSelector(namespace.Labels, vm.Spec.NamespaceSelector) {
+		spec, err := inheritance.ResolveSpec(ctx, h.Client, &vm)
+		if err != nil {
+			continue
+		}
+		// So are invalid specs, which the reconciler refuses to act on
+		errs := validation.ValidateVpaManagerSpec(spec)
+		errs = append(errs, validation.ValidateTenantScope(&vm)...)
+		if len(errs) > 0 {
+			continue
+		}
+		spec = h.SelectorDefaults.Apply(spec)
+
+		if !validation.NamespaceInTenant(&vm, namespace) {
+			continue
+		}
+
+		if excludedByLabels(h.Selectors, &vm, spec, namespace.Labels) || !selectsNamespace(spec, namespace.Name, func() bool {
+			return matchesLabelSelector(h.Selectors, &vm, "namespaceSelector", namespace.Labels, spec.NamespaceSelectorTerms())
+		}) {
 			continue
 		}
 
-		if !matchesLabelSelector(sts.Labels, vm.Spec.StatefulSetSelector) {
+		if !spec.MatchAllWorkloads && !matchesLabelSelector(h.Selectors, &vm, "StatefulSet", sts.Labels, spec.WorkloadSelectorTerms("StatefulSet")) {
+			continue
+		}
+		if !matchesWorkloadName(spec, sts.Name) {
 			continue
 		}
 
-		return &vm, nil
+		resolved := vm.DeepCopy()
+		resolved.Spec = *spec
+		return resolved, nil
 	}
 
 	return nil, nil
@@ -190,8 +330,39 @@ func (h *StatefulSetWebhookHandler) createVPA(ctx context.Context, vpaManager *a
 		return err
 	}
 
-	vpa := h.buildVPA(vpaManager, sts, vpaName)
-	return h.Client.Create(ctx, vpa)
+	resolved, err := policy.Resolve(ctx, h.Client, vpaManager, "StatefulSet", sts, &sts.Spec.Template)
+	if err != nil {
+		return err
+	}
+	vpaManager = resolved.VpaManager
+	vpaObj := h.buildVPA(vpaManager, sts, vpaName)
+	spec, _ := vpaObj.Object["spec"].(map[string]interface{})
+	vpa.SetManagedContainerPolicies(vpaObj, vpa.ContainerPolicyNames(spec))
+	vpa.ApplyTraceAnnotations(vpaObj, vpa.TraceAnnotations(sts, vpaManager.Spec.PropagateAnnotations))
+	vpa.ApplyTuningAnnotations(vpaObj, vpaManager.Spec.RecommendationTuning)
+	vpa.ApplyCustomMetadata(vpaObj, vpaManager.Spec.VPALabels, vpaManager.Spec.VPAAnnotations, h.Ownership)
+	vpa.ApplyDormancy(vpaObj, spec, workload.ScaledToZero(&workload.StatefulSetWorkload{StatefulSet: sts}), vpaManager.Spec.Dormancy, time.Now())
+	if err := applyEvictionGuard(ctx, h.Client, h.TopologyGuard, vpaObj, spec, &workload.StatefulSetWorkload{StatefulSet: sts}); err != nil {
+		return err
+	}
+	layer, source := resolved.UpdateModeSource(vpaObj)
+	vpa.RecordUpdateModeChange(vpaObj, "", vpa.UpdateMode(spec), string(layer), source, time.Now())
+	if !allowWrite(h.RateLimiter, h.Metrics, "statefulset", sts.Namespace, vpaManager.Name) {
+		return nil
+	}
+	// Over the cluster-wide cap the VPA is not created; the reconciler reports it
+	allowed, err := h.Capacity.Reserve(ctx, h.Client)
+	if err != nil || !allowed {
+		return err
+	}
+	writeStart := time.Now()
+	vpa.StampReconcile(ctx, vpaObj, writeStart)
+	err = h.Client.Create(ctx, vpaObj)
+	h.scope(vpaManager.Name, sts.Namespace).ObserveVPAWrite("create", writeStart)
+	if err != nil {
+		h.Capacity.Release()
+	}
+	return err
 }
 
 // updateVPA updates a VPA for a statefulset
@@ -206,19 +377,76 @@ func (h *StatefulSetWebhookHandler) updateVPA(ctx context.Context, vpaManager *a
 		return err
 	}
 
+	// VPAs being deleted are recreated by the reconciler once they are gone
+	if existing.GetDeletionTimestamp() != nil || h.Ownership.Foreign(existing) || vpa.GitOpsOwner(existing) != "" {
+		return nil
+	}
+
+	resolved, err := policy.Resolve(ctx, h.Client, vpaManager, "StatefulSet", sts, &sts.Spec.Template)
+	if err != nil {
+		return err
+	}
+	vpaManager = resolved.VpaManager
 	newVPA := h.buildVPA(vpaManager, sts, vpaName)
-	existing.Object["spec"] = newVPA.Object["spec"]
-	return h.Client.Update(ctx, existing)
+	newSpec, _ := newVPA.Object["spec"].(map[string]interface{})
+	existingSpec, _ := existing.Object["spec"].(map[string]interface{})
+	strategy := vpaManager.Spec.ContainerPolicyMerge
+	// A VPA left behind by a deleted statefulset of the same name is regenerated for this one
+	if vpa.StaleTarget(existing, "StatefulSet", sts.Name, sts.UID) {
+		strategy = autoscalingv1.ContainerPolicyMergeOperatorWins
+		vpa.ReplaceOwnerReferences(existing, newVPA.GetOwnerReferences())
+	}
+	managed := vpa.ManagedContainerPolicies(existing, existingSpec)
+	spec, written := vpa.MergeContainerPolicies(strategy, newSpec, existingSpec, managed)
+	vpa.ApplyDormancy(existing, spec, workload.ScaledToZero(&workload.StatefulSetWorkload{StatefulSet: sts}), vpaManager.Spec.Dormancy, time.Now())
+	if err := applyEvictionGuard(ctx, h.Client, h.TopologyGuard, existing, spec, &workload.StatefulSetWorkload{StatefulSet: sts}); err != nil {
+		return err
+	}
+	previousMode, desiredMode := vpa.UpdateMode(existingSpec), vpa.UpdateMode(spec)
+	modeLayer, modeSource := resolved.UpdateModeSource(existing)
+	modeChanged := vpa.RecordUpdateModeChange(existing, previousMode, desiredMode, string(modeLayer), modeSource, time.Now())
+	existing.Object["spec"] = spec
+	vpa.SetManagedContainerPolicies(existing, written)
+	vpa.ApplyTraceAnnotations(existing, vpa.TraceAnnotations(sts, vpaManager.Spec.PropagateAnnotations))
+	vpa.ApplyTuningAnnotations(existing, vpaManager.Spec.RecommendationTuning)
+	vpa.ApplyCustomMetadata(existing, vpaManager.Spec.VPALabels, vpaManager.Spec.VPAAnnotations, h.Ownership)
+	if !allowWrite(h.RateLimiter, h.Metrics, "statefulset", sts.Namespace, vpaManager.Name) {
+		return nil
+	}
+	writeStart := time.Now()
+	vpa.StampReconcile(ctx, existing, writeStart)
+	err = h.Client.Update(ctx, existing)
+	scope := h.scope(vpaManager.Name, sts.Namespace)
+	scope.ObserveVPAWrite("update", writeStart)
+	if err == nil && modeChanged {
+		scope.RecordUpdateModeTransition(previousMode, desiredMode, string(modeLayer))
+	}
+	return err
 }
 
-// deleteVPA deletes a VPA
-func (h *StatefulSetWebhookHandler) deleteVPA(ctx context.Context, namespace, vpaName string) error {
-	vpa := &unstructured.Unstructured{}
-	vpa.SetGroupVersionKind(vpaGVK)
-	vpa.SetName(vpaName)
-	vpa.SetNamespace(namespace)
+// deleteVPA deletes a VPA generated for the named VpaManager. VPAs of other operator
+// instances or created by hand under the same name are left alone.
+func (h *StatefulSetWebhookHandler) deleteVPA(ctx context.Context, vpaManagerName, namespace, vpaName string) error {
+	existing := &unstructured.Unstructured{}
+	existing.SetGroupVersionKind(vpaGVK)
+	err := h.Client.Get(ctx, types.NamespacedName{Name: vpaName, Namespace: namespace}, existing)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	if !h.Ownership.Owns(existing) {
+		return nil
+	}
 
-	err := h.Client.Delete(ctx, vpa)
+	if !allowWrite(h.RateLimiter, h.Metrics, "statefulset", namespace, vpaManagerName) {
+		return nil
+	}
+	uid := existing.GetUID()
+	writeStart := time.Now()
+	err = h.Client.Delete(ctx, existing, client.Preconditions{UID: &uid})
+	h.scope(vpaManagerName, namespace).ObserveVPAWrite("delete", writeStart)
 	if errors.IsNotFound(err) {
 		return nil
 	}
@@ -227,17 +455,14 @@ func (h *StatefulSetWebhookHandler) deleteVPA(ctx context.Context, namespace, vp
 
 // buildVPA creates a VPA unstructured object for a statefulset
 func (h *StatefulSetWebhookHandler) buildVPA(vpaManager *autoscalingv1.VpaManager, sts *appsv1.StatefulSet, vpaName string) *unstructured.Unstructured {
-	vpa := &unstructured.Unstructured{}
-	vpa.SetGroupVersionKind(vpaGVK)
-	vpa.SetName(vpaName)
-	vpa.SetNamespace(sts.Namespace)
-
-	vpa.SetLabels(map[string]string{
-		"app.kubernetes.io/managed-by": "vpa-operator",
-		"app.kubernetes.io/created-by": vpaManager.Name,
-	})
+	vpaObj := &unstructured.Unstructured{}
+	vpaObj.SetGroupVersionKind(vpaGVK)
+	vpaObj.SetName(vpaName)
+	vpaObj.SetNamespace(sts.Namespace)
 
-	vpa.SetOwnerReferences([]metav1.OwnerReference{
+	vpaObj.SetLabels(h.Ownership.Labels(vpaManager.Name))
+
+	vpaObj.SetOwnerReferences([]metav1.OwnerReference{
 		{
 			APIVersion: "apps/v1",
 			Kind:       "StatefulSet",
@@ -246,46 +471,8 @@ func (h *StatefulSetWebhookHandler) buildVPA(vpaManager *autoscalingv1.VpaManage
 		},
 	})
 
-	spec := map[string]interface{}{
-		"targetRef": map[string]interface{}{
-			"apiVersion": "apps/v1",
-			"kind":       "StatefulSet",
-			"name":       sts.Name,
-		},
-		"updatePolicy": map[string]interface{}{
-			"updateMode": vpaManager.Spec.UpdateMode,
-		},
-	}
-
-	if vpaManager.Spec.ResourcePolicy != nil && len(vpaManager.Spec.ResourcePolicy.ContainerPolicies) > 0 {
-		containerPolicies := make([]interface{}, 0, len(vpaManager.Spec.ResourcePolicy.ContainerPolicies))
-		for _, cp := range vpaManager.Spec.ResourcePolicy.ContainerPolicies {
-			policy := map[string]interface{}{
-				"containerName": cp.ContainerName,
-			}
-			if cp.MinAllowed != nil {
-				minAllowed := make(map[string]interface{})
-				for k, v := range cp.MinAllowed {
-					minAllowed[k] = v
-				}
-				policy["minAllowed"] = minAllowed
-			}
-			if cp.MaxAllowed != nil {
-				maxAllowed := make(map[string]interface{})
-				for k, v := range cp.MaxAllowed {
-					maxAllowed[k] = v
-				}
-				policy["maxAllowed"] = maxAllowed
-			}
-			containerPolicies = append(containerPolicies, policy)
-		}
-		spec["resourcePolicy"] = map[string]interface{}{
-			"containerPolicies": containerPolicies,
-		}
-	}
-
-	vpa.Object["spec"] = spec
-	return vpa
+	vpaObj.Object["spec"] = vpa.BuildSpec(vpaManager, "StatefulSet", sts.Name)
+	return vpaObj
 }
 
 // InjectDecoder injects the decoder
@@ -397,11 +397,15 @@ func TestStatefulSetWebhook_AppliesResourcePolicy(t *testing.T) {
 		},
 	}
 
+	minReplicas := int32(2)
 	vpaManager := &autoscalingv1.VpaManager{
 		ObjectMeta: metav1.ObjectMeta{Name: "test-vpamanager"},
 		Spec: autoscalingv1.VpaManagerSpec{
 			Enabled:    true,
 			UpdateMode: "Initial",
+			UpdatePolicy: &autoscalingv1.UpdatePolicy{
+				MinReplicas: &minReplicas,
+			},
 			NamespaceSelector: &metav1.LabelSelector{
 				MatchLabels: map[string]string{"vpa-enabled": "true"},
 			},
@@ -420,6 +424,9 @@ func TestStatefulSetWebhook_AppliesResourcePolicy(t *testing.T) {
 							"cpu":    "2",
 							"memory": "2Gi",
 						},
+						ControlledResources: []string{"memory"},
+						ControlledValues:    autoscalingv1.ControlledValuesRequestsOnly,
+						Mode:                autoscalingv1.ContainerScalingModeAuto,
 					},
 				},
 			},
@@ -462,6 +469,7 @@ func TestStatefulSetWebhook_AppliesResourcePolicy(t *testing.T) {
 
 	updatePolicy := spec["updatePolicy"].(map[string]interface{})
 	assert.Equal(t, "Initial", updatePolicy["updateMode"])
+	assert.Equal(t, int64(2), updatePolicy["minReplicas"])
 
 	resourcePolicy := spec["resourcePolicy"].(map[string]interface{})
 	containerPolicies := resourcePolicy["containerPolicies"].([]interface{})
@@ -471,6 +479,9 @@ func TestStatefulSetWebhook_AppliesResourcePolicy(t *testing.T) {
 	minAllowed := policy["minAllowed"].(map[string]interface{})
 	assert.Equal(t, "50m", minAllowed["cpu"])
 	assert.Equal(t, "64Mi", minAllowed["memory"])
+	assert.Equal(t, []interface{}{"memory"}, policy["controlledResources"])
+	assert.Equal(t, "RequestsOnly", policy["controlledValues"])
+	assert.Equal(t, "Auto", policy["mode"])
 }
 
 // Helper functions
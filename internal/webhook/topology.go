@@ -0,0 +1,25 @@
+package webhook
+
+import (
+	"context"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/joaomo/k8s_op_vpa/internal/vpa"
+	"github.com/joaomo/k8s_op_vpa/internal/workload"
+)
+
+// applyEvictionGuard turns spec, the VPA spec of obj, Off while enabled and pods of wl evicted
+// by the VPA updater could not be rescheduled, mirroring the reconciler's topology guard
+func applyEvictionGuard(ctx context.Context, c client.Reader, enabled bool, obj metav1.Object, spec map[string]interface{}, wl workload.Workload) error {
+	topologyKey, blocked := "", false
+	if enabled {
+		var err error
+		if topologyKey, blocked, err = workload.EvictionBlocked(ctx, c, wl); err != nil {
+			return err
+		}
+	}
+	vpa.ApplyEvictionGuard(obj, spec, topologyKey, blocked)
+	return nil
+}
@@ -0,0 +1,97 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	autoscalingv1 "github.com/joaomo/k8s_op_vpa/api/v1"
+	"github.com/joaomo/k8s_op_vpa/internal/inheritance"
+	"github.com/joaomo/k8s_op_vpa/internal/metrics"
+	"github.com/joaomo/k8s_op_vpa/internal/validation"
+)
+
+// VpaManagerValidator validates VpaManager create and update requests
+type VpaManagerValidator struct {
+	Metrics *metrics.Metrics
+
+	// AutoSafeguards selects whether VpaManagers using Auto mode without updatePolicy.minReplicas
+	// are admitted silently, with a warning or rejected. Empty admits them silently.
+	AutoSafeguards validation.AutoSafeguardMode
+
+	// Client reads the parents of inheriting VpaManagers, so a safeguard set on a parent
+	// counts. nil checks the VpaManager's own spec only.
+	Client client.Reader
+}
+
+// Handle implements the admission.Handler interface
+func (v *VpaManagerValidator) Handle(ctx context.Context, req admission.Request) admission.Response {
+	start := time.Now()
+	log := ctrl.LoggerFrom(ctx).WithValues("webhook", "vpamanager", "operation", req.Operation, "name", req.Name)
+
+	var err error
+	defer func() {
+		v.Metrics.RecordWebhookRequest(string(req.Operation), req.Name, start, err)
+	}()
+
+	if req.Operation != admissionv1.Create && req.Operation != admissionv1.Update {
+		return admission.Allowed("")
+	}
+
+	vpaManager := &autoscalingv1.VpaManager{}
+	if err = json.Unmarshal(req.Object.Raw, vpaManager); err != nil {
+		err = fmt.Errorf("failed to decode vpamanager: %w", err)
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	errs := validation.ValidateVpaManagerSpec(&vpaManager.Spec)
+	errs = append(errs, validation.ValidateTenantScope(vpaManager)...)
+	if req.Operation == admissionv1.Update && len(req.OldObject.Raw) > 0 {
+		oldManager := &autoscalingv1.VpaManager{}
+		if err = json.Unmarshal(req.OldObject.Raw, oldManager); err != nil {
+			err = fmt.Errorf("failed to decode old vpamanager: %w", err)
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+		errs = append(errs, validation.ValidateTenantUpdate(oldManager, vpaManager)...)
+	}
+	safeguardErrs := v.autoSafeguardErrors(ctx, vpaManager)
+	if v.AutoSafeguards == validation.AutoSafeguardsEnforce {
+		errs = append(errs, safeguardErrs...)
+	}
+	if len(errs) > 0 {
+		err = errs.ToAggregate()
+		log.Info("rejecting invalid VpaManager", "errors", err.Error())
+		return admission.Denied(err.Error())
+	}
+
+	warnings := validation.Warnings(&vpaManager.Spec)
+	if v.AutoSafeguards == validation.AutoSafeguardsWarn {
+		for _, safeguardErr := range safeguardErrs {
+			warnings = append(warnings, safeguardErr.Error())
+		}
+	}
+	return admission.Allowed("").WithWarnings(warnings...)
+}
+
+// autoSafeguardErrors checks the Auto mode safeguards of a VpaManager's effective spec.
+// When its parents cannot be read the VpaManager's own spec is checked.
+func (v *VpaManagerValidator) autoSafeguardErrors(ctx context.Context, vpaManager *autoscalingv1.VpaManager) field.ErrorList {
+	if v.AutoSafeguards == "" || v.AutoSafeguards == validation.AutoSafeguardsOff {
+		return nil
+	}
+	spec := &vpaManager.Spec
+	if v.Client != nil {
+		if resolved, err := inheritance.ResolveSpec(ctx, v.Client, vpaManager); err == nil {
+			spec = resolved
+		}
+	}
+	return validation.ValidateAutoSafeguards(spec)
+}
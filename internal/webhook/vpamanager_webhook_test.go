@@ -0,0 +1,278 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	autoscalingv1 "github.com/joaomo/k8s_op_vpa/api/v1"
+	"github.com/joaomo/k8s_op_vpa/internal/validation"
+)
+
+// Test: Validator accepts a well-formed VpaManager
+func TestVpaManagerValidator_AllowsValidSpec(t *testing.T) {
+	validator := &VpaManagerValidator{Metrics: createTestMetrics()}
+
+	vpaManager := &autoscalingv1.VpaManager{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-vpamanager"},
+		Spec: autoscalingv1.VpaManagerSpec{
+			Enabled:    true,
+			UpdateMode: "Auto",
+			ResourcePolicy: &autoscalingv1.ResourcePolicy{
+				ContainerPolicies: []autoscalingv1.ContainerResourcePolicy{{
+					ContainerName: "*",
+					MinAllowed:    map[string]string{"cpu": "100m"},
+					MaxAllowed:    map[string]string{"cpu": "1"},
+				}},
+			},
+		},
+	}
+
+	resp := validator.Handle(context.Background(), createVpaManagerAdmissionRequest(t, admissionv1.Create, vpaManager))
+	assert.True(t, resp.Allowed)
+}
+
+// Test: Validator warns about the deprecated implicit match-all namespace selector
+func TestVpaManagerValidator_WarnsOnImplicitMatchAll(t *testing.T) {
+	validator := &VpaManagerValidator{Metrics: createTestMetrics()}
+
+	tests := []struct {
+		name         string
+		spec         autoscalingv1.VpaManagerSpec
+		wantWarnings int
+	}{
+		{
+			name:         "omitted namespace selector",
+			spec:         autoscalingv1.VpaManagerSpec{Enabled: true, UpdateMode: "Off"},
+			wantWarnings: 1,
+		},
+		{
+			name: "explicit match all",
+			spec: autoscalingv1.VpaManagerSpec{Enabled: true, UpdateMode: "Off", MatchAllNamespaces: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vpaManager := &autoscalingv1.VpaManager{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-vpamanager"},
+				Spec:       tt.spec,
+			}
+			resp := validator.Handle(context.Background(), createVpaManagerAdmissionRequest(t, admissionv1.Create, vpaManager))
+			assert.True(t, resp.Allowed)
+			assert.Len(t, resp.Warnings, tt.wantWarnings)
+		})
+	}
+}
+
+// Test: Tenant-scoped managers cannot widen their namespace selection
+func TestVpaManagerValidator_EnforcesTenantScope(t *testing.T) {
+	validator := &VpaManagerValidator{Metrics: createTestMetrics()}
+
+	scoped := func(selector map[string]string, tenant string) *autoscalingv1.VpaManager {
+		vm := &autoscalingv1.VpaManager{
+			ObjectMeta: metav1.ObjectMeta{Name: "team"},
+			Spec: autoscalingv1.VpaManagerSpec{
+				Enabled:           true,
+				UpdateMode:        "Off",
+				NamespaceSelector: &metav1.LabelSelector{MatchLabels: selector},
+			},
+		}
+		if tenant != "" {
+			vm.Labels = map[string]string{validation.TenantLabel: tenant}
+		}
+		return vm
+	}
+	own := map[string]string{validation.TenantLabel: "payments"}
+	wide := map[string]string{"env": "prod"}
+
+	tests := []struct {
+		name      string
+		operation admissionv1.Operation
+		oldObj    *autoscalingv1.VpaManager
+		newObj    *autoscalingv1.VpaManager
+		allowed   bool
+	}{
+		{
+			name:      "create within tenant",
+			operation: admissionv1.Create,
+			newObj:    scoped(own, "payments"),
+			allowed:   true,
+		},
+		{
+			name:      "create selecting outside tenant",
+			operation: admissionv1.Create,
+			newObj:    scoped(wide, "payments"),
+		},
+		{
+			name:      "update widening the selector",
+			operation: admissionv1.Update,
+			oldObj:    scoped(own, "payments"),
+			newObj:    scoped(wide, "payments"),
+		},
+		{
+			name:      "update dropping the tenant label",
+			operation: admissionv1.Update,
+			oldObj:    scoped(own, "payments"),
+			newObj:    scoped(wide, ""),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := createVpaManagerAdmissionRequest(t, tt.operation, tt.newObj)
+			if tt.oldObj != nil {
+				raw, err := json.Marshal(tt.oldObj)
+				require.NoError(t, err)
+				req.OldObject = runtime.RawExtension{Raw: raw}
+			}
+			resp := validator.Handle(context.Background(), req)
+			assert.Equal(t, tt.allowed, resp.Allowed)
+		})
+	}
+}
+
+// Test: Validator rejects minAllowed greater than maxAllowed with an actionable message
+func TestVpaManagerValidator_RejectsMinAboveMax(t *testing.T) {
+	validator := &VpaManagerValidator{Metrics: createTestMetrics()}
+
+	vpaManager := &autoscalingv1.VpaManager{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-vpamanager"},
+		Spec: autoscalingv1.VpaManagerSpec{
+			Enabled:    true,
+			UpdateMode: "Auto",
+			ResourcePolicy: &autoscalingv1.ResourcePolicy{
+				ContainerPolicies: []autoscalingv1.ContainerResourcePolicy{{
+					ContainerName: "*",
+					MinAllowed:    map[string]string{"memory": "2Gi"},
+					MaxAllowed:    map[string]string{"memory": "1Gi"},
+				}},
+			},
+		},
+	}
+
+	resp := validator.Handle(context.Background(), createVpaManagerAdmissionRequest(t, admissionv1.Update, vpaManager))
+	assert.False(t, resp.Allowed)
+	require.NotNil(t, resp.Result)
+	assert.Contains(t, resp.Result.Message, "spec.resourcePolicy.containerPolicies[0].minAllowed[memory]")
+}
+
+// Test: Validator rejects quantities that do not parse
+func TestVpaManagerValidator_RejectsInvalidQuantity(t *testing.T) {
+	validator := &VpaManagerValidator{Metrics: createTestMetrics()}
+
+	vpaManager := &autoscalingv1.VpaManager{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-vpamanager"},
+		Spec: autoscalingv1.VpaManagerSpec{
+			Enabled:    true,
+			UpdateMode: "Off",
+			ResourcePolicy: &autoscalingv1.ResourcePolicy{
+				ContainerPolicies: []autoscalingv1.ContainerResourcePolicy{{
+					ContainerName: "*",
+					MaxAllowed:    map[string]string{"cpu": "lots"},
+				}},
+			},
+		},
+	}
+
+	resp := validator.Handle(context.Background(), createVpaManagerAdmissionRequest(t, admissionv1.Create, vpaManager))
+	assert.False(t, resp.Allowed)
+	assert.Contains(t, resp.Result.Message, "must be a valid resource quantity")
+}
+
+// Test: Auto mode without updatePolicy.minReplicas is admitted, warned about or rejected
+// depending on the safeguard mode, counting a safeguard inherited from a parent
+func TestVpaManagerValidator_AutoSafeguards(t *testing.T) {
+	two := int32(2)
+	parent := &autoscalingv1.VpaManager{
+		ObjectMeta: metav1.ObjectMeta{Name: "parent"},
+		Spec: autoscalingv1.VpaManagerSpec{
+			UpdateMode:   "Off",
+			UpdatePolicy: &autoscalingv1.UpdatePolicy{MinReplicas: &two},
+		},
+	}
+	fakeClient := fake.NewClientBuilder().WithScheme(setupScheme(t)).WithObjects(parent).Build()
+
+	tests := []struct {
+		name         string
+		mode         validation.AutoSafeguardMode
+		spec         autoscalingv1.VpaManagerSpec
+		wantAllowed  bool
+		wantWarnings int
+	}{
+		{
+			name:        "unset mode",
+			spec:        autoscalingv1.VpaManagerSpec{UpdateMode: "Auto", MatchAllNamespaces: true},
+			wantAllowed: true,
+		},
+		{
+			name:        "off",
+			mode:        validation.AutoSafeguardsOff,
+			spec:        autoscalingv1.VpaManagerSpec{UpdateMode: "Auto", MatchAllNamespaces: true},
+			wantAllowed: true,
+		},
+		{
+			name:         "warn",
+			mode:         validation.AutoSafeguardsWarn,
+			spec:         autoscalingv1.VpaManagerSpec{UpdateMode: "Auto", MatchAllNamespaces: true},
+			wantAllowed:  true,
+			wantWarnings: 1,
+		},
+		{
+			name: "enforce",
+			mode: validation.AutoSafeguardsEnforce,
+			spec: autoscalingv1.VpaManagerSpec{UpdateMode: "Auto", MatchAllNamespaces: true},
+		},
+		{
+			name:        "enforce with minReplicas",
+			mode:        validation.AutoSafeguardsEnforce,
+			spec:        autoscalingv1.VpaManagerSpec{UpdateMode: "Auto", MatchAllNamespaces: true, UpdatePolicy: &autoscalingv1.UpdatePolicy{MinReplicas: &two}},
+			wantAllowed: true,
+		},
+		{
+			name:        "enforce with inherited minReplicas",
+			mode:        validation.AutoSafeguardsEnforce,
+			spec:        autoscalingv1.VpaManagerSpec{UpdateMode: "Auto", MatchAllNamespaces: true, InheritFrom: "parent"},
+			wantAllowed: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			validator := &VpaManagerValidator{Metrics: createTestMetrics(), AutoSafeguards: tt.mode, Client: fakeClient}
+			vpaManager := &autoscalingv1.VpaManager{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-vpamanager"},
+				Spec:       tt.spec,
+			}
+
+			resp := validator.Handle(context.Background(), createVpaManagerAdmissionRequest(t, admissionv1.Create, vpaManager))
+			assert.Equal(t, tt.wantAllowed, resp.Allowed)
+			assert.Len(t, resp.Warnings, tt.wantWarnings)
+			if !tt.wantAllowed {
+				assert.Contains(t, resp.Result.Message, "spec.updatePolicy.minReplicas")
+			}
+		})
+	}
+}
+
+func createVpaManagerAdmissionRequest(t *testing.T, operation admissionv1.Operation, obj *autoscalingv1.VpaManager) admission.Request {
+	raw, err := json.Marshal(obj)
+	require.NoError(t, err)
+
+	return admission.Request{
+		AdmissionRequest: admissionv1.AdmissionRequest{
+			UID:       "test-uid",
+			Operation: operation,
+			Name:      obj.Name,
+			Object:    runtime.RawExtension{Raw: raw},
+		},
+	}
+}
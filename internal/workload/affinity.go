@@ -0,0 +1,92 @@
+package workload
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// SelfAntiAffinityTopologyKeys returns the topology keys of the required pod anti-affinity
+// terms of a pod template that select the template's own pods, i.e. the terms that allow at
+// most one pod of the workload per topology domain
+func SelfAntiAffinityTopologyKeys(namespace string, template *corev1.PodTemplateSpec) []string {
+	if template == nil || template.Spec.Affinity == nil || template.Spec.Affinity.PodAntiAffinity == nil {
+		return nil
+	}
+	var keys []string
+	podLabels := labels.Set(template.Labels)
+	for _, term := range template.Spec.Affinity.PodAntiAffinity.RequiredDuringSchedulingIgnoredDuringExecution {
+		if term.LabelSelector == nil || term.NamespaceSelector != nil || !coversNamespace(term.Namespaces, namespace) {
+			continue
+		}
+		selector, err := metav1.LabelSelectorAsSelector(term.LabelSelector)
+		if err != nil || selector.Empty() || !selector.Matches(podLabels) {
+			continue
+		}
+		keys = append(keys, term.TopologyKey)
+	}
+	return keys
+}
+
+// coversNamespace reports whether an affinity term listing namespaces applies to pods in
+// namespace; an empty list means the pod's own namespace
+func coversNamespace(namespaces []string, namespace string) bool {
+	if len(namespaces) == 0 {
+		return true
+	}
+	for _, ns := range namespaces {
+		if ns == namespace {
+			return true
+		}
+	}
+	return false
+}
+
+// TopologyDomains counts the distinct values of key among the schedulable nodes that match
+// the nodeSelector of a pod template. Node affinity and taints are not taken into account.
+func TopologyDomains(nodes []corev1.Node, template *corev1.PodTemplateSpec, key string) int {
+	selector := labels.SelectorFromSet(template.Spec.NodeSelector)
+	domains := map[string]bool{}
+	for i := range nodes {
+		node := &nodes[i]
+		if node.Spec.Unschedulable || !selector.Matches(labels.Set(node.Labels)) {
+			continue
+		}
+		if value, ok := node.Labels[key]; ok {
+			domains[value] = true
+		}
+	}
+	return len(domains)
+}
+
+// EvictionBlocked reports whether a pod of wl evicted by the VPA updater could not be
+// rescheduled: its pod template allows one pod per topology domain through required pod
+// anti-affinity and it has at least as many replicas as there are domains. It returns the
+// topology key that blocks eviction. Nodes are only listed for workloads with such terms;
+// workloads without a replica count are never blocked.
+func EvictionBlocked(ctx context.Context, c client.Reader, wl Workload) (string, bool, error) {
+	scalable, ok := wl.(Scalable)
+	if !ok || scalable.GetReplicas() == 0 {
+		return "", false, nil
+	}
+	template := wl.GetPodTemplateSpec()
+	keys := SelfAntiAffinityTopologyKeys(wl.GetNamespace(), template)
+	if len(keys) == 0 {
+		return "", false, nil
+	}
+
+	nodes := &corev1.NodeList{}
+	if err := c.List(ctx, nodes); err != nil {
+		return "", false, fmt.Errorf("listing nodes: %w", err)
+	}
+	for _, key := range keys {
+		if int(scalable.GetReplicas()) >= TopologyDomains(nodes.Items, template, key) {
+			return key, true, nil
+		}
+	}
+	return "", false, nil
+}
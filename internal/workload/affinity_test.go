@@ -0,0 +1,120 @@
+package workload
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const hostnameKey = "kubernetes.io/hostname"
+
+// antiAffinityTemplate returns a pod template labeled app=db with a required pod
+// anti-affinity term for each of terms
+func antiAffinityTemplate(terms ...corev1.PodAffinityTerm) corev1.PodTemplateSpec {
+	template := corev1.PodTemplateSpec{
+		ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "db"}},
+		Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "db", Image: "postgres"}}},
+	}
+	if len(terms) > 0 {
+		template.Spec.Affinity = &corev1.Affinity{PodAntiAffinity: &corev1.PodAntiAffinity{
+			RequiredDuringSchedulingIgnoredDuringExecution: terms,
+		}}
+	}
+	return template
+}
+
+// selectApp is an anti-affinity term on key selecting pods labeled app=value
+func selectApp(value, key string) corev1.PodAffinityTerm {
+	return corev1.PodAffinityTerm{
+		LabelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": value}},
+		TopologyKey:   key,
+	}
+}
+
+func TestSelfAntiAffinityTopologyKeys(t *testing.T) {
+	otherNamespace := selectApp("db", hostnameKey)
+	otherNamespace.Namespaces = []string{"other"}
+
+	tests := []struct {
+		name     string
+		template corev1.PodTemplateSpec
+		expected []string
+	}{
+		{name: "no affinity", template: antiAffinityTemplate()},
+		{
+			name:     "own pods per node and zone",
+			template: antiAffinityTemplate(selectApp("db", hostnameKey), selectApp("db", "topology.kubernetes.io/zone")),
+			expected: []string{hostnameKey, "topology.kubernetes.io/zone"},
+		},
+		{name: "other pods", template: antiAffinityTemplate(selectApp("cache", hostnameKey))},
+		{name: "other namespace", template: antiAffinityTemplate(otherNamespace)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, SelfAntiAffinityTopologyKeys("test-ns", &tt.template))
+		})
+	}
+}
+
+func TestEvictionBlocked(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, clientgoscheme.AddToScheme(scheme))
+
+	node := func(name string, unschedulable bool) client.Object {
+		return &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{hostnameKey: name}},
+			Spec:       corev1.NodeSpec{Unschedulable: unschedulable},
+		}
+	}
+	statefulSet := func(replicas int32, template corev1.PodTemplateSpec) Workload {
+		return &StatefulSetWorkload{&appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "test-ns"},
+			Spec:       appsv1.StatefulSetSpec{Replicas: &replicas, Template: template},
+		}}
+	}
+	spread := antiAffinityTemplate(selectApp("db", hostnameKey))
+
+	tests := []struct {
+		name        string
+		workload    Workload
+		expectedKey string
+		blocked     bool
+	}{
+		{name: "fewer replicas than nodes", workload: statefulSet(2, spread)},
+		{name: "one replica per node", workload: statefulSet(3, spread), expectedKey: hostnameKey, blocked: true},
+		{name: "without anti-affinity", workload: statefulSet(3, antiAffinityTemplate())},
+		{name: "scaled to zero", workload: statefulSet(0, spread)},
+		{
+			name: "daemonset",
+			workload: &DaemonSetWorkload{&appsv1.DaemonSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "agent", Namespace: "test-ns"},
+				Spec:       appsv1.DaemonSetSpec{Template: spread},
+			}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The cordoned node is not a topology domain
+			c := fake.NewClientBuilder().
+				WithScheme(scheme).
+				WithObjects(node("node-a", false), node("node-b", false), node("node-c", false), node("node-d", true)).
+				Build()
+
+			key, blocked, err := EvictionBlocked(context.Background(), c, tt.workload)
+			require.NoError(t, err)
+			assert.Equal(t, tt.blocked, blocked)
+			assert.Equal(t, tt.expectedKey, key)
+		})
+	}
+}
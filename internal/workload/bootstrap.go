@@ -0,0 +1,30 @@
+package workload
+
+// DefaultVPAComponents are the Deployments of an upstream VPA installation
+var DefaultVPAComponents = []string{"vpa-admission-controller", "vpa-recommender", "vpa-updater"}
+
+// Bootstrapped identifies the Deployments of the VPA installation that the operator gives an
+// Off-mode VPA of its own. VpaManagers never manage them regardless of selectors: Auto-mode
+// evictions of the VPA components would interrupt the recommendations of every workload.
+type Bootstrapped struct {
+	Namespace string
+	Names     []string
+}
+
+// Matches reports whether w is a bootstrapped VPA component. It is nil-safe.
+func (b *Bootstrapped) Matches(w Workload) bool {
+	return b.MatchesObject(w.GetKind(), w.GetNamespace(), w.GetName())
+}
+
+// MatchesObject reports whether the given workload is a bootstrapped VPA component. It is nil-safe.
+func (b *Bootstrapped) MatchesObject(kind, namespace, name string) bool {
+	if b == nil || kind != "Deployment" || b.Namespace != namespace {
+		return false
+	}
+	for _, n := range b.Names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
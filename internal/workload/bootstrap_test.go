@@ -0,0 +1,18 @@
+package workload
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBootstrapped_MatchesObject(t *testing.T) {
+	components := &Bootstrapped{Namespace: "kube-system", Names: DefaultVPAComponents}
+	var disabled *Bootstrapped
+
+	assert.True(t, components.MatchesObject("Deployment", "kube-system", "vpa-recommender"))
+	assert.False(t, components.MatchesObject("Deployment", "kube-system", "coredns"))
+	assert.False(t, components.MatchesObject("StatefulSet", "kube-system", "vpa-recommender"))
+	assert.False(t, components.MatchesObject("Deployment", "default", "vpa-recommender"))
+	assert.False(t, disabled.MatchesObject("Deployment", "kube-system", "vpa-recommender"))
+}
@@ -4,7 +4,9 @@ import (
 	"context"
 
 	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -17,6 +19,10 @@ type DaemonSetWorkload struct {
 func (d *DaemonSetWorkload) GetKind() string       { return "DaemonSet" }
 func (d *DaemonSetWorkload) GetAPIVersion() string { return "apps/v1" }
 func (d *DaemonSetWorkload) GetUID() types.UID     { return d.UID }
+func (d *DaemonSetWorkload) GetPodTemplateSpec() *corev1.PodTemplateSpec {
+	return &d.Spec.Template
+}
+func (d *DaemonSetWorkload) GetObject() client.Object { return d.DaemonSet }
 
 // DaemonSetProvider provides DaemonSet workloads
 type DaemonSetProvider struct{}
@@ -32,18 +38,29 @@ func (p *DaemonSetProvider) List(ctx context.Context, c client.Client, namespace
 	return workloads, err
 }
 
+func (p *DaemonSetProvider) Get(ctx context.Context, c client.Client, namespace, name string) (Workload, error) {
+	obj := &appsv1.DaemonSet{}
+	if err := c.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, obj); err != nil {
+		return nil, err
+	}
+	return &DaemonSetWorkload{obj}, nil
+}
+
 func (p *DaemonSetProvider) ForEach(ctx context.Context, c client.Client, namespace string, selector *metav1.LabelSelector, callback WorkloadCallback) error {
+	labelSelector, err := compileSelector(selector)
+	if err != nil {
+		return err
+	}
+	return p.ForEachMatching(ctx, c, namespace, labelSelector, callback)
+}
+
+func (p *DaemonSetProvider) ForEachMatching(ctx context.Context, c client.Client, namespace string, selector labels.Selector, callback WorkloadCallback) error {
 	listOpts := []client.ListOption{
 		client.InNamespace(namespace),
 		client.Limit(PageSize),
 	}
-
-	if selector != nil {
-		labelSelector, err := metav1.LabelSelectorAsSelector(selector)
-		if err != nil {
-			return err
-		}
-		listOpts = append(listOpts, client.MatchingLabelsSelector{Selector: labelSelector})
+	if selector != nil && !selector.Empty() {
+		listOpts = append(listOpts, client.MatchingLabelsSelector{Selector: selector})
 	}
 
 	var continueToken string
@@ -4,7 +4,9 @@ import (
 	"context"
 
 	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -17,6 +19,18 @@ type DeploymentWorkload struct {
 func (d *DeploymentWorkload) GetKind() string       { return "Deployment" }
 func (d *DeploymentWorkload) GetAPIVersion() string { return "apps/v1" }
 func (d *DeploymentWorkload) GetUID() types.UID     { return d.UID }
+func (d *DeploymentWorkload) GetPodTemplateSpec() *corev1.PodTemplateSpec {
+	return &d.Spec.Template
+}
+func (d *DeploymentWorkload) GetObject() client.Object { return d.Deployment }
+
+// GetReplicas returns spec.replicas, which defaults to 1
+func (d *DeploymentWorkload) GetReplicas() int32 {
+	if d.Spec.Replicas == nil {
+		return 1
+	}
+	return *d.Spec.Replicas
+}
 
 // DeploymentProvider provides Deployment workloads
 type DeploymentProvider struct{}
@@ -32,18 +46,29 @@ func (p *DeploymentProvider) List(ctx context.Context, c client.Client, namespac
 	return workloads, err
 }
 
+func (p *DeploymentProvider) Get(ctx context.Context, c client.Client, namespace, name string) (Workload, error) {
+	obj := &appsv1.Deployment{}
+	if err := c.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, obj); err != nil {
+		return nil, err
+	}
+	return &DeploymentWorkload{obj}, nil
+}
+
 func (p *DeploymentProvider) ForEach(ctx context.Context, c client.Client, namespace string, selector *metav1.LabelSelector, callback WorkloadCallback) error {
+	labelSelector, err := compileSelector(selector)
+	if err != nil {
+		return err
+	}
+	return p.ForEachMatching(ctx, c, namespace, labelSelector, callback)
+}
+
+func (p *DeploymentProvider) ForEachMatching(ctx context.Context, c client.Client, namespace string, selector labels.Selector, callback WorkloadCallback) error {
 	listOpts := []client.ListOption{
 		client.InNamespace(namespace),
 		client.Limit(PageSize),
 	}
-
-	if selector != nil {
-		labelSelector, err := metav1.LabelSelectorAsSelector(selector)
-		if err != nil {
-			return err
-		}
-		listOpts = append(listOpts, client.MatchingLabelsSelector{Selector: labelSelector})
+	if selector != nil && !selector.Empty() {
+		listOpts = append(listOpts, client.MatchingLabelsSelector{Selector: selector})
 	}
 
 	var continueToken string
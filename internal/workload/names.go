@@ -0,0 +1,65 @@
+package workload
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// NameMatcher selects workloads by name with include and exclude regular expressions. The
+// nil NameMatcher selects every name.
+type NameMatcher struct {
+	include []*regexp.Regexp
+	exclude []*regexp.Regexp
+}
+
+// CompileNamePatterns compiles include and exclude patterns, each anchored to match the whole
+// name. It returns nil when there are no patterns.
+func CompileNamePatterns(include, exclude []string) (*NameMatcher, error) {
+	if len(include) == 0 && len(exclude) == 0 {
+		return nil, nil
+	}
+	m := &NameMatcher{}
+	var err error
+	if m.include, err = compilePatterns(include); err != nil {
+		return nil, err
+	}
+	if m.exclude, err = compilePatterns(exclude); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// compilePatterns compiles patterns anchored to the whole name
+func compilePatterns(patterns []string) ([]*regexp.Regexp, error) {
+	compiled := make([]*regexp.Regexp, 0, len(patterns))
+	for _, pattern := range patterns {
+		re, err := regexp.Compile("^(?:" + pattern + ")$")
+		if err != nil {
+			return nil, fmt.Errorf("invalid name pattern %q: %w", pattern, err)
+		}
+		compiled = append(compiled, re)
+	}
+	return compiled, nil
+}
+
+// Matches reports whether name matches no exclude pattern and, when there are include
+// patterns, any of them
+func (m *NameMatcher) Matches(name string) bool {
+	if m == nil {
+		return true
+	}
+	for _, re := range m.exclude {
+		if re.MatchString(name) {
+			return false
+		}
+	}
+	if len(m.include) == 0 {
+		return true
+	}
+	for _, re := range m.include {
+		if re.MatchString(name) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,40 @@
+package workload
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNameMatcher(t *testing.T) {
+	tests := []struct {
+		name     string
+		include  []string
+		exclude  []string
+		workload string
+		expected bool
+	}{
+		{name: "no patterns", workload: "web", expected: true},
+		{name: "included", include: []string{"legacy-.*", "billing-api"}, workload: "legacy-web", expected: true},
+		{name: "not included", include: []string{"legacy-.*"}, workload: "web", expected: false},
+		{name: "patterns match the whole name", include: []string{"billing"}, workload: "billing-api", expected: false},
+		{name: "alternation is anchored as a whole", include: []string{"web|api"}, workload: "web-canary", expected: false},
+		{name: "excluded", exclude: []string{".*-canary"}, workload: "web-canary", expected: false},
+		{name: "exclude wins over include", include: []string{"legacy-.*"}, exclude: []string{".*-canary"}, workload: "legacy-web-canary", expected: false},
+		{name: "not excluded", exclude: []string{".*-canary"}, workload: "web", expected: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := CompileNamePatterns(tt.include, tt.exclude)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, m.Matches(tt.workload))
+		})
+	}
+}
+
+func TestCompileNamePatterns_Invalid(t *testing.T) {
+	_, err := CompileNamePatterns([]string{"legacy-("}, nil)
+	assert.ErrorContains(t, err, `invalid name pattern "legacy-("`)
+}
@@ -0,0 +1,115 @@
+package workload_test
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/joaomo/k8s_op_vpa/internal/workload"
+	"github.com/joaomo/k8s_op_vpa/internal/workload/providertest"
+)
+
+func TestProviderConformance(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+
+	template := corev1.PodTemplateSpec{
+		Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "app", Image: "nginx"}}},
+	}
+	meta := func(namespace, name string, labels map[string]string) metav1.ObjectMeta {
+		return metav1.ObjectMeta{Name: name, Namespace: namespace, Labels: labels}
+	}
+
+	tests := []struct {
+		name        string
+		provider    workload.Provider
+		newWorkload func(namespace, name string, labels map[string]string) client.Object
+	}{
+		{
+			name:     "Deployment",
+			provider: &workload.DeploymentProvider{},
+			newWorkload: func(namespace, name string, labels map[string]string) client.Object {
+				return &appsv1.Deployment{ObjectMeta: meta(namespace, name, labels), Spec: appsv1.DeploymentSpec{Template: template}}
+			},
+		},
+		{
+			name:     "StatefulSet",
+			provider: &workload.StatefulSetProvider{},
+			newWorkload: func(namespace, name string, labels map[string]string) client.Object {
+				return &appsv1.StatefulSet{ObjectMeta: meta(namespace, name, labels), Spec: appsv1.StatefulSetSpec{Template: template}}
+			},
+		},
+		{
+			name:     "DaemonSet",
+			provider: &workload.DaemonSetProvider{},
+			newWorkload: func(namespace, name string, labels map[string]string) client.Object {
+				return &appsv1.DaemonSet{ObjectMeta: meta(namespace, name, labels), Spec: appsv1.DaemonSetSpec{Template: template}}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			providertest.Run(t, tt.provider, providertest.Fixture{Scheme: scheme, NewWorkload: tt.newWorkload})
+		})
+	}
+}
+
+func TestForEachMatchingAny(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	deployment := func(name string, labels map[string]string) client.Object {
+		return &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "test-ns", Labels: labels}}
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		deployment("web", map[string]string{"app": "web"}),
+		deployment("frontend", map[string]string{"tier": "frontend"}),
+		deployment("both", map[string]string{"app": "web", "tier": "frontend"}),
+		deployment("other", map[string]string{"app": "batch"}),
+	).Build()
+	app := labels.SelectorFromSet(labels.Set{"app": "web"})
+	tier := labels.SelectorFromSet(labels.Set{"tier": "frontend"})
+
+	tests := []struct {
+		name      string
+		selectors []labels.Selector
+		stopAfter int
+		expected  []string
+	}{
+		{name: "one selector", selectors: []labels.Selector{app}, expected: []string{"both", "web"}},
+		{name: "alternatives", selectors: []labels.Selector{app, tier}, expected: []string{"both", "frontend", "web"}},
+		{name: "empty alternative", selectors: []labels.Selector{app, labels.Everything()}, expected: []string{"both", "frontend", "other", "web"}},
+		{name: "stopped", selectors: []labels.Selector{app, tier}, stopAfter: 1, expected: []string{"both"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var names []string
+			err := workload.ForEachMatchingAny(context.Background(), c, &workload.DeploymentProvider{}, "test-ns", tt.selectors, func(wl workload.Workload) (bool, error) {
+				names = append(names, wl.GetName())
+				return tt.stopAfter == 0 || len(names) < tt.stopAfter, nil
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			sort.Strings(names)
+			if !reflect.DeepEqual(tt.expected, names) {
+				t.Errorf("expected %v, got %v", tt.expected, names)
+			}
+		})
+	}
+}
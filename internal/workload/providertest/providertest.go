@@ -0,0 +1,380 @@
+// Package providertest is a conformance suite for workload.Provider implementations.
+// A new provider, e.g. for Argo Rollouts or CronJobs, is safe to add to the operator
+// once it passes Run:
+//
+//	func TestRolloutProvider(t *testing.T) {
+//		providertest.Run(t, &RolloutProvider{}, providertest.Fixture{
+//			Scheme:      scheme,
+//			NewWorkload: newRollout,
+//		})
+//	}
+package providertest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"testing"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	"github.com/joaomo/k8s_op_vpa/internal/workload"
+)
+
+// Namespace is the namespace the suite creates its workloads in
+const Namespace = "conformance"
+
+// serverPageSize is how many items the suite's API server returns per page at most,
+// whatever limit the provider asks for, so pagination is exercised with few workloads
+const serverPageSize = 2
+
+// Fixture describes how to build workloads of the provider's kind
+type Fixture struct {
+	// Scheme must know the provider's workload type and its list type
+	Scheme *runtime.Scheme
+
+	// NewWorkload returns a valid workload object with the given name, namespace and
+	// labels, and a pod template with at least one container. The UID is set by the suite.
+	NewWorkload func(namespace, name string, labels map[string]string) client.Object
+}
+
+// Run checks that provider behaves like the built-in providers: workloads report the
+// provider's kind, Get reports missing workloads as NotFound, selectors and namespaces
+// are honored, every page of a paginated list is read, and ForEach stops when the
+// callback says so or fails.
+func Run(t *testing.T, provider workload.Provider, fixture Fixture) {
+	t.Helper()
+	if fixture.Scheme == nil || fixture.NewWorkload == nil {
+		t.Fatal("providertest: Fixture.Scheme and Fixture.NewWorkload are required")
+	}
+
+	t.Run("Kind", func(t *testing.T) { testKind(t, provider, fixture) })
+	t.Run("Get", func(t *testing.T) { testGet(t, provider, fixture) })
+	t.Run("Selector", func(t *testing.T) { testSelector(t, provider, fixture) })
+	t.Run("Pagination", func(t *testing.T) { testPagination(t, provider, fixture) })
+	t.Run("ForEachEarlyExit", func(t *testing.T) { testEarlyExit(t, provider, fixture) })
+	t.Run("ForEachError", func(t *testing.T) { testCallbackError(t, provider, fixture) })
+	t.Run("List", func(t *testing.T) { testList(t, provider, fixture) })
+}
+
+// testKind checks that the kind, the watched object and the workloads agree
+func testKind(t *testing.T, provider workload.Provider, fixture Fixture) {
+	gvk, err := apiutil.GVKForObject(provider.NewObject(), fixture.Scheme)
+	if err != nil {
+		t.Fatalf("NewObject() is not registered in the scheme: %v", err)
+	}
+	if gvk.Kind != provider.Kind() {
+		t.Errorf("NewObject() kind = %q, want Kind() %q", gvk.Kind, provider.Kind())
+	}
+
+	c := newClient(fixture, nil, "web")
+	wl, err := provider.Get(context.Background(), c, Namespace, "web")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if wl.GetKind() != provider.Kind() {
+		t.Errorf("GetKind() = %q, want %q", wl.GetKind(), provider.Kind())
+	}
+	if wl.GetAPIVersion() != gvk.GroupVersion().String() {
+		t.Errorf("GetAPIVersion() = %q, want %q", wl.GetAPIVersion(), gvk.GroupVersion().String())
+	}
+}
+
+// testGet checks that Get returns the named workload and NotFound for a missing one
+func testGet(t *testing.T, provider workload.Provider, fixture Fixture) {
+	ctx := context.Background()
+	c := newClient(fixture, nil, "web", "api")
+
+	wl, err := provider.Get(ctx, c, Namespace, "web")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if wl.GetName() != "web" || wl.GetNamespace() != Namespace {
+		t.Errorf("Get() = %s/%s, want %s/web", wl.GetNamespace(), wl.GetName(), Namespace)
+	}
+	if wl.GetUID() != uid("web") {
+		t.Errorf("GetUID() = %q, want %q", wl.GetUID(), uid("web"))
+	}
+	if template := wl.GetPodTemplateSpec(); template == nil || len(template.Spec.Containers) == 0 {
+		t.Error("GetPodTemplateSpec() returned no containers")
+	}
+	if obj := wl.GetObject(); obj == nil || obj.GetName() != "web" {
+		t.Errorf("GetObject() = %v, want the web workload", obj)
+	}
+
+	if _, err := provider.Get(ctx, c, Namespace, "missing"); !apierrors.IsNotFound(err) {
+		t.Errorf("Get() of a missing workload error = %v, want NotFound", err)
+	}
+}
+
+// testSelector checks that ForEach honors the namespace and label selector
+func testSelector(t *testing.T, provider workload.Provider, fixture Fixture) {
+	web := fixture.NewWorkload(Namespace, "web", map[string]string{"tier": "frontend", "vpa-enabled": "true"})
+	api := fixture.NewWorkload(Namespace, "api", map[string]string{"tier": "backend", "vpa-enabled": "true"})
+	batch := fixture.NewWorkload(Namespace, "batch", map[string]string{"tier": "backend"})
+	other := fixture.NewWorkload("other", "elsewhere", map[string]string{"tier": "frontend", "vpa-enabled": "true"})
+	c := newClient(fixture, nil)
+	for _, obj := range []client.Object{web, api, batch, other} {
+		obj.SetUID(uid(obj.GetName()))
+		if err := c.Create(context.Background(), obj); err != nil {
+			t.Fatalf("creating %s: %v", obj.GetName(), err)
+		}
+	}
+
+	tests := []struct {
+		name     string
+		selector *metav1.LabelSelector
+		expected []string
+	}{
+		{name: "nil selector matches the namespace", expected: []string{"api", "batch", "web"}},
+		{name: "empty selector matches the namespace", selector: &metav1.LabelSelector{}, expected: []string{"api", "batch", "web"}},
+		{
+			name:     "match labels",
+			selector: &metav1.LabelSelector{MatchLabels: map[string]string{"vpa-enabled": "true"}},
+			expected: []string{"api", "web"},
+		},
+		{
+			name: "match expressions",
+			selector: &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{
+				{Key: "tier", Operator: metav1.LabelSelectorOpIn, Values: []string{"backend"}},
+				{Key: "vpa-enabled", Operator: metav1.LabelSelectorOpDoesNotExist},
+			}},
+			expected: []string{"batch"},
+		},
+		{
+			name:     "no match",
+			selector: &metav1.LabelSelector{MatchLabels: map[string]string{"tier": "database"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			names, err := collect(provider, c, tt.selector)
+			if err != nil {
+				t.Fatalf("ForEach() error = %v", err)
+			}
+			assertNames(t, names, tt.expected)
+
+			// ForEachMatching must agree with ForEach for the compiled selector
+			var compiled labels.Selector
+			if tt.selector != nil {
+				if compiled, err = metav1.LabelSelectorAsSelector(tt.selector); err != nil {
+					t.Fatalf("compiling selector: %v", err)
+				}
+			}
+			names = nil
+			err = provider.ForEachMatching(context.Background(), c, Namespace, compiled, func(wl workload.Workload) (bool, error) {
+				names = append(names, wl.GetName())
+				return true, nil
+			})
+			if err != nil {
+				t.Fatalf("ForEachMatching() error = %v", err)
+			}
+			assertNames(t, names, tt.expected)
+		})
+	}
+
+	t.Run("invalid selector", func(t *testing.T) {
+		invalid := &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{
+			{Key: "tier", Operator: "Near"},
+		}}
+		if _, err := collect(provider, c, invalid); err == nil {
+			t.Error("ForEach() with an invalid selector should fail")
+		}
+	})
+}
+
+// testPagination checks that ForEach asks for bounded pages and follows continue tokens
+func testPagination(t *testing.T, provider workload.Provider, fixture Fixture) {
+	var calls listCalls
+	c := newClient(fixture, &calls, "a", "b", "c", "d", "e")
+
+	names, err := collect(provider, c, nil)
+	if err != nil {
+		t.Fatalf("ForEach() error = %v", err)
+	}
+	assertNames(t, names, []string{"a", "b", "c", "d", "e"})
+	if calls.count != 3 {
+		t.Errorf("ForEach() made %d list calls, want 3 pages of at most %d", calls.count, serverPageSize)
+	}
+	if calls.unbounded > 0 {
+		t.Errorf("ForEach() made %d list calls without a limit", calls.unbounded)
+	}
+}
+
+// testEarlyExit checks that ForEach stops, without reading further pages, when the callback returns false
+func testEarlyExit(t *testing.T, provider workload.Provider, fixture Fixture) {
+	var calls listCalls
+	c := newClient(fixture, &calls, "a", "b", "c", "d", "e")
+
+	visited := 0
+	err := provider.ForEach(context.Background(), c, Namespace, nil, func(workload.Workload) (bool, error) {
+		visited++
+		return false, nil
+	})
+	if err != nil {
+		t.Fatalf("ForEach() error = %v", err)
+	}
+	if visited != 1 {
+		t.Errorf("callback called %d times after returning false, want 1", visited)
+	}
+	if calls.count != 1 {
+		t.Errorf("ForEach() made %d list calls after an early exit, want 1", calls.count)
+	}
+}
+
+// testCallbackError checks that ForEach stops and returns the callback's error
+func testCallbackError(t *testing.T, provider workload.Provider, fixture Fixture) {
+	c := newClient(fixture, nil, "a", "b", "c")
+	errStop := errors.New("stop")
+
+	visited := 0
+	err := provider.ForEach(context.Background(), c, Namespace, nil, func(workload.Workload) (bool, error) {
+		visited++
+		return true, errStop
+	})
+	if !errors.Is(err, errStop) {
+		t.Errorf("ForEach() error = %v, want the callback error", err)
+	}
+	if visited != 1 {
+		t.Errorf("callback called %d times after failing, want 1", visited)
+	}
+}
+
+// testList checks that the deprecated List returns what ForEach visits
+func testList(t *testing.T, provider workload.Provider, fixture Fixture) {
+	c := newClient(fixture, nil, "a", "b", "c")
+
+	workloads, err := provider.List(context.Background(), c, Namespace, nil)
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	names := make([]string, 0, len(workloads))
+	for _, wl := range workloads {
+		names = append(names, wl.GetName())
+	}
+	assertNames(t, names, []string{"a", "b", "c"})
+}
+
+// listCalls counts the list requests the suite's API server receives
+type listCalls struct {
+	count     int
+	unbounded int
+}
+
+// newClient returns a client serving workloads with the given names in Namespace. Lists
+// are served in pages of at most serverPageSize items, and recorded in calls if set.
+func newClient(fixture Fixture, calls *listCalls, names ...string) client.Client {
+	objects := make([]client.Object, 0, len(names))
+	for _, name := range names {
+		obj := fixture.NewWorkload(Namespace, name, map[string]string{"app": name})
+		obj.SetUID(uid(name))
+		objects = append(objects, obj)
+	}
+
+	return fake.NewClientBuilder().
+		WithScheme(fixture.Scheme).
+		WithObjects(objects...).
+		WithInterceptorFuncs(interceptor.Funcs{
+			List: func(ctx context.Context, c client.WithWatch, list client.ObjectList, opts ...client.ListOption) error {
+				if calls != nil {
+					calls.count++
+				}
+				return listPage(ctx, c, list, calls, opts...)
+			},
+		}).
+		Build()
+}
+
+// listPage serves one page of a list like the API server does: at most the requested
+// limit, capped by serverPageSize, with a continue token while items remain
+func listPage(ctx context.Context, c client.WithWatch, list client.ObjectList, calls *listCalls, opts ...client.ListOption) error {
+	listOpts := &client.ListOptions{}
+	listOpts.ApplyOptions(opts)
+	if listOpts.Limit <= 0 && calls != nil {
+		calls.unbounded++
+	}
+
+	all := []client.ListOption{client.InNamespace(listOpts.Namespace)}
+	if listOpts.LabelSelector != nil {
+		all = append(all, client.MatchingLabelsSelector{Selector: listOpts.LabelSelector})
+	}
+	if err := c.List(ctx, list, all...); err != nil {
+		return err
+	}
+	items, err := meta.ExtractList(list)
+	if err != nil {
+		return err
+	}
+
+	offset := 0
+	if listOpts.Continue != "" {
+		if offset, err = strconv.Atoi(listOpts.Continue); err != nil {
+			return apierrors.NewBadRequest(fmt.Sprintf("invalid continue token %q", listOpts.Continue))
+		}
+	}
+	size := serverPageSize
+	if listOpts.Limit > 0 && int(listOpts.Limit) < size {
+		size = int(listOpts.Limit)
+	}
+	end := offset + size
+	if end > len(items) {
+		end = len(items)
+	}
+	if offset > end {
+		offset = end
+	}
+
+	if err := meta.SetList(list, items[offset:end]); err != nil {
+		return err
+	}
+	continueToken := ""
+	if end < len(items) {
+		continueToken = strconv.Itoa(end)
+	}
+	list.SetContinue(continueToken)
+	return nil
+}
+
+// collect returns the names of the workloads ForEach visits in Namespace
+func collect(provider workload.Provider, c client.Client, selector *metav1.LabelSelector) ([]string, error) {
+	var names []string
+	err := provider.ForEach(context.Background(), c, Namespace, selector, func(wl workload.Workload) (bool, error) {
+		names = append(names, wl.GetName())
+		return true, nil
+	})
+	return names, err
+}
+
+// assertNames reports a mismatch between visited and expected workload names, in any order
+func assertNames(t *testing.T, got, expected []string) {
+	t.Helper()
+	seen := map[string]int{}
+	for _, name := range got {
+		seen[name]++
+	}
+	for _, name := range expected {
+		seen[name]--
+	}
+	for name, count := range seen {
+		if count != 0 {
+			t.Errorf("visited workloads %v, want %v (%s off by %d)", got, expected, name, count)
+			return
+		}
+	}
+}
+
+// uid returns the UID the suite gives the named workload
+func uid(name string) types.UID {
+	return types.UID("uid-" + name)
+}
@@ -0,0 +1,72 @@
+package workload
+
+import (
+	"context"
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Downward API environment variables identifying the operator pod
+const (
+	PodNameEnv      = "POD_NAME"
+	PodNamespaceEnv = "POD_NAMESPACE"
+)
+
+// Self identifies the operator's own workload, which is never managed regardless of
+// selectors: Auto-mode evictions of the operator would leave gaps in reconciliation
+type Self struct {
+	Namespace string
+	Kind      string
+	// Name is empty when the workload could not be resolved, protecting the whole namespace
+	Name string
+}
+
+// Matches reports whether w is the operator's own workload. It is nil-safe.
+func (s *Self) Matches(w Workload) bool {
+	return s.MatchesObject(w.GetKind(), w.GetNamespace(), w.GetName())
+}
+
+// MatchesObject reports whether the given workload is the operator's own. It is nil-safe.
+func (s *Self) MatchesObject(kind, namespace, name string) bool {
+	if s == nil || s.Namespace != namespace {
+		return false
+	}
+	return s.Name == "" || (s.Kind == kind && s.Name == name)
+}
+
+// String returns the workload as Kind namespace/name
+func (s *Self) String() string {
+	if s.Name == "" {
+		return fmt.Sprintf("namespace %s", s.Namespace)
+	}
+	return fmt.Sprintf("%s %s/%s", s.Kind, s.Namespace, s.Name)
+}
+
+// DiscoverSelf resolves the top-level workload that owns the given pod by following
+// controller references, e.g. Pod -> ReplicaSet -> Deployment
+func DiscoverSelf(ctx context.Context, reader client.Reader, namespace, podName string) (*Self, error) {
+	pod := &corev1.Pod{}
+	if err := reader.Get(ctx, types.NamespacedName{Namespace: namespace, Name: podName}, pod); err != nil {
+		return nil, fmt.Errorf("getting operator pod: %w", err)
+	}
+
+	owner := metav1.GetControllerOf(pod)
+	if owner == nil {
+		return &Self{Namespace: namespace, Kind: "Pod", Name: podName}, nil
+	}
+	if owner.Kind == "ReplicaSet" {
+		rs := &appsv1.ReplicaSet{}
+		if err := reader.Get(ctx, types.NamespacedName{Namespace: namespace, Name: owner.Name}, rs); err != nil {
+			return nil, fmt.Errorf("getting operator replicaset: %w", err)
+		}
+		if rsOwner := metav1.GetControllerOf(rs); rsOwner != nil {
+			owner = rsOwner
+		}
+	}
+	return &Self{Namespace: namespace, Kind: owner.Kind, Name: owner.Name}, nil
+}
@@ -0,0 +1,86 @@
+package workload
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func controllerRef(kind, name string) []metav1.OwnerReference {
+	isController := true
+	return []metav1.OwnerReference{{APIVersion: "apps/v1", Kind: kind, Name: name, UID: "owner-uid", Controller: &isController}}
+}
+
+func TestDiscoverSelf(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, clientgoscheme.AddToScheme(scheme))
+
+	tests := []struct {
+		name     string
+		objects  []client.Object
+		expected *Self
+		wantErr  bool
+	}{
+		{
+			name: "deployment via replicaset",
+			objects: []client.Object{
+				&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "op-abc-123", Namespace: "vpa-system", OwnerReferences: controllerRef("ReplicaSet", "op-abc")}},
+				&appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{Name: "op-abc", Namespace: "vpa-system", OwnerReferences: controllerRef("Deployment", "op")}},
+			},
+			expected: &Self{Namespace: "vpa-system", Kind: "Deployment", Name: "op"},
+		},
+		{
+			name: "statefulset owner",
+			objects: []client.Object{
+				&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "op-abc-123", Namespace: "vpa-system", OwnerReferences: controllerRef("StatefulSet", "op")}},
+			},
+			expected: &Self{Namespace: "vpa-system", Kind: "StatefulSet", Name: "op"},
+		},
+		{
+			name: "bare pod",
+			objects: []client.Object{
+				&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "op-abc-123", Namespace: "vpa-system"}},
+			},
+			expected: &Self{Namespace: "vpa-system", Kind: "Pod", Name: "op-abc-123"},
+		},
+		{
+			name:    "pod not found",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(tt.objects...).Build()
+			self, err := DiscoverSelf(context.Background(), c, "vpa-system", "op-abc-123")
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, self)
+		})
+	}
+}
+
+func TestSelf_MatchesObject(t *testing.T) {
+	deployment := &Self{Namespace: "vpa-system", Kind: "Deployment", Name: "op"}
+	namespace := &Self{Namespace: "vpa-system"}
+	var disabled *Self
+
+	assert.True(t, deployment.MatchesObject("Deployment", "vpa-system", "op"))
+	assert.False(t, deployment.MatchesObject("Deployment", "vpa-system", "other"))
+	assert.False(t, deployment.MatchesObject("StatefulSet", "vpa-system", "op"))
+	assert.False(t, deployment.MatchesObject("Deployment", "default", "op"))
+	assert.True(t, namespace.MatchesObject("DaemonSet", "vpa-system", "anything"))
+	assert.False(t, disabled.MatchesObject("Deployment", "vpa-system", "op"))
+}
@@ -4,7 +4,9 @@ import (
 	"context"
 
 	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -17,6 +19,18 @@ type StatefulSetWorkload struct {
 func (s *StatefulSetWorkload) GetKind() string       { return "StatefulSet" }
 func (s *StatefulSetWorkload) GetAPIVersion() string { return "apps/v1" }
 func (s *StatefulSetWorkload) GetUID() types.UID     { return s.UID }
+func (s *StatefulSetWorkload) GetPodTemplateSpec() *corev1.PodTemplateSpec {
+	return &s.Spec.Template
+}
+func (s *StatefulSetWorkload) GetObject() client.Object { return s.StatefulSet }
+
+// GetReplicas returns spec.replicas, which defaults to 1
+func (s *StatefulSetWorkload) GetReplicas() int32 {
+	if s.Spec.Replicas == nil {
+		return 1
+	}
+	return *s.Spec.Replicas
+}
 
 // StatefulSetProvider provides StatefulSet workloads
 type StatefulSetProvider struct{}
@@ -32,18 +46,29 @@ func (p *StatefulSetProvider) List(ctx context.Context, c client.Client, namespa
 	return workloads, err
 }
 
+func (p *StatefulSetProvider) Get(ctx context.Context, c client.Client, namespace, name string) (Workload, error) {
+	obj := &appsv1.StatefulSet{}
+	if err := c.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, obj); err != nil {
+		return nil, err
+	}
+	return &StatefulSetWorkload{obj}, nil
+}
+
 func (p *StatefulSetProvider) ForEach(ctx context.Context, c client.Client, namespace string, selector *metav1.LabelSelector, callback WorkloadCallback) error {
+	labelSelector, err := compileSelector(selector)
+	if err != nil {
+		return err
+	}
+	return p.ForEachMatching(ctx, c, namespace, labelSelector, callback)
+}
+
+func (p *StatefulSetProvider) ForEachMatching(ctx context.Context, c client.Client, namespace string, selector labels.Selector, callback WorkloadCallback) error {
 	listOpts := []client.ListOption{
 		client.InNamespace(namespace),
 		client.Limit(PageSize),
 	}
-
-	if selector != nil {
-		labelSelector, err := metav1.LabelSelectorAsSelector(selector)
-		if err != nil {
-			return err
-		}
-		listOpts = append(listOpts, client.MatchingLabelsSelector{Selector: labelSelector})
+	if selector != nil && !selector.Empty() {
+		listOpts = append(listOpts, client.MatchingLabelsSelector{Selector: selector})
 	}
 
 	var continueToken string
@@ -3,7 +3,9 @@ package workload
 import (
 	"context"
 
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -11,6 +13,24 @@ import (
 // PageSize is the default number of items to fetch per page
 const PageSize = 500
 
+// ExcludeAnnotation set to "true" on a workload opts it out of VPA management whatever the
+// selectors; a VPA created for it before is deleted like that of a workload no longer selected
+const ExcludeAnnotation = "vpa-operator.io/exclude"
+
+// Excluded reports whether a workload opts out with ExcludeAnnotation
+func Excluded(obj metav1.Object) bool {
+	return obj.GetAnnotations()[ExcludeAnnotation] == "true"
+}
+
+// PausedAnnotation set to "true" on a Namespace freezes the VPAs in it, e.g. during incident
+// response: existing VPAs are kept as they are and none is created, updated or deleted
+const PausedAnnotation = "vpa-operator.io/paused"
+
+// NamespacePaused reports whether a namespace is paused with PausedAnnotation
+func NamespacePaused(ns metav1.Object) bool {
+	return ns.GetAnnotations()[PausedAnnotation] == "true"
+}
+
 // Workload abstracts Deployment, StatefulSet, DaemonSet for VPA management
 type Workload interface {
 	GetName() string
@@ -19,6 +39,9 @@ type Workload interface {
 	GetLabels() map[string]string
 	GetKind() string
 	GetAPIVersion() string
+	GetPodTemplateSpec() *corev1.PodTemplateSpec
+	// GetObject returns the underlying API object, e.g. for recording events
+	GetObject() client.Object
 }
 
 // WorkloadCallback is called for each workload during iteration
@@ -38,6 +61,66 @@ type Provider interface {
 	// This is more memory-efficient than List for large datasets
 	ForEach(ctx context.Context, c client.Client, namespace string, selector *metav1.LabelSelector, callback WorkloadCallback) error
 
+	// ForEachMatching is ForEach with a compiled selector, for callers that reuse it across
+	// namespaces. A nil or empty selector matches every workload.
+	ForEachMatching(ctx context.Context, c client.Client, namespace string, selector labels.Selector, callback WorkloadCallback) error
+
+	// Get returns a single workload by namespace and name
+	Get(ctx context.Context, c client.Client, namespace, name string) (Workload, error)
+
 	// NewObject returns a new empty object for controller watches
 	NewObject() client.Object
 }
+
+// Scalable is implemented by workloads with a desired replica count
+type Scalable interface {
+	// GetReplicas returns the desired number of replicas
+	GetReplicas() int32
+}
+
+// ScaledToZero reports whether a workload is scaled to zero replicas. Workloads
+// without a replica count, such as DaemonSets, never are.
+func ScaledToZero(wl Workload) bool {
+	scalable, ok := wl.(Scalable)
+	return ok && scalable.GetReplicas() == 0
+}
+
+// ForEachMatchingAny calls callback for every workload of p in namespace that matches any of
+// selectors. Each selector is listed on its own so the API server keeps filtering; a workload
+// matching several is passed once. An empty selector among them matches every workload.
+func ForEachMatchingAny(ctx context.Context, c client.Client, p Provider, namespace string, selectors []labels.Selector, callback WorkloadCallback) error {
+	for _, selector := range selectors {
+		if selector == nil || selector.Empty() {
+			return p.ForEachMatching(ctx, c, namespace, selector, callback)
+		}
+	}
+	if len(selectors) == 1 {
+		return p.ForEachMatching(ctx, c, namespace, selectors[0], callback)
+	}
+
+	seen := map[string]bool{}
+	stopped := false
+	for _, selector := range selectors {
+		err := p.ForEachMatching(ctx, c, namespace, selector, func(wl Workload) (bool, error) {
+			if seen[wl.GetName()] {
+				return true, nil
+			}
+			seen[wl.GetName()] = true
+			continueIteration, err := callback(wl)
+			stopped = !continueIteration
+			return continueIteration, err
+		})
+		if err != nil || stopped {
+			return err
+		}
+	}
+	return nil
+}
+
+// compileSelector compiles an optional selector, nil when it is omitted
+func compileSelector(selector *metav1.LabelSelector) (labels.Selector, error) {
+	if selector == nil {
+		return nil, nil
+	}
+	return metav1.LabelSelectorAsSelector(selector)
+}
@@ -1,14 +1,25 @@
 package main
 
 import (
+	"context"
 	"flag"
+	"net/http"
 	"os"
+	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/discovery"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
@@ -17,8 +28,17 @@ import (
 
 	autoscalingv1 "github.com/joaomo/k8s_op_vpa/api/v1"
 	"github.com/joaomo/k8s_op_vpa/internal/controller"
+	"github.com/joaomo/k8s_op_vpa/internal/decisions"
+	"github.com/joaomo/k8s_op_vpa/internal/export"
+	"github.com/joaomo/k8s_op_vpa/internal/faultinject"
+	"github.com/joaomo/k8s_op_vpa/internal/inheritance"
+	"github.com/joaomo/k8s_op_vpa/internal/labelselector"
 	"github.com/joaomo/k8s_op_vpa/internal/metrics"
+	"github.com/joaomo/k8s_op_vpa/internal/policy"
+	"github.com/joaomo/k8s_op_vpa/internal/validation"
+	"github.com/joaomo/k8s_op_vpa/internal/vpa"
 	webhookhandler "github.com/joaomo/k8s_op_vpa/internal/webhook"
+	"github.com/joaomo/k8s_op_vpa/internal/workload"
 )
 
 var (
@@ -36,6 +56,52 @@ func main() {
 	var enableLeaderElection bool
 	var probeAddr string
 	var enableWebhook bool
+	var exportURL string
+	var exportInterval time.Duration
+	exportHeaders := map[string]string{}
+	var enableDefaultSelectors bool
+	var defaultNamespaceSelector string
+	var defaultDeploymentSelector string
+	var strictSelectors bool
+	var webhookClientTimeout time.Duration
+	var webhookVPAWritesPerSecond float64
+	var webhookVPAWriteBurst int
+	var maxOrphanDeletions int
+	var orphanSweepInterval time.Duration
+	var orphanSweepMaxDeletions int
+	var orphanDeletionGracePeriod time.Duration
+	var maxHandovers int
+	var summaryInterval time.Duration
+	var vpaDiscoveryTTL time.Duration
+	var kindRecheckInterval time.Duration
+	var reconcileBudget time.Duration
+	var maxNamespacesPerCycle int
+	var maxManagedVPAs int
+	var metricsMaxNamespaces int
+	var webhookCertDir string
+	var webhookCertRenewalWindow time.Duration
+	var webhookRegistration bool
+	var webhookEphemeral bool
+	var webhookServiceName string
+	var webhookServiceNamespace string
+	var webhookServicePort int
+	var enableSimulation bool
+	var decisionLogSize int
+	var recordWorkloadLists bool
+	var annotateWorkloads bool
+	var checkpointWarmStart bool
+	var topologyGuard bool
+	var rbacSelfCheck bool
+	var bootstrapVPAs bool
+	var bootstrapVPANamespace string
+	var bootstrapVPAComponents string
+	var bootstrapOperatorVPA bool
+	var modeFlag string
+	var autoSafeguardsFlag string
+	var resyncPeriod time.Duration
+	var ownershipLabelKey string
+	var ownershipLabelValue string
+	var instanceID string
 
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
@@ -43,54 +109,469 @@ func main() {
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
 	flag.BoolVar(&enableWebhook, "enable-webhook", true, "Enable the deployment webhook.")
+	flag.StringVar(&modeFlag, "mode", string(controller.ModeCombined),
+		"Operating mode: combined runs the reconciler and the webhooks, reconcile-only never registers webhooks "+
+			"and relies on the reconciler alone, for clusters that prohibit webhooks on core workloads. "+
+			"webhook-only serves the webhooks without the reconciler, next to another instance that reconciles.")
+	flag.DurationVar(&resyncPeriod, "resync-period", 0,
+		"How often every VpaManager is reconciled without any change. Defaults to 5m, or 1m in reconcile-only mode.")
+	flag.StringVar(&exportURL, "export-url", "",
+		"HTTP endpoint that receives JSON snapshots of recommendations and requests. Export is disabled when empty.")
+	flag.DurationVar(&exportInterval, "export-interval", time.Hour, "How often snapshots are pushed to the export URL.")
+	flag.Func("export-header", "HTTP header sent with every export request, as \"Name: value\". Can be repeated.", func(header string) error {
+		name, value, err := export.ParseHeader(header)
+		if err != nil {
+			return err
+		}
+		exportHeaders[name] = value
+		return nil
+	})
+	flag.BoolVar(&enableDefaultSelectors, "enable-default-selectors", false,
+		"Apply the default selectors to VpaManagers that omit them, instead of matching every namespace.")
+	flag.StringVar(&defaultNamespaceSelector, "default-namespace-selector", policy.DefaultSelectorLabel,
+		"Label selector used when a VpaManager has no namespaceSelector. Requires --enable-default-selectors.")
+	flag.StringVar(&defaultDeploymentSelector, "default-deployment-selector", policy.DefaultSelectorLabel,
+		"Label selector used when a VpaManager has no workload selectors. Requires --enable-default-selectors.")
+	flag.DurationVar(&webhookClientTimeout, "webhook-client-timeout", webhookhandler.DefaultClientTimeout,
+		"Upper bound for API calls made while handling one admission request. Keep it below the webhook timeoutSeconds.")
+	flag.Float64Var(&webhookVPAWritesPerSecond, "webhook-vpa-writes-per-second", webhookhandler.DefaultVPAWritesPerSecond,
+		"VPA writes per second the workload webhooks may make in one namespace; writes over the limit are left to the reconciler. 0 disables the limit.")
+	flag.IntVar(&webhookVPAWriteBurst, "webhook-vpa-write-burst", webhookhandler.DefaultVPAWriteBurst,
+		"Burst of VPA writes the workload webhooks may make in one namespace before --webhook-vpa-writes-per-second applies.")
+	flag.BoolVar(&strictSelectors, "strict-selectors", false,
+		"Treat omitted selectors as matching nothing unless matchAllNamespaces or matchAllWorkloads is set. "+
+			"Will become the default once the match-all deprecation window ends.")
+	flag.StringVar(&webhookCertDir, "webhook-cert-dir", "",
+		"Directory with the webhook serving certificate (tls.crt, tls.key and optionally ca.crt). Defaults to the controller-runtime location.")
+	flag.DurationVar(&webhookCertRenewalWindow, "webhook-cert-renewal-window", webhookhandler.DefaultCertRenewalWindow,
+		"Report VpaManagers Degraded when the webhook serving certificate expires within this window. "+
+			"Keep it below the renewal window of the certificate issuer.")
+	flag.BoolVar(&webhookRegistration, "webhook-registration", false,
+		"Register the webhook configurations once the webhook server is serving with a valid certificate.")
+	flag.BoolVar(&webhookEphemeral, "webhook-ephemeral", false,
+		"Remove the registered webhook configurations on graceful shutdown. Requires --webhook-registration.")
+	flag.StringVar(&webhookServiceName, "webhook-service-name", "vpa-operator-webhook",
+		"Name of the Service in front of the webhook server, used by --webhook-registration.")
+	flag.StringVar(&webhookServiceNamespace, "webhook-service-namespace", "",
+		"Namespace of the webhook Service. Defaults to the POD_NAMESPACE environment variable.")
+	flag.IntVar(&webhookServicePort, "webhook-service-port", 443, "Port of the webhook Service.")
+	flag.BoolVar(&enableSimulation, "enable-simulation-endpoint", false,
+		"Serve POST "+controller.SimulationPath+" on the metrics endpoint, returning the VPAs the operator would generate for a workload manifest.")
+	flag.IntVar(&decisionLogSize, "decision-log-size", decisions.DefaultSize,
+		"Number of recent per-workload VPA decisions kept in memory, served on GET "+decisions.Path+
+			" of the metrics endpoint and logged on SIGUSR1. 0 disables the decision log.")
+	flag.StringVar(&autoSafeguardsFlag, "auto-safeguards", string(validation.AutoSafeguardsWarn),
+		"How the validating webhook admits VpaManagers using Auto mode without updatePolicy.minReplicas of at least 2: "+
+			"off admits them, warn admits them with a warning, enforce rejects them.")
+	flag.BoolVar(&recordWorkloadLists, "record-workload-lists", false,
+		"Record every managed workload in the VpaManager status lists (managedDeployments, managedStatefulSets, "+
+			"managedDaemonSets, managedWorkloads). Only the count fields are kept otherwise.")
+	flag.BoolVar(&annotateWorkloads, "annotate-workloads", false,
+		"Annotate every managed workload with its VPA and effective update mode ("+vpa.WorkloadVPAAnnotation+": <vpa>,mode=<mode>). "+
+			"Requires patch access to workloads; the operator never writes to them otherwise.")
+	flag.BoolVar(&checkpointWarmStart, "checkpoint-warm-start", false,
+		"When a VPA is created for a workload another VPA already targets, copy the recommender checkpoints "+
+			"of that VPA so the recommendation history survives the migration, and switch that VPA to updateMode Off. "+
+			"Every VPA creation then lists the VPAs of its namespace.")
+	flag.BoolVar(&topologyGuard, "topology-guard", true,
+		"Turn the VPA of a workload Off while pods it evicts could not be rescheduled: required pod anti-affinity "+
+			"allows one pod per topology domain and the workload has as many replicas as there are domains.")
+	flag.BoolVar(&rbacSelfCheck, "rbac-self-check", true,
+		"Check at startup, with SelfSubjectAccessReviews, every permission the reconciler needs for the configured workload kinds. "+
+			"Missing permissions are logged and exported as vpa_operator_missing_rbac, and keep the operator unready until granted.")
+	flag.BoolVar(&bootstrapVPAs, "bootstrap-vpas", false,
+		"Maintain an Off-mode VPA for each Deployment of the VPA installation, so the autoscaling stack gets recommendations "+
+			"to rightsize it. VpaManagers never manage these Deployments, so Auto mode cannot evict them.")
+	flag.StringVar(&bootstrapVPANamespace, "bootstrap-vpa-namespace", "kube-system",
+		"Namespace of the VPA installation for --bootstrap-vpas.")
+	flag.StringVar(&bootstrapVPAComponents, "bootstrap-vpa-components", strings.Join(workload.DefaultVPAComponents, ","),
+		"Comma-separated names of the VPA component Deployments for --bootstrap-vpas. Missing ones are skipped.")
+	flag.BoolVar(&bootstrapOperatorVPA, "bootstrap-operator-vpa", false,
+		"With --bootstrap-vpas, also maintain an Off-mode VPA for the operator's own workload, which VpaManagers never manage.")
+	flag.IntVar(&maxOrphanDeletions, "max-orphan-deletions", 20,
+		"Hold back orphaned VPA deletions when one reconcile would delete more than this many. 0 disables the limit.")
+	flag.DurationVar(&orphanDeletionGracePeriod, "orphan-deletion-grace-period", time.Hour,
+		"How long held-back orphan deletions wait before proceeding without confirmation. 0 waits for confirmation.")
+	flag.IntVar(&maxHandovers, "max-handovers-per-reconcile", 50,
+		"Maximum number of VPAs one reconcile takes over from other VpaManagers, e.g. after a namespace was relabeled into another tier. "+
+			"The rest follow in a reconcile shortly after. 0 takes over every VPA at once.")
+	flag.DurationVar(&orphanSweepInterval, "orphan-sweep-interval", time.Hour,
+		"How often managed VPAs are scanned cluster-wide for a deleted VpaManager or target workload. 0 disables the sweep.")
+	flag.IntVar(&orphanSweepMaxDeletions, "orphan-sweep-max-deletions", 20,
+		"Maximum number of orphaned VPAs one orphan sweep deletes; the rest follow in later sweeps. 0 is unlimited.")
+	flag.IntVar(&maxManagedVPAs, "max-managed-vpas", 0,
+		"Maximum number of VPAs this operator instance manages across the cluster. Over the cap no VPA is created and "+
+			"VpaManagers report the ClusterCapacityReached condition, protecting etcd from over-broad selectors. 0 disables the cap.")
+	flag.IntVar(&metricsMaxNamespaces, "metrics-max-namespaces", metrics.DefaultMaxNamespaceLabels,
+		"Maximum number of distinct namespace label values of the per-namespace metrics. Later namespaces are recorded as _other.")
+	flag.DurationVar(&summaryInterval, "summary-interval", 24*time.Hour,
+		"How often each VpaManager's summary of added and removed VPAs, coverage, errors and top deviations is logged and emitted as an event. 0 disables the summary.")
+	flag.DurationVar(&vpaDiscoveryTTL, "vpa-discovery-ttl", vpa.DefaultAvailabilityTTL,
+		"How long the discovery of the VerticalPodAutoscaler CRD is cached. While the CRD is missing, reconciles only report it, "+
+			"and a watch on the CRD resumes them as soon as it is installed.")
+	flag.DurationVar(&reconcileBudget, "reconcile-budget", 0,
+		"Large-cluster mode: how long one reconcile processes namespaces. A VpaManager with more work continues in further "+
+			"reconciles from status.progress, keeping the workqueue responsive for other VpaManagers. 0 processes every namespace at once.")
+	flag.IntVar(&maxNamespacesPerCycle, "max-namespaces-per-cycle", 0,
+		"Large-cluster mode: how many namespaces one reconcile processes, keeping its API requests within API server priority "+
+			"and fairness limits. A VpaManager with more namespaces continues in further reconciles from status.progress. 0 disables the limit.")
+	flag.DurationVar(&kindRecheckInterval, "workload-kind-recheck-interval", controller.DefaultKindRecheckInterval,
+		"How often workload kinds whose API the cluster did not serve at startup are checked again. A kind is watched and "+
+			"managed as soon as its API appears. 0 disables rechecks.")
+	flag.StringVar(&ownershipLabelKey, "ownership-label-key", vpa.ManagedByLabel,
+		"Label key marking the VPAs of this operator instance. Instances with different ownership labels never delete each other's VPAs.")
+	flag.StringVar(&ownershipLabelValue, "ownership-label-value", vpa.DefaultManagedByValue,
+		"Label value marking the VPAs of this operator instance.")
+	flag.StringVar(&instanceID, "instance-id", "",
+		"ID stamped on the VPAs of this operator instance as the "+vpa.InstanceLabel+" label. Instances only update and delete "+
+			"VPAs with their own ID, and report VPAs of other instances with the ForeignInstanceConflict condition.")
 
 	opts := zap.Options{
 		Development: false,
 	}
 	opts.BindFlags(flag.CommandLine)
+	faultinject.BindFlags(flag.CommandLine)
 	flag.Parse()
 
 	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
 
+	mode, err := controller.ParseMode(modeFlag)
+	if err != nil {
+		setupLog.Error(err, "invalid --mode")
+		os.Exit(1)
+	}
+	autoSafeguards, err := validation.ParseAutoSafeguardMode(autoSafeguardsFlag)
+	if err != nil {
+		setupLog.Error(err, "invalid --auto-safeguards")
+		os.Exit(1)
+	}
+	if resyncPeriod <= 0 {
+		resyncPeriod = mode.ResyncPeriod()
+	}
+	ownership, err := vpa.NewOwnership(ownershipLabelKey, ownershipLabelValue, instanceID)
+	if err != nil {
+		setupLog.Error(err, "invalid ownership label")
+		os.Exit(1)
+	}
+	// The reconciler and the webhooks share one count of managed VPAs
+	clusterCapacity := vpa.NewClusterCapacity(maxManagedVPAs, ownership)
+	if enableWebhook && !mode.RunsWebhooks() {
+		setupLog.Info("webhooks are not registered in this mode", "mode", mode)
+		enableWebhook = false
+	}
+	if !enableWebhook && !mode.RunsReconciler() {
+		setupLog.Error(nil, "webhook-only mode requires --enable-webhook")
+		os.Exit(1)
+	}
+
 	// Initialize metrics
 	metricsInstance := metrics.NewMetrics(prometheus.WrapRegistererWith(
 		prometheus.Labels{"controller": "vpa-operator"},
 		ctrlmetrics.Registry,
 	))
+	metricsInstance.SetMaxNamespaceLabels(metricsMaxNamespaces)
+
+	// The simulation handler is registered before the reconciler it delegates to exists;
+	// the metrics server only starts serving once the manager starts
+	metricsOptions := metricsserver.Options{BindAddress: metricsAddr}
+	metricsOptions.ExtraHandlers = map[string]http.Handler{}
+	simulation := &controller.SimulationHandler{}
+	if enableSimulation {
+		metricsOptions.ExtraHandlers[controller.SimulationPath] = simulation
+	}
+	decisionLog := decisions.NewLog(decisionLogSize)
+	if decisionLog != nil {
+		metricsOptions.ExtraHandlers[decisions.Path] = decisionLog
+	}
 
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
 		Scheme:                 scheme,
-		Metrics:                metricsserver.Options{BindAddress: metricsAddr},
+		Metrics:                metricsOptions,
 		HealthProbeBindAddress: probeAddr,
 		LeaderElection:         enableLeaderElection,
-		LeaderElectionID:       "vpa-operator.operators.joaomo.io",
+		LeaderElectionID:       mode.LeaderElectionID(),
+		WebhookServer:          webhook.NewServer(webhook.Options{CertDir: webhookCertDir}),
+		// Only profile ConfigMaps are read, so keep every other ConfigMap out of the cache
+		Cache: cache.Options{ByObject: map[client.Object]cache.ByObject{
+			&corev1.ConfigMap{}: {Label: labels.SelectorFromSet(labels.Set{inheritance.ProfileLabel: "true"})},
+		}},
 	})
 	if err != nil {
 		setupLog.Error(err, "unable to start manager")
 		os.Exit(1)
 	}
 
+	apiClient, err := faultinject.FromFlags(mgr.GetClient())
+	if err != nil {
+		setupLog.Error(err, "invalid --fault-injection")
+		os.Exit(1)
+	}
+
+	// Never manage the operator's own workload, detected from the downward API
+	var self *workload.Self
+	if podName, podNamespace := os.Getenv(workload.PodNameEnv), os.Getenv(workload.PodNamespaceEnv); podName != "" && podNamespace != "" {
+		self, err = workload.DiscoverSelf(context.Background(), mgr.GetAPIReader(), podNamespace, podName)
+		if err != nil {
+			setupLog.Error(err, "unable to resolve the operator workload, excluding its whole namespace")
+			self = &workload.Self{Namespace: podNamespace}
+		}
+		setupLog.Info("excluding the operator's own workload from management", "workload", self.String())
+	}
+	// The VPA components with a bootstrap VPA are excluded like the operator's own workload
+	var bootstrapped *workload.Bootstrapped
+	if bootstrapVPAs {
+		bootstrapped = &workload.Bootstrapped{Namespace: bootstrapVPANamespace, Names: strings.Split(bootstrapVPAComponents, ",")}
+		setupLog.Info("excluding the VPA components from management", "namespace", bootstrapped.Namespace, "deployments", bootstrapped.Names)
+	}
+
 	// Setup VpaManager controller
-	if err = (&controller.VpaManagerReconciler{
-		Client:  mgr.GetClient(),
-		Scheme:  mgr.GetScheme(),
-		Metrics: metricsInstance,
-	}).SetupWithManager(mgr); err != nil {
-		setupLog.Error(err, "unable to create controller", "controller", "VpaManager")
+	workloadConfigs := controller.DefaultWorkloadConfigs()
+	var selectorDefaults *policy.SelectorDefaults
+	if enableDefaultSelectors {
+		selectorDefaults, err = policy.NewSelectorDefaults(defaultNamespaceSelector, defaultDeploymentSelector)
+		if err != nil {
+			setupLog.Error(err, "invalid default selector")
+			os.Exit(1)
+		}
+	}
+	if recordWorkloadLists {
+		setupLog.Info("--record-workload-lists populates the deprecated status.managedDeployments and status.managedWorkloads fields; " +
+			"move status readers to the count fields or the per-kind lists")
+	}
+
+	var summaryLedger *controller.SummaryLedger
+	if summaryInterval > 0 && mode.RunsReconciler() {
+		summaryLedger = controller.NewSummaryLedger()
+	}
+
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(mgr.GetConfig())
+	if err != nil {
+		setupLog.Error(err, "unable to create discovery client")
 		os.Exit(1)
 	}
 
+	// The webhook serving certificate is watched wherever webhooks are served; in combined
+	// mode its expiry is also reported in the VpaManager Degraded condition
+	certDir := webhookCertDir
+	if certDir == "" {
+		certDir = filepath.Join(os.TempDir(), "k8s-webhook-server", "serving-certs")
+	}
+	var certExpiry *webhookhandler.CertExpiryMonitor
+	if enableWebhook {
+		certExpiry = &webhookhandler.CertExpiryMonitor{
+			CertFile:      filepath.Join(certDir, "tls.crt"),
+			Metrics:       metricsInstance,
+			RenewalWindow: webhookCertRenewalWindow,
+		}
+	}
+
+	reconciler := &controller.VpaManagerReconciler{
+		Client:                apiClient,
+		Scheme:                mgr.GetScheme(),
+		Metrics:               metricsInstance,
+		Recorder:              mgr.GetEventRecorderFor("vpa-operator"),
+		WorkloadConfigs:       workloadConfigs,
+		SelectorDefaults:      selectorDefaults,
+		StrictSelectors:       strictSelectors,
+		Self:                  self,
+		Bootstrapped:          bootstrapped,
+		RecordWorkloadLists:   recordWorkloadLists,
+		AnnotateWorkloads:     annotateWorkloads,
+		CheckpointWarmStart:   checkpointWarmStart,
+		TopologyGuard:         topologyGuard,
+		ResyncPeriod:          resyncPeriod,
+		KindRecheckInterval:   kindRecheckInterval,
+		ReconcileBudget:       reconcileBudget,
+		MaxNamespacesPerCycle: maxNamespacesPerCycle,
+		Ownership:             ownership,
+		ClusterCapacity:       clusterCapacity,
+		VPAAvailability:       vpa.NewAvailability(discoveryClient, vpaDiscoveryTTL),
+		Selectors:             labelselector.NewCache(),
+		Summary:               summaryLedger,
+		Decisions:             decisionLog,
+		OrphanBurstGuard: &controller.OrphanBurstGuard{
+			MaxDeletions: maxOrphanDeletions,
+			GracePeriod:  orphanDeletionGracePeriod,
+		},
+		MaxHandovers: maxHandovers,
+	}
+	if certExpiry != nil {
+		reconciler.WebhookCertificate = certExpiry
+	}
+	// In webhook-only mode another instance reconciles; the reconciler is still used by the simulation endpoint
+	if mode.RunsReconciler() {
+		if err = reconciler.SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "VpaManager")
+			os.Exit(1)
+		}
+	} else {
+		setupLog.Info("not running the VpaManager reconciler in this mode", "mode", mode)
+	}
+
+	simulation.Reconciler = reconciler
+
+	// Check the reconciler's permissions independently of how the ClusterRole was installed
+	if rbacSelfCheck && mode.RunsReconciler() {
+		permissionCheck := &controller.PermissionCheck{
+			Client:              apiClient,
+			Metrics:             metricsInstance,
+			WorkloadConfigs:     workloadConfigs,
+			AnnotateWorkloads:   annotateWorkloads,
+			CheckpointWarmStart: checkpointWarmStart,
+			TopologyGuard:       topologyGuard,
+		}
+		if err := mgr.Add(permissionCheck); err != nil {
+			setupLog.Error(err, "unable to set up RBAC self-check")
+			os.Exit(1)
+		}
+		if err := mgr.AddReadyzCheck("rbac", permissionCheck.Checker); err != nil {
+			setupLog.Error(err, "unable to set up RBAC ready check")
+			os.Exit(1)
+		}
+	}
+
+	// Setup snapshot export if configured; it runs next to the reconciler
+	if exportURL != "" && mode.RunsReconciler() {
+		setupLog.Info("setting up snapshot export", "url", exportURL, "interval", exportInterval)
+		providers := make([]workload.Provider, 0, len(workloadConfigs))
+		for _, wc := range workloadConfigs {
+			providers = append(providers, wc.Provider)
+		}
+		if err := mgr.Add(&export.Runner{
+			Collector: &export.Collector{
+				Client:          apiClient,
+				Providers:       providers,
+				ManagedByLabels: ownership.Selector(),
+			},
+			Exporter: &export.HTTPExporter{URL: exportURL, Headers: exportHeaders},
+			Interval: exportInterval,
+		}); err != nil {
+			setupLog.Error(err, "unable to set up snapshot export")
+			os.Exit(1)
+		}
+	}
+
+	// Setup the bootstrap VPAs of the autoscaling stack; like the reconciler they are only written where VPAs are reconciled
+	if bootstrapped != nil && mode.RunsReconciler() {
+		bootstrap := &controller.BootstrapVPAs{
+			Client:          apiClient,
+			Metrics:         metricsInstance,
+			WorkloadConfigs: workloadConfigs,
+			Components:      bootstrapped,
+			Interval:        resyncPeriod,
+		}
+		if bootstrapOperatorVPA {
+			bootstrap.Self = self
+		}
+		if err := mgr.Add(bootstrap); err != nil {
+			setupLog.Error(err, "unable to set up bootstrap VPAs")
+			os.Exit(1)
+		}
+	}
+
+	// Setup the cluster-wide orphan sweep; like the reconciler it only runs where VPAs are reconciled
+	if orphanSweepInterval > 0 && mode.RunsReconciler() {
+		setupLog.Info("setting up orphan sweeper", "interval", orphanSweepInterval)
+		if err := mgr.Add(&controller.OrphanSweeper{
+			Client:          apiClient,
+			Metrics:         metricsInstance,
+			WorkloadConfigs: workloadConfigs,
+			Interval:        orphanSweepInterval,
+			MaxDeletions:    orphanSweepMaxDeletions,
+			BurstGuard:      reconciler.OrphanBurstGuard,
+			Recorder:        mgr.GetEventRecorderFor("vpa-operator"),
+			Ownership:       ownership,
+			Decisions:       decisionLog,
+		}); err != nil {
+			setupLog.Error(err, "unable to set up orphan sweeper")
+			os.Exit(1)
+		}
+	}
+
+	if decisionLog != nil {
+		if err := mgr.Add(&decisions.SignalDumper{Log: decisionLog, Logger: ctrl.Log.WithName("decisions")}); err != nil {
+			setupLog.Error(err, "unable to set up the decision log dump")
+			os.Exit(1)
+		}
+	}
+
+	// Setup the periodic summary, reported from what the reconciler records
+	if summaryLedger != nil {
+		setupLog.Info("setting up daily summary", "interval", summaryInterval)
+		if err := mgr.Add(&controller.DailySummary{
+			Client:   apiClient,
+			Ledger:   summaryLedger,
+			Recorder: mgr.GetEventRecorderFor("vpa-operator"),
+			Interval: summaryInterval,
+		}); err != nil {
+			setupLog.Error(err, "unable to set up daily summary")
+			os.Exit(1)
+		}
+	}
+
 	// Setup webhook if enabled
 	if enableWebhook {
 		setupLog.Info("setting up webhook server")
 		hookServer := mgr.GetWebhookServer()
-		hookServer.Register("/mutate-apps-v1-deployment", &webhook.Admission{
+		hookServer.Register(webhookhandler.DeploymentWebhookPath, &webhook.Admission{
 			Handler: &webhookhandler.DeploymentWebhookHandler{
-				Client:  mgr.GetClient(),
-				Scheme:  mgr.GetScheme(),
-				Metrics: metricsInstance,
+				Client:           apiClient,
+				Scheme:           mgr.GetScheme(),
+				Metrics:          metricsInstance,
+				StrictSelectors:  strictSelectors,
+				ClientTimeout:    webhookClientTimeout,
+				Self:             self,
+				Bootstrapped:     bootstrapped,
+				Ownership:        ownership,
+				SelectorDefaults: selectorDefaults,
+				RateLimiter:      webhookhandler.NewNamespaceRateLimiter(webhookVPAWritesPerSecond, webhookVPAWriteBurst),
+				Capacity:         clusterCapacity,
+				Selectors:        labelselector.NewCache(),
+				TopologyGuard:    topologyGuard,
+				Decisions:        decisionLog,
+			},
+		})
+		hookServer.Register(webhookhandler.VpaManagerWebhookPath, &webhook.Admission{
+			Handler: &webhookhandler.VpaManagerValidator{
+				Metrics:        metricsInstance,
+				AutoSafeguards: autoSafeguards,
+				Client:         apiClient,
 			},
 		})
+
+		// Not ready until the webhook server answers TLS connections
+		if err := mgr.AddReadyzCheck("webhook", hookServer.StartedChecker()); err != nil {
+			setupLog.Error(err, "unable to set up webhook ready check")
+			os.Exit(1)
+		}
+
+		if err := mgr.Add(certExpiry); err != nil {
+			setupLog.Error(err, "unable to set up webhook certificate expiry monitor")
+			os.Exit(1)
+		}
+
+		if webhookRegistration {
+			if webhookServiceNamespace == "" {
+				webhookServiceNamespace = os.Getenv(workload.PodNamespaceEnv)
+			}
+			port := int32(webhookServicePort)
+			if err := mgr.Add(&webhookhandler.Registrar{
+				Client: apiClient,
+				Name:   "vpa-operator",
+				Service: admissionregistrationv1.ServiceReference{
+					Namespace: webhookServiceNamespace,
+					Name:      webhookServiceName,
+					Port:      &port,
+				},
+				CertFile:  filepath.Join(certDir, "tls.crt"),
+				CAFile:    filepath.Join(certDir, "ca.crt"),
+				Started:   hookServer.StartedChecker(),
+				Ephemeral: webhookEphemeral,
+			}); err != nil {
+				setupLog.Error(err, "unable to set up webhook registration")
+				os.Exit(1)
+			}
+		}
 	}
 
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {